@@ -2212,6 +2212,67 @@ func TestRestoreInto(t *testing.T) {
 	sqlDB.CheckQueryResults(t, `SELECT * FROM "data 2".bank`, expected)
 }
 
+func TestBackupRestoreUserDefinedSchemas(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 1
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `
+		CREATE SCHEMA sc;
+		CREATE SCHEMA empty;
+		CREATE TABLE sc.bank (id INT PRIMARY KEY, balance INT);
+		INSERT INTO sc.bank VALUES (1, 10), (2, 20);
+		CREATE TABLE sc.child (id INT PRIMARY KEY, bank_id INT REFERENCES sc.bank);
+		INSERT INTO sc.child VALUES (1, 2);
+	`)
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo)
+
+	expectedBank := sqlDB.QueryStr(t, `SELECT * FROM data.sc.bank`)
+	expectedChild := sqlDB.QueryStr(t, `SELECT * FROM data.sc.child`)
+	const schemasQuery = `
+		SELECT schema_name FROM %s.information_schema.schemata
+		WHERE schema_name IN ('sc', 'empty') ORDER BY schema_name`
+
+	t.Run("into-db", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE DATABASE data2`)
+		sqlDB.Exec(t, `RESTORE data.* FROM $1 WITH into_db = 'data2'`, localFoo)
+
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(schemasQuery, "data2"), [][]string{{"empty"}, {"sc"}})
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data2.sc.bank`, expectedBank)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data2.sc.child`, expectedChild)
+		sqlDB.ExpectErr(t, "foreign key violation", `INSERT INTO data2.sc.child VALUES (2, 3)`)
+	})
+
+	t.Run("new-schema", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE DATABASE data3`)
+		sqlDB.Exec(t, `RESTORE data.sc.bank FROM $1 WITH into_db = 'data3'`, localFoo)
+
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(schemasQuery, "data3"), [][]string{{"sc"}})
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data3.sc.bank`, expectedBank)
+	})
+
+	t.Run("existing-schema", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `relation "bank" already exists`,
+			`RESTORE data.sc.bank FROM $1`, localFoo)
+
+		sqlDB.Exec(t, `DROP TABLE data.sc.child`)
+		sqlDB.Exec(t, `RESTORE data.sc.child FROM $1 WITH skip_missing_foreign_keys`, localFoo)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.sc.child`, expectedChild)
+	})
+
+	t.Run("database", func(t *testing.T) {
+		sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+		sqlDB.Exec(t, `RESTORE DATABASE data FROM $1`, localFoo)
+
+		sqlDB.CheckQueryResults(t, fmt.Sprintf(schemasQuery, "data"), [][]string{{"empty"}, {"sc"}})
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.sc.bank`, expectedBank)
+		sqlDB.CheckQueryResults(t, `SELECT * FROM data.sc.child`, expectedChild)
+		sqlDB.ExpectErr(t, "foreign key violation", `INSERT INTO data.sc.child VALUES (2, 3)`)
+	})
+}
+
 func TestBackupRestorePermissions(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -44,7 +44,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-// TableRewriteMap maps old table IDs to new table and parent IDs.
+// TableRewriteMap maps old table IDs to new table and parent IDs. It also maps
+// the old IDs of the restored databases and user-defined schemas to their new
+// IDs.
 type TableRewriteMap map[sqlbase.ID]*jobspb.RestoreDetails_TableRewrite
 
 const (
@@ -117,6 +119,12 @@ func loadSQLDescsFromBackupsAtTime(
 				continue
 			}
 		}
+		if sc := desc.GetSchema(); sc != nil {
+			// Likewise for the user-defined schemas.
+			if byID[sc.ParentID] == nil {
+				continue
+			}
+		}
 		allDescs = append(allDescs, *desc)
 	}
 	return allDescs, lastBackupDesc
@@ -187,6 +195,11 @@ func rewriteViewQueryDBNames(table *sqlbase.TableDescriptor, newDB string) error
 // TableRewrite. It first validates that the provided sqlDescs can be restored
 // into their original database (or the database specified in opst) to avoid
 // leaking table IDs if we can be sure the restore would fail.
+//
+// The user-defined schemas in sqlDescs are restored into the same database as
+// their tables. If the database already has a schema with the same name, the
+// restored tables are added to it, and the schema is mapped to its ID.
+// Otherwise the schema is created with a new ID.
 func allocateTableRewrites(
 	ctx context.Context,
 	p sql.PlanHookState,
@@ -207,10 +220,13 @@ func allocateTableRewrites(
 	}
 
 	databasesByID := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	schemasByID := make(map[sqlbase.ID]*sqlbase.SchemaDescriptor)
 	tablesByID := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
 	for _, desc := range sqlDescs {
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
 			databasesByID[dbDesc.ID] = dbDesc
+		} else if schemaDesc := desc.GetSchema(); schemaDesc != nil {
+			schemasByID[schemaDesc.ID] = schemaDesc
 		} else if tableDesc := desc.GetTable(); tableDesc != nil {
 			tablesByID[tableDesc.ID] = tableDesc
 		}
@@ -240,6 +256,13 @@ func allocateTableRewrites(
 			return nil, err
 		}
 
+		// Check that the user-defined schema of the table exists.
+		if scID := table.UnexposedParentSchemaID; scID != 0 {
+			if _, ok := schemasByID[scID]; !ok {
+				return nil, errors.Errorf("no schema with ID %d in backup for table %q", scID, table.Name)
+			}
+		}
+
 		// Check that referenced sequences exist.
 		for _, col := range table.Columns {
 			for _, seqID := range col.UsesSequenceIds {
@@ -271,44 +294,107 @@ func allocateTableRewrites(
 			}
 		}
 
-		for _, table := range tablesByID {
-			var targetDB string
+		targetDBName := func(parentID sqlbase.ID, kind, name string) (string, error) {
 			if renaming {
-				targetDB = overrideDB
-			} else {
-				database, ok := databasesByID[table.ParentID]
-				if !ok {
-					return errors.Errorf("no database with ID %d in backup for table %q",
-						table.ParentID, table.Name)
+				return overrideDB, nil
+			}
+			database, ok := databasesByID[parentID]
+			if !ok {
+				return "", errors.Errorf("no database with ID %d in backup for %s %q",
+					parentID, kind, name)
+			}
+			return database.Name, nil
+		}
+		existingDBID := func(targetDB, kind, name string) (sqlbase.ID, error) {
+			existingDatabaseID, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, targetDB))
+			if err != nil {
+				return 0, err
+			}
+			if existingDatabaseID.Value == nil {
+				return 0, errors.Errorf("a database named %q needs to exist to restore %s %q",
+					targetDB, kind, name)
+			}
+
+			newParentID, err := existingDatabaseID.Value.GetInt()
+			if err != nil {
+				return 0, err
+			}
+			return sqlbase.ID(newParentID), nil
+		}
+
+		for _, schema := range schemasByID {
+			targetDB, err := targetDBName(schema.ParentID, "schema", schema.Name)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := restoreDBNames[targetDB]; ok {
+				needsNewParentIDs[targetDB] = append(needsNewParentIDs[targetDB], schema.ID)
+				continue
+			}
+			parentID, err := existingDBID(targetDB, "schema", schema.Name)
+			if err != nil {
+				return err
+			}
+			parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, parentID)
+			if err != nil {
+				return errors.Wrapf(err, "failed to lookup parent DB %d", parentID)
+			}
+			if err := p.CheckPrivilege(ctx, parentDB, privilege.CREATE); err != nil {
+				return err
+			}
+
+			// Reuse the schema with the same name if there is one. Its ID is
+			// left to be allocated below otherwise.
+			existingSchemaID, err := sql.GetUserSchemaID(ctx, txn, parentID, schema.Name)
+			if err != nil {
+				return err
+			}
+			if existingSchemaID != 0 {
+				var existing sqlbase.Descriptor
+				if err := txn.GetProto(ctx, sqlbase.MakeDescMetadataKey(existingSchemaID), &existing); err != nil {
+					return err
+				}
+				existingSchema := existing.GetSchema()
+				if existingSchema == nil {
+					return errors.Errorf("descriptor %d of schema %q is not a schema descriptor",
+						existingSchemaID, schema.Name)
+				}
+				if err := p.CheckPrivilege(ctx, existingSchema, privilege.CREATE); err != nil {
+					return err
 				}
-				targetDB = database.Name
+			}
+			tableRewrites[schema.ID] = &jobspb.RestoreDetails_TableRewrite{
+				TableID: existingSchemaID, ParentID: parentID,
+			}
+		}
+
+		for _, table := range tablesByID {
+			targetDB, err := targetDBName(table.ParentID, "table", table.Name)
+			if err != nil {
+				return err
 			}
 
 			if _, ok := restoreDBNames[targetDB]; ok {
 				needsNewParentIDs[targetDB] = append(needsNewParentIDs[targetDB], table.ID)
 			} else {
-				var parentID sqlbase.ID
-				{
-					existingDatabaseID, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, targetDB))
-					if err != nil {
-						return err
-					}
-					if existingDatabaseID.Value == nil {
-						return errors.Errorf("a database named %q needs to exist to restore table %q",
-							targetDB, table.Name)
-					}
-
-					newParentID, err := existingDatabaseID.Value.GetInt()
-					if err != nil {
-						return err
-					}
-					parentID = sqlbase.ID(newParentID)
+				parentID, err := existingDBID(targetDB, "table", table.Name)
+				if err != nil {
+					return err
 				}
 
 				// Check that the table name is _not_ in use.
 				// This would fail the CPut later anyway, but this yields a prettier error.
-				if err := CheckTableExists(ctx, txn, parentID, table.Name); err != nil {
-					return err
+				// The name can only be in use in a user-defined schema if the schema
+				// already exists.
+				nameParentID := parentID
+				if scID := table.UnexposedParentSchemaID; scID != 0 {
+					nameParentID = tableRewrites[scID].TableID
+				}
+				if nameParentID != 0 {
+					if err := CheckTableExists(ctx, txn, nameParentID, table.Name); err != nil {
+						return err
+					}
 				}
 
 				// Check privileges. These will be checked again in the transaction
@@ -333,7 +419,7 @@ func allocateTableRewrites(
 		return nil, err
 	}
 
-	// Allocate new IDs for each database and table.
+	// Allocate new IDs for each database, schema and table.
 	//
 	// NB: we do this in a standalone transaction, not one that covers the
 	// entire restore since restarts would be terrible (and our bulk import
@@ -357,6 +443,18 @@ func allocateTableRewrites(
 		}
 	}
 
+	for _, schema := range schemasByID {
+		if tableRewrites[schema.ID].TableID != 0 {
+			// The schema already exists in the target database.
+			continue
+		}
+		newSchemaID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return nil, err
+		}
+		tableRewrites[schema.ID].TableID = newSchemaID
+	}
+
 	tables := make([]*sqlbase.TableDescriptor, 0, len(tablesByID))
 	for _, table := range tablesByID {
 		tables = append(tables, table)
@@ -414,6 +512,13 @@ func RewriteTableDescs(
 
 		table.ID = tableRewrite.TableID
 		table.ParentID = tableRewrite.ParentID
+		if table.UnexposedParentSchemaID != 0 {
+			schemaRewrite, ok := tableRewrites[table.UnexposedParentSchemaID]
+			if !ok {
+				return errors.Errorf("missing schema rewrite for table %d", table.ID)
+			}
+			table.UnexposedParentSchemaID = schemaRewrite.TableID
+		}
 
 		if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
 			// Verify that for any interleaved index being restored, the interleave
@@ -869,13 +974,15 @@ func splitAndScatter(
 
 // WriteTableDescs writes all the the new descriptors: First the ID ->
 // TableDescriptor for the new table, then flip (or initialize) the name -> ID
-// entry so any new queries will use the new one. The tables are assigned the
-// permissions of their parent database and the user must have CREATE permission
-// on that database at the time this function is called.
+// entry so any new queries will use the new one. The schemas and tables are
+// assigned the permissions of their parent database and the user must have
+// CREATE permission on that database at the time this function is called. A
+// schema that already exists with the same name and ID is not written again.
 func WriteTableDescs(
 	ctx context.Context,
 	txn *client.Txn,
 	databases []*sqlbase.DatabaseDescriptor,
+	schemas []*sqlbase.SchemaDescriptor,
 	tables []*sqlbase.TableDescriptor,
 	user string,
 	settings *cluster.Settings,
@@ -893,6 +1000,37 @@ func WriteTableDescs(
 			b.CPut(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc), nil)
 			b.CPut(sqlbase.MakeNameMetadataKey(keys.RootNamespaceID, desc.Name), desc.ID, nil)
 		}
+		for _, desc := range schemas {
+			nsID, err := sql.GetOrCreateSchemaNamespaceID(ctx, txn, desc.ParentID, false /* kvTrace */)
+			if err != nil {
+				return err
+			}
+			nameKey := sqlbase.MakeNameMetadataKey(nsID, desc.Name)
+			existing, err := txn.Get(ctx, nameKey)
+			if err != nil {
+				return err
+			}
+			if existing.Exists() {
+				if sqlbase.ID(existing.ValueInt()) != desc.ID {
+					return sqlbase.NewSchemaAlreadyExistsError(desc.Name)
+				}
+				continue
+			}
+			if wrote, ok := wroteDBs[desc.ParentID]; ok {
+				desc.Privileges = wrote.GetPrivileges()
+			} else {
+				parentDB, err := sqlbase.GetDatabaseDescFromID(ctx, txn, desc.ParentID)
+				if err != nil {
+					return errors.Wrapf(err, "failed to lookup parent DB %d", desc.ParentID)
+				}
+				if err := sql.CheckPrivilegeForUser(ctx, user, parentDB, privilege.CREATE); err != nil {
+					return err
+				}
+				desc.Privileges = parentDB.GetPrivileges()
+			}
+			b.CPut(sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc), nil)
+			b.CPut(nameKey, desc.ID, nil)
+		}
 		for _, table := range tables {
 			if wrote, ok := wroteDBs[table.ParentID]; ok {
 				table.Privileges = wrote.GetPrivileges()
@@ -922,6 +1060,11 @@ func WriteTableDescs(
 			return err
 		}
 
+		for _, desc := range schemas {
+			if err := desc.Validate(); err != nil {
+				return errors.Wrapf(err, "validate schema %d", desc.ID)
+			}
+		}
 		for _, table := range tables {
 			if err := table.Validate(ctx, txn, settings); err != nil {
 				return errors.Wrapf(err, "validate table %d", table.ID)
@@ -966,7 +1109,13 @@ func restore(
 	overrideDB string,
 	job *jobs.Job,
 	resultsCh chan<- tree.Datums,
-) (roachpb.BulkOpSummary, []*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
+) (
+	roachpb.BulkOpSummary,
+	[]*sqlbase.DatabaseDescriptor,
+	[]*sqlbase.SchemaDescriptor,
+	[]*sqlbase.TableDescriptor,
+	error,
+) {
 	// A note about contexts and spans in this method: the top-level context
 	// `restoreCtx` is used for orchestration logging. All operations that carry
 	// out work get their individual contexts.
@@ -981,6 +1130,7 @@ func restore(
 	}
 
 	var databases []*sqlbase.DatabaseDescriptor
+	var schemas []*sqlbase.SchemaDescriptor
 	var tables []*sqlbase.TableDescriptor
 	var oldTableIDs []sqlbase.ID
 	for _, desc := range sqlDescs {
@@ -994,6 +1144,13 @@ func restore(
 				databases = append(databases, dbDesc)
 			}
 		}
+		if scDesc := desc.GetSchema(); scDesc != nil {
+			if rewrite, ok := tableRewrites[scDesc.ID]; ok {
+				scDesc.ID = rewrite.TableID
+				scDesc.ParentID = rewrite.ParentID
+				schemas = append(schemas, scDesc)
+			}
+		}
 	}
 
 	log.Eventf(restoreCtx, "starting restore for %d tables", len(tables))
@@ -1005,7 +1162,7 @@ func restore(
 	// Assign new IDs and privileges to the tables, and update all references to
 	// use the new IDs.
 	if err := RewriteTableDescs(tables, tableRewrites, overrideDB); err != nil {
-		return mu.res, nil, nil, err
+		return mu.res, nil, nil, nil, err
 	}
 
 	{
@@ -1025,7 +1182,7 @@ func restore(
 	for i := range tables {
 		newDescBytes, err := protoutil.Marshal(sqlbase.WrapDescriptor(tables[i]))
 		if err != nil {
-			return mu.res, nil, nil, errors.Wrap(err, "marshaling descriptor")
+			return mu.res, nil, nil, nil, errors.Wrap(err, "marshaling descriptor")
 		}
 		rekeys = append(rekeys, roachpb.ImportRequest_TableRekey{
 			OldID:   uint32(oldTableIDs[i]),
@@ -1034,7 +1191,7 @@ func restore(
 	}
 	kr, err := storageccl.MakeKeyRewriterFromRekeys(rekeys)
 	if err != nil {
-		return mu.res, nil, nil, err
+		return mu.res, nil, nil, nil, err
 	}
 
 	// Pivot the backups, which are grouped by time, into requests for import,
@@ -1042,7 +1199,7 @@ func restore(
 	highWaterMark := job.Progress().Details.(*jobspb.Progress_Restore).Restore.HighWater
 	importSpans, _, err := makeImportSpans(spans, backupDescs, highWaterMark, errOnMissingRange)
 	if err != nil {
-		return mu.res, nil, nil, errors.Wrapf(err, "making import requests for %d backups", len(backupDescs))
+		return mu.res, nil, nil, nil, errors.Wrapf(err, "making import requests for %d backups", len(backupDescs))
 	}
 
 	for i := range importSpans {
@@ -1178,10 +1335,10 @@ func restore(
 		// This leaves the data that did get imported in case the user wants to
 		// retry.
 		// TODO(dan): Build tooling to allow a user to restart a failed restore.
-		return mu.res, nil, nil, errors.Wrapf(err, "importing %d ranges", len(importSpans))
+		return mu.res, nil, nil, nil, errors.Wrapf(err, "importing %d ranges", len(importSpans))
 	}
 
-	return mu.res, databases, tables, nil
+	return mu.res, databases, schemas, tables, nil
 }
 
 // RestoreHeader is the header for RESTORE stmt results.
@@ -1389,6 +1546,7 @@ type restoreResumer struct {
 	settings       *cluster.Settings
 	res            roachpb.BulkOpSummary
 	databases      []*sqlbase.DatabaseDescriptor
+	schemas        []*sqlbase.SchemaDescriptor
 	tables         []*sqlbase.TableDescriptor
 	statsRefresher *stats.Refresher
 }
@@ -1404,7 +1562,7 @@ func (r *restoreResumer) Resume(
 		return err
 	}
 
-	res, databases, tables, err := restore(
+	res, databases, schemas, tables, err := restore(
 		ctx,
 		p.ExecCfg().DB,
 		p.ExecCfg().Gossip,
@@ -1418,6 +1576,7 @@ func (r *restoreResumer) Resume(
 	)
 	r.res = res
 	r.databases = databases
+	r.schemas = schemas
 	r.tables = tables
 	r.statsRefresher = p.ExecCfg().StatsRefresher
 	return err
@@ -1448,7 +1607,7 @@ func (r *restoreResumer) OnSuccess(ctx context.Context, txn *client.Txn, job *jo
 	// Write the new TableDescriptors and flip the namespace entries over to
 	// them. After this call, any queries on a table will be served by the newly
 	// restored data.
-	if err := WriteTableDescs(ctx, txn, r.databases, r.tables, job.Payload().Username, r.settings, nil); err != nil {
+	if err := WriteTableDescs(ctx, txn, r.databases, r.schemas, r.tables, job.Payload().Username, r.settings, nil); err != nil {
 		return errors.Wrapf(err, "restoring %d TableDescriptors", len(r.tables))
 	}
 
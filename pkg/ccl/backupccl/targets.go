@@ -18,7 +18,7 @@ import (
 )
 
 type descriptorsMatched struct {
-	// all tables that match targets plus their parent databases.
+	// all tables that match targets plus their parent databases and schemas.
 	descs []sqlbase.Descriptor
 
 	// the databases from which all tables were matched (eg a.* or DATABASE a).
@@ -52,19 +52,34 @@ type descriptorResolver struct {
 	descByID map[sqlbase.ID]sqlbase.Descriptor
 	// Map: db name -> dbID
 	dbsByName map[string]sqlbase.ID
-	// Map: dbID -> obj name -> obj ID
+	// Map: dbID -> schema name -> schema ID, for the user-defined schemas.
+	schemasByName map[sqlbase.ID]map[string]sqlbase.ID
+	// Map: namespace parent ID -> obj name -> obj ID. The namespace parent ID
+	// is the ID of the database for the objects of the public schema, and the
+	// ID of the schema for the objects of a user-defined schema.
 	objsByName map[sqlbase.ID]map[string]sqlbase.ID
 }
 
+// lookupNamespaceParentID returns the ID under which the names of the objects
+// of the given schema are recorded, or false if the schema does not exist.
+func (r *descriptorResolver) lookupNamespaceParentID(dbName, scName string) (sqlbase.ID, bool) {
+	dbID, ok := r.dbsByName[dbName]
+	if !ok {
+		return 0, false
+	}
+	if scName == tree.PublicSchema {
+		return dbID, true
+	}
+	scID, ok := r.schemasByName[dbID][scName]
+	return scID, ok
+}
+
 // LookupSchema implements the tree.TableNameTargetResolver interface.
 func (r *descriptorResolver) LookupSchema(
 	_ context.Context, dbName, scName string,
 ) (bool, tree.SchemaMeta, error) {
-	if scName != tree.PublicSchema {
-		return false, nil, nil
-	}
-	if dbID, ok := r.dbsByName[dbName]; ok {
-		return true, r.descByID[dbID], nil
+	if id, ok := r.lookupNamespaceParentID(dbName, scName); ok {
+		return true, r.descByID[id], nil
 	}
 	return false, nil, nil
 }
@@ -76,14 +91,11 @@ func (r *descriptorResolver) LookupObject(
 	if requireMutable {
 		panic("did not expect request for mutable descriptor")
 	}
-	if scName != tree.PublicSchema {
-		return false, nil, nil
-	}
-	dbID, ok := r.dbsByName[dbName]
+	parentID, ok := r.lookupNamespaceParentID(dbName, scName)
 	if !ok {
 		return false, nil, nil
 	}
-	if objMap, ok := r.objsByName[dbID]; ok {
+	if objMap, ok := r.objsByName[parentID]; ok {
 		if objID, ok := objMap[obName]; ok {
 			return true, r.descByID[objID], nil
 		}
@@ -95,14 +107,15 @@ func (r *descriptorResolver) LookupObject(
 // known set of descriptors.
 func newDescriptorResolver(descs []sqlbase.Descriptor) (*descriptorResolver, error) {
 	r := &descriptorResolver{
-		descByID:   make(map[sqlbase.ID]sqlbase.Descriptor),
-		dbsByName:  make(map[string]sqlbase.ID),
-		objsByName: make(map[sqlbase.ID]map[string]sqlbase.ID),
+		descByID:      make(map[sqlbase.ID]sqlbase.Descriptor),
+		dbsByName:     make(map[string]sqlbase.ID),
+		schemasByName: make(map[sqlbase.ID]map[string]sqlbase.ID),
+		objsByName:    make(map[sqlbase.ID]map[string]sqlbase.ID),
 	}
 
 	// Iterate to find the databases first. We need that because we also
-	// check the ParentID for tables, and all the valid parents must be
-	// known before we start to check that.
+	// check the ParentID for schemas and tables, and all the valid parents
+	// must be known before we start to check that.
 	for _, desc := range descs {
 		if dbDesc := desc.GetDatabase(); dbDesc != nil {
 			if _, ok := r.dbsByName[dbDesc.Name]; ok {
@@ -119,10 +132,31 @@ func newDescriptorResolver(descs []sqlbase.Descriptor) (*descriptorResolver, err
 		}
 		r.descByID[desc.GetID()] = desc
 	}
+	// Then the user-defined schemas.
+	for _, desc := range descs {
+		if scDesc := desc.GetSchema(); scDesc != nil {
+			parentDesc, ok := r.descByID[scDesc.ParentID]
+			if !ok || parentDesc.GetDatabase() == nil {
+				return nil, errors.Errorf("schema %q has unknown ParentID %d", scDesc.Name, scDesc.ParentID)
+			}
+			scMap := r.schemasByName[scDesc.ParentID]
+			if scMap == nil {
+				scMap = make(map[string]sqlbase.ID)
+			}
+			if _, ok := scMap[scDesc.Name]; ok {
+				return nil, errors.Errorf("duplicate schema name: %q.%q used for ID %d and %d",
+					parentDesc.GetName(), scDesc.Name, scDesc.ID, scMap[scDesc.Name])
+			}
+			scMap[scDesc.Name] = scDesc.ID
+			r.schemasByName[scDesc.ParentID] = scMap
+		}
+	}
 	// Now on to the tables.
 	for _, desc := range descs {
 		if tbDesc := desc.GetTable(); tbDesc != nil {
-			if tbDesc.Dropped() {
+			// Temporary tables only live as long as the session that created
+			// them, so they are never backed up or restored.
+			if tbDesc.Dropped() || tbDesc.Temporary {
 				continue
 			}
 			parentDesc, ok := r.descByID[tbDesc.ParentID]
@@ -133,16 +167,23 @@ func newDescriptorResolver(descs []sqlbase.Descriptor) (*descriptorResolver, err
 				return nil, errors.Errorf("table %q's ParentID %d (%q) is not a database",
 					tbDesc.Name, tbDesc.ParentID, parentDesc.GetName())
 			}
-			objMap := r.objsByName[parentDesc.GetID()]
+			if scID := tbDesc.UnexposedParentSchemaID; scID != 0 {
+				if scDesc, ok := r.descByID[scID]; !ok || scDesc.GetSchema() == nil {
+					return nil, errors.Errorf("table %q has unknown schema ID %d", tbDesc.Name, scID)
+				}
+			}
+			parentID := tbDesc.GetNamespaceParentID()
+			objMap := r.objsByName[parentID]
 			if objMap == nil {
 				objMap = make(map[string]sqlbase.ID)
 			}
 			if _, ok := objMap[tbDesc.Name]; ok {
+				parentDesc := r.descByID[parentID]
 				return nil, errors.Errorf("duplicate table name: %q.%q used for ID %d and %d",
 					parentDesc.GetName(), tbDesc.Name, tbDesc.ID, objMap[tbDesc.Name])
 			}
 			objMap[tbDesc.Name] = tbDesc.ID
-			r.objsByName[parentDesc.GetID()] = objMap
+			r.objsByName[parentID] = objMap
 		}
 	}
 
@@ -151,7 +192,9 @@ func newDescriptorResolver(descs []sqlbase.Descriptor) (*descriptorResolver, err
 
 // descriptorsMatchingTargets returns the descriptors that match the targets. A
 // database descriptor is included in this set if it matches the targets (or the
-// session database) or if one of its tables matches the targets. All expanded
+// session database) or if one of its tables matches the targets. Likewise, the
+// descriptor of a user-defined schema is included if its database is expanded
+// or if one of its tables matches the targets. All expanded
 // DBs, via either `foo.*` or `DATABASE foo` are noted, as are those explicitly
 // named as DBs (e.g. with `DATABASE foo`, not `foo.*`). These distinctions are
 // used e.g. by RESTORE.
@@ -164,9 +207,6 @@ func descriptorsMatchingTargets(
 	descriptors []sqlbase.Descriptor,
 	targets tree.TargetList,
 ) (descriptorsMatched, error) {
-	// TODO(dan): once CockroachDB supports schemas in addition to
-	// catalogs, then this method will need to support it.
-
 	ret := descriptorsMatched{}
 
 	resolver, err := newDescriptorResolver(descriptors)
@@ -176,6 +216,13 @@ func descriptorsMatchingTargets(
 
 	alreadyRequestedDBs := make(map[sqlbase.ID]struct{})
 	alreadyExpandedDBs := make(map[sqlbase.ID]struct{})
+	alreadyRequestedSchemas := make(map[sqlbase.ID]struct{})
+	requestSchema := func(scID sqlbase.ID) {
+		if _, ok := alreadyRequestedSchemas[scID]; !ok {
+			ret.descs = append(ret.descs, resolver.descByID[scID])
+			alreadyRequestedSchemas[scID] = struct{}{}
+		}
+	}
 	// Process all the DATABASE requests.
 	for _, d := range targets.Databases {
 		dbID, ok := resolver.dbsByName[string(d)]
@@ -220,6 +267,10 @@ func descriptorsMatchingTargets(
 				ret.descs = append(ret.descs, parentDesc)
 				alreadyRequestedDBs[parentID] = struct{}{}
 			}
+			// Likewise for its user-defined schema, if any.
+			if scID := desc.GetTable().UnexposedParentSchemaID; scID != 0 {
+				requestSchema(scID)
+			}
 			// Then request the table itself.
 			if _, ok := alreadyRequestedTables[desc.GetID()]; !ok {
 				alreadyRequestedTables[desc.GetID()] = struct{}{}
@@ -236,6 +287,23 @@ func descriptorsMatchingTargets(
 			}
 			desc := descI.(sqlbase.Descriptor)
 
+			// A user-defined schema is expanded on its own: its database and its
+			// tables are requested as if they had been named explicitly.
+			if scDesc := desc.GetSchema(); scDesc != nil {
+				if _, ok := alreadyRequestedDBs[scDesc.ParentID]; !ok {
+					ret.descs = append(ret.descs, resolver.descByID[scDesc.ParentID])
+					alreadyRequestedDBs[scDesc.ParentID] = struct{}{}
+				}
+				requestSchema(scDesc.ID)
+				for _, tblID := range resolver.objsByName[scDesc.ID] {
+					if _, ok := alreadyRequestedTables[tblID]; !ok {
+						alreadyRequestedTables[tblID] = struct{}{}
+						ret.descs = append(ret.descs, resolver.descByID[tblID])
+					}
+				}
+				continue
+			}
+
 			// If the database is not requested already, request it now.
 			dbID := desc.GetID()
 			if _, ok := alreadyRequestedDBs[dbID]; !ok {
@@ -254,11 +322,19 @@ func descriptorsMatchingTargets(
 		}
 	}
 
-	// Then process the database expansions.
+	// Then process the database expansions, which include the user-defined
+	// schemas of the databases and their tables.
 	for dbID := range alreadyExpandedDBs {
-		for _, tblID := range resolver.objsByName[dbID] {
-			if _, ok := alreadyRequestedTables[tblID]; !ok {
-				ret.descs = append(ret.descs, resolver.descByID[tblID])
+		parentIDs := []sqlbase.ID{dbID}
+		for _, scID := range resolver.schemasByName[dbID] {
+			requestSchema(scID)
+			parentIDs = append(parentIDs, scID)
+		}
+		for _, parentID := range parentIDs {
+			for _, tblID := range resolver.objsByName[parentID] {
+				if _, ok := alreadyRequestedTables[tblID]; !ok {
+					ret.descs = append(ret.descs, resolver.descByID[tblID])
+				}
 			}
 		}
 	}
@@ -33,6 +33,12 @@ func TestDescriptorsMatchingTargets(t *testing.T) {
 		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 4, Name: "baz", ParentID: 3}),
 		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 3, Name: "data"}),
 		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 5, Name: "empty"}),
+		*sqlbase.WrapDescriptor(&sqlbase.DatabaseDescriptor{ID: 6, Name: "scdb"}),
+		*sqlbase.WrapDescriptor(&sqlbase.SchemaDescriptor{ID: 7, Name: "sc", ParentID: 6}),
+		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 8, Name: "qux", ParentID: 6}),
+		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 9, Name: "qux", ParentID: 6, UnexposedParentSchemaID: 7}),
+		*sqlbase.WrapDescriptor(&sqlbase.TableDescriptor{ID: 10, Name: "quux", ParentID: 6, UnexposedParentSchemaID: 7}),
+		*sqlbase.WrapDescriptor(&sqlbase.SchemaDescriptor{ID: 11, Name: "emptysc", ParentID: 6}),
 	}
 
 	tests := []struct {
@@ -95,6 +101,17 @@ func TestDescriptorsMatchingTargets(t *testing.T) {
 		{"", `TABLE system."foo"`, []string{"system", "foo"}, nil, ``},
 		{"", `TABLE system.public."foo"`, []string{"system", "foo"}, nil, ``},
 		{"system", `TABLE "foo"`, []string{"system", "foo"}, nil, ``},
+
+		{"", "DATABASE scdb", []string{"scdb", "sc", "emptysc", "qux", "qux", "quux"}, []string{"scdb"}, ``},
+		{"", "TABLE scdb.*", []string{"scdb", "sc", "emptysc", "qux", "qux", "quux"}, nil, ``},
+		{"", "TABLE scdb.qux", []string{"scdb", "qux"}, nil, ``},
+		{"", "TABLE scdb.sc.qux", []string{"scdb", "sc", "qux"}, nil, ``},
+		{"", "TABLE scdb.sc.qux, scdb.sc.quux", []string{"scdb", "sc", "qux", "quux"}, nil, ``},
+		{"", "TABLE scdb.sc.*", []string{"scdb", "sc", "qux", "quux"}, nil, ``},
+		{"", "TABLE scdb.emptysc.*", []string{"scdb", "emptysc"}, nil, ``},
+		{"", "TABLE scdb.noexist.*", nil, nil, `"scdb\.noexist\.\*" does not match any valid database or schema`},
+		{"scdb", "TABLE quux", nil, nil, `table "quux" does not exist`},
+		{"scdb", "TABLE sc.quux", []string{"scdb", "sc", "quux"}, nil, ``},
 		// TODO(dan): Enable these tests once #8862 is fixed.
 		// {"", `TABLE system."FOO"`, []string{"system"}},
 		// {"system", `TABLE "FOO"`, []string{"system"}},
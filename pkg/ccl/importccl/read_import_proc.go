@@ -222,7 +222,7 @@ func newRowConverter(
 	}
 
 	ri, err := row.MakeInserter(nil /* txn */, immutDesc, nil, /* fkTables */
-		immutDesc.Columns, false /* checkFKs */, &sqlbase.DatumAlloc{})
+		immutDesc.Columns, false /* checkFKs */, evalCtx, &sqlbase.DatumAlloc{})
 	if err != nil {
 		return nil, errors.Wrap(err, "make row inserter")
 	}
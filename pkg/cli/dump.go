@@ -41,7 +41,9 @@ var dumpCmd = &cobra.Command{
 	Short: "dump sql tables\n",
 	Long: `
 Dump SQL tables of a cockroach database. If the table name
-is omitted, dump all tables in the database.
+is omitted, dump all tables in the database. The tables of
+user-defined schemas are named by their schema-qualified
+name, such as myschema.mytable.
 `,
 	Args: cobra.MinimumNArgs(1),
 	RunE: MaybeDecorateGRPCError(runDump),
@@ -110,6 +112,16 @@ func runDump(cmd *cobra.Command, args []string) error {
 	w := os.Stdout
 
 	if dumpCtx.dumpMode != dumpDataOnly {
+		schemaNames, err := getSchemaNames(conn, dbName, mds, tableNames == nil, ts)
+		if err != nil {
+			return err
+		}
+		for _, scName := range schemaNames {
+			fmt.Fprintf(w, "%s;\n", &tree.CreateSchema{Schema: tree.Name(scName)})
+		}
+		if len(schemaNames) > 0 && len(mds) > 0 {
+			fmt.Fprintln(w)
+		}
 		for i, md := range mds {
 			if i > 0 {
 				fmt.Fprintln(w)
@@ -191,6 +203,17 @@ type basicMetadata struct {
 	validate   []string
 }
 
+// dumpName returns the name of the table in the dump. Since a dump can be
+// loaded into another database, the name is not qualified by the name of the
+// database, unlike md.name, and only qualified by the name of its schema if it
+// is a user-defined schema.
+func (md basicMetadata) dumpName() *tree.TableName {
+	tn := *md.name
+	tn.ExplicitCatalog = false
+	tn.ExplicitSchema = tn.SchemaName != tree.PublicSchemaName
+	return &tn
+}
+
 // tableMetadata describes one table to dump.
 type tableMetadata struct {
 	basicMetadata
@@ -219,16 +242,25 @@ func getDumpMetadata(
 		clusterTS = asOf
 	}
 
+	var names []tree.TableName
 	if tableNames == nil {
-		tableNames, err = getTableNames(conn, dbName, clusterTS)
+		names, err = getTableNames(conn, dbName, clusterTS)
 		if err != nil {
 			return nil, "", err
 		}
+	} else {
+		names = make([]tree.TableName, len(tableNames))
+		for i, tableName := range tableNames {
+			names[i], err = parseDumpTableName(dbName, tableName)
+			if err != nil {
+				return nil, "", err
+			}
+		}
 	}
 
-	mds = make([]basicMetadata, len(tableNames))
-	for i, tableName := range tableNames {
-		basicMD, err := getBasicMetadata(conn, dbName, tableName, clusterTS)
+	mds = make([]basicMetadata, len(names))
+	for i := range names {
+		basicMD, err := getBasicMetadata(conn, &names[i], clusterTS)
 		if err != nil {
 			return nil, "", err
 		}
@@ -238,10 +270,30 @@ func getDumpMetadata(
 	return mds, clusterTS, nil
 }
 
-// getTableNames retrieves all tables names in the given database.
-func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []string, err error) {
+// parseDumpTableName returns the fully qualified name of a table given on the
+// command line. The name is either the name of a table of the public schema,
+// or a name qualified by the name of a user-defined schema.
+func parseDumpTableName(dbName, tableName string) (tree.TableName, error) {
+	scName := tree.PublicSchemaName
+	if strings.Contains(tableName, ".") {
+		tn, err := parser.ParseTableName(tableName)
+		if err != nil {
+			return tree.TableName{}, err
+		}
+		if tn.ExplicitCatalog {
+			return tree.TableName{}, errors.Errorf(
+				"table name %s must not be qualified by a database name", tableName)
+		}
+		scName, tableName = tn.SchemaName, tn.Table()
+	}
+	return tree.MakeTableNameWithSchema(tree.Name(dbName), scName, tree.Name(tableName)), nil
+}
+
+// getTableNames retrieves the fully qualified names of all tables in the given
+// database.
+func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []tree.TableName, err error) {
 	rows, err := conn.Query(fmt.Sprintf(`
-		SELECT descriptor_name
+		SELECT schema_name, descriptor_name
 		FROM "".crdb_internal.create_statements
 		AS OF SYSTEM TIME %s
 		WHERE database_name = $1
@@ -250,19 +302,24 @@ func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []string
 		return nil, err
 	}
 
-	vals := make([]driver.Value, 1)
+	vals := make([]driver.Value, 2)
 	for {
 		if err := rows.Next(vals); err == io.EOF {
 			break
 		} else if err != nil {
 			return nil, err
 		}
-		nameI := vals[0]
+		scNameI, nameI := vals[0], vals[1]
+		scName, ok := scNameI.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected value: %T", scNameI)
+		}
 		name, ok := nameI.(string)
 		if !ok {
 			return nil, fmt.Errorf("unexpected value: %T", nameI)
 		}
-		tableNames = append(tableNames, name)
+		tableNames = append(tableNames,
+			tree.MakeTableNameWithSchema(tree.Name(dbName), tree.Name(scName), tree.Name(name)))
 	}
 
 	if err := rows.Close(); err != nil {
@@ -272,11 +329,61 @@ func getTableNames(conn *sqlConn, dbName string, ts string) (tableNames []string
 	return tableNames, nil
 }
 
-func getBasicMetadata(conn *sqlConn, dbName, tableName string, ts string) (basicMetadata, error) {
-	name := tree.NewTableName(tree.Name(dbName), tree.Name(tableName))
+// getSchemaNames retrieves the names of the user-defined schemas to create in
+// the dump: the schemas of the dumped tables and, if allSchemas is set, all
+// the user-defined schemas of the given database.
+func getSchemaNames(
+	conn *sqlConn, dbName string, mds []basicMetadata, allSchemas bool, ts string,
+) ([]string, error) {
+	seen := make(map[string]bool)
+	var schemaNames []string
+	add := func(scName string) {
+		if scName != tree.PublicSchema && !seen[scName] {
+			seen[scName] = true
+			schemaNames = append(schemaNames, scName)
+		}
+	}
+	for _, md := range mds {
+		add(md.name.Schema())
+	}
+
+	if allSchemas {
+		rows, err := conn.Query(fmt.Sprintf(`
+		SELECT schema_name
+		FROM %s.information_schema.schemata
+		AS OF SYSTEM TIME %s
+		WHERE catalog_name = $1
+			AND schema_name NOT IN ('crdb_internal', 'information_schema', 'pg_catalog')
+		`, tree.NameString(dbName), lex.EscapeSQLString(ts)), []driver.Value{dbName})
+		if err != nil {
+			return nil, err
+		}
+		vals := make([]driver.Value, 1)
+		for {
+			if err := rows.Next(vals); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			nameI := vals[0]
+			name, ok := nameI.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected value: %T", nameI)
+			}
+			add(name)
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(schemaNames)
+	return schemaNames, nil
+}
 
+func getBasicMetadata(conn *sqlConn, name *tree.TableName, ts string) (basicMetadata, error) {
 	// Fetch table ID.
-	dbNameEscaped := tree.NameString(dbName)
+	dbNameEscaped := tree.NameString(name.Catalog())
 	vals, err := conn.QueryRow(fmt.Sprintf(`
 		SELECT
 			descriptor_id,
@@ -287,8 +394,10 @@ func getBasicMetadata(conn *sqlConn, dbName, tableName string, ts string) (basic
 		FROM %s.crdb_internal.create_statements
 		AS OF SYSTEM TIME %s
 		WHERE database_name = $1
-			AND descriptor_name = $2
-	`, dbNameEscaped, lex.EscapeSQLString(ts)), []driver.Value{dbName, tableName})
+			AND schema_name = $2
+			AND descriptor_name = $3
+	`, dbNameEscaped, lex.EscapeSQLString(ts)),
+		[]driver.Value{name.Catalog(), name.Schema(), name.Table()})
 	if err != nil {
 		if err == io.EOF {
 			return basicMetadata{}, errors.Wrap(
@@ -350,7 +459,7 @@ func getBasicMetadata(conn *sqlConn, dbName, tableName string, ts string) (basic
 
 	md := basicMetadata{
 		ID:         id,
-		name:       tree.NewTableName(tree.Name(dbName), tree.Name(tableName)),
+		name:       name,
 		createStmt: createStatement,
 		dependsOn:  refs,
 		kind:       kind,
@@ -527,7 +636,7 @@ func dumpSequenceData(w io.Writer, conn *sqlConn, clusterTS string, bmd basicMet
 	// given out is 1.
 	fmt.Fprintf(
 		w, "SELECT setval(%s, %d, false);\n",
-		lex.EscapeSQLString(tree.NameString(bmd.name.Table())), seqVal+seqInc,
+		lex.EscapeSQLString(bmd.dumpName().String()), seqVal+seqInc,
 	)
 
 	return nil
@@ -703,7 +812,7 @@ func dumpTableData(w io.Writer, conn *sqlConn, clusterTS string, bmd basicMetada
 }
 
 func writeInserts(w io.Writer, tmd tableMetadata, inserts []string) {
-	fmt.Fprintf(w, "\nINSERT INTO %s (%s) VALUES", &tmd.name.TableName, tmd.columnNames)
+	fmt.Fprintf(w, "\nINSERT INTO %s (%s) VALUES", tmd.dumpName(), tmd.columnNames)
 	for idx, values := range inserts {
 		if idx > 0 {
 			fmt.Fprint(w, ",")
@@ -182,7 +182,7 @@ func (b *Batch) fillResults(ctx context.Context) {
 			case *roachpb.PutRequest:
 				row := &result.Rows[k]
 				row.Key = []byte(req.Key)
-				if result.Err == nil {
+				if result.Err == nil && !req.LockOnly {
 					row.Value = &req.Value
 				}
 			case *roachpb.ConditionalPutRequest:
@@ -536,6 +536,29 @@ func (b *Batch) Del(keys ...interface{}) {
 	b.initResult(len(reqs), len(reqs), notRaw, nil)
 }
 
+// Lock acquires locks on one or more keys for the batch's transaction
+// without changing their values. The locks are released when the
+// transaction commits or aborts. Locks can only be acquired within
+// transactions.
+//
+// A new result will be appended to the batch and each key will have a
+// corresponding row in the returned Result.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) Lock(keys ...interface{}) {
+	reqs := make([]roachpb.Request, 0, len(keys))
+	for _, key := range keys {
+		k, err := marshalKey(key)
+		if err != nil {
+			b.initResult(0, len(keys), notRaw, err)
+			return
+		}
+		reqs = append(reqs, roachpb.NewPutLock(k))
+	}
+	b.appendReqs(reqs...)
+	b.initResult(len(reqs), len(reqs), notRaw, nil)
+}
+
 // DelRange deletes the rows between begin (inclusive) and end (exclusive).
 //
 // A new result will be appended to the batch which will contain 0 rows and
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
@@ -135,6 +136,23 @@ type TxnSender interface {
 	// SetDebugName sets the txn's debug name.
 	SetDebugName(name string)
 
+	// SetIsolation sets the txn's isolation level. The isolation level cannot
+	// be changed once the txn has performed any operations.
+	SetIsolation(enginepb.IsolationType) error
+
+	// AdvanceReadTimestamp moves the read timestamp of a READ_COMMITTED txn
+	// forward to the current time, so that subsequent reads observe all the
+	// writes committed before the call. The reads performed at the previous
+	// read timestamp are not refreshed if the txn's timestamp is pushed. If the
+	// txn's commit timestamp has been observed, the read timestamp is left
+	// unchanged.
+	//
+	// Errors encountered after the read timestamp has been advanced do not
+	// restart a READ_COMMITTED txn: the epoch is preserved, and the caller is
+	// expected to roll back to a savepoint established after the call and to
+	// advance the read timestamp again before retrying its operations.
+	AdvanceReadTimestamp(context.Context) error
+
 	// TxnStatus exports the txn's status.
 	TxnStatus() roachpb.TransactionStatus
 
@@ -177,6 +195,19 @@ type TxnSender interface {
 	// TODO(nvanbenschoten): Fix up flaky tests to allow us to get rid of this.
 	EagerRecord() error
 
+	// CreateSavepoint establishes a savepoint of the transaction. The writes
+	// performed after the savepoint can later be rolled back with
+	// RollbackToSavepoint.
+	CreateSavepoint(context.Context) (SavepointToken, error)
+
+	// RollbackToSavepoint rolls back the writes performed since the given
+	// savepoint was created. The savepoint remains valid afterwards, and can be
+	// rolled back to again. Rolling back to a savepoint makes the transaction
+	// usable again after a non-retriable error. Savepoints don't survive
+	// restarts of the transaction: rolling back to a savepoint created at an
+	// earlier epoch returns an error.
+	RollbackToSavepoint(context.Context, SavepointToken) error
+
 	// OrigTimestamp returns the transaction's starting timestamp.
 	// Note a transaction can be internally pushed forward in time before
 	// committing so this is not guaranteed to be the commit timestamp.
@@ -217,6 +248,10 @@ type TxnSender interface {
 	SerializeTxn() *roachpb.Transaction
 }
 
+// SavepointToken represents a savepoint of a transaction. It is created by
+// TxnSender.CreateSavepoint and is opaque to its users.
+type SavepointToken interface{}
+
 // TxnStatusOpt represents options for TxnSender.GetMeta().
 type TxnStatusOpt int
 
@@ -323,6 +358,17 @@ func (m *MockTransactionalSender) SetDebugName(name string) {
 	m.txn.Name = name
 }
 
+// SetIsolation is part of the TxnSender interface.
+func (m *MockTransactionalSender) SetIsolation(iso enginepb.IsolationType) error {
+	m.txn.Isolation = iso
+	return nil
+}
+
+// AdvanceReadTimestamp is part of the TxnSender interface.
+func (m *MockTransactionalSender) AdvanceReadTimestamp(context.Context) error {
+	panic("unimplemented")
+}
+
 // OrigTimestamp is part of the TxnSender interface.
 func (m *MockTransactionalSender) OrigTimestamp() hlc.Timestamp {
 	return m.txn.OrigTimestamp
@@ -377,6 +423,16 @@ func (m *MockTransactionalSender) DisablePipelining() error { return nil }
 // EagerRecord is part of the client.TxnSender interface.
 func (m *MockTransactionalSender) EagerRecord() error { return nil }
 
+// CreateSavepoint is part of the TxnSender interface.
+func (m *MockTransactionalSender) CreateSavepoint(context.Context) (SavepointToken, error) {
+	panic("unimplemented")
+}
+
+// RollbackToSavepoint is part of the TxnSender interface.
+func (m *MockTransactionalSender) RollbackToSavepoint(context.Context, SavepointToken) error {
+	panic("unimplemented")
+}
+
 // MockTxnSenderFactory is a TxnSenderFactory producing MockTxnSenders.
 type MockTxnSenderFactory struct {
 	senderFunc func(context.Context, *roachpb.Transaction, roachpb.BatchRequest) (
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -62,6 +63,10 @@ type Txn struct {
 		// NormalUserPriority will be used.
 		userPriority roachpb.UserPriority
 
+		// isolation is the transaction's isolation level. Transactions default
+		// to SERIALIZABLE.
+		isolation enginepb.IsolationType
+
 		// previousIDs holds the set of all previous IDs that the Txn's Proto has
 		// had across transaction aborts. This allows us to determine if a given
 		// response was meant for any incarnation of this transaction. This is
@@ -218,6 +223,39 @@ func (txn *Txn) UserPriority() roachpb.UserPriority {
 	return txn.mu.userPriority
 }
 
+// SetIsolation sets the transaction's isolation level. Transactions default to
+// SERIALIZABLE isolation. The isolation level must be set before any operations
+// are performed on the transaction.
+func (txn *Txn) SetIsolation(iso enginepb.IsolationType) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	if txn.mu.isolation == iso {
+		return nil
+	}
+	if err := txn.mu.sender.SetIsolation(iso); err != nil {
+		return err
+	}
+	txn.mu.isolation = iso
+	return nil
+}
+
+// Isolation returns the transaction's isolation level.
+func (txn *Txn) Isolation() enginepb.IsolationType {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.isolation
+}
+
+// AdvanceReadTimestamp moves the read timestamp of a READ_COMMITTED
+// transaction forward to the current time. It is called before each statement
+// of the transaction, so that each statement reads from a fresh snapshot. See
+// TxnSender.AdvanceReadTimestamp for details.
+func (txn *Txn) AdvanceReadTimestamp(ctx context.Context) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.AdvanceReadTimestamp(ctx)
+}
+
 // SetDebugName sets the debug name associated with the transaction which will
 // appear in log files and the web UI.
 func (txn *Txn) SetDebugName(name string) {
@@ -305,6 +343,22 @@ func (txn *Txn) EagerRecord() error {
 	return txn.mu.sender.EagerRecord()
 }
 
+// CreateSavepoint establishes a savepoint of the transaction, to which its
+// writes can later be rolled back with RollbackToSavepoint.
+func (txn *Txn) CreateSavepoint(ctx context.Context) (SavepointToken, error) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.CreateSavepoint(ctx)
+}
+
+// RollbackToSavepoint rolls back the writes performed by the transaction since
+// the given savepoint was created.
+func (txn *Txn) RollbackToSavepoint(ctx context.Context, s SavepointToken) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.RollbackToSavepoint(ctx, s)
+}
+
 // NewBatch creates and returns a new empty batch object for use with the Txn.
 func (txn *Txn) NewBatch() *Batch {
 	return &Batch{txn: txn}
@@ -737,6 +791,13 @@ func (txn *Txn) exec(ctx context.Context, fn func(context.Context, *Txn) error)
 				return errors.Wrapf(err, "retryable error from another txn")
 			}
 			retryable = true
+			if txn.Isolation() == enginepb.READ_COMMITTED && t.Transaction.ID == txn.ID() {
+				// Retryable errors don't restart READ_COMMITTED transactions, as
+				// they are generally handled by retrying only the statement that
+				// encountered them. The closure is retried in its entirety, so the
+				// transaction has to be restarted.
+				txn.ManualRestart(ctx, t.Transaction.Timestamp)
+			}
 		}
 
 		if !retryable {
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -176,6 +177,8 @@ type DistSender struct {
 	nodeDialer       *nodedialer.Dialer
 	rpcRetryOptions  retry.Options
 	asyncSenderSem   chan struct{}
+	// localClosedTimestamp is DistSenderConfig.LocalClosedTimestamp.
+	localClosedTimestamp func(roachpb.RangeID) (hlc.Timestamp, bool)
 
 	// disableFirstRangeUpdates disables updates of the first range via
 	// gossip. Used by tests which want finer control of the contents of the
@@ -202,6 +205,12 @@ type DistSenderConfig struct {
 
 	NodeDialer *nodedialer.Dialer
 
+	// LocalClosedTimestamp, if set, returns the maximum closed timestamp of the
+	// replica of the given range held by the local node, if there is one. Reads
+	// below it are sent to the local replica, which can serve them as follower
+	// reads.
+	LocalClosedTimestamp func(roachpb.RangeID) (hlc.Timestamp, bool)
+
 	TestingKnobs ClientTestingKnobs
 }
 
@@ -216,6 +225,8 @@ func NewDistSender(cfg DistSenderConfig, g *gossip.Gossip) *DistSender {
 		gossip:     g,
 		metrics:    makeDistSenderMetrics(),
 		nodeDialer: cfg.NodeDialer,
+
+		localClosedTimestamp: cfg.LocalClosedTimestamp,
 	}
 	if ds.st == nil {
 		ds.st = cluster.MakeTestingClusterSettings()
@@ -386,6 +397,8 @@ func (ds *DistSender) sendRPC(
 	replicas ReplicaSlice,
 	ba roachpb.BatchRequest,
 	cachedLeaseHolder roachpb.ReplicaDescriptor,
+	canSendToFollower bool,
+	withCommit bool,
 ) (*roachpb.BatchResponse, error) {
 	if len(replicas) == 0 {
 		return nil, roachpb.NewSendError(
@@ -399,15 +412,57 @@ func (ds *DistSender) sendRPC(
 
 	return ds.sendToReplicas(
 		ctx,
-		SendOptions{metrics: &ds.metrics},
+		SendOptions{metrics: &ds.metrics, canSendToFollower: canSendToFollower},
 		rangeID,
 		replicas,
 		ba,
 		ds.nodeDialer,
 		cachedLeaseHolder,
+		withCommit,
 	)
 }
 
+// canSendToFollower returns whether the batch can be sent to the nearest
+// replica of a range rather than to its lease holder, in the expectation that
+// the batch's timestamp is below the range's closed timestamp and so can be
+// served by any replica as a follower read. This is the case for read-only
+// batches whose timestamp, including the uncertainty interval of their
+// transaction, is below the closed timestamp of the local replica of the range
+// or trails the present by at least closedts.FollowerReadOffset.
+//
+// Transactions which have written are excluded, as their commit timestamp is
+// going to be above the closed timestamp anyway.
+func (ds *DistSender) canSendToFollower(ba roachpb.BatchRequest, rangeID roachpb.RangeID) bool {
+	if !closedts.FollowerReadsEnabled.Get(&ds.st.SV) || !ba.IsReadOnly() {
+		return false
+	}
+	ts := ba.Timestamp
+	if ba.Txn != nil {
+		if ba.Txn.Writing {
+			return false
+		}
+		ts.Forward(ba.Txn.MaxTimestamp)
+	}
+	if closed, ok := ds.LocalClosedTimestamp(rangeID); ok && !closed.Less(ts) {
+		return true
+	}
+	offset := closedts.FollowerReadOffset(&ds.st.SV)
+	if offset == 0 {
+		return false
+	}
+	return ts.Less(ds.clock.Now().Add(-offset.Nanoseconds(), 0))
+}
+
+// LocalClosedTimestamp returns the maximum closed timestamp of the replica of
+// the given range held by the local node. False is returned if the local node
+// doesn't hold a replica of the range or if this information isn't available.
+func (ds *DistSender) LocalClosedTimestamp(rangeID roachpb.RangeID) (hlc.Timestamp, bool) {
+	if ds.localClosedTimestamp == nil {
+		return hlc.Timestamp{}, false
+	}
+	return ds.localClosedTimestamp(rangeID)
+}
+
 // CountRanges returns the number of ranges that encompass the given key span.
 func (ds *DistSender) CountRanges(ctx context.Context, rs roachpb.RSpan) (int64, error) {
 	var count int64
@@ -449,15 +504,18 @@ func (ds *DistSender) getDescriptor(
 
 // sendSingleRange gathers and rearranges the replicas, and makes an RPC call.
 func (ds *DistSender) sendSingleRange(
-	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor,
+	ctx context.Context, ba roachpb.BatchRequest, desc *roachpb.RangeDescriptor, withCommit bool,
 ) (*roachpb.BatchResponse, *roachpb.Error) {
 	// Try to send the call.
 	replicas := NewReplicaSlice(ds.gossip, desc)
 
 	// If this request needs to go to a lease holder and we know who that is, move
-	// it to the front.
+	// it to the front. Requests which can be served by followers are instead sent
+	// to the nearest replica; if it can't serve them after all, it redirects us
+	// to the lease holder.
 	var cachedLeaseHolder roachpb.ReplicaDescriptor
-	if ba.RequiresLeaseHolder() {
+	canSendToFollower := ba.RequiresLeaseHolder() && ds.canSendToFollower(ba, desc.RangeID)
+	if ba.RequiresLeaseHolder() && !canSendToFollower {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
 				replicas.MoveToFront(i)
@@ -475,7 +533,9 @@ func (ds *DistSender) sendSingleRange(
 		replicas.OptimizeReplicaOrder(ds.getNodeDescriptor(), latencyFn)
 	}
 
-	br, err := ds.sendRPC(ctx, desc.RangeID, replicas, ba, cachedLeaseHolder)
+	br, err := ds.sendRPC(
+		ctx, desc.RangeID, replicas, ba, cachedLeaseHolder, canSendToFollower, withCommit,
+	)
 	if err != nil {
 		log.VErrEvent(ctx, 2, err.Error())
 		return nil, roachpb.NewError(err)
@@ -688,8 +748,15 @@ func (ds *DistSender) Send(
 			return nil, roachpb.NewError(err)
 		}
 
+		// Determine whether this part of the BatchRequest contains a committing
+		// EndTransaction request.
+		var withCommit bool
+		if etArg, ok := ba.GetArg(roachpb.EndTransaction); ok {
+			withCommit = etArg.(*roachpb.EndTransactionRequest).Commit
+		}
+
 		var rpl *roachpb.BatchResponse
-		rpl, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, 0 /* batchIdx */)
+		rpl, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, withCommit, 0 /* batchIdx */)
 
 		if pErr == errNo1PCTxn {
 			// If we tried to send a single round-trip EndTransaction but
@@ -750,9 +817,11 @@ type response struct {
 // either serially or in parallel, if possible. batchIdx indicates
 // which partial fragment of the larger batch is being processed by
 // this method. It's specified as non-zero when this method is invoked
-// recursively.
+// recursively. withCommit indicates that the batch contains a committing
+// EndTransaction, in which case the outcome of any of its partial batches may
+// be ambiguous.
 func (ds *DistSender) divideAndSendBatchToRanges(
-	ctx context.Context, ba roachpb.BatchRequest, rs roachpb.RSpan, batchIdx int,
+	ctx context.Context, ba roachpb.BatchRequest, rs roachpb.RSpan, withCommit bool, batchIdx int,
 ) (br *roachpb.BatchResponse, pErr *roachpb.Error) {
 	// Clone the BatchRequest's transaction so that future mutations to the
 	// proto don't affect the proto in this batch.
@@ -777,7 +846,9 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 	}
 	// Take the fast path if this batch fits within a single range.
 	if !ri.NeedAnother(rs) {
-		resp := ds.sendPartialBatch(ctx, ba, rs, ri.Desc(), ri.Token(), batchIdx, false /* needsTruncate */)
+		resp := ds.sendPartialBatch(
+			ctx, ba, rs, ri.Desc(), ri.Token(), withCommit, batchIdx, false, /* needsTruncate */
+		)
 		return resp.reply, resp.pErr
 	}
 
@@ -899,10 +970,15 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 			}
 			// If the request is more than but ends with EndTransaction, we
 			// want the caller to come again with the EndTransaction in an
-			// extra call.
+			// extra call. Parallel commits are an exception, as their
+			// EndTransaction is meant to be sent in parallel with the rest of
+			// the batch.
 			if l := len(ba.Requests) - 1; l > 0 && ba.Requests[l].GetInner().Method() == roachpb.EndTransaction {
-				responseCh <- response{pErr: errNo1PCTxn}
-				return
+				et := ba.Requests[l].GetInner().(*roachpb.EndTransactionRequest)
+				if !et.IsParallelCommit() {
+					responseCh <- response{pErr: errNo1PCTxn}
+					return
+				}
 			}
 		}
 
@@ -938,10 +1014,14 @@ func (ds *DistSender) divideAndSendBatchToRanges(
 		// If we can reserve one of the limited goroutines available for parallel
 		// batch RPCs, send asynchronously.
 		if canParallelize && !lastRange && ds.rpcContext != nil &&
-			ds.sendPartialBatchAsync(ctx, ba, rs, ri.Desc(), ri.Token(), batchIdx, responseCh) {
+			ds.sendPartialBatchAsync(
+				ctx, ba, rs, ri.Desc(), ri.Token(), withCommit, batchIdx, responseCh,
+			) {
 			// Sent the batch asynchronously.
 		} else {
-			resp := ds.sendPartialBatch(ctx, ba, rs, ri.Desc(), ri.Token(), batchIdx, true /* needsTruncate */)
+			resp := ds.sendPartialBatch(
+				ctx, ba, rs, ri.Desc(), ri.Token(), withCommit, batchIdx, true, /* needsTruncate */
+			)
 			responseCh <- resp
 			if resp.pErr != nil {
 				return
@@ -1032,6 +1112,7 @@ func (ds *DistSender) sendPartialBatchAsync(
 	rs roachpb.RSpan,
 	desc *roachpb.RangeDescriptor,
 	evictToken *EvictionToken,
+	withCommit bool,
 	batchIdx int,
 	responseCh chan response,
 ) bool {
@@ -1040,7 +1121,9 @@ func (ds *DistSender) sendPartialBatchAsync(
 		ds.asyncSenderSem, false, /* wait */
 		func(ctx context.Context) {
 			ds.metrics.AsyncSentCount.Inc(1)
-			responseCh <- ds.sendPartialBatch(ctx, ba, rs, desc, evictToken, batchIdx, true /* needsTruncate */)
+			responseCh <- ds.sendPartialBatch(
+				ctx, ba, rs, desc, evictToken, withCommit, batchIdx, true, /* needsTruncate */
+			)
 		},
 	); err != nil {
 		ds.metrics.AsyncThrottledCount.Inc(1)
@@ -1066,6 +1149,7 @@ func (ds *DistSender) sendPartialBatch(
 	rs roachpb.RSpan,
 	desc *roachpb.RangeDescriptor,
 	evictToken *EvictionToken,
+	withCommit bool,
 	batchIdx int,
 	needsTruncate bool,
 ) response {
@@ -1118,7 +1202,7 @@ func (ds *DistSender) sendPartialBatch(
 			}
 		}
 
-		reply, pErr = ds.sendSingleRange(ctx, ba, desc)
+		reply, pErr = ds.sendSingleRange(ctx, ba, desc, withCommit)
 
 		// If sending succeeded, return immediately.
 		if pErr == nil {
@@ -1184,7 +1268,7 @@ func (ds *DistSender) sendPartialBatch(
 			// batch here would give a potentially larger response slice
 			// with unknown mapping to our truncated reply).
 			log.VEventf(ctx, 1, "likely split; resending batch to span: %s", tErr)
-			reply, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, batchIdx)
+			reply, pErr = ds.divideAndSendBatchToRanges(ctx, ba, rs, withCommit, batchIdx)
 			return response{reply: reply, positions: positions, pErr: pErr}
 		}
 		break
@@ -1311,7 +1395,9 @@ func fillSkippedResponses(
 // slice of replicas. On success, Send returns the first successful
 // reply. If an error occurs which is not specific to a single
 // replica, it's returned immediately. Otherwise, when all replicas
-// have been tried and failed, returns a send error.
+// have been tried and failed, returns a send error. withCommit
+// indicates that the batch is part of a batch containing a committing
+// EndTransaction, in which case RPC errors may be ambiguous.
 func (ds *DistSender) sendToReplicas(
 	ctx context.Context,
 	opts SendOptions,
@@ -1320,14 +1406,13 @@ func (ds *DistSender) sendToReplicas(
 	ba roachpb.BatchRequest,
 	nodeDialer *nodedialer.Dialer,
 	cachedLeaseHolder roachpb.ReplicaDescriptor,
+	withCommit bool,
 ) (*roachpb.BatchResponse, error) {
-	var ambiguousError error
-	var haveCommit bool
 	// We only check for committed txns, not aborts because aborts may
-	// be retried without any risk of inconsistencies.
-	if etArg, ok := ba.GetArg(roachpb.EndTransaction); ok {
-		haveCommit = etArg.(*roachpb.EndTransactionRequest).Commit
-	}
+	// be retried without any risk of inconsistencies. The writes sent in
+	// parallel with a parallel commit are checked as well, because the
+	// transaction is implicitly committed as soon as all of them succeed.
+	var ambiguousError error
 
 	transport, err := ds.transportFactory(opts, nodeDialer, replicas)
 	if err != nil {
@@ -1368,7 +1453,7 @@ func (ds *DistSender) sendToReplicas(
 			// guaranteed to return an error. If the original attempt merely timed out
 			// or was lost, then the batch will succeed and we can be assured the
 			// commit was applied just once.
-			if haveCommit && !grpcutil.RequestDidNotStart(err) {
+			if withCommit && !grpcutil.RequestDidNotStart(err) {
 				ambiguousError = err
 			}
 			log.VErrEventf(ctx, 2, "RPC error: %s", err)
@@ -1400,8 +1485,10 @@ func (ds *DistSender) sendToReplicas(
 				// back as successful, make sure the leaseholder cache reflects this
 				// replica. In steady state, this is almost always the case, and so we
 				// gate the update on whether the response comes from a node that we didn't
-				// know held the lease.
-				if cachedLeaseHolder != curReplica && ba.RequiresLeaseHolder() {
+				// know held the lease. Follower reads may have been served by any
+				// replica, so they tell us nothing about the lease holder.
+				if cachedLeaseHolder != curReplica && ba.RequiresLeaseHolder() &&
+					!opts.canSendToFollower {
 					ds.leaseHolderCache.Update(ctx, rangeID, curReplica.StoreID)
 				}
 				return br, nil
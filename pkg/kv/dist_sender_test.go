@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -229,6 +230,13 @@ func TestSendRPCOrder(t *testing.T) {
 		// Likely a test setup here will never have a read lease, but good
 		// to keep in mind.
 		consistent bool
+		// followerRead is set for reads at a timestamp old enough for them to
+		// be served by any replica.
+		followerRead bool
+		// localClosed is set if the local replica reports a closed timestamp
+		// covering the read, which is otherwise too recent to be a follower
+		// read.
+		localClosed bool
 	}{
 		// Inconsistent Scan without matching attributes.
 		{
@@ -288,6 +296,36 @@ func TestSendRPCOrder(t *testing.T) {
 			expReplica:  []roachpb.NodeID{1, 2, 3, 4, 5},
 			leaseHolder: 2,
 		},
+		// Consistent Get with matching attributes that finds the lease holder.
+		// Should address the lease holder.
+		{
+			args:        &roachpb.GetRequest{},
+			tiers:       nodeTiers[5],
+			expReplica:  []roachpb.NodeID{2, 0, 0, 0, 0},
+			leaseHolder: 2,
+			consistent:  true,
+		},
+		// Consistent follower read with matching attributes and a lease holder.
+		// Should move the two nodes matching the attributes to the front, like
+		// an inconsistent read.
+		{
+			args:         &roachpb.GetRequest{},
+			tiers:        nodeTiers[5],
+			expReplica:   []roachpb.NodeID{5, 4, 0, 0, 0},
+			leaseHolder:  2,
+			consistent:   true,
+			followerRead: true,
+		},
+		// Consistent recent read covered by the closed timestamp of the local
+		// replica. Should be treated like a follower read.
+		{
+			args:        &roachpb.GetRequest{},
+			tiers:       nodeTiers[5],
+			expReplica:  []roachpb.NodeID{5, 4, 0, 0, 0},
+			leaseHolder: 2,
+			consistent:  true,
+			localClosed: true,
+		},
 	}
 
 	descriptor := roachpb.RangeDescriptor{
@@ -328,14 +366,23 @@ func TestSendRPCOrder(t *testing.T) {
 		return args.CreateReply(), nil
 	}
 
+	// Closed timestamp of the local replica, set in each test case.
+	var localClosed hlc.Timestamp
+
+	st := cluster.MakeTestingClusterSettings()
+	closedts.FollowerReadsEnabled.Override(&st.SV, true)
 	cfg := DistSenderConfig{
 		AmbientCtx: log.AmbientContext{Tracer: tracing.NewTracer()},
+		Settings:   st,
 		Clock:      clock,
 		TestingKnobs: ClientTestingKnobs{
 			TransportFactory: adaptSimpleTransport(testFn),
 		},
 		RangeDescriptorDB: mockRangeDescriptorDBForDescs(descriptor),
 		NodeDialer:        nodedialer.New(nil, gossip.AddressResolver(g)),
+		LocalClosedTimestamp: func(roachpb.RangeID) (hlc.Timestamp, bool) {
+			return localClosed, !localClosed.IsEmpty()
+		},
 	}
 
 	ds := NewDistSender(cfg, g)
@@ -383,14 +430,32 @@ func TestSendRPCOrder(t *testing.T) {
 		if !tc.consistent {
 			consistency = roachpb.INCONSISTENT
 		}
+		var ts hlc.Timestamp
+		if tc.followerRead {
+			ts = clock.Now().Add(-time.Hour.Nanoseconds(), 0)
+		}
+		localClosed = hlc.Timestamp{}
+		if tc.localClosed {
+			ts = clock.Now()
+			localClosed = ts
+		}
 		// Kill the cached NodeDescriptor, enforcing a lookup from Gossip.
 		ds.nodeDescriptor = nil
 		if _, err := client.SendWrappedWith(context.Background(), ds, roachpb.Header{
 			RangeID:         rangeID, // Not used in this test, but why not.
 			ReadConsistency: consistency,
+			Timestamp:       ts,
 		}, args); err != nil {
 			t.Errorf("%d: %s", n, err)
 		}
+		// Replies from other replicas than the lease holder must not have
+		// updated the lease holder cache.
+		if tc.leaseHolder > 0 {
+			expStoreID := descriptor.Replicas[tc.leaseHolder-1].StoreID
+			if storeID, _ := ds.leaseHolderCache.Lookup(context.TODO(), rangeID); storeID != expStoreID {
+				t.Errorf("%d: expected lease holder s%d, found s%d", n, expStoreID, storeID)
+			}
+		}
 	}
 }
 
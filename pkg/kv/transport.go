@@ -36,6 +36,10 @@ import (
 // responses are required.
 type SendOptions struct {
 	metrics *DistSenderMetrics
+	// canSendToFollower is set for batches which can be served by any of the
+	// replicas, and not just by the lease holder. See
+	// DistSender.canSendToFollower.
+	canSendToFollower bool
 }
 
 type batchClient struct {
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -141,12 +142,13 @@ type TxnCoordSender struct {
 	// is embedded in the interceptorAlloc struct, so the entire stack is
 	// allocated together with TxnCoordSender without any additional heap
 	// allocations necessary.
-	interceptorStack [6]txnInterceptor
+	interceptorStack [7]txnInterceptor
 	interceptorAlloc struct {
 		txnHeartbeat
 		txnIntentCollector
 		txnPipeliner
 		txnSpanRefresher
+		txnCommitter
 		txnSeqNumAllocator
 		txnMetrics
 		txnLockGatekeeper // not in interceptorStack array.
@@ -443,6 +445,11 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		canAutoRetry:     typ == client.RootTxn,
 		autoRetryCounter: tcs.metrics.AutoRetries,
 	}
+	tcs.interceptorAlloc.txnCommitter = txnCommitter{
+		st:      tcf.st,
+		stopper: tcs.stopper,
+		mu:      &tcs.mu.Mutex,
+	}
 	tcs.interceptorAlloc.txnLockGatekeeper = txnLockGatekeeper{
 		wrapped: tcs.wrapped,
 		mu:      &tcs.mu,
@@ -457,6 +464,10 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 		&tcs.interceptorAlloc.txnIntentCollector,
 		&tcs.interceptorAlloc.txnPipeliner,
 		&tcs.interceptorAlloc.txnSpanRefresher,
+		// The committer is below the span refresher so that the refresher can
+		// handle the retry errors returned by a parallel commit that fails to
+		// implicitly commit the transaction.
+		&tcs.interceptorAlloc.txnCommitter,
 		&tcs.interceptorAlloc.txnMetrics,
 	}
 	for i, reqInt := range tcs.interceptorStack {
@@ -686,7 +697,13 @@ func (tc *TxnCoordSender) Send(
 			retriable = true
 		}
 
-		if !retriable {
+		// Requests that don't wait on conflicting intents return the conflicts
+		// to the client, which can handle them and continue to use the
+		// transaction.
+		_, conflict := pErr.GetDetail().(*roachpb.WriteIntentError)
+		conflict = conflict && ba.WaitPolicy == roachpb.WaitPolicy_Error
+
+		if !retriable && !conflict {
 			tc.mu.txnState = txnError
 		}
 
@@ -836,6 +853,16 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 		}
 	}
 	errTxnID := pErr.GetTxn().ID
+	if _, aborted := pErr.GetDetail().(*roachpb.TransactionAbortedError); !aborted &&
+		tc.mu.txn.Isolation == enginepb.READ_COMMITTED {
+		// READ_COMMITTED transactions don't restart on retryable errors. The
+		// epoch is preserved so that the client can roll back to the savepoint
+		// it established at the beginning of the statement which encountered
+		// the error and retry the statement at a new read timestamp. See
+		// client.TxnSender.AdvanceReadTimestamp.
+		newTxn := roachpb.PrepareTransactionForStatementRetry(ctx, pErr)
+		return roachpb.NewTransactionRetryWithProtoRefreshError(pErr.Message, errTxnID, newTxn)
+	}
 	newTxn := roachpb.PrepareTransactionForRetry(ctx, pErr, tc.mu.userPriority, tc.clock)
 
 	// We'll pass a TransactionRetryWithProtoRefreshError up to the next layer.
@@ -971,6 +998,47 @@ func (tc *TxnCoordSender) SetUserPriority(pri roachpb.UserPriority) error {
 	return nil
 }
 
+// SetIsolation is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) SetIsolation(iso enginepb.IsolationType) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.mu.txn.Isolation == iso {
+		return nil
+	}
+	if tc.mu.active {
+		return errors.Errorf("cannot change the isolation level of a running transaction")
+	}
+	tc.mu.txn.Isolation = iso
+	return nil
+}
+
+// AdvanceReadTimestamp is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) AdvanceReadTimestamp(ctx context.Context) error {
+	if tc.typ != client.RootTxn {
+		return errors.Errorf("cannot advance the read timestamp of a leaf transaction")
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.mu.txn.Isolation != enginepb.READ_COMMITTED {
+		return errors.Errorf("cannot advance the read timestamp of a %s transaction",
+			tc.mu.txn.Isolation)
+	}
+	if pErr := tc.maybeRejectClientLocked(ctx, nil /* ba */); pErr != nil {
+		return pErr.GoError()
+	}
+	if tc.mu.txn.OrigTimestampWasObserved {
+		// The transaction must commit at the timestamp that was observed, so all
+		// of its reads must be performed at that timestamp as well.
+		return nil
+	}
+	tc.mu.txn.AdvanceReadTimestamp(tc.clock.Now(), tc.clock.MaxOffset().Nanoseconds())
+	tc.interceptorAlloc.txnSpanRefresher.readTimestampAdvancedLocked()
+	return nil
+}
+
 // SetDebugName is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) SetDebugName(name string) {
 	tc.mu.Lock()
@@ -1048,6 +1116,11 @@ func (tc *TxnCoordSender) IsSerializablePushAndRefreshNotPossible() bool {
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 
+	if tc.mu.txn.Isolation == enginepb.READ_COMMITTED {
+		// READ_COMMITTED transactions don't need to refresh their reads in order
+		// to commit at a pushed timestamp.
+		return false
+	}
 	origTimestamp := tc.mu.txn.OrigTimestamp
 	origTimestamp.Forward(tc.mu.txn.RefreshedTimestamp)
 	isTxnPushed := tc.mu.txn.Timestamp != origTimestamp
@@ -0,0 +1,146 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// savepoint is the client.SavepointToken of the TxnCoordSender. It records
+// the sequence number of the last write performed by the transaction before
+// the savepoint was created.
+//
+// Rolling back to a savepoint ignores all the writes performed at higher
+// sequence numbers. The intents of these writes are rewritten eagerly: each
+// intent is reverted to its latest write which isn't ignored, using the
+// intent history, or removed if there is none. The ignored sequence numbers
+// thus never have to be consulted by readers of the intents.
+type savepoint struct {
+	txnID  uuid.UUID
+	epoch  uint32
+	seqNum int32
+}
+
+// CreateSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) CreateSavepoint(ctx context.Context) (client.SavepointToken, error) {
+	if tc.typ != client.RootTxn {
+		return nil, errors.Errorf("cannot create savepoints in leaf transactions")
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if pErr := tc.maybeRejectClientLocked(ctx, nil /* ba */); pErr != nil {
+		return nil, pErr.GoError()
+	}
+	return &savepoint{
+		txnID:  tc.mu.txn.ID,
+		epoch:  tc.mu.txn.Epoch,
+		seqNum: tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter,
+	}, nil
+}
+
+// RollbackToSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) RollbackToSavepoint(
+	ctx context.Context, token client.SavepointToken,
+) (retErr error) {
+	if tc.typ != client.RootTxn {
+		return errors.Errorf("cannot roll back to savepoints in leaf transactions")
+	}
+	sp, ok := token.(*savepoint)
+	if !ok {
+		return errors.Errorf("unexpected savepoint token %T", token)
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	// Rolling back to a savepoint is allowed after a non-retriable error (for
+	// example a ConditionFailedError), as the writes of the failed batch are
+	// rolled back along with the other writes performed since the savepoint.
+	if tc.mu.txnState == txnError {
+		tc.mu.txnState = txnPending
+		defer func() {
+			if retErr != nil && tc.mu.txnState == txnPending {
+				tc.mu.txnState = txnError
+			}
+		}()
+	}
+	if pErr := tc.maybeRejectClientLocked(ctx, nil /* ba */); pErr != nil {
+		return pErr.GoError()
+	}
+	if sp.txnID != tc.mu.txn.ID || sp.epoch != tc.mu.txn.Epoch {
+		return errors.Errorf("cannot roll back to a savepoint established before a transaction restart")
+	}
+
+	seqNum := tc.interceptorAlloc.txnSeqNumAllocator.seqNumCounter
+	if seqNum == sp.seqNum {
+		// There were no writes since the savepoint.
+		return nil
+	}
+	ignored := []enginepb.IgnoredSeqNumRange{{Start: sp.seqNum + 1, End: seqNum}}
+	log.VEventf(ctx, 2, "rolling back to savepoint, ignoring sequence numbers %d-%d",
+		ignored[0].Start, ignored[0].End)
+
+	// Make sure that all the pipelined writes have succeeded before rolling
+	// them back. Otherwise, a write which is still being replicated could
+	// lay down its intent after the rollback.
+	txn := tc.mu.txn.Clone()
+	if pErr := tc.interceptorAlloc.txnPipeliner.proveOutstandingWritesLocked(ctx, &txn); pErr != nil {
+		ba := roachpb.BatchRequest{}
+		ba.Txn = &txn
+		pErr = tc.updateStateLocked(ctx, 0 /* startNS */, ba, nil /* br */, pErr)
+		if _, ok := pErr.GetDetail().(*roachpb.TransactionRetryWithProtoRefreshError); !ok {
+			tc.mu.txnState = txnError
+		}
+		return pErr.GoError()
+	}
+
+	// Rewrite the intents written since the savepoint. The ResolveIntent
+	// requests are non-transactional, so they bypass the interceptors.
+	var ba roachpb.BatchRequest
+	for _, span := range tc.interceptorAlloc.txnIntentCollector.intents {
+		if len(span.EndKey) == 0 {
+			ba.Add(&roachpb.ResolveIntentRequest{
+				RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+				IntentTxn:      txn.TxnMeta,
+				Status:         roachpb.PENDING,
+				IgnoredSeqNums: ignored,
+			})
+		} else {
+			ba.Add(&roachpb.ResolveIntentRangeRequest{
+				RequestHeader:  roachpb.RequestHeaderFromSpan(span),
+				IntentTxn:      txn.TxnMeta,
+				Status:         roachpb.PENDING,
+				IgnoredSeqNums: ignored,
+			})
+		}
+	}
+	if len(ba.Requests) == 0 {
+		return nil
+	}
+	if _, pErr := tc.interceptorAlloc.txnLockGatekeeper.SendLocked(ctx, ba); pErr != nil {
+		// Some of the intents may not have been rolled back, so the transaction
+		// must not be allowed to commit.
+		tc.mu.txnState = txnError
+		return pErr.GoError()
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestTxnCoordSenderRollbackToSavepoint verifies that rolling back to a
+// savepoint undoes the writes performed after the savepoint, both to the
+// reads of the transaction and once it commits.
+func TestTxnCoordSenderRollbackToSavepoint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	expect := func(txn *client.Txn, key string, expected string) {
+		t.Helper()
+		var kv client.KeyValue
+		var err error
+		if txn != nil {
+			kv, err = txn.Get(ctx, key)
+		} else {
+			kv, err = s.DB.Get(ctx, key)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := string(kv.ValueBytes()); actual != expected {
+			t.Fatalf("%s: expected %q, found %q", key, expected, actual)
+		}
+	}
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	if err := txn.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "a", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "b", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.DelRange(ctx, "c", "e"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	expect(txn, "a", "1")
+	expect(txn, "b", "")
+
+	// The savepoint can be rolled back to again.
+	if err := txn.Put(ctx, "b", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	expect(txn, "b", "")
+	if err := txn.Put(ctx, "c", "3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CommitOrCleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect(nil, "a", "1")
+	expect(nil, "b", "")
+	expect(nil, "c", "3")
+}
+
+// TestTxnCoordSenderRollbackToSavepointAfterError verifies that rolling back
+// to a savepoint makes a transaction usable again after a non-retriable
+// error.
+func TestTxnCoordSenderRollbackToSavepointAfterError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	if err := txn.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "b", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CPut(ctx, "a", "2", "3"); !testutils.IsError(err, "unexpected value") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.Put(ctx, "c", "1"); !testutils.IsError(err, "txn already encountered an error") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "c", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CommitOrCleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := s.DB.Scan(ctx, "a", "d", 0 /* maxRows */)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || string(rows[0].Key) != "a" || string(rows[1].Key) != "c" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+// TestTxnCoordSenderRollbackToSavepointAfterRestart verifies that a
+// savepoint can't be rolled back to once the transaction restarted.
+func TestTxnCoordSenderRollbackToSavepointAfterRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	txn.ManualRestart(ctx, hlc.Timestamp{})
+	if err := txn.RollbackToSavepoint(ctx, sp); !testutils.IsError(err,
+		"cannot roll back to a savepoint established before a transaction restart") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
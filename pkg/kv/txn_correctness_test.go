@@ -29,6 +29,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/localtestcluster"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
@@ -542,6 +543,10 @@ type verifier struct {
 	preHistory string
 	history    string
 	checkFn    func(env map[string]int64) error
+	// isolation is the isolation level of the transactions of the histories.
+	// The commands of READ_COMMITTED transactions are run like individual
+	// statements, each reading at a new timestamp.
+	isolation enginepb.IsolationType
 }
 
 // historyVerifier parses a planned transaction execution history into
@@ -779,11 +784,19 @@ func (hv *historyVerifier) runTxn(
 
 		txn.SetDebugName(txnName)
 		txn.InternalSetPriority(priority)
+		if err := txn.SetIsolation(hv.verify.isolation); err != nil {
+			return err
+		}
 
 		env := map[string]int64{}
 		for cmdIdx+1 < len(cmds) {
 			cmdIdx++
 			cmds[cmdIdx].env = env
+			if hv.verify.isolation == enginepb.READ_COMMITTED {
+				if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+					return err
+				}
+			}
 			_, err := hv.runCmd(txn, txnIdx, retry, cmds[cmdIdx], t)
 			if err != nil {
 				if log.V(1) {
@@ -1065,3 +1078,193 @@ func TestTxnDBWriteSkewAnomaly(t *testing.T) {
 	}
 	checkConcurrency("write skew", []string{txn1, txn2}, verify, t)
 }
+
+// TestTxnDBReadCommittedStatementSnapshot verifies that each statement of a
+// READ_COMMITTED transaction reads from a consistent snapshot. Unlike the
+// reads of separate statements, the values read by a single scan can't
+// include only some of the writes of another transaction.
+//
+// An inconsistent snapshot would typically fail with a history such as:
+//   W2(A,1) SC1(A-C) W2(B,1) C2 W1(C,A+B) C1
+// where the scan observes the write to A but not the one to B.
+func TestTxnDBReadCommittedStatementSnapshot(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	txn1 := "SC(A-C) W(C,A+B) C"
+	txn2 := "W(A,1) W(B,1) C"
+	verify := &verifier{
+		history: "R(C)",
+		checkFn: func(env map[string]int64) error {
+			if env["C"] != 2 && env["C"] != 0 {
+				return errors.Errorf("expected C to be either 0 or 2, got %d", env["C"])
+			}
+			return nil
+		},
+		isolation: enginepb.READ_COMMITTED,
+	}
+	checkConcurrency("read committed statement snapshot", []string{txn1, txn2}, verify, t)
+}
+
+// TestTxnReadCommittedAdvanceReadTimestamp verifies that advancing the read
+// timestamp of a READ_COMMITTED transaction makes the values committed by
+// other transactions visible, and that the transaction commits even though
+// its earlier reads could no longer be refreshed.
+func TestTxnReadCommittedAdvanceReadTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	expect := func(txn *client.Txn, key string, expected int64) {
+		t.Helper()
+		var kv client.KeyValue
+		var err error
+		if txn != nil {
+			kv, err = txn.Get(ctx, key)
+		} else {
+			kv, err = s.DB.Get(ctx, key)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual int64
+		if kv.Value != nil {
+			actual = kv.ValueInt()
+		}
+		if actual != expected {
+			t.Fatalf("%s: expected %d, found %d", key, expected, actual)
+		}
+	}
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	if err := txn.SetIsolation(enginepb.READ_COMMITTED); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect(txn, "a", 0)
+
+	// The value of a written after the first read is not visible until the
+	// read timestamp is advanced.
+	if err := s.DB.Put(ctx, "a", 1); err != nil {
+		t.Fatal(err)
+	}
+	expect(txn, "a", 0)
+	if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect(txn, "a", 1)
+
+	// The isolation level can't be changed once the transaction is running.
+	if err := txn.SetIsolation(enginepb.SERIALIZABLE); !testutils.IsError(
+		err, "cannot change the isolation level of a running transaction",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Write a key below the timestamp cache entry of a read performed by
+	// another transaction, which pushes the transaction's timestamp. A
+	// SERIALIZABLE transaction would have to refresh its read of a, which
+	// fails because of the write below. A READ_COMMITTED transaction commits.
+	if _, err := s.DB.Get(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.Put(ctx, "a", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "b", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CommitOrCleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect(nil, "a", 2)
+	expect(nil, "b", 3)
+}
+
+// TestTxnReadCommittedStatementRetry verifies that a write-write conflict
+// encountered by a statement of a READ_COMMITTED transaction doesn't restart
+// the transaction: the statement can be retried on its own at a new read
+// timestamp after rolling back to a savepoint, and the writes of the previous
+// statements are preserved.
+func TestTxnReadCommittedStatementRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s := createTestDB(t)
+	defer s.Stop()
+
+	if err := s.DB.Put(ctx, "a", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	if err := txn.SetIsolation(enginepb.READ_COMMITTED); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first statement writes b.
+	if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	epoch := txn.Epoch()
+
+	// The second statement increments a, which is updated by another
+	// transaction between the read and the write.
+	increment := func() error {
+		if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+			t.Fatal(err)
+		}
+		kv, err := txn.Get(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return txn.Put(ctx, "a", kv.ValueInt()+1)
+	}
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.AdvanceReadTimestamp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txn.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DB.Put(ctx, "a", 5); err != nil {
+		t.Fatal(err)
+	}
+	err = txn.Put(ctx, "a", 2)
+	retryErr, ok := err.(*roachpb.TransactionRetryWithProtoRefreshError)
+	if !ok {
+		t.Fatalf("expected a retry error, got: %v", err)
+	}
+	if retryErr.Transaction.ID != txn.ID() || txn.Epoch() != epoch {
+		t.Fatalf("expected the transaction not to be restarted: %s", retryErr.Transaction)
+	}
+
+	// Retry the statement.
+	if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := increment(); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.CommitOrCleanup(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for key, expected := range map[string]int64{"a": 6, "b": 1} {
+		kv, err := s.DB.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := kv.ValueInt(); actual != expected {
+			t.Errorf("%s: expected %d, found %d", key, expected, actual)
+		}
+	}
+}
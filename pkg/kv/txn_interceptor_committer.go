@@ -0,0 +1,235 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+)
+
+var parallelCommitsEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.parallel_commits_enabled",
+	"if enabled, transactional commits will be parallelized with transactional writes",
+	true,
+)
+
+// parallelCommitsAllowed returns whether transactions are permitted to perform
+// parallel commits. Nodes running versions that predate parallel commits
+// consider a transaction record in the STAGING status to be finalized, so
+// parallel commits are not allowed until the whole cluster understands them.
+func parallelCommitsAllowed(st *cluster.Settings) bool {
+	return st.Version.IsActive(cluster.VersionParallelCommits) &&
+		parallelCommitsEnabled.Get(&st.SV)
+}
+
+// txnCommitter is a txnInterceptor that concerns itself with committing and
+// rolling back transactions. It intercepts EndTransaction requests and
+// coordinates their execution. This is accomplished either by issuing them
+// directly with proper addressing or, in the case of parallel commits, by
+// explicitly committing transactions that were implicitly committed by their
+// EndTransaction request.
+//
+// A parallel commit is a commit whose EndTransaction is sent in parallel with
+// the writes that it depends on. The txnPipeliner attaches these writes to a
+// committing EndTransaction as its in-flight writes. The EndTransaction then
+// moves the transaction record into the STAGING status instead of committing
+// it. A STAGING transaction is implicitly committed once all of its in-flight
+// writes have succeeded at or below the timestamp of its STAGING record,
+// because at that point any other transaction that finds the record is able
+// to prove this condition and commit it itself (see the transaction recovery
+// procedure in the storage package).
+//
+// The txnCommitter is responsible for deciding whether a committing batch can
+// perform a parallel commit, for checking whether the transaction was
+// implicitly committed when the batch's response arrives and, if so, for
+// asynchronously moving the transaction record to the COMMITTED status so
+// that its intents can be resolved. If the transaction was not implicitly
+// committed because one of its writes was pushed above its staging timestamp,
+// the interceptor returns a retry error that the txnSpanRefresher above it can
+// handle by refreshing and resending the batch.
+type txnCommitter struct {
+	log.AmbientContext
+
+	st      *cluster.Settings
+	stopper *stop.Stopper
+	wrapped lockedSender
+	mu      sync.Locker
+}
+
+// SendLocked implements the lockedSender interface.
+func (tc *txnCommitter) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	rArgs, hasET := ba.GetArg(roachpb.EndTransaction)
+	if !hasET {
+		return tc.wrapped.SendLocked(ctx, ba)
+	}
+	et := rArgs.(*roachpb.EndTransactionRequest)
+
+	// Determine whether we can perform a parallel commit.
+	if et.IsParallelCommit() && !tc.canCommitInParallel(ba, et) {
+		// If we can't perform a parallel commit, strip the EndTransaction of
+		// its in-flight writes so that it commits the transaction directly.
+		ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests...)
+		etCopy := *et
+		etCopy.InFlightWrites = nil
+		ba.Requests[len(ba.Requests)-1].MustSetInner(&etCopy)
+		et = &etCopy
+	}
+
+	// Send the adjusted batch through the wrapped lockedSender. Unlocks while
+	// sending then re-locks.
+	br, pErr := tc.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		// If the batch resulted in an error but the EndTransaction request
+		// succeeded, staging the transaction record in the process, downgrade
+		// the status back to PENDING. The transaction cannot be implicitly
+		// committed because one of the writes it depends on failed.
+		if txn := pErr.GetTxn(); txn != nil && txn.Status == roachpb.STAGING {
+			pErr.SetTxn(cloneWithStatus(txn, roachpb.PENDING))
+		}
+		return nil, pErr
+	}
+
+	// Determine next steps based on the status of the transaction.
+	switch br.Txn.Status {
+	case roachpb.STAGING:
+		// Continue with the parallel commit below.
+	default:
+		// The transaction was committed or rolled back directly.
+		return br, nil
+	}
+
+	// The transaction is implicitly committed if all of the writes that it
+	// depends on succeeded at or below the staging timestamp. Because the
+	// batch's response carries the largest timestamp that any of its requests
+	// were performed at, it suffices to compare the two.
+	etResp := br.Responses[len(br.Responses)-1].GetInner().(*roachpb.EndTransactionResponse)
+	if etResp.StagingTimestamp.Less(br.Txn.Timestamp) {
+		// One of the writes was pushed above the staging timestamp. Return a
+		// retry error so that the transaction can refresh its reads and stage
+		// its record again at the higher timestamp.
+		reason := roachpb.RETRY_SERIALIZABLE
+		if br.Txn.WriteTooOld {
+			reason = roachpb.RETRY_WRITE_TOO_OLD
+		}
+		err := roachpb.NewTransactionRetryError(reason)
+		return nil, roachpb.NewErrorWithTxn(err, cloneWithStatus(br.Txn, roachpb.PENDING))
+	}
+
+	// The transaction is implicitly committed, so its coordinator is free to
+	// return to the client. Asynchronously move the transaction record to the
+	// COMMITTED status so that the transaction's intents can be resolved.
+	tc.makeTxnCommitExplicitAsync(ctx, br.Txn, et)
+	br.Txn.Status = roachpb.COMMITTED
+	br.Txn.InFlightWrites = nil
+	return br, nil
+}
+
+// canCommitInParallel determines whether the batch can perform a parallel
+// commit. Batches containing a BeginTransaction or a ranged write cannot, as
+// neither can be proven to have succeeded by the transaction recovery
+// procedure. Neither can EndTransactions with commit triggers, which must be
+// run synchronously when the transaction commits. Neither can any batch before
+// parallel commits are allowed in the cluster.
+func (tc *txnCommitter) canCommitInParallel(
+	ba roachpb.BatchRequest, et *roachpb.EndTransactionRequest,
+) bool {
+	if !parallelCommitsAllowed(tc.st) {
+		return false
+	}
+	if et.InternalCommitTrigger != nil {
+		return false
+	}
+	for _, ru := range ba.Requests[:len(ba.Requests)-1] {
+		req := ru.GetInner()
+		if req.Method() == roachpb.BeginTransaction {
+			return false
+		}
+		if roachpb.IsTransactionWrite(req) && roachpb.IsRange(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// makeTxnCommitExplicitAsync launches an async task that sends an
+// EndTransaction request, stripped of its in-flight writes, to move the
+// implicitly committed transaction's record to the COMMITTED status.
+func (tc *txnCommitter) makeTxnCommitExplicitAsync(
+	ctx context.Context, txn *roachpb.Transaction, et *roachpb.EndTransactionRequest,
+) {
+	log.VEventf(ctx, 2, "making txn commit explicit: %s", txn)
+	txnCopy := cloneWithStatus(txn, roachpb.PENDING)
+	etCopy := *et
+	etCopy.InFlightWrites = nil
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: txnCopy}
+	ba.Add(&etCopy)
+
+	// NB: We use context.Background() here because we don't want a canceled
+	// context to interrupt the commit.
+	ctx = tc.AnnotateCtx(context.Background())
+	if err := tc.stopper.RunAsyncTask(
+		ctx, "txnCommitter: making txn commit explicit", func(ctx context.Context) {
+			tc.mu.Lock()
+			defer tc.mu.Unlock()
+			_, pErr := tc.wrapped.SendLocked(ctx, ba)
+			if pErr != nil {
+				// A TransactionStatusError indicating that the transaction is
+				// already committed is expected if the record was recovered by
+				// another transaction in the meantime.
+				if tse, ok := pErr.GetDetail().(*roachpb.TransactionStatusError); ok &&
+					tse.Reason == roachpb.TransactionStatusError_REASON_TXN_COMMITTED {
+					return
+				}
+				log.VErrEventf(ctx, 1, "making txn commit explicit failed for %s: %s", txnCopy, pErr)
+			}
+		},
+	); err != nil {
+		log.VErrEventf(ctx, 1, "failed to make txn commit explicit: %s", err)
+	}
+}
+
+// setWrapped is part of the txnInterceptor interface.
+func (tc *txnCommitter) setWrapped(wrapped lockedSender) { tc.wrapped = wrapped }
+
+// populateMetaLocked is part of the txnInterceptor interface.
+func (*txnCommitter) populateMetaLocked(*roachpb.TxnCoordMeta) {}
+
+// augmentMetaLocked is part of the txnInterceptor interface.
+func (*txnCommitter) augmentMetaLocked(roachpb.TxnCoordMeta) {}
+
+// epochBumpedLocked is part of the txnInterceptor interface.
+func (*txnCommitter) epochBumpedLocked() {}
+
+// closeLocked is part of the txnInterceptor interface.
+func (*txnCommitter) closeLocked() {}
+
+// cloneWithStatus creates a copy of the provided transaction with the
+// provided status.
+func cloneWithStatus(txn *roachpb.Transaction, s roachpb.TransactionStatus) *roachpb.Transaction {
+	clone := txn.Clone()
+	clone.Status = s
+	clone.InFlightWrites = nil
+	return &clone
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/stretchr/testify/require"
+)
+
+func makeMockTxnCommitter() (txnCommitter, *mockLockedSender, *stop.Stopper) {
+	mockSender := &mockLockedSender{}
+	stopper := stop.NewStopper()
+	return txnCommitter{
+		st:      cluster.MakeTestingClusterSettings(),
+		stopper: stopper,
+		wrapped: mockSender,
+		mu:      new(syncutil.Mutex),
+	}, mockSender, stopper
+}
+
+// TestTxnCommitterNonParallelCommit tests that EndTransaction requests that
+// are not parallel commits pass through the txnCommitter untouched.
+func TestTxnCommitterNonParallelCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA := roachpb.Key("a")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(&roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}})
+	etArgs := roachpb.EndTransactionRequest{Commit: true}
+	ba.Add(&etArgs)
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Equal(t, 2, len(ba.Requests))
+		require.Equal(t, &etArgs, ba.Requests[1].GetInner())
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+
+	br, pErr := tc.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, roachpb.COMMITTED, br.Txn.Status)
+}
+
+// TestTxnCommitterStripsInFlightWrites tests that the txnCommitter strips the
+// in-flight writes of EndTransaction requests that cannot perform a parallel
+// commit.
+func TestTxnCommitterStripsInFlightWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	inFlight := []roachpb.SequencedWrite{{Key: keyA, Sequence: 1}}
+
+	testCases := []struct {
+		name    string
+		setup   func()
+		prepend roachpb.Request
+		trigger *roachpb.InternalCommitTrigger
+	}{
+		{
+			name: "ranged write",
+			prepend: &roachpb.DeleteRangeRequest{
+				RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyB},
+			},
+		},
+		{
+			name:    "begin transaction",
+			prepend: &roachpb.BeginTransactionRequest{RequestHeader: roachpb.RequestHeader{Key: txn.Key}},
+		},
+		{
+			name:    "commit trigger",
+			trigger: &roachpb.InternalCommitTrigger{ModifiedSpanTrigger: &roachpb.ModifiedSpanTrigger{}},
+		},
+		{
+			name:  "disabled",
+			setup: func() { parallelCommitsEnabled.Override(&tc.st.SV, false) },
+		},
+		{
+			name: "old cluster version",
+			setup: func() {
+				v := cluster.VersionByKey(cluster.VersionLazyTxnRecord)
+				tc.st = cluster.MakeTestingClusterSettingsWithVersion(v, v)
+			},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.setup != nil {
+				c.setup()
+			}
+
+			var ba roachpb.BatchRequest
+			ba.Header = roachpb.Header{Txn: &txn}
+			if c.prepend != nil {
+				ba.Add(c.prepend)
+			}
+			ba.Add(&roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}})
+			etArgs := roachpb.EndTransactionRequest{
+				Commit:                true,
+				InFlightWrites:        inFlight,
+				InternalCommitTrigger: c.trigger,
+			}
+			ba.Add(&etArgs)
+
+			mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+				etReq := ba.Requests[len(ba.Requests)-1].GetInner().(*roachpb.EndTransactionRequest)
+				require.Nil(t, etReq.InFlightWrites)
+
+				br := ba.CreateReply()
+				br.Txn = ba.Txn
+				br.Txn.Status = roachpb.COMMITTED
+				return br, nil
+			})
+
+			br, pErr := tc.SendLocked(ctx, ba)
+			require.Nil(t, pErr)
+			require.NotNil(t, br)
+			require.Equal(t, inFlight, etArgs.InFlightWrites) // caller's request not mutated
+		})
+	}
+}
+
+// TestTxnCommitterAsyncExplicitCommit tests that the txnCommitter considers a
+// transaction that is implicitly committed by a parallel commit to be
+// committed and that it commits the transaction explicitly asynchronously.
+func TestTxnCommitterAsyncExplicitCommit(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA := roachpb.Key("a")
+	inFlight := []roachpb.SequencedWrite{{Key: keyA, Sequence: 1}}
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	putArgs := roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}}
+	putArgs.Sequence = 1
+	ba.Add(&putArgs)
+	ba.Add(&roachpb.EndTransactionRequest{Commit: true, InFlightWrites: inFlight})
+
+	explicitCommitCh := make(chan struct{})
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Equal(t, 2, len(ba.Requests))
+		etReq := ba.Requests[1].GetInner().(*roachpb.EndTransactionRequest)
+		require.Equal(t, inFlight, etReq.InFlightWrites)
+
+		br := ba.CreateReply()
+		stagingTxn := ba.Txn.Clone()
+		br.Txn = &stagingTxn
+		br.Txn.Status = roachpb.STAGING
+		br.Txn.InFlightWrites = etReq.InFlightWrites
+		br.Responses[1].GetInner().(*roachpb.EndTransactionResponse).StagingTimestamp = br.Txn.Timestamp
+
+		// Before returning, mock out the sender again to test against the
+		// async task that should be sent to make the implicit txn commit
+		// explicit.
+		mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			defer close(explicitCommitCh)
+			require.Equal(t, 1, len(ba.Requests))
+			require.Equal(t, roachpb.PENDING, ba.Txn.Status)
+			etReq := ba.Requests[0].GetInner().(*roachpb.EndTransactionRequest)
+			require.True(t, etReq.Commit)
+			require.Nil(t, etReq.InFlightWrites)
+
+			br := ba.CreateReply()
+			br.Txn = ba.Txn
+			br.Txn.Status = roachpb.COMMITTED
+			return br, nil
+		})
+		return br, nil
+	})
+
+	tc.mu.Lock()
+	br, pErr := tc.SendLocked(ctx, ba)
+	tc.mu.Unlock()
+	require.Nil(t, pErr)
+	require.NotNil(t, br)
+	require.Equal(t, roachpb.COMMITTED, br.Txn.Status)
+	require.Nil(t, br.Txn.InFlightWrites)
+
+	// Wait until the explicit commit succeeds.
+	<-explicitCommitCh
+}
+
+// TestTxnCommitterRetryAfterStaging tests that the txnCommitter returns a
+// retry error when a parallel commit stages the transaction record but one of
+// the writes it depends on is pushed above the staging timestamp.
+func TestTxnCommitterRetryAfterStaging(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	tc, mockSender, stopper := makeMockTxnCommitter()
+	defer stopper.Stop(ctx)
+
+	txn := makeTxnProto()
+	keyA := roachpb.Key("a")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	putArgs := roachpb.PutRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}}
+	putArgs.Sequence = 1
+	ba.Add(&putArgs)
+	ba.Add(&roachpb.EndTransactionRequest{
+		Commit:         true,
+		InFlightWrites: []roachpb.SequencedWrite{{Key: keyA, Sequence: 1}},
+	})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		br := ba.CreateReply()
+		stagingTxn := ba.Txn.Clone()
+		br.Txn = &stagingTxn
+		br.Txn.Status = roachpb.STAGING
+		br.Responses[1].GetInner().(*roachpb.EndTransactionResponse).StagingTimestamp = br.Txn.Timestamp
+
+		// Pretend the PutRequest was pushed above the staging timestamp.
+		br.Txn.Timestamp = br.Txn.Timestamp.Add(1, 0)
+		return br, nil
+	})
+
+	br, pErr := tc.SendLocked(ctx, ba)
+	require.Nil(t, br)
+	require.NotNil(t, pErr)
+	require.IsType(t, &roachpb.TransactionRetryError{}, pErr.GetDetail())
+	retryErr := pErr.GetDetail().(*roachpb.TransactionRetryError)
+	require.Equal(t, roachpb.RETRY_SERIALIZABLE, retryErr.Reason)
+	require.Equal(t, roachpb.PENDING, pErr.GetTxn().Status)
+}
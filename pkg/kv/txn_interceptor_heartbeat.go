@@ -512,6 +512,13 @@ func (h *txnHeartbeat) heartbeat(ctx context.Context) bool {
 	// This appears to be benign, but it's still somewhat disconcerting. If this
 	// ever causes any issues, we'll need to be smarter about detecting this race
 	// on the client and conditionally ignoring the result of heartbeat responses.
+	//
+	// A STAGING transaction record indicates that a parallel commit is in
+	// progress. Its outcome is determined by the response to the commit, so
+	// the heartbeat does not update the status and keeps the record alive.
+	if respTxn != nil && respTxn.Status == roachpb.STAGING {
+		respTxn = cloneWithStatus(respTxn, roachpb.PENDING)
+	}
 	h.mu.txn.Update(respTxn)
 	if h.mu.txn.Status != roachpb.PENDING {
 		if h.mu.txn.Status == roachpb.ABORTED {
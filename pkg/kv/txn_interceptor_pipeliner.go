@@ -86,11 +86,12 @@ var pipelinedWritesMaxBatchSize = settings.RegisterNonNegativeIntSetting(
 // by tacking on a QueryIntent request for each one to the front of an
 // EndTransaction(Commit=true) requests. The result of this is that the
 // EndTransaction needs to wait at the DistSender level for all of QueryIntent
-// requests to succeed at before executing itself [1]. This is a little
-// unfortunate because a transaction could have accumulated a large number of
-// outstanding writes without proving any of them, and the more of these writes
-// there are, the more chance querying one of them gets delayed and delays the
-// overall transaction.
+// requests to succeed at before executing itself, unless the transaction
+// performs a parallel commit [1]. This is a little unfortunate because a
+// transaction could have accumulated a large number of outstanding writes
+// without proving any of them, and the more of these writes there are, the
+// more chance querying one of them gets delayed and delays the overall
+// transaction.
 //
 // Three approaches have been considered to address this, all of which revolve
 // around the idea that earlier writes in a transaction may have finished
@@ -121,11 +122,13 @@ var pipelinedWritesMaxBatchSize = settings.RegisterNonNegativeIntSetting(
 //    they finish consensus without any extra RPCs.
 // So far, none of these approaches have been integrated.
 //
-// [1] A proposal called "parallel commits" (#24194) exists that would allow all
-//     QueryIntent requests and the EndTransaction request that they are prepended
-//     to to be sent by the DistSender in parallel. This would help with this
-//     issue by hiding the cost of the QueryIntent requests behind the cost of the
-//     "staging" EndTransaction request.
+// [1] With parallel commits (#24194), the interceptor also attaches all of the
+//     writes that the committing EndTransaction depends on, i.e. the outstanding
+//     writes that the QueryIntent requests prove and the point writes in the
+//     same batch, to the EndTransaction as its in-flight writes. This allows the
+//     DistSender to send the QueryIntent requests, the writes and the "staging"
+//     EndTransaction request in parallel, which hides the cost of the QueryIntent
+//     requests behind the cost of the EndTransaction. See txnCommitter.
 //
 type txnPipeliner struct {
 	st       *cluster.Settings
@@ -299,6 +302,23 @@ func (tp *txnPipeliner) chainToOutstandingWrites(ba roachpb.BatchRequest) roachp
 			}
 		}
 
+		// A committing EndTransaction depends on the success of all outstanding
+		// writes and of all point writes that precede it in the batch. Attach
+		// them to it as its in-flight writes so that it can perform a parallel
+		// commit.
+		et, ok := req.(*roachpb.EndTransactionRequest)
+		if ok && et.Commit && parallelCommitsAllowed(tp.st) {
+			if ws := tp.inFlightWritesForCommit(oldReqs[:i]); len(ws) > 0 {
+				if !forked {
+					ba.Requests = append([]roachpb.RequestUnion(nil), ba.Requests[:i]...)
+					forked = true
+				}
+				etCopy := *et
+				etCopy.InFlightWrites = ws
+				req = &etCopy
+			}
+		}
+
 		// If the BatchRequest's slice of requests has been forked from the original,
 		// append the request to the new slice.
 		if forked {
@@ -312,6 +332,30 @@ func (tp *txnPipeliner) chainToOutstandingWrites(ba roachpb.BatchRequest) roachp
 	return ba
 }
 
+// inFlightWritesForCommit returns the writes that a committing EndTransaction
+// preceded by the provided requests depends on: the outstanding writes and the
+// point writes among the requests.
+func (tp *txnPipeliner) inFlightWritesForCommit(
+	reqs []roachpb.RequestUnion,
+) []roachpb.SequencedWrite {
+	var ws []roachpb.SequencedWrite
+	if tp.outstandingWritesLen() > 0 {
+		ws = make([]roachpb.SequencedWrite, 0, tp.outstandingWritesLen()+len(reqs))
+		tp.outstandingWrites.Ascend(func(item btree.Item) bool {
+			ws = append(ws, item.(*outstandingWrite).SequencedWrite)
+			return true
+		})
+	}
+	for _, ru := range reqs {
+		req := ru.GetInner()
+		if roachpb.IsTransactionWrite(req) && !roachpb.IsRange(req) {
+			h := req.Header()
+			ws = append(ws, roachpb.SequencedWrite{Key: h.Key, Sequence: h.Sequence})
+		}
+	}
+	return ws
+}
+
 // updateOutstandingWrites reads the response for the given request and uses
 // it to update the tracked outstanding write set. It does so by performing
 // two actions:
@@ -392,6 +436,40 @@ func (tp *txnPipeliner) adjustError(
 	return pErr
 }
 
+// proveOutstandingWritesLocked proves that all of the outstanding writes have
+// succeeded. This must be done before rolling back the transaction's writes
+// to a savepoint, so that no write can be replicated after its intent was
+// rolled back.
+func (tp *txnPipeliner) proveOutstandingWritesLocked(
+	ctx context.Context, txn *roachpb.Transaction,
+) *roachpb.Error {
+	if tp.outstandingWritesLen() == 0 {
+		return nil
+	}
+	var ba roachpb.BatchRequest
+	ba.Txn = txn
+	tp.outstandingWrites.Ascend(func(item btree.Item) bool {
+		w := item.(*outstandingWrite)
+		meta := txn.TxnMeta
+		meta.Sequence = w.Sequence
+		ba.Add(&roachpb.QueryIntentRequest{
+			RequestHeader: roachpb.RequestHeader{
+				Key: w.Key,
+			},
+			Txn:       meta,
+			IfMissing: roachpb.QueryIntentRequest_RETURN_ERROR,
+		})
+		return true
+	})
+
+	br, pErr := tp.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		return tp.adjustError(ctx, ba, pErr)
+	}
+	tp.updateOutstandingWrites(ctx, ba, br)
+	return nil
+}
+
 // setWrapped implements the txnInterceptor interface.
 func (tp *txnPipeliner) setWrapped(wrapped lockedSender) { tp.wrapped = wrapped }
 
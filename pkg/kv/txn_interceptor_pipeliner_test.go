@@ -218,6 +218,17 @@ func TestTxnPipelinerTrackOutstandingWrites(t *testing.T) {
 		require.Equal(t, int32(3), qiReq2.Txn.Sequence)
 		require.Equal(t, int32(5), qiReq3.Txn.Sequence)
 
+		// The EndTransaction depends on the outstanding writes and on the
+		// write in its batch.
+		etReq := ba.Requests[4].GetInner().(*roachpb.EndTransactionRequest)
+		expInFlight := []roachpb.SequencedWrite{
+			{Key: keyA, Sequence: 2},
+			{Key: keyB, Sequence: 3},
+			{Key: keyC, Sequence: 5},
+			{Key: keyD, Sequence: 6},
+		}
+		require.Equal(t, expInFlight, etReq.InFlightWrites)
+
 		br = ba.CreateReply()
 		br.Txn = ba.Txn
 		br.Txn.Status = roachpb.COMMITTED
@@ -232,6 +243,7 @@ func TestTxnPipelinerTrackOutstandingWrites(t *testing.T) {
 	require.Equal(t, 2, len(br.Responses)) // QueryIntent response stripped
 	require.Nil(t, pErr)
 	require.Equal(t, 0, tp.outstandingWritesLen())
+	require.Nil(t, etArgs.InFlightWrites) // caller's request not mutated
 }
 
 // TestTxnPipelinerReads tests that txnPipeliner will never instruct batches
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
@@ -139,10 +140,13 @@ func (sr *txnSpanRefresher) SendLocked(
 	// in IsSerializablePushAndRefreshNotPossible. We plan to remove
 	// this all shortly (#30074), but if that changes, we should clean
 	// this overlap up.
+	//
+	// READ_COMMITTED transactions don't need to refresh their reads in order to
+	// commit at a pushed timestamp.
 	ts := br.Txn.OrigTimestamp
 	ts.Forward(br.Txn.RefreshedTimestamp)
 	pushed := ts != br.Txn.Timestamp
-	if pushed && sr.refreshInvalid {
+	if pushed && sr.refreshInvalid && br.Txn.Isolation == enginepb.SERIALIZABLE {
 		return nil, roachpb.NewErrorWithTxn(
 			errors.New("transaction is too large to complete; try splitting into pieces"), br.Txn,
 		)
@@ -390,5 +394,16 @@ func (sr *txnSpanRefresher) epochBumpedLocked() {
 	sr.refreshSpansBytes = 0
 }
 
+// readTimestampAdvancedLocked is called when the read timestamp of a
+// READ_COMMITTED transaction is advanced. The reads performed at the previous
+// read timestamp don't need to be refreshed if the transaction is pushed, so
+// only the spans read after this call are tracked.
+func (sr *txnSpanRefresher) readTimestampAdvancedLocked() {
+	sr.refreshReads = nil
+	sr.refreshWrites = nil
+	sr.refreshInvalid = false
+	sr.refreshSpansBytes = 0
+}
+
 // closeLocked implements the txnInterceptor interface.
 func (*txnSpanRefresher) closeLocked() {}
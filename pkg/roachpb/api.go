@@ -659,6 +659,13 @@ func (etr *EndTransactionRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// IsParallelCommit returns whether the EndTransaction request is attempting
+// to perform a parallel commit, i.e. whether it commits the transaction while
+// some of its writes are still in flight.
+func (etr *EndTransactionRequest) IsParallelCommit() bool {
+	return etr.Commit && len(etr.InFlightWrites) > 0
+}
+
 // ShallowCopy implements the Request interface.
 func (asr *AdminSplitRequest) ShallowCopy() Request {
 	shallowCopy := *asr
@@ -858,6 +865,17 @@ func NewPut(key Key, value Value) Request {
 	}
 }
 
+// NewPutLock returns a Request initialized to acquire a lock on key for
+// the request's transaction without changing its value.
+func NewPutLock(key Key) Request {
+	return &PutRequest{
+		RequestHeader: RequestHeader{
+			Key: key,
+		},
+		LockOnly: true,
+	}
+}
+
 // NewPutInline returns a Request initialized to put the value at key
 // using an inline value.
 func NewPutInline(key Key, value Value) Request {
@@ -739,6 +739,15 @@ const (
 	MaxTxnPriority = math.MaxInt32
 )
 
+// IsFinalized determines whether the transaction status is in a terminal
+// state. A transaction in a terminal state will never move to another state.
+// Note that a STAGING transaction is not finalized: it is implicitly
+// committed if all of its in-flight writes succeeded, but it must still be
+// moved to COMMITTED or ABORTED before its intents can be resolved.
+func (s TransactionStatus) IsFinalized() bool {
+	return s == COMMITTED || s == ABORTED
+}
+
 // MakeTransaction creates a new transaction. The transaction key is
 // composed using the specified baseKey (for locality with data
 // affected by the transaction) and a random ID to guarantee
@@ -823,6 +832,7 @@ func (t Transaction) Clone() Transaction {
 	// Note that we're not cloning the span keys under the assumption that the
 	// keys themselves are not mutable.
 	t.Intents = append([]Span(nil), t.Intents...)
+	t.InFlightWrites = append([]SequencedWrite(nil), t.InFlightWrites...)
 	return t
 }
 
@@ -948,18 +958,40 @@ func (t *Transaction) Restart(
 // restart. This invalidates all write intents previously written at lower
 // epochs.
 func (t *Transaction) BumpEpoch() {
-	if t.Epoch == 0 {
+	if t.Epoch == 0 && t.EpochZeroTimestamp == (hlc.Timestamp{}) {
 		t.EpochZeroTimestamp = t.OrigTimestamp
 	}
 	t.Epoch++
 }
 
+// AdvanceReadTimestamp moves the read snapshot of a READ_COMMITTED transaction
+// forward to now, which is typically the current time on the gateway. The
+// provisional commit timestamp is forwarded along with it and the uncertainty
+// interval is reset to start at the new read timestamp.
+//
+// Intents written at the previous read timestamps are left where they are, so
+// the earliest timestamp used by the transaction is recorded in
+// EpochZeroTimestamp.
+func (t *Transaction) AdvanceReadTimestamp(now hlc.Timestamp, maxOffsetNs int64) {
+	if t.EpochZeroTimestamp == (hlc.Timestamp{}) {
+		t.EpochZeroTimestamp = t.OrigTimestamp
+	}
+	t.Timestamp.Forward(now)
+	t.OrigTimestamp = t.Timestamp
+	if maxOffsetNs != timeutil.ClocklessMaxOffset {
+		t.MaxTimestamp.Forward(t.OrigTimestamp.Add(maxOffsetNs, 0))
+	}
+	// The observed timestamps were collected relative to the previous read
+	// timestamp and would limit the new uncertainty interval to below it.
+	t.ObservedTimestamps = nil
+}
+
 // InclusiveTimeBounds returns start and end timestamps such that all intents written as
 // part of this transaction have a timestamp in the interval [start, end].
 func (t *Transaction) InclusiveTimeBounds() (hlc.Timestamp, hlc.Timestamp) {
 	min := t.OrigTimestamp
 	max := t.Timestamp
-	if t.Epoch != 0 && t.EpochZeroTimestamp != (hlc.Timestamp{}) {
+	if t.EpochZeroTimestamp != (hlc.Timestamp{}) {
 		if min.Less(t.EpochZeroTimestamp) {
 			panic(fmt.Sprintf("orig timestamp %s less than epoch zero %s", min, t.EpochZeroTimestamp))
 		}
@@ -983,7 +1015,19 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(t.Key) == 0 {
 		t.Key = o.Key
 	}
-	if o.Status != PENDING {
+	// The isolation level is chosen before the transaction performs any
+	// requests and never changes afterwards.
+	if o.Isolation != enginepb.SERIALIZABLE {
+		t.Isolation = o.Isolation
+	}
+	switch o.Status {
+	case PENDING:
+	case STAGING:
+		// A transaction that has been finalized can't move back to STAGING.
+		if !t.Status.IsFinalized() {
+			t.Status = o.Status
+		}
+	default:
 		t.Status = o.Status
 	}
 
@@ -1023,6 +1067,9 @@ func (t *Transaction) Update(o *Transaction) {
 	if len(o.Intents) > 0 {
 		t.Intents = o.Intents
 	}
+	if len(o.InFlightWrites) > 0 {
+		t.InFlightWrites = o.InFlightWrites
+	}
 	// On update, set epoch zero timestamp to the minimum seen by either txn.
 	if o.EpochZeroTimestamp != (hlc.Timestamp{}) {
 		if t.EpochZeroTimestamp == (hlc.Timestamp{}) || o.EpochZeroTimestamp.Less(t.EpochZeroTimestamp) {
@@ -1053,9 +1100,15 @@ func (t Transaction) String() string {
 		"ts=%s orig=%s max=%s wto=%t seq=%d",
 		t.Short(), Key(t.Key), t.Writing, floatPri, t.Status, t.Epoch, t.Timestamp,
 		t.OrigTimestamp, t.MaxTimestamp, t.WriteTooOld, t.Sequence)
+	if t.Isolation != enginepb.SERIALIZABLE {
+		fmt.Fprintf(&buf, " iso=%s", t.Isolation)
+	}
 	if ni := len(t.Intents); t.Status != PENDING && ni > 0 {
 		fmt.Fprintf(&buf, " int=%d", ni)
 	}
+	if nw := len(t.InFlightWrites); t.Status != PENDING && nw > 0 {
+		fmt.Fprintf(&buf, " ifw=%d", nw)
+	}
 	return buf.String()
 }
 
@@ -1102,6 +1155,7 @@ func (t *Transaction) AsRecord() TransactionRecord {
 	tr.LastHeartbeat = t.LastHeartbeat
 	tr.OrigTimestamp = t.OrigTimestamp
 	tr.Intents = t.Intents
+	tr.InFlightWrites = t.InFlightWrites
 	return tr
 }
 
@@ -1115,6 +1169,7 @@ func (tr *TransactionRecord) AsTransaction() Transaction {
 	t.LastHeartbeat = tr.LastHeartbeat
 	t.OrigTimestamp = tr.OrigTimestamp
 	t.Intents = tr.Intents
+	t.InFlightWrites = tr.InFlightWrites
 	return t
 }
 
@@ -1145,7 +1200,7 @@ func PrepareTransactionForRetry(
 
 	txn := pErr.GetTxn().Clone()
 	aborted := false
-	switch tErr := pErr.GetDetail().(type) {
+	switch pErr.GetDetail().(type) {
 	case *TransactionAbortedError:
 		// The txn coming with a TransactionAbortedError is not supposed to be used
 		// for the restart. Instead, a brand new transaction is created.
@@ -1166,11 +1221,48 @@ func PrepareTransactionForRetry(
 			now,
 			clock.MaxOffset().Nanoseconds(),
 		)
+		txn.Isolation = pErr.GetTxn().Isolation
 		// Use the priority communicated back by the server.
 		txn.Priority = errTxnPri
+	default:
+		forwardTimestampForRetry(ctx, &txn, pErr)
+	}
+	if !aborted {
+		txn.Restart(pri, txn.Priority, txn.Timestamp)
+	}
+	return txn
+}
+
+// PrepareTransactionForStatementRetry returns a new Transaction to be used for
+// retrying the current statement of a READ_COMMITTED transaction. Unlike
+// PrepareTransactionForRetry, the epoch is not bumped, so the writes of the
+// previous statements of the transaction remain valid; the timestamp is only
+// forwarded past the conflict that caused the error. The caller is responsible
+// for rolling back the writes of the statement and for advancing the read
+// timestamp of the transaction before retrying the statement.
+//
+// The error must not be a TransactionAbortedError, as an aborted transaction
+// can only be retried from the start.
+func PrepareTransactionForStatementRetry(ctx context.Context, pErr *Error) Transaction {
+	if pErr.TransactionRestart == TransactionRestart_NONE {
+		log.Fatalf(ctx, "invalid retryable err (%T): %s", pErr.GetDetail(), pErr)
+	}
+	if pErr.GetTxn() == nil {
+		log.Fatalf(ctx, "missing txn for retryable error: %s", pErr)
+	}
+	txn := pErr.GetTxn().Clone()
+	forwardTimestampForRetry(ctx, &txn, pErr)
+	txn.WriteTooOld = false
+	return txn
+}
+
+// forwardTimestampForRetry forwards the timestamp of the transaction past the
+// conflict that caused the given retryable error.
+func forwardTimestampForRetry(ctx context.Context, txn *Transaction, pErr *Error) {
+	switch tErr := pErr.GetDetail().(type) {
 	case *ReadWithinUncertaintyIntervalError:
 		txn.Timestamp.Forward(
-			readWithinUncertaintyIntervalRetryTimestamp(ctx, &txn, tErr, pErr.OriginNode))
+			readWithinUncertaintyIntervalRetryTimestamp(ctx, txn, tErr, pErr.OriginNode))
 	case *TransactionPushError:
 		// Increase timestamp if applicable, ensuring that we're just ahead of
 		// the pushee.
@@ -1182,14 +1274,10 @@ func PrepareTransactionForRetry(
 		// the restart.
 	case *WriteTooOldError:
 		// Increase the timestamp to the ts at which we've actually written.
-		txn.Timestamp.Forward(writeTooOldRetryTimestamp(&txn, tErr))
+		txn.Timestamp.Forward(writeTooOldRetryTimestamp(txn, tErr))
 	default:
 		log.Fatalf(ctx, "invalid retryable err (%T): %s", pErr.GetDetail(), pErr)
 	}
-	if !aborted {
-		txn.Restart(pri, txn.Priority, txn.Timestamp)
-	}
-	return txn
 }
 
 // CanTransactionRetryAtRefreshedTimestamp returns whether the transaction
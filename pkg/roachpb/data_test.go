@@ -481,6 +481,7 @@ var nonZeroTxn = Transaction{
 		Timestamp: makeTS(20, 21),
 		Priority:  957356782,
 		Sequence:  123,
+		Isolation: enginepb.READ_COMMITTED,
 	},
 	Name:                     "name",
 	Status:                   COMMITTED,
@@ -494,6 +495,7 @@ var nonZeroTxn = Transaction{
 	Intents:                  []Span{{Key: []byte("a"), EndKey: []byte("b")}},
 	EpochZeroTimestamp:       makeTS(1, 1),
 	OrigTimestampWasObserved: true,
+	InFlightWrites:           []SequencedWrite{{Key: []byte("c"), Sequence: 1}},
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -537,6 +539,36 @@ func TestTransactionUpdateEpochZero(t *testing.T) {
 	}
 }
 
+// TestTransactionUpdateStatus verifies that a STAGING status never
+// overrides a finalized one, while all other statuses except PENDING do.
+func TestTransactionUpdateStatus(t *testing.T) {
+	testCases := []struct {
+		cur, upd, exp TransactionStatus
+	}{
+		{PENDING, PENDING, PENDING},
+		{PENDING, STAGING, STAGING},
+		{PENDING, COMMITTED, COMMITTED},
+		{STAGING, PENDING, STAGING},
+		{STAGING, COMMITTED, COMMITTED},
+		{STAGING, ABORTED, ABORTED},
+		{COMMITTED, STAGING, COMMITTED},
+		{ABORTED, STAGING, ABORTED},
+	}
+	for _, c := range testCases {
+		txn := nonZeroTxn
+		txn.Status = c.cur
+		txn2 := nonZeroTxn
+		txn2.Status = c.upd
+		txn.Update(&txn2)
+		if txn.Status != c.exp {
+			t.Errorf("%s updated with %s: expected %s, got %s", c.cur, c.upd, c.exp, txn.Status)
+		}
+		if e, a := c.exp == COMMITTED || c.exp == ABORTED, txn.Status.IsFinalized(); e != a {
+			t.Errorf("%s: expected finalized %t, got %t", txn.Status, e, a)
+		}
+	}
+}
+
 func TestTransactionClone(t *testing.T) {
 	txn := nonZeroTxn.Clone()
 
@@ -547,6 +579,7 @@ func TestTransactionClone(t *testing.T) {
 	// listed below. If this test fails, please update the list below and/or
 	// Transaction.Clone().
 	expFields := []string{
+		"InFlightWrites.Key",
 		"Intents.EndKey",
 		"Intents.Key",
 		"TxnMeta.Key",
@@ -595,6 +628,10 @@ func TestTransactionRecordRoundtrips(t *testing.T) {
 	if !reflect.DeepEqual(txnRecord.Intents, txn.Intents) {
 		t.Fatalf("txnRecord.Intents = %v, txn.Intents = %v", txnRecord.Intents, txn.Intents)
 	}
+	if !reflect.DeepEqual(txnRecord.InFlightWrites, txn.InFlightWrites) {
+		t.Fatalf("txnRecord.InFlightWrites = %v, txn.InFlightWrites = %v",
+			txnRecord.InFlightWrites, txn.InFlightWrites)
+	}
 
 	// Verify that converting through a Transaction message and back
 	// to a TransactionRecord is a lossless round trip.
@@ -711,3 +711,18 @@ func (e *RangeFeedRetryError) message(pErr *Error) string {
 }
 
 var _ ErrorDetailInterface = &RangeFeedRetryError{}
+
+// NewIndeterminateCommitError initializes a new IndeterminateCommitError.
+func NewIndeterminateCommitError(txn Transaction) *IndeterminateCommitError {
+	return &IndeterminateCommitError{StagingTxn: txn.Clone()}
+}
+
+func (e *IndeterminateCommitError) Error() string {
+	return e.message(nil)
+}
+
+func (e *IndeterminateCommitError) message(pErr *Error) string {
+	return fmt.Sprintf("found txn in indeterminate STAGING state %s", e.StagingTxn)
+}
+
+var _ ErrorDetailInterface = &IndeterminateCommitError{}
@@ -185,6 +185,7 @@ type Server struct {
 	sessionRegistry    *sql.SessionRegistry
 	jobRegistry        *jobs.Registry
 	statsRefresher     *stats.Refresher
+	tempObjectCleaner  *sql.TemporaryObjectCleaner
 	engines            Engines
 	internalMemMetrics sql.MemoryMetrics
 	adminMemMetrics    sql.MemoryMetrics
@@ -292,6 +293,11 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		RPCRetryOptions: &retryOpts,
 		TestingKnobs:    clientTestingKnobs,
 		NodeDialer:      s.nodeDialer,
+		// NB: s.node is not defined at this point, but it will be
+		// before this is ever called.
+		LocalClosedTimestamp: func(rangeID roachpb.RangeID) (hlc.Timestamp, bool) {
+			return s.node.stores.MaxClosedTimestamp(rangeID)
+		},
 	}
 	s.distSender = kv.NewDistSender(distSenderCfg, s.gossip)
 	s.registry.AddMetricStruct(s.distSender.Metrics())
@@ -594,6 +600,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		DB:                      s.db,
 		Gossip:                  s.gossip,
 		MetricsRecorder:         s.recorder,
+		LockWaits:               s.node.stores,
 		DistSender:              s.distSender,
 		RPCContext:              s.rpcContext,
 		LeaseManager:            s.leaseMgr,
@@ -701,6 +708,15 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 	s.internalExecutor = internalExecutor
 	execCfg.InternalExecutor = internalExecutor
 
+	s.tempObjectCleaner = sql.NewTemporaryObjectCleaner(
+		s.st,
+		s.db,
+		s.internalExecutor,
+		&s.nodeIDContainer,
+		s.sessionRegistry,
+		s.nodeLiveness.IsLive,
+	)
+
 	s.execCfg = &execCfg
 
 	s.leaseMgr.SetExecCfg(&execCfg)
@@ -1575,6 +1591,10 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Start the background thread for periodically dropping the temporary
+	// objects of sessions which are not running anymore.
+	s.tempObjectCleaner.Start(ctx, s.stopper)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -73,6 +73,9 @@ const (
 	VersionLoadSplits
 	VersionExportStorageWorkload
 	VersionLazyTxnRecord
+	VersionParallelCommits
+	VersionRowLocking
+	VersionReadCommitted
 
 	// Add new versions here (step one of two).
 
@@ -311,6 +314,24 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionLazyTxnRecord,
 		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 4},
 	},
+	{
+		// VersionParallelCommits gates the STAGING transaction status and the
+		// parallel commits that use it.
+		Key:     VersionParallelCommits,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 5},
+	},
+	{
+		// VersionRowLocking gates the lock-only intents and the wait policies
+		// that row-level locking uses.
+		Key:     VersionRowLocking,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 6},
+	},
+	{
+		// VersionReadCommitted gates the READ COMMITTED isolation level, whose
+		// transactions advance their read timestamp between statements.
+		Key:     VersionReadCommitted,
+		Version: roachpb.Version{Major: 2, Minor: 1, Unstable: 7},
+	},
 
 	// Add new versions here (step two of two).
 
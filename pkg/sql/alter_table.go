@@ -186,13 +186,23 @@ func (n *alterTableNode) startExec(params runParams) error {
 					return fmt.Errorf("multiple primary keys for table %q are not allowed", n.tableDesc.Name)
 				}
 				idx := sqlbase.IndexDescriptor{
-					Name:             string(d.Name),
-					Unique:           true,
-					StoreColumnNames: d.Storing.ToStrings(),
+					Name:              string(d.Name),
+					Unique:            true,
+					StoreColumnNames:  d.Storing.ToStrings(),
+					Deferrable:        d.Deferrable != tree.NotDeferrable,
+					InitiallyDeferred: d.Deferrable == tree.DeferrableInitiallyDeferred,
 				}
 				if err := idx.FillColumns(d.Columns); err != nil {
 					return err
 				}
+				if d.Predicate != nil {
+					predicate, err := makeIndexPredicate(
+						params.ctx, n.tableDesc, d.Predicate, tn, &params.p.semaCtx, params.EvalContext())
+					if err != nil {
+						return err
+					}
+					idx.Predicate = predicate
+				}
 				if d.PartitionBy != nil {
 					partitioning, err := CreatePartitioning(
 						params.ctx, params.p.ExecCfg().Settings,
@@ -380,6 +390,19 @@ func (n *alterTableNode) startExec(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// The columns referenced by the predicate of a partial index are
+				// treated like the indexed columns.
+				predicateColIDs, err := idx.PredicateColumnIDs(n.tableDesc.TableDesc())
+				if err != nil {
+					return err
+				}
+				for _, id := range predicateColIDs {
+					if id == col.ID {
+						containsThisColumn = true
+					} else {
+						containsOnlyThisColumn = false
+					}
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -673,8 +696,14 @@ func applyColumnMutation(
 ) error {
 	switch t := mut.(type) {
 	case *tree.AlterTableAlterColumnType:
+		// Resolve the name of a user-defined type.
+		toType, err := params.p.semaCtx.ResolveUserDefinedType(t.ToType)
+		if err != nil {
+			return err
+		}
+
 		// Convert the parsed type into one of the basic datum types.
-		datum := coltypes.CastTargetToDatumType(t.ToType)
+		datum := coltypes.CastTargetToDatumType(toType)
 
 		// Special handling for STRING COLLATE xy to verify that we recognize the language.
 		if t.Collation != "" {
@@ -0,0 +1,185 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type alterTypeAddValueNode struct {
+	n        *tree.AlterTypeAddValue
+	typeDesc *sqlbase.TypeDescriptor
+}
+
+// AlterTypeAddValue adds a member to an ENUM type.
+// Privileges: CREATE on type.
+func (p *planner) AlterTypeAddValue(
+	ctx context.Context, n *tree.AlterTypeAddValue,
+) (planNode, error) {
+	typeDesc, _, err := p.resolveTypeDesc(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, typeDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &alterTypeAddValueNode{n: n, typeDesc: typeDesc}, nil
+}
+
+func (n *alterTypeAddValueNode) startExec(params runParams) error {
+	ctx, p := params.ctx, params.p
+	desc := n.typeDesc
+
+	if desc.EnumMemberIndex(n.n.NewVal) != -1 {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"enum label %q already exists", n.n.NewVal)
+	}
+
+	pos := len(desc.EnumMembers)
+	if n.n.Placement != nil {
+		pos = desc.EnumMemberIndex(n.n.Placement.ExistingVal)
+		if pos == -1 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"%q is not an existing enum label", n.n.Placement.ExistingVal)
+		}
+		if !n.n.Placement.Before {
+			pos++
+		}
+	}
+	// The member is added as read-only, and is made public by the type schema
+	// changer once every node can decode it.
+	if err := desc.AddEnumMember(n.n.NewVal, pos); err != nil {
+		return err
+	}
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.updateEnumColumnTypes(ctx, desc); err != nil {
+		return err
+	}
+	if err := p.writeTypeDesc(ctx, desc); err != nil {
+		return err
+	}
+	p.queueTypeSchemaChange(desc.ID)
+
+	// Record this type alteration in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the type descriptor
+	// update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogAlterType,
+		int32(desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.n.Name.FQString(), n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*alterTypeAddValueNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterTypeAddValueNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterTypeAddValueNode) Close(context.Context)        {}
+
+// updateEnumColumnTypes copies the members of the given type into the column
+// types of the columns which use it, and writes the new versions of the
+// tables which contain these columns. The tables are found through the
+// back-references of the type; the references to tables which don't use the
+// type anymore are removed from the given descriptor, which the caller
+// writes.
+func (p *planner) updateEnumColumnTypes(
+	ctx context.Context, typeDesc *sqlbase.TypeDescriptor,
+) error {
+	tableIDs := append([]sqlbase.ID(nil), typeDesc.ReferencingDescriptorIDs...)
+	for _, id := range tableIDs {
+		mutDesc, err := p.Tables().getMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			if err == sqlbase.ErrDescriptorNotFound {
+				typeDesc.RemoveReferencingDescriptorID(id)
+				continue
+			}
+			return err
+		}
+		if mutDesc.Dropped() || !setEnumColumnTypes(mutDesc.TableDesc(), typeDesc) {
+			typeDesc.RemoveReferencingDescriptorID(id)
+			continue
+		}
+		if err := p.writeSchemaChange(ctx, mutDesc, sqlbase.InvalidMutationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachEnumColumn calls fn on the columns of the given table, including the
+// columns being added or dropped, which are of a user-defined ENUM type.
+func forEachEnumColumn(table *sqlbase.TableDescriptor, fn func(*sqlbase.ColumnDescriptor)) {
+	maybeCall := func(col *sqlbase.ColumnDescriptor) {
+		if col.Type.SemanticType == sqlbase.ColumnType_ENUM && col.Type.EnumMetadata != nil {
+			fn(col)
+		}
+	}
+	for i := range table.Columns {
+		maybeCall(&table.Columns[i])
+	}
+	for i := range table.Mutations {
+		if col := table.Mutations[i].GetColumn(); col != nil {
+			maybeCall(col)
+		}
+	}
+}
+
+// enumTypeIDs returns the IDs of the user-defined types of the columns of the
+// given table.
+func enumTypeIDs(table *sqlbase.TableDescriptor) []sqlbase.ID {
+	var ids []sqlbase.ID
+	forEachEnumColumn(table, func(col *sqlbase.ColumnDescriptor) {
+		id := col.Type.EnumMetadata.TypeID
+		for _, seen := range ids {
+			if seen == id {
+				return
+			}
+		}
+		ids = append(ids, id)
+	})
+	return ids
+}
+
+// setEnumColumnTypes updates the column types of the columns of the given
+// type with the current members of the type, and returns whether the table
+// has such columns.
+func setEnumColumnTypes(table *sqlbase.TableDescriptor, typeDesc *sqlbase.TypeDescriptor) bool {
+	found := false
+	forEachEnumColumn(table, func(col *sqlbase.ColumnDescriptor) {
+		if col.Type.EnumMetadata.TypeID == typeDesc.ID {
+			col.Type.EnumMetadata = typeDesc.EnumMetadata()
+			found = true
+		}
+	})
+	return found
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// applyJoinNode implements an apply join, which is used to execute correlated
+// subqueries and LATERAL joins that could not be decorrelated by the
+// optimizer:
+//  1. Read the next row from the left side.
+//  2. Plan the right side for that row; the plan is regenerated for each left
+//     row using a callback (implemented by the execbuilder), which replaces
+//     the references to the left side with the values of the row.
+//  3. Run the right side and join its rows with the left row, using the ON
+//     condition.
+// Only inner, left outer, semi and anti joins are supported.
+type applyJoinNode struct {
+	joinType sqlbase.JoinType
+
+	// input is the left side of the join.
+	input planNode
+
+	// planRightSideFn creates the plan of the right side for a left row.
+	planRightSideFn exec.ApplyJoinPlanRightSideFn
+
+	// leftCols and rightCols contain the metadata for the columns of the left
+	// and right sides.
+	leftCols  sqlbase.ResultColumns
+	rightCols sqlbase.ResultColumns
+
+	// onCond is the join condition, which can refer to the columns of both
+	// sides (first the left columns, then the right columns). It is nil if
+	// there is no join condition.
+	onCond     tree.TypedExpr
+	ivarHelper tree.IndexedVarHelper
+
+	// columns contains the metadata for the results of this node. For semi and
+	// anti joins, only the left columns are output.
+	columns sqlbase.ResultColumns
+
+	run applyJoinRun
+}
+
+// applyJoinRun contains the run-time state of applyJoinNode during local
+// execution.
+type applyJoinRun struct {
+	// rightPlan is the plan of the right side for the current left row. It is
+	// nil if the next left row needs to be read.
+	rightPlan planNode
+
+	// matched is set once the current left row has been joined with a row of
+	// the right side.
+	matched bool
+
+	// row contains the current left row, followed by the current row of the
+	// right side.
+	row tree.Datums
+}
+
+// applyJoinNode implements tree.IndexedVarContainer.
+var _ tree.IndexedVarContainer = &applyJoinNode{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (n *applyJoinNode) IndexedVarEval(idx int, ctx *tree.EvalContext) (tree.Datum, error) {
+	return n.run.row[idx].Eval(ctx)
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (n *applyJoinNode) IndexedVarResolvedType(idx int) types.T {
+	if idx < len(n.leftCols) {
+		return n.leftCols[idx].Typ
+	}
+	return n.rightCols[idx-len(n.leftCols)].Typ
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (n *applyJoinNode) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	if idx < len(n.leftCols) {
+		return (*tree.Name)(&n.leftCols[idx].Name)
+	}
+	return (*tree.Name)(&n.rightCols[idx-len(n.leftCols)].Name)
+}
+
+// startExec implements the execStartable interface.
+func (n *applyJoinNode) startExec(params runParams) error {
+	n.run.row = make(tree.Datums, len(n.leftCols)+len(n.rightCols))
+	return nil
+}
+
+// Next is part of the planNode interface.
+func (n *applyJoinNode) Next(params runParams) (bool, error) {
+	for {
+		if n.run.rightPlan == nil {
+			ok, err := n.input.Next(params)
+			if err != nil || !ok {
+				return false, err
+			}
+			if err := n.startRightSide(params); err != nil {
+				return false, err
+			}
+		}
+
+		ok, err := n.run.rightPlan.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			// The right side is exhausted; unmatched left rows are emitted by
+			// left outer and anti joins.
+			n.closeRightSide(params.ctx)
+			if n.run.matched {
+				continue
+			}
+			switch n.joinType {
+			case sqlbase.LeftOuterJoin:
+				right := n.run.row[len(n.leftCols):]
+				for i := range right {
+					right[i] = tree.DNull
+				}
+				return true, nil
+			case sqlbase.LeftAntiJoin:
+				return true, nil
+			}
+			continue
+		}
+
+		copy(n.run.row[len(n.leftCols):], n.run.rightPlan.Values())
+		if n.onCond != nil {
+			params.extendedEvalCtx.PushIVarContainer(n)
+			passesOnCond, err := sqlbase.RunFilter(n.onCond, params.EvalContext())
+			params.extendedEvalCtx.PopIVarContainer()
+			if err != nil {
+				return false, err
+			}
+			if !passesOnCond {
+				continue
+			}
+		}
+		n.run.matched = true
+
+		switch n.joinType {
+		case sqlbase.LeftSemiJoin:
+			// Only the first match of a left row is emitted.
+			n.closeRightSide(params.ctx)
+			return true, nil
+		case sqlbase.LeftAntiJoin:
+			// The left row has a match, so it is not emitted.
+			n.closeRightSide(params.ctx)
+			continue
+		}
+		return true, nil
+	}
+}
+
+// startRightSide sets up and starts the plan of the right side for the
+// current left row.
+func (n *applyJoinNode) startRightSide(params runParams) error {
+	if err := params.p.cancelChecker.Check(); err != nil {
+		return err
+	}
+	leftRow := n.run.row[:len(n.leftCols)]
+	copy(leftRow, n.input.Values())
+	n.run.matched = false
+
+	newPlan, err := n.planRightSideFn(leftRow)
+	if err != nil {
+		return err
+	}
+	n.run.rightPlan = newPlan.(planNode)
+	return startExec(params, n.run.rightPlan)
+}
+
+// closeRightSide closes the plan of the right side for the current left row.
+func (n *applyJoinNode) closeRightSide(ctx context.Context) {
+	n.run.rightPlan.Close(ctx)
+	n.run.rightPlan = nil
+}
+
+// Values is part of the planNode interface.
+func (n *applyJoinNode) Values() tree.Datums {
+	return n.run.row[:len(n.columns)]
+}
+
+// Close is part of the planNode interface.
+func (n *applyJoinNode) Close(ctx context.Context) {
+	n.input.Close(ctx)
+	if n.run.rightPlan != nil {
+		n.closeRightSide(ctx)
+	}
+}
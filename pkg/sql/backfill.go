@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"sort"
 	"time"
@@ -28,8 +29,11 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -55,6 +59,10 @@ const (
 	// many ranges.
 	indexBackfillChunkSize = 100
 
+	// materializedViewRefreshChunkSize is the maximum number of rows of a
+	// materialized view written per chunk during a refresh.
+	materializedViewRefreshChunkSize = 100
+
 	// checkpointInterval is the interval after which a checkpoint of the
 	// schema change is posted.
 	checkpointInterval = 2 * time.Minute
@@ -113,6 +121,7 @@ func (sc *SchemaChanger) runBackfill(
 	// mutations. Collect the elements that are part of the mutation.
 	var droppedIndexDescs []sqlbase.IndexDescriptor
 	var addedIndexDescs []sqlbase.IndexDescriptor
+	var refresh *sqlbase.MaterializedViewRefresh
 
 	var tableDesc *sqlbase.TableDescriptor
 	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
@@ -145,6 +154,8 @@ func (sc *SchemaChanger) runBackfill(
 				}
 			case *sqlbase.DescriptorMutation_Index:
 				addedIndexDescs = append(addedIndexDescs, *t.Index)
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				refresh = t.MaterializedViewRefresh
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -157,6 +168,9 @@ func (sc *SchemaChanger) runBackfill(
 				if !sc.canClearRangeForDrop(t.Index) {
 					droppedIndexDescs = append(droppedIndexDescs, *t.Index)
 				}
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				// The new indexes of a rolled back refresh are removed once the
+				// mutation is complete.
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
 			}
@@ -184,11 +198,205 @@ func (sc *SchemaChanger) runBackfill(
 		if err := sc.backfillIndexes(ctx, evalCtx, lease, version); err != nil {
 			return err
 		}
+		if err := sc.validateDeferrableUniqueIndexes(ctx, addedIndexDescs); err != nil {
+			return err
+		}
+	}
+
+	// Recompute the contents of a materialized view.
+	if refresh != nil {
+		if err := sc.refreshMaterializedView(ctx, evalCtx, lease, tableDesc, refresh); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// refreshMaterializedView runs the query of the materialized view tableDesc
+// as of the timestamp of the refresh, and writes its results into the new
+// indexes of the refresh. The new indexes aren't visible until the refresh
+// mutation is complete.
+//
+// The results are written in chunks, each in its own transaction, while the
+// query runs. They are returned in a deterministic order, and the rowid of each
+// row is its rank in that order, so that rewriting a row writes the same keys.
+// The refresh is checkpointed in the same way as the other backfills: the
+// resume span of the mutation starts at the key of the first row left to write
+// in the new primary index, and a resumed refresh only queries the rows from
+// that one on.
+func (sc *SchemaChanger) refreshMaterializedView(
+	ctx context.Context,
+	evalCtx *extendedEvalContext,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	tableDesc *sqlbase.TableDescriptor,
+	refresh *sqlbase.MaterializedViewRefresh,
+) error {
+	duration := checkpointInterval
+	if sc.testingKnobs.WriteCheckpointInterval > 0 {
+		duration = sc.testingKnobs.WriteCheckpointInterval
+	}
+	chunkSize := sc.getChunkSize(materializedViewRefreshChunkSize)
+	filter := backfill.MaterializedViewRefreshMutationFilter
+
+	desc := protoutil.Clone(tableDesc).(*sqlbase.TableDescriptor)
+	desc.PrimaryIndex = refresh.NewPrimaryIndex
+	desc.Indexes = refresh.NewIndexes
+	desc.Mutations = nil
+	immutDesc := sqlbase.NewImmutableTableDescriptor(*desc)
+
+	var spans []roachpb.Span
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		spans, _, _, err = distsqlrun.GetResumeSpans(
+			ctx, sc.jobRegistry, txn, sc.tableID, sc.mutationID, filter)
+		return err
+	}); err != nil {
+		return err
+	}
+	if len(spans) == 0 {
+		return nil
+	}
+	if refresh.AsOf == (hlc.Timestamp{}) {
+		return errors.Errorf("refresh of %q has no timestamp", tableDesc.Name)
+	}
+	if len(spans) != 1 {
+		return errors.Errorf("expected a single resume span for the refresh, found %+v", spans)
+	}
+
+	// The primary index of a materialized view is on its rowid column.
+	resume := spans[0]
+	prefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(desc, desc.PrimaryIndex.ID))
+	var nextRowID int64
+	if !resume.Key.Equal(prefix) {
+		if !bytes.HasPrefix(resume.Key, prefix) {
+			return errors.Errorf("resume span %s is not in the index %d", resume, desc.PrimaryIndex.ID)
+		}
+		var err error
+		if _, nextRowID, err = encoding.DecodeVarintAscending(resume.Key[len(prefix):]); err != nil {
+			return err
+		}
+	}
+	log.VEventf(ctx, 2, "refresh: resume at row %d", nextRowID)
+
+	checkpoint := func(ctx context.Context, done bool) error {
+		var newResume roachpb.Span
+		if !done {
+			newResume = roachpb.Span{
+				Key:    encoding.EncodeVarintAscending(append(roachpb.Key(nil), prefix...), nextRowID),
+				EndKey: resume.EndKey,
+			}
+		}
+		if err := distsqlrun.WriteResumeSpan(ctx, sc.db, sc.tableID, sc.mutationID,
+			filter, resume, newResume, sc.jobRegistry); err != nil {
+			return err
+		}
+		resume = newResume
+		return nil
+	}
+
+	lastCheckpoint := timeutil.Now()
+	w := &refreshChunkWriter{chunkSize: int(chunkSize)}
+	w.flush = func(ctx context.Context, rows []tree.Datums) error {
+		// First extend the schema change lease.
+		if err := sc.ExtendLease(ctx, lease); err != nil {
+			return err
+		}
+		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			if fn := sc.execCfg.DistSQLRunTestingKnobs.RunBeforeBackfillChunk; fn != nil {
+				if err := fn(resume); err != nil {
+					return err
+				}
+			}
+			if fn := sc.execCfg.DistSQLRunTestingKnobs.RunAfterBackfillChunk; fn != nil {
+				defer fn()
+			}
+
+			i := 0
+			rowID := nextRowID
+			_, err := writeMaterializedViewRows(ctx, txn, &evalCtx.EvalContext, immutDesc,
+				func() (tree.Datums, error) {
+					if i >= len(rows) {
+						return nil, nil
+					}
+					i++
+					return rows[i-1], nil
+				},
+				func() tree.Datum {
+					rowID++
+					return tree.NewDInt(tree.DInt(rowID - 1))
+				},
+				false, /* traceKV */
+			)
+			return err
+		}); err != nil {
+			return err
+		}
+		nextRowID += int64(len(rows))
+		if timeutil.Since(lastCheckpoint) > duration {
+			if err := checkpoint(ctx, false /* done */); err != nil {
+				return err
+			}
+			lastCheckpoint = timeutil.Now()
+		}
+		return nil
+	}
+
+	// The query runs in a transaction of its own, which only reads at the
+	// timestamp of the refresh.
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, refresh.AsOf)
+		w.rows = w.rows[:0]
+		w.err = nil
+		return runMaterializedViewQuery(ctx, txn, sc.execCfg,
+			materializedViewRefreshQuery(desc, nextRowID), w)
+	}); err != nil {
+		return err
+	}
+	if len(w.rows) > 0 {
+		if err := w.flush(ctx, w.rows); err != nil {
+			return err
+		}
+	}
+	return checkpoint(ctx, true /* done */)
+}
+
+// refreshChunkWriter is a rowResultWriter which buffers the rows of a
+// materialized view refresh, and hands them to flush in chunks of chunkSize
+// rows.
+type refreshChunkWriter struct {
+	chunkSize int
+	flush     func(ctx context.Context, rows []tree.Datums) error
+	rows      []tree.Datums
+	err       error
+}
+
+var _ rowResultWriter = &refreshChunkWriter{}
+
+// AddRow is part of the rowResultWriter interface.
+func (w *refreshChunkWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	w.rows = append(w.rows, append(tree.Datums(nil), row...))
+	if len(w.rows) < w.chunkSize {
+		return nil
+	}
+	err := w.flush(ctx, w.rows)
+	w.rows = w.rows[:0]
+	return err
+}
+
+// IncrementRowsAffected is part of the rowResultWriter interface.
+func (w *refreshChunkWriter) IncrementRowsAffected(n int) {}
+
+// SetError is part of the rowResultWriter interface.
+func (w *refreshChunkWriter) SetError(err error) {
+	w.err = err
+}
+
+// Err is part of the rowResultWriter interface.
+func (w *refreshChunkWriter) Err() error {
+	return w.err
+}
+
 func (sc *SchemaChanger) getTableVersion(
 	ctx context.Context, txn *client.Txn, tc *TableCollection, version sqlbase.DescriptorVersion,
 ) (*sqlbase.ImmutableTableDescriptor, error) {
@@ -473,6 +681,33 @@ func (sc *SchemaChanger) backfillIndexes(
 		backfill.IndexMutationFilter)
 }
 
+// validateDeferrableUniqueIndexes checks that the rows of the table don't
+// violate the deferrable unique constraints among the given backfilled
+// indexes. Unlike the other unique indexes, whose backfill fails when it
+// writes a duplicate entry, a deferrable unique index is encoded like a
+// non-unique index. The rows written after the backfill are checked by the
+// statements which write them.
+func (sc *SchemaChanger) validateDeferrableUniqueIndexes(
+	ctx context.Context, indexes []sqlbase.IndexDescriptor,
+) error {
+	for i := range indexes {
+		if !indexes[i].Deferrable {
+			continue
+		}
+		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, sc.tableID)
+			if err != nil {
+				return err
+			}
+			return row.ValidateUniqueIndex(
+				ctx, txn, sqlbase.NewImmutableTableDescriptor(*tableDesc), &indexes[i])
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sc *SchemaChanger) truncateAndBackfillColumns(
 	ctx context.Context,
 	evalCtx *extendedEvalContext,
@@ -535,9 +770,14 @@ func runSchemaChangesInTxn(
 				doneColumnBackfill = true
 
 			case *sqlbase.DescriptorMutation_Index:
-				if err := indexBackfillInTxn(ctx, txn, immutDesc, traceKV); err != nil {
+				if err := indexBackfillInTxn(ctx, txn, evalCtx, immutDesc, traceKV); err != nil {
 					return err
 				}
+				if idx := m.GetIndex(); idx.Deferrable {
+					if err := row.ValidateUniqueIndex(ctx, txn, immutDesc, idx); err != nil {
+						return err
+					}
+				}
 
 			default:
 				return errors.Errorf("unsupported mutation: %+v", m)
@@ -630,10 +870,14 @@ func columnBackfillInTxn(
 }
 
 func indexBackfillInTxn(
-	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.ImmutableTableDescriptor, traceKV bool,
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	tableDesc *sqlbase.ImmutableTableDescriptor,
+	traceKV bool,
 ) error {
 	var backfiller backfill.IndexBackfiller
-	if err := backfiller.Init(tableDesc); err != nil {
+	if err := backfiller.Init(evalCtx, tableDesc); err != nil {
 		return err
 	}
 	sp := tableDesc.PrimaryIndexSpan()
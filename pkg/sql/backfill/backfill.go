@@ -46,6 +46,12 @@ func IndexMutationFilter(m sqlbase.DescriptorMutation) bool {
 	return m.GetIndex() != nil && m.Direction == sqlbase.DescriptorMutation_ADD
 }
 
+// MaterializedViewRefreshMutationFilter is a filter that allows mutations that
+// refresh a materialized view.
+func MaterializedViewRefreshMutationFilter(m sqlbase.DescriptorMutation) bool {
+	return m.GetMaterializedViewRefresh() != nil && m.Direction == sqlbase.DescriptorMutation_ADD
+}
+
 // backfiller is common to a ColumnBackfiller or an IndexBackfiller.
 type backfiller struct {
 	fetcher row.Fetcher
@@ -176,6 +182,7 @@ func (cb *ColumnBackfiller) RunColumnBackfillChunk(
 		cb.updateCols,
 		requestedCols,
 		row.UpdaterOnlyColumns,
+		row.CheckFKs,
 		cb.evalCtx,
 		&cb.alloc,
 	)
@@ -302,10 +309,16 @@ type IndexBackfiller struct {
 
 	types   []sqlbase.ColumnType
 	rowVals tree.Datums
+
+	// predicates determines which rows are backfilled into the added partial
+	// indexes.
+	predicates sqlbase.PartialIndexPredicates
 }
 
 // Init initializes an IndexBackfiller.
-func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
+func (ib *IndexBackfiller) Init(
+	evalCtx *tree.EvalContext, desc *sqlbase.ImmutableTableDescriptor,
+) error {
 	numCols := len(desc.Columns)
 	cols := desc.Columns
 	if len(desc.Mutations) > 0 {
@@ -337,6 +350,19 @@ func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
 		}
 	}
 
+	var err error
+	ib.predicates, err = sqlbase.MakePartialIndexPredicates(desc.TableDesc(), ib.added, evalCtx)
+	if err != nil {
+		return err
+	}
+	for _, colID := range ib.predicates.ColumnIDs() {
+		for i, col := range cols {
+			if col.ID == colID {
+				valNeededForCol.Add(i)
+			}
+		}
+	}
+
 	ib.types = make([]sqlbase.ColumnType, len(cols))
 	for i := range cols {
 		ib.types[i] = cols[i].Type
@@ -412,7 +438,25 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 			ib.rowVals, buffer); err != nil {
 			return nil, nil, err
 		}
-		entries = append(entries, buffer...)
+		if !ib.predicates.HasPredicates() {
+			entries = append(entries, buffer...)
+			continue
+		}
+		// Skip the entries of the partial indexes whose predicate the row
+		// doesn't satisfy. Partial indexes can't be inverted, so they have
+		// exactly one entry per row, at the position of the index.
+		for j := range buffer {
+			if j < len(ib.added) {
+				ok, err := ib.predicates.Satisfies(j, ib.colIdxMap, ib.rowVals)
+				if err != nil {
+					return nil, nil, err
+				}
+				if !ok {
+					continue
+				}
+			}
+			entries = append(entries, buffer[j])
+		}
 	}
 	return entries, ib.fetcher.Key(), nil
 }
@@ -0,0 +1,156 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/kv"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// Bounded staleness reads are SELECT statements whose AS OF SYSTEM TIME clause
+// uses with_max_staleness() or with_min_timestamp(). Instead of reading at a
+// fixed timestamp, such a statement reads at the newest timestamp at which
+// every range it touches can be served by a replica local to the gateway,
+// i.e. the minimum over these ranges of the closed timestamps of the local
+// replicas. The statement is rejected if this timestamp is older than the
+// bound given in the clause.
+//
+// The statement is first planned at the current time to determine the spans
+// it reads. Once the timestamp is negotiated, the statement is planned again
+// at that timestamp so that the table descriptors it uses are consistent with
+// the data it reads. The plan is executed locally so that the DistSender
+// routes its reads to the local replicas.
+
+// replanBoundedStaleness negotiates the timestamp of a bounded staleness read
+// whose plan, built at the current time, is in planner.curPlan, and replaces
+// that plan with one built at the negotiated timestamp.
+func (ex *connExecutor) replanBoundedStaleness(
+	ctx context.Context, stmt Statement, planner *planner,
+) (planFlags, error) {
+	ts, err := ex.negotiateBoundedStaleness(ctx, planner)
+	planner.curPlan.close(ctx)
+	if err != nil {
+		planner.curPlan = planTop{AST: stmt.AST}
+		return 0, err
+	}
+	log.VEventf(ctx, 2, "bounded staleness read negotiated at %s", ts)
+
+	// The leases acquired while planning at the current time may not be valid
+	// at the negotiated timestamp.
+	planner.Tables().releaseLeases(ctx)
+	planner.semaCtx.AsOfTimestamp = &ts
+	planner.txn.SetFixedTimestamp(ctx, ts)
+	return ex.makeExecPlan(ctx, stmt, planner)
+}
+
+// negotiateBoundedStaleness returns the newest timestamp at which all the
+// spans read by planner.curPlan can be served by replicas local to this node.
+func (ex *connExecutor) negotiateBoundedStaleness(
+	ctx context.Context, planner *planner,
+) (hlc.Timestamp, error) {
+	minTS := planner.semaCtx.AsOfMinTimestamp
+	if !closedts.FollowerReadsEnabled.Get(&ex.server.cfg.Settings.SV) {
+		return hlc.Timestamp{}, errors.Errorf(
+			"bounded staleness reads require kv.closed_timestamp.follower_reads_enabled")
+	}
+	spans, err := collectReadSpans(ctx, &planner.curPlan)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+
+	ds := ex.server.cfg.DistSender
+	ts := ex.server.cfg.Clock.Now()
+	ri := kv.NewRangeIterator(ds)
+	for _, span := range spans {
+		var rs roachpb.RSpan
+		if rs.Key, err = keys.Addr(span.Key); err != nil {
+			return hlc.Timestamp{}, err
+		}
+		if rs.EndKey, err = keys.AddrUpperBound(span.EndKey); err != nil {
+			return hlc.Timestamp{}, err
+		}
+		for ri.Seek(ctx, rs.Key, kv.Ascending); ; ri.Next(ctx) {
+			if !ri.Valid() {
+				return hlc.Timestamp{}, ri.Error().GoError()
+			}
+			desc := ri.Desc()
+			closed, ok := ds.LocalClosedTimestamp(desc.RangeID)
+			if !ok {
+				return hlc.Timestamp{}, errors.Errorf(
+					"bounded staleness read cannot be served locally: "+
+						"no local replica of range %d", desc.RangeID)
+			}
+			if closed.Less(minTS) {
+				return hlc.Timestamp{}, errors.Errorf(
+					"bounded staleness read cannot be served locally: "+
+						"staleness bound would be exceeded (range %d closed at %s, minimum %s)",
+					desc.RangeID, closed, minTS)
+			}
+			ts.Backward(closed)
+			if !ri.NeedAnother(rs) {
+				break
+			}
+		}
+	}
+	return ts, nil
+}
+
+// collectReadSpans returns the spans read by the scans of the given plan and
+// of its subqueries. Scans whose spans are only known at execution time, e.g.
+// the primary index lookups of an index join, are assumed to read their whole
+// index.
+func collectReadSpans(ctx context.Context, plan *planTop) (roachpb.Spans, error) {
+	var spans roachpb.Spans
+	addScan := func(n *scanNode) {
+		if n.desc.IsVirtualTable() {
+			return
+		}
+		if len(n.spans) > 0 {
+			spans = append(spans, n.spans...)
+			return
+		}
+		spans = append(spans, n.desc.IndexSpan(n.index.ID))
+	}
+	observer := planObserver{
+		enterNode: func(_ context.Context, _ string, node planNode) (bool, error) {
+			switch n := node.(type) {
+			case *scanNode:
+				addScan(n)
+			case *zigzagJoinNode:
+				// The scans of a zigzag join are not visited by walkPlan.
+				for _, side := range n.sides {
+					addScan(side.scan)
+				}
+			}
+			return true, nil
+		},
+	}
+	if err := walkPlan(ctx, plan.plan, observer); err != nil {
+		return nil, err
+	}
+	for i := range plan.subqueryPlans {
+		if err := walkPlan(ctx, plan.subqueryPlans[i].plan, observer); err != nil {
+			return nil, err
+		}
+	}
+	return spans, nil
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// rowBuffer is implemented by the nodes whose rows can be read by a
+// scanBufferNode.
+type rowBuffer interface {
+	planNode
+
+	// bufferedRows returns the rows that a scanBufferNode should return.
+	bufferedRows() *sqlbase.RowContainer
+}
+
+var _ rowBuffer = &bufferNode{}
+var _ rowBuffer = &recursiveCTENode{}
+
+// bufferNode passes through the rows of its input, and also saves them in a
+// buffer. Once the node has been run to completion, the buffered rows can be
+// read (any number of times) by scanBufferNodes. It is used to make the input
+// of a mutation available to the plans that check its foreign keys.
+type bufferNode struct {
+	plan planNode
+
+	// label is a string used to describe the node in an EXPLAIN output.
+	label string
+
+	run bufferRun
+}
+
+type bufferRun struct {
+	rows   *sqlbase.RowContainer
+	values tree.Datums
+}
+
+// startExec implements the execStartable interface.
+func (n *bufferNode) startExec(params runParams) error {
+	n.run.rows = sqlbase.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(planColumns(n.plan)),
+		0, /* rowCapacity */
+	)
+	return nil
+}
+
+// Next is part of the planNode interface.
+func (n *bufferNode) Next(params runParams) (bool, error) {
+	if err := params.p.cancelChecker.Check(); err != nil {
+		return false, err
+	}
+	ok, err := n.plan.Next(params)
+	if !ok || err != nil {
+		return false, err
+	}
+	n.run.values, err = n.run.rows.AddRow(params.ctx, n.plan.Values())
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Values is part of the planNode interface.
+func (n *bufferNode) Values() tree.Datums {
+	return n.run.values
+}
+
+// bufferedRows is part of the rowBuffer interface.
+func (n *bufferNode) bufferedRows() *sqlbase.RowContainer {
+	return n.run.rows
+}
+
+// Close is part of the planNode interface.
+func (n *bufferNode) Close(ctx context.Context) {
+	n.plan.Close(ctx)
+	if n.run.rows != nil {
+		n.run.rows.Close(ctx)
+		n.run.rows = nil
+	}
+}
+
+// scanBufferNode returns the rows saved by a rowBuffer: the working table of a
+// recursiveCTENode (inside the plan of an iteration of the recursive CTE), or
+// the rows saved by a bufferNode (inside a postquery).
+type scanBufferNode struct {
+	buffer rowBuffer
+
+	// label is a string used to describe the node in an EXPLAIN output.
+	label string
+
+	columns sqlbase.ResultColumns
+
+	nextRowIdx int
+}
+
+// startExec implements the execStartable interface.
+func (n *scanBufferNode) startExec(params runParams) error {
+	n.nextRowIdx = 0
+	return nil
+}
+
+// Next is part of the planNode interface.
+func (n *scanBufferNode) Next(params runParams) (bool, error) {
+	n.nextRowIdx++
+	return n.nextRowIdx <= n.buffer.bufferedRows().Len(), nil
+}
+
+// Values is part of the planNode interface.
+func (n *scanBufferNode) Values() tree.Datums {
+	return n.buffer.bufferedRows().At(n.nextRowIdx - 1)
+}
+
+// Close is part of the planNode interface.
+func (n *scanBufferNode) Close(ctx context.Context) {}
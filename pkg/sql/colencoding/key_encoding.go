@@ -16,11 +16,14 @@ package colencoding
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/pkg/errors"
 )
 
@@ -201,7 +204,8 @@ func decodeTableKeyToCol(
 			rkey, f, err = encoding.DecodeFloatDescending(key)
 		}
 		vec.Float64()[idx] = f
-	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME,
+		sqlbase.ColumnType_UUID:
 		var r []byte
 		if dir == sqlbase.IndexDescriptor_ASC {
 			rkey, r, err = encoding.DecodeBytesAscending(key, nil)
@@ -217,6 +221,22 @@ func decodeTableKeyToCol(
 			rkey, t, err = encoding.DecodeVarintDescending(key)
 		}
 		vec.Int64()[idx] = t
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		var t time.Time
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, t, err = encoding.DecodeTimeAscending(key)
+		} else {
+			rkey, t, err = encoding.DecodeTimeDescending(key)
+		}
+		vec.Timestamp()[idx] = t
+	case sqlbase.ColumnType_INTERVAL:
+		var d duration.Duration
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, d, err = encoding.DecodeDurationAscending(key)
+		} else {
+			rkey, d, err = encoding.DecodeDurationDescending(key)
+		}
+		vec.Interval()[idx] = d
 	default:
 		panic(fmt.Sprintf("unsupported type %+v", valType))
 	}
@@ -252,7 +272,8 @@ func skipTableKey(
 		} else {
 			rkey, _, err = encoding.DecodeFloatDescending(key)
 		}
-	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME,
+		sqlbase.ColumnType_UUID:
 		if dir == sqlbase.IndexDescriptor_ASC {
 			rkey, _, err = encoding.DecodeBytesAscending(key, nil)
 		} else {
@@ -264,6 +285,18 @@ func skipTableKey(
 		} else {
 			rkey, _, err = encoding.DecodeDecimalDescending(key, nil)
 		}
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, _, err = encoding.DecodeTimeAscending(key)
+		} else {
+			rkey, _, err = encoding.DecodeTimeDescending(key)
+		}
+	case sqlbase.ColumnType_INTERVAL:
+		if dir == sqlbase.IndexDescriptor_ASC {
+			rkey, _, err = encoding.DecodeDurationAscending(key)
+		} else {
+			rkey, _, err = encoding.DecodeDurationDescending(key)
+		}
 	default:
 		panic(fmt.Sprintf("unsupported type %+v", valType))
 	}
@@ -312,7 +345,8 @@ func UnmarshalColumnValueToCol(
 		vec.Float64()[idx] = v
 	case sqlbase.ColumnType_DECIMAL:
 		err = value.GetDecimalInto(&vec.Decimal()[idx])
-	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME,
+		sqlbase.ColumnType_UUID:
 		var v []byte
 		v, err = value.GetBytes()
 		vec.Bytes()[idx] = v
@@ -320,6 +354,23 @@ func UnmarshalColumnValueToCol(
 		var v int64
 		v, err = value.GetInt()
 		vec.Int64()[idx] = v
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		var v time.Time
+		v, err = value.GetTime()
+		vec.Timestamp()[idx] = v
+	case sqlbase.ColumnType_INTERVAL:
+		var v duration.Duration
+		v, err = value.GetDuration()
+		vec.Interval()[idx] = v
+	case sqlbase.ColumnType_JSONB:
+		var v []byte
+		v, err = value.GetBytes()
+		if err != nil {
+			return err
+		}
+		var j json.JSON
+		j, err = json.FromEncoding(v)
+		vec.JSON()[idx] = j
 	default:
 		return errors.Errorf("unsupported column type: %s", typ.SemanticType)
 	}
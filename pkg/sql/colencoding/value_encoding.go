@@ -15,9 +15,14 @@
 package colencoding
 
 import (
+	"time"
+
 	"github.com/cockroachdb/cockroach/pkg/sql/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -94,6 +99,27 @@ func decodeUntaggedDatumToCol(
 		default:
 			return buf, errors.Errorf("unknown integer width %d", t.Width)
 		}
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		var v time.Time
+		buf, v, err = encoding.DecodeUntaggedTimeValue(buf)
+		vec.Timestamp()[idx] = v
+	case sqlbase.ColumnType_INTERVAL:
+		var d duration.Duration
+		buf, d, err = encoding.DecodeUntaggedDurationValue(buf)
+		vec.Interval()[idx] = d
+	case sqlbase.ColumnType_UUID:
+		var u uuid.UUID
+		buf, u, err = encoding.DecodeUntaggedUUIDValue(buf)
+		vec.Bytes()[idx] = u.GetBytes()
+	case sqlbase.ColumnType_JSONB:
+		var data []byte
+		buf, data, err = encoding.DecodeUntaggedBytesValue(buf)
+		if err != nil {
+			return buf, err
+		}
+		var j json.JSON
+		j, err = json.FromEncoding(data)
+		vec.JSON()[idx] = j
 	default:
 		return buf, errors.Errorf("couldn't decode type %s", t)
 	}
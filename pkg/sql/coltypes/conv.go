@@ -133,6 +133,8 @@ func DatumTypeToColumnType(t types.T) (T, error) {
 		return colTyp, nil
 	case types.TOidWrapper:
 		return DatumTypeToColumnType(typ.T)
+	case *types.TEnum:
+		return &TEnum{Name: typ.TypeName, Typ: typ}, nil
 	}
 
 	return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
@@ -205,6 +207,13 @@ func CastTargetToDatumType(t CastTargetType) types.T {
 		return ret
 	case *TOid:
 		return TOidToType(ct)
+	case *TEnum:
+		if ct.Typ == nil {
+			// The name of the type has not been resolved yet. The result has no
+			// members and, like types.FamEnum, is equivalent to every ENUM type.
+			return &types.TEnum{TypeName: ct.Name}
+		}
+		return ct.Typ
 	default:
 		panic(fmt.Sprintf("unexpected CastTarget %T", t))
 	}
@@ -64,6 +64,7 @@ func (*TBytes) columnType()          {}
 func (*TCollatedString) columnType() {}
 func (*TDate) columnType()           {}
 func (*TDecimal) columnType()        {}
+func (*TEnum) columnType()           {}
 func (*TFloat) columnType()          {}
 func (*TIPAddr) columnType()         {}
 func (*TInt) columnType()            {}
@@ -88,6 +89,7 @@ func (*TBytes) castTargetType()          {}
 func (*TCollatedString) castTargetType() {}
 func (*TDate) castTargetType()           {}
 func (*TDecimal) castTargetType()        {}
+func (*TEnum) castTargetType()           {}
 func (*TFloat) castTargetType()          {}
 func (*TIPAddr) castTargetType()         {}
 func (*TInt) castTargetType()            {}
@@ -111,6 +113,7 @@ func (node *TBytes) String() string          { return ColTypeAsString(node) }
 func (node *TCollatedString) String() string { return ColTypeAsString(node) }
 func (node *TDate) String() string           { return ColTypeAsString(node) }
 func (node *TDecimal) String() string        { return ColTypeAsString(node) }
+func (node *TEnum) String() string           { return ColTypeAsString(node) }
 func (node *TFloat) String() string          { return ColTypeAsString(node) }
 func (node *TIPAddr) String() string         { return ColTypeAsString(node) }
 func (node *TInt) String() string            { return ColTypeAsString(node) }
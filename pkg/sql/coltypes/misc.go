@@ -18,6 +18,7 @@ import (
 	"bytes"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 )
 
 // This file contains column type definitions that don't fit
@@ -77,3 +78,19 @@ func (node *TOid) TypeName() string { return node.Name }
 func (node *TOid) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
 	buf.WriteString(node.Name)
 }
+
+// TEnum represents a user-defined ENUM type. The parser only knows the name
+// of the type; Typ is set once the name has been resolved to the descriptor
+// of the type.
+type TEnum struct {
+	Name string
+	Typ  *types.TEnum
+}
+
+// TypeName implements the ColTypeFormatter interface.
+func (node *TEnum) TypeName() string { return node.Name }
+
+// Format implements the ColTypeFormatter interface.
+func (node *TEnum) Format(buf *bytes.Buffer, f lex.EncodeFlags) {
+	lex.EncodeRestrictedSQLIdent(buf, node.Name, f)
+}
@@ -32,13 +32,16 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil"
@@ -625,6 +628,10 @@ func (s *Server) newConnExecutorWithTxn(
 		txn.OrigTimestamp().GoTime(),
 		txn.UserPriority(),
 		tree.ReadWrite,
+		// The higher-level txn is managed by its owner; statements executed by
+		// the InternalExecutor don't advance the read timestamp of a
+		// READ_COMMITTED txn.
+		enginepb.SERIALIZABLE,
 		txn,
 		ex.transitionCtx)
 	return ex, nil
@@ -731,6 +738,11 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 	// cancellation. Now what?
 	_ = ex.synchronizeParallelStmts(ctx)
 
+	// An Aborted txn may have kept its KV txn open for a ROLLBACK TO SAVEPOINT.
+	if _, ok := ex.machine.CurState().(stateAborted); ok {
+		ex.state.rollbackKeptTxn()
+	}
+
 	if closeType == normalClose {
 		// We'll cleanup the SQL txn by creating a non-retriable (commit:true) event.
 		// This event is guaranteed to be accepted in every state.
@@ -751,6 +763,16 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		// Close all statements and prepared portals.
 		ex.extraTxnState.prepStmtsNamespace.resetTo(ctx, prepStmtNamespace{})
 		ex.extraTxnState.prepStmtsNamespaceAtTxnRewindPos.resetTo(ctx, prepStmtNamespace{})
+
+		// Drop the temporary tables of the session, if it created any. If this
+		// fails, the TemporaryObjectCleaner will eventually drop them.
+		if ex.sessionData.SearchPath.GetTemporarySchemaName() != "" {
+			if err := cleanupSessionTempObjects(
+				ctx, ex.server.cfg.DB, ex.server.cfg.InternalExecutor, ex.sessionID,
+			); err != nil {
+				log.Warningf(ctx, "error while cleaning up temporary objects: %s", err)
+			}
+		}
 	}
 
 	if ex.sessionTracing.Enabled() {
@@ -842,6 +864,20 @@ type connExecutor struct {
 		// stateOpen.
 		autoRetryCounter int
 
+		// stmtRetryPos is the position of the last statement of a READ COMMITTED
+		// txn that was retried on its own, and stmtRetries is the number of times
+		// it was retried. See maybeRetryReadCommittedStmt.
+		stmtRetryPos CmdPos
+		stmtRetries  int
+
+		// numDDL is the number of DDL statements executed in the transaction. It
+		// is used to reject rolling back DDL statements to a savepoint.
+		numDDL int
+
+		// deferredChecks accumulates the checks of the deferred foreign key and
+		// unique constraints, which are run when the transaction commits.
+		deferredChecks row.DeferredChecks
+
 		// txnRewindPos is the position within stmtBuf to which we'll rewind when
 		// performing automatic retries. This is more or less the position where the
 		// current transaction started.
@@ -1020,6 +1056,12 @@ func (ex *connExecutor) resetExtraTxnState(
 	ex.extraTxnState.tables.databaseCache = dbCacheHolder.getDatabaseCache()
 
 	ex.extraTxnState.autoRetryCounter = 0
+	ex.extraTxnState.stmtRetries = 0
+	ex.extraTxnState.numDDL = 0
+	ex.extraTxnState.deferredChecks.Reset()
+
+	// The savepoints don't survive a restart of the transaction.
+	ex.state.savepoints = nil
 
 	// Close all portals.
 	for name, p := range ex.extraTxnState.prepStmtsNamespace.portals {
@@ -1352,7 +1394,11 @@ func (ex *connExecutor) run(
 				return err
 			}
 		case rewind:
-			ex.rewindPrepStmtNamespace(ex.Ctx())
+			// Statements of READ COMMITTED txns are rewound without restarting the
+			// txn; the prepared statements created before them remain valid.
+			if advInfo.txnEvent == txnRestart {
+				ex.rewindPrepStmtNamespace(ex.Ctx())
+			}
 			advInfo.rewCap.rewindAndUnlock(ex.Ctx())
 		case stayInPlace:
 			// Nothing to do. The same statement will be executed again.
@@ -1413,6 +1459,11 @@ func (ex *connExecutor) updateTxnRewindPosMaybe(
 		// if the rewind point is not current set to the command's position
 		// (i.e. we don't do anything if txnRewindPos != pos).
 
+		if advInfo.code == rewind {
+			// A statement of a READ COMMITTED txn is being retried; the statement
+			// is at or past txnRewindPos, which stays unchanged.
+			return nil
+		}
 		if advInfo.code != advanceOne {
 			panic(fmt.Sprintf("unexpected advanceCode: %s", advInfo.code))
 		}
@@ -1622,6 +1673,23 @@ func (ex *connExecutor) getRewindTxnCapability() (rewindCapability, bool) {
 	}, true
 }
 
+// getRewindStmtCapability checks whether the statement at position pos can be
+// executed again, which is the case if no results at or past pos have been
+// delivered to the client. It is used to retry the statements of READ COMMITTED
+// transactions on their own.
+func (ex *connExecutor) getRewindStmtCapability(pos CmdPos) (rewindCapability, bool) {
+	cl := ex.clientComm.LockCommunication()
+	if cl.ClientPos() >= pos {
+		cl.Close()
+		return rewindCapability{}, false
+	}
+	return rewindCapability{
+		cl:        cl,
+		buf:       ex.stmtBuf,
+		rewindPos: pos,
+	}, true
+}
+
 // isCommit returns true if stmt is a "COMMIT" statement.
 func isCommit(stmt tree.Statement) bool {
 	_, ok := stmt.(*tree.CommitTransaction)
@@ -1642,6 +1710,16 @@ func (ex *connExecutor) makeErrEvent(err error, stmt tree.Statement) (fsm.Event,
 			panic(fmt.Sprintf("retriable error in unexpected state: %#v",
 				ex.machine.CurState()))
 		}
+		if ex.state.isolation == enginepb.READ_COMMITTED {
+			// Retriable errors don't restart READ COMMITTED txns, as they are
+			// generally handled by retrying the statement that encountered them
+			// (see maybeRetryReadCommittedStmt). The txn needs to be restarted
+			// before it can be retried from the beginning.
+			retryErr := err.(*roachpb.TransactionRetryWithProtoRefreshError)
+			if retryErr.Transaction.ID == ex.state.mu.txn.ID() {
+				ex.state.mu.txn.ManualRestart(ex.state.Ctx, retryErr.Transaction.Timestamp)
+			}
+		}
 		rc, canAutoRetry := ex.getRewindTxnCapability()
 		ev := eventRetriableErr{
 			IsCommit:     fsm.FromBool(isCommit(stmt)),
@@ -1736,8 +1814,21 @@ func (ex *connExecutor) setTransactionModes(modes tree.TransactionModes) error {
 			return err
 		}
 	}
-	if modes.Isolation != tree.UnspecifiedIsolation && modes.Isolation != tree.SerializableIsolation {
-		return errors.Errorf("unknown isolation level: %s", modes.Isolation)
+	if modes.Isolation != tree.UnspecifiedIsolation {
+		iso, err := isolationToProto(modes.Isolation)
+		if err != nil {
+			return err
+		}
+		if err := checkIsolationSupported(ex.server.cfg.Settings, iso); err != nil {
+			return err
+		}
+		// Implicit transactions are always SERIALIZABLE, as each of them runs a
+		// single statement.
+		if !ex.implicitTxn() {
+			if err := ex.state.setIsolation(iso); err != nil {
+				return err
+			}
+		}
 	}
 
 	return ex.state.setReadOnlyMode(modes.ReadWriteMode)
@@ -1760,6 +1851,49 @@ func priorityToProto(mode tree.UserPriority) (roachpb.UserPriority, error) {
 	return pri, nil
 }
 
+func isolationToProto(level tree.IsolationLevel) (enginepb.IsolationType, error) {
+	switch level {
+	case tree.UnspecifiedIsolation, tree.SerializableIsolation:
+		return enginepb.SERIALIZABLE, nil
+	case tree.ReadCommittedIsolation:
+		return enginepb.READ_COMMITTED, nil
+	default:
+		return enginepb.SERIALIZABLE, errors.Errorf("unknown isolation level: %s", level)
+	}
+}
+
+// checkIsolationSupported returns an error if the given isolation level can't
+// be used until the cluster version is upgraded.
+func checkIsolationSupported(st *cluster.Settings, iso enginepb.IsolationType) error {
+	if iso == enginepb.READ_COMMITTED && !st.Version.IsActive(cluster.VersionReadCommitted) {
+		return fmt.Errorf("cluster version does not support READ COMMITTED isolation (required: %s)",
+			cluster.VersionByKey(cluster.VersionReadCommitted))
+	}
+	return nil
+}
+
+// isolationName returns the name of the isolation level in the lowercase
+// format used by the transaction_isolation session variable.
+func isolationName(iso enginepb.IsolationType) string {
+	if iso == enginepb.READ_COMMITTED {
+		return "read committed"
+	}
+	return "serializable"
+}
+
+func (ex *connExecutor) isolationWithSessionDefault(
+	level tree.IsolationLevel,
+) (enginepb.IsolationType, error) {
+	if level == tree.UnspecifiedIsolation {
+		return ex.sessionData.DefaultIsolation, nil
+	}
+	iso, err := isolationToProto(level)
+	if err != nil {
+		return iso, err
+	}
+	return iso, checkIsolationSupported(ex.server.cfg.Settings, iso)
+}
+
 func (ex *connExecutor) readWriteModeWithSessionDefault(
 	mode tree.ReadWriteMode,
 ) tree.ReadWriteMode {
@@ -1828,7 +1962,9 @@ func (ex *connExecutor) evalCtx(
 		DistSQLPlanner:  ex.server.cfg.DistSQLPlanner,
 		TxnModesSetter:  ex,
 		SchemaChangers:  &ex.extraTxnState.schemaChangers,
+		DeferredChecks:  &ex.extraTxnState.deferredChecks,
 		schemaAccessors: scInterface,
+		SessionID:       ex.sessionID,
 	}
 }
 
@@ -1883,6 +2019,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx = tree.MakeSemaContext(ex.sessionData.User == security.RootUser)
 	p.semaCtx.Location = &ex.sessionData.DataConversion.Location
 	p.semaCtx.SearchPath = ex.sessionData.SearchPath
+	p.semaCtx.TypeResolver = p
 	p.semaCtx.AsOfTimestamp = nil
 
 	p.extendedEvalCtx = ex.evalCtx(ctx, p, stmtTS)
@@ -1942,7 +2079,7 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 			return advanceInfo{}, err
 		}
 		scc := &ex.extraTxnState.schemaChangers
-		if len(scc.schemaChangers) != 0 {
+		if !scc.empty() {
 			ieFactory := func(ctx context.Context, sd *sessiondata.SessionData) sqlutil.InternalExecutor {
 				ie := MakeSessionBoundInternalExecutor(
 					ctx,
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/fsm"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -198,7 +199,17 @@ func (ex *connExecutor) execStmtInOpenState(
 		}
 	}()
 
+	// stmtSavepoint is established at the beginning of the statements of READ
+	// COMMITTED txns, which are retried on their own on retriable errors.
+	var stmtSavepoint client.SavepointToken
 	makeErrEvent := func(err error) (fsm.Event, fsm.EventPayload, error) {
+		if stmtSavepoint != nil {
+			if ev, payload, ok := ex.maybeRetryReadCommittedStmt(
+				ctx, err, stmt.AST, stmtSavepoint,
+			); ok {
+				return ev, payload, nil
+			}
+		}
 		ev, payload := ex.makeErrEvent(err, stmt.AST)
 		return ev, payload, nil
 	}
@@ -222,6 +233,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.ReleaseSavepoint:
+		if !ex.isRestartSavepointName(s.Savepoint) {
+			if err := ex.execReleaseSavepointInOpenState(os, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		if err := ex.validateSavepointName(s.Savepoint); err != nil {
 			return makeErrEvent(err)
 		}
@@ -240,6 +257,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.Savepoint:
+		if !ex.isRestartSavepointName(s.Name) {
+			if err := ex.execSavepointInOpenState(ctx, os, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		// Ensure that the user isn't trying to run BEGIN; SAVEPOINT; SAVEPOINT;
 		if ex.state.activeSavepointName != "" {
 			err := fmt.Errorf("SAVEPOINT may not be nested")
@@ -256,7 +279,7 @@ func (ex *connExecutor) execStmtInOpenState(
 		// See also:
 		// https://github.com/cockroachdb/cockroach/issues/15012
 		meta := ex.state.mu.txn.GetTxnCoordMeta(ctx)
-		if meta.CommandCount > 0 {
+		if meta.CommandCount > 0 || len(ex.state.savepoints) > 0 {
 			err := fmt.Errorf("SAVEPOINT %s needs to be the first statement in a "+
 				"transaction", RestartSavepointName)
 			return makeErrEvent(err)
@@ -267,6 +290,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return eventRetryIntentSet{}, nil /* payload */, nil
 
 	case *tree.RollbackToSavepoint:
+		if !ex.isRestartSavepointName(s.Savepoint) {
+			if err := ex.execRollbackToSavepointInOpenState(ctx, os, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		if err := ex.validateSavepointName(s.Savepoint); err != nil {
 			return makeErrEvent(err)
 		}
@@ -353,14 +382,36 @@ func (ex *connExecutor) execStmtInOpenState(
 	// For regular statements (the ones that get to this point), we don't return
 	// any event unless an an error happens.
 
+	if stmt.AST.StatementType() == tree.DDL {
+		// Keep track of the schema changes, which can't be rolled back to a
+		// savepoint.
+		ex.extraTxnState.numDDL++
+	}
+
+	if ex.state.isolation == enginepb.READ_COMMITTED && !os.ImplicitTxn.Get() {
+		// Each statement of a READ COMMITTED txn reads from a new snapshot. The
+		// savepoint allows the statement to be retried at a newer snapshot if it
+		// encounters a retriable error.
+		if err := ex.state.mu.txn.AdvanceReadTimestamp(ctx); err != nil {
+			return makeErrEvent(err)
+		}
+		sp, err := ex.state.mu.txn.CreateSavepoint(ctx)
+		if err != nil {
+			return makeErrEvent(err)
+		}
+		stmtSavepoint = sp
+	}
+
 	var p *planner
 	stmtTS := ex.server.cfg.Clock.PhysicalTime()
 	// Only run statements asynchronously through the parallelize queue if the
 	// statements are parallelized and we're in a transaction. Parallelized
 	// statements outside of a transaction are run synchronously with mocked
 	// results, which has the same effect as running asynchronously but
-	// immediately blocking.
-	runInParallel := parallelize && !os.ImplicitTxn.Get()
+	// immediately blocking. The statements of READ COMMITTED txns are not run
+	// asynchronously, as each of them reads from its own snapshot.
+	runInParallel := parallelize && !os.ImplicitTxn.Get() &&
+		ex.state.isolation == enginepb.SERIALIZABLE
 	if runInParallel {
 		// Create a new planner since we're executing in parallel.
 		p = ex.newPlanner(ctx, ex.state.mu.txn, stmtTS)
@@ -371,24 +422,37 @@ func (ex *connExecutor) execStmtInOpenState(
 	}
 
 	if os.ImplicitTxn.Get() {
-		ts, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
+		asOf, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if ts != nil {
-			p.semaCtx.AsOfTimestamp = ts
-			ex.state.mu.txn.SetFixedTimestamp(ctx, *ts)
+		if asOf != nil {
+			ts := asOf.Timestamp
+			if asOf.BoundedStaleness {
+				// The statement is first planned at the current time so that the
+				// spans it reads can be determined. The timestamp at which it is
+				// executed is negotiated in dispatchToExecutionEngine.
+				p.semaCtx.AsOfMinTimestamp = ts
+				ts = ex.server.cfg.Clock.Now()
+			}
+			p.semaCtx.AsOfTimestamp = &ts
+			ex.state.mu.txn.SetFixedTimestamp(ctx, ts)
 		}
 	} else {
 		// If we're in an explicit txn, we allow AOST but only if it matches with
 		// the transaction's timestamp. This is useful for running AOST statements
 		// using the InternalExecutor inside an external transaction; one might want
 		// to do that to force p.avoidCachedDescriptors to be set below.
-		ts, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
+		asOf, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now())
 		if err != nil {
 			return makeErrEvent(err)
 		}
-		if ts != nil {
+		if asOf != nil {
+			if asOf.BoundedStaleness {
+				return makeErrEvent(errors.Errorf(
+					"bounded staleness reads cannot be used inside a transaction"))
+			}
+			ts := &asOf.Timestamp
 			if *ts != ex.state.mu.txn.OrigTimestamp() {
 				return makeErrEvent(errors.Errorf("inconsistent \"as of system time\" timestamp. Expected: %s. "+
 					"Generally \"as of system time\" cannot be used inside a transaction.",
@@ -467,6 +531,54 @@ func (ex *connExecutor) execStmtInOpenState(
 	return nil, nil, nil
 }
 
+// maxReadCommittedStmtRetries is the number of times a statement of a READ
+// COMMITTED txn is retried on its own before the txn is retried in its entirety.
+const maxReadCommittedStmtRetries = 10
+
+// maybeRetryReadCommittedStmt checks whether the statement of a READ COMMITTED
+// txn that encountered err can be retried on its own. If so, the KV txn is
+// rolled back to sp, the savepoint established at the beginning of the
+// statement, and an event causing the statement to be executed again at a new
+// read timestamp is returned.
+//
+// The statement can't be retried if the txn was aborted, if the statement
+// performed schema changes, if some of its results were already delivered to
+// the client, or if it was already retried too many times.
+func (ex *connExecutor) maybeRetryReadCommittedStmt(
+	ctx context.Context, err error, stmt tree.Statement, sp client.SavepointToken,
+) (fsm.Event, fsm.EventPayload, bool) {
+	retryErr, ok := err.(*roachpb.TransactionRetryWithProtoRefreshError)
+	if !ok || retryErr.Transaction.ID != ex.state.mu.txn.ID() {
+		return nil, nil, false
+	}
+	if stmt.StatementType() == tree.DDL {
+		return nil, nil, false
+	}
+	_, pos, bufErr := ex.stmtBuf.curCmd()
+	if bufErr != nil {
+		return nil, nil, false
+	}
+	if pos != ex.extraTxnState.stmtRetryPos {
+		ex.extraTxnState.stmtRetryPos = pos
+		ex.extraTxnState.stmtRetries = 0
+	}
+	if ex.extraTxnState.stmtRetries >= maxReadCommittedStmtRetries {
+		return nil, nil, false
+	}
+	rc, canRewind := ex.getRewindStmtCapability(pos)
+	if !canRewind {
+		return nil, nil, false
+	}
+	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, sp); err != nil {
+		log.VEventf(ctx, 2, "failed to roll back statement for retry: %v", err)
+		rc.cl.Close()
+		return nil, nil, false
+	}
+	ex.extraTxnState.stmtRetries++
+	log.VEventf(ctx, 2, "retrying statement of READ COMMITTED txn after: %v", retryErr)
+	return eventRetriableStmtErr{}, eventRetriableErrPayload{err: err, rewCap: rc}, true
+}
+
 // maybeSynchronizeParallelStmts check if the statement is parallelized or is
 // independent from parallel execution. If neither of these cases are true, the
 // method synchronizes parallel execution by letting it drain before returning.
@@ -612,6 +724,10 @@ func (ex *connExecutor) commitSQLTransaction(
 		return ex.makeErrEvent(err, stmt)
 	}
 
+	if err := ex.extraTxnState.deferredChecks.RunAll(ctx, ex.state.mu.txn); err != nil {
+		return ex.makeErrEvent(err, stmt)
+	}
+
 	if err := ex.state.mu.txn.Commit(ctx); err != nil {
 		return ex.makeErrEvent(err, stmt)
 	}
@@ -802,6 +918,9 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	planner.statsCollector.PhaseTimes()[plannerStartLogicalPlan] = timeutil.Now()
 
 	flags, err := ex.makeExecPlan(ctx, stmt, planner)
+	if err == nil && !planner.semaCtx.AsOfMinTimestamp.IsEmpty() {
+		flags, err = ex.replanBoundedStaleness(ctx, stmt, planner)
+	}
 	defer planner.curPlan.close(ctx)
 
 	defer func() { planner.maybeLogStatement(ctx, "exec", res.RowsAffected(), res.Err()) }()
@@ -831,6 +950,11 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		distributePlan = shouldDistributePlan(
 			ctx, ex.sessionData.DistSQLMode, ex.server.cfg.DistSQLPlanner, planner.curPlan.plan)
 	}
+	if !planner.semaCtx.AsOfMinTimestamp.IsEmpty() {
+		// Bounded staleness reads are served by the replicas local to the
+		// gateway, at the timestamp negotiated with them.
+		distributePlan = false
+	}
 	ex.sessionTracing.TracePlanCheckEnd(ctx, nil, distributePlan)
 
 	if ex.server.cfg.TestingKnobs.BeforeExecute != nil {
@@ -1006,6 +1130,10 @@ func (ex *connExecutor) execWithLocalEngine(
 			res.SetError(err)
 			return nil
 		}
+		if err := planner.curPlan.runPostqueries(params); err != nil {
+			res.SetError(err)
+			return nil
+		}
 		res.IncrementRowsAffected(count)
 		return nil
 	case tree.Rows:
@@ -1029,6 +1157,10 @@ func (ex *connExecutor) execWithLocalEngine(
 		}
 		if queryErr != nil {
 			res.SetError(queryErr)
+			return nil
+		}
+		if err := planner.curPlan.runPostqueries(params); err != nil {
+			res.SetError(err)
 		}
 		return nil
 	default:
@@ -1069,13 +1201,17 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	planCtx.isLocal = !distribute
 	planCtx.planner = planner
 	planCtx.stmtType = recv.stmtType
+	// The postqueries read the rows buffered by the main plan, so the plan must
+	// stay open until they have run; dispatchToExecutionEngine closes it.
+	planCtx.ignoreClose = len(planner.curPlan.postqueryPlans) != 0
+
+	evalCtxFactory := func() *extendedEvalContext {
+		evalCtx := ex.evalCtx(ctx, planner, planner.ExtendedEvalContext().StmtTimestamp)
+		evalCtx.Placeholders = &planner.semaCtx.Placeholders
+		return &evalCtx
+	}
 
 	if len(planner.curPlan.subqueryPlans) != 0 {
-		evalCtxFactory := func() *extendedEvalContext {
-			evalCtx := ex.evalCtx(ctx, planner, planner.ExtendedEvalContext().StmtTimestamp)
-			evalCtx.Placeholders = &planner.semaCtx.Placeholders
-			return &evalCtx
-		}
 		if !ex.server.cfg.DistSQLPlanner.PlanAndRunSubqueries(ctx, planner, evalCtxFactory, planner.curPlan.subqueryPlans, recv, distribute) {
 			return recv.commErr
 		}
@@ -1085,6 +1221,15 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	// the planner whether or not to plan remote table readers.
 	ex.server.cfg.DistSQLPlanner.PlanAndRun(
 		ctx, evalCtx, planCtx, planner.txn, planner.curPlan.plan, recv)
+	if recv.commErr != nil || res.Err() != nil {
+		return recv.commErr
+	}
+
+	if len(planner.curPlan.postqueryPlans) != 0 {
+		ex.server.cfg.DistSQLPlanner.PlanAndRunPostqueries(
+			ctx, planner, evalCtxFactory, planner.curPlan.postqueryPlans, recv, distribute,
+		)
+	}
 	return recv.commErr
 }
 
@@ -1132,10 +1277,14 @@ func (ex *connExecutor) execStmtInNoTxnState(
 		if err != nil {
 			return ex.makeErrEvent(err, s)
 		}
+		iso, err := ex.isolationWithSessionDefault(s.Modes.Isolation)
+		if err != nil {
+			return ex.makeErrEvent(err, s)
+		}
 
 		return eventTxnStart{ImplicitTxn: fsm.False},
 			makeEventTxnStartPayload(
-				pri, ex.readWriteModeWithSessionDefault(s.Modes.ReadWriteMode),
+				pri, ex.readWriteModeWithSessionDefault(s.Modes.ReadWriteMode), iso,
 				ex.server.cfg.Clock.PhysicalTime(),
 				ex.transitionCtx)
 	case *tree.CommitTransaction, *tree.ReleaseSavepoint,
@@ -1150,6 +1299,9 @@ func (ex *connExecutor) execStmtInNoTxnState(
 			makeEventTxnStartPayload(
 				roachpb.NormalUserPriority,
 				mode,
+				// Implicit txns run a single statement; they are always
+				// SERIALIZABLE.
+				enginepb.SERIALIZABLE,
 				ex.server.cfg.Clock.PhysicalTime(),
 				ex.transitionCtx)
 	}
@@ -1160,6 +1312,8 @@ func (ex *connExecutor) execStmtInNoTxnState(
 // - COMMIT / ROLLBACK: aborts the current transaction.
 // - ROLLBACK TO SAVEPOINT / SAVEPOINT: reopens the current transaction,
 //   allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT of a general savepoint: rolls back the current
+//   transaction to the savepoint, in state Aborted.
 func (ex *connExecutor) execStmtInAbortedState(
 	ctx context.Context, stmt Statement, res RestrictedCommandResult,
 ) (fsm.Event, fsm.EventPayload) {
@@ -1193,6 +1347,21 @@ func (ex *connExecutor) execStmtInAbortedState(
 		default:
 			panic("unreachable")
 		}
+		if !ex.isRestartSavepointName(spName) {
+			if !isRollback {
+				// General savepoints can't be established in an aborted txn.
+				ev := eventNonRetriableErr{IsCommit: fsm.False}
+				payload := eventNonRetriableErrPayload{
+					err: sqlbase.NewTransactionAbortedError("" /* customMsg */),
+				}
+				return ev, payload
+			}
+			// Rolling back to a general savepoint makes the transaction usable
+			// again, unless it needs to be restarted.
+			if i := ex.state.findSavepoint(spName); i >= 0 && !inRestartWait {
+				return ex.execRollbackToSavepointInAbortedState(ctx, i)
+			}
+		}
 		// If the user issued a SAVEPOINT in the abort state, validate
 		// as though there were no active savepoint.
 		if !isRollback {
@@ -1242,7 +1411,7 @@ func (ex *connExecutor) execStmtInAbortedState(
 			rwMode = tree.ReadOnly
 		}
 		payload := makeEventTxnStartPayload(
-			ex.state.priority, rwMode, ex.state.sqlTimestamp, ex.transitionCtx)
+			ex.state.priority, rwMode, ex.state.isolation, ex.state.sqlTimestamp, ex.transitionCtx)
 		return ev, payload
 	default:
 		ev := eventNonRetriableErr{IsCommit: fsm.False}
@@ -1466,10 +1635,10 @@ func (ex *connExecutor) incrementStmtCounter(stmt Statement) {
 }
 
 // validateSavepointName validates that it is that the provided ident
-// matches the active savepoint name, begins with RestartSavepointName,
-// or that force_savepoint_restart==true. We accept everything with the
-// desired prefix because at least the C++ libpqxx appends sequence
-// numbers to the savepoint name specified by the user.
+// matches the active savepoint name, or that it refers to the
+// cockroach_restart savepoint (see isRestartSavepointName). The names of the
+// general savepoints are only accepted by their own code paths, so a general
+// savepoint name that makes it here does not exist.
 func (ex *connExecutor) validateSavepointName(savepoint tree.Name) error {
 	if ex.state.activeSavepointName != "" {
 		if savepoint == ex.state.activeSavepointName {
@@ -1478,11 +1647,8 @@ func (ex *connExecutor) validateSavepointName(savepoint tree.Name) error {
 		return pgerror.NewErrorf(pgerror.CodeInvalidSavepointSpecificationError,
 			`SAVEPOINT %q is in use`, tree.ErrString(&ex.state.activeSavepointName))
 	}
-	if !ex.sessionData.ForceSavepointRestart && !strings.HasPrefix(string(savepoint), RestartSavepointName) {
-		return pgerror.UnimplementedWithIssueHintError(10735,
-			"SAVEPOINT not supported except for "+RestartSavepointName,
-			"Retryable transactions with arbitrary SAVEPOINT names can be enabled "+
-				"with SET force_savepoint_restart=true")
+	if !ex.isRestartSavepointName(savepoint) {
+		return newSavepointDoesNotExistError(savepoint)
 	}
 	return nil
 }
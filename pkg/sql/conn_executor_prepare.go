@@ -196,13 +196,20 @@ func (ex *connExecutor) populatePrepared(
 	p.extendedEvalCtx.ActiveMemAcc = &constantMemAcc
 	defer constantMemAcc.Close(ctx)
 
-	protoTS, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now() /* max */)
+	asOf, err := p.isAsOf(stmt.AST, ex.server.cfg.Clock.Now() /* max */)
 	if err != nil {
 		return 0, err
 	}
-	if protoTS != nil {
-		p.semaCtx.AsOfTimestamp = protoTS
-		txn.SetFixedTimestamp(ctx, *protoTS)
+	if asOf != nil {
+		protoTS := asOf.Timestamp
+		if asOf.BoundedStaleness {
+			// The timestamp of a bounded staleness read is only negotiated at
+			// execution time; prepare the statement at the current time.
+			p.semaCtx.AsOfMinTimestamp = protoTS
+			protoTS = ex.server.cfg.Clock.Now()
+		}
+		p.semaCtx.AsOfTimestamp = &protoTS
+		txn.SetFixedTimestamp(ctx, protoTS)
 	}
 
 	// PREPARE has a limited subset of statements it can be run with. Postgres
@@ -342,6 +349,14 @@ func (ex *connExecutor) execBind(
 			if arg == nil {
 				// nil indicates a NULL argument value.
 				qargs[k] = tree.DNull
+			} else if enumTyp, ok := ps.Types[k].(*types.TEnum); ok {
+				// The OIDs of user-defined types are not known to pgwirebase. The
+				// members of ENUM types are sent as their label in both formats.
+				d, err := tree.NewDEnumFromLogicalRep(enumTyp, string(arg))
+				if err != nil {
+					return retErr(err)
+				}
+				qargs[k] = d
 			} else {
 				d, err := pgwirebase.DecodeOidDatum(ptCtx, t, qArgFormatCodes[i], arg)
 				if err != nil {
@@ -0,0 +1,170 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+)
+
+// This file contains the support for general savepoints, i.e. the savepoints
+// other than cockroach_restart.
+//
+// The cockroach_restart savepoint is a marker for the client's intent to retry
+// the transaction: rolling back to it restarts the KV transaction. General
+// savepoints can be nested, and rolling back to one of them undoes the writes
+// performed since the savepoint was established, without restarting the KV
+// transaction (see client.TxnSender.RollbackToSavepoint).
+//
+// When a statement fails in an explicit transaction that has savepoints, the
+// KV transaction is kept open while the SQL transaction is in the Aborted
+// state, so that the client can roll back to one of the savepoints and
+// continue the transaction. The KV transaction is rolled back if the SQL
+// transaction ends instead.
+
+// sqlSavepoint is a savepoint established with the SAVEPOINT statement.
+type sqlSavepoint struct {
+	name  tree.Name
+	token client.SavepointToken
+	// numDDL is the number of DDL statements that had been executed in the
+	// transaction when the savepoint was established.
+	numDDL int
+}
+
+// isRestartSavepointName returns true if the given savepoint name refers to
+// the cockroach_restart savepoint. We accept everything with the
+// RestartSavepointName prefix because at least the C++ libpqxx appends
+// sequence numbers to the savepoint name specified by the user. All the names
+// refer to the restart savepoint when force_savepoint_restart is set.
+func (ex *connExecutor) isRestartSavepointName(name tree.Name) bool {
+	return ex.sessionData.ForceSavepointRestart ||
+		strings.HasPrefix(string(name), RestartSavepointName)
+}
+
+// findSavepoint returns the index in ts.savepoints of the most recent savepoint
+// with the given name, or -1 if there is no such savepoint.
+func (ts *txnState) findSavepoint(name tree.Name) int {
+	for i := len(ts.savepoints) - 1; i >= 0; i-- {
+		if ts.savepoints[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func newSavepointDoesNotExistError(name tree.Name) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidSavepointSpecificationError,
+		"savepoint %q does not exist", tree.ErrString(&name))
+}
+
+// checkSavepointInExplicitTxn returns an error if a savepoint statement is
+// executed outside of an explicit transaction.
+func checkSavepointInExplicitTxn(os stateOpen, stmtName string) error {
+	if os.ImplicitTxn.Get() {
+		return pgerror.NewErrorf(pgerror.CodeNoActiveSQLTransactionError,
+			"%s can only be used in transaction blocks", stmtName)
+	}
+	return nil
+}
+
+// execSavepointInOpenState establishes a general savepoint.
+func (ex *connExecutor) execSavepointInOpenState(
+	ctx context.Context, os stateOpen, s *tree.Savepoint,
+) error {
+	if err := checkSavepointInExplicitTxn(os, "SAVEPOINT"); err != nil {
+		return err
+	}
+	token, err := ex.state.mu.txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	ex.state.savepoints = append(ex.state.savepoints, sqlSavepoint{
+		name:   s.Name,
+		token:  token,
+		numDDL: ex.extraTxnState.numDDL,
+	})
+	return nil
+}
+
+// execReleaseSavepointInOpenState releases a general savepoint, along with all
+// the savepoints established after it. The writes performed since the
+// savepoint are kept.
+func (ex *connExecutor) execReleaseSavepointInOpenState(
+	os stateOpen, s *tree.ReleaseSavepoint,
+) error {
+	if err := checkSavepointInExplicitTxn(os, "RELEASE SAVEPOINT"); err != nil {
+		return err
+	}
+	i := ex.state.findSavepoint(s.Savepoint)
+	if i < 0 {
+		return newSavepointDoesNotExistError(s.Savepoint)
+	}
+	ex.state.savepoints = ex.state.savepoints[:i]
+	return nil
+}
+
+// execRollbackToSavepointInOpenState undoes the writes performed since a
+// general savepoint was established. The savepoints established after it are
+// released; the savepoint itself remains in place.
+func (ex *connExecutor) execRollbackToSavepointInOpenState(
+	ctx context.Context, os stateOpen, s *tree.RollbackToSavepoint,
+) error {
+	if err := checkSavepointInExplicitTxn(os, "ROLLBACK TO SAVEPOINT"); err != nil {
+		return err
+	}
+	i := ex.state.findSavepoint(s.Savepoint)
+	if i < 0 {
+		return newSavepointDoesNotExistError(s.Savepoint)
+	}
+	return ex.rollbackToSavepoint(ctx, i)
+}
+
+// execRollbackToSavepointInAbortedState is like
+// execRollbackToSavepointInOpenState, but it is executed in the Aborted state
+// for a savepoint that exists. On success, the transaction moves back to the
+// Open state.
+func (ex *connExecutor) execRollbackToSavepointInAbortedState(
+	ctx context.Context, i int,
+) (fsm.Event, fsm.EventPayload) {
+	if err := ex.rollbackToSavepoint(ctx, i); err != nil {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload
+	}
+	return eventSavepointRollback{}, nil
+}
+
+// rollbackToSavepoint rolls back the KV txn to the savepoint at index i of
+// ex.state.savepoints, and releases the savepoints established after it.
+func (ex *connExecutor) rollbackToSavepoint(ctx context.Context, i int) error {
+	sp := &ex.state.savepoints[i]
+	if sp.numDDL != ex.extraTxnState.numDDL {
+		// The schema changes performed in the transaction are tracked outside
+		// of the KV txn (e.g. in the table collection and the schema changers),
+		// and we don't know how to undo them.
+		return pgerror.Unimplemented("rollback to savepoint after DDL",
+			"ROLLBACK TO SAVEPOINT not supported after schema changes")
+	}
+	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, sp.token); err != nil {
+		return err
+	}
+	ex.state.savepoints = ex.state.savepoints[:i+1]
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	// We dot-import fsm to use common names such as fsm.True/False. State machine
 	// implementations using that library are weird beasts intimately inter-twined
 	// with that package; therefor this file should stay as small as possible.
@@ -130,17 +131,20 @@ type eventTxnStartPayload struct {
 	// current_timestamp(), transaction_timestamp().
 	txnSQLTimestamp time.Time
 	readOnly        tree.ReadWriteMode
+	isolation       enginepb.IsolationType
 }
 
 func makeEventTxnStartPayload(
 	pri roachpb.UserPriority,
 	readOnly tree.ReadWriteMode,
+	isolation enginepb.IsolationType,
 	txnSQLTimestamp time.Time,
 	tranCtx transitionCtx,
 ) eventTxnStartPayload {
 	return eventTxnStartPayload{
 		pri:             pri,
 		readOnly:        readOnly,
+		isolation:       isolation,
 		txnSQLTimestamp: txnSQLTimestamp,
 		tranCtx:         tranCtx,
 	}
@@ -207,22 +211,36 @@ func (p eventRetriableErrPayload) errorCause() error {
 // eventRetriableErrPayload implements payloadWithError.
 var _ payloadWithError = eventRetriableErrPayload{}
 
+// eventRetriableStmtErr is generated when a statement of a READ COMMITTED
+// transaction encounters a retriable error and can be retried on its own. The
+// KV txn has already been rolled back to the savepoint established at the
+// beginning of the statement. The event's payload is an
+// eventRetriableErrPayload whose rewCap points at the statement.
+type eventRetriableStmtErr struct{}
+
 // eventTxnReleased is generated after a successful RELEASE SAVEPOINT
 // cockroach_restart. It moves the state to CommitWait.
 type eventTxnReleased struct{}
 
+// eventSavepointRollback is generated in the Aborted state after a successful
+// ROLLBACK TO SAVEPOINT of a savepoint other than cockroach_restart. It moves
+// the state back to Open.
+type eventSavepointRollback struct{}
+
 // payloadWithError is a common interface for the payloads that wrap an error.
 type payloadWithError interface {
 	errorCause() error
 }
 
-func (eventRetryIntentSet) Event()  {}
-func (eventTxnStart) Event()        {}
-func (eventTxnFinish) Event()       {}
-func (eventTxnRestart) Event()      {}
-func (eventNonRetriableErr) Event() {}
-func (eventRetriableErr) Event()    {}
-func (eventTxnReleased) Event()     {}
+func (eventRetryIntentSet) Event()    {}
+func (eventTxnStart) Event()          {}
+func (eventTxnFinish) Event()         {}
+func (eventTxnRestart) Event()        {}
+func (eventNonRetriableErr) Event()   {}
+func (eventRetriableErr) Event()      {}
+func (eventRetriableStmtErr) Event()  {}
+func (eventTxnReleased) Event()       {}
+func (eventSavepointRollback) Event() {}
 
 // TxnStateTransitions describe the transitions used by a connExecutor's
 // fsm.Machine. Args.Extended is a txnState, which is muted by the Actions.
@@ -328,12 +346,33 @@ var TxnStateTransitions = Compile(Pattern{
 			Next: stateAborted{RetryIntent: Var("retryIntent")},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
-				ts.mu.txn.CleanupOnError(ts.Ctx, args.Payload.(payloadWithError).errorCause())
-				ts.setAdvanceInfo(skipBatch, noRewind, txnAborted)
+				if len(ts.savepoints) > 0 {
+					// Keep the KV txn open so that the client can roll back to one of
+					// the savepoints. The KV txn is rolled back if the SQL txn ends
+					// instead.
+					ts.setAdvanceInfo(skipBatch, noRewind, noEvent)
+				} else {
+					ts.mu.txn.CleanupOnError(ts.Ctx, args.Payload.(payloadWithError).errorCause())
+					ts.setAdvanceInfo(skipBatch, noRewind, txnAborted)
+				}
 				ts.txnAbortCount.Inc(1)
 				return nil
 			},
 		},
+		// A statement of a READ COMMITTED txn is retried at a new read timestamp.
+		// Unlike an auto-retry, this doesn't restart the transaction.
+		eventRetriableStmtErr{}: {
+			Description: "Retriable err in READ COMMITTED txn; will retry statement",
+			Next:        stateOpen{ImplicitTxn: False, RetryIntent: Var("retryIntent")},
+			Action: func(args Args) error {
+				// The caller will call rewCap.rewindAndUnlock().
+				args.Extended.(*txnState).setAdvanceInfo(
+					rewind,
+					args.Payload.(eventRetriableErrPayload).rewCap,
+					noEvent)
+				return nil
+			},
+		},
 		// SAVEPOINT cockroach_restart: we just change the state (RetryIntent) if it
 		// wasn't set already.
 		eventRetryIntentSet{}: {
@@ -408,6 +447,7 @@ var TxnStateTransitions = Compile(Pattern{
 			Next:        stateNoTxn{},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
+				ts.rollbackKeptTxn()
 				ts.finishSQLTxn()
 				ts.setAdvanceInfo(
 					advanceOne, noRewind, args.Payload.(eventTxnFinishPayload).toEvent())
@@ -423,6 +463,14 @@ var TxnStateTransitions = Compile(Pattern{
 				return nil
 			},
 		},
+		eventSavepointRollback{}: {
+			Description: "ROLLBACK TO SAVEPOINT (not cockroach_restart)",
+			Next:        stateOpen{ImplicitTxn: False, RetryIntent: Var("retryIntent")},
+			Action: func(args Args) error {
+				args.Extended.(*txnState).setAdvanceInfo(advanceOne, noRewind, noEvent)
+				return nil
+			},
+		},
 	},
 	stateAborted{RetryIntent: True}: {
 		// ROLLBACK TO SAVEPOINT. We accept this in the Aborted state for the
@@ -433,6 +481,7 @@ var TxnStateTransitions = Compile(Pattern{
 			Next:        stateOpen{ImplicitTxn: False, RetryIntent: True},
 			Action: func(args Args) error {
 				ts := args.Extended.(*txnState)
+				ts.rollbackKeptTxn()
 				ts.finishSQLTxn()
 
 				payload := args.Payload.(eventTxnStartPayload)
@@ -442,7 +491,7 @@ var TxnStateTransitions = Compile(Pattern{
 				ts.resetForNewSQLTxn(
 					ts.connCtx,
 					explicitTxn,
-					payload.txnSQLTimestamp, payload.pri, payload.readOnly,
+					payload.txnSQLTimestamp, payload.pri, payload.readOnly, payload.isolation,
 					nil, /* txn */
 					args.Payload.(eventTxnStartPayload).tranCtx,
 				)
@@ -542,6 +591,7 @@ func (ts *txnState) noTxnToOpen(
 		payload.txnSQLTimestamp,
 		payload.pri,
 		payload.readOnly,
+		payload.isolation,
 		nil, /* txn */
 		payload.tranCtx,
 	)
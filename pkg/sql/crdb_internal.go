@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -73,6 +74,7 @@ var crdbInternal = virtualSchema{
 		crdbInternalLeasesTable,
 		crdbInternalLocalQueriesTable,
 		crdbInternalLocalSessionsTable,
+		crdbInternalLocalLockWaitsTable,
 		crdbInternalLocalMetricsTable,
 		crdbInternalPartitionsTable,
 		crdbInternalRangesNoLeasesTable,
@@ -301,6 +303,8 @@ CREATE TABLE crdb_internal.schema_changes (
 					mutType = "INDEX"
 					targetID = tree.NewDInt(tree.DInt(int64(d.Index.ID)))
 					targetName = tree.NewDString(d.Index.Name)
+				case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+					mutType = "MATERIALIZED VIEW REFRESH"
 				}
 				if err := addRow(
 					tableID,
@@ -992,6 +996,54 @@ var crdbInternalLocalMetricsTable = virtualSchemaTable{
 	},
 }
 
+// crdbInternalLocalLockWaitsTable exposes the requests waiting in the lock
+// tables of the ranges whose replicas are on the current node.
+var crdbInternalLocalLockWaitsTable = virtualSchemaTable{
+	schema: `
+CREATE TABLE crdb_internal.node_lock_waits (
+  store_id       INT NOT NULL,        -- the store holding the range's replica
+  range_id       INT NOT NULL,        -- the range containing the lock
+  key            BYTES NOT NULL,      -- the key of the lock
+  key_pretty     STRING NOT NULL,
+  holder_txn_id  STRING NULL,         -- the transaction holding the lock, if any
+  waiter_txn_id  STRING NULL,         -- the transaction of the waiter, if any
+  position       INT NOT NULL,        -- the position of the waiter in the lock's queue
+  wait_start     TIMESTAMP NOT NULL   -- the time at which the waiter entered the queue
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.node_lock_waits"); err != nil {
+			return err
+		}
+
+		lw := p.ExecCfg().LockWaits
+		if lw == nil {
+			return nil
+		}
+		return lw.VisitLockWaits(func(
+			storeID roachpb.StoreID, rangeID roachpb.RangeID, w locktable.WaitInfo,
+		) error {
+			holderTxnID := tree.DNull
+			if w.Holder != nil {
+				holderTxnID = tree.NewDString(w.Holder.ID.String())
+			}
+			waiterTxnID := tree.DNull
+			if w.Waiter != nil {
+				waiterTxnID = tree.NewDString(w.Waiter.ID.String())
+			}
+			return addRow(
+				tree.NewDInt(tree.DInt(storeID)),
+				tree.NewDInt(tree.DInt(rangeID)),
+				tree.NewDBytes(tree.DBytes(w.Key)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, w.Key)),
+				holderTxnID,
+				waiterTxnID,
+				tree.NewDInt(tree.DInt(w.Position)),
+				tree.MakeDTimestamp(w.WaitStart, time.Microsecond),
+			)
+		})
+	},
+}
+
 // crdbInternalBuiltinFunctionsTable exposes the built-in function
 // metadata.
 var crdbInternalBuiltinFunctionsTable = virtualSchemaTable{
@@ -1061,16 +1113,24 @@ CREATE TABLE crdb_internal.create_statements (
 				var stmt, createNofk string
 				alterStmts := tree.NewDArray(types.String)
 				validateStmts := tree.NewDArray(types.String)
+				// The names of the objects of the user-defined schemas are
+				// qualified by the name of their schema.
+				var tn tree.NodeFormatter = (*tree.Name)(&table.Name)
+				if isUserSchemaName(scName) {
+					qualifiedName := tree.MakeUnqualifiedTableName(tree.Name(table.Name))
+					qualifiedName.SchemaName = tree.Name(scName)
+					qualifiedName.ExplicitSchema = true
+					tn = &qualifiedName
+				}
 				var err error
 				if table.IsView() {
 					descType = typeView
-					stmt, err = ShowCreateView(ctx, (*tree.Name)(&table.Name), table)
+					stmt, err = ShowCreateView(ctx, tn, table)
 				} else if table.IsSequence() {
 					descType = typeSequence
-					stmt, err = ShowCreateSequence(ctx, (*tree.Name)(&table.Name), table)
+					stmt, err = ShowCreateSequence(ctx, tn, table)
 				} else {
 					descType = typeTable
-					tn := (*tree.Name)(&table.Name)
 					createNofk, err = ShowCreateTable(ctx, tn, contextName, table, lCtx, true /* ignoreFKs */)
 					if err != nil {
 						return err
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -72,6 +73,10 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 		if n.Unique {
 			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be unique")
 		}
+
+		if n.Predicate != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidSQLStatementNameError, "inverted indexes can't be partial")
+		}
 		indexDesc.Type = sqlbase.IndexDescriptor_INVERTED
 	}
 
@@ -81,6 +86,39 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 	return &indexDesc, nil
 }
 
+// makeIndexPredicate checks that the predicate of a partial index is a
+// boolean expression over the columns of the table that doesn't contain impure
+// functions, and returns its serialized form.
+func makeIndexPredicate(
+	ctx context.Context,
+	desc *sqlbase.MutableTableDescriptor,
+	predicate tree.Expr,
+	tableName *tree.TableName,
+	semaCtx *tree.SemaContext,
+	evalCtx *tree.EvalContext,
+) (string, error) {
+	// Replace column references with typed dummies to allow typechecking.
+	replacedExpr, _, err := replaceVars(desc, predicate)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, types.Bool, "index predicate", semaCtx, evalCtx, false, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sources := sqlbase.MultiSourceInfo{sqlbase.NewSourceInfoForSingleTable(
+		*tableName, sqlbase.ResultColumnsFromColDescs(desc.Columns),
+	)}
+	expr, err := dequalifyColumnRefs(ctx, sources, predicate)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
 func (n *createIndexNode) startExec(params runParams) error {
 	_, dropped, err := n.tableDesc.FindIndexByName(string(n.n.Name))
 	if err == nil {
@@ -97,6 +135,15 @@ func (n *createIndexNode) startExec(params runParams) error {
 		return err
 	}
 
+	if n.n.Predicate != nil {
+		predicate, err := makeIndexPredicate(
+			params.ctx, n.tableDesc, n.n.Predicate, &n.n.Table, &params.p.semaCtx, params.EvalContext())
+		if err != nil {
+			return err
+		}
+		indexDesc.Predicate = predicate
+	}
+
 	if n.n.PartitionBy != nil {
 		partitioning, err := CreatePartitioning(params.ctx, params.p.ExecCfg().Settings,
 			params.EvalContext(), n.tableDesc, indexDesc, n.n.PartitionBy)
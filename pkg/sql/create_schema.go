@@ -0,0 +1,294 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// This file contains the support for user-defined schemas.
+//
+// The descriptors of user-defined schemas are stored in system.descriptor
+// alongside the descriptors of tables and databases. Like the types, the
+// schemas of a database live in a namespace of their own: the namespace is
+// recorded in system.namespace under the ID of the database with the name
+// schemaNamespaceName, and the names of the schemas are in turn recorded
+// under the ID of the namespace. This way the names of the schemas don't
+// conflict with the names of the tables of the public schema.
+//
+// The tables of a user-defined schema keep the ID of their database as their
+// ParentID, and record the ID of their schema in UnexposedParentSchemaID.
+// Their names are recorded in system.namespace under the ID of the schema.
+
+// schemaNamespaceName is the name under which the namespace of the schemas of
+// a database is recorded in system.namespace.
+const schemaNamespaceName = "crdb_internal_schemas"
+
+// isVirtualSchemaName returns true if the given name is the name of a virtual
+// schema. The names are listed explicitly rather than read from
+// virtualSchemas, since the virtual tables use name resolution themselves and
+// would otherwise form an initialization cycle.
+func isVirtualSchemaName(scName string) bool {
+	switch scName {
+	case informationSchemaName, pgCatalogName, crdbInternalName:
+		return true
+	}
+	return false
+}
+
+// isUserSchemaName returns true if the given name can only designate a
+// user-defined schema, that is, if it is neither the name of the public
+// schema, nor that of a temporary or virtual schema.
+func isUserSchemaName(scName string) bool {
+	return scName != tree.PublicSchema && scName != sessiondata.PgTempSchemaName &&
+		!isTemporarySchemaName(scName) && !isVirtualSchemaName(scName)
+}
+
+// getSchemaNamespaceID returns the ID of the schema namespace of the given
+// database, or 0 if the database has no user-defined schemas.
+func getSchemaNamespaceID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	kv, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(dbID, schemaNamespaceName))
+	if err != nil || !kv.Exists() {
+		return 0, err
+	}
+	return sqlbase.ID(kv.ValueInt()), nil
+}
+
+// GetOrCreateSchemaNamespaceID returns the ID of the schema namespace of the
+// given database, creating the namespace if it does not exist.
+func GetOrCreateSchemaNamespaceID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, kvTrace bool,
+) (sqlbase.ID, error) {
+	id, err := getSchemaNamespaceID(ctx, txn, dbID)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	id, err = GenerateUniqueDescID(ctx, txn.DB())
+	if err != nil {
+		return 0, err
+	}
+	key := sqlbase.MakeNameMetadataKey(dbID, schemaNamespaceName)
+	if kvTrace {
+		log.VEventf(ctx, 2, "CPut %s -> %d", key, id)
+	}
+	if err := txn.CPut(ctx, key, id, nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetUserSchemaID returns the ID of the user-defined schema with the given
+// name in the given database, or 0 if the schema does not exist.
+func GetUserSchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (sqlbase.ID, error) {
+	nsID, err := getSchemaNamespaceID(ctx, txn, dbID)
+	if err != nil || nsID == 0 {
+		return 0, err
+	}
+	kv, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(nsID, scName))
+	if err != nil || !kv.Exists() {
+		return 0, err
+	}
+	return sqlbase.ID(kv.ValueInt()), nil
+}
+
+// getUserSchemaDesc returns the descriptor of the user-defined schema with the
+// given name in the given database, or nil if the schema does not exist.
+func getUserSchemaDesc(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (*sqlbase.SchemaDescriptor, error) {
+	id, err := GetUserSchemaID(ctx, txn, dbID, scName)
+	if err != nil || id == 0 {
+		return nil, err
+	}
+	desc := &sqlbase.SchemaDescriptor{}
+	if err := getDescriptorByID(ctx, txn, id, desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// listUserSchemas returns the names and IDs of the user-defined schemas in the
+// given database.
+func listUserSchemas(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (map[string]sqlbase.ID, error) {
+	nsID, err := getSchemaNamespaceID(ctx, txn, dbID)
+	if err != nil || nsID == 0 {
+		return nil, err
+	}
+	prefix := sqlbase.MakeNameMetadataKey(nsID, "")
+	kvs, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]sqlbase.ID, len(kvs))
+	for _, kv := range kvs {
+		_, name, err := encoding.DecodeUnsafeStringAscending(bytes.TrimPrefix(kv.Key, prefix), nil)
+		if err != nil {
+			return nil, err
+		}
+		schemas[name] = sqlbase.ID(kv.ValueInt())
+	}
+	return schemas, nil
+}
+
+// getSchemaID returns the ID under which the names of the objects of the
+// given schema of the given database are recorded in system.namespace, or 0
+// if the schema does not exist. The names of the objects of the public schema
+// are recorded under the ID of the database.
+func getSchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (sqlbase.ID, error) {
+	switch {
+	case scName == tree.PublicSchema:
+		return dbID, nil
+	case isTemporarySchemaName(scName):
+		return getTemporarySchemaID(ctx, txn, dbID, scName)
+	default:
+		return GetUserSchemaID(ctx, txn, dbID, scName)
+	}
+}
+
+// resolveCreateSchemaID returns the ID under which the name of an object
+// created with the given (resolved) name is recorded in system.namespace. For
+// objects created in a user-defined schema, this is the ID of the schema, and
+// the user must have the CREATE privilege on the schema.
+func (p *planner) resolveCreateSchemaID(
+	ctx context.Context, dbDesc *DatabaseDescriptor, tn *tree.TableName,
+) (sqlbase.ID, error) {
+	if !isUserSchemaName(tn.Schema()) {
+		return dbDesc.ID, nil
+	}
+	scDesc, err := getUserSchemaDesc(ctx, p.txn, dbDesc.ID, tn.Schema())
+	if err != nil {
+		return 0, err
+	}
+	if scDesc == nil {
+		return 0, sqlbase.NewUndefinedSchemaError(tn.Schema())
+	}
+	if err := p.CheckPrivilege(ctx, scDesc, privilege.CREATE); err != nil {
+		return 0, err
+	}
+	return scDesc.ID, nil
+}
+
+type createSchemaNode struct {
+	n      *tree.CreateSchema
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateSchema creates a schema in the current database.
+// Privileges: CREATE on database.
+//   Notes: postgres requires CREATE on database.
+func (p *planner) CreateSchema(ctx context.Context, n *tree.CreateSchema) (planNode, error) {
+	scName := string(n.Schema)
+	if scName == "" {
+		return nil, pgerror.NewError(pgerror.CodeInvalidSchemaNameError, "empty schema name")
+	}
+	if !isUserSchemaName(scName) || strings.HasPrefix(scName, "pg_") {
+		return nil, pgerror.NewErrorf(pgerror.CodeReservedNameError,
+			"unacceptable schema name %q", scName).SetDetailf(
+			`The prefix "pg_" is reserved for system schemas.`)
+	}
+
+	if p.CurrentDatabase() == "" {
+		return nil, errNoDatabase
+	}
+	dbDesc, err := p.ResolveUncachedDatabaseByName(ctx, p.CurrentDatabase(), true /* required */)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &createSchemaNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *createSchemaNode) startExec(params runParams) error {
+	ctx, p := params.ctx, params.p
+	nsID, err := GetOrCreateSchemaNamespaceID(
+		ctx, p.txn, n.dbDesc.ID, p.ExtendedEvalContext().Tracing.KVTracingEnabled(),
+	)
+	if err != nil {
+		return err
+	}
+	scName := string(n.n.Schema)
+	key := sqlbase.MakeNameMetadataKey(nsID, scName)
+	if exists, err := descExists(ctx, p.txn, key); err != nil {
+		return err
+	} else if exists {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return sqlbase.NewSchemaAlreadyExistsError(scName)
+	}
+
+	id, err := GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	desc := &sqlbase.SchemaDescriptor{
+		Name:       scName,
+		ID:         id,
+		ParentID:   n.dbDesc.ID,
+		Privileges: n.dbDesc.GetPrivileges(),
+	}
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.createDescriptorWithID(ctx, key, id, desc, params.EvalContext().Settings); err != nil {
+		return err
+	}
+
+	// Log Create Schema event. This is an auditable log event and is recorded
+	// in the same transaction as the schema descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogCreateSchema,
+		int32(desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			SchemaName string
+			Statement  string
+			User       string
+		}{scName, n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*createSchemaNode) Next(runParams) (bool, error) { return false, nil }
+func (*createSchemaNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createSchemaNode) Close(context.Context)        {}
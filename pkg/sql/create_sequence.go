@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -32,6 +33,11 @@ type createSequenceNode struct {
 }
 
 func (p *planner) CreateSequence(ctx context.Context, n *tree.CreateSequence) (planNode, error) {
+	if n.Temporary {
+		return nil, pgerror.UnimplementedWithIssueDetailError(5807, "create temp sequence",
+			"temporary sequences are not supported")
+	}
+
 	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Name)
 	if err != nil {
 		return nil, err
@@ -48,7 +54,11 @@ func (p *planner) CreateSequence(ctx context.Context, n *tree.CreateSequence) (p
 }
 
 func (n *createSequenceNode) startExec(params runParams) error {
-	tKey := getSequenceKey(n.dbDesc, n.n.Name.Table())
+	parentSchemaID, err := params.p.resolveCreateSchemaID(params.ctx, n.dbDesc, &n.n.Name)
+	if err != nil {
+		return err
+	}
+	tKey := getSequenceKey(parentSchemaID, n.n.Name.Table())
 	if exists, err := descExists(params.ctx, params.p.txn, tKey.Key()); err == nil && exists {
 		if n.n.IfNotExists {
 			// If the sequence exists but the user specified IF NOT EXISTS, return without doing anything.
@@ -62,8 +72,11 @@ func (n *createSequenceNode) startExec(params runParams) error {
 	return doCreateSequence(params, n.n.String(), n.dbDesc, &n.n.Name, n.n.Options)
 }
 
-func getSequenceKey(dbDesc *DatabaseDescriptor, seqName string) tableKey {
-	return tableKey{parentID: dbDesc.ID, name: seqName}
+// getSequenceKey returns the namespace key of a sequence. The parentSchemaID
+// is the ID of the database, or that of the user-defined schema of the
+// sequence.
+func getSequenceKey(parentSchemaID sqlbase.ID, seqName string) tableKey {
+	return tableKey{parentID: parentSchemaID, name: seqName}
 }
 
 // doCreateSequence performs the creation of a sequence in KV. The
@@ -75,6 +88,11 @@ func doCreateSequence(
 	name *ObjectName,
 	opts tree.SequenceOptions,
 ) error {
+	parentSchemaID, err := params.p.resolveCreateSchemaID(params.ctx, dbDesc, name)
+	if err != nil {
+		return err
+	}
+
 	id, err := GenerateUniqueDescID(params.ctx, params.p.ExecCfg().DB)
 	if err != nil {
 		return err
@@ -89,10 +107,14 @@ func doCreateSequence(
 		return err
 	}
 
+	if parentSchemaID != dbDesc.ID {
+		desc.UnexposedParentSchemaID = parentSchemaID
+	}
+
 	// makeSequenceTableDesc already validates the table. No call to
 	// desc.ValidateTable() needed here.
 
-	key := getSequenceKey(dbDesc, name.Table()).Key()
+	key := getSequenceKey(parentSchemaID, name.Table()).Key()
 	if err = params.p.createDescriptorWithID(params.ctx, key, id, &desc, params.EvalContext().Settings); err != nil {
 		return err
 	}
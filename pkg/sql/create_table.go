@@ -49,7 +49,7 @@ type createTableNode struct {
 // Privileges: CREATE on database.
 //   Notes: postgres/mysql require CREATE on database.
 func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNode, error) {
-	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Table)
+	dbDesc, err := p.resolveCreateTableTarget(ctx, n)
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +104,21 @@ type createTableRun struct {
 }
 
 func (n *createTableNode) startExec(params runParams) error {
-	tKey := tableKey{parentID: n.dbDesc.ID, name: n.n.Table.Table()}
+	// The names of temporary tables are recorded under the temporary schema
+	// of the session, and those of the tables of user-defined schemas under
+	// their schema.
+	var parentSchemaID sqlbase.ID
+	var err error
+	if n.n.Temporary {
+		parentSchemaID, err = params.p.getOrCreateTemporarySchemaID(params.ctx, n.dbDesc.ID)
+	} else {
+		parentSchemaID, err = params.p.resolveCreateSchemaID(params.ctx, n.dbDesc, &n.n.Table)
+	}
+	if err != nil {
+		return err
+	}
+
+	tKey := tableKey{parentID: parentSchemaID, name: n.n.Table.Table()}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		if n.n.IfNotExists {
@@ -149,6 +163,9 @@ func (n *createTableNode) startExec(params runParams) error {
 	if err != nil {
 		return err
 	}
+	if parentSchemaID != n.dbDesc.ID {
+		desc.UnexposedParentSchemaID = parentSchemaID
+	}
 
 	if desc.Adding() {
 		// if this table and all its references are created in the same
@@ -223,6 +240,7 @@ func (n *createTableNode) startExec(params runParams) error {
 			nil,
 			desc.Columns,
 			row.SkipFKs,
+			params.EvalContext(),
 			&params.p.alloc)
 		if err != nil {
 			return err
@@ -342,10 +360,14 @@ const (
 
 // Referenced cols must be unique, thus referenced indexes must match exactly.
 // Referencing cols have no uniqueness requirement and thus may match a strict
-// prefix of an index.
+// prefix of an index. Partial indexes only contain a subset of the rows of
+// the table, so they never match.
 func matchesIndex(
 	cols []sqlbase.ColumnDescriptor, idx sqlbase.IndexDescriptor, exact indexMatch,
 ) bool {
+	if idx.IsPartial() {
+		return false
+	}
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
@@ -444,6 +466,14 @@ func ResolveFK(
 	if err != nil {
 		return err
 	}
+	if target.Temporary != tbl.Temporary {
+		persistence := "permanent"
+		if tbl.Temporary {
+			persistence = "temporary"
+		}
+		return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"constraints on %s tables may reference only %s tables", persistence, persistence)
+	}
 	if target.ID == tbl.ID {
 		// When adding a self-ref FK to an _existing_ table, we want to make sure
 		// we edit the same copy.
@@ -513,9 +543,11 @@ func ResolveFK(
 		targetIdxID = target.PrimaryIndex.ID
 	} else {
 		found := false
-		// Find the index corresponding to the referenced column.
+		// Find the index corresponding to the referenced column. As in
+		// Postgres, a deferrable unique constraint can't be referenced, since
+		// the referenced values aren't unique until it is checked.
 		for i, idx := range target.Indexes {
-			if idx.Unique && matchesIndex(targetCols, idx, matchExact) {
+			if idx.HasUniqueKeys() && matchesIndex(targetCols, idx, matchExact) {
 				targetIdxIndex = i
 				targetIdxID = idx.ID
 				found = true
@@ -558,13 +590,15 @@ func ResolveFK(
 	}
 
 	ref := sqlbase.ForeignKeyReference{
-		Table:           target.ID,
-		Index:           targetIdxID,
-		Name:            constraintName,
-		SharedPrefixLen: int32(len(srcCols)),
-		OnDelete:        sqlbase.ForeignKeyReferenceActionValue[d.Actions.Delete],
-		OnUpdate:        sqlbase.ForeignKeyReferenceActionValue[d.Actions.Update],
-		Match:           sqlbase.CompositeKeyMatchMethodValue[d.Match],
+		Table:             target.ID,
+		Index:             targetIdxID,
+		Name:              constraintName,
+		SharedPrefixLen:   int32(len(srcCols)),
+		OnDelete:          sqlbase.ForeignKeyReferenceActionValue[d.Actions.Delete],
+		OnUpdate:          sqlbase.ForeignKeyReferenceActionValue[d.Actions.Update],
+		Match:             sqlbase.CompositeKeyMatchMethodValue[d.Match],
+		Deferrable:        d.Deferrable != tree.NotDeferrable,
+		InitiallyDeferred: d.Deferrable == tree.DeferrableInitiallyDeferred,
 	}
 
 	if ts != NewTable {
@@ -726,6 +760,10 @@ func addInterleave(
 	if err != nil {
 		return err
 	}
+	if parentTable.Temporary != desc.Temporary {
+		return pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+			"cannot interleave temporary and permanent tables")
+	}
 	parentIndex := parentTable.PrimaryIndex
 
 	// typeOfIndex is used to give more informative error messages.
@@ -902,6 +940,7 @@ func makeTableDescIfAs(
 	evalCtx *tree.EvalContext,
 ) (desc sqlbase.MutableTableDescriptor, err error) {
 	desc = InitTableDescriptor(id, parentID, p.Table.Table(), creationTime, privileges)
+	desc.Temporary = p.Temporary
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -993,6 +1032,7 @@ func MakeTableDesc(
 	evalCtx *tree.EvalContext,
 ) (sqlbase.MutableTableDescriptor, error) {
 	desc := InitTableDescriptor(id, parentID, n.Table.Table(), creationTime, privileges)
+	desc.Temporary = n.Temporary
 
 	for _, def := range n.Defs {
 		if d, ok := def.(*tree.ColumnTableDef); ok {
@@ -1077,6 +1117,13 @@ func MakeTableDesc(
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				predicate, err := makeIndexPredicate(ctx, &desc, d.Predicate, &n.Table, semaCtx, evalCtx)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = predicate
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
@@ -1092,13 +1139,22 @@ func MakeTableDesc(
 			}
 		case *tree.UniqueConstraintTableDef:
 			idx := sqlbase.IndexDescriptor{
-				Name:             string(d.Name),
-				Unique:           true,
-				StoreColumnNames: d.Storing.ToStrings(),
+				Name:              string(d.Name),
+				Unique:            true,
+				StoreColumnNames:  d.Storing.ToStrings(),
+				Deferrable:        d.Deferrable != tree.NotDeferrable,
+				InitiallyDeferred: d.Deferrable == tree.DeferrableInitiallyDeferred,
 			}
 			if err := idx.FillColumns(d.Columns); err != nil {
 				return desc, err
 			}
+			if d.Predicate != nil {
+				predicate, err := makeIndexPredicate(ctx, &desc, d.Predicate, &n.Table, semaCtx, evalCtx)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = predicate
+			}
 			if d.PartitionBy != nil {
 				partitioning, err := CreatePartitioning(ctx, st, evalCtx, &desc, &idx, d.PartitionBy)
 				if err != nil {
@@ -0,0 +1,319 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// This file contains the support for user-defined types.
+//
+// The descriptors of user-defined types are stored in system.descriptor
+// alongside the descriptors of tables and databases. Types live in a
+// namespace of their own, so that they don't conflict with tables: the
+// namespace of the types of a database is recorded in system.namespace under
+// the ID of the database with the name typeNamespaceName, and an ID allocated
+// like a descriptor ID. The names of the types are in turn recorded in
+// system.namespace under the ID of the type namespace.
+//
+// The columns of a user-defined type carry a copy of the metadata of the type
+// in their column type, so that their values can be encoded and decoded
+// without looking up the type descriptor. Altering a type thus also updates
+// the descriptors of the tables which use it, which the type descriptor
+// records as back-references.
+//
+// A member is added to a type in two steps, like a column is added to a
+// table. It is first added as read-only to the type and its tables: its
+// values can be decoded, but not input. Once every node uses a version of the
+// tables in which the member can be decoded, the typeSchemaChanger makes the
+// member public, after which its values can be written.
+
+// typeNamespaceName is the name under which the namespace of the types of a
+// database is recorded in system.namespace.
+const typeNamespaceName = "crdb_internal_types"
+
+// getTypeNamespaceID returns the ID of the type namespace of the given
+// database, or 0 if the database has no types.
+func getTypeNamespaceID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	kv, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(dbID, typeNamespaceName))
+	if err != nil || !kv.Exists() {
+		return 0, err
+	}
+	return sqlbase.ID(kv.ValueInt()), nil
+}
+
+// getOrCreateTypeNamespaceID returns the ID of the type namespace of the given
+// database, creating the namespace if it does not exist.
+func (p *planner) getOrCreateTypeNamespaceID(
+	ctx context.Context, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	id, err := getTypeNamespaceID(ctx, p.txn, dbID)
+	if err != nil || id != 0 {
+		return id, err
+	}
+	id, err = GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+	if err != nil {
+		return 0, err
+	}
+	key := sqlbase.MakeNameMetadataKey(dbID, typeNamespaceName)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "CPut %s -> %d", key, id)
+	}
+	if err := p.txn.CPut(ctx, key, id, nil); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// getTypeDesc returns the descriptor of the type with the given name in the
+// given database, or nil if the type does not exist.
+func getTypeDesc(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, name string,
+) (*sqlbase.TypeDescriptor, error) {
+	nsID, err := getTypeNamespaceID(ctx, txn, dbID)
+	if err != nil || nsID == 0 {
+		return nil, err
+	}
+	kv, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(nsID, name))
+	if err != nil || !kv.Exists() {
+		return nil, err
+	}
+	desc := &sqlbase.TypeDescriptor{}
+	if err := getDescriptorByID(ctx, txn, sqlbase.ID(kv.ValueInt()), desc); err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// getTypeDescByID returns the descriptor of the type with the given ID, or nil
+// if the type does not exist anymore.
+func getTypeDescByID(
+	ctx context.Context, txn *client.Txn, id sqlbase.ID,
+) (*sqlbase.TypeDescriptor, error) {
+	desc := &sqlbase.Descriptor{}
+	if err := txn.GetProto(ctx, sqlbase.MakeDescMetadataKey(id), desc); err != nil {
+		return nil, err
+	}
+	typ := desc.GetType()
+	if typ == nil {
+		return nil, nil
+	}
+	if err := typ.Validate(); err != nil {
+		return nil, err
+	}
+	return typ, nil
+}
+
+// addTypeBackReferences records the given table in the back-references of the
+// user-defined types of its columns.
+func (p *planner) addTypeBackReferences(
+	ctx context.Context, table *sqlbase.TableDescriptor,
+) error {
+	if table.Dropped() {
+		return nil
+	}
+	for _, typeID := range enumTypeIDs(table) {
+		typeDesc, err := getTypeDescByID(ctx, p.txn, typeID)
+		if err != nil {
+			return err
+		}
+		// The type may have been dropped with its database, in which case
+		// the table keeps its copy of the type.
+		if typeDesc == nil || !typeDesc.AddReferencingDescriptorID(table.ID) {
+			continue
+		}
+		if err := p.writeTypeDesc(ctx, typeDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTypeDesc writes the given type descriptor in the current transaction.
+func (p *planner) writeTypeDesc(ctx context.Context, desc *sqlbase.TypeDescriptor) error {
+	descKey := sqlbase.MakeDescMetadataKey(desc.ID)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, desc)
+	}
+	return p.txn.Put(ctx, descKey, sqlbase.WrapDescriptor(desc))
+}
+
+// listTypes returns the names and IDs of the types in the given database.
+func listTypes(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (map[string]sqlbase.ID, error) {
+	nsID, err := getTypeNamespaceID(ctx, txn, dbID)
+	if err != nil || nsID == 0 {
+		return nil, err
+	}
+	prefix := sqlbase.MakeNameMetadataKey(nsID, "")
+	kvs, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	typs := make(map[string]sqlbase.ID, len(kvs))
+	for _, kv := range kvs {
+		_, name, err := encoding.DecodeUnsafeStringAscending(bytes.TrimPrefix(kv.Key, prefix), nil)
+		if err != nil {
+			return nil, err
+		}
+		typs[name] = sqlbase.ID(kv.ValueInt())
+	}
+	return typs, nil
+}
+
+// ResolveType implements the tree.TypeReferenceResolver interface. Types are
+// looked up in the current database.
+func (p *planner) ResolveType(name string) (types.T, error) {
+	ctx := p.EvalContext().Context
+	dbDesc, err := p.LogicalSchemaAccessor().GetDatabaseDesc(ctx, p.txn, p.CurrentDatabase(),
+		p.CommonLookupFlags(false /* required */))
+	if err != nil {
+		return nil, err
+	}
+	var desc *sqlbase.TypeDescriptor
+	if dbDesc != nil {
+		if desc, err = getTypeDesc(ctx, p.txn, dbDesc.ID, name); err != nil {
+			return nil, err
+		}
+	}
+	if desc == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError, "type %q does not exist", name)
+	}
+	return desc.DatumType(), nil
+}
+
+// resolveTypeDesc returns the descriptor of the type with the given name,
+// along with the descriptor of its database.
+func (p *planner) resolveTypeDesc(
+	ctx context.Context, name *tree.TableName,
+) (*sqlbase.TypeDescriptor, *DatabaseDescriptor, error) {
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	desc, err := getTypeDesc(ctx, p.txn, dbDesc.ID, name.Table())
+	if err != nil {
+		return nil, nil, err
+	}
+	if desc == nil {
+		return nil, nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"type %q does not exist", name.Table())
+	}
+	return desc, dbDesc, nil
+}
+
+type createTypeNode struct {
+	n      *tree.CreateType
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateType creates a user-defined type.
+// Privileges: CREATE on database.
+func (p *planner) CreateType(ctx context.Context, n *tree.CreateType) (planNode, error) {
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The types are looked up in their database only.
+	if isUserSchemaName(n.Name.Schema()) {
+		return nil, pgerror.Unimplemented("types in user-defined schemas",
+			"types cannot be created in user-defined schemas")
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	// The names of the builtin types are not resolved as user-defined types,
+	// so a user-defined type with the same name could never be used.
+	if typ, err := parser.ParseType(n.Name.Table()); err == nil {
+		if _, ok := typ.(*coltypes.TEnum); !ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+				"type %q already exists", n.Name.Table())
+		}
+	}
+
+	return &createTypeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *createTypeNode) startExec(params runParams) error {
+	ctx, p := params.ctx, params.p
+	nsID, err := p.getOrCreateTypeNamespaceID(ctx, n.dbDesc.ID)
+	if err != nil {
+		return err
+	}
+	key := sqlbase.MakeNameMetadataKey(nsID, n.n.Name.Table())
+	if exists, err := descExists(ctx, p.txn, key); err != nil {
+		return err
+	} else if exists {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"type %q already exists", n.n.Name.Table())
+	}
+
+	id, err := GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+	if err != nil {
+		return err
+	}
+
+	// Inherit permissions from the database descriptor.
+	desc, err := sqlbase.NewEnumTypeDescriptor(
+		id, n.dbDesc.ID, n.n.Name.Table(), n.n.EnumLabels, n.dbDesc.GetPrivileges())
+	if err != nil {
+		return err
+	}
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+
+	if err := p.createDescriptorWithID(ctx, key, id, desc, params.EvalContext().Settings); err != nil {
+		return err
+	}
+
+	// Log Create Type event. This is an auditable log event and is recorded
+	// in the same transaction as the type descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		ctx,
+		p.txn,
+		EventLogCreateType,
+		int32(desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			TypeName  string
+			Statement string
+			User      string
+		}{n.n.Name.FQString(), n.n.String(), params.SessionData().User},
+	)
+}
+
+func (*createTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTypeNode) Close(context.Context)        {}
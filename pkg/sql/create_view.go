@@ -19,8 +19,10 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -36,6 +38,9 @@ type createViewNode struct {
 	// depends on. This is collected during the construction of
 	// the view query's logical plan.
 	planDeps planDependencies
+	// sourcePlan is the plan of the view query, which populates a
+	// materialized view. It is nil for other views.
+	sourcePlan planNode
 }
 
 // CreateView creates a view.
@@ -44,6 +49,11 @@ type createViewNode struct {
 //						selected columns.
 //          mysql requires CREATE VIEW plus SELECT on all the selected columns.
 func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode, error) {
+	if n.Temporary {
+		return nil, pgerror.UnimplementedWithIssueDetailError(5807, "create temp view",
+			"temporary views are not supported")
+	}
+
 	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Name)
 	if err != nil {
 		return nil, err
@@ -65,6 +75,16 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 		return nil, err
 	}
 
+	// Temporary tables are dropped when their session ends, so they cannot
+	// be referenced by permanent views.
+	for _, dep := range planDeps {
+		if dep.desc.Temporary {
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot create view %q because it depends on temporary table %q",
+				tree.ErrString(&n.Name), dep.desc.Name)
+		}
+	}
+
 	// Ensure that all the table names pretty-print as fully qualified,
 	// so we store that in the view descriptor.
 	//
@@ -101,17 +121,32 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 
 	log.VEventf(ctx, 2, "collected view dependencies:\n%s", planDeps.String())
 
+	// The results of the query of a materialized view are stored when the
+	// view is created, like those of CREATE TABLE AS.
+	var sourcePlan planNode
+	if n.Materialized {
+		sourcePlan, err = p.Select(ctx, n.AsSource, []types.T{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &createViewNode{
 		n:             n,
 		dbDesc:        dbDesc,
 		sourceColumns: sourceColumns,
 		planDeps:      planDeps,
+		sourcePlan:    sourcePlan,
 	}, nil
 }
 
 func (n *createViewNode) startExec(params runParams) error {
 	viewName := n.n.Name.Table()
-	tKey := tableKey{parentID: n.dbDesc.ID, name: viewName}
+	parentSchemaID, err := params.p.resolveCreateSchemaID(params.ctx, n.dbDesc, &n.n.Name)
+	if err != nil {
+		return err
+	}
+	tKey := tableKey{parentID: parentSchemaID, name: viewName}
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		// TODO(a-robinson): Support CREATE OR REPLACE commands.
@@ -141,6 +176,10 @@ func (n *createViewNode) startExec(params runParams) error {
 		return err
 	}
 
+	if parentSchemaID != n.dbDesc.ID {
+		desc.UnexposedParentSchemaID = parentSchemaID
+	}
+
 	// Collect all the tables/views this view depends on.
 	for backrefID := range n.planDeps {
 		desc.DependsOn = append(desc.DependsOn, backrefID)
@@ -176,6 +215,34 @@ func (n *createViewNode) startExec(params runParams) error {
 		return err
 	}
 
+	if n.sourcePlan != nil {
+		rowCount, err := writeMaterializedViewRows(
+			params.ctx,
+			params.p.txn,
+			params.EvalContext(),
+			sqlbase.NewImmutableTableDescriptor(*desc.TableDesc()),
+			func() (tree.Datums, error) {
+				if err := params.p.cancelChecker.Check(); err != nil {
+					return nil, err
+				}
+				if next, err := n.sourcePlan.Next(params); !next {
+					return nil, err
+				}
+				return n.sourcePlan.Values(), nil
+			},
+			nil, /* nextRowID */
+			params.extendedEvalCtx.Tracing.KVTracingEnabled(),
+		)
+		if err != nil {
+			return err
+		}
+		params.ExecCfg().StatsRefresher.NotifyMutation(
+			&params.EvalContext().Settings.SV,
+			desc.ID,
+			rowCount,
+		)
+	}
+
 	// Log Create View event. This is an auditable log event and is
 	// recorded in the same transaction as the table descriptor update.
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
@@ -194,7 +261,12 @@ func (n *createViewNode) startExec(params runParams) error {
 
 func (*createViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*createViewNode) Values() tree.Datums          { return tree.Datums{} }
-func (n *createViewNode) Close(ctx context.Context)  {}
+func (n *createViewNode) Close(ctx context.Context) {
+	if n.sourcePlan != nil {
+		n.sourcePlan.Close(ctx)
+		n.sourcePlan = nil
+	}
+}
 
 // makeViewTableDesc returns the table descriptor for a new view.
 //
@@ -215,6 +287,9 @@ func (n *createViewNode) makeViewTableDesc(
 	desc := InitTableDescriptor(id, parentID, viewName,
 		params.p.txn.CommitTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	// A materialized view is stored like a table, so AllocateIDs gives it a
+	// primary index over a hidden rowid column.
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -254,6 +329,7 @@ func MakeViewTableDesc(
 	viewName := n.Name.Table()
 	desc := InitTableDescriptor(id, parentID, viewName, creationTime, privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.AsSource, tree.FmtParsable)
+	desc.IsMaterializedView = n.Materialized
 
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
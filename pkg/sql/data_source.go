@@ -171,6 +171,11 @@ func (p *planner) getDataSource(
 			indexFlags = t.IndexFlags
 		}
 
+		if t.Lateral {
+			return planDataSource{}, pgerror.UnimplementedWithIssueErrorf(24560,
+				"LATERAL is only supported by the cost-based optimizer")
+		}
+
 		src, err := p.getDataSource(ctx, t.Expr, indexFlags, scanVisibility)
 		if err != nil {
 			return src, err
@@ -307,7 +312,8 @@ func (p *planner) getPlanForDesc(
 	indexFlags *tree.IndexFlags,
 	colCfg scanColumnsConfig,
 ) (planDataSource, error) {
-	if desc.IsView() {
+	// The contents of a materialized view are scanned like those of a table.
+	if desc.IsView() && !desc.MaterializedView() {
 		if colCfg.wantedColumns != nil {
 			return planDataSource{},
 				errors.Errorf("cannot specify an explicit column list when accessing a view by reference")
@@ -317,7 +323,7 @@ func (p *planner) getPlanForDesc(
 	if desc.IsSequence() {
 		return p.getSequenceSource(ctx, *tn, desc)
 	}
-	if !desc.IsTable() {
+	if !desc.IsTable() && !desc.MaterializedView() {
 		return planDataSource{}, errors.Errorf(
 			"unexpected table descriptor of type %s for %q", desc.TypeName(), tree.ErrString(tn))
 	}
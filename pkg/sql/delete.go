@@ -135,6 +135,7 @@ func (p *planner) Delete(
 	if err != nil {
 		return nil, err
 	}
+	rd.SetDeferredChecks(p.deferredChecks())
 
 	tracing.AnnotateTrace()
 
@@ -495,6 +496,19 @@ func canDeleteFast(ctx context.Context, source planNode, r *deleteRun) (*scanNod
 		return nil, false
 	}
 
+	// The spans of the scan only cover all the column families of the rows if
+	// it was planned as the source of a delete. This is the case for the scans
+	// created by the cost-based optimizer's DeleteRange fast path.
+	if !scan.isDeleteSource {
+		return nil, false
+	}
+
+	// A limited scan only returns some of the rows in its spans, whereas the
+	// fast path deletes all of them.
+	if scan.hardLimit != 0 {
+		return nil, false
+	}
+
 	// A scan ought to be simple enough, except when it's not: a scan
 	// may have a remaining filter. We can't be fast over that.
 	if scan.filter != nil {
@@ -130,6 +130,13 @@ func (p *planner) createDescriptorWithID(
 		if err := p.Tables().addUncommittedTable(*mutDesc); err != nil {
 			return err
 		}
+		if err := p.addTypeBackReferences(ctx, mutDesc.TableDesc()); err != nil {
+			return err
+		}
+	} else {
+		// The other descriptors are not tracked by the table collection, but
+		// the scans of all the descriptors must see them.
+		p.Tables().releaseAllDescriptors()
 	}
 
 	if err := p.txn.Run(ctx, b); err != nil {
@@ -206,6 +213,26 @@ func getDescriptorByID(
 			return err
 		}
 		*t = *database
+	case *sqlbase.TypeDescriptor:
+		typ := desc.GetType()
+		if typ == nil {
+			return errors.Errorf("%q is not a type", desc.String())
+		}
+
+		if err := typ.Validate(); err != nil {
+			return err
+		}
+		*t = *typ
+	case *sqlbase.SchemaDescriptor:
+		schema := desc.GetSchema()
+		if schema == nil {
+			return errors.Errorf("%q is not a schema", desc.String())
+		}
+
+		if err := schema.Validate(); err != nil {
+			return err
+		}
+		*t = *schema
 	}
 	return nil
 }
@@ -230,6 +257,10 @@ func GetAllDescriptors(ctx context.Context, txn *client.Txn) ([]sqlbase.Descript
 			descs[i] = desc.GetTable()
 		case *sqlbase.Descriptor_Database:
 			descs[i] = desc.GetDatabase()
+		case *sqlbase.Descriptor_Type:
+			descs[i] = desc.GetType()
+		case *sqlbase.Descriptor_Schema:
+			descs[i] = desc.GetSchema()
 		default:
 			return nil, errors.Errorf("Descriptor.Union has unexpected type %T", t)
 		}
@@ -202,9 +202,12 @@ func (v *distSQLExprCheckVisitor) VisitPre(expr tree.Expr) (recurse bool, newExp
 	case *tree.DOid:
 		v.err = newQueryNotSupportedError("OID expressions are not supported by distsql")
 		return false, expr
+	case *tree.DEnum:
+		v.err = newQueryNotSupportedError("ENUM constants are not supported by distsql")
+		return false, expr
 	case *tree.CastExpr:
 		switch t.Type.(type) {
-		case *coltypes.TOid:
+		case *coltypes.TOid, *coltypes.TEnum:
 			v.err = newQueryNotSupportedErrorf("cast to %s is not supported by distsql", t.Type)
 			return false, expr
 		}
@@ -360,6 +363,12 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *scanNode:
+		if n.lockingStrength != tree.ForNone {
+			// Locking the rows requires writing intents, which is not possible
+			// from the leaf transactions used by remote flows. The scan can still
+			// be run by a local flow, which uses the root transaction.
+			return cannotDistribute, nil
+		}
 		rec := canDistribute
 		if n.softLimit != 0 {
 			// We don't yet recommend distributing plans where soft limits propagate
@@ -458,7 +467,7 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		// This is a potential hot path.
 		return cannotDistribute, mutationsNotSupportedError
 
-	case *setVarNode, *setClusterSettingNode:
+	case *setVarNode, *setClusterSettingNode, *setConstraintsNode:
 		// SET statements are never distributed.
 		return cannotDistribute, setNotSupportedError
 
@@ -474,6 +483,12 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 	case *windowNode:
 		return dsp.checkSupportForNode(n.plan)
 
+	case *recursiveCTENode:
+		// The recursive CTE is wrapped in a local processor on the gateway,
+		// which also runs the plans of its iterations. Its initial query can be
+		// distributed.
+		return dsp.checkSupportForNode(n.initial)
+
 	default:
 		return cannotDistribute, newQueryNotSupportedErrorf("unsupported node %T", node)
 	}
@@ -840,14 +855,21 @@ func initTableReaderSpec(
 ) (*distsqlpb.TableReaderSpec, distsqlpb.PostProcessSpec, error) {
 	s := distsqlplan.NewTableReaderSpec()
 	*s = distsqlpb.TableReaderSpec{
-		Table:      *n.desc.TableDesc(),
-		Reverse:    n.reverse,
-		IsCheck:    n.run.isCheck,
-		Visibility: n.colCfg.visibility.toDistSQLScanVisibility(),
+		Table:         *n.desc.TableDesc(),
+		Reverse:       n.reverse,
+		IsCheck:       n.run.isCheck,
+		Visibility:    n.colCfg.visibility.toDistSQLScanVisibility(),
+		LockForUpdate: n.lockingStrength != tree.ForNone,
 
 		// Retain the capacity of the spans slice.
 		Spans: s.Spans[:0],
 	}
+	switch n.lockingWaitPolicy {
+	case tree.LockWaitSkip:
+		s.LockingWaitPolicy = distsqlpb.ScanLockingWaitPolicy_SKIP_LOCKED
+	case tree.LockWaitError:
+		s.LockingWaitPolicy = distsqlpb.ScanLockingWaitPolicy_NOWAIT
+	}
 	indexIdx, err := getIndexIdx(n)
 	if err != nil {
 		return nil, distsqlpb.PostProcessSpec{}, err
@@ -1273,7 +1295,11 @@ func (dsp *DistSQLPlanner) addAggregators(
 		}
 		aggregations[i].Func = distsqlpb.AggregatorSpec_Func(funcIdx)
 		aggregations[i].Distinct = fholder.isDistinct()
-		if fholder.argRenderIdx != noRenderIdx {
+		if n.groupingSets != nil && fholder.argRenderIdx == n.groupingSetRenderIdx() {
+			// The aggregator makes the ordinal of the grouping set available as an
+			// extra column following the input columns.
+			aggregations[i].ColIdx = []uint32{uint32(len(p.ResultTypes))}
+		} else if fholder.argRenderIdx != noRenderIdx {
 			aggregations[i].ColIdx = []uint32{uint32(p.PlanToStreamColMap[fholder.argRenderIdx])}
 		}
 		if fholder.hasFilter() {
@@ -1301,11 +1327,28 @@ func (dsp *DistSQLPlanner) addAggregators(
 	}
 
 	inputTypes := p.ResultTypes
+	if n.groupingSets != nil {
+		inputTypes = append(
+			inputTypes[:len(inputTypes):len(inputTypes)],
+			sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT},
+		)
+	}
 
 	groupCols := make([]uint32, len(n.groupCols))
 	for i, idx := range n.groupCols {
 		groupCols[i] = uint32(p.PlanToStreamColMap[idx])
 	}
+	var groupingSets []distsqlpb.Columns
+	if n.groupingSets != nil {
+		groupingSets = make([]distsqlpb.Columns, len(n.groupingSets))
+		for i, set := range n.groupingSets {
+			set.ForEach(func(idx int) {
+				groupingSets[i].Columns = append(
+					groupingSets[i].Columns, uint32(p.PlanToStreamColMap[idx]),
+				)
+			})
+		}
+	}
 	orderedGroupCols := make([]uint32, len(n.orderedGroupCols))
 	var orderedGroupColSet util.FastIntSet
 	for i, idx := range n.orderedGroupCols {
@@ -1322,6 +1365,8 @@ func (dsp *DistSQLPlanner) addAggregators(
 	//  - we have a mix of aggregations that use distinct and aggregations that
 	//    don't use distinct. TODO(arjun): This would require doing the same as
 	//    the todo as above.
+	//  - there are no grouping sets, which would need to be computed by the
+	//    final stage on top of the groups of the local stage.
 	multiStage := false
 	allDistinct := true
 	anyDistinct := false
@@ -1335,7 +1380,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 		}
 	}
 
-	if prevStageNode == 0 {
+	if prevStageNode == 0 && n.groupingSets == nil {
 		// Check that all aggregation functions support a local stage.
 		multiStage = true
 		for _, e := range aggregations {
@@ -1412,6 +1457,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 			Aggregations:     aggregations,
 			GroupCols:        groupCols,
 			OrderedGroupCols: orderedGroupCols,
+			GroupingSets:     groupingSets,
 		}
 	} else {
 		// Some aggregations might need multiple aggregation as part of
@@ -1460,7 +1506,7 @@ func (dsp *DistSQLPlanner) addAggregators(
 		// finalIdx is the index of the final aggregation with respect
 		// to all final aggregations.
 		finalIdx := 0
-		for _, e := range aggregations {
+		for aggIdx, e := range aggregations {
 			info := distsqlplan.DistAggregationTable[e.Func]
 
 			// relToAbsLocalIdx maps each local stage for the given
@@ -1530,9 +1576,13 @@ func (dsp *DistSQLPlanner) addAggregators(
 				for i, relIdx := range finalInfo.LocalIdxs {
 					argIdxs[i] = relToAbsLocalIdx[relIdx]
 				}
+				// Any constant arguments of the aggregation (like the
+				// fraction of APPROX_PERCENTILE) are passed to the final
+				// stage.
 				finalAgg := distsqlpb.AggregatorSpec_Aggregation{
-					Func:   finalInfo.Fn,
-					ColIdx: argIdxs,
+					Func:      finalInfo.Fn,
+					ColIdx:    argIdxs,
+					Arguments: e.Arguments,
 				}
 
 				isNewAgg := true
@@ -1555,13 +1605,18 @@ func (dsp *DistSQLPlanner) addAggregators(
 					finalAggs = append(finalAggs, finalAgg)
 
 					if needRender {
-						argTypes := make([]sqlbase.ColumnType, len(finalInfo.LocalIdxs))
+						argTypes := make(
+							[]sqlbase.ColumnType,
+							len(finalInfo.LocalIdxs),
+							len(finalInfo.LocalIdxs)+len(e.Arguments),
+						)
 						for i := range finalInfo.LocalIdxs {
 							// Map the corresponding local
 							// aggregation output types for
 							// the current aggregation e.
 							argTypes[i] = intermediateTypes[argIdxs[i]]
 						}
+						argTypes = append(argTypes, aggregationsColumnTypes[aggIdx]...)
 						_, outputType, err := distsqlrun.GetAggregateInfo(
 							finalInfo.Fn, argTypes...,
 						)
@@ -1731,8 +1786,10 @@ func (dsp *DistSQLPlanner) addAggregators(
 		p.PlanToStreamColMap = identityMap(p.PlanToStreamColMap, len(aggregations))
 	}
 
-	if len(finalAggsSpec.GroupCols) == 0 || len(p.ResultRouters) == 1 {
-		// No GROUP BY, or we have a single stream. Use a single final aggregator.
+	if len(finalAggsSpec.GroupCols) == 0 || len(finalAggsSpec.GroupingSets) > 0 ||
+		len(p.ResultRouters) == 1 {
+		// No GROUP BY, grouping sets (which can't be distributed by the group
+		// columns), or we have a single stream. Use a single final aggregator.
 		// If the previous stage was all on a single node, put the final
 		// aggregator there. Otherwise, bring the results back on this node.
 		node := dsp.nodeDesc.NodeID
@@ -1895,8 +1952,13 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	// the corresponding projection.
 	// The internal schema of the join reader is:
 	//    <input columns>... <table columns>...
+	// Semi and anti joins only output the input columns.
 	numLeftCols := len(plan.ResultTypes)
-	numOutCols := numLeftCols + len(n.table.cols)
+	numOutCols := numLeftCols
+	outputTableCols := n.joinType != sqlbase.LeftSemiJoin && n.joinType != sqlbase.LeftAntiJoin
+	if outputTableCols {
+		numOutCols += len(n.table.cols)
+	}
 	post := distsqlpb.PostProcessSpec{Projection: true}
 
 	post.OutputColumns = make([]uint32, numOutCols)
@@ -1906,10 +1968,14 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 		types[i] = plan.ResultTypes[i]
 		post.OutputColumns[i] = uint32(i)
 	}
+	tableColOrdinals := make([]int, len(n.table.cols))
 	for i := range n.table.cols {
-		types[numLeftCols+i] = n.table.cols[i].Type
 		ord := tableOrdinal(n.table.desc, n.table.cols[i].ID, n.table.colCfg.visibility)
-		post.OutputColumns[numLeftCols+i] = uint32(numLeftCols + ord)
+		tableColOrdinals[i] = numLeftCols + ord
+		if outputTableCols {
+			types[numLeftCols+i] = n.table.cols[i].Type
+			post.OutputColumns[numLeftCols+i] = uint32(tableColOrdinals[i])
+		}
 	}
 
 	// Map the columns of the lookupJoinNode to the result streams of the
@@ -1917,18 +1983,20 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	planToStreamColMap := makePlanToStreamColMap(len(n.columns))
 	copy(planToStreamColMap, plan.PlanToStreamColMap)
 	numInputNodeCols := len(planColumns(n.input))
-	for i := range n.table.cols {
-		planToStreamColMap[numInputNodeCols+i] = numLeftCols + i
+	if outputTableCols {
+		for i := range n.table.cols {
+			planToStreamColMap[numInputNodeCols+i] = numLeftCols + i
+		}
 	}
 
 	// Set the ON condition.
 	if n.onCond != nil {
 		// Note that the ON condition refers to the *internal* columns of the
 		// processor (before the OutputColumns projection).
-		indexVarMap := makePlanToStreamColMap(len(n.columns))
+		indexVarMap := makePlanToStreamColMap(numInputNodeCols + len(n.table.cols))
 		copy(indexVarMap, plan.PlanToStreamColMap)
 		for i := range n.table.cols {
-			indexVarMap[numInputNodeCols+i] = int(post.OutputColumns[numLeftCols+i])
+			indexVarMap[numInputNodeCols+i] = tableColOrdinals[i]
 		}
 		var err error
 		joinReaderSpec.OnExpr, err = distsqlplan.MakeExpression(
@@ -766,6 +766,84 @@ func (dsp *DistSQLPlanner) planAndRunSubquery(
 	return nil
 }
 
+// PlanAndRunPostqueries runs the postqueries of a plan, in order, after the
+// main plan has been run to completion. It returns false if an error was
+// encountered and sets that error in the provided receiver.
+func (dsp *DistSQLPlanner) PlanAndRunPostqueries(
+	ctx context.Context,
+	planner *planner,
+	evalCtxFactory func() *extendedEvalContext,
+	postqueryPlans []planNode,
+	recv *DistSQLReceiver,
+	maybeDistribute bool,
+) bool {
+	for _, postqueryPlan := range postqueryPlans {
+		if err := dsp.planAndRunPostquery(
+			ctx,
+			postqueryPlan,
+			planner,
+			evalCtxFactory,
+			recv,
+			maybeDistribute,
+		); err != nil {
+			recv.SetError(err)
+			return false
+		}
+	}
+
+	return true
+}
+
+func (dsp *DistSQLPlanner) planAndRunPostquery(
+	ctx context.Context,
+	postqueryPlan planNode,
+	planner *planner,
+	evalCtxFactory func() *extendedEvalContext,
+	recv *DistSQLReceiver,
+	maybeDistribute bool,
+) error {
+	evalCtx := evalCtxFactory()
+
+	var postqueryPlanCtx *PlanningCtx
+	var distributePostquery bool
+	if maybeDistribute {
+		distributePostquery = shouldDistributePlan(
+			ctx, planner.SessionData().DistSQLMode, dsp, postqueryPlan)
+	}
+	if distributePostquery {
+		postqueryPlanCtx = dsp.NewPlanningCtx(ctx, evalCtx, planner.txn)
+	} else {
+		postqueryPlanCtx = dsp.newLocalPlanningCtx(ctx, evalCtx)
+	}
+
+	postqueryPlanCtx.isLocal = !distributePostquery
+	postqueryPlanCtx.planner = planner
+	postqueryPlanCtx.stmtType = tree.Rows
+	// Don't close the top-level plan from postqueries - someone else will handle
+	// that.
+	postqueryPlanCtx.ignoreClose = true
+
+	postqueryPhysPlan, err := dsp.createPlanForNode(postqueryPlanCtx, postqueryPlan)
+	if err != nil {
+		return err
+	}
+	dsp.FinalizePlan(postqueryPlanCtx, &postqueryPhysPlan)
+
+	// Postqueries don't produce any rows; they only return an error if a
+	// constraint was violated.
+	postqueryRecv := recv.clone()
+	postqueryResultWriter := &errOnlyResultWriter{}
+	postqueryRecv.resultWriter = postqueryResultWriter
+	dsp.Run(
+		postqueryPlanCtx, planner.txn, &postqueryPhysPlan, postqueryRecv, evalCtx,
+		nil, /* finishedSetupFn */
+	)
+	if postqueryRecv.commErr != nil {
+		return postqueryRecv.commErr
+	}
+	return postqueryResultWriter.Err()
+}
+
 // PlanAndRun generates a physical plan from a planNode tree and executes it. It
 // assumes that the tree is supported (see CheckSupport).
 //
@@ -272,4 +272,17 @@ var DistAggregationTable = map[distsqlpb.AggregatorSpec_Func]DistAggregationInfo
 			},
 		},
 	},
+
+	// The local stage summarizes its input in a t-digest and the final stage
+	// merges the digests. The final stage is passed the constant fraction
+	// argument of the original aggregation.
+	distsqlpb.AggregatorSpec_APPROX_PERCENTILE: {
+		LocalStage: []distsqlpb.AggregatorSpec_Func{distsqlpb.AggregatorSpec_APPROX_PERCENTILE_DIGEST},
+		FinalStage: []FinalStageInfo{
+			{
+				Fn:        distsqlpb.AggregatorSpec_FINAL_APPROX_PERCENTILE,
+				LocalIdxs: passThroughLocalIdxs,
+			},
+		},
+	},
 }
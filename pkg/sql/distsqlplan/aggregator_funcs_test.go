@@ -414,6 +414,12 @@ func TestDistAggregationTable(t *testing.T) {
 			// COUNT_ROWS takes no arguments; skip it in this test.
 			continue
 		}
+		if fn == distsqlpb.AggregatorSpec_APPROX_PERCENTILE {
+			// APPROX_PERCENTILE takes a constant argument and its result depends on
+			// how the values are split between the local stages; it is tested in
+			// the util/tdigest package.
+			continue
+		}
 		// We're going to test each aggregation function on every column that can be
 		// used as input for it.
 		foundCol := false
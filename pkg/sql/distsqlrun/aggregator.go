@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -131,6 +132,22 @@ type aggregatorBase struct {
 	orderedGroupCols columns
 	aggregations     []distsqlpb.AggregatorSpec_Aggregation
 
+	// groupingSets are the grouping sets of a GROUP BY with ROLLUP, CUBE or
+	// GROUPING SETS, as sets of input columns (see AggregatorSpec.GroupingSets).
+	// If there are grouping sets, each input row is accumulated once for every
+	// set, extended with the ordinal of the set; inputTypes includes the type
+	// of that extra column. Only the hashAggregator supports grouping sets.
+	groupingSets []util.FastIntSet
+	// groupingSetSkippedAggs contains, for each grouping set, the ANY_NOT_NULL
+	// aggregations of group columns that are not part of the set. These
+	// aggregations are not fed any values, so they produce NULL.
+	groupingSetSkippedAggs []util.FastIntSet
+	// groupingSetIDs contains the ordinal of each grouping set.
+	groupingSetIDs sqlbase.EncDatumRow
+	// groupingSetRow is a scratch row that holds an input row extended with the
+	// ordinal of a grouping set.
+	groupingSetRow sqlbase.EncDatumRow
+
 	lastOrdGroupCols sqlbase.EncDatumRow
 	arena            stringarena.Arena
 	row              sqlbase.EncDatumRow
@@ -184,6 +201,39 @@ func (ag *aggregatorBase) init(
 	// grouped-by values for each bucket.  ag.funcs is updated to contain all
 	// the functions which need to be fed values.
 	ag.inputTypes = input.OutputTypes()
+	if len(spec.GroupingSets) > 0 {
+		// The ordinal of the grouping set is available to the aggregations as an
+		// extra column that follows the input columns.
+		intType := sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT}
+		numInputCols := len(ag.inputTypes)
+		ag.inputTypes = append(ag.inputTypes[:numInputCols:numInputCols], intType)
+		ag.groupingSetRow = make(sqlbase.EncDatumRow, numInputCols+1)
+		ag.groupingSets = make([]util.FastIntSet, len(spec.GroupingSets))
+		ag.groupingSetSkippedAggs = make([]util.FastIntSet, len(spec.GroupingSets))
+		ag.groupingSetIDs = make(sqlbase.EncDatumRow, len(spec.GroupingSets))
+		var groupColSet util.FastIntSet
+		for _, c := range spec.GroupCols {
+			groupColSet.Add(int(c))
+		}
+		for i := range spec.GroupingSets {
+			for _, c := range spec.GroupingSets[i].Columns {
+				if !groupColSet.Contains(int(c)) {
+					return errors.Errorf("grouping set column %d is not a group column", c)
+				}
+				ag.groupingSets[i].Add(int(c))
+			}
+			for j, aggInfo := range spec.Aggregations {
+				if aggInfo.Func == distsqlpb.AggregatorSpec_ANY_NOT_NULL &&
+					len(aggInfo.ColIdx) == 1 && groupColSet.Contains(int(aggInfo.ColIdx[0])) &&
+					!ag.groupingSets[i].Contains(int(aggInfo.ColIdx[0])) {
+					ag.groupingSetSkippedAggs[i].Add(j)
+				}
+			}
+			ag.groupingSetIDs[i] = sqlbase.DatumToEncDatum(
+				intType, tree.NewDInt(tree.DInt(i)),
+			)
+		}
+	}
 	for i, aggInfo := range spec.Aggregations {
 		if aggInfo.FilterColIdx != nil {
 			col := *aggInfo.FilterColIdx
@@ -333,13 +383,14 @@ func newAggregator(
 	output RowReceiver,
 ) (Processor, error) {
 	if len(spec.GroupCols) == 0 &&
+		len(spec.GroupingSets) == 0 &&
 		len(spec.Aggregations) == 1 &&
 		spec.Aggregations[0].FilterColIdx == nil &&
 		spec.Aggregations[0].Func == distsqlpb.AggregatorSpec_COUNT_ROWS &&
 		!spec.Aggregations[0].Distinct {
 		return newCountAggregator(flowCtx, processorID, input, post, output)
 	}
-	if len(spec.OrderedGroupCols) == len(spec.GroupCols) {
+	if len(spec.OrderedGroupCols) == len(spec.GroupCols) && len(spec.GroupingSets) == 0 {
 		return newOrderedAggregator(flowCtx, processorID, spec, input, post, output)
 	}
 
@@ -501,8 +552,14 @@ func (ag *hashAggregator) accumulateRows() (
 	}
 
 	// Queries like `SELECT MAX(n) FROM t` expect a row of NULLs if nothing was
-	// aggregated.
-	if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
+	// aggregated. The same goes for the empty grouping sets of queries like
+	// `SELECT MAX(n) FROM t GROUP BY ROLLUP (m)`.
+	if len(ag.buckets) < 1 && len(ag.groupingSets) > 0 {
+		if err := ag.addEmptyGroupingSetBuckets(); err != nil {
+			ag.MoveToDraining(err)
+			return aggStateUnknown, nil, nil
+		}
+	} else if len(ag.buckets) < 1 && len(ag.groupCols) == 0 {
 		bucket, err := ag.createAggregateFuncs()
 		if err != nil {
 			ag.MoveToDraining(err)
@@ -745,11 +802,16 @@ func (ag *orderedAggregator) ConsumerClosed() {
 	ag.close()
 }
 
+// accumulateRowIntoBucket feeds the given row into the aggregate functions of a
+// bucket, except for the skipped aggregations.
 func (ag *aggregatorBase) accumulateRowIntoBucket(
-	row sqlbase.EncDatumRow, groupKey []byte, bucket aggregateFuncs,
+	row sqlbase.EncDatumRow, groupKey []byte, bucket aggregateFuncs, skippedAggs util.FastIntSet,
 ) error {
 	// Feed the func holders for this bucket the non-grouping datums.
 	for i, a := range ag.aggregations {
+		if skippedAggs.Contains(i) {
+			continue
+		}
 		if a.FilterColIdx != nil {
 			col := *a.FilterColIdx
 			if err := row[col].EnsureDecoded(&ag.inputTypes[col], &ag.datumAlloc); err != nil {
@@ -804,6 +866,10 @@ func (ag *hashAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 		return err
 	}
 
+	if len(ag.groupingSets) > 0 {
+		return ag.accumulateRowIntoGroupingSets(row)
+	}
+
 	// The encoding computed here determines which bucket the non-grouping
 	// datums are accumulated to.
 	encoded, err := ag.encode(ag.scratch, row)
@@ -812,20 +878,87 @@ func (ag *hashAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 	}
 	ag.scratch = encoded[:0]
 
+	bucket, err := ag.getBucket(encoded)
+	if err != nil {
+		return err
+	}
+	return ag.accumulateRowIntoBucket(row, encoded, bucket, util.FastIntSet{} /* skippedAggs */)
+}
+
+// accumulateRowIntoGroupingSets accumulates a single row once for each of the
+// grouping sets.
+func (ag *hashAggregator) accumulateRowIntoGroupingSets(row sqlbase.EncDatumRow) error {
+	extendedRow := ag.groupingSetRow
+	copy(extendedRow, row)
+	for i := range ag.groupingSets {
+		extendedRow[len(row)] = ag.groupingSetIDs[i]
+		encoded, err := ag.encodeGroupingSet(ag.scratch, extendedRow, i)
+		if err != nil {
+			return err
+		}
+		ag.scratch = encoded[:0]
+
+		bucket, err := ag.getBucket(encoded)
+		if err != nil {
+			return err
+		}
+		err = ag.accumulateRowIntoBucket(extendedRow, encoded, bucket, ag.groupingSetSkippedAggs[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBucket returns the bucket for the given group key, creating it if
+// necessary.
+func (ag *hashAggregator) getBucket(encoded []byte) (aggregateFuncs, error) {
 	bucket, ok := ag.buckets[string(encoded)]
 	if !ok {
 		s, err := ag.arena.AllocBytes(ag.Ctx, encoded)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		bucket, err = ag.createAggregateFuncs()
 		if err != nil {
-			return err
+			return nil, err
 		}
 		ag.buckets[s] = bucket
 	}
+	return bucket, nil
+}
+
+// addEmptyGroupingSetBuckets adds a bucket for each empty grouping set. It is
+// used when there were no input rows; like a scalar aggregation, an empty
+// grouping set produces a row even in that case.
+func (ag *hashAggregator) addEmptyGroupingSetBuckets() error {
+	ordinalIdx := uint32(len(ag.inputTypes) - 1)
+	for i := range ag.groupingSets {
+		if !ag.groupingSets[i].Empty() {
+			continue
+		}
+		encoded, err := ag.encodeGroupingSet(ag.scratch, ag.groupingSetIDs[i:i+1], i)
+		if err != nil {
+			return err
+		}
+		ag.scratch = encoded[:0]
 
-	return ag.accumulateRowIntoBucket(row, encoded, bucket)
+		bucket, err := ag.getBucket(encoded)
+		if err != nil {
+			return err
+		}
+		// The aggregations of the ordinal of the grouping set produce the
+		// ordinal; all the others behave like they do for a scalar aggregation.
+		for j, a := range ag.aggregations {
+			if a.Func == distsqlpb.AggregatorSpec_ANY_NOT_NULL &&
+				len(a.ColIdx) == 1 && a.ColIdx[0] == ordinalIdx {
+				if err := bucket[j].Add(ag.Ctx, ag.groupingSetIDs[i].Datum); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // accumulateRow accumulates a single row, returning an error if accumulation
@@ -843,7 +976,8 @@ func (ag *orderedAggregator) accumulateRow(row sqlbase.EncDatumRow) error {
 		}
 	}
 
-	return ag.accumulateRowIntoBucket(row, nil /* groupKey */, ag.bucket)
+	return ag.accumulateRowIntoBucket(
+		row, nil /* groupKey */, ag.bucket, util.FastIntSet{} /* skippedAggs */)
 }
 
 type aggregateFuncHolder struct {
@@ -916,6 +1050,32 @@ func (ag *aggregatorBase) encode(
 	return appendTo, nil
 }
 
+// encodeGroupingSet returns the group key of a row for the given grouping set:
+// the encoding of the ordinal of the set followed by the encoding of the group
+// columns that are part of the set. The row must be extended with the ordinal
+// of the set.
+func (ag *aggregatorBase) encodeGroupingSet(
+	appendTo []byte, row sqlbase.EncDatumRow, groupingSet int,
+) (encoding []byte, err error) {
+	ordinalIdx := len(ag.inputTypes) - 1
+	appendTo, err = row[len(row)-1].Encode(
+		&ag.inputTypes[ordinalIdx], &ag.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, appendTo)
+	if err != nil {
+		return appendTo, err
+	}
+	for _, colIdx := range ag.groupCols {
+		if !ag.groupingSets[groupingSet].Contains(int(colIdx)) {
+			continue
+		}
+		appendTo, err = row[colIdx].Encode(
+			&ag.inputTypes[colIdx], &ag.datumAlloc, sqlbase.DatumEncoding_ASCENDING_KEY, appendTo)
+		if err != nil {
+			return appendTo, err
+		}
+	}
+	return appendTo, nil
+}
+
 func (ag *aggregatorBase) createAggregateFuncs() (aggregateFuncs, error) {
 	if err := ag.bucketsAcc.Grow(ag.Ctx, sizeOfAggregateFunc*int64(len(ag.funcs))); err != nil {
 		return nil, err
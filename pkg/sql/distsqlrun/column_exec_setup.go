@@ -18,14 +18,17 @@ import (
 	"context"
 	"reflect"
 
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/pkg/errors"
 )
 
@@ -36,8 +39,73 @@ func checkNumIn(inputs []exec.Operator, numIn int) error {
 	return nil
 }
 
+// vectorizedResources tracks the memory monitors, memory accounts and operators
+// holding temporary disk storage that are created while setting up a
+// vectorized flow, so that they can be released once the flow is done.
+type vectorizedResources struct {
+	monitors []*mon.BytesMonitor
+	accounts []*mon.BoundAccount
+	closers  []exec.Closer
+}
+
+// spillingStorage returns the memory account and the temporary disk storage
+// for an operator that can spill to disk. If the use of temporary storage is
+// disabled by useTempStorage, the disk factory and monitor are nil, and the
+// memory account is only limited by the flow's memory monitor.
+func (r *vectorizedResources) spillingStorage(
+	ctx context.Context, flowCtx *FlowCtx, name string, useTempStorage *settings.BoolSetting,
+) (*mon.BoundAccount, diskmap.Factory, *mon.BytesMonitor) {
+	if !useTempStorage.Get(&flowCtx.Settings.SV) && flowCtx.testingKnobs.MemoryLimitBytes <= 0 {
+		memMonitor := NewMonitor(ctx, flowCtx.EvalCtx.Mon, name+"-mem")
+		memAcc := memMonitor.MakeBoundAccount()
+		r.monitors = append(r.monitors, memMonitor)
+		r.accounts = append(r.accounts, &memAcc)
+		return &memAcc, nil, nil
+	}
+	// Limit the memory use by creating a child monitor with a hard limit. The
+	// operator will overflow to disk if this limit is not enough.
+	limit := flowCtx.testingKnobs.MemoryLimitBytes
+	if limit <= 0 {
+		limit = settingWorkMemBytes.Get(&flowCtx.Settings.SV)
+	}
+	limitedMon := mon.MakeMonitorInheritWithLimit(name+"-limited", limit, flowCtx.EvalCtx.Mon)
+	limitedMon.Start(ctx, flowCtx.EvalCtx.Mon, mon.BoundAccount{})
+	memAcc := limitedMon.MakeBoundAccount()
+	diskMonitor := NewMonitor(ctx, flowCtx.diskMonitor, name+"-disk")
+	r.monitors = append(r.monitors, &limitedMon, diskMonitor)
+	r.accounts = append(r.accounts, &memAcc)
+	return &memAcc, flowCtx.TempStorage, diskMonitor
+}
+
+// addCloser registers op to be closed along with the other resources, if it
+// holds any.
+func (r *vectorizedResources) addCloser(op exec.Operator) {
+	if c, ok := op.(exec.Closer); ok {
+		r.closers = append(r.closers, c)
+	}
+}
+
+// close releases all of the resources. The operators are closed first, since
+// their temporary storage is accounted for by the monitors.
+func (r *vectorizedResources) close(ctx context.Context) {
+	for _, c := range r.closers {
+		c.Close()
+	}
+	for _, acc := range r.accounts {
+		acc.Close(ctx)
+	}
+	for i := len(r.monitors) - 1; i >= 0; i-- {
+		r.monitors[i].Stop(ctx)
+	}
+	*r = vectorizedResources{}
+}
+
 func newColOperator(
-	ctx context.Context, flowCtx *FlowCtx, spec *distsqlpb.ProcessorSpec, inputs []exec.Operator,
+	ctx context.Context,
+	flowCtx *FlowCtx,
+	spec *distsqlpb.ProcessorSpec,
+	inputs []exec.Operator,
+	res *vectorizedResources,
 ) (exec.Operator, error) {
 	core := &spec.Core
 	post := &spec.Post
@@ -51,19 +119,37 @@ func newColOperator(
 	// interface.
 	var columnTypes []sqlbase.ColumnType
 
+	for i := range spec.Input {
+		if err := checkColumnTypes(spec.Input[i].ColumnTypes); err != nil {
+			return nil, err
+		}
+	}
+
 	switch {
 	case core.TableReader != nil:
 		if err := checkNumIn(inputs, 0); err != nil {
 			return nil, err
 		}
-		op, err = newColBatchScan(flowCtx, core.TableReader, post)
+		if core.TableReader.LockForUpdate {
+			// The columnar scan does not acquire row-level locks. Failing to set
+			// up the vectorized flow makes the flow fall back on the row-based
+			// tableReader, except with experimental_vectorize=always.
+			return nil, errors.New("row-level locking not supported")
+		}
 		returnMutations := core.TableReader.Visibility == distsqlpb.ScanVisibility_PUBLIC_AND_NOT_PUBLIC
 		columnTypes = core.TableReader.Table.ColumnTypesWithMutations(returnMutations)
+		if err := checkColumnTypes(columnTypes); err != nil {
+			return nil, err
+		}
+		op, err = newColBatchScan(flowCtx, core.TableReader, post)
 	case core.Aggregator != nil:
 		if err := checkNumIn(inputs, 1); err != nil {
 			return nil, err
 		}
 		aggSpec := core.Aggregator
+		if len(aggSpec.GroupingSets) > 0 {
+			return nil, errors.New("grouping sets not supported")
+		}
 		if len(aggSpec.GroupCols) == 0 &&
 			len(aggSpec.Aggregations) == 1 &&
 			aggSpec.Aggregations[0].FilterColIdx == nil &&
@@ -186,7 +272,11 @@ func newColOperator(
 			}
 		}
 
-		op, err = exec.NewEqHashJoinerOp(
+		memAcc, diskFactory, diskMonitor := res.spillingStorage(
+			ctx, flowCtx, "hashjoiner", settingUseTempStorageJoins,
+		)
+		op, err = exec.NewExternalEqHashJoinerOp(
+			ctx,
 			inputs[0],
 			inputs[1],
 			core.HashJoiner.LeftEqColumns,
@@ -198,15 +288,188 @@ func newColOperator(
 			core.HashJoiner.RightEqColumnsAreKey,
 			core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
 			core.HashJoiner.Type,
+			memAcc,
+			diskFactory,
+			diskMonitor,
 		)
+		if err != nil {
+			return nil, err
+		}
+		res.addCloser(op)
+
+	case core.MergeJoiner != nil:
+		if err := checkNumIn(inputs, 2); err != nil {
+			return nil, err
+		}
+
+		if !core.MergeJoiner.OnExpr.Empty() {
+			return nil, errors.New("can't plan merge join with on expressions")
+		}
+		if core.MergeJoiner.NullEquality {
+			return nil, errors.New("can't plan merge join with null equality")
+		}
+
+		leftTypes := types.FromColumnTypes(spec.Input[0].ColumnTypes)
+		rightTypes := types.FromColumnTypes(spec.Input[1].ColumnTypes)
+
+		nLeftCols := uint32(len(leftTypes))
+		nRightCols := uint32(len(rightTypes))
+
+		// Semi and anti joins only output the columns of the left input.
+		outputsRightCols := core.MergeJoiner.Type != sqlbase.JoinType_LEFT_SEMI &&
+			core.MergeJoiner.Type != sqlbase.JoinType_LEFT_ANTI
+
+		leftOutCols := make([]uint32, 0)
+		rightOutCols := make([]uint32, 0)
+
+		if post.Projection {
+			for _, col := range post.OutputColumns {
+				if col < nLeftCols {
+					leftOutCols = append(leftOutCols, col)
+				} else {
+					rightOutCols = append(rightOutCols, col-nLeftCols)
+				}
+			}
+		} else {
+			for i := uint32(0); i < nLeftCols; i++ {
+				leftOutCols = append(leftOutCols, i)
+			}
+
+			if outputsRightCols {
+				for i := uint32(0); i < nRightCols; i++ {
+					rightOutCols = append(rightOutCols, i)
+				}
+			}
+		}
+
+		op, err = exec.NewMergeJoinOp(
+			core.MergeJoiner.Type,
+			inputs[0],
+			inputs[1],
+			leftOutCols,
+			rightOutCols,
+			leftTypes,
+			rightTypes,
+			core.MergeJoiner.LeftOrdering.Columns,
+			core.MergeJoiner.RightOrdering.Columns,
+		)
+
+	case core.Windower != nil:
+		if err := checkNumIn(inputs, 1); err != nil {
+			return nil, err
+		}
+		if len(core.Windower.WindowFns) != 1 {
+			return nil, errors.New("only a single window function is supported")
+		}
+		wf := core.Windower.WindowFns[0]
+		if wf.Func.WindowFunc == nil {
+			return nil, errors.New("window aggregate functions not supported")
+		}
+		if wf.FilterColIdx >= 0 {
+			return nil, errors.New("window functions with FILTER clause not supported")
+		}
+		if wf.ArgCount != 0 {
+			return nil, errors.New("window functions with arguments not supported")
+		}
+
+		inputTypes := spec.Input[0].ColumnTypes
+		typs := types.FromColumnTypes(inputTypes)
+		input := inputs[0]
+
+		// The input has to be sorted on the partitioning columns followed by the
+		// ordering columns of the window function.
+		partitionTyps := make([]types.T, len(core.Windower.PartitionBy))
+		orderingCols := make(
+			[]distsqlpb.Ordering_Column, 0, len(partitionTyps)+len(wf.Ordering.Columns),
+		)
+		for i, col := range core.Windower.PartitionBy {
+			partitionTyps[i] = typs[col]
+			orderingCols = append(orderingCols, distsqlpb.Ordering_Column{
+				ColIdx:    col,
+				Direction: distsqlpb.Ordering_Column_ASC,
+			})
+		}
+		orderingCols = append(orderingCols, wf.Ordering.Columns...)
+		if len(orderingCols) > 0 {
+			memAcc, diskFactory, diskMonitor := res.spillingStorage(
+				ctx, flowCtx, "windower-sorter", settingUseTempStorageSorts,
+			)
+			input, err = exec.NewExternalSorter(
+				ctx, input, typs, orderingCols, memAcc, diskFactory, diskMonitor,
+			)
+			if err != nil {
+				return nil, err
+			}
+			res.addCloser(input)
+		}
+
+		// The result of the window function is appended to the input columns.
+		outputColIdx := len(typs)
+		switch *wf.Func.WindowFunc {
+		case distsqlpb.WindowerSpec_ROW_NUMBER:
+			op, err = exec.NewRowNumberOperator(
+				input, core.Windower.PartitionBy, partitionTyps, outputColIdx,
+			)
+		case distsqlpb.WindowerSpec_RANK, distsqlpb.WindowerSpec_DENSE_RANK:
+			peersCols := make([]uint32, len(wf.Ordering.Columns))
+			peersTyps := make([]types.T, len(wf.Ordering.Columns))
+			for i, col := range wf.Ordering.Columns {
+				peersCols[i] = col.ColIdx
+				peersTyps[i] = typs[col.ColIdx]
+			}
+			op, err = exec.NewRankOperator(
+				input,
+				*wf.Func.WindowFunc == distsqlpb.WindowerSpec_DENSE_RANK,
+				core.Windower.PartitionBy,
+				partitionTyps,
+				peersCols,
+				peersTyps,
+				outputColIdx,
+			)
+		default:
+			return nil, errors.Errorf("window function %s not supported", *wf.Func.WindowFunc)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// The windower outputs the result of its window function in place of the
+		// arguments of the function, that is, at ArgIdxStart.
+		projection := make([]uint32, 0, len(typs)+1)
+		for i := uint32(0); i < wf.ArgIdxStart; i++ {
+			projection = append(projection, i)
+		}
+		projection = append(projection, uint32(outputColIdx))
+		for i := wf.ArgIdxStart; i < uint32(len(typs)); i++ {
+			projection = append(projection, i)
+		}
+		op = exec.NewSimpleProjectOp(op, projection)
+
+		columnTypes = make([]sqlbase.ColumnType, 0, len(inputTypes)+1)
+		columnTypes = append(columnTypes, inputTypes[:wf.ArgIdxStart]...)
+		columnTypes = append(columnTypes, sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_INT})
+		columnTypes = append(columnTypes, inputTypes[wf.ArgIdxStart:]...)
 
 	case core.Sorter != nil:
 		if err := checkNumIn(inputs, 1); err != nil {
 			return nil, err
 		}
-		op, err = exec.NewSorter(inputs[0],
+		memAcc, diskFactory, diskMonitor := res.spillingStorage(
+			ctx, flowCtx, "sorter", settingUseTempStorageSorts,
+		)
+		op, err = exec.NewExternalSorter(
+			ctx,
+			inputs[0],
 			types.FromColumnTypes(spec.Input[0].ColumnTypes),
-			core.Sorter.OutputOrdering.Columns)
+			core.Sorter.OutputOrdering.Columns,
+			memAcc,
+			diskFactory,
+			diskMonitor,
+		)
+		if err != nil {
+			return nil, err
+		}
+		res.addCloser(op)
 
 	default:
 		return nil, errors.Errorf("unsupported processor core %s", core)
@@ -276,6 +539,20 @@ func newColOperator(
 	return op, nil
 }
 
+// checkColumnTypes returns an error if one of the given column types has no
+// vectorized type, which makes the flow fall back on the row-based processors.
+// This is notably the case of collated strings: they compare according to
+// their collation rather than to their bytes, so they can't be handled as
+// Bytes.
+func checkColumnTypes(cts []sqlbase.ColumnType) error {
+	for i := range cts {
+		if types.FromColumnType(cts[i]) == types.Unhandled {
+			return errors.Errorf("unsupported type %s", cts[i].SQLString())
+		}
+	}
+	return nil
+}
+
 // planExpressionOperators plans a chain of operators to execute the provided
 // expression. It returns the the tail of the chain, as well as the column index
 // of the expression's result (if any, otherwise -1) and the column types of the
@@ -374,9 +651,19 @@ func planExpressionOperators(
 	}
 }
 
-func (f *Flow) setupVectorized(ctx context.Context) error {
+func (f *Flow) setupVectorized(ctx context.Context) (retErr error) {
 	f.processors = make([]Processor, 1)
 
+	// res holds the resources of the operators that are set up. They are handed
+	// off to the materializer, which releases them once the flow is done, or
+	// released here if the setup fails.
+	res := &vectorizedResources{}
+	defer func() {
+		if retErr != nil {
+			res.close(ctx)
+		}
+	}()
+
 	streamIDToInputOp := make(map[distsqlpb.StreamID]exec.Operator)
 	streamIDToSpecIdx := make(map[distsqlpb.StreamID]int)
 	// queue is a queue of indices into f.spec.Processors, for topologically
@@ -427,7 +714,7 @@ func (f *Flow) setupVectorized(ctx context.Context) error {
 			inputs = append(inputs, streamIDToInputOp[inputStream.StreamID])
 		}
 
-		op, err := newColOperator(ctx, &f.FlowCtx, pspec, inputs)
+		op, err := newColOperator(ctx, &f.FlowCtx, pspec, inputs, res)
 		if err != nil {
 			return err
 		}
@@ -446,6 +733,7 @@ func (f *Flow) setupVectorized(ctx context.Context) error {
 			if err != nil {
 				return err
 			}
+			proc.resources = res
 			f.processors[0] = proc
 		default:
 			return errors.Errorf("unsupported output stream type %s", outputStream.Type)
@@ -48,7 +48,8 @@ const (
 	jrPerformingLookup
 	// jrCollectingOutputRows means we are collecting the result of the index
 	// lookup to be emitted, while preserving the order of the input, and
-	// optionally rendering rows for unmatched inputs for left outer joins.
+	// optionally rendering rows for unmatched inputs for left outer and anti
+	// joins.
 	jrCollectingOutputRows
 	// jrEmittingRows means we are emitting the results of the index lookup.
 	jrEmittingRows
@@ -519,7 +520,18 @@ func (jr *joinReader) performLookup() (joinReaderState, *ProducerMetadata) {
 				jr.MoveToDraining(err)
 				return jrStateUnknown, jr.DrainHelper()
 			}
-			if renderedRow != nil {
+			if renderedRow == nil {
+				continue
+			}
+			switch jr.joinType {
+			case sqlbase.LeftSemiJoin, sqlbase.LeftAntiJoin:
+				// Semi and anti joins only need to know whether the input row has a
+				// match; the input row itself is the only output row.
+				if len(jr.inputRowIdxToOutputRows[inputRowIdx]) == 0 {
+					jr.inputRowIdxToOutputRows[inputRowIdx] = append(
+						jr.inputRowIdxToOutputRows[inputRowIdx], jr.inputRows[inputRowIdx])
+				}
+			default:
 				rowCopy := jr.out.rowAlloc.CopyRow(renderedRow)
 				jr.inputRowIdxToOutputRows[inputRowIdx] = append(
 					jr.inputRowIdxToOutputRows[inputRowIdx], rowCopy)
@@ -536,9 +548,16 @@ func (jr *joinReader) performLookup() (joinReaderState, *ProducerMetadata) {
 
 // collectOutputRows iterates over jr.inputRowIdxToOutputRows and adds output
 // rows to jr.Emit, rendering rows for unmatched inputs if the join is a left
-// outer join, while preserving the input order.
+// outer join (or emitting them if it is an anti join), while preserving the
+// input order.
 func (jr *joinReader) collectOutputRows() joinReaderState {
 	for i, outputRows := range jr.inputRowIdxToOutputRows {
+		if jr.joinType == sqlbase.LeftAntiJoin {
+			if len(outputRows) == 0 {
+				jr.toEmit = append(jr.toEmit, jr.inputRows[i])
+			}
+			continue
+		}
 		if len(outputRows) == 0 {
 			if jr.joinType == sqlbase.LeftOuterJoin {
 				if row := jr.renderUnmatchedRow(jr.inputRows[i], leftSide); row != nil {
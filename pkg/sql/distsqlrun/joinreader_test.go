@@ -219,6 +219,58 @@ func TestJoinReader(t *testing.T) {
 			outputTypes: threeIntCols,
 			expected:    "[[10 0 NULL] [0 2 2]]",
 		},
+		{
+			description: "Test semi lookup join on primary index",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{aFn(110), bFn(110)},
+				{aFn(5), bFn(5)},
+			},
+			lookupCols:  []uint32{0, 1},
+			joinType:    sqlbase.LeftSemiJoin,
+			inputTypes:  twoIntCols,
+			outputTypes: twoIntCols,
+			expected:    "[[0 2] [0 5]]",
+		},
+		{
+			description: "Test anti lookup join on primary index",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{aFn(110), bFn(110)},
+				{aFn(5), bFn(5)},
+			},
+			lookupCols:  []uint32{0, 1},
+			joinType:    sqlbase.LeftAntiJoin,
+			inputTypes:  twoIntCols,
+			outputTypes: twoIntCols,
+			expected:    "[[11 0]]",
+		},
+		{
+			description: "Test anti lookup join with an ON condition",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{aFn(2), bFn(2)},
+				{aFn(5), bFn(5)},
+				{tree.DNull, bFn(5)},
+			},
+			lookupCols:  []uint32{0, 1},
+			onExpr:      "@5 > 3",
+			joinType:    sqlbase.LeftAntiJoin,
+			inputTypes:  twoIntCols,
+			outputTypes: twoIntCols,
+			expected:    "[[0 2] [NULL 5]]",
+		},
 		{
 			description: "Test left outer lookup join on covering secondary index",
 			indexIdx:    1,
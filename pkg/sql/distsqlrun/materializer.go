@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 // materializer converts an exec.Operator input into a RowSource.
@@ -51,6 +53,10 @@ type materializer struct {
 
 	// row is the memory used for the output row.
 	row sqlbase.EncDatumRow
+
+	// resources, if set, are the resources held by the input operator tree,
+	// which are released when the materializer is closed.
+	resources *vectorizedResources
 }
 
 func newMaterializer(
@@ -88,6 +94,16 @@ func newMaterializer(
 			m.row[i] = sqlbase.EncDatum{Datum: tree.NewDName("")}
 		case sqlbase.ColumnType_OID:
 			m.row[i] = sqlbase.EncDatum{Datum: tree.NewDOid(0)}
+		case sqlbase.ColumnType_TIMESTAMP:
+			m.row[i] = sqlbase.EncDatum{Datum: &tree.DTimestamp{}}
+		case sqlbase.ColumnType_TIMESTAMPTZ:
+			m.row[i] = sqlbase.EncDatum{Datum: &tree.DTimestampTZ{}}
+		case sqlbase.ColumnType_INTERVAL:
+			m.row[i] = sqlbase.EncDatum{Datum: &tree.DInterval{}}
+		case sqlbase.ColumnType_UUID:
+			m.row[i] = sqlbase.EncDatum{Datum: &tree.DUuid{}}
+		case sqlbase.ColumnType_JSONB:
+			m.row[i] = sqlbase.EncDatum{Datum: &tree.DJSON{JSON: json.NullJSONValue}}
 		default:
 			panic(fmt.Sprintf("Unsupported column type %s", ct.SQLString()))
 		}
@@ -99,24 +115,35 @@ func newMaterializer(
 		flowCtx,
 		processorID,
 		output,
-		nil,
-		ProcStateOpts{},
+		nil, /* memMonitor */
+		ProcStateOpts{
+			TrailingMetaCallback: func(context.Context) []ProducerMetadata {
+				m.close()
+				return nil
+			},
+		},
 	); err != nil {
 		return nil, err
 	}
 	return m, nil
 }
 
+const materializerProcName = "materializer"
+
 func (m *materializer) Start(ctx context.Context) context.Context {
 	m.input.Init()
-	return ctx
+	return m.StartInternal(ctx, materializerProcName)
 }
 
 func (m *materializer) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
 	for m.State == StateRunning {
 		if m.batch == nil || m.curIdx >= m.batch.Length() {
-			// Get a fresh batch.
-			m.batch = m.input.Next()
+			// Get a fresh batch. Operators that spill to disk report their errors
+			// by panicking, so catch those here and surface them as metadata.
+			if err := exec.CatchRuntimeError(func() { m.batch = m.input.Next() }); err != nil {
+				m.MoveToDraining(err)
+				return nil, m.DrainHelper()
+			}
 			if m.batch.Length() == 0 {
 				m.MoveToDraining(nil /* err */)
 				return nil, nil
@@ -174,6 +201,23 @@ func (m *materializer) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
 				m.row[outIdx].Datum = m.da.NewDName(tree.DString(*(*string)(unsafe.Pointer(&b))))
 			case sqlbase.ColumnType_OID:
 				m.row[outIdx].Datum = m.da.NewDOid(tree.MakeDOid(tree.DInt(col.Int64()[rowIdx])))
+			case sqlbase.ColumnType_TIMESTAMP:
+				m.row[outIdx].Datum = m.da.NewDTimestamp(tree.DTimestamp{Time: col.Timestamp()[rowIdx]})
+			case sqlbase.ColumnType_TIMESTAMPTZ:
+				m.row[outIdx].Datum = m.da.NewDTimestampTZ(
+					tree.DTimestampTZ{Time: col.Timestamp()[rowIdx]},
+				)
+			case sqlbase.ColumnType_INTERVAL:
+				m.row[outIdx].Datum = m.da.NewDInterval(tree.DInterval{Duration: col.Interval()[rowIdx]})
+			case sqlbase.ColumnType_UUID:
+				u, err := uuid.FromBytes(col.Bytes()[rowIdx])
+				if err != nil {
+					m.MoveToDraining(err)
+					return nil, m.DrainHelper()
+				}
+				m.row[outIdx].Datum = m.da.NewDUuid(tree.DUuid{UUID: u})
+			case sqlbase.ColumnType_JSONB:
+				m.row[outIdx].Datum = m.da.NewDJSON(tree.DJSON{JSON: col.JSON()[rowIdx]})
 			default:
 				panic(fmt.Sprintf("Unsupported column type %s", ct.SQLString()))
 			}
@@ -183,6 +227,12 @@ func (m *materializer) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
 	return nil, nil
 }
 
+func (m *materializer) close() {
+	if m.InternalClose() && m.resources != nil {
+		m.resources.close(m.Ctx)
+	}
+}
+
 func (m *materializer) ConsumerClosed() {
-	m.InternalClose()
+	m.close()
 }
@@ -81,6 +81,12 @@ type ProcOutputHelper struct {
 	maxRowIdx uint64
 
 	rowIdx uint64
+
+	// lockRow, if set, is invoked on each row that passes the filter, before
+	// the row counts towards the offset and the limit. It acquires the
+	// row-level lock on the row and returns false if the row should be skipped
+	// because it is locked by another transaction (SELECT ... SKIP LOCKED).
+	lockRow func(context.Context) (bool, error)
 }
 
 // Reset resets this ProcOutputHelper, retaining allocated memory in its slices.
@@ -364,6 +370,15 @@ func (h *ProcOutputHelper) ProcessRow(
 			return nil, true, nil
 		}
 	}
+	if h.lockRow != nil {
+		locked, err := h.lockRow(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		if !locked {
+			return nil, true, nil
+		}
+	}
 	h.rowIdx++
 	if h.rowIdx <= h.offset {
 		// Suppress row.
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -119,6 +120,21 @@ func newTableReader(
 	); err != nil {
 		return nil, err
 	}
+	if spec.LockForUpdate {
+		// The locks are acquired on the rows that pass the post-processing
+		// filter, through the root transaction of the gateway's flow.
+		waitPolicy := tree.LockWaitBlock
+		switch spec.LockingWaitPolicy {
+		case distsqlpb.ScanLockingWaitPolicy_SKIP_LOCKED:
+			waitPolicy = tree.LockWaitSkip
+		case distsqlpb.ScanLockingWaitPolicy_NOWAIT:
+			waitPolicy = tree.LockWaitError
+		}
+		tr.fetcher.SetLocking(waitPolicy)
+		tr.out.lockRow = func(ctx context.Context) (bool, error) {
+			return tr.fetcher.LockRow(ctx, tr.flowCtx.txn)
+		}
+	}
 
 	nSpans := len(spec.Spans)
 	if cap(tr.spans) >= nSpans {
@@ -16,6 +16,7 @@ package sql
 
 import (
 	"context"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -32,6 +33,15 @@ type dropDatabaseNode struct {
 	n      *tree.DropDatabase
 	dbDesc *sqlbase.DatabaseDescriptor
 	td     []toDelete
+	// tempSchemaNames contains the names of the temporary schemas of the
+	// database, which are dropped along with it.
+	tempSchemaNames []string
+	// typeIDs contains the IDs of the user-defined types of the database,
+	// which are dropped along with it.
+	typeIDs []sqlbase.ID
+	// schemaIDs contains the IDs of the user-defined schemas of the database,
+	// which are dropped along with it.
+	schemaIDs []sqlbase.ID
 }
 
 // DropDatabase drops a database.
@@ -70,7 +80,56 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
-	if len(tbNames) > 0 {
+	// The temporary tables of all the sessions are dropped too.
+	tempSchemas, err := listTemporarySchemas(ctx, p.txn, dbDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	tempSchemaNames := make([]string, 0, len(tempSchemas))
+	for scName := range tempSchemas {
+		tempSchemaNames = append(tempSchemaNames, scName)
+	}
+	sort.Strings(tempSchemaNames)
+	for _, scName := range tempSchemaNames {
+		tempNames, err := GetObjectNames(ctx, p.txn, p, dbDesc, scName, true /*explicitPrefix*/)
+		if err != nil {
+			return nil, err
+		}
+		tbNames = append(tbNames, tempNames...)
+	}
+
+	// The user-defined schemas and their tables are dropped too.
+	schemas, err := listUserSchemas(ctx, p.txn, dbDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	schemaNames := make([]string, 0, len(schemas))
+	for scName := range schemas {
+		schemaNames = append(schemaNames, scName)
+	}
+	sort.Strings(schemaNames)
+	schemaIDs := make([]sqlbase.ID, 0, len(schemas))
+	for _, scName := range schemaNames {
+		scNames, err := GetObjectNames(ctx, p.txn, p, dbDesc, scName, true /*explicitPrefix*/)
+		if err != nil {
+			return nil, err
+		}
+		tbNames = append(tbNames, scNames...)
+		schemaIDs = append(schemaIDs, schemas[scName])
+	}
+
+	// The user-defined types are dropped too.
+	typs, err := listTypes(ctx, p.txn, dbDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	typeIDs := make([]sqlbase.ID, 0, len(typs))
+	for _, id := range typs {
+		typeIDs = append(typeIDs, id)
+	}
+	sort.Slice(typeIDs, func(i, j int) bool { return typeIDs[i] < typeIDs[j] })
+
+	if len(tbNames) > 0 || len(typeIDs) > 0 || len(schemaIDs) > 0 {
 		switch n.DropBehavior {
 		case tree.DropRestrict:
 			return nil, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
@@ -110,7 +169,10 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
-	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td}, nil
+	return &dropDatabaseNode{
+		n: n, dbDesc: dbDesc, td: td, tempSchemaNames: tempSchemaNames, typeIDs: typeIDs,
+		schemaIDs: schemaIDs,
+	}, nil
 }
 
 func (n *dropDatabaseNode) startExec(params runParams) error {
@@ -171,6 +233,60 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 	}
 	b.Del(descKey)
 	b.Del(nameKey)
+	for _, scName := range n.tempSchemaNames {
+		scKey := sqlbase.MakeNameMetadataKey(n.dbDesc.ID, scName)
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", scKey)
+		}
+		b.Del(scKey)
+	}
+	if len(n.typeIDs) > 0 {
+		// Delete the type namespace, the names of the types in it and the
+		// descriptors of the types.
+		nsID, err := getTypeNamespaceID(ctx, p.txn, n.dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		nsKey := sqlbase.MakeNameMetadataKey(n.dbDesc.ID, typeNamespaceName)
+		typesPrefix := sqlbase.MakeNameMetadataKey(nsID, "")
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", nsKey)
+			log.VEventf(ctx, 2, "DelRange %s - %s", typesPrefix, typesPrefix.PrefixEnd())
+		}
+		b.Del(nsKey)
+		b.DelRange(typesPrefix, typesPrefix.PrefixEnd(), false /* returnKeys */)
+		for _, id := range n.typeIDs {
+			typeKey := sqlbase.MakeDescMetadataKey(id)
+			if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+				log.VEventf(ctx, 2, "Del %s", typeKey)
+			}
+			b.Del(typeKey)
+		}
+	}
+
+	if len(n.schemaIDs) > 0 {
+		// Delete the schema namespace, the names of the schemas in it and the
+		// descriptors of the schemas.
+		nsID, err := getSchemaNamespaceID(ctx, p.txn, n.dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		nsKey := sqlbase.MakeNameMetadataKey(n.dbDesc.ID, schemaNamespaceName)
+		schemasPrefix := sqlbase.MakeNameMetadataKey(nsID, "")
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", nsKey)
+			log.VEventf(ctx, 2, "DelRange %s - %s", schemasPrefix, schemasPrefix.PrefixEnd())
+		}
+		b.Del(nsKey)
+		b.DelRange(schemasPrefix, schemasPrefix.PrefixEnd(), false /* returnKeys */)
+		for _, id := range n.schemaIDs {
+			schemaKey := sqlbase.MakeDescMetadataKey(id)
+			if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+				log.VEventf(ctx, 2, "Del %s", schemaKey)
+			}
+			b.Del(schemaKey)
+		}
+	}
 
 	// No job was created because no tables were dropped, so zone config can be
 	// immediately removed.
@@ -0,0 +1,220 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+type dropSchemaNode struct {
+	n      *tree.DropSchema
+	dbDesc *sqlbase.DatabaseDescriptor
+	d      []schemaToDelete
+}
+
+type schemaToDelete struct {
+	desc *sqlbase.SchemaDescriptor
+	td   []toDelete
+}
+
+// DropSchema drops schemas of the current database.
+// Privileges: DROP on schema and DROP on all tables in the schema.
+//   Notes: postgres allows only the schema owner to DROP a schema.
+func (p *planner) DropSchema(ctx context.Context, n *tree.DropSchema) (planNode, error) {
+	if p.CurrentDatabase() == "" {
+		return nil, errNoDatabase
+	}
+	dbDesc, err := p.ResolveUncachedDatabaseByName(ctx, p.CurrentDatabase(), true /* required */)
+	if err != nil {
+		return nil, err
+	}
+
+	d := make([]schemaToDelete, 0, len(n.Names))
+	for _, name := range n.Names {
+		scName := string(name)
+		if !isUserSchemaName(scName) {
+			return nil, pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+				"cannot drop schema %q", scName)
+		}
+		scDesc, err := getUserSchemaDesc(ctx, p.txn, dbDesc.ID, scName)
+		if err != nil {
+			return nil, err
+		}
+		if scDesc == nil {
+			if n.IfExists {
+				continue
+			}
+			return nil, sqlbase.NewUndefinedSchemaError(scName)
+		}
+
+		if err := p.CheckPrivilege(ctx, scDesc, privilege.DROP); err != nil {
+			return nil, err
+		}
+
+		tbNames, err := GetObjectNames(ctx, p.txn, p, dbDesc, scName, true /*explicitPrefix*/)
+		if err != nil {
+			return nil, err
+		}
+		if len(tbNames) > 0 {
+			switch n.DropBehavior {
+			case tree.DropRestrict, tree.DropDefault:
+				// Unlike DROP DATABASE, the default behavior of DROP SCHEMA
+				// is RESTRICT, like in PostgreSQL.
+				return nil, pgerror.NewErrorf(pgerror.CodeDependentObjectsStillExistError,
+					"schema %q is not empty and CASCADE was not specified", scName)
+			}
+		}
+
+		td := make([]toDelete, 0, len(tbNames))
+		for i := range tbNames {
+			tbDesc, err := p.prepareDrop(ctx, &tbNames[i], false /*required*/, anyDescType)
+			if err != nil {
+				return nil, err
+			}
+			if tbDesc == nil {
+				continue
+			}
+			// Recursively check permissions on all dependent views, since some
+			// may be in different schemas.
+			for _, ref := range tbDesc.DependedOnBy {
+				if err := p.canRemoveDependentView(ctx, tbDesc, ref, tree.DropCascade); err != nil {
+					return nil, err
+				}
+			}
+			td = append(td, toDelete{&tbNames[i], tbDesc})
+		}
+
+		td, err = p.filterCascadedTables(ctx, td)
+		if err != nil {
+			return nil, err
+		}
+		d = append(d, schemaToDelete{desc: scDesc, td: td})
+	}
+
+	if len(d) == 0 {
+		return newZeroNode(nil /* columns */), nil
+	}
+	return &dropSchemaNode{n: n, dbDesc: dbDesc, d: d}, nil
+}
+
+func (n *dropSchemaNode) startExec(params runParams) error {
+	ctx := params.ctx
+	p := params.p
+
+	nsID, err := getSchemaNamespaceID(ctx, p.txn, n.dbDesc.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, sc := range n.d {
+		tbNameStrings, err := p.dropSchemaTables(
+			params, sc.td, tree.AsStringWithFlags(n.n, tree.FmtAlwaysQualifyTableNames))
+		if err != nil {
+			return err
+		}
+
+		nameKey := sqlbase.MakeNameMetadataKey(nsID, sc.desc.Name)
+		descKey := sqlbase.MakeDescMetadataKey(sc.desc.ID)
+		b := &client.Batch{}
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "Del %s", descKey)
+			log.VEventf(ctx, 2, "Del %s", nameKey)
+		}
+		b.Del(descKey)
+		b.Del(nameKey)
+		if err := p.txn.Run(ctx, b); err != nil {
+			return err
+		}
+		p.Tables().releaseAllDescriptors()
+
+		// Log Drop Schema event. This is an auditable log event and is
+		// recorded in the same transaction as the schema descriptor update.
+		if err := MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+			ctx,
+			p.txn,
+			EventLogDropSchema,
+			int32(sc.desc.ID),
+			int32(params.extendedEvalCtx.NodeID),
+			struct {
+				SchemaName           string
+				Statement            string
+				User                 string
+				DroppedSchemaObjects []string
+			}{sc.desc.Name, n.n.String(), p.SessionData().User, tbNameStrings},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropSchemaTables drops the given tables and views of a schema, and returns
+// the names of the dropped objects.
+func (p *planner) dropSchemaTables(
+	params runParams, td []toDelete, stmt string,
+) ([]string, error) {
+	tbNameStrings := make([]string, 0, len(td))
+	droppedTableDetails := make([]jobspb.DroppedTableDetails, 0, len(td))
+	tableDescs := make([]*sqlbase.MutableTableDescriptor, 0, len(td))
+
+	for _, toDel := range td {
+		if toDel.desc.IsView() {
+			continue
+		}
+		droppedTableDetails = append(droppedTableDetails, jobspb.DroppedTableDetails{
+			Name: toDel.tn.FQString(),
+			ID:   toDel.desc.ID,
+		})
+		tableDescs = append(tableDescs, toDel.desc)
+	}
+
+	if _, err := p.createDropTablesJob(
+		params.ctx,
+		tableDescs,
+		droppedTableDetails,
+		stmt,
+		true, /* drainNames */
+		sqlbase.InvalidID /* droppedDatabaseID */); err != nil {
+		return nil, err
+	}
+
+	for _, toDel := range td {
+		var cascadedViews []string
+		var err error
+		if toDel.desc.IsView() {
+			cascadedViews, err = p.dropViewImpl(params.ctx, toDel.desc, tree.DropCascade)
+		} else {
+			cascadedViews, err = p.dropTableImpl(params, toDel.desc)
+		}
+		if err != nil {
+			return nil, err
+		}
+		tbNameStrings = append(tbNameStrings, cascadedViews...)
+		tbNameStrings = append(tbNameStrings, toDel.tn.FQString())
+	}
+	return tbNameStrings, nil
+}
+
+func (*dropSchemaNode) Next(runParams) (bool, error) { return false, nil }
+func (*dropSchemaNode) Values() tree.Datums          { return tree.Datums{} }
+func (*dropSchemaNode) Close(context.Context)        {}
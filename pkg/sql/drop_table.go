@@ -343,7 +343,10 @@ func (p *planner) initiateDropTable(
 	//
 	// TODO(bram): If interleaved and ON DELETE CASCADE, we will be
 	// able to use this faster mechanism.
-	if tableDesc.IsTable() && !tableDesc.IsInterleaved() &&
+	//
+	// Materialized views store their rows like tables, so their data is
+	// cleared the same way.
+	if (tableDesc.IsTable() || tableDesc.MaterializedView()) && !tableDesc.IsInterleaved() &&
 		p.ExecCfg().Settings.Version.IsActive(cluster.VersionClearRange) {
 		// Get the zone config applying to this table in order to
 		// ensure there is a GC TTL.
@@ -361,7 +364,7 @@ func (p *planner) initiateDropTable(
 	if drainName {
 		// Queue up name for draining.
 		nameDetails := sqlbase.TableDescriptor_NameInfo{
-			ParentID: tableDesc.ParentID,
+			ParentID: tableDesc.GetNamespaceParentID(),
 			Name:     tableDesc.Name}
 		tableDesc.DrainingNames = append(tableDesc.DrainingNames, nameDetails)
 	}
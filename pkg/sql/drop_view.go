@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -46,6 +47,9 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 			// IfExists specified and the view did not exist.
 			continue
 		}
+		if err := checkViewMatchesMaterialized(droppedDesc, n.IsMaterialized); err != nil {
+			return nil, err
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
@@ -111,6 +115,22 @@ func (*dropViewNode) Next(runParams) (bool, error) { return false, nil }
 func (*dropViewNode) Values() tree.Datums          { return tree.Datums{} }
 func (*dropViewNode) Close(context.Context)        {}
 
+// checkViewMatchesMaterialized errors if the given view is materialized and
+// materialized is false, or vice versa, like in PostgreSQL.
+func checkViewMatchesMaterialized(desc *sqlbase.MutableTableDescriptor, materialized bool) error {
+	if desc.MaterializedView() == materialized {
+		return nil
+	}
+	if materialized {
+		return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"%q is not a materialized view", desc.Name).SetHintf(
+			"Use DROP VIEW to remove a view.")
+	}
+	return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+		"%q is a materialized view", desc.Name).SetHintf(
+		"Use DROP MATERIALIZED VIEW to remove a materialized view.")
+}
+
 func descInSlice(descID sqlbase.ID, td []toDelete) bool {
 	for _, toDel := range td {
 		if descID == toDel.desc.ID {
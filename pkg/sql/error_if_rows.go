@@ -0,0 +1,56 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// errorIfRowsNode wraps another planNode and returns an error if the wrapped
+// node produces any rows. The error is created from the first row. The node
+// itself never produces rows.
+//
+// This node is used to check foreign keys: the wrapped node returns the rows
+// that violate the constraint.
+type errorIfRowsNode struct {
+	plan planNode
+
+	// mkErr creates the error from the first row of plan.
+	mkErr func(tree.Datums) error
+}
+
+// Next is part of the planNode interface.
+func (n *errorIfRowsNode) Next(params runParams) (bool, error) {
+	ok, err := n.plan.Next(params)
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		return false, n.mkErr(n.plan.Values())
+	}
+	return false, nil
+}
+
+// Values is part of the planNode interface.
+func (n *errorIfRowsNode) Values() tree.Datums {
+	return nil
+}
+
+// Close is part of the planNode interface.
+func (n *errorIfRowsNode) Close(ctx context.Context) {
+	n.plan.Close(ctx)
+}
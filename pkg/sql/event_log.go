@@ -61,6 +61,9 @@ const (
 	EventLogCreateView EventLogType = "create_view"
 	// EventLogDropView is recorded when a view is dropped.
 	EventLogDropView EventLogType = "drop_view"
+	// EventLogRefreshMaterializedView is recorded when the refresh of a
+	// materialized view is started.
+	EventLogRefreshMaterializedView EventLogType = "refresh_materialized_view"
 
 	// EventLogCreateSequence is recorded when a sequence is created.
 	EventLogCreateSequence EventLogType = "create_sequence"
@@ -69,6 +72,16 @@ const (
 	// EventLogAlterSequence is recorded when a sequence is altered.
 	EventLogAlterSequence EventLogType = "alter_sequence"
 
+	// EventLogCreateType is recorded when a type is created.
+	EventLogCreateType EventLogType = "create_type"
+	// EventLogAlterType is recorded when a type is altered.
+	EventLogAlterType EventLogType = "alter_type"
+
+	// EventLogCreateSchema is recorded when a schema is created.
+	EventLogCreateSchema EventLogType = "create_schema"
+	// EventLogDropSchema is recorded when a schema is dropped.
+	EventLogDropSchema EventLogType = "drop_schema"
+
 	// EventLogReverseSchemaChange is recorded when an in-progress schema change
 	// encounters a problem and is reversed.
 	EventLogReverseSchemaChange EventLogType = "reverse_schema_change"
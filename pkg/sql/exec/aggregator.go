@@ -179,15 +179,19 @@ func NewOrderedAggregator(
 			a.aggregateFuncs[i], err = newAvgAgg(aggTyps[i][0])
 		case distsqlpb.AggregatorSpec_SUM, distsqlpb.AggregatorSpec_SUM_INT:
 			a.aggregateFuncs[i], err = newSumAgg(aggTyps[i][0])
-		case distsqlpb.AggregatorSpec_COUNT_ROWS:
+		case distsqlpb.AggregatorSpec_COUNT_ROWS, distsqlpb.AggregatorSpec_COUNT:
 			a.aggregateFuncs[i] = newCountAgg()
+		case distsqlpb.AggregatorSpec_MIN:
+			a.aggregateFuncs[i], err = newMinAgg(aggTyps[i][0])
+		case distsqlpb.AggregatorSpec_MAX:
+			a.aggregateFuncs[i], err = newMaxAgg(aggTyps[i][0])
 		default:
 			return nil, errors.Errorf("unsupported columnar aggregate function %d", aggFns[i])
 		}
 
 		// Set the output type of the aggregate.
 		switch aggFns[i] {
-		case distsqlpb.AggregatorSpec_COUNT_ROWS:
+		case distsqlpb.AggregatorSpec_COUNT_ROWS, distsqlpb.AggregatorSpec_COUNT:
 			// TODO(jordan): this is a somewhat of a hack. The aggregate functions
 			// should come with their own output types, somehow.
 			a.outputTyps[i] = types.Int64
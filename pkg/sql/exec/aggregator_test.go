@@ -220,6 +220,75 @@ func TestAggregatorOneFunc(t *testing.T) {
 			groupCols:       []uint32{},
 			groupTypes:      []types.T{},
 		},
+		{
+			aggFns: []distsqlpb.AggregatorSpec_Func{distsqlpb.AggregatorSpec_MIN},
+			input: tuples{
+				{0, 3},
+				{0, nil},
+				{0, 1},
+				{1, nil},
+				{2, 5},
+				{3, nil},
+				{3, 4},
+				{4, nil},
+				{4, nil},
+			},
+			expected: tuples{
+				{1},
+				{nil},
+				{5},
+				{4},
+				{nil},
+			},
+			batchSize:       2,
+			outputBatchSize: 1,
+			name:            "MinWithNulls",
+		},
+		{
+			aggFns: []distsqlpb.AggregatorSpec_Func{distsqlpb.AggregatorSpec_MAX},
+			input: tuples{
+				{0, 3},
+				{0, nil},
+				{0, 1},
+				{1, nil},
+				{2, 5},
+				{3, nil},
+				{3, 4},
+				{4, nil},
+				{4, nil},
+			},
+			expected: tuples{
+				{3},
+				{nil},
+				{5},
+				{4},
+				{nil},
+			},
+			batchSize:       2,
+			outputBatchSize: 1,
+			name:            "MaxWithNulls",
+		},
+		{
+			aggFns: []distsqlpb.AggregatorSpec_Func{distsqlpb.AggregatorSpec_COUNT},
+			input: tuples{
+				{0, 3},
+				{0, nil},
+				{0, 1},
+				{1, nil},
+				{2, 5},
+				{3, nil},
+				{3, 4},
+			},
+			expected: tuples{
+				{2},
+				{0},
+				{1},
+				{1},
+			},
+			batchSize:       2,
+			outputBatchSize: 1,
+			name:            "CountWithNulls",
+		},
 	}
 
 	// Run tests with deliberate batch sizes and no selection vectors.
@@ -0,0 +1,177 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/pkg/errors"
+)
+
+// encodeBatch appends the encoding of the selected tuples of batch, whose
+// columns have the given types, to appendTo. The encoding consists of the
+// number of tuples followed by the values of each column in turn, using the
+// value encoding without column IDs.
+func encodeBatch(appendTo []byte, batch ColBatch, typs []types.T) ([]byte, error) {
+	n := batch.Length()
+	sel := batch.Selection()
+	appendTo = encoding.EncodeUvarintAscending(appendTo, uint64(n))
+	var err error
+	for colIdx, t := range typs {
+		vec := batch.ColVec(colIdx)
+		hasNulls := vec.HasNulls()
+		for i := uint16(0); i < n; i++ {
+			idx := i
+			if sel != nil {
+				idx = sel[i]
+			}
+			if hasNulls && vec.NullAt(idx) {
+				appendTo = encoding.EncodeNullValue(appendTo, encoding.NoColumnID)
+				continue
+			}
+			if appendTo, err = encodeValue(appendTo, vec, idx, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return appendTo, nil
+}
+
+// encodeValue appends the value encoding of the non-null value at idx of vec
+// to appendTo.
+func encodeValue(appendTo []byte, vec ColVec, idx uint16, t types.T) ([]byte, error) {
+	const colID = encoding.NoColumnID
+	switch t {
+	case types.Bool:
+		return encoding.EncodeBoolValue(appendTo, colID, vec.Bool()[idx]), nil
+	case types.Int8:
+		return encoding.EncodeIntValue(appendTo, colID, int64(vec.Int8()[idx])), nil
+	case types.Int16:
+		return encoding.EncodeIntValue(appendTo, colID, int64(vec.Int16()[idx])), nil
+	case types.Int32:
+		return encoding.EncodeIntValue(appendTo, colID, int64(vec.Int32()[idx])), nil
+	case types.Int64:
+		return encoding.EncodeIntValue(appendTo, colID, vec.Int64()[idx]), nil
+	case types.Float32:
+		return encoding.EncodeFloatValue(appendTo, colID, float64(vec.Float32()[idx])), nil
+	case types.Float64:
+		return encoding.EncodeFloatValue(appendTo, colID, vec.Float64()[idx]), nil
+	case types.Bytes:
+		return encoding.EncodeBytesValue(appendTo, colID, vec.Bytes()[idx]), nil
+	case types.Decimal:
+		return encoding.EncodeDecimalValue(appendTo, colID, &vec.Decimal()[idx]), nil
+	case types.Timestamp:
+		return encoding.EncodeTimeValue(appendTo, colID, vec.Timestamp()[idx]), nil
+	case types.Interval:
+		return encoding.EncodeDurationValue(appendTo, colID, vec.Interval()[idx]), nil
+	case types.JSON:
+		data, err := json.EncodeJSON(nil, vec.JSON()[idx])
+		if err != nil {
+			return nil, err
+		}
+		return encoding.EncodeJSONValue(appendTo, colID, data), nil
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+}
+
+// decodeBatch decodes a batch encoded by encodeBatch into batch, which must
+// have been allocated with the given types and a capacity of at least the
+// number of encoded tuples. The selection vector of batch is unset.
+func decodeBatch(b []byte, batch ColBatch, typs []types.T) error {
+	b, n, err := encoding.DecodeUvarintAscending(b)
+	if err != nil {
+		return err
+	}
+	for colIdx, t := range typs {
+		vec := batch.ColVec(colIdx)
+		vec.UnsetNulls()
+		for i := uint16(0); i < uint16(n); i++ {
+			_, dataOffset, _, typ, err := encoding.DecodeValueTag(b)
+			if err != nil {
+				return err
+			}
+			if typ == encoding.Null {
+				vec.SetNull(i)
+				b = b[dataOffset:]
+				continue
+			}
+			// Bool is special because the value is stored in the value tag.
+			if t != types.Bool {
+				b = b[dataOffset:]
+			}
+			if b, err = decodeValue(b, vec, i, t); err != nil {
+				return err
+			}
+		}
+	}
+	if len(b) != 0 {
+		return errors.Errorf("%d trailing bytes in encoded batch", len(b))
+	}
+	batch.SetSelection(false)
+	batch.SetLength(uint16(n))
+	return nil
+}
+
+// decodeValue decodes a value encoded by encodeValue into the idx'th position
+// of vec. Except for booleans, the value tag must already have been consumed.
+func decodeValue(b []byte, vec ColVec, idx uint16, t types.T) ([]byte, error) {
+	var err error
+	switch t {
+	case types.Bool:
+		b, vec.Bool()[idx], err = encoding.DecodeBoolValue(b)
+	case types.Int8, types.Int16, types.Int32, types.Int64:
+		var i int64
+		b, i, err = encoding.DecodeUntaggedIntValue(b)
+		switch t {
+		case types.Int8:
+			vec.Int8()[idx] = int8(i)
+		case types.Int16:
+			vec.Int16()[idx] = int16(i)
+		case types.Int32:
+			vec.Int32()[idx] = int32(i)
+		default:
+			vec.Int64()[idx] = i
+		}
+	case types.Float32, types.Float64:
+		var f float64
+		b, f, err = encoding.DecodeUntaggedFloatValue(b)
+		if t == types.Float32 {
+			vec.Float32()[idx] = float32(f)
+		} else {
+			vec.Float64()[idx] = f
+		}
+	case types.Bytes:
+		b, vec.Bytes()[idx], err = encoding.DecodeUntaggedBytesValue(b)
+	case types.Decimal:
+		b, err = encoding.DecodeIntoUntaggedDecimalValue(&vec.Decimal()[idx], b)
+	case types.Timestamp:
+		b, vec.Timestamp()[idx], err = encoding.DecodeUntaggedTimeValue(b)
+	case types.Interval:
+		b, vec.Interval()[idx], err = encoding.DecodeUntaggedDurationValue(b)
+	case types.JSON:
+		var data []byte
+		if b, data, err = encoding.DecodeUntaggedBytesValue(b); err != nil {
+			return b, err
+		}
+		vec.JSON()[idx], err = json.FromEncoding(data)
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+	return b, err
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// encodingRoundTripOp is an Operator that returns the result of encoding and
+// decoding each of the batches of its input.
+type encodingRoundTripOp struct {
+	t     *testing.T
+	input Operator
+	typs  []types.T
+
+	output ColBatch
+}
+
+func (o *encodingRoundTripOp) Init() {
+	o.input.Init()
+	o.output = NewMemBatch(o.typs)
+}
+
+func (o *encodingRoundTripOp) Next() ColBatch {
+	// The decoded values may reference the encoded batch, so a new buffer is
+	// used for every batch.
+	b, err := encodeBatch(nil /* appendTo */, o.input.Next(), o.typs)
+	if err != nil {
+		o.t.Fatal(err)
+	}
+	if err := decodeBatch(b, o.output, o.typs); err != nil {
+		o.t.Fatal(err)
+	}
+	return o.output
+}
+
+func TestBatchEncoding(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := time.Unix(1546300800, 123456789).UTC()
+	d := duration.Duration{Months: 1, Days: 2, Nanos: 3}
+	tups := tuples{
+		{true, int16(1), int64(-1), float64(1.5), []byte("foo"), ts, d},
+		{nil, nil, nil, nil, nil, nil, nil},
+		{false, int16(-2), int64(1 << 40), float64(-0.25), []byte{}, ts.Add(time.Hour), d},
+		{nil, int16(3), nil, float64(0), []byte("bar"), nil, duration.Duration{}},
+	}
+	typs := []types.T{
+		types.Bool, types.Int16, types.Int64, types.Float64,
+		types.Bytes, types.Timestamp, types.Interval,
+	}
+	runTests(t, []tuples{tups}, nil, func(t *testing.T, input []Operator) {
+		op := &encodingRoundTripOp{t: t, input: input[0], typs: typs}
+		out := newOpTestOutput(op, []int{0, 1, 2, 3, 4, 5, 6}, tups)
+		if err := out.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// bufferedBatches is an Operator that returns the batches that were added to
+// it, in the order in which they were added. Batches are copied when they are
+// added, and the references to them are dropped once they have been returned.
+type bufferedBatches struct {
+	typs    []types.T
+	batches []ColBatch
+
+	zeroBatch ColBatch
+}
+
+var _ Operator = &bufferedBatches{}
+
+func newBufferedBatches(typs []types.T) *bufferedBatches {
+	return &bufferedBatches{
+		typs:      typs,
+		zeroBatch: NewMemBatchWithSize(typs, 0),
+	}
+}
+
+// add appends a copy of the selected tuples of batch to the buffer.
+func (b *bufferedBatches) add(batch ColBatch) {
+	n := batch.Length()
+	if n == 0 {
+		return
+	}
+	buffered := NewMemBatchWithSize(b.typs, 0)
+	sel := batch.Selection()
+	for i, t := range b.typs {
+		buffered.ColVec(i).AppendSlice(batch.ColVec(i), t, 0 /* destStartIdx */, 0, n, sel)
+	}
+	buffered.SetLength(n)
+	b.batches = append(b.batches, buffered)
+}
+
+// empty returns whether there are no batches left in the buffer.
+func (b *bufferedBatches) empty() bool {
+	return len(b.batches) == 0
+}
+
+// reset drops all of the batches in the buffer.
+func (b *bufferedBatches) reset() {
+	for i := range b.batches {
+		b.batches[i] = nil
+	}
+	b.batches = b.batches[:0]
+}
+
+func (b *bufferedBatches) Init() {}
+
+func (b *bufferedBatches) Next() ColBatch {
+	if len(b.batches) == 0 {
+		return b.zeroBatch
+	}
+	batch := b.batches[0]
+	b.batches[0] = nil
+	b.batches = b.batches[1:]
+	return batch
+}
+
+// diskBatchStore stores column batches in a temporary on-disk map. Every batch
+// is added to one of several queues, each of which can be read back by a
+// diskQueueReader in the order in which its batches were added.
+type diskBatchStore struct {
+	ctx  context.Context
+	typs []types.T
+
+	diskMap diskmap.SortedDiskMap
+	writer  diskmap.SortedDiskMapBatchWriter
+	// diskAcc keeps track of disk usage.
+	diskAcc mon.BoundAccount
+	// dirty is set when batches were added since the writer was last flushed.
+	dirty bool
+
+	// seq is the sequence number of the next batch added to the store. It is
+	// encoded in the key of every batch after the index of the queue, so that
+	// the batches of a queue are iterated over in the order in which they were
+	// added.
+	seq        uint64
+	scratchKey []byte
+	scratchVal []byte
+
+	// readers contains the readers that were created for this store, so that
+	// their iterators can be closed along with the store.
+	readers []*diskQueueReader
+}
+
+func newDiskBatchStore(
+	ctx context.Context,
+	typs []types.T,
+	diskFactory diskmap.Factory,
+	diskMonitor *mon.BytesMonitor,
+) *diskBatchStore {
+	diskMap := diskFactory.NewSortedDiskMap()
+	return &diskBatchStore{
+		ctx:     ctx,
+		typs:    typs,
+		diskMap: diskMap,
+		writer:  diskMap.NewBatchWriter(),
+		diskAcc: diskMonitor.MakeBoundAccount(),
+	}
+}
+
+// add appends the selected tuples of batch to the queue with the given index.
+func (s *diskBatchStore) add(queueIdx int, batch ColBatch) error {
+	if batch.Length() == 0 {
+		return nil
+	}
+	s.scratchKey = encoding.EncodeUvarintAscending(s.scratchKey[:0], uint64(queueIdx))
+	s.scratchKey = encoding.EncodeUvarintAscending(s.scratchKey, s.seq)
+	var err error
+	if s.scratchVal, err = encodeBatch(s.scratchVal[:0], batch, s.typs); err != nil {
+		return err
+	}
+	if err := s.diskAcc.Grow(s.ctx, int64(len(s.scratchKey)+len(s.scratchVal))); err != nil {
+		return err
+	}
+	if err := s.writer.Put(s.scratchKey, s.scratchVal); err != nil {
+		return err
+	}
+	s.seq++
+	s.dirty = true
+	return nil
+}
+
+// newQueueReader returns an Operator that returns the batches that were added
+// to the queue with the given index. No more batches may be added to the queue
+// once it is being read.
+func (s *diskBatchStore) newQueueReader(queueIdx int) (*diskQueueReader, error) {
+	if s.dirty {
+		if err := s.writer.Flush(); err != nil {
+			return nil, err
+		}
+		s.dirty = false
+	}
+	r := &diskQueueReader{
+		store:  s,
+		prefix: encoding.EncodeUvarintAscending(nil, uint64(queueIdx)),
+	}
+	s.readers = append(s.readers, r)
+	return r, nil
+}
+
+// close releases the on-disk map along with the resources held by the readers
+// of the store. It is safe to call close multiple times.
+func (s *diskBatchStore) close() {
+	if s.diskMap == nil {
+		return
+	}
+	for _, r := range s.readers {
+		r.close()
+	}
+	// Nothing is going to be read after the writer is closed, so its error can
+	// be safely ignored.
+	_ = s.writer.Close(s.ctx)
+	s.diskMap.Close(s.ctx)
+	s.diskAcc.Close(s.ctx)
+	s.diskMap = nil
+}
+
+// diskQueueReader is an Operator that returns the batches of one of the queues
+// of a diskBatchStore.
+type diskQueueReader struct {
+	store  *diskBatchStore
+	prefix []byte
+
+	iter  diskmap.SortedDiskMapIterator
+	batch ColBatch
+	done  bool
+}
+
+var _ Operator = &diskQueueReader{}
+
+func (r *diskQueueReader) Init() {
+	r.batch = NewMemBatch(r.store.typs)
+	r.iter = r.store.diskMap.NewIterator()
+	r.iter.Seek(r.prefix)
+}
+
+func (r *diskQueueReader) Next() ColBatch {
+	if r.done {
+		r.batch.SetLength(0)
+		return r.batch
+	}
+	ok, err := r.iter.Valid()
+	if err != nil {
+		raiseError(err)
+	}
+	// The encoding of the queue index is prefix-free, so all of the keys that
+	// have the prefix belong to this reader's queue.
+	if !ok || !bytes.HasPrefix(r.iter.UnsafeKey(), r.prefix) {
+		r.done = true
+		r.close()
+		r.batch.SetLength(0)
+		return r.batch
+	}
+	// Value returns a copy of the encoded batch, which can be referenced by the
+	// decoded values.
+	if err := decodeBatch(r.iter.Value(), r.batch, r.store.typs); err != nil {
+		raiseError(err)
+	}
+	r.iter.Next()
+	return r.batch
+}
+
+// close releases the iterator of the reader.
+func (r *diskQueueReader) close() {
+	if r.iter != nil {
+		r.iter.Close()
+		r.iter = nil
+	}
+}
@@ -16,9 +16,12 @@ package exec
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 )
 
 // column is an interface that represents a raw array of a Go native type.
@@ -49,6 +52,12 @@ type ColVec interface {
 	// TODO(jordan): should this be [][]byte?
 	// Decimal returns an apd.Decimal slice.
 	Decimal() []apd.Decimal
+	// Timestamp returns a time.Time slice.
+	Timestamp() []time.Time
+	// Interval returns a duration.Duration slice.
+	Interval() []duration.Duration
+	// JSON returns a json.JSON slice.
+	JSON() []json.JSON
 
 	// Col returns the raw, typeless backing storage for this ColVec.
 	Col() interface{}
@@ -65,7 +74,17 @@ type ColVec interface {
 	// maximum size of ColBatchSize, filtered by the given selection vector.
 	AppendWithSel(vec ColVec, sel []uint16, batchSize uint16, colType types.T, toLength uint64)
 
-	// Copy copies src[srcStartIdx:srcEndIdx] into this ColVec.
+	// AppendSlice appends src[srcStartIdx:srcEndIdx] to this ColVec starting at
+	// destStartIdx, truncating anything that was previously stored at or past
+	// destStartIdx. If sel is non-nil, the source indices are first looked up in
+	// the selection vector. Unlike Append, the null bitmap of the appended range
+	// is overwritten, so this ColVec can be reused as a growable buffer.
+	AppendSlice(
+		src ColVec, colType types.T, destStartIdx uint64, srcStartIdx, srcEndIdx uint16, sel []uint16,
+	)
+
+	// Copy copies src[srcStartIdx:srcEndIdx] into this ColVec, along with the
+	// corresponding null values.
 	Copy(src ColVec, srcStartIdx, srcEndIdx uint64, typ types.T)
 
 	// CopyWithSelInt64 copies vec, filtered by sel, into this ColVec. It replaces
@@ -94,12 +113,18 @@ type Nulls interface {
 	NullAt(i uint16) bool
 	// SetNull takes in a uint16 and sets the ith value of the column to null.
 	SetNull(i uint16)
+	// UnsetNull takes in a uint16 and sets the ith value of the column to
+	// non-null.
+	UnsetNull(i uint16)
 
 	// NullAt64 takes in a uint64 and returns true if the ith value of the column
 	// is null.
 	NullAt64(i uint64) bool
 	// SetNull64 takes in a uint64 and sets the ith value of the column to null.
 	SetNull64(i uint64)
+	// UnsetNull64 takes in a uint64 and sets the ith value of the column to
+	// non-null.
+	UnsetNull64(i uint64)
 
 	// UnsetNulls sets the column to have 0 null values.
 	UnsetNulls()
@@ -162,6 +187,12 @@ func newMemColumn(t types.T, n int) *memColumn {
 		return &memColumn{col: make([]float64, n), nulls: nulls}
 	case types.Decimal:
 		return &memColumn{col: make([]apd.Decimal, n), nulls: nulls}
+	case types.Timestamp:
+		return &memColumn{col: make([]time.Time, n), nulls: nulls}
+	case types.Interval:
+		return &memColumn{col: make([]duration.Duration, n), nulls: nulls}
+	case types.JSON:
+		return &memColumn{col: make([]json.JSON, n), nulls: nulls}
 	default:
 		panic(fmt.Sprintf("unhandled type %s", t))
 	}
@@ -179,6 +210,10 @@ func (m *memColumn) SetNull(i uint16) {
 	m.SetNull64(uint64(i))
 }
 
+func (m *memColumn) UnsetNull(i uint16) {
+	m.UnsetNull64(uint64(i))
+}
+
 func (m *memColumn) UnsetNulls() {
 	m.hasNulls = false
 
@@ -208,6 +243,22 @@ func (m *memColumn) SetNull64(i uint64) {
 	m.nulls[intIdx] |= 1 << (i % 64)
 }
 
+func (m *memColumn) UnsetNull64(i uint64) {
+	intIdx := i >> 6
+	m.nulls[intIdx] &^= 1 << (i % 64)
+}
+
+// ensureNullsCapacity grows the null bitmap so that it can hold at least n
+// values.
+func (m *memColumn) ensureNullsCapacity(n uint64) {
+	if n == 0 {
+		return
+	}
+	if need := int((n-1)>>6 + 1); len(m.nulls) < need {
+		m.nulls = append(m.nulls, make([]int64, need-len(m.nulls))...)
+	}
+}
+
 func (m *memColumn) Bool() []bool {
 	return m.col.([]bool)
 }
@@ -244,6 +295,18 @@ func (m *memColumn) Decimal() []apd.Decimal {
 	return m.col.([]apd.Decimal)
 }
 
+func (m *memColumn) Timestamp() []time.Time {
+	return m.col.([]time.Time)
+}
+
+func (m *memColumn) Interval() []duration.Duration {
+	return m.col.([]duration.Duration)
+}
+
+func (m *memColumn) JSON() []json.JSON {
+	return m.col.([]json.JSON)
+}
+
 func (m *memColumn) Col() interface{} {
 	return m.col
 }
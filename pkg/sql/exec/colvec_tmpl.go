@@ -25,9 +25,12 @@ package exec
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 )
 
 // {{/*
@@ -35,6 +38,15 @@ import (
 // Dummy import to pull in "apd" package.
 var _ apd.Decimal
 
+// Dummy import to pull in "time" package.
+var _ time.Time
+
+// Dummy import to pull in "duration" package.
+var _ duration.Duration
+
+// Dummy import to pull in "json" package.
+var _ json.JSON
+
 // _TYPES_T is the template type variable for types.T. It will be replaced by
 // types.Foo for each type Foo in the types.T type.
 const _TYPES_T = types.Unhandled
@@ -93,6 +105,45 @@ func (m *memColumn) AppendWithSel(
 	}
 }
 
+func (m *memColumn) AppendSlice(
+	src ColVec, colType types.T, destStartIdx uint64, srcStartIdx, srcEndIdx uint16, sel []uint16,
+) {
+	n := uint64(srcEndIdx - srcStartIdx)
+	switch colType {
+	// {{range .}}
+	case _TYPES_T:
+		fromCol := src._TemplateType()
+		if sel != nil {
+			toCol := append(m._TemplateType()[:destStartIdx], make([]_GOTYPE, n)...)
+			for i, j := range sel[srcStartIdx:srcEndIdx] {
+				toCol[destStartIdx+uint64(i)] = fromCol[j]
+			}
+			m.col = toCol
+		} else {
+			m.col = append(m._TemplateType()[:destStartIdx], fromCol[srcStartIdx:srcEndIdx]...)
+		}
+		// {{end}}
+	default:
+		panic(fmt.Sprintf("unhandled type %d", colType))
+	}
+
+	m.ensureNullsCapacity(destStartIdx + n)
+	if !src.HasNulls() && !m.hasNulls {
+		return
+	}
+	for i := uint64(0); i < n; i++ {
+		srcIdx := srcStartIdx + uint16(i)
+		if sel != nil {
+			srcIdx = sel[srcIdx]
+		}
+		if src.NullAt(srcIdx) {
+			m.SetNull64(destStartIdx + i)
+		} else {
+			m.UnsetNull64(destStartIdx + i)
+		}
+	}
+}
+
 func (m *memColumn) Copy(src ColVec, srcStartIdx, srcEndIdx uint64, typ types.T) {
 	switch typ {
 	// {{range .}}
@@ -102,6 +153,19 @@ func (m *memColumn) Copy(src ColVec, srcStartIdx, srcEndIdx uint64, typ types.T)
 	default:
 		panic(fmt.Sprintf("unhandled type %d", typ))
 	}
+
+	if !src.HasNulls() && !m.hasNulls {
+		return
+	}
+	// Note that src may be this ColVec, in which case the copy is always from a
+	// later index to an earlier one, so iterating forwards is safe.
+	for i := srcStartIdx; i < srcEndIdx; i++ {
+		if src.NullAt64(i) {
+			m.SetNull64(i - srcStartIdx)
+		} else {
+			m.UnsetNull64(i - srcStartIdx)
+		}
+	}
 }
 
 func (m *memColumn) CopyWithSelInt64(vec ColVec, sel []uint64, nSel uint16, colType types.T) {
@@ -18,7 +18,9 @@ func newCountAgg() *countAgg {
 	return &countAgg{}
 }
 
-// countAgg is the COUNT(*) aggregate - it takes no arguments.
+// countAgg counts the rows of each group. With no arguments it implements
+// COUNT(*); with a single argument it implements COUNT(col), which ignores the
+// rows where the argument is NULL.
 type countAgg struct {
 	groups []bool
 	vec    []int64
@@ -48,7 +50,7 @@ func (a *countAgg) SetOutputIndex(idx int) {
 	}
 }
 
-func (a *countAgg) Compute(b ColBatch, _ []uint32) {
+func (a *countAgg) Compute(b ColBatch, inputIdxs []uint32) {
 	if a.done {
 		return
 	}
@@ -59,6 +61,12 @@ func (a *countAgg) Compute(b ColBatch, _ []uint32) {
 		return
 	}
 	sel := b.Selection()
+	if len(inputIdxs) > 0 {
+		if vec := b.ColVec(int(inputIdxs[0])); vec.HasNulls() {
+			a.computeWithNulls(vec, sel, inputLen)
+			return
+		}
+	}
 	if sel != nil {
 		sel = sel[:inputLen]
 		for _, i := range sel {
@@ -80,3 +88,32 @@ func (a *countAgg) Compute(b ColBatch, _ []uint32) {
 		}
 	}
 }
+
+// computeWithNulls is like Compute, but only counts the rows for which the
+// given argument is not NULL.
+func (a *countAgg) computeWithNulls(nulls Nulls, sel []uint16, inputLen uint16) {
+	if sel != nil {
+		sel = sel[:inputLen]
+		for _, i := range sel {
+			x := 0
+			if a.groups[i] {
+				x = 1
+			}
+			a.curIdx += x
+			if !nulls.NullAt(i) {
+				a.vec[a.curIdx]++
+			}
+		}
+	} else {
+		for i := uint16(0); i < inputLen; i++ {
+			x := 0
+			if a.groups[i] {
+				x = 1
+			}
+			a.curIdx += x
+			if !nulls.NullAt(i) {
+				a.vec[a.curIdx]++
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+// runtimeError wraps an error encountered by an operator during execution.
+// Since Operator.Next can't return errors, such errors are propagated by
+// panicking with a runtimeError, which is then recovered by CatchRuntimeError.
+type runtimeError struct {
+	error
+}
+
+// raiseError propagates err to the nearest CatchRuntimeError up the stack.
+func raiseError(err error) {
+	panic(runtimeError{error: err})
+}
+
+// CatchRuntimeError executes f, returning any error that was raised by an
+// operator during its execution. Panics that weren't raised by an operator
+// aren't recovered.
+func CatchRuntimeError(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			re, ok := r.(runtimeError)
+			if !ok {
+				panic(r)
+			}
+			err = re.error
+		}
+	}()
+	f()
+	return nil
+}
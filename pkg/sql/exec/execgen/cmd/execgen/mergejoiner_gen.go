@@ -0,0 +1,70 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// mergeJoinOverload pairs the equality overload of a type with its less than
+// overload, which are both needed to compare the equality columns of the two
+// inputs of a merge join.
+type mergeJoinOverload struct {
+	*overload
+	Lt *overload
+}
+
+func genMergeJoinOps(wr io.Writer) error {
+	d, err := ioutil.ReadFile("pkg/sql/exec/mergejoiner_tmpl.go")
+	if err != nil {
+		return err
+	}
+
+	s := string(d)
+
+	// Replace the template variables.
+	s = strings.Replace(s, "_TYPES_T", "types.{{.LTyp}}", -1)
+	s = strings.Replace(s, "_TemplateType", "{{.LTyp}}", -1)
+
+	assignEqRe := makeFunctionRegex("_ASSIGN_EQ", 3)
+	s = assignEqRe.ReplaceAllString(s, "{{.Assign $1 $2 $3}}")
+	assignLtRe := makeFunctionRegex("_ASSIGN_LT", 3)
+	s = assignLtRe.ReplaceAllString(s, "{{.Lt.Assign $1 $2 $3}}")
+
+	// Now, generate the op, from the template.
+	tmpl, err := template.New("mergejoiner_op").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	overloads := intersectOverloads(
+		comparisonOpToOverloads[tree.EQ], comparisonOpToOverloads[tree.LT],
+	)
+	eqOverloads, ltOverloads := overloads[0], overloads[1]
+	data := make([]mergeJoinOverload, len(eqOverloads))
+	for i := range eqOverloads {
+		data[i] = mergeJoinOverload{overload: eqOverloads[i], Lt: ltOverloads[i]}
+	}
+	return tmpl.Execute(wr, data)
+}
+
+func init() {
+	registerGenerator(genMergeJoinOps, "mergejoiner.eg.go")
+}
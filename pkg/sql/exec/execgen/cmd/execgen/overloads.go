@@ -141,7 +141,7 @@ func init() {
 		for _, op := range binOps {
 			// Skip types that don't have associated binary ops.
 			switch t {
-			case types.Bytes, types.Bool:
+			case types.Bytes, types.Bool, types.Timestamp, types.Interval, types.JSON:
 				continue
 			}
 			ov := &overload{
@@ -250,6 +250,19 @@ type decimalCustomizer struct{}
 type float32Customizer struct{}
 type float64Customizer struct{}
 
+// timestampCustomizer is necessary since time.Time doesn't have infix operators
+// and has to be compared with its Equal, Before and After methods.
+type timestampCustomizer struct{}
+
+// intervalCustomizer is necessary since duration.Duration values with
+// different months, days and nanos fields can be equal, so they have to be
+// compared (and hashed) in their normalized form.
+type intervalCustomizer struct{}
+
+// jsonCustomizer is necessary since json.JSON is an interface that can only be
+// compared via its Compare method.
+type jsonCustomizer struct{}
+
 func (boolCustomizer) getCmpOpAssignFunc() assignFunc {
 	return func(op overload, target, l, r string) string {
 		switch op.CmpOp {
@@ -336,6 +349,80 @@ func (float64Customizer) getHashAssignFunc() assignFunc {
 	}
 }
 
+func (timestampCustomizer) getCmpOpAssignFunc() assignFunc {
+	return func(op overload, target, l, r string) string {
+		switch op.CmpOp {
+		case tree.EQ:
+			return fmt.Sprintf("%s = %s.Equal(%s)", target, l, r)
+		case tree.NE:
+			return fmt.Sprintf("%s = !%s.Equal(%s)", target, l, r)
+		case tree.LT:
+			return fmt.Sprintf("%s = %s.Before(%s)", target, l, r)
+		case tree.LE:
+			return fmt.Sprintf("%s = !%s.After(%s)", target, l, r)
+		case tree.GT:
+			return fmt.Sprintf("%s = %s.After(%s)", target, l, r)
+		case tree.GE:
+			return fmt.Sprintf("%s = !%s.Before(%s)", target, l, r)
+		}
+		panic(fmt.Sprintf("unhandled comparison operator %s", op.CmpOp))
+	}
+}
+
+func (timestampCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		return fmt.Sprintf("%s = uint64(%s.UnixNano())", target, v)
+	}
+}
+
+func (intervalCustomizer) getCmpOpAssignFunc() assignFunc {
+	return func(op overload, target, l, r string) string {
+		return fmt.Sprintf("%s = %s.Compare(%s) %s 0", target, l, r, op.OpStr)
+	}
+}
+
+func (intervalCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		// Months are treated as 30 days and days as 24 hours, just like the
+		// normalization done by Compare, so that equal intervals hash equally.
+		return fmt.Sprintf(
+			"%[1]s = uint64((%[2]s.Months*30+%[2]s.Days)*86400000000000 + %[2]s.Nanos)",
+			target, v,
+		)
+	}
+}
+
+func (jsonCustomizer) getCmpOpAssignFunc() assignFunc {
+	return func(op overload, target, l, r string) string {
+		return fmt.Sprintf(`
+			{
+				cmpResult, err := %[2]s.Compare(%[3]s)
+				if err != nil {
+					raiseError(err)
+				}
+				%[1]s = cmpResult %[4]s 0
+			}
+		`, target, l, r, op.OpStr)
+	}
+}
+
+func (jsonCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		// JSON values with different encodings can be equal (for example, the
+		// numbers 1 and 1.0), so the hash is computed by json.Hash, which is
+		// consistent with Compare.
+		return fmt.Sprintf(`
+			{
+				h, err := json.Hash(%[2]s)
+				if err != nil {
+					raiseError(err)
+				}
+				%[1]s = h
+			}
+		`, target, v)
+	}
+}
+
 func registerTypeCustomizers() {
 	typeCustomizers = make(map[types.T]typeCustomizer)
 	registerTypeCustomizer(types.Bool, boolCustomizer{})
@@ -343,6 +430,9 @@ func registerTypeCustomizers() {
 	registerTypeCustomizer(types.Decimal, decimalCustomizer{})
 	registerTypeCustomizer(types.Float32, float32Customizer{})
 	registerTypeCustomizer(types.Float64, float64Customizer{})
+	registerTypeCustomizer(types.Timestamp, timestampCustomizer{})
+	registerTypeCustomizer(types.Interval, intervalCustomizer{})
+	registerTypeCustomizer(types.JSON, jsonCustomizer{})
 }
 
 // Avoid unused warning for Assign, which is only used in templates.
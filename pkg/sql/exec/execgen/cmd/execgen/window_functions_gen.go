@@ -0,0 +1,89 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/template"
+)
+
+type rowNumberTmplInfo struct {
+	HasPartition bool
+	String       string
+}
+
+func genRowNumberOp(wr io.Writer) error {
+	d, err := ioutil.ReadFile("pkg/sql/exec/row_number_tmpl.go")
+	if err != nil {
+		return err
+	}
+
+	s := string(d)
+	s = strings.Replace(s, "_ROW_NUMBER_STRING", "{{.String}}", -1)
+
+	// Now, generate the op, from the template.
+	tmpl, err := template.New("row_number_op").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	rowNumberTmplInfos := []rowNumberTmplInfo{
+		{HasPartition: false, String: "rowNumberNoPartition"},
+		{HasPartition: true, String: "rowNumberWithPartition"},
+	}
+	return tmpl.Execute(wr, rowNumberTmplInfos)
+}
+
+type rankTmplInfo struct {
+	IsDense      bool
+	HasPartition bool
+	String       string
+	Name         string
+}
+
+func genRankOps(wr io.Writer) error {
+	d, err := ioutil.ReadFile("pkg/sql/exec/rank_tmpl.go")
+	if err != nil {
+		return err
+	}
+
+	s := string(d)
+	s = strings.Replace(s, "_RANK_STRING", "{{.String}}", -1)
+	s = strings.Replace(s, "_RANK_NAME", "{{.Name}}", -1)
+
+	computeRankRe := makeFunctionRegex("_COMPUTE_RANK", 3)
+	s = computeRankRe.ReplaceAllString(s, `{{template "computeRank" .}}`)
+
+	// Now, generate the op, from the template.
+	tmpl, err := template.New("rank_op").Parse(s)
+	if err != nil {
+		return err
+	}
+
+	rankTmplInfos := []rankTmplInfo{
+		{IsDense: false, HasPartition: false, String: "rankNoPartition", Name: "rank"},
+		{IsDense: false, HasPartition: true, String: "rankWithPartition", Name: "rank"},
+		{IsDense: true, HasPartition: false, String: "denseRankNoPartition", Name: "dense_rank"},
+		{IsDense: true, HasPartition: true, String: "denseRankWithPartition", Name: "dense_rank"},
+	}
+	return tmpl.Execute(wr, rankTmplInfos)
+}
+
+func init() {
+	registerGenerator(genRowNumberOp, "row_number.eg.go")
+	registerGenerator(genRankOps, "rank.eg.go")
+}
@@ -0,0 +1,359 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+const (
+	// externalHashJoinerPartitionBits is the number of bits of the hash of the
+	// equality columns that are used to pick the partition of a tuple.
+	externalHashJoinerPartitionBits = 4
+	// externalHashJoinerNumPartitions is the number of partitions that both
+	// sides of the join are split into when the build side doesn't fit in
+	// memory.
+	externalHashJoinerNumPartitions = 1 << externalHashJoinerPartitionBits
+	// externalHashJoinerMaxDepth is the maximum number of times that the inputs
+	// are partitioned. Partitions whose build side doesn't fit in memory are
+	// themselves partitioned, unless all of their tuples have the same equality
+	// columns, in which case partitioning further doesn't help.
+	externalHashJoinerMaxDepth = 4
+	// externalHashJoinerTupleOverhead is the number of bytes needed by the hash
+	// table for every build tuple on top of the tuple itself.
+	externalHashJoinerTupleOverhead = 2*sizeOfUint64 + 2
+	// hashTableOverhead is the number of bytes needed by the hash table
+	// regardless of the number of build tuples.
+	hashTableOverhead = hashTableBucketSize * sizeOfUint64
+)
+
+// NewExternalEqHashJoinerOp creates a new equality hash join operator with the
+// same arguments as NewEqHashJoinerOp. Unlike the operator returned by
+// NewEqHashJoinerOp, it accounts for the memory used to buffer the build side
+// against memAcc. If the build side exceeds the memory budget, both sides are
+// partitioned on disk on the hash of their equality columns, and the pairs of
+// partitions are joined one at a time (a grace hash join). If diskFactory is
+// nil, exceeding the memory budget is an error.
+func NewExternalEqHashJoinerOp(
+	ctx context.Context,
+	leftSource Operator,
+	rightSource Operator,
+	leftEqCols []uint32,
+	rightEqCols []uint32,
+	leftOutCols []uint32,
+	rightOutCols []uint32,
+	leftTypes []types.T,
+	rightTypes []types.T,
+	buildRightSide bool,
+	buildDistinct bool,
+	joinType sqlbase.JoinType,
+	memAcc *mon.BoundAccount,
+	diskFactory diskmap.Factory,
+	diskMonitor *mon.BytesMonitor,
+) (Operator, error) {
+	op, err := NewEqHashJoinerOp(
+		leftSource, rightSource, leftEqCols, rightEqCols, leftOutCols, rightOutCols,
+		leftTypes, rightTypes, buildRightSide, buildDistinct, joinType,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &externalHashJoiner{
+		ctx:         ctx,
+		spec:        op.(*hashJoinEqOp).spec,
+		memAcc:      memAcc,
+		diskFactory: diskFactory,
+		diskMonitor: diskMonitor,
+	}, nil
+}
+
+// externalHashJoinerState represents the state of the external hash join
+// operator.
+type externalHashJoinerState int
+
+const (
+	// ehjBuffering is the initial state of the operator, where it buffers the
+	// build side of the join in memory.
+	ehjBuffering externalHashJoinerState = iota
+	// ehjJoiningInMemory indicates that the build side fit in memory and that the
+	// join is performed by an in-memory hash joiner.
+	ehjJoiningInMemory
+	// ehjJoiningPartitions indicates that both sides were partitioned on disk
+	// and that the pairs of partitions are being joined one at a time.
+	ehjJoiningPartitions
+)
+
+type externalHashJoiner struct {
+	ctx  context.Context
+	spec hashJoinerSpec
+
+	memAcc      *mon.BoundAccount
+	diskFactory diskmap.Factory
+	diskMonitor *mon.BytesMonitor
+	// depth is the number of times that the inputs of this operator were
+	// partitioned.
+	depth int
+
+	state externalHashJoinerState
+	// buffer contains the build side of the join while it is being buffered.
+	buffer      *bufferedBatches
+	inMemJoiner Operator
+
+	// buildPartitions and probePartitions contain the partitions of the build
+	// and probe sides of the join, each in a queue of its own.
+	buildPartitions *diskBatchStore
+	probePartitions *diskBatchStore
+	// partitionIdx is the index of the pair of partitions that partitionJoiner
+	// is joining.
+	partitionIdx    int
+	partitionJoiner *externalHashJoiner
+
+	// hasher is only used to hash the equality columns of the inputs.
+	hasher  hashTable
+	buckets []uint64
+	// partitionSels contains, for every partition, the selection vector of the
+	// tuples of the batch being partitioned that belong to it.
+	partitionSels  [externalHashJoinerNumPartitions][]uint16
+	partitionBatch memBatch
+
+	zeroBatch ColBatch
+}
+
+var _ Operator = &externalHashJoiner{}
+
+// buildAndProbeSides returns the specifications of the build and probe sides
+// of spec.
+func buildAndProbeSides(spec *hashJoinerSpec) (build, probe *hashJoinerSourceSpec) {
+	if spec.buildRightSide {
+		return &spec.right, &spec.left
+	}
+	return &spec.left, &spec.right
+}
+
+func (s *externalHashJoiner) Init() {
+	s.spec.left.source.Init()
+	s.spec.right.source.Init()
+
+	build, _ := buildAndProbeSides(&s.spec)
+	s.buffer = newBufferedBatches(build.sourceTypes)
+	s.buckets = make([]uint64, ColBatchSize)
+	for i := range s.partitionSels {
+		s.partitionSels[i] = make([]uint16, 0, ColBatchSize)
+	}
+	outputTypes := make([]types.T, 0, len(s.spec.left.sourceTypes)+len(s.spec.right.sourceTypes))
+	outputTypes = append(outputTypes, s.spec.left.sourceTypes...)
+	outputTypes = append(outputTypes, s.spec.right.sourceTypes...)
+	s.zeroBatch = NewMemBatchWithSize(outputTypes, 0)
+	s.state = ehjBuffering
+}
+
+func (s *externalHashJoiner) Next() ColBatch {
+	switch s.state {
+	case ehjBuffering:
+		if overflow := s.bufferBuildSide(); overflow == nil {
+			s.initInMemJoiner()
+			s.state = ehjJoiningInMemory
+		} else {
+			s.partitionInputs(overflow)
+			s.state = ehjJoiningPartitions
+		}
+		return s.Next()
+	case ehjJoiningInMemory:
+		return s.inMemJoiner.Next()
+	case ehjJoiningPartitions:
+		for s.partitionIdx < externalHashJoinerNumPartitions {
+			if s.partitionJoiner == nil {
+				s.partitionJoiner = s.newPartitionJoiner(s.partitionIdx)
+				s.partitionJoiner.Init()
+			}
+			if batch := s.partitionJoiner.Next(); batch.Length() != 0 {
+				return batch
+			}
+			s.partitionJoiner.Close()
+			s.partitionJoiner = nil
+			s.memAcc.Clear(s.ctx)
+			s.partitionIdx++
+		}
+		s.Close()
+		return s.zeroBatch
+	}
+	panic(fmt.Sprintf("invalid external hash joiner state %v", s.state))
+}
+
+// Close releases the temporary disk storage used by the operator.
+func (s *externalHashJoiner) Close() {
+	if s.partitionJoiner != nil {
+		s.partitionJoiner.Close()
+	}
+	if s.buildPartitions != nil {
+		s.buildPartitions.close()
+		s.probePartitions.close()
+	}
+}
+
+// grow accounts for size more bytes of memory. It returns false if they don't
+// fit in the memory budget and the inputs can be partitioned.
+func (s *externalHashJoiner) grow(size int64) bool {
+	err := s.memAcc.Grow(s.ctx, size)
+	if err == nil {
+		return true
+	}
+	if !isOutOfMemoryError(err) || s.diskFactory == nil || s.depth == externalHashJoinerMaxDepth {
+		raiseError(err)
+	}
+	return false
+}
+
+// bufferBuildSide buffers the build side of the join. If the build side
+// doesn't fit in the memory budget, it returns the batch that exceeded the
+// budget, which hasn't been buffered, and leaves the rest of the build side
+// unconsumed.
+func (s *externalHashJoiner) bufferBuildSide() (overflow ColBatch) {
+	build, _ := buildAndProbeSides(&s.spec)
+	if !s.grow(hashTableOverhead) {
+		return s.zeroBatch
+	}
+	for batch := build.source.Next(); batch.Length() != 0; batch = build.source.Next() {
+		size := estimateBatchSizeBytes(batch, build.sourceTypes) +
+			int64(batch.Length())*externalHashJoinerTupleOverhead
+		if !s.grow(size) {
+			return batch
+		}
+		s.buffer.add(batch)
+	}
+	return nil
+}
+
+// initInMemJoiner sets up the in-memory hash joiner that joins the buffered
+// build side with the probe side.
+func (s *externalHashJoiner) initInMemJoiner() {
+	spec := s.spec
+	build, probe := buildAndProbeSides(&spec)
+	build.source = s.buffer
+	// The probe side was initialized already.
+	probe.source = &noopOperator{input: probe.source}
+	s.inMemJoiner = &hashJoinEqOp{spec: spec}
+	s.inMemJoiner.Init()
+}
+
+// partitionInputs partitions both sides of the join on disk, starting with the
+// buffered part of the build side and the batch that overflowed the buffer.
+func (s *externalHashJoiner) partitionInputs(overflow ColBatch) {
+	build, probe := buildAndProbeSides(&s.spec)
+	s.buildPartitions = newDiskBatchStore(s.ctx, build.sourceTypes, s.diskFactory, s.diskMonitor)
+	s.probePartitions = newDiskBatchStore(s.ctx, probe.sourceTypes, s.diskFactory, s.diskMonitor)
+
+	for batch := s.buffer.Next(); batch.Length() != 0; batch = s.buffer.Next() {
+		s.partition(s.buildPartitions, build, batch)
+	}
+	s.memAcc.Clear(s.ctx)
+	s.partition(s.buildPartitions, build, overflow)
+	for batch := build.source.Next(); batch.Length() != 0; batch = build.source.Next() {
+		s.partition(s.buildPartitions, build, batch)
+	}
+	for batch := probe.source.Next(); batch.Length() != 0; batch = probe.source.Next() {
+		s.partition(s.probePartitions, probe, batch)
+	}
+}
+
+// partition adds each of the selected tuples of batch, which comes from the
+// given side of the join, to the queue of store of the partition that the
+// hash of its equality columns maps to.
+func (s *externalHashJoiner) partition(
+	store *diskBatchStore, side *hashJoinerSourceSpec, batch ColBatch,
+) {
+	n := batch.Length()
+	if n == 0 {
+		return
+	}
+	sel := batch.Selection()
+	buckets := s.buckets[:n]
+	s.hasher.initHash(buckets, uint64(n))
+	for i, colIdx := range side.eqCols {
+		t := side.sourceTypes[colIdx]
+		s.hasher.rehash(buckets, i, t, batch.ColVec(int(colIdx)), uint64(n), sel)
+	}
+
+	for i := range s.partitionSels {
+		s.partitionSels[i] = s.partitionSels[i][:0]
+	}
+	for i := uint16(0); i < n; i++ {
+		idx := i
+		if sel != nil {
+			idx = sel[i]
+		}
+		p := hashPartition(buckets[i], s.depth)
+		s.partitionSels[p] = append(s.partitionSels[p], idx)
+	}
+
+	s.partitionBatch.b = batch.ColVecs()
+	s.partitionBatch.useSel = true
+	for p, partitionSel := range s.partitionSels {
+		if len(partitionSel) == 0 {
+			continue
+		}
+		s.partitionBatch.sel = partitionSel
+		s.partitionBatch.n = uint16(len(partitionSel))
+		if err := store.add(p, &s.partitionBatch); err != nil {
+			raiseError(err)
+		}
+	}
+}
+
+// hashPartition returns the partition of a tuple whose equality columns have the
+// given hash, at the given partitioning depth. The hash is mixed first, since
+// the in-memory hash table picks buckets based on its low bits. Otherwise, the
+// tuples of a partition would only use a fraction of the buckets of the hash
+// table that joins them.
+func hashPartition(hash uint64, depth int) int {
+	// This is the finalizer of MurmurHash3.
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	hash >>= uint(depth * externalHashJoinerPartitionBits)
+	return int(hash & (externalHashJoinerNumPartitions - 1))
+}
+
+// newPartitionJoiner returns an operator that joins the given pair of
+// partitions, partitioning them further if need be.
+func (s *externalHashJoiner) newPartitionJoiner(partitionIdx int) *externalHashJoiner {
+	buildReader, err := s.buildPartitions.newQueueReader(partitionIdx)
+	if err != nil {
+		raiseError(err)
+	}
+	probeReader, err := s.probePartitions.newQueueReader(partitionIdx)
+	if err != nil {
+		raiseError(err)
+	}
+	spec := s.spec
+	build, probe := buildAndProbeSides(&spec)
+	build.source, probe.source = buildReader, probeReader
+	return &externalHashJoiner{
+		ctx:         s.ctx,
+		spec:        spec,
+		memAcc:      s.memAcc,
+		diskFactory: s.diskFactory,
+		diskMonitor: s.diskMonitor,
+		depth:       s.depth + 1,
+	}
+}
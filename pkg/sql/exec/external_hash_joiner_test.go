@@ -0,0 +1,163 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+// nestedLoopJoin returns the result of joining left and right on the equality
+// of their first columns, computed in the simplest way possible.
+func nestedLoopJoin(left, right tuples, joinType sqlbase.JoinType) tuples {
+	var result tuples
+	rightMatched := make([]bool, len(right))
+	for _, l := range left {
+		matched := false
+		for j, r := range right {
+			if l[0] != nil && r[0] != nil && l[0].(int64) == r[0].(int64) {
+				result = append(result, tuple{l[0], l[1], r[0], r[1]})
+				matched, rightMatched[j] = true, true
+			}
+		}
+		if !matched && (joinType == sqlbase.JoinType_LEFT_OUTER ||
+			joinType == sqlbase.JoinType_FULL_OUTER) {
+			result = append(result, tuple{l[0], l[1], nil, nil})
+		}
+	}
+	if joinType == sqlbase.JoinType_RIGHT_OUTER || joinType == sqlbase.JoinType_FULL_OUTER {
+		for j, r := range right {
+			if !rightMatched[j] {
+				result = append(result, tuple{nil, nil, r[0], r[1]})
+			}
+		}
+	}
+	return result
+}
+
+func TestExternalHashJoiner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewPseudoRand()
+	nTups := 1024
+	// The first column of the tuples is the join key, which is sometimes NULL,
+	// and the second one identifies the tuple. The keys of the build side are
+	// distinct, so that every probe tuple matches at most one build tuple.
+	buildTups, probeTups := make(tuples, nTups), make(tuples, nTups)
+	perm := rng.Perm(2 * nTups)
+	for i := 0; i < nTups; i++ {
+		var buildKey, probeKey interface{}
+		if rng.Intn(64) != 0 {
+			buildKey = int64(perm[i])
+		}
+		if rng.Intn(16) != 0 {
+			probeKey = int64(rng.Intn(2 * nTups))
+		}
+		buildTups[i] = tuple{buildKey, int64(i)}
+		probeTups[i] = tuple{probeKey, int64(i)}
+	}
+	typs := []types.T{types.Int64, types.Int64}
+
+	for _, jt := range []struct {
+		joinType       sqlbase.JoinType
+		buildRightSide bool
+	}{
+		{joinType: sqlbase.JoinType_INNER, buildRightSide: false},
+		{joinType: sqlbase.JoinType_INNER, buildRightSide: true},
+		{joinType: sqlbase.JoinType_LEFT_OUTER, buildRightSide: false},
+		{joinType: sqlbase.JoinType_RIGHT_OUTER, buildRightSide: true},
+	} {
+		joinType, buildRightSide := jt.joinType, jt.buildRightSide
+		left, right := buildTups, probeTups
+		if buildRightSide {
+			left, right = probeTups, buildTups
+		}
+		expected := nestedLoopJoin(left, right, joinType)
+		for _, tc := range []struct {
+			name     string
+			memLimit int64
+			spills   bool
+		}{
+			{name: "InMemory", memLimit: 0},
+			// The build side doesn't fit in memory, but each of its partitions
+			// does.
+			{name: "Partitioned", memLimit: hashTableOverhead + 8<<10, spills: true},
+			// The partitions of the build side need to be partitioned again.
+			{name: "Repartitioned", memLimit: hashTableOverhead + 1<<10, spills: true},
+		} {
+			name := fmt.Sprintf("%s/%s/buildRight=%t", joinType, tc.name, buildRightSide)
+			t.Run(name, func(t *testing.T) {
+				runTests(t, []tuples{left, right}, nil, func(t *testing.T, sources []Operator) {
+					storage := newTestExternalStorage(t, tc.memLimit)
+					defer storage.close()
+
+					hj, err := NewExternalEqHashJoinerOp(
+						context.Background(), sources[0], sources[1],
+						[]uint32{0}, []uint32{0}, []uint32{0, 1}, []uint32{0, 1},
+						typs, typs, buildRightSide, false /* buildDistinct */, joinType,
+						&storage.memAcc, storage.tempEngine, &storage.diskMonitor,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer hj.(Closer).Close()
+					out := newOpTestOutput(hj, []int{0, 1, 2, 3}, expected)
+					if err := out.VerifyAnyOrder(); err != nil {
+						t.Fatal(err)
+					}
+					if spilled := storage.diskMonitor.MaximumBytes() > 0; spilled != tc.spills {
+						t.Fatalf("expected spilled=%t, got %t", tc.spills, spilled)
+					}
+				})
+			})
+		}
+	}
+}
+
+func TestExternalHashJoinerMaxDepth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// All of the build tuples have the same key, so partitioning them never
+	// reduces the size of the build side.
+	tups := make(tuples, 1024)
+	for i := range tups {
+		tups[i] = tuple{int64(1), int64(i)}
+	}
+	storage := newTestExternalStorage(t, hashTableOverhead+1<<10)
+	defer storage.close()
+
+	typs := []types.T{types.Int64, types.Int64}
+	hj, err := NewExternalEqHashJoinerOp(
+		context.Background(), newOpTestInput(ColBatchSize, tups), newOpTestInput(ColBatchSize, tups),
+		[]uint32{0}, []uint32{0}, []uint32{0, 1}, []uint32{0, 1},
+		typs, typs, false /* buildRightSide */, false /* buildDistinct */, sqlbase.JoinType_INNER,
+		&storage.memAcc, storage.tempEngine, &storage.diskMonitor,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hj.(Closer).Close()
+	hj.Init()
+	err = CatchRuntimeError(func() { hj.Next() })
+	if !isOutOfMemoryError(err) {
+		t.Fatalf("expected an out of memory error, got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// externalSorterTupleOverhead is the number of bytes needed by the in-memory
+// sort for every tuple on top of the tuple itself, for its order and working
+// space vectors.
+const externalSorterTupleOverhead = 2 * sizeOfUint64
+
+// NewExternalSorter returns a new sort operator, which sorts its input on the
+// columns given in orderingCols. Unlike the operator returned by NewSorter, it
+// accounts for the memory used to buffer its input against memAcc. Whenever the
+// memory budget is exceeded, the buffered input is sorted and written to disk
+// as a sorted run, and the runs are merged once the input is exhausted. If
+// diskFactory is nil, exceeding the memory budget is an error.
+func NewExternalSorter(
+	ctx context.Context,
+	input Operator,
+	inputTypes []types.T,
+	orderingCols []distsqlpb.Ordering_Column,
+	memAcc *mon.BoundAccount,
+	diskFactory diskmap.Factory,
+	diskMonitor *mon.BytesMonitor,
+) (Operator, error) {
+	// Make sure that the in-memory sorter supports the ordering before any input
+	// is consumed.
+	if _, err := NewSorter(input, inputTypes, orderingCols); err != nil {
+		return nil, err
+	}
+	return &externalSorter{
+		ctx:          ctx,
+		input:        input,
+		inputTypes:   inputTypes,
+		orderingCols: orderingCols,
+		memAcc:       memAcc,
+		diskFactory:  diskFactory,
+		diskMonitor:  diskMonitor,
+		buffer:       newBufferedBatches(inputTypes),
+		state:        externalSorterSpooling,
+	}, nil
+}
+
+// externalSorterState represents the state of the external sort operator.
+type externalSorterState int
+
+const (
+	// externalSorterSpooling is the initial state of the operator, where it
+	// buffers its input, spilling sorted runs to disk whenever the memory budget
+	// is exceeded.
+	externalSorterSpooling externalSorterState = iota
+	// externalSorterEmittingInMemory indicates that the whole input fit in memory
+	// and that each call to Next will return another chunk of the input, sorted
+	// in memory.
+	externalSorterEmittingInMemory
+	// externalSorterMerging indicates that the input was spilled to disk and that
+	// each call to Next will return another chunk of the merged sorted runs.
+	externalSorterMerging
+)
+
+type externalSorter struct {
+	ctx          context.Context
+	input        Operator
+	inputTypes   []types.T
+	orderingCols []distsqlpb.Ordering_Column
+
+	memAcc      *mon.BoundAccount
+	diskFactory diskmap.Factory
+	diskMonitor *mon.BytesMonitor
+
+	state externalSorterState
+	// buffer contains the input that was spooled since the last sorted run was
+	// spilled to disk.
+	buffer *bufferedBatches
+	// inMemSorter sorts the buffer if the whole input fits in memory.
+	inMemSorter Operator
+
+	// runs stores the sorted runs that were spilled to disk, each in a queue of
+	// its own.
+	runs    *diskBatchStore
+	numRuns int
+	// heap contains the sorted runs that haven't been fully merged yet.
+	heap   sortedRunHeap
+	output ColBatch
+}
+
+var _ Operator = &externalSorter{}
+
+func (s *externalSorter) Init() {
+	s.input.Init()
+	s.output = NewMemBatch(s.inputTypes)
+}
+
+func (s *externalSorter) Next() ColBatch {
+	switch s.state {
+	case externalSorterSpooling:
+		s.spool()
+		if s.numRuns == 0 {
+			sorter, err := NewSorter(s.buffer, s.inputTypes, s.orderingCols)
+			if err != nil {
+				raiseError(err)
+			}
+			sorter.Init()
+			s.inMemSorter = sorter
+			s.state = externalSorterEmittingInMemory
+		} else {
+			s.spillBuffer()
+			s.initMerge()
+			s.state = externalSorterMerging
+		}
+		return s.Next()
+	case externalSorterEmittingInMemory:
+		return s.inMemSorter.Next()
+	case externalSorterMerging:
+		return s.nextMerged()
+	}
+	panic(fmt.Sprintf("invalid external sort state %v", s.state))
+}
+
+// Close releases the temporary disk storage used by the operator.
+func (s *externalSorter) Close() {
+	if s.runs != nil {
+		s.runs.close()
+	}
+}
+
+// spool buffers the whole input, spilling the buffer to disk as a sorted run
+// whenever the memory budget is exceeded.
+func (s *externalSorter) spool() {
+	for batch := s.input.Next(); batch.Length() != 0; batch = s.input.Next() {
+		size := estimateBatchSizeBytes(batch, s.inputTypes) +
+			int64(batch.Length())*externalSorterTupleOverhead
+		if err := s.memAcc.Grow(s.ctx, size); err != nil {
+			if !isOutOfMemoryError(err) || s.diskFactory == nil {
+				raiseError(err)
+			}
+			s.spillBuffer()
+			if err := s.memAcc.Grow(s.ctx, size); err != nil {
+				if !isOutOfMemoryError(err) {
+					raiseError(err)
+				}
+				// The batch doesn't fit in the memory budget on its own, so it is
+				// spilled as a sorted run by itself.
+				s.buffer.add(batch)
+				s.spillBuffer()
+				continue
+			}
+		}
+		s.buffer.add(batch)
+	}
+}
+
+// spillBuffer sorts the buffered input and writes it to disk as a new sorted
+// run, releasing the memory used by the buffer.
+func (s *externalSorter) spillBuffer() {
+	if s.buffer.empty() {
+		return
+	}
+	if s.runs == nil {
+		s.runs = newDiskBatchStore(s.ctx, s.inputTypes, s.diskFactory, s.diskMonitor)
+	}
+	sorter, err := NewSorter(s.buffer, s.inputTypes, s.orderingCols)
+	if err != nil {
+		raiseError(err)
+	}
+	sorter.Init()
+	for batch := sorter.Next(); batch.Length() != 0; batch = sorter.Next() {
+		if err := s.runs.add(s.numRuns, batch); err != nil {
+			raiseError(err)
+		}
+	}
+	s.numRuns++
+	s.memAcc.Clear(s.ctx)
+}
+
+// initMerge prepares the merge of all of the sorted runs. Each run holds one
+// batch in memory while it is being merged.
+func (s *externalSorter) initMerge() {
+	s.heap = sortedRunHeap{s: s, runs: make([]*sortedRun, 0, s.numRuns)}
+	for i := 0; i < s.numRuns; i++ {
+		reader, err := s.runs.newQueueReader(i)
+		if err != nil {
+			raiseError(err)
+		}
+		reader.Init()
+		run := &sortedRun{reader: reader, batch: reader.Next()}
+		if run.batch.Length() != 0 {
+			s.heap.runs = append(s.heap.runs, run)
+		}
+	}
+	heap.Init(&s.heap)
+}
+
+// nextMerged returns the next chunk of the merged sorted runs.
+func (s *externalSorter) nextMerged() ColBatch {
+	for _, vec := range s.output.ColVecs() {
+		vec.UnsetNulls()
+	}
+	var n uint16
+	for n < ColBatchSize && s.heap.Len() > 0 {
+		run := s.heap.runs[0]
+		for i, t := range s.inputTypes {
+			mjCopyRange(t, run.batch.ColVec(i), nil /* sel */, uint64(run.idx), s.output.ColVec(i), n, 1)
+		}
+		n++
+		run.idx++
+		if run.idx == run.batch.Length() {
+			run.batch, run.idx = run.reader.Next(), 0
+			if run.batch.Length() == 0 {
+				heap.Pop(&s.heap)
+				continue
+			}
+		}
+		heap.Fix(&s.heap, 0)
+	}
+	if n == 0 {
+		s.Close()
+	}
+	s.output.SetLength(n)
+	return s.output
+}
+
+// compare returns a negative number, 0 or a positive number if the current
+// tuple of run a sorts respectively before, equal to or after the current
+// tuple of run b. NULLs sort before all other values.
+func (s *externalSorter) compare(a, b *sortedRun) int {
+	for _, ord := range s.orderingCols {
+		aVec, bVec := a.batch.ColVec(int(ord.ColIdx)), b.batch.ColVec(int(ord.ColIdx))
+		aNull := aVec.HasNulls() && aVec.NullAt(a.idx)
+		bNull := bVec.HasNulls() && bVec.NullAt(b.idx)
+		var cmp int
+		switch {
+		case aNull && bNull:
+			cmp = 0
+		case aNull:
+			cmp = -1
+		case bNull:
+			cmp = 1
+		default:
+			cmp = mjCompareValues(
+				s.inputTypes[ord.ColIdx], aVec, uint64(a.idx), bVec, uint64(b.idx),
+			)
+		}
+		if ord.Direction == distsqlpb.Ordering_Column_DESC {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// sortedRun is a sorted run that is being merged by the external sorter.
+type sortedRun struct {
+	reader *diskQueueReader
+	// batch is the batch of the run that is currently being merged, and idx is
+	// the index of the current tuple of the run in it. Batches read from disk
+	// never have a selection vector.
+	batch ColBatch
+	idx   uint16
+}
+
+// sortedRunHeap is a min-heap of sorted runs, ordered by their current tuples.
+type sortedRunHeap struct {
+	s    *externalSorter
+	runs []*sortedRun
+}
+
+var _ heap.Interface = &sortedRunHeap{}
+
+func (h *sortedRunHeap) Len() int { return len(h.runs) }
+
+func (h *sortedRunHeap) Less(i, j int) bool { return h.s.compare(h.runs[i], h.runs[j]) < 0 }
+
+func (h *sortedRunHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *sortedRunHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*sortedRun)) }
+
+func (h *sortedRunHeap) Pop() interface{} {
+	n := len(h.runs)
+	run := h.runs[n-1]
+	h.runs[n-1] = nil
+	h.runs = h.runs[:n-1]
+	return run
+}
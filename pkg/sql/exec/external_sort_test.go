@@ -0,0 +1,118 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalSort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewPseudoRand()
+	nTups := 2048
+	typs := []types.T{types.Int64, types.Bytes, types.Float64}
+	// The first column is a permutation, so that the order of the sorted tuples
+	// is fully determined by it. The last column contains NULLs.
+	perm := rng.Perm(nTups)
+	tups := make(tuples, nTups)
+	for i := range tups {
+		var f interface{}
+		if rng.Intn(4) != 0 {
+			f = rng.Float64()
+		}
+		tups[i] = tuple{int64(perm[i]), []byte(fmt.Sprintf("%d", rng.Intn(1000))), f}
+	}
+
+	for _, dir := range []distsqlpb.Ordering_Column_Direction{
+		distsqlpb.Ordering_Column_ASC, distsqlpb.Ordering_Column_DESC,
+	} {
+		ordCols := []distsqlpb.Ordering_Column{{ColIdx: 0, Direction: dir}}
+		expected := make(tuples, nTups)
+		copy(expected, tups)
+		sort.Slice(expected, func(i, j int) bool {
+			if dir == distsqlpb.Ordering_Column_DESC {
+				i, j = j, i
+			}
+			return expected[i][0].(int64) < expected[j][0].(int64)
+		})
+
+		for _, tc := range []struct {
+			name     string
+			memLimit int64
+			spills   bool
+		}{
+			{name: "InMemory", memLimit: 0},
+			{name: "Spilling", memLimit: 16 << 10, spills: true},
+		} {
+			t.Run(fmt.Sprintf("%s/dir=%s", tc.name, dir), func(t *testing.T) {
+				runTests(t, []tuples{tups}, nil, func(t *testing.T, input []Operator) {
+					storage := newTestExternalStorage(t, tc.memLimit)
+					defer storage.close()
+
+					sorter, err := NewExternalSorter(
+						context.Background(), input[0], typs, ordCols,
+						&storage.memAcc, storage.tempEngine, &storage.diskMonitor,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer sorter.(Closer).Close()
+					out := newOpTestOutput(sorter, []int{0, 1, 2}, expected)
+					if err := out.Verify(); err != nil {
+						t.Fatal(err)
+					}
+					if spilled := storage.diskMonitor.MaximumBytes() > 0; spilled != tc.spills {
+						t.Fatalf("expected spilled=%t, got %t", tc.spills, spilled)
+					}
+				})
+			})
+		}
+	}
+}
+
+func TestExternalSortMemoryLimitWithoutDisk(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tups := make(tuples, 2048)
+	for i := range tups {
+		tups[i] = tuple{int64(len(tups) - i)}
+	}
+	storage := newTestExternalStorage(t, 1<<10)
+	defer storage.close()
+
+	input := newOpTestInput(ColBatchSize, tups)
+	sorter, err := NewExternalSorter(
+		context.Background(), input, []types.T{types.Int64},
+		[]distsqlpb.Ordering_Column{{ColIdx: 0}},
+		&storage.memAcc, nil /* diskFactory */, &storage.diskMonitor,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorter.Init()
+	err = CatchRuntimeError(func() { sorter.Next() })
+	if !isOutOfMemoryError(err) {
+		t.Fatalf("expected an out of memory error, got %v", err)
+	}
+}
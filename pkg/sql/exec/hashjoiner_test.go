@@ -17,10 +17,12 @@ package exec
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -37,6 +39,13 @@ func TestHashJoinerInt64(t *testing.T) {
 		}
 	}
 
+	// Set up the time.Time values used in tests. ts3 is the same instant as
+	// ts1, in a different location.
+	ts0 := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts1 := ts0.Add(time.Hour)
+	ts2 := ts0.Add(2 * time.Hour)
+	ts3 := ts1.In(time.FixedZone("UTC+5", 5*60*60))
+
 	tcs := []struct {
 		leftTypes  []types.T
 		rightTypes []types.T
@@ -668,6 +677,63 @@ func TestHashJoinerInt64(t *testing.T) {
 				{decs[0]},
 			},
 		},
+		{
+			leftTypes:  []types.T{types.Timestamp},
+			rightTypes: []types.T{types.Timestamp},
+
+			// Test types.Timestamp type as equality column. Timestamps in
+			// different locations are equal if they represent the same instant.
+			leftTuples: tuples{
+				{ts0},
+				{ts1},
+			},
+			rightTuples: tuples{
+				{ts2},
+				{ts3},
+				{ts0},
+			},
+
+			leftEqCols:   []uint32{0},
+			rightEqCols:  []uint32{0},
+			leftOutCols:  []uint32{},
+			rightOutCols: []uint32{0},
+
+			buildDistinct: true,
+
+			expectedTuples: tuples{
+				{ts3},
+				{ts0},
+			},
+		},
+		{
+			leftTypes:  []types.T{types.Interval},
+			rightTypes: []types.T{types.Interval},
+
+			// Test types.Interval type as equality column. Intervals are equal if
+			// they are equal once normalized.
+			leftTuples: tuples{
+				{duration.Duration{Months: 1}},
+				{duration.Duration{Days: 2}},
+				{duration.Duration{Nanos: 3}},
+			},
+			rightTuples: tuples{
+				{duration.Duration{Days: 30}},
+				{duration.Duration{Days: 3}},
+				{duration.Duration{Nanos: 3}},
+			},
+
+			leftEqCols:   []uint32{0},
+			rightEqCols:  []uint32{0},
+			leftOutCols:  []uint32{0},
+			rightOutCols: []uint32{0},
+
+			buildDistinct: true,
+
+			expectedTuples: tuples{
+				{duration.Duration{Months: 1}, duration.Duration{Days: 30}},
+				{duration.Duration{Nanos: 3}, duration.Duration{Nanos: 3}},
+			},
+		},
 	}
 
 	for _, tc := range tcs {
@@ -30,6 +30,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 )
 
 // {{/*
@@ -43,6 +44,9 @@ var _ = math.Pi
 // Dummy import to pull in "bytes" package.
 var _ bytes.Buffer
 
+// Dummy import to pull in "json" package.
+var _ json.JSON
+
 // _ASSIGN_HASH is the template equality function for assigning the first input
 // to the result of the hash value of the second input.
 func _ASSIGN_HASH(_, _ interface{}) uint64 {
@@ -0,0 +1,100 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+)
+
+const (
+	sizeOfSlice     = int64(unsafe.Sizeof([]byte{}))
+	sizeOfDecimal   = int64(unsafe.Sizeof(apd.Decimal{}))
+	sizeOfTime      = int64(unsafe.Sizeof(time.Time{}))
+	sizeOfDuration  = int64(unsafe.Sizeof(duration.Duration{}))
+	sizeOfInterface = int64(unsafe.Sizeof(interface{}(nil)))
+	sizeOfUint64    = int64(unsafe.Sizeof(uint64(0)))
+	sizeOfWord      = int64(unsafe.Sizeof(uintptr(0)))
+)
+
+// estimateBatchSizeBytes returns an estimate of the number of bytes needed to
+// store the selected tuples of batch, whose columns have the given types.
+func estimateBatchSizeBytes(batch ColBatch, typs []types.T) int64 {
+	n := batch.Length()
+	sel := batch.Selection()
+	var size int64
+	for colIdx, t := range typs {
+		var fixedSize int64
+		switch t {
+		case types.Bool, types.Int8:
+			fixedSize = 1
+		case types.Int16:
+			fixedSize = 2
+		case types.Int32, types.Float32:
+			fixedSize = 4
+		case types.Int64, types.Float64:
+			fixedSize = 8
+		case types.Bytes:
+			fixedSize = sizeOfSlice
+		case types.Decimal:
+			fixedSize = sizeOfDecimal
+		case types.Timestamp:
+			fixedSize = sizeOfTime
+		case types.Interval:
+			fixedSize = sizeOfDuration
+		case types.JSON:
+			fixedSize = sizeOfInterface
+		default:
+			panic(fmt.Sprintf("unhandled type %s", t))
+		}
+		size += fixedSize * int64(n)
+
+		// Variable-length types also own memory outside of the column's slice.
+		if t != types.Bytes && t != types.Decimal && t != types.JSON {
+			continue
+		}
+		vec := batch.ColVec(colIdx)
+		for i := uint16(0); i < n; i++ {
+			idx := i
+			if sel != nil {
+				idx = sel[i]
+			}
+			switch t {
+			case types.Bytes:
+				size += int64(len(vec.Bytes()[idx]))
+			case types.Decimal:
+				size += int64(len(vec.Decimal()[idx].Coeff.Bits())) * sizeOfWord
+			case types.JSON:
+				if j := vec.JSON()[idx]; j != nil {
+					size += int64(j.Size())
+				}
+			}
+		}
+	}
+	return size
+}
+
+// isOutOfMemoryError returns whether err is the error returned by a memory
+// monitor when its budget is exceeded.
+func isOutOfMemoryError(err error) bool {
+	pgErr, ok := pgerror.GetPGCause(err)
+	return ok && pgErr.Code == pgerror.CodeOutOfMemoryError
+}
@@ -0,0 +1,560 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/pkg/errors"
+)
+
+// mjEmitKind specifies which rows the merge joiner is emitting for the current
+// pair of groups.
+type mjEmitKind int
+
+const (
+	// mjEmitMatched emits the rows of a left and a right group with equal
+	// equality columns: their cross product, or just the left rows for a semi
+	// join.
+	mjEmitMatched mjEmitKind = iota
+
+	// mjEmitLeftUnmatched emits the rows of a left group that has no matching
+	// right group, with NULL values for the right columns.
+	mjEmitLeftUnmatched
+
+	// mjEmitRightUnmatched emits the rows of a right group that has no matching
+	// left group, with NULL values for the left columns.
+	mjEmitRightUnmatched
+)
+
+// mjGroup is a run of consecutive rows of one of the merge joiner's inputs
+// which have equal values in all of the equality columns.
+type mjGroup struct {
+	// vecs are the column vectors that store the rows of the group, indexed by
+	// input column. They are either the columns of the current input batch or,
+	// if the group spans several input batches, the buffered columns of the
+	// input.
+	vecs []ColVec
+	// sel is the selection vector that applies to vecs, or nil.
+	sel []uint16
+	// start and end delimit the rows of the group in vecs. If sel is set, they
+	// are indices into sel.
+	start, end uint64
+	// hasNull is set if one of the equality columns of the group is NULL, in
+	// which case the group doesn't match any rows of the other input. Such a
+	// group always consists of a single row.
+	hasNull bool
+	// loaded is set when the group has been read from the input and hasn't been
+	// fully processed yet.
+	loaded bool
+}
+
+// rowIdx returns the index in vecs of the ith row of the group.
+func (g *mjGroup) rowIdx(i uint64) uint64 {
+	if g.sel != nil {
+		return uint64(g.sel[i])
+	}
+	return i
+}
+
+// mergeJoinInput holds the specification and the state of one of the inputs
+// of a merge joiner.
+type mergeJoinInput struct {
+	// source specifies the input operator.
+	source Operator
+
+	// eqCols specify the indices of the equality columns, in the order in which
+	// the input is sorted on them.
+	eqCols []uint32
+
+	// outCols specify the indices of the columns that should be outputted by
+	// the merge joiner.
+	outCols []uint32
+
+	// sourceTypes specify the types of the input columns.
+	sourceTypes []types.T
+
+	// batch is the current input batch and batchVecs are its column vectors.
+	// Only the equality and output columns are set in batchVecs.
+	batch     ColBatch
+	batchVecs []ColVec
+	// idx is the index (into the selection vector, if there is one) of the
+	// first row of batch that hasn't been assigned to a group yet.
+	idx uint64
+	// exhausted is set once the source returns a zero-length batch.
+	exhausted bool
+
+	// buffer stores the rows of a group that spans several input batches. Like
+	// batchVecs, only the equality and output columns are set.
+	buffer []ColVec
+
+	// group is the current group of this input.
+	group mjGroup
+}
+
+// fetch makes sure that the input has a batch with rows that haven't been
+// assigned to a group yet, reading a new batch if necessary. It returns false
+// once the input is exhausted.
+func (in *mergeJoinInput) fetch() bool {
+	for !in.exhausted && (in.batch == nil || in.idx >= uint64(in.batch.Length())) {
+		in.batch = in.source.Next()
+		in.idx = 0
+		if in.batch.Length() == 0 {
+			in.exhausted = true
+			break
+		}
+		for i := range in.batchVecs {
+			if in.buffer[i] != nil {
+				in.batchVecs[i] = in.batch.ColVec(i)
+			}
+		}
+	}
+	return !in.exhausted
+}
+
+// runEnd returns the index of the first row in [start, end) of the current
+// batch whose equality columns differ from the ones of the row at keyIdx in
+// keyVecs, or end if there is no such row.
+func (in *mergeJoinInput) runEnd(keyVecs []ColVec, keyIdx uint64, start, end uint64) uint64 {
+	sel := in.batch.Selection()
+	for _, c := range in.eqCols {
+		end = mjRunEnd(in.sourceTypes[c], keyVecs[c], keyIdx, in.batchVecs[c], sel, start, end)
+		if end == start {
+			break
+		}
+	}
+	return end
+}
+
+// bufferRows appends the rows [start, end) of the current batch to the
+// buffer, starting at the row destIdx of the buffer.
+func (in *mergeJoinInput) bufferRows(destIdx uint64, start, end uint64) {
+	sel := in.batch.Selection()
+	for i, vec := range in.buffer {
+		if vec != nil {
+			vec.AppendSlice(in.batchVecs[i], in.sourceTypes[i], destIdx, uint16(start), uint16(end), sel)
+		}
+	}
+}
+
+// loadGroup reads the next group of rows from the input. If the input is
+// exhausted, the group is left unloaded.
+func (in *mergeJoinInput) loadGroup() {
+	g := &in.group
+	if !in.fetch() {
+		*g = mjGroup{}
+		return
+	}
+
+	length := uint64(in.batch.Length())
+	sel := in.batch.Selection()
+	first := in.idx
+	firstIdx := first
+	if sel != nil {
+		firstIdx = uint64(sel[first])
+	}
+	*g = mjGroup{vecs: in.batchVecs, sel: sel, start: first, loaded: true}
+
+	for _, c := range in.eqCols {
+		if vec := in.batchVecs[c]; vec.HasNulls() && vec.NullAt64(firstIdx) {
+			// NULL is not equal to anything, so a row that has a NULL equality
+			// column is a group on its own.
+			g.hasNull = true
+			g.end = first + 1
+			in.idx = g.end
+			return
+		}
+	}
+
+	end := in.runEnd(in.batchVecs, firstIdx, first+1, length)
+	if end < length {
+		// The group ends within the current batch, so we can refer to the rows
+		// of the batch directly.
+		g.end = end
+		in.idx = end
+		return
+	}
+
+	// The group might continue in the next batch. Reading the next batch
+	// invalidates the current one, so buffer the rows of the group.
+	in.bufferRows(0, first, end)
+	n := end - first
+	in.idx = end
+	for in.fetch() {
+		length = uint64(in.batch.Length())
+		end = in.runEnd(in.buffer, 0, in.idx, length)
+		in.bufferRows(n, in.idx, end)
+		n += end - in.idx
+		in.idx = end
+		if end < length {
+			break
+		}
+	}
+	*g = mjGroup{vecs: in.buffer, start: 0, end: n, loaded: true}
+}
+
+// mergeJoinOp is an operator that implements sort-merge join. It performs a
+// merge on the left and right input sources, based on the equality columns,
+// assuming both inputs are in sorted order on them in the same directions.
+//
+// Both inputs are consumed in groups: runs of consecutive rows with equal
+// values in the equality columns. A group that is fully contained in the
+// current input batch is referenced in place, while a group that spans several
+// batches is buffered, since reading the next batch of an input invalidates
+// its previous one. The current groups of the two inputs are then compared:
+// - if they are equal, their cross product is emitted (just the left rows for
+//   a semi join), and both groups are consumed.
+// - otherwise, the smaller group has no match, so it's emitted with NULLs for
+//   the other side's columns if required by the join type, and consumed.
+// Emitting a pair of groups can be interrupted once the output batch is full,
+// and is resumed on the next call to Next.
+//
+// The output batch contains all of the columns of the left input followed by
+// all of the columns of the right input (just the columns of the left input
+// for semi and anti joins), but only the output columns are populated.
+type mergeJoinOp struct {
+	joinType sqlbase.JoinType
+
+	left  mergeJoinInput
+	right mergeJoinInput
+
+	// directions are the directions in which the inputs are sorted on the
+	// equality columns.
+	directions []distsqlpb.Ordering_Column_Direction
+
+	// output is the output batch and outputBatchSize is the maximum number of
+	// rows that it can contain.
+	output          ColBatch
+	outputBatchSize uint16
+
+	// emitting is set while the rows of the current groups are being emitted,
+	// emitKind specifies which ones, and leftIdx and rightIdx are the indices
+	// of the next left and right rows to emit.
+	emitting          bool
+	emitKind          mjEmitKind
+	leftIdx, rightIdx uint64
+}
+
+var _ Operator = &mergeJoinOp{}
+
+// NewMergeJoinOp returns a new merge join operator with the given spec. Both
+// inputs must be ordered on their equality columns, which are given by
+// leftOrdering and rightOrdering. The ith column of the left ordering is
+// constrained to be equal to the ith column of the right ordering, and the
+// directions of the two orderings must match.
+func NewMergeJoinOp(
+	joinType sqlbase.JoinType,
+	left Operator,
+	right Operator,
+	leftOutCols []uint32,
+	rightOutCols []uint32,
+	leftTypes []types.T,
+	rightTypes []types.T,
+	leftOrdering []distsqlpb.Ordering_Column,
+	rightOrdering []distsqlpb.Ordering_Column,
+) (Operator, error) {
+	switch joinType {
+	case sqlbase.JoinType_INNER, sqlbase.JoinType_LEFT_OUTER, sqlbase.JoinType_RIGHT_OUTER,
+		sqlbase.JoinType_FULL_OUTER:
+	case sqlbase.JoinType_LEFT_SEMI, sqlbase.JoinType_LEFT_ANTI:
+		if len(rightOutCols) != 0 {
+			return nil, errors.Errorf("%s join can't output right columns", joinType)
+		}
+	default:
+		return nil, errors.Errorf("merge join of type %s not supported", joinType)
+	}
+	if len(leftOrdering) != len(rightOrdering) {
+		return nil, errors.Errorf(
+			"mismatched merge join orderings: left(%d), right(%d)", len(leftOrdering), len(rightOrdering),
+		)
+	}
+
+	leftEqCols := make([]uint32, len(leftOrdering))
+	rightEqCols := make([]uint32, len(rightOrdering))
+	directions := make([]distsqlpb.Ordering_Column_Direction, len(leftOrdering))
+	for i := range leftOrdering {
+		leftEqCols[i] = leftOrdering[i].ColIdx
+		rightEqCols[i] = rightOrdering[i].ColIdx
+		if leftOrdering[i].Direction != rightOrdering[i].Direction {
+			return nil, errors.New("merge join orderings must have matching directions")
+		}
+		directions[i] = leftOrdering[i].Direction
+		if l, r := leftTypes[leftEqCols[i]], rightTypes[rightEqCols[i]]; l != r {
+			return nil, errors.Errorf("can't merge join equality columns of types %s and %s", l, r)
+		}
+	}
+
+	return &mergeJoinOp{
+		joinType:   joinType,
+		left:       makeMergeJoinInput(left, leftEqCols, leftOutCols, leftTypes),
+		right:      makeMergeJoinInput(right, rightEqCols, rightOutCols, rightTypes),
+		directions: directions,
+	}, nil
+}
+
+func makeMergeJoinInput(
+	source Operator, eqCols []uint32, outCols []uint32, sourceTypes []types.T,
+) mergeJoinInput {
+	in := mergeJoinInput{
+		source:      source,
+		eqCols:      eqCols,
+		outCols:     outCols,
+		sourceTypes: sourceTypes,
+		batchVecs:   make([]ColVec, len(sourceTypes)),
+		buffer:      make([]ColVec, len(sourceTypes)),
+	}
+	for _, cols := range [][]uint32{eqCols, outCols} {
+		for _, c := range cols {
+			if in.buffer[c] == nil {
+				in.buffer[c] = newMemColumn(sourceTypes[c], 0)
+			}
+		}
+	}
+	return in
+}
+
+func (o *mergeJoinOp) Init() {
+	o.initWithOutputBatchSize(ColBatchSize)
+}
+
+func (o *mergeJoinOp) initWithOutputBatchSize(outBatchSize uint16) {
+	o.left.source.Init()
+	o.right.source.Init()
+
+	outputTypes := o.left.sourceTypes
+	if o.outputsRightCols() {
+		outputTypes = append(append([]types.T(nil), o.left.sourceTypes...), o.right.sourceTypes...)
+	}
+	o.output = NewMemBatchWithSize(outputTypes, int(outBatchSize))
+	o.outputBatchSize = outBatchSize
+}
+
+// outputsRightCols returns whether the output of the merge joiner contains the
+// columns of the right input.
+func (o *mergeJoinOp) outputsRightCols() bool {
+	return o.joinType != sqlbase.JoinType_LEFT_SEMI && o.joinType != sqlbase.JoinType_LEFT_ANTI
+}
+
+func (o *mergeJoinOp) Next() ColBatch {
+	o.output.SetSelection(false)
+	for _, vec := range o.output.ColVecs() {
+		vec.UnsetNulls()
+	}
+
+	outCount := uint16(0)
+	for outCount < o.outputBatchSize {
+		if o.emitting {
+			outCount = o.emit(outCount)
+			continue
+		}
+
+		l, r := &o.left.group, &o.right.group
+		if !l.loaded {
+			o.left.loadGroup()
+		}
+		if !r.loaded {
+			o.right.loadGroup()
+		}
+
+		var cmp int
+		switch {
+		case !l.loaded && !r.loaded:
+			// Both inputs are exhausted.
+			o.output.SetLength(outCount)
+			return o.output
+		case !l.loaded || r.hasNull:
+			cmp = 1
+		case !r.loaded || l.hasNull:
+			cmp = -1
+		default:
+			cmp = o.compareGroups()
+		}
+
+		switch {
+		case cmp < 0:
+			o.startEmitting(mjEmitLeftUnmatched)
+		case cmp > 0:
+			o.startEmitting(mjEmitRightUnmatched)
+		default:
+			o.startEmitting(mjEmitMatched)
+		}
+	}
+
+	o.output.SetLength(outCount)
+	return o.output
+}
+
+// compareGroups compares the equality columns of the current left and right
+// groups, taking the ordering directions into account. It returns a negative
+// number if the left group comes first, a positive number if the right group
+// comes first, and 0 if the groups match.
+func (o *mergeJoinOp) compareGroups() int {
+	l, r := &o.left.group, &o.right.group
+	lIdx, rIdx := l.rowIdx(l.start), r.rowIdx(r.start)
+	for i, lCol := range o.left.eqCols {
+		rCol := o.right.eqCols[i]
+		cmp := mjCompareValues(o.left.sourceTypes[lCol], l.vecs[lCol], lIdx, r.vecs[rCol], rIdx)
+		if cmp != 0 {
+			if o.directions[i] == distsqlpb.Ordering_Column_DESC {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
+
+// startEmitting sets up the emission of the given kind of rows from the
+// current groups. If the join type doesn't require any rows to be emitted, the
+// groups are consumed right away.
+func (o *mergeJoinOp) startEmitting(kind mjEmitKind) {
+	var emit bool
+	switch kind {
+	case mjEmitMatched:
+		emit = o.joinType != sqlbase.JoinType_LEFT_ANTI
+	case mjEmitLeftUnmatched:
+		emit = o.joinType == sqlbase.JoinType_LEFT_OUTER ||
+			o.joinType == sqlbase.JoinType_FULL_OUTER ||
+			o.joinType == sqlbase.JoinType_LEFT_ANTI
+	case mjEmitRightUnmatched:
+		emit = o.joinType == sqlbase.JoinType_RIGHT_OUTER || o.joinType == sqlbase.JoinType_FULL_OUTER
+	}
+	if !emit {
+		o.finishEmitting(kind)
+		return
+	}
+	o.emitting = true
+	o.emitKind = kind
+	o.leftIdx = o.left.group.start
+	o.rightIdx = o.right.group.start
+}
+
+// finishEmitting consumes the groups whose rows were emitted.
+func (o *mergeJoinOp) finishEmitting(kind mjEmitKind) {
+	o.emitting = false
+	if kind != mjEmitRightUnmatched {
+		o.left.group.loaded = false
+	}
+	if kind != mjEmitLeftUnmatched {
+		o.right.group.loaded = false
+	}
+}
+
+// emit emits rows from the current groups into the output batch, starting at
+// the output row outCount, until either all of the rows have been emitted or
+// the output batch is full. It returns the new number of output rows.
+func (o *mergeJoinOp) emit(outCount uint16) uint16 {
+	l, r := &o.left.group, &o.right.group
+	leftColOffset := uint32(len(o.left.sourceTypes))
+
+	var done bool
+	switch o.emitKind {
+	case mjEmitMatched:
+		if !o.outputsRightCols() {
+			// For a semi join, every matching left row is emitted once.
+			n := o.emitCount(outCount, l.end-o.leftIdx)
+			o.copyRows(&o.left, 0 /* colOffset */, o.leftIdx, outCount, n)
+			o.leftIdx += uint64(n)
+			outCount += n
+		} else {
+			for outCount < o.outputBatchSize && o.leftIdx < l.end {
+				// Emit the current left row with as many right rows as fit.
+				n := o.emitCount(outCount, r.end-o.rightIdx)
+				o.repeatRow(&o.left, 0 /* colOffset */, o.leftIdx, outCount, n)
+				o.copyRows(&o.right, leftColOffset, o.rightIdx, outCount, n)
+				o.rightIdx += uint64(n)
+				outCount += n
+				if o.rightIdx == r.end {
+					o.rightIdx = r.start
+					o.leftIdx++
+				}
+			}
+		}
+		done = o.leftIdx == l.end
+
+	case mjEmitLeftUnmatched:
+		n := o.emitCount(outCount, l.end-o.leftIdx)
+		o.copyRows(&o.left, 0 /* colOffset */, o.leftIdx, outCount, n)
+		if o.outputsRightCols() {
+			o.setNulls(&o.right, leftColOffset, outCount, n)
+		}
+		o.leftIdx += uint64(n)
+		outCount += n
+		done = o.leftIdx == l.end
+
+	case mjEmitRightUnmatched:
+		n := o.emitCount(outCount, r.end-o.rightIdx)
+		o.setNulls(&o.left, 0 /* colOffset */, outCount, n)
+		o.copyRows(&o.right, leftColOffset, o.rightIdx, outCount, n)
+		o.rightIdx += uint64(n)
+		outCount += n
+		done = o.rightIdx == r.end
+	}
+
+	if done {
+		o.finishEmitting(o.emitKind)
+	}
+	return outCount
+}
+
+// emitCount returns how many of the remaining rows fit into the output batch,
+// which already contains outCount rows.
+func (o *mergeJoinOp) emitCount(outCount uint16, remaining uint64) uint16 {
+	if space := uint64(o.outputBatchSize - outCount); remaining > space {
+		return uint16(space)
+	}
+	return uint16(remaining)
+}
+
+// copyRows copies n rows of the current group of the given input, starting at
+// the row start, to the output columns of the input, which start at colOffset
+// in the output batch. The rows are written starting at the output row
+// outStart.
+func (o *mergeJoinOp) copyRows(
+	in *mergeJoinInput, colOffset uint32, start uint64, outStart uint16, n uint16,
+) {
+	g := &in.group
+	for _, c := range in.outCols {
+		outVec := o.output.ColVec(int(c + colOffset))
+		mjCopyRange(in.sourceTypes[c], g.vecs[c], g.sel, start, outVec, outStart, n)
+	}
+}
+
+// repeatRow copies the row idx of the current group of the given input n times
+// to the output columns of the input, which start at colOffset in the output
+// batch. The rows are written starting at the output row outStart.
+func (o *mergeJoinOp) repeatRow(
+	in *mergeJoinInput, colOffset uint32, idx uint64, outStart uint16, n uint16,
+) {
+	g := &in.group
+	rowIdx := g.rowIdx(idx)
+	for _, c := range in.outCols {
+		outVec := o.output.ColVec(int(c + colOffset))
+		mjCopyRepeated(in.sourceTypes[c], g.vecs[c], rowIdx, outVec, outStart, n)
+	}
+}
+
+// setNulls sets n rows of the output columns of the given input, which start
+// at colOffset in the output batch, to NULL, starting at the output row
+// outStart.
+func (o *mergeJoinOp) setNulls(in *mergeJoinInput, colOffset uint32, outStart uint16, n uint16) {
+	for _, c := range in.outCols {
+		vec := o.output.ColVec(int(c + colOffset))
+		for i := outStart; i < outStart+n; i++ {
+			vec.SetNull(i)
+		}
+	}
+}
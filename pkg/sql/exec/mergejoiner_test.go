@@ -0,0 +1,374 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestMergeJoiner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tcs := []struct {
+		description string
+
+		leftTypes  []types.T
+		rightTypes []types.T
+
+		leftTuples  tuples
+		rightTuples tuples
+
+		leftOrdering  []distsqlpb.Ordering_Column
+		rightOrdering []distsqlpb.Ordering_Column
+
+		leftOutCols  []uint32
+		rightOutCols []uint32
+
+		// The default joinType is sqlbase.JoinType_INNER if this value is not set.
+		joinType sqlbase.JoinType
+
+		expectedTuples tuples
+	}{
+		{
+			description: "inner join with duplicates on both sides",
+			leftTypes:   []types.T{types.Int64, types.Int64},
+			rightTypes:  []types.T{types.Int64, types.Int64},
+			leftTuples: tuples{
+				{1, 10},
+				{2, 20},
+				{2, 21},
+				{4, 40},
+				{5, 50},
+				{5, 51},
+				{5, 52},
+			},
+			rightTuples: tuples{
+				{0, 0},
+				{2, 200},
+				{2, 201},
+				{3, 300},
+				{5, 500},
+				{6, 600},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{0, 1},
+			rightOutCols:  []uint32{1},
+			expectedTuples: tuples{
+				{2, 20, 200},
+				{2, 20, 201},
+				{2, 21, 200},
+				{2, 21, 201},
+				{5, 50, 500},
+				{5, 51, 500},
+				{5, 52, 500},
+			},
+		},
+		{
+			description: "left outer join",
+			leftTypes:   []types.T{types.Int64, types.Int64},
+			rightTypes:  []types.T{types.Int64, types.Int64},
+			leftTuples: tuples{
+				{1, 10},
+				{2, 20},
+				{2, 21},
+				{4, 40},
+			},
+			rightTuples: tuples{
+				{2, 200},
+				{3, 300},
+				{4, 400},
+				{4, 401},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{1},
+			rightOutCols:  []uint32{1},
+			joinType:      sqlbase.JoinType_LEFT_OUTER,
+			expectedTuples: tuples{
+				{10, nil},
+				{20, 200},
+				{21, 200},
+				{40, 400},
+				{40, 401},
+			},
+		},
+		{
+			description: "right outer join",
+			leftTypes:   []types.T{types.Int64},
+			rightTypes:  []types.T{types.Int64},
+			leftTuples: tuples{
+				{1},
+				{3},
+				{3},
+			},
+			rightTuples: tuples{
+				{0},
+				{3},
+				{4},
+				{4},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{0},
+			rightOutCols:  []uint32{0},
+			joinType:      sqlbase.JoinType_RIGHT_OUTER,
+			expectedTuples: tuples{
+				{nil, 0},
+				{3, 3},
+				{3, 3},
+				{nil, 4},
+				{nil, 4},
+			},
+		},
+		{
+			description: "full outer join with NULL equality columns",
+			leftTypes:   []types.T{types.Int64},
+			rightTypes:  []types.T{types.Int64},
+			leftTuples: tuples{
+				{nil},
+				{nil},
+				{1},
+				{2},
+				{4},
+			},
+			rightTuples: tuples{
+				{nil},
+				{2},
+				{3},
+				{4},
+				{5},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{0},
+			rightOutCols:  []uint32{0},
+			joinType:      sqlbase.JoinType_FULL_OUTER,
+			expectedTuples: tuples{
+				{nil, nil},
+				{nil, nil},
+				{nil, nil},
+				{1, nil},
+				{2, 2},
+				{nil, 3},
+				{4, 4},
+				{nil, 5},
+			},
+		},
+		{
+			description: "left semi join",
+			leftTypes:   []types.T{types.Int64, types.Int64},
+			rightTypes:  []types.T{types.Int64},
+			leftTuples: tuples{
+				{nil, 0},
+				{1, 10},
+				{2, 20},
+				{2, 21},
+				{3, 30},
+			},
+			rightTuples: tuples{
+				{nil},
+				{2},
+				{2},
+				{3},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{1},
+			joinType:      sqlbase.JoinType_LEFT_SEMI,
+			expectedTuples: tuples{
+				{20},
+				{21},
+				{30},
+			},
+		},
+		{
+			description: "left anti join",
+			leftTypes:   []types.T{types.Int64, types.Int64},
+			rightTypes:  []types.T{types.Int64},
+			leftTuples: tuples{
+				{nil, 0},
+				{1, 10},
+				{2, 20},
+				{2, 21},
+				{3, 30},
+				{4, 40},
+			},
+			rightTuples: tuples{
+				{nil},
+				{2},
+				{2},
+				{3},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{1},
+			joinType:      sqlbase.JoinType_LEFT_ANTI,
+			expectedTuples: tuples{
+				{0},
+				{10},
+				{40},
+			},
+		},
+		{
+			description: "descending orderings on multiple equality columns",
+			leftTypes:   []types.T{types.Int64, types.Bytes, types.Float64},
+			rightTypes:  []types.T{types.Bytes, types.Int64},
+			leftTuples: tuples{
+				{3, "b", 0.3},
+				{3, "a", 0.1},
+				{3, "a", 0.2},
+				{2, "b", 1.0},
+				{1, "c", 2.0},
+			},
+			rightTuples: tuples{
+				{"b", 3},
+				{"a", 3},
+				{"c", 2},
+				{"b", 2},
+				{"a", 1},
+			},
+			leftOrdering: []distsqlpb.Ordering_Column{
+				{ColIdx: 0, Direction: distsqlpb.Ordering_Column_DESC},
+				{ColIdx: 1, Direction: distsqlpb.Ordering_Column_DESC},
+			},
+			rightOrdering: []distsqlpb.Ordering_Column{
+				{ColIdx: 1, Direction: distsqlpb.Ordering_Column_DESC},
+				{ColIdx: 0, Direction: distsqlpb.Ordering_Column_DESC},
+			},
+			leftOutCols:  []uint32{2},
+			rightOutCols: []uint32{0, 1},
+			expectedTuples: tuples{
+				{0.3, "b", 3},
+				{0.1, "a", 3},
+				{0.2, "a", 3},
+				{1.0, "b", 2},
+			},
+		},
+		{
+			description: "NULL output columns",
+			leftTypes:   []types.T{types.Int64, types.Int64},
+			rightTypes:  []types.T{types.Int64, types.Int64},
+			leftTuples: tuples{
+				{1, nil},
+				{1, 11},
+				{2, nil},
+			},
+			rightTuples: tuples{
+				{1, nil},
+				{2, 20},
+				{3, nil},
+			},
+			leftOrdering:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			rightOrdering: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+			leftOutCols:   []uint32{1},
+			rightOutCols:  []uint32{1},
+			joinType:      sqlbase.JoinType_FULL_OUTER,
+			expectedTuples: tuples{
+				{nil, nil},
+				{11, nil},
+				{nil, 20},
+				{nil, nil},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		inputs := []tuples{tc.leftTuples, tc.rightTuples}
+		for _, outBatchSize := range []uint16{1, 3, ColBatchSize} {
+			t.Run(fmt.Sprintf("%s/outBatchSize=%d", tc.description, outBatchSize), func(t *testing.T) {
+				runTests(t, inputs, nil, func(t *testing.T, sources []Operator) {
+					op, err := NewMergeJoinOp(
+						tc.joinType, sources[0], sources[1], tc.leftOutCols, tc.rightOutCols,
+						tc.leftTypes, tc.rightTypes, tc.leftOrdering, tc.rightOrdering,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+					mj := op.(*mergeJoinOp)
+					mj.initWithOutputBatchSize(outBatchSize)
+
+					cols := make([]int, 0, len(tc.leftOutCols)+len(tc.rightOutCols))
+					for _, colIdx := range tc.leftOutCols {
+						cols = append(cols, int(colIdx))
+					}
+					for _, colIdx := range tc.rightOutCols {
+						cols = append(cols, int(colIdx)+len(tc.leftTypes))
+					}
+
+					// opTestOutput initializes its input, so wrap the merge joiner to
+					// preserve the output batch size that was set above.
+					out := newOpTestOutput(&noopInitOp{Operator: mj}, cols, tc.expectedTuples)
+					if err := out.Verify(); err != nil {
+						t.Fatal(err)
+					}
+				})
+			})
+		}
+	}
+}
+
+// TestMergeJoinerLongGroups tests groups that span many input and output
+// batches.
+func TestMergeJoinerLongGroups(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const nLeft, nRight = 300, 7
+	var leftTuples, rightTuples, expected tuples
+	leftTuples = append(leftTuples, tuple{0, -1})
+	for i := 0; i < nLeft; i++ {
+		leftTuples = append(leftTuples, tuple{1, i})
+	}
+	for i := 0; i < nRight; i++ {
+		rightTuples = append(rightTuples, tuple{1, i})
+	}
+	rightTuples = append(rightTuples, tuple{2, -1})
+	expected = append(expected, tuple{-1, nil})
+	for i := 0; i < nLeft; i++ {
+		for j := 0; j < nRight; j++ {
+			expected = append(expected, tuple{i, j})
+		}
+	}
+	expected = append(expected, tuple{nil, -1})
+
+	runTests(t, []tuples{leftTuples, rightTuples}, nil, func(t *testing.T, sources []Operator) {
+		ordering := []distsqlpb.Ordering_Column{{ColIdx: 0}}
+		mj, err := NewMergeJoinOp(
+			sqlbase.JoinType_FULL_OUTER, sources[0], sources[1], []uint32{1}, []uint32{1},
+			[]types.T{types.Int64, types.Int64}, []types.T{types.Int64, types.Int64},
+			ordering, ordering,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := newOpTestOutput(mj, []int{1, 3}, expected)
+		if err := out.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// noopInitOp is an Operator that doesn't initialize its input.
+type noopInitOp struct {
+	Operator
+}
+
+func (n *noopInitOp) Init() {}
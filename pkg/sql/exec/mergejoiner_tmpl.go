@@ -0,0 +1,187 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for mergejoiner.eg.go. It's formatted in a
+// special way, so it's both valid Go and a valid text/template input. This
+// permits editing this file with editor support.
+//
+// */}}
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// {{/*
+
+// Declarations to make the template compile properly.
+
+// Dummy import to pull in "bytes" package.
+var _ bytes.Buffer
+
+// Dummy import to pull in "tree" package.
+var _ tree.Datum
+
+// _TYPES_T is the template type variable for types.T. It will be replaced by
+// types.Foo for each type Foo in the types.T type.
+const _TYPES_T = types.Unhandled
+
+// _ASSIGN_EQ is the template equality function for assigning the first input
+// to the result of the second input == the third input.
+func _ASSIGN_EQ(_, _, _ string) bool {
+	panic("")
+}
+
+// _ASSIGN_LT is the template function for assigning the first input to the
+// result of the second input < the third input.
+func _ASSIGN_LT(_, _, _ string) bool {
+	panic("")
+}
+
+// */}}
+
+// mjCompareValues returns a negative number, 0 or a positive number if the
+// value of a at aIdx is respectively smaller than, equal to or greater than the
+// value of b at bIdx. Both values must be non-null.
+func mjCompareValues(t types.T, a ColVec, aIdx uint64, b ColVec, bIdx uint64) int {
+	switch t {
+	// {{range .}}
+	case _TYPES_T:
+		aVal, bVal := a._TemplateType()[aIdx], b._TemplateType()[bIdx]
+		var eq, lt bool
+		_ASSIGN_EQ("eq", "aVal", "bVal")
+		if eq {
+			return 0
+		}
+		_ASSIGN_LT("lt", "aVal", "bVal")
+		if lt {
+			return -1
+		}
+		return 1
+	// {{end}}
+	default:
+		panic(fmt.Sprintf("unhandled type %d", t))
+	}
+}
+
+// mjRunEnd returns the first index in [start, end) at which the value of vec
+// (filtered by sel, if non-nil) differs from the value of keyVec at keyIdx, or
+// end if there is no such index. The key must be non-null, and NULL values in
+// vec are considered to differ from it.
+func mjRunEnd(
+	t types.T, keyVec ColVec, keyIdx uint64, vec ColVec, sel []uint16, start, end uint64,
+) uint64 {
+	hasNulls := vec.HasNulls()
+	switch t {
+	// {{range .}}
+	case _TYPES_T:
+		key := keyVec._TemplateType()[keyIdx]
+		col := vec._TemplateType()
+		if sel != nil {
+			for i := start; i < end; i++ {
+				idx := sel[i]
+				if hasNulls && vec.NullAt(idx) {
+					return i
+				}
+				var eq bool
+				_ASSIGN_EQ("eq", "col[idx]", "key")
+				if !eq {
+					return i
+				}
+			}
+		} else {
+			for i := start; i < end; i++ {
+				if hasNulls && vec.NullAt64(i) {
+					return i
+				}
+				var eq bool
+				_ASSIGN_EQ("eq", "col[i]", "key")
+				if !eq {
+					return i
+				}
+			}
+		}
+		return end
+	// {{end}}
+	default:
+		panic(fmt.Sprintf("unhandled type %d", t))
+	}
+}
+
+// mjCopyRepeated sets the n values of dst starting at dstStart to the value of
+// src at srcIdx.
+func mjCopyRepeated(t types.T, src ColVec, srcIdx uint64, dst ColVec, dstStart, n uint16) {
+	if src.HasNulls() && src.NullAt64(srcIdx) {
+		for i := dstStart; i < dstStart+n; i++ {
+			dst.SetNull(i)
+		}
+		return
+	}
+	switch t {
+	// {{range .}}
+	case _TYPES_T:
+		val := src._TemplateType()[srcIdx]
+		out := dst._TemplateType()[dstStart : dstStart+n]
+		for i := range out {
+			out[i] = val
+		}
+	// {{end}}
+	default:
+		panic(fmt.Sprintf("unhandled type %d", t))
+	}
+}
+
+// mjCopyRange copies the n values of src (filtered by sel, if non-nil)
+// starting at srcStart to dst, starting at dstStart.
+func mjCopyRange(
+	t types.T, src ColVec, sel []uint16, srcStart uint64, dst ColVec, dstStart, n uint16,
+) {
+	srcEnd := srcStart + uint64(n)
+	switch t {
+	// {{range .}}
+	case _TYPES_T:
+		from := src._TemplateType()
+		out := dst._TemplateType()[dstStart : dstStart+n]
+		if sel != nil {
+			for i, idx := range sel[srcStart:srcEnd] {
+				out[i] = from[idx]
+			}
+		} else {
+			copy(out, from[srcStart:srcEnd])
+		}
+	// {{end}}
+	default:
+		panic(fmt.Sprintf("unhandled type %d", t))
+	}
+
+	if src.HasNulls() {
+		for i := uint16(0); i < n; i++ {
+			srcIdx := srcStart + uint64(i)
+			if sel != nil {
+				srcIdx = uint64(sel[srcIdx])
+			}
+			if src.NullAt64(srcIdx) {
+				dst.SetNull(dstStart + i)
+			}
+		}
+	}
+}
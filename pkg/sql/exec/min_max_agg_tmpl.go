@@ -0,0 +1,214 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for min_max_agg.eg.go. It's formatted in a
+// special way, so it's both valid Go and a valid text/template input. This
+// permits editing this file with editor support.
+//
+// */}}
+
+package exec
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/pkg/errors"
+)
+
+// {{/*
+
+// Declarations to make the template compile properly.
+
+// Dummy import to pull in "bytes" package.
+var _ bytes.Buffer
+
+// Dummy import to pull in "apd" package.
+var _ apd.Decimal
+
+// Dummy import to pull in "time" package.
+var _ time.Time
+
+// Dummy import to pull in "duration" package.
+var _ duration.Duration
+
+// Dummy import to pull in "json" package.
+var _ json.JSON
+
+// Dummy import to pull in "tree" package.
+var _ tree.Datum
+
+// _GOTYPE is the template Go type variable for this operator. It will be
+// replaced by the Go type equivalent for each type in types.T, for example
+// int64 for types.Int64.
+type _GOTYPE interface{}
+
+// _TYPES_T is the template type variable for types.T. It will be replaced by
+// types.Foo for each type Foo in the types.T type.
+const _TYPES_T = types.Unhandled
+
+// _ASSIGN_CMP is the template function for assigning true to the first input
+// if the second input compares successfully to the third input. The comparison
+// operator is tree.LT for MIN and is tree.GT for MAX.
+func _ASSIGN_CMP(_, _, _ string) bool {
+	panic("")
+}
+
+// */}}
+
+// {{range .}} {{/* for each aggregation (min and max) */}}
+// {{$agg := .AggNameLower}} {{$title := .AggNameTitle}}
+
+func new_AGG_TITLEAgg(t types.T) (aggregateFunc, error) {
+	switch t {
+	// {{range .Overloads}}
+	case _TYPES_T:
+		return &_AGG_TYPEAgg{}, nil
+	// {{end}}
+	default:
+		return nil, errors.Errorf("unsupported _AGG agg type %s", t)
+	}
+}
+
+// {{range .Overloads}}
+
+// _AGG_TYPEAgg implements the _AGG_TITLE aggregate on _TYPE values. NULL
+// values are ignored, and a group that consists only of NULL values results in
+// NULL.
+type _AGG_TYPEAgg struct {
+	done   bool
+	groups []bool
+	curIdx int
+	// vec points to the output vector we are updating. The value of the
+	// current group is stored at curIdx until the group is finished.
+	vec []_GOTYPE
+	// nulls points to the null bitmap of the output vector.
+	nulls Nulls
+	// foundNonNullForCurrentGroup tracks whether we have seen a non-null value
+	// for the group that is currently being aggregated.
+	foundNonNullForCurrentGroup bool
+}
+
+var _ aggregateFunc = &_AGG_TYPEAgg{}
+
+func (a *_AGG_TYPEAgg) Init(groups []bool, v ColVec) {
+	a.groups = groups
+	a.vec = v._TemplateType()
+	a.nulls = v
+	a.Reset()
+}
+
+func (a *_AGG_TYPEAgg) Reset() {
+	a.done = false
+	a.curIdx = -1
+	a.foundNonNullForCurrentGroup = false
+	a.nulls.UnsetNulls()
+}
+
+func (a *_AGG_TYPEAgg) CurrentOutputIndex() int {
+	return a.curIdx
+}
+
+func (a *_AGG_TYPEAgg) SetOutputIndex(idx int) {
+	if a.curIdx != -1 {
+		a.curIdx = idx
+	}
+}
+
+// finishGroup sets the null value of the output for the current group, which
+// is NULL iff the group had no non-null input values.
+func (a *_AGG_TYPEAgg) finishGroup() {
+	if a.curIdx < 0 {
+		return
+	}
+	if a.foundNonNullForCurrentGroup {
+		a.nulls.UnsetNull64(uint64(a.curIdx))
+	} else {
+		a.nulls.SetNull64(uint64(a.curIdx))
+	}
+}
+
+func (a *_AGG_TYPEAgg) Compute(b ColBatch, inputIdxs []uint32) {
+	if a.done {
+		return
+	}
+	inputLen := b.Length()
+	if inputLen == 0 {
+		// The aggregation is finished. Flush the last value.
+		a.finishGroup()
+		a.curIdx++
+		a.done = true
+		return
+	}
+	vec, sel := b.ColVec(int(inputIdxs[0])), b.Selection()
+	col, hasNulls := vec._TemplateType(), vec.HasNulls()
+	if sel != nil {
+		sel = sel[:inputLen]
+		for _, i := range sel {
+			if a.groups[i] {
+				a.finishGroup()
+				a.curIdx++
+				a.foundNonNullForCurrentGroup = false
+			}
+			if hasNulls && vec.NullAt(i) {
+				continue
+			}
+			candidate := col[i]
+			if !a.foundNonNullForCurrentGroup {
+				a.vec[a.curIdx] = candidate
+				a.foundNonNullForCurrentGroup = true
+				continue
+			}
+			var cmp bool
+			_ASSIGN_CMP("cmp", "candidate", "a.vec[a.curIdx]")
+			if cmp {
+				a.vec[a.curIdx] = candidate
+			}
+		}
+	} else {
+		col = col[:inputLen]
+		for i := range col {
+			if a.groups[i] {
+				a.finishGroup()
+				a.curIdx++
+				a.foundNonNullForCurrentGroup = false
+			}
+			if hasNulls && vec.NullAt(uint16(i)) {
+				continue
+			}
+			candidate := col[i]
+			if !a.foundNonNullForCurrentGroup {
+				a.vec[a.curIdx] = candidate
+				a.foundNonNullForCurrentGroup = true
+				continue
+			}
+			var cmp bool
+			_ASSIGN_CMP("cmp", "candidate", "a.vec[a.curIdx]")
+			if cmp {
+				a.vec[a.curIdx] = candidate
+			}
+		}
+	}
+}
+
+// {{end}}
+// {{end}}
@@ -28,6 +28,14 @@ type Operator interface {
 	Next() ColBatch
 }
 
+// Closer is implemented by operators that hold resources, such as temporary
+// disk storage, which must be released once the operator is no longer used.
+type Closer interface {
+	// Close releases the resources held by the operator. It may be called at
+	// any point, including before the operator is finished, and more than once.
+	Close()
+}
+
 type noopOperator struct {
 	input Operator
 }
@@ -0,0 +1,92 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for rank.eg.go. It's formatted in a
+// special way, so it's both valid Go and a valid text/template input. This
+// permits editing this file with editor support.
+//
+// */}}
+
+package exec
+
+import "github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+
+// {{range .}}
+
+// _RANK_STRINGOp computes the _RANK_NAME window function. Its input is
+// ordered on the partitioning and ordering columns, and the distinct operators
+// that it's set up on mark the start of every partition and peer group.
+type _RANK_STRINGOp struct {
+	*rankBase
+}
+
+var _ Operator = &_RANK_STRINGOp{}
+
+func (r *_RANK_STRINGOp) Next() ColBatch {
+	batch := r.input.Next()
+	if batch.Length() == 0 {
+		return batch
+	}
+	if r.outputColIdx == batch.Width() {
+		batch.AppendCol(types.Int64)
+	}
+	rankCol := batch.ColVec(r.outputColIdx).Int64()
+	sel := batch.Selection()
+	if sel != nil {
+		for _, i := range sel[:batch.Length()] {
+			_COMPUTE_RANK(r, rankCol, i)
+		}
+	} else {
+		for i := uint16(0); i < batch.Length(); i++ {
+			_COMPUTE_RANK(r, rankCol, i)
+		}
+	}
+	// Zero out the boolean columns, since the distinct operators that compute
+	// them or their results with their previous contents.
+	// {{ if .HasPartition }}
+	copy(r.partitionCol, zeroBoolVec)
+	// {{ end }}
+	copy(r.peersCol, zeroBoolVec)
+	return batch
+}
+
+// {{end}}
+
+// {{/*
+func _COMPUTE_RANK(r *_RANK_STRINGOp, rankCol []int64, i uint16) { // */}}
+	// {{define "computeRank"}}
+	// {{ if .HasPartition }}
+	if r.partitionCol[i] {
+		r.rank = 0
+		r.rankIncrement = 1
+	}
+	// {{ end }}
+	if r.peersCol[i] {
+		r.rank += r.rankIncrement
+		r.rankIncrement = 0
+	}
+	rankCol[i] = r.rank
+	// {{ if .IsDense }}
+	r.rankIncrement = 1
+	// {{ else }}
+	r.rankIncrement++
+	// {{ end }}
+	// {{end}}
+	// {{/*
+}
+
+// */}}
@@ -0,0 +1,78 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// {{/*
+// +build execgen_template
+//
+// This file is the execgen template for row_number.eg.go. It's formatted in a
+// special way, so it's both valid Go and a valid text/template input. This
+// permits editing this file with editor support.
+//
+// */}}
+
+package exec
+
+import "github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+
+// {{range .}}
+
+// _ROW_NUMBER_STRINGOp computes the row_number window function. Its input is
+// ordered on the partitioning columns, if any, and the distinct operators that
+// it's set up on mark the start of every partition.
+type _ROW_NUMBER_STRINGOp struct {
+	rowNumberBase
+}
+
+var _ Operator = &_ROW_NUMBER_STRINGOp{}
+
+func (r *_ROW_NUMBER_STRINGOp) Next() ColBatch {
+	batch := r.input.Next()
+	if batch.Length() == 0 {
+		return batch
+	}
+	if r.outputColIdx == batch.Width() {
+		batch.AppendCol(types.Int64)
+	}
+	rowNumberCol := batch.ColVec(r.outputColIdx).Int64()
+	sel := batch.Selection()
+	if sel != nil {
+		for _, i := range sel[:batch.Length()] {
+			// {{ if .HasPartition }}
+			if r.partitionCol[i] {
+				r.rowNumber = 0
+			}
+			// {{ end }}
+			r.rowNumber++
+			rowNumberCol[i] = r.rowNumber
+		}
+	} else {
+		for i := range rowNumberCol[:batch.Length()] {
+			// {{ if .HasPartition }}
+			if r.partitionCol[i] {
+				r.rowNumber = 0
+			}
+			// {{ end }}
+			r.rowNumber++
+			rowNumberCol[i] = r.rowNumber
+		}
+	}
+	// {{ if .HasPartition }}
+	// Zero out partitionCol, since the distinct operators that compute it or
+	// their results with its previous contents.
+	copy(r.partitionCol, zeroBoolVec)
+	// {{ end }}
+	return batch
+}
+
+// {{end}}
@@ -18,9 +18,11 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 )
@@ -82,6 +84,30 @@ func TestSort(t *testing.T) {
 			typ:      []types.T{types.Float64},
 			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
 		},
+		{
+			tuples: tuples{
+				{time.Unix(3, 0)}, {time.Unix(1, 0)}, {time.Unix(2, 0)},
+			},
+			expected: tuples{
+				{time.Unix(1, 0)}, {time.Unix(2, 0)}, {time.Unix(3, 0)},
+			},
+			typ:     []types.T{types.Timestamp},
+			ordCols: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+		},
+		{
+			tuples: tuples{
+				{duration.Duration{Months: 1}},
+				{duration.Duration{Days: 31}},
+				{duration.Duration{Nanos: 1}},
+			},
+			expected: tuples{
+				{duration.Duration{Nanos: 1}},
+				{duration.Duration{Months: 1}},
+				{duration.Duration{Days: 31}},
+			},
+			typ:     []types.T{types.Interval},
+			ordCols: []distsqlpb.Ordering_Column{{ColIdx: 0}},
+		},
 
 		{
 			tuples:   tuples{{0, 1, 0}, {1, 2, 0}, {2, 3, 2}, {3, 7, 1}, {4, 2, 2}},
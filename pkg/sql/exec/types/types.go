@@ -17,11 +17,14 @@ package types
 import (
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/pkg/errors"
 )
 
@@ -49,6 +52,12 @@ const (
 	Float32
 	// Float64 is a column of type float64
 	Float64
+	// Timestamp is a column of type time.Time
+	Timestamp
+	// Interval is a column of type duration.Duration
+	Interval
+	// JSON is a column of type json.JSON
+	JSON
 
 	// Unhandled is a temporary value that represents an unhandled type.
 	// TODO(jordan): this should be replaced by a panic once all types are
@@ -70,7 +79,8 @@ func FromColumnType(ct sqlbase.ColumnType) T {
 	switch ct.SemanticType {
 	case sqlbase.ColumnType_BOOL:
 		return Bool
-	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME:
+	case sqlbase.ColumnType_BYTES, sqlbase.ColumnType_STRING, sqlbase.ColumnType_NAME,
+		sqlbase.ColumnType_UUID:
 		return Bytes
 	case sqlbase.ColumnType_DATE, sqlbase.ColumnType_OID:
 		return Int64
@@ -90,7 +100,15 @@ func FromColumnType(ct sqlbase.ColumnType) T {
 		panic(fmt.Sprintf("integer with unknown width %d", ct.Width))
 	case sqlbase.ColumnType_FLOAT:
 		return Float64
+	case sqlbase.ColumnType_TIMESTAMP, sqlbase.ColumnType_TIMESTAMPTZ:
+		return Timestamp
+	case sqlbase.ColumnType_INTERVAL:
+		return Interval
+	case sqlbase.ColumnType_JSONB:
+		return JSON
 	}
+	// Collated strings are intentionally left unhandled: their ordering is
+	// defined by the collation rather than by their bytes.
 	return Unhandled
 }
 
@@ -128,6 +146,12 @@ func FromGoType(v interface{}) T {
 		return Bytes
 	case apd.Decimal:
 		return Decimal
+	case time.Time:
+		return Timestamp
+	case duration.Duration:
+		return Interval
+	case json.JSON:
+		return JSON
 	default:
 		panic(fmt.Sprintf("type %T not supported yet", t))
 	}
@@ -154,6 +178,12 @@ func (t T) GoTypeName() string {
 		return "float32"
 	case Float64:
 		return "float64"
+	case Timestamp:
+		return "time.Time"
+	case Interval:
+		return "duration.Duration"
+	case JSON:
+		return "json.JSON"
 	default:
 		panic(fmt.Sprintf("unhandled type %d", t))
 	}
@@ -267,6 +297,46 @@ func GetDatumToPhysicalFn(ct sqlbase.ColumnType) func(tree.Datum) (interface{},
 			}
 			return d.Decimal, nil
 		}
+	case sqlbase.ColumnType_TIMESTAMP:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DTimestamp)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DTimestamp, found %s", reflect.TypeOf(datum))
+			}
+			return d.Time, nil
+		}
+	case sqlbase.ColumnType_TIMESTAMPTZ:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DTimestampTZ)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DTimestampTZ, found %s", reflect.TypeOf(datum))
+			}
+			return d.Time, nil
+		}
+	case sqlbase.ColumnType_INTERVAL:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DInterval)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DInterval, found %s", reflect.TypeOf(datum))
+			}
+			return d.Duration, nil
+		}
+	case sqlbase.ColumnType_UUID:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DUuid)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DUuid, found %s", reflect.TypeOf(datum))
+			}
+			return d.UUID.GetBytes(), nil
+		}
+	case sqlbase.ColumnType_JSONB:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DJSON)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DJSON, found %s", reflect.TypeOf(datum))
+			}
+			return d.JSON, nil
+		}
 	}
 	panic(fmt.Sprintf("unhandled ColumnType %s", ct.String()))
 }
@@ -15,13 +15,19 @@
 package exec
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
 	"sort"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
 	"github.com/pkg/errors"
 )
@@ -278,6 +284,27 @@ func (r *opTestOutput) Verify() error {
 	return assertTuplesEquals(r.expected, actual)
 }
 
+// VerifyAnyOrder is like Verify, except that the tuples produced by the input
+// may be in any order.
+func (r *opTestOutput) VerifyAnyOrder() error {
+	var actual tuples
+	for {
+		tup := r.next()
+		if tup == nil {
+			break
+		}
+		actual = append(actual, tup)
+	}
+	expected := append(tuples(nil), r.expected...)
+	for _, tups := range []tuples{expected, actual} {
+		tups := tups
+		sort.SliceStable(tups, func(i, j int) bool {
+			return fmt.Sprint(tups[i]) < fmt.Sprint(tups[j])
+		})
+	}
+	return assertTuplesEquals(expected, actual)
+}
+
 // assertTupleEquals asserts that two tuples are equal, using a slow,
 // reflection-based method to do the assertion. Reflection is used so that
 // values can be compared in a type-agnostic way.
@@ -312,6 +339,62 @@ func assertTuplesEquals(expected tuples, actual tuples) error {
 	return nil
 }
 
+// testExternalStorage contains the memory account and the temporary disk
+// storage used by the tests of operators that spill to disk.
+type testExternalStorage struct {
+	memMonitor  mon.BytesMonitor
+	memAcc      mon.BoundAccount
+	diskMonitor mon.BytesMonitor
+	tempEngine  engine.MapProvidingEngine
+}
+
+// newTestExternalStorage returns a testExternalStorage whose memory account
+// can hold at most memLimit bytes, or is unlimited if memLimit is 0.
+func newTestExternalStorage(t *testing.T, memLimit int64) *testExternalStorage {
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	tempEngine, err := engine.NewTempEngine(
+		base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testExternalStorage{tempEngine: tempEngine}
+	s.memMonitor = mon.MakeMonitorWithLimit(
+		"test-mem",
+		mon.MemoryResource,
+		memLimit,
+		nil, /* curCount */
+		nil, /* maxHist */
+		1,   /* increment */
+		math.MaxInt64,
+		st,
+	)
+	s.memMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	s.memAcc = s.memMonitor.MakeBoundAccount()
+	s.diskMonitor = mon.MakeMonitor(
+		"test-disk",
+		mon.DiskResource,
+		nil, /* curCount */
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+		st,
+	)
+	s.diskMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	return s
+}
+
+// close releases the storage. The operators that used it must have been closed
+// already.
+func (s *testExternalStorage) close() {
+	ctx := context.Background()
+	s.memAcc.Close(ctx)
+	s.memMonitor.Stop(ctx)
+	s.diskMonitor.Stop(ctx)
+	s.tempEngine.Close()
+}
+
 // repeatableBatchSource is an Operator that returns the same batch forever.
 type repeatableBatchSource struct {
 	internalBatch ColBatch
@@ -0,0 +1,150 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import "github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+
+// NewRowNumberOperator creates a new Operator that computes the row_number
+// window function. The input must be ordered on the partitioning columns
+// partitionCols (if any), and the result is written to the Int64 column at
+// outputColIdx, which is appended to the batch if it is equal to its width.
+func NewRowNumberOperator(
+	input Operator, partitionCols []uint32, partitionTyps []types.T, outputColIdx int,
+) (Operator, error) {
+	if len(partitionCols) == 0 {
+		return &rowNumberNoPartitionOp{
+			rowNumberBase: rowNumberBase{input: input, outputColIdx: outputColIdx},
+		}, nil
+	}
+	op, partitionCol, err := orderedDistinctColsToOperators(input, partitionCols, partitionTyps)
+	if err != nil {
+		return nil, err
+	}
+	return &rowNumberWithPartitionOp{
+		rowNumberBase: rowNumberBase{
+			input:        op,
+			outputColIdx: outputColIdx,
+			partitionCol: partitionCol,
+		},
+	}, nil
+}
+
+// rowNumberBase is the state shared by all of the row_number operators.
+type rowNumberBase struct {
+	input        Operator
+	outputColIdx int
+
+	// partitionCol is the boolean column that is set to true for every row that
+	// starts a new partition. It is nil if there are no partitioning columns.
+	partitionCol []bool
+
+	// rowNumber is the row number of the last row that was processed.
+	rowNumber int64
+}
+
+func (r *rowNumberBase) Init() {
+	r.input.Init()
+}
+
+// NewRankOperator creates a new Operator that computes the rank window
+// function, or the dense_rank window function if dense is set. The input must
+// be ordered on the partitioning columns partitionCols (if any) followed by the
+// ordering columns orderingCols, and the result is written to the Int64 column
+// at outputColIdx, which is appended to the batch if it is equal to its width.
+func NewRankOperator(
+	input Operator,
+	dense bool,
+	partitionCols []uint32,
+	partitionTyps []types.T,
+	orderingCols []uint32,
+	orderingTyps []types.T,
+	outputColIdx int,
+) (Operator, error) {
+	r := &rankBase{outputColIdx: outputColIdx, rankIncrement: 1}
+	var err error
+	if len(partitionCols) > 0 {
+		input, r.partitionCol, err = orderedDistinctColsToOperators(
+			input, partitionCols, partitionTyps,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Two rows are peers if they are equal on both the partitioning and the
+	// ordering columns, so the peer groups are computed on top of the
+	// partitions.
+	peersCols := append(append([]uint32(nil), partitionCols...), orderingCols...)
+	peersTyps := append(append([]types.T(nil), partitionTyps...), orderingTyps...)
+	r.input, r.peersCol, err = orderedDistinctColsToOperators(input, peersCols, peersTyps)
+	if err != nil {
+		return nil, err
+	}
+	if len(peersCols) == 0 {
+		// If there are no columns, we can't rely on the distinct operators to mark
+		// the first row as the start of a peer group, so we have to do it
+		// ourselves. Set up a oneShotOp to mark the first row.
+		peersCol := r.peersCol
+		r.input = &oneShotOp{
+			input: r.input,
+			fn: func(batch ColBatch) {
+				if batch.Length() == 0 {
+					return
+				}
+				if sel := batch.Selection(); sel != nil {
+					peersCol[sel[0]] = true
+				} else {
+					peersCol[0] = true
+				}
+			},
+			outputSourceRef: &r.input,
+		}
+	}
+
+	switch {
+	case dense && len(partitionCols) > 0:
+		return &denseRankWithPartitionOp{rankBase: r}, nil
+	case dense:
+		return &denseRankNoPartitionOp{rankBase: r}, nil
+	case len(partitionCols) > 0:
+		return &rankWithPartitionOp{rankBase: r}, nil
+	default:
+		return &rankNoPartitionOp{rankBase: r}, nil
+	}
+}
+
+// rankBase is the state shared by all of the rank and dense_rank operators.
+type rankBase struct {
+	input        Operator
+	outputColIdx int
+
+	// partitionCol is the boolean column that is set to true for every row that
+	// starts a new partition. It is nil if there are no partitioning columns.
+	partitionCol []bool
+	// peersCol is the boolean column that is set to true for every row that
+	// starts a new peer group, that is, a group of rows that are equal on both
+	// the partitioning and the ordering columns.
+	peersCol []bool
+
+	// rank is the rank of the last row that was processed.
+	rank int64
+	// rankIncrement is the value to add to rank when the next peer group
+	// starts.
+	rankIncrement int64
+}
+
+func (r *rankBase) Init() {
+	r.input.Init()
+}
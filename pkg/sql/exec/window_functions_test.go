@@ -0,0 +1,160 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package exec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRowNumber(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tcs := []struct {
+		partitionCols []uint32
+		tuples        tuples
+		expected      tuples
+	}{
+		{
+			tuples:   tuples{{3}, {1}, {2}, {1}},
+			expected: tuples{{3, 1}, {1, 2}, {2, 3}, {1, 4}},
+		},
+		{
+			partitionCols: []uint32{0},
+			tuples:        tuples{{1}, {1}, {2}, {3}, {3}, {3}},
+			expected:      tuples{{1, 1}, {1, 2}, {2, 1}, {3, 1}, {3, 2}, {3, 3}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(fmt.Sprintf("partitionCols=%v", tc.partitionCols), func(t *testing.T) {
+			runTests(t, []tuples{tc.tuples}, nil, func(t *testing.T, input []Operator) {
+				partitionTyps := make([]types.T, len(tc.partitionCols))
+				for i := range partitionTyps {
+					partitionTyps[i] = types.Int64
+				}
+				op, err := NewRowNumberOperator(
+					input[0], tc.partitionCols, partitionTyps, 1, /* outputColIdx */
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out := newOpTestOutput(op, []int{0, 1}, tc.expected)
+				if err := out.Verify(); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
+
+func TestRank(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tcs := []struct {
+		dense         bool
+		partitionCols []uint32
+		orderingCols  []uint32
+		tuples        tuples
+		expected      tuples
+	}{
+		{
+			tuples:   tuples{{1, 1}, {2, 2}, {3, 3}},
+			expected: tuples{{1, 1, 1}, {2, 2, 1}, {3, 3, 1}},
+		},
+		{
+			dense:    true,
+			tuples:   tuples{{1, 1}, {2, 2}, {3, 3}},
+			expected: tuples{{1, 1, 1}, {2, 2, 1}, {3, 3, 1}},
+		},
+		{
+			orderingCols: []uint32{1},
+			tuples:       tuples{{1, 1}, {2, 1}, {1, 2}, {1, 3}, {2, 3}, {3, 3}, {1, 4}},
+			expected: tuples{
+				{1, 1, 1}, {2, 1, 1}, {1, 2, 3}, {1, 3, 4}, {2, 3, 4}, {3, 3, 4}, {1, 4, 7},
+			},
+		},
+		{
+			dense:        true,
+			orderingCols: []uint32{1},
+			tuples:       tuples{{1, 1}, {2, 1}, {1, 2}, {1, 3}, {2, 3}, {3, 3}, {1, 4}},
+			expected: tuples{
+				{1, 1, 1}, {2, 1, 1}, {1, 2, 2}, {1, 3, 3}, {2, 3, 3}, {3, 3, 3}, {1, 4, 4},
+			},
+		},
+		{
+			partitionCols: []uint32{0},
+			tuples:        tuples{{1, 1}, {1, 2}, {2, 1}, {3, 5}},
+			expected:      tuples{{1, 1, 1}, {1, 2, 1}, {2, 1, 1}, {3, 5, 1}},
+		},
+		{
+			partitionCols: []uint32{0},
+			orderingCols:  []uint32{1},
+			tuples: tuples{
+				{1, 1}, {1, 1}, {1, 2}, {2, 2}, {2, 2}, {2, 3}, {2, 3}, {2, 4}, {3, 4},
+			},
+			expected: tuples{
+				{1, 1, 1}, {1, 1, 1}, {1, 2, 3},
+				{2, 2, 1}, {2, 2, 1}, {2, 3, 3}, {2, 3, 3}, {2, 4, 5},
+				{3, 4, 1},
+			},
+		},
+		{
+			dense:         true,
+			partitionCols: []uint32{0},
+			orderingCols:  []uint32{1},
+			tuples: tuples{
+				{1, 1}, {1, 1}, {1, 2}, {2, 2}, {2, 2}, {2, 3}, {2, 3}, {2, 4}, {3, 4},
+			},
+			expected: tuples{
+				{1, 1, 1}, {1, 1, 1}, {1, 2, 2},
+				{2, 2, 1}, {2, 2, 1}, {2, 3, 2}, {2, 3, 2}, {2, 4, 3},
+				{3, 4, 1},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		name := fmt.Sprintf(
+			"dense=%t/partitionCols=%v/orderingCols=%v", tc.dense, tc.partitionCols, tc.orderingCols,
+		)
+		t.Run(name, func(t *testing.T) {
+			runTests(t, []tuples{tc.tuples}, nil, func(t *testing.T, input []Operator) {
+				partitionTyps := make([]types.T, len(tc.partitionCols))
+				for i := range partitionTyps {
+					partitionTyps[i] = types.Int64
+				}
+				orderingTyps := make([]types.T, len(tc.orderingCols))
+				for i := range orderingTyps {
+					orderingTyps[i] = types.Int64
+				}
+				op, err := NewRankOperator(
+					input[0], tc.dense, tc.partitionCols, partitionTyps, tc.orderingCols, orderingTyps,
+					2, /* outputColIdx */
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out := newOpTestOutput(op, []int{0, 1, 2}, tc.expected)
+				if err := out.Verify(); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
@@ -51,6 +51,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/util/bitarray"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -128,11 +130,23 @@ var OptimizerClusterMode = settings.RegisterEnumSetting(
 )
 
 // OptimizerMutationsClusterMode controls the cluster default for when the cost-
-// based optimizer is planning mutation statements.
+// based optimizer is planning mutation statements. It is on by default, and
+// can be turned off to plan UPDATE, UPSERT and DELETE statements with the
+// heuristic planner instead.
 var OptimizerMutationsClusterMode = settings.RegisterBoolSetting(
 	"sql.defaults.experimental_optimizer_mutations",
 	"default experimental_optimizer_mutations mode",
-	false,
+	true,
+)
+
+// OptimizerFKsClusterMode controls the cluster default for whether the cost-
+// based optimizer plans foreign key checks and cascades. It is on by default,
+// and can be turned off to perform the checks and cascades row by row in the
+// execution engine instead.
+var OptimizerFKsClusterMode = settings.RegisterBoolSetting(
+	"sql.defaults.optimizer_foreign_keys.enabled",
+	"default optimizer_foreign_keys mode",
+	true,
 )
 
 // VectorizeClusterMode controls the cluster default for when automatic
@@ -334,6 +348,12 @@ type nodeStatusGenerator interface {
 	GenerateNodeStatus(ctx context.Context) *statuspb.NodeStatus
 }
 
+// lockWaitsVisitor is a limited portion of the storage.Stores struct, to
+// avoid having to import all of storage in sql.
+type lockWaitsVisitor interface {
+	VisitLockWaits(func(roachpb.StoreID, roachpb.RangeID, locktable.WaitInfo) error) error
+}
+
 // An ExecutorConfig encompasses the auxiliary objects and configuration
 // required to create an executor.
 // All fields holding a pointer or an interface are required to create
@@ -351,6 +371,7 @@ type ExecutorConfig struct {
 	DistSQLSrv       *distsqlrun.ServerImpl
 	StatusServer     serverpb.StatusServer
 	MetricsRecorder  nodeStatusGenerator
+	LockWaits        lockWaitsVisitor
 	SessionRegistry  *SessionRegistry
 	JobRegistry      *jobs.Registry
 	VirtualSchemas   *VirtualSchemaHolder
@@ -709,13 +730,15 @@ func ParseHLC(s string) (hlc.Timestamp, error) {
 
 // isAsOf analyzes a statement to bypass the logic in newPlan(), since
 // that requires the transaction to be started already. If the returned
-// timestamp is not nil, it is the timestamp to which a transaction
-// should be set. The statements that will be checked are Select,
-// ShowTrace (of a Select statement), Scrub, Export, and CreateStats.
+// result is not nil, its timestamp is the timestamp to which a transaction
+// should be set, or, for a bounded staleness read, the minimum timestamp
+// at which the statement can be served. The statements that will be
+// checked are Select, ShowTrace (of a Select statement), Scrub, Export,
+// and CreateStats. Only Select statements can use bounded staleness.
 //
 // max is a lower bound on what the transaction's timestamp will be.
 // Used to check that the user didn't specify a timestamp in the future.
-func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp, error) {
+func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*tree.AsOfSystemTime, error) {
 	var asOf tree.AsOfClause
 	switch s := stmt.(type) {
 	case *tree.Select:
@@ -734,14 +757,19 @@ func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp
 			return nil, nil
 		}
 
-		asOf = sc.From.AsOf
+		asOfTS, err := tree.EvalAsOf(sc.From.AsOf, max, &p.semaCtx, p.EvalContext())
+		return &asOfTS, err
 	case *tree.Scrub:
 		if s.AsOf.Expr == nil {
 			return nil, nil
 		}
 		asOf = s.AsOf
 	case *tree.Export:
-		return p.isAsOf(s.Query, max)
+		asOfTS, err := p.isAsOf(s.Query, max)
+		if err == nil && asOfTS != nil && asOfTS.BoundedStaleness {
+			err = tree.ErrBoundedStalenessStatement
+		}
+		return asOfTS, err
 	case *tree.CreateStats:
 		if s.AsOf.Expr == nil {
 			return nil, nil
@@ -752,7 +780,7 @@ func (p *planner) isAsOf(stmt tree.Statement, max hlc.Timestamp) (*hlc.Timestamp
 	}
 
 	ts, err := p.EvalAsOfTimestamp(asOf, max)
-	return &ts, err
+	return &tree.AsOfSystemTime{Timestamp: ts}, err
 }
 
 // isSavepoint returns true if stmt is a SAVEPOINT statement.
@@ -855,6 +883,14 @@ func (r *SessionRegistry) deregister(id ClusterWideID) {
 	r.Unlock()
 }
 
+// hasSession returns true if the session with the given ID is registered.
+func (r *SessionRegistry) hasSession(id ClusterWideID) bool {
+	r.Lock()
+	defer r.Unlock()
+	_, ok := r.sessions[id]
+	return ok
+}
+
 type registrySession interface {
 	user() string
 	cancelQuery(queryID ClusterWideID) bool
@@ -943,15 +979,26 @@ func newSchemaInterface(tables *TableCollection, vt VirtualTabler) *schemaInterf
 const MaxSQLBytes = 1000
 
 type schemaChangerCollection struct {
-	schemaChangers []SchemaChanger
+	schemaChangers     []SchemaChanger
+	typeSchemaChangers []typeSchemaChanger
 }
 
 func (scc *schemaChangerCollection) queueSchemaChanger(schemaChanger SchemaChanger) {
 	scc.schemaChangers = append(scc.schemaChangers, schemaChanger)
 }
 
+func (scc *schemaChangerCollection) queueTypeSchemaChanger(schemaChanger typeSchemaChanger) {
+	scc.typeSchemaChangers = append(scc.typeSchemaChangers, schemaChanger)
+}
+
 func (scc *schemaChangerCollection) reset() {
 	scc.schemaChangers = nil
+	scc.typeSchemaChangers = nil
+}
+
+// empty returns whether no schema changers are queued.
+func (scc *schemaChangerCollection) empty() bool {
+	return len(scc.schemaChangers) == 0 && len(scc.typeSchemaChangers) == 0
 }
 
 // execSchemaChanges releases schema leases and runs the queued
@@ -965,7 +1012,7 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 	tracing *SessionTracing,
 	ieFactory sqlutil.SessionBoundInternalExecutorFactory,
 ) error {
-	if len(scc.schemaChangers) == 0 {
+	if scc.empty() {
 		return nil
 	}
 	if fn := cfg.SchemaChangerTestingKnobs.SyncFilter; fn != nil {
@@ -1013,6 +1060,15 @@ func (scc *schemaChangerCollection) execSchemaChanges(
 		}
 	}
 	scc.schemaChangers = nil
+	// The members added to types are made public once the schema changes of
+	// the tables of the types have been executed. If this fails, the
+	// SchemaChangeManager completes the type schema change asynchronously.
+	for _, sc := range scc.typeSchemaChangers {
+		if err := sc.exec(ctx); err != nil && err != ctx.Err() {
+			log.Warningf(ctx, "error executing type schema change: %s", err)
+		}
+	}
+	scc.typeSchemaChangers = nil
 	return firstError
 }
 
@@ -1662,6 +1718,10 @@ func (m *sessionDataMutator) SetDefaultReadOnly(val bool) {
 	m.data.DefaultReadOnly = val
 }
 
+func (m *sessionDataMutator) SetDefaultIsolation(val enginepb.IsolationType) {
+	m.data.DefaultIsolation = val
+}
+
 func (m *sessionDataMutator) SetDistSQLMode(val sessiondata.DistSQLExecMode) {
 	m.data.DistSQLMode = val
 }
@@ -1694,6 +1754,10 @@ func (m *sessionDataMutator) SetOptimizerMutations(val bool) {
 	m.data.OptimizerMutations = val
 }
 
+func (m *sessionDataMutator) SetOptimizerFKs(val bool) {
+	m.data.OptimizerFKs = val
+}
+
 func (m *sessionDataMutator) SetSerialNormalizationMode(val sessiondata.SerialNormalizationMode) {
 	m.data.SerialNormalizationMode = val
 }
@@ -1706,6 +1770,10 @@ func (m *sessionDataMutator) SetSearchPath(val sessiondata.SearchPath) {
 	m.data.SearchPath = val
 }
 
+func (m *sessionDataMutator) SetTemporarySchemaName(scName string) {
+	m.data.SearchPath = m.data.SearchPath.WithTemporarySchemaName(scName)
+}
+
 func (m *sessionDataMutator) SetLocation(loc *time.Location) {
 	m.data.DataConversion.Location = loc
 }
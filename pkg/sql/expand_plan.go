@@ -111,6 +111,11 @@ func doExpandPlan(
 	case *createTableNode:
 		n.sourcePlan, err = doExpandPlan(ctx, p, noParams, n.sourcePlan)
 
+	case *createViewNode:
+		if n.sourcePlan != nil {
+			n.sourcePlan, err = doExpandPlan(ctx, p, noParams, n.sourcePlan)
+		}
+
 	case *updateNode:
 		n.source, err = doExpandPlan(ctx, p, noParams, n.source)
 
@@ -286,6 +291,9 @@ func doExpandPlan(
 	case *max1RowNode:
 		n.plan, err = doExpandPlan(ctx, p, noParams, n.plan)
 
+	case *recursiveCTENode:
+		n.initial, err = doExpandPlan(ctx, p, noParams, n.initial)
+
 	case *sortNode:
 		if !n.ordering.IsPrefixOf(params.desiredOrdering) {
 			params.desiredOrdering = n.ordering
@@ -349,6 +357,7 @@ func doExpandPlan(
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *commentOnColumnNode:
 	case *commentOnDatabaseNode:
@@ -357,21 +366,25 @@ func doExpandPlan(
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *CreateUserNode:
-	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
 	case *DropUserNode:
 	case *zeroNode:
+	case *scanBufferNode:
 	case *unaryNode:
 	case *hookFnNode:
 		for i := range n.subplans {
@@ -383,6 +396,7 @@ func doExpandPlan(
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setZoneConfigNode:
 	case *showZoneConfigNode:
 	case *showFingerprintsNode:
@@ -671,6 +685,11 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *createTableNode:
 		n.sourcePlan = p.simplifyOrderings(n.sourcePlan, nil)
 
+	case *createViewNode:
+		if n.sourcePlan != nil {
+			n.sourcePlan = p.simplifyOrderings(n.sourcePlan, nil)
+		}
+
 	case *updateNode:
 		n.source = p.simplifyOrderings(n.source, nil)
 
@@ -760,6 +779,9 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *max1RowNode:
 		n.plan = p.simplifyOrderings(n.plan, usefulOrdering)
 
+	case *recursiveCTENode:
+		n.initial = p.simplifyOrderings(n.initial, nil)
+
 	case *spoolNode:
 		n.source = p.simplifyOrderings(n.source, usefulOrdering)
 
@@ -861,6 +883,7 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *commentOnColumnNode:
 	case *commentOnDatabaseNode:
@@ -869,26 +892,31 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *CreateUserNode:
-	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
 	case *DropUserNode:
 	case *zeroNode:
+	case *scanBufferNode:
 	case *unaryNode:
 	case *hookFnNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setZoneConfigNode:
 	case *showZoneConfigNode:
 	case *showFingerprintsNode:
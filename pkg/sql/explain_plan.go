@@ -35,6 +35,9 @@ type explainPlanNode struct {
 	// subqueryPlans contains the subquery plans for the explained query.
 	subqueryPlans []subquery
 
+	// postqueryPlans contains the postquery plans for the explained query.
+	postqueryPlans []planNode
+
 	// expanded indicates whether to invoke expandPlan() on the sub-node.
 	expanded bool
 
@@ -64,6 +67,7 @@ func (p *planner) makeExplainPlanNode(
 	}
 	return p.makeExplainPlanNodeWithPlan(
 		ctx, opts, true /* optimizeSubqueries */, plan, p.curPlan.subqueryPlans,
+		nil, /* postqueryPlans */
 	)
 }
 
@@ -75,6 +79,7 @@ func (p *planner) makeExplainPlanNodeWithPlan(
 	optimizeSubqueries bool,
 	plan planNode,
 	subqueryPlans []subquery,
+	postqueryPlans []planNode,
 ) (planNode, error) {
 	flags := explainFlags{
 		symbolicVars: opts.Flags.Contains(tree.ExplainFlagSymVars),
@@ -124,6 +129,7 @@ func (p *planner) makeExplainPlanNodeWithPlan(
 		optimizeSubqueries: optimizeSubqueries,
 		plan:               plan,
 		subqueryPlans:      subqueryPlans,
+		postqueryPlans:     postqueryPlans,
 		run: explainPlanRun{
 			results: p.newContainerValuesNode(columns, 0),
 		},
@@ -154,7 +160,9 @@ func (e *explainPlanNode) startExec(params runParams) error {
 		}
 	}
 
-	return params.p.populateExplain(params.ctx, &e.explainer, e.run.results, e.plan, e.subqueryPlans)
+	return params.p.populateExplain(
+		params.ctx, &e.explainer, e.run.results, e.plan, e.subqueryPlans, e.postqueryPlans,
+	)
 }
 
 func (e *explainPlanNode) Next(params runParams) (bool, error) { return e.run.results.Next(params) }
@@ -165,6 +173,9 @@ func (e *explainPlanNode) Close(ctx context.Context) {
 	for i := range e.subqueryPlans {
 		e.subqueryPlans[i].plan.Close(ctx)
 	}
+	for _, postqueryPlan := range e.postqueryPlans {
+		postqueryPlan.Close(ctx)
+	}
 	e.run.results.Close(ctx)
 }
 
@@ -219,12 +230,17 @@ type explainer struct {
 var emptyString = tree.NewDString("")
 
 // populateExplain walks the plan and generates rows in a valuesNode.
-// The subquery plans, if any are known to the planner, are printed
-// at the bottom.
+// The subquery and postquery plans, if any are known to the planner, are
+// printed at the bottom.
 func (p *planner) populateExplain(
-	ctx context.Context, e *explainer, v *valuesNode, plan planNode, subqueryPlans []subquery,
+	ctx context.Context,
+	e *explainer,
+	v *valuesNode,
+	plan planNode,
+	subqueryPlans []subquery,
+	postqueryPlans []planNode,
 ) error {
-	e.populateEntries(ctx, plan, subqueryPlans)
+	e.populateEntries(ctx, plan, subqueryPlans, postqueryPlans)
 
 	tp := treeprinter.New()
 	// n keeps track of the current node on each level.
@@ -274,24 +290,30 @@ func (p *planner) populateExplain(
 	return nil
 }
 
-func (e *explainer) populateEntries(ctx context.Context, plan planNode, subqueryPlans []subquery) {
+func (e *explainer) populateEntries(
+	ctx context.Context, plan planNode, subqueryPlans []subquery, postqueryPlans []planNode,
+) {
 	e.entries = nil
 	observer := e.observer()
-	_ = populateEntriesForObserver(ctx, plan, subqueryPlans, observer, false /* returnError */)
+	_ = populateEntriesForObserver(
+		ctx, plan, subqueryPlans, postqueryPlans, observer, false, /* returnError */
+	)
 }
 
 func populateEntriesForObserver(
 	ctx context.Context,
 	plan planNode,
 	subqueryPlans []subquery,
+	postqueryPlans []planNode,
 	observer planObserver,
 	returnError bool,
 ) error {
-	// If there are any subqueries in the plan, we enclose both the main
-	// plan and the sub-queries as children of a virtual "root"
-	// node. This is not introduced in the common case where there are
-	// no subqueries.
-	if len(subqueryPlans) > 0 {
+	// If there are any subqueries or postqueries in the plan, we enclose the
+	// main plan, the sub-queries and the postqueries as children of a virtual
+	// "root" node. This is not introduced in the common case where there are
+	// no subqueries or postqueries.
+	hasRoot := len(subqueryPlans) > 0 || len(postqueryPlans) > 0
+	if hasRoot {
 		if _, err := observer.enterNode(ctx, "root", plan); err != nil && returnError {
 			return err
 		}
@@ -324,7 +346,20 @@ func populateEntriesForObserver(
 		}
 	}
 
-	if len(subqueryPlans) > 0 {
+	// Explain the postqueries.
+	for _, postqueryPlan := range postqueryPlans {
+		if _, err := observer.enterNode(ctx, "postquery", postqueryPlan); err != nil && returnError {
+			return err
+		}
+		if err := walkPlan(ctx, postqueryPlan, observer); err != nil && returnError {
+			return err
+		}
+		if err := observer.leaveNode("postquery", postqueryPlan); err != nil && returnError {
+			return err
+		}
+	}
+
+	if hasRoot {
 		if err := observer.leaveNode("root", plan); err != nil && returnError {
 			return err
 		}
@@ -342,7 +377,7 @@ func planToString(ctx context.Context, plan planNode, subqueryPlans []subquery)
 		},
 		fmtFlags: tree.FmtExpr(tree.FmtSymbolicSubqueries, true, true, true),
 	}
-	e.populateEntries(ctx, plan, subqueryPlans)
+	e.populateEntries(ctx, plan, subqueryPlans, nil /* postqueryPlans */)
 	var buf bytes.Buffer
 	for _, e := range e.entries {
 		field := e.field
@@ -81,7 +81,9 @@ func planToTree(ctx context.Context, top planTop) *roachpb.ExplainTreePlanNode {
 		},
 	}
 
-	if err := populateEntriesForObserver(ctx, top.plan, top.subqueryPlans, observer, true /* returnError */); err != nil {
+	if err := populateEntriesForObserver(
+		ctx, top.plan, top.subqueryPlans, top.postqueryPlans, observer, true, /* returnError */
+	); err != nil {
 		panic(fmt.Sprintf("error while walking plan to save it to statement stats: %s", err.Error()))
 	}
 	return nodeStack.peek()
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -52,6 +53,14 @@ type groupNode struct {
 	// Indices of the group by columns in the source plan that have an ordering.
 	orderedGroupCols []int
 
+	// groupingSets are the grouping sets of a GROUP BY with ROLLUP, CUBE or
+	// GROUPING SETS, as sets of indices of group by columns in the source plan.
+	// If there are grouping sets, each input row is accumulated once for every
+	// set, and the ordinal of the set is available to the aggregation functions
+	// at groupingSetRenderIdx. Grouping sets are only planned by the optimizer
+	// and are incompatible with orderedGroupCols.
+	groupingSets []util.FastIntSet
+
 	// isScalar is set for "scalar groupby", where we want a result
 	// even if there are no input rows, e.g. SELECT MIN(x) FROM t.
 	isScalar bool
@@ -130,6 +139,12 @@ func (p *planner) groupBy(
 	for i, expr := range n.GroupBy {
 		expr = tree.StripParens(expr)
 
+		if _, ok := expr.(*tree.GroupingSet); ok {
+			// Grouping sets are only supported by the optimizer.
+			return nil, nil, pgerror.Unimplemented("grouping sets",
+				"GROUPING SETS, ROLLUP and CUBE are only supported by the cost-based optimizer")
+		}
+
 		// Check whether the GROUP BY clause refers to a rendered column
 		// (specified in the original query) by index, e.g. `SELECT a, SUM(b)
 		// FROM y GROUP BY 1`.
@@ -319,6 +334,16 @@ type groupRun struct {
 	lastOrderedGroupKey tree.Datums
 	consumedGroupKey    bool
 
+	// groupingSetIDs contains the ordinal of each grouping set.
+	groupingSetIDs tree.Datums
+	// groupingSetSkippedFuncs contains, for each grouping set, the indices of
+	// the any_not_null aggregations of group by columns that are not part of the
+	// set. These aggregations are not fed any values, so they produce NULL.
+	groupingSetSkippedFuncs []util.FastIntSet
+	// groupingSetValues is a scratch row that holds an input row extended with
+	// the ordinal of a grouping set.
+	groupingSetValues tree.Datums
+
 	// The current result row.
 	values tree.Datums
 
@@ -344,6 +369,10 @@ func (n *groupNode) matchLastGroupKey(ctx *tree.EvalContext, row tree.Datums) bo
 // accumulateRow takes a row and accumulates it into all the aggregate
 // functions.
 func (n *groupNode) accumulateRow(params runParams, values tree.Datums) error {
+	if n.groupingSets != nil {
+		return n.accumulateRowForGroupingSets(params, values)
+	}
+
 	bucket := n.run.scratch
 	for _, idx := range n.groupCols {
 		var err error
@@ -353,10 +382,76 @@ func (n *groupNode) accumulateRow(params runParams, values tree.Datums) error {
 		}
 	}
 
+	if err := n.accumulateRowIntoBucket(params, values, bucket, util.FastIntSet{}); err != nil {
+		return err
+	}
+
+	n.run.scratch = bucket[:0]
+	n.run.gotOneRow = true
+
+	return nil
+}
+
+// accumulateRowForGroupingSets accumulates a row once for each of the grouping
+// sets. The bucket of each set is keyed by the ordinal of the set followed by
+// the group by columns that are part of the set.
+func (n *groupNode) accumulateRowForGroupingSets(params runParams, values tree.Datums) error {
+	extendedValues := append(n.run.groupingSetValues[:0], values...)
+	extendedValues = append(extendedValues, nil)
+	n.run.groupingSetValues = extendedValues
+
+	for i := range n.groupingSets {
+		extendedValues[len(values)] = n.run.groupingSetIDs[i]
+		bucket, err := n.encodeGroupingSetBucket(n.run.scratch, values, i)
+		if err != nil {
+			return err
+		}
+		err = n.accumulateRowIntoBucket(
+			params, extendedValues, bucket, n.run.groupingSetSkippedFuncs[i],
+		)
+		if err != nil {
+			return err
+		}
+		n.run.scratch = bucket[:0]
+	}
+	n.run.gotOneRow = true
+
+	return nil
+}
+
+// encodeGroupingSetBucket appends the bucket key of a row for the given
+// grouping set to the given buffer.
+func (n *groupNode) encodeGroupingSetBucket(
+	appendTo []byte, values tree.Datums, groupingSet int,
+) ([]byte, error) {
+	bucket, err := sqlbase.EncodeDatumKeyAscending(appendTo, n.run.groupingSetIDs[groupingSet])
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range n.groupCols {
+		if !n.groupingSets[groupingSet].Contains(idx) {
+			continue
+		}
+		bucket, err = sqlbase.EncodeDatumKeyAscending(bucket, values[idx])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bucket, nil
+}
+
+// accumulateRowIntoBucket feeds the values of a row to the aggregate functions
+// of a bucket, except for the skipped functions.
+func (n *groupNode) accumulateRowIntoBucket(
+	params runParams, values tree.Datums, bucket []byte, skippedFuncs util.FastIntSet,
+) error {
 	n.run.buckets[string(bucket)] = struct{}{}
 
 	// Feed the aggregateFuncHolders for this bucket the non-grouped values.
-	for _, f := range n.funcs {
+	for i, f := range n.funcs {
+		if skippedFuncs.Contains(i) {
+			continue
+		}
 		if f.hasFilter() && values[f.filterRenderIdx] != tree.DBoolTrue {
 			continue
 		}
@@ -370,17 +465,33 @@ func (n *groupNode) accumulateRow(params runParams, values tree.Datums) error {
 			return err
 		}
 	}
-
-	n.run.scratch = bucket[:0]
-	n.run.gotOneRow = true
-
 	return nil
 }
 
+// groupingSetRenderIdx returns the index at which the ordinal of the grouping
+// set is available to the aggregation functions: it follows the columns of the
+// source plan.
+func (n *groupNode) groupingSetRenderIdx() int {
+	return len(planColumns(n.plan))
+}
+
 func (n *groupNode) startExec(params runParams) error {
 	// TODO(peter): This memory isn't being accounted for. The similar code in
 	// sql/distsqlrun/aggregator.go does account for the memory.
 	n.run.buckets = make(map[string]struct{})
+
+	if n.groupingSets != nil {
+		n.run.groupingSetIDs = make(tree.Datums, len(n.groupingSets))
+		n.run.groupingSetSkippedFuncs = make([]util.FastIntSet, len(n.groupingSets))
+		for i, set := range n.groupingSets {
+			n.run.groupingSetIDs[i] = tree.NewDInt(tree.DInt(i))
+			for j := range n.funcs {
+				if colIdx, ok := n.aggIsGroupingColumn(j); ok && !set.Contains(colIdx) {
+					n.run.groupingSetSkippedFuncs[i].Add(j)
+				}
+			}
+		}
+	}
 	return nil
 }
 
@@ -413,7 +524,9 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 		}
 		if !next {
 			n.run.sourceEmpty = true
-			n.setupOutput()
+			if err := n.setupOutput(params); err != nil {
+				return false, err
+			}
 			break
 		}
 
@@ -427,7 +540,9 @@ func (n *groupNode) Next(params runParams) (bool, error) {
 			copy(n.run.lastOrderedGroupKey, values)
 			n.run.consumedGroupKey = false
 			n.run.populated = true
-			n.setupOutput()
+			if err := n.setupOutput(params); err != nil {
+				return false, err
+			}
 			break
 		}
 
@@ -480,13 +595,37 @@ func (n *groupNode) Close(ctx context.Context) {
 
 // setupOutput runs once after all the input rows have been processed. It sets
 // up the necessary state to start iterating through the buckets in Next().
-func (n *groupNode) setupOutput() {
+func (n *groupNode) setupOutput(params runParams) error {
 	if len(n.run.buckets) < 1 && n.isScalar {
 		n.run.buckets[""] = struct{}{}
 	}
+	if len(n.run.buckets) < 1 && n.groupingSets != nil {
+		// Like a scalar groupby, each empty grouping set produces a row even if
+		// there are no input rows.
+		ordinalIdx := n.groupingSetRenderIdx()
+		for i, set := range n.groupingSets {
+			if !set.Empty() {
+				continue
+			}
+			bucket, err := n.encodeGroupingSetBucket(n.run.scratch, nil /* values */, i)
+			if err != nil {
+				return err
+			}
+			n.run.buckets[string(bucket)] = struct{}{}
+			for _, f := range n.funcs {
+				if f.argRenderIdx == ordinalIdx {
+					id := n.run.groupingSetIDs[i]
+					if err := f.add(params.ctx, params.EvalContext(), bucket, id); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
 	if n.run.values == nil {
 		n.run.values = make(tree.Datums, len(n.funcs))
 	}
+	return nil
 }
 
 // requiresIsDistinctFromNullFilter returns whether a
@@ -529,7 +668,7 @@ func (n *groupNode) addIsDistinctFromNullFilter(where *filterNode, render *rende
 // instead have another variable (e.g. from the AST) tell us what type
 // of aggregation we're dealing with, and test that here.
 func (n *groupNode) desiredAggregateOrdering(evalCtx *tree.EvalContext) sqlbase.ColumnOrdering {
-	if len(n.groupCols) > 0 {
+	if len(n.groupCols) > 0 || n.groupingSets != nil {
 		return nil
 	}
 
@@ -636,7 +775,8 @@ func (v *extractAggregatesVisitor) VisitPre(expr tree.Expr) (recurse bool, newEx
 	case *tree.FuncExpr:
 		if agg := t.GetAggregateConstructor(); agg != nil {
 			var f *aggregateFuncHolder
-			if len(t.Exprs) == 0 {
+			args := t.AggregateArgs()
+			if len(args) == 0 {
 				// COUNT_ROWS has no arguments.
 				f = v.groupNode.newAggregateFuncHolder(
 					t.Func.String(),
@@ -649,24 +789,24 @@ func (v *extractAggregatesVisitor) VisitPre(expr tree.Expr) (recurse bool, newEx
 			} else {
 				// Only the first argument can be an expression, all the following ones
 				// must be consts. So before we proceed, they must be checked.
-				arguments := make(tree.Datums, len(t.Exprs)-1)
-				if len(t.Exprs) > 1 {
+				arguments := make(tree.Datums, len(args)-1)
+				if len(args) > 1 {
 					evalContext := v.planner.EvalContext()
-					for i := 1; i < len(t.Exprs); i++ {
-						if !tree.IsConst(evalContext, t.Exprs[i]) {
+					for i := 1; i < len(args); i++ {
+						if !tree.IsConst(evalContext, args[i]) {
 							v.err = pgerror.UnimplementedWithIssueError(28417, "aggregate functions with multiple non-constant expressions are not supported")
 							return false, expr
 						}
 						var err error
-						arguments[i-1], err = t.Exprs[i].(tree.TypedExpr).Eval(evalContext)
+						arguments[i-1], err = args[i].(tree.TypedExpr).Eval(evalContext)
 						if err != nil {
-							v.err = pgerror.NewAssertionErrorf("can't evaluate %s - %v", t.Exprs[i].String(), err)
+							v.err = pgerror.NewAssertionErrorf("can't evaluate %s - %v", args[i].String(), err)
 							return false, expr
 						}
 					}
 				}
 
-				argExpr := t.Exprs[0].(tree.TypedExpr)
+				argExpr := args[0].(tree.TypedExpr)
 
 				// TODO(knz): it's really a shame that we need to recurse
 				// through the sub-tree to determine whether the arguments
@@ -791,6 +791,15 @@ CREATE TABLE information_schema.schema_privileges (
 		return forEachDatabaseDesc(ctx, p, dbContext, func(db *sqlbase.DatabaseDescriptor) error {
 			return forEachSchemaName(ctx, p, db, func(scName string) error {
 				privs := db.Privileges.Show()
+				if isUserSchemaName(scName) {
+					scDesc, err := getUserSchemaDesc(ctx, p.txn, db.ID, scName)
+					if err != nil {
+						return err
+					}
+					if scDesc != nil {
+						privs = scDesc.Privileges.Show()
+					}
+				}
 				dbNameStr := tree.NewDString(db.Name)
 				scNameStr := tree.NewDString(scName)
 				for _, u := range privs {
@@ -1003,6 +1012,13 @@ CREATE TABLE information_schema.table_constraints (
 				tbNameStr := tree.NewDString(table.Name)
 
 				for conName, c := range conInfo {
+					// Only foreign key and unique constraints can be deferrable.
+					deferrable, initiallyDeferred := false, false
+					if c.FK != nil {
+						deferrable, initiallyDeferred = c.FK.Deferrable, c.FK.InitiallyDeferred
+					} else if c.Kind == sqlbase.ConstraintTypeUnique {
+						deferrable, initiallyDeferred = c.Index.Deferrable, c.Index.InitiallyDeferred
+					}
 					if err := addRow(
 						dbNameStr,                       // constraint_catalog
 						scNameStr,                       // constraint_schema
@@ -1011,8 +1027,8 @@ CREATE TABLE information_schema.table_constraints (
 						scNameStr,                       // table_schema
 						tbNameStr,                       // table_name
 						tree.NewDString(string(c.Kind)), // constraint_type
-						yesOrNoDatum(false),             // is_deferrable
-						yesOrNoDatum(false),             // initially_deferred
+						yesOrNoDatum(deferrable),        // is_deferrable
+						yesOrNoDatum(initiallyDeferred), // initially_deferred
 					); err != nil {
 						return err
 					}
@@ -1103,6 +1119,7 @@ var (
 	tableTypeSystemView = tree.NewDString("SYSTEM VIEW")
 	tableTypeBaseTable  = tree.NewDString("BASE TABLE")
 	tableTypeView       = tree.NewDString("VIEW")
+	tableTypeMatView    = tree.NewDString("MATERIALIZED VIEW")
 )
 
 var informationSchemaTablesTable = virtualSchemaTable{
@@ -1118,6 +1135,9 @@ var informationSchemaTablesTable = virtualSchemaTable{
 				if isVirtualDescriptor(table) {
 					tableType = tableTypeSystemView
 					insertable = noString
+				} else if table.MaterializedView() {
+					tableType = tableTypeMatView
+					insertable = noString
 				} else if table.IsView() {
 					tableType = tableTypeView
 					insertable = noString
@@ -1156,7 +1176,7 @@ CREATE TABLE information_schema.views (
 	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /* virtual schemas have no views */
 			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
-				if !table.IsView() {
+				if !table.IsView() || table.MaterializedView() {
 					return nil
 				}
 				// Note that the view query printed will not include any column aliases
@@ -1183,11 +1203,20 @@ CREATE TABLE information_schema.views (
 	},
 }
 
-// forEachSchemaName iterates over the physical and virtual schemas.
+// forEachSchemaName iterates over the physical, user-defined and virtual
+// schemas.
 func forEachSchemaName(
 	ctx context.Context, p *planner, db *sqlbase.DatabaseDescriptor, fn func(string) error,
 ) error {
 	scNames := []string{string(tree.PublicSchemaName)}
+	// Handle user-defined schemas.
+	userSchemas, err := listUserSchemas(ctx, p.txn, db.ID)
+	if err != nil {
+		return err
+	}
+	for scName := range userSchemas {
+		scNames = append(scNames, scName)
+	}
 	// Handle virtual schemas.
 	for _, schema := range p.getVirtualTabler().getEntries() {
 		scNames = append(scNames, schema.desc.Name)
@@ -1233,6 +1262,49 @@ func forEachDatabaseDesc(
 	return nil
 }
 
+// forEachTypeDesc retrieves all the descriptors of user-defined types and
+// iterates through them in order of their ID. For each type, the function
+// will call fn with its respective database and type descriptor.
+//
+// The dbContext argument specifies in which database context we are
+// requesting the descriptors. In context nil all descriptors are
+// visible, in non-empty contexts only the descriptors of that
+// database are visible.
+func forEachTypeDesc(
+	ctx context.Context,
+	p *planner,
+	dbContext *DatabaseDescriptor,
+	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TypeDescriptor) error,
+) error {
+	descs, err := p.Tables().getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+
+	dbDescs := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	var typeDescs []*sqlbase.TypeDescriptor
+	for _, desc := range descs {
+		switch t := desc.(type) {
+		case *sqlbase.DatabaseDescriptor:
+			dbDescs[t.ID] = t
+		case *sqlbase.TypeDescriptor:
+			typeDescs = append(typeDescs, t)
+		}
+	}
+	sort.Slice(typeDescs, func(i, j int) bool { return typeDescs[i].ID < typeDescs[j].ID })
+
+	for _, typ := range typeDescs {
+		db, ok := dbDescs[typ.ParentID]
+		if !ok || (dbContext != nil && dbContext.ID != db.ID) || !userCanSeeDatabase(ctx, p, db) {
+			continue
+		}
+		if err := fn(db, typ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // forEachTableDesc retrieves all table descriptors from the current
 // database and all system databases and iterates through them. For
 // each table, the function will call fn with its respective database
@@ -1365,14 +1437,40 @@ func forEachTableDescWithTableLookupInternal(
 		}
 	}
 
-	// Physical descriptors next.
+	// Physical descriptors next. Temporary tables are only visible to the
+	// session that created them, in its temporary schema.
+	tempSchemaName := p.SessionData().SearchPath.GetTemporarySchemaName()
+	tempSchemaIDs := make(map[sqlbase.ID]sqlbase.ID)
 	for _, tbID := range lCtx.tbIDs {
 		table := lCtx.tbDescs[tbID]
 		dbDesc, parentExists := lCtx.dbDescs[table.GetParentID()]
 		if table.Dropped() || !userCanSeeTable(ctx, p, table, allowAdding) || !parentExists {
 			continue
 		}
-		if err := fn(dbDesc, tree.PublicSchema, table, lCtx); err != nil {
+		scName := tree.PublicSchema
+		if table.Temporary {
+			if tempSchemaName == "" {
+				continue
+			}
+			scID, ok := tempSchemaIDs[dbDesc.ID]
+			if !ok {
+				if scID, err = getTemporarySchemaID(ctx, p.txn, dbDesc.ID, tempSchemaName); err != nil {
+					return err
+				}
+				tempSchemaIDs[dbDesc.ID] = scID
+			}
+			if table.UnexposedParentSchemaID != scID {
+				continue
+			}
+			scName = tempSchemaName
+		} else if table.UnexposedParentSchemaID != 0 {
+			scDesc, ok := lCtx.scDescs[table.UnexposedParentSchemaID]
+			if !ok {
+				continue
+			}
+			scName = scDesc.Name
+		}
+		if err := fn(dbDesc, scName, table, lCtx); err != nil {
 			return err
 		}
 	}
@@ -266,10 +266,11 @@ func (p *planner) Insert(
 
 	// Create the table insert, which does the bulk of the work.
 	ri, err := row.MakeInserter(p.txn, desc, fkTables, insertCols,
-		row.CheckFKs, &p.alloc)
+		row.CheckFKs, p.EvalContext(), &p.alloc)
 	if err != nil {
 		return nil, err
 	}
+	ri.SetDeferredChecks(p.deferredChecks())
 
 	// rowsNeeded will help determine whether we need to allocate a
 	// rowsContainer.
@@ -1214,7 +1214,7 @@ func (c *tableNameCache) get(
 	if !nameMatchesTable(&table.ImmutableTableDescriptor, dbID, tableName) {
 		panic(fmt.Sprintf("Out of sync entry in the name cache. "+
 			"Cache entry: %d.%q -> %d. Lease: %d.%q.",
-			dbID, tableName, table.ID, table.ParentID, table.Name))
+			dbID, tableName, table.ID, table.GetNamespaceParentID(), table.Name))
 	}
 
 	// Expired table. Don't hand it out.
@@ -1227,10 +1227,15 @@ func (c *tableNameCache) get(
 }
 
 func (c *tableNameCache) insert(table *tableVersionState) {
+	// The names of temporary tables are not resolved through leases.
+	if table.Temporary {
+		return
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeTableNameCacheKey(table.ParentID, table.Name)
+	key := makeTableNameCacheKey(table.GetNamespaceParentID(), table.Name)
 	existing, ok := c.tables[key]
 	if !ok {
 		c.tables[key] = table
@@ -1250,7 +1255,7 @@ func (c *tableNameCache) remove(table *tableVersionState) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeTableNameCacheKey(table.ParentID, table.Name)
+	key := makeTableNameCacheKey(table.GetNamespaceParentID(), table.Name)
 	existing, ok := c.tables[key]
 	if !ok {
 		// Table for lease not found in table name cache. This can happen if we had
@@ -1345,7 +1350,7 @@ func (m *LeaseManager) SetExecCfg(execCfg *ExecutorConfig) {
 func nameMatchesTable(
 	table *sqlbase.ImmutableTableDescriptor, dbID sqlbase.ID, tableName string,
 ) bool {
-	return table.ParentID == dbID && table.Name == tableName
+	return table.GetNamespaceParentID() == dbID && table.Name == tableName
 }
 
 // findNewest returns the newest table version state for the tableID.
@@ -1374,6 +1379,9 @@ func (m *LeaseManager) findNewest(tableID sqlbase.ID) *tableVersionState {
 // can use the timestamp and get the correct name->id  mapping at a
 // timestamp, it uses Acquire() to get a descriptor with the corresponding
 // id and fails because the id has been dropped by the TRUNCATE.
+//
+// The names of the tables of user-defined schemas are recorded under the ID
+// of their schema, so for such tables dbID is the ID of the schema.
 func (m *LeaseManager) AcquireByName(
 	ctx context.Context, timestamp hlc.Timestamp, dbID sqlbase.ID, tableName string,
 ) (*sqlbase.ImmutableTableDescriptor, hlc.Timestamp, error) {
@@ -1671,7 +1679,7 @@ func (m *LeaseManager) RefreshLeases(s *stop.Stopper, db *client.DB, g *gossip.G
 							log.Warningf(ctx, "error purging leases for table %d(%s): %s",
 								table.ID, table.Name, err)
 						}
-					case *sqlbase.Descriptor_Database:
+					case *sqlbase.Descriptor_Database, *sqlbase.Descriptor_Type, *sqlbase.Descriptor_Schema:
 						// Ignore.
 					}
 				})
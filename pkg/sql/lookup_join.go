@@ -26,7 +26,7 @@ type lookupJoinNode struct {
 	input planNode
 	table *scanNode
 
-	// joinType is either INNER or LEFT_OUTER.
+	// joinType is either INNER, LEFT_OUTER, LEFT_SEMI or LEFT_ANTI.
 	joinType sqlbase.JoinType
 
 	// keyCols identifies the columns from the input which are used for the
@@ -34,8 +34,8 @@ type lookupJoinNode struct {
 	// are looking up into).
 	keyCols []int
 
-	// columns are the produced columns, namely the input clumns and the
-	// columns in the table scanNode.
+	// columns are the produced columns, namely the input clumns and (unless
+	// this is a semi or anti join) the columns in the table scanNode.
 	columns sqlbase.ResultColumns
 
 	// onCond is any ON condition to be used in conjunction with the implicit
@@ -80,7 +80,7 @@ func (lj *lookupJoinNode) startExec(params runParams) error {
 	}
 
 	indexColIDs := lj.table.index.ColumnIDs
-	if !lj.table.index.Unique {
+	if !lj.table.index.HasUniqueKeys() {
 		// Add implicit key columns.
 		indexColIDs = append(indexColIDs, lj.table.index.ExtraColumnIDs...)
 	}
@@ -40,6 +40,8 @@ func (f *stubFactory) ConstructScan(
 	reverse bool,
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
+	waitPolicy tree.LockingWaitPolicy,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -76,6 +78,16 @@ func (f *stubFactory) ConstructHashJoin(
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructApplyJoin(
+	joinType sqlbase.JoinType,
+	left exec.Node,
+	rightColumns sqlbase.ResultColumns,
+	onCond tree.TypedExpr,
+	planRightSideFn exec.ApplyJoinPlanRightSideFn,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) ConstructMergeJoin(
 	joinType sqlbase.JoinType,
 	left, right exec.Node,
@@ -102,6 +114,15 @@ func (f *stubFactory) ConstructScalarGroupBy(
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructGroupingSets(
+	input exec.Node,
+	groupCols []exec.ColumnOrdinal,
+	groupingSets []exec.ColumnOrdinalSet,
+	aggregations []exec.AggInfo,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) ConstructDistinct(
 	input exec.Node, distinctCols, orderedCols exec.ColumnOrdinalSet,
 ) (exec.Node, error) {
@@ -175,11 +196,17 @@ func (f *stubFactory) ConstructProjectSet(
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructWindow(n exec.Node, window exec.WindowInfo) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) RenameColumns(input exec.Node, colNames []string) (exec.Node, error) {
 	return struct{}{}, nil
 }
 
-func (f *stubFactory) ConstructPlan(root exec.Node, subqueries []exec.Subquery) (exec.Plan, error) {
+func (f *stubFactory) ConstructPlan(
+	root exec.Node, subqueries []exec.Subquery, postqueries []exec.Node,
+) (exec.Plan, error) {
 	return struct{}{}, nil
 }
 
@@ -194,13 +221,22 @@ func (f *stubFactory) ConstructShowTrace(typ tree.ShowTraceType, compact bool) (
 }
 
 func (f *stubFactory) ConstructInsert(
-	input exec.Node, table cat.Table, insertCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	insertCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
 
 func (f *stubFactory) ConstructUpdate(
-	input exec.Node, table cat.Table, fetchCols, updateCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	updateCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -218,13 +254,50 @@ func (f *stubFactory) ConstructUpsert(
 	insertCols exec.ColumnOrdinalSet,
 	fetchCols exec.ColumnOrdinalSet,
 	updateCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
 	rowsNeeded bool,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
 
 func (f *stubFactory) ConstructDelete(
-	input exec.Node, table cat.Table, fetchCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructFastUpsert(
+	input exec.Node, table cat.Table, insertCols exec.ColumnOrdinalSet, skipFKChecks bool,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructDeleteRange(
+	table cat.Table, fetchCols exec.ColumnOrdinalSet, indexConstraint *constraint.Constraint,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructRecursiveCTE(
+	initial exec.Node, fn exec.RecursiveCTEIterationFn, label string, deduplicate bool,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructScanBuffer(ref exec.Node, label string) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructBuffer(input exec.Node, label string) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructErrorIfRows(
+	input exec.Node, mkErr func(tree.Datums) error,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
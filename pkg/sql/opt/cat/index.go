@@ -45,6 +45,12 @@ type Index interface {
 	// IsInverted returns true if this is a JSON inverted index.
 	IsInverted() bool
 
+	// Predicate returns the SQL text of the predicate of a partial index, and
+	// true. A partial index only contains entries for the rows for which the
+	// predicate evaluates to true. Returns false for the second return value if
+	// the index is not partial.
+	Predicate() (string, bool)
+
 	// ColumnCount returns the number of columns in the index. This includes
 	// columns that were part of the index definition (including the STORING
 	// clause), as well as implicitly added primary key columns.
@@ -117,6 +123,16 @@ type Index interface {
 	// of an outbound foreign key relation. Returns false for the second
 	// return value if there is no foreign key reference on this index.
 	ForeignKey() (ForeignKeyReference, bool)
+
+	// InboundForeignKeyCount returns the number of foreign key references from
+	// other indexes (possibly of the same table) that target this index.
+	InboundForeignKeyCount() int
+
+	// InboundForeignKey returns the ith inbound foreign key reference, where
+	// i < InboundForeignKeyCount. Only the TableID and IndexID fields are set;
+	// they identify the origin index, whose ForeignKey method returns the full
+	// reference.
+	InboundForeignKey(i int) ForeignKeyReference
 }
 
 // IndexColumn describes a single column that is part of an index definition.
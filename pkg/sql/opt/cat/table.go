@@ -34,6 +34,11 @@ type Table interface {
 	// information_schema tables.
 	IsVirtualTable() bool
 
+	// IsMaterializedView returns true if this table stores the results of the
+	// query of a materialized view. The contents of a materialized view can
+	// only be changed by refreshing it.
+	IsMaterializedView() bool
+
 	// ColumnCount returns the number of columns in the table.
 	ColumnCount() int
 
@@ -61,6 +66,12 @@ type Table interface {
 	// creates one based on a hidden rowid column.
 	Index(i int) Index
 
+	// MutationIndexCount returns the number of indexes that are in the process
+	// of being added to or dropped from the table. These indexes are not part
+	// of the indexes returned by Index, but mutation statements must still
+	// maintain their entries.
+	MutationIndexCount() int
+
 	// StatisticCount returns the number of statistics available for the table.
 	StatisticCount() int
 
@@ -94,7 +105,23 @@ type TableStatistic interface {
 	// any column in the statistic.
 	NullCount() uint64
 
-	// TODO(radu): add Histogram().
+	// Histogram returns the histogram of the values of the first column of the
+	// statistic, or nil if there is no histogram. The buckets are ordered by
+	// their upper bound. NULL values are not included in the histogram.
+	Histogram() []HistogramBucket
+}
+
+// HistogramBucket contains the data for a single bucket of a histogram.
+type HistogramBucket struct {
+	// NumEq is the estimated number of values equal to UpperBound.
+	NumEq float64
+
+	// NumRange is the estimated number of values between the upper bound of the
+	// previous bucket and UpperBound (both boundaries are exclusive).
+	NumRange float64
+
+	// UpperBound is the upper bound of the bucket.
+	UpperBound tree.Datum
 }
 
 // ForeignKeyReference is a struct representing an outbound foreign key reference.
@@ -113,6 +140,21 @@ type ForeignKeyReference struct {
 
 	// Match contains the method used for comparing composite foreign keys.
 	Match tree.CompositeKeyMatchMethod
+
+	// Name is the name of the foreign key constraint.
+	Name string
+
+	// OnDelete is the action taken on the referencing rows when a referenced
+	// row is deleted.
+	OnDelete tree.ReferenceAction
+
+	// OnUpdate is the action taken on the referencing rows when the key of a
+	// referenced row is updated.
+	OnUpdate tree.ReferenceAction
+
+	// Deferrable is true if checking of the constraint can be deferred until
+	// the end of the transaction.
+	Deferrable bool
 }
 
 // FindTableColumnByName returns the ordinal of the column having the given
@@ -126,6 +168,17 @@ func FindTableColumnByName(tab Table, name tree.Name) int {
 	return -1
 }
 
+// FindIndexByID returns the ordinal of the index having the given stable ID, if
+// one exists in the given table. Otherwise, it returns -1.
+func FindIndexByID(tab Table, id StableID) int {
+	for ord, n := 0, tab.IndexCount(); ord < n; ord++ {
+		if tab.Index(ord).ID() == id {
+			return ord
+		}
+	}
+	return -1
+}
+
 // FormatCatalogTable nicely formats a catalog table using a treeprinter for
 // debugging and testing.
 func FormatCatalogTable(cat Catalog, tab Table, tp treeprinter.Node) {
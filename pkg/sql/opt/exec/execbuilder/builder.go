@@ -35,6 +35,26 @@ type Builder struct {
 	// expressions we built. Each entry is associated with a tree.Subquery
 	// expression node.
 	subqueries []exec.Subquery
+
+	// postqueries accumulates the plans that check foreign keys and run
+	// cascading actions for the mutations we built. They are run, in order,
+	// after the main plan.
+	postqueries []exec.Node
+
+	// buffers maps the WithID of a mutation to the plan that buffers its input
+	// rows. It is used to build the WithScan expressions of the mutation's
+	// foreign key checks and cascades.
+	buffers map[opt.WithID]execPlan
+
+	// workingTables maps the WithID of a recursive CTE to the buffer node which
+	// holds the results of the previous iteration. It is only set while building
+	// the recursive side of a RecursiveCTE expression.
+	workingTables map[opt.WithID]exec.Node
+
+	// outerBindings maps the outer columns of the right side of an apply join to
+	// their values in the current row of the left side. It is only set while
+	// building the right side of an ApplyJoin expression.
+	outerBindings map[opt.ColumnID]tree.Datum
 }
 
 // New constructs an instance of the execution node builder using the
@@ -51,7 +71,7 @@ func (b *Builder) Build() (exec.Plan, error) {
 	if err != nil {
 		return nil, err
 	}
-	return b.factory.ConstructPlan(root, b.subqueries)
+	return b.factory.ConstructPlan(root, b.subqueries, b.postqueries)
 }
 
 func (b *Builder) build(e opt.Expr) (exec.Node, error) {
@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/ordering"
@@ -26,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/pkg/errors"
 )
@@ -169,6 +171,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.GroupByExpr, *memo.ScalarGroupByExpr:
 		ep, err = b.buildGroupBy(e)
 
+	case *memo.GroupingSetsGroupByExpr:
+		ep, err = b.buildGroupingSetsGroupBy(t)
+
 	case *memo.DistinctOnExpr:
 		ep, err = b.buildDistinct(t)
 
@@ -205,6 +210,18 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.ProjectSetExpr:
 		ep, err = b.buildProjectSet(t)
 
+	case *memo.WindowExpr:
+		ep, err = b.buildWindow(t)
+
+	case *memo.RecursiveCTEExpr:
+		ep, err = b.buildRecursiveCTE(t)
+
+	case *memo.WorkingTableScanExpr:
+		ep, err = b.buildWorkingTableScan(t)
+
+	case *memo.WithScanExpr:
+		ep, err = b.buildWithScan(t)
+
 	case *memo.InsertExpr:
 		ep, err = b.buildInsert(t)
 
@@ -230,7 +247,8 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 			break
 		}
 		if opt.IsJoinApplyOp(e) {
-			return execPlan{}, b.decorrelationError()
+			ep, err = b.buildApplyJoin(e)
+			break
 		}
 	}
 	if err != nil {
@@ -352,6 +370,13 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	// Locking the rows requires writing to them, so raise an error if the scan
+	// is part of a read-only transaction.
+	if scan.Locking != tree.ForNone && b.evalCtx.TxnReadOnly {
+		return execPlan{}, pgerror.NewErrorf(pgerror.CodeReadOnlySQLTransactionError,
+			"cannot execute SELECT %s in a read-only transaction", scan.Locking)
+	}
+
 	needed, output := b.getColumns(scan.Cols, scan.Table)
 	res := execPlan{outputCols: output}
 
@@ -365,6 +390,8 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		ordering.ScanIsReverse(scan, &scan.RequiredPhysical().Ordering),
 		b.indexConstraintMaxResults(scan),
 		res.reqOrdering(scan),
+		scan.Locking,
+		scan.LockingWaitPolicy,
 	)
 	if err != nil {
 		return execPlan{}, err
@@ -517,6 +544,108 @@ func (b *Builder) buildHashJoin(join memo.RelExpr) (execPlan, error) {
 	return ep, nil
 }
 
+// buildApplyJoin builds an apply join which could not be decorrelated by the
+// optimizer. The right side is built anew for each row of the left side,
+// with the outer columns that refer to the left side replaced by the values
+// of that row.
+func (b *Builder) buildApplyJoin(join memo.RelExpr) (execPlan, error) {
+	var joinType sqlbase.JoinType
+	switch join.Op() {
+	case opt.InnerJoinApplyOp:
+		joinType = sqlbase.InnerJoin
+	case opt.LeftJoinApplyOp:
+		joinType = sqlbase.LeftOuterJoin
+	case opt.SemiJoinApplyOp:
+		joinType = sqlbase.LeftSemiJoin
+	case opt.AntiJoinApplyOp:
+		joinType = sqlbase.LeftAntiJoin
+	default:
+		// Right and full apply joins would need to keep track of the right rows
+		// which were matched across all left rows.
+		return execPlan{}, b.decorrelationError()
+	}
+	leftExpr := join.Child(0).(memo.RelExpr)
+	rightExpr := join.Child(1).(memo.RelExpr)
+	filters := join.Child(2).(*memo.FiltersExpr)
+
+	left, err := b.buildRelational(leftExpr)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The plan of the right side is not available yet, so fix the order of its
+	// output columns.
+	md := b.mem.Metadata()
+	rightCols := rightExpr.Relational().OutputCols
+	rightColList := make(opt.ColList, 0, rightCols.Len())
+	rightColumns := make(sqlbase.ResultColumns, 0, rightCols.Len())
+	var rightOutputCols opt.ColMap
+	for i, ok := rightCols.Next(0); ok; i, ok = rightCols.Next(i + 1) {
+		colMeta := md.ColumnMeta(opt.ColumnID(i))
+		rightOutputCols.Set(i, len(rightColList))
+		rightColList = append(rightColList, opt.ColumnID(i))
+		rightColumns = append(rightColumns, sqlbase.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type})
+	}
+
+	allCols := joinOutputMap(left.outputCols, rightOutputCols)
+	ctx := buildScalarCtx{
+		ivh:     tree.MakeIndexedVarHelper(nil /* container */, allCols.Len()),
+		ivarMap: allCols,
+	}
+	var onExpr tree.TypedExpr
+	if len(*filters) != 0 {
+		onExpr, err = b.buildScalar(&ctx, filters)
+		if err != nil {
+			return execPlan{}, err
+		}
+	}
+
+	leftBoundCols := rightExpr.Relational().OuterCols.Intersection(
+		leftExpr.Relational().OutputCols,
+	)
+	fn := func(leftRow tree.Datums) (exec.Node, error) {
+		innerBld := New(b.factory, b.mem, rightExpr, b.evalCtx)
+		innerBld.workingTables = b.workingTables
+		innerBld.outerBindings = make(
+			map[opt.ColumnID]tree.Datum, len(b.outerBindings)+leftBoundCols.Len(),
+		)
+		for col, d := range b.outerBindings {
+			innerBld.outerBindings[col] = d
+		}
+		for i, ok := leftBoundCols.Next(0); ok; i, ok = leftBoundCols.Next(i + 1) {
+			innerBld.outerBindings[opt.ColumnID(i)] = leftRow[left.getColumnOrdinal(opt.ColumnID(i))]
+		}
+
+		plan, err := innerBld.buildRelational(rightExpr)
+		if err != nil {
+			return nil, err
+		}
+		if len(innerBld.subqueries) > 0 {
+			return nil, pgerror.Unimplemented(
+				"apply-join-subquery", "subqueries not supported on the right side of an apply join",
+			)
+		}
+		plan, err = innerBld.ensureColumns(
+			plan, rightColList, nil /* colNames */, rightExpr.ProvidedPhysical().Ordering,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return plan.root, nil
+	}
+
+	ep := execPlan{outputCols: allCols}
+	if joinType == sqlbase.LeftSemiJoin || joinType == sqlbase.LeftAntiJoin {
+		// For semi and anti join, only the left columns are output.
+		ep.outputCols = left.outputCols
+	}
+	ep.root, err = b.factory.ConstructApplyJoin(joinType, left.root, rightColumns, onExpr, fn)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
 func (b *Builder) buildMergeJoin(join *memo.MergeJoinExpr) (execPlan, error) {
 	joinType := joinOpToJoinType(join.JoinType)
 
@@ -627,6 +756,76 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 	}
 
 	aggregations := *groupBy.Child(1).(*memo.AggregationsExpr)
+	aggInfos, err := b.buildAggInfos(aggregations, &input)
+	if err != nil {
+		return execPlan{}, err
+	}
+	for i := range aggregations {
+		ep.outputCols.Set(int(aggregations[i].Col), len(groupingColIdx)+i)
+	}
+
+	if groupBy.Op() == opt.ScalarGroupByOp {
+		ep.root, err = b.factory.ConstructScalarGroupBy(input.root, aggInfos)
+	} else {
+		groupBy := groupBy.(*memo.GroupByExpr)
+		orderedInputCols := input.getColumnOrdinalSet(
+			ordering.StreamingGroupingCols(&groupBy.GroupingPrivate, &groupBy.RequiredPhysical().Ordering),
+		)
+		reqOrdering := ep.reqOrdering(groupBy)
+		ep.root, err = b.factory.ConstructGroupBy(
+			input.root, groupingColIdx, orderedInputCols, aggInfos, reqOrdering,
+		)
+	}
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
+func (b *Builder) buildGroupingSetsGroupBy(
+	groupBy *memo.GroupingSetsGroupByExpr,
+) (execPlan, error) {
+	input, err := b.buildGroupByInput(groupBy)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	var ep execPlan
+	groupingCols := groupBy.GroupingCols
+	groupingColIdx := make([]exec.ColumnOrdinal, 0, groupingCols.Len())
+	for i, ok := groupingCols.Next(0); ok; i, ok = groupingCols.Next(i + 1) {
+		ep.outputCols.Set(i, len(groupingColIdx))
+		groupingColIdx = append(groupingColIdx, input.getColumnOrdinal(opt.ColumnID(i)))
+	}
+
+	groupingSets := make([]exec.ColumnOrdinalSet, len(groupBy.GroupingSets))
+	for i, set := range groupBy.GroupingSets {
+		groupingSets[i] = input.getColumnOrdinalSet(set)
+	}
+
+	aggInfos, err := b.buildAggInfos(groupBy.Aggregations, &input)
+	if err != nil {
+		return execPlan{}, err
+	}
+	for i := range groupBy.Aggregations {
+		ep.outputCols.Set(int(groupBy.Aggregations[i].Col), len(groupingColIdx)+i)
+	}
+	ep.outputCols.Set(int(groupBy.GroupingSetIDCol), len(groupingColIdx)+len(aggInfos))
+
+	ep.root, err = b.factory.ConstructGroupingSets(
+		input.root, groupingColIdx, groupingSets, aggInfos,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
+// buildAggInfos returns the exec.AggInfo for each of the given aggregations,
+// the arguments of which are columns of the given input.
+func (b *Builder) buildAggInfos(
+	aggregations memo.AggregationsExpr, input *execPlan,
+) ([]exec.AggInfo, error) {
 	aggInfos := make([]exec.AggInfo, len(aggregations))
 	for i := range aggregations {
 		item := &aggregations[i]
@@ -634,17 +833,26 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 
 		distinct := false
 		var argIdx []exec.ColumnOrdinal
+		filterIdx := exec.ColumnOrdinal(-1)
 
 		if item.Agg.ChildCount() > 0 {
 			child := item.Agg.Child(0)
 
+			if aggFilter, ok := child.(*memo.AggFilterExpr); ok {
+				filter, ok := aggFilter.Filter.(*memo.VariableExpr)
+				if !ok {
+					return nil, errors.Errorf("only VariableOp filters supported")
+				}
+				filterIdx = input.getColumnOrdinal(filter.Col)
+				child = aggFilter.Input
+			}
 			if aggDistinct, ok := child.(*memo.AggDistinctExpr); ok {
 				distinct = true
 				child = aggDistinct.Input
 			}
 			v, ok := child.(*memo.VariableExpr)
 			if !ok {
-				return execPlan{}, errors.Errorf("only VariableOp args supported")
+				return nil, errors.Errorf("only VariableOp args supported")
 			}
 			argIdx = []exec.ColumnOrdinal{input.getColumnOrdinal(v.Col)}
 		}
@@ -658,33 +866,17 @@ func (b *Builder) buildGroupBy(groupBy memo.RelExpr) (execPlan, error) {
 			ResultType: item.Agg.DataType(),
 			ArgCols:    argIdx,
 			ConstArgs:  constArgs,
+			Filter:     filterIdx,
 		}
-		ep.outputCols.Set(int(item.Col), len(groupingColIdx)+i)
 	}
-
-	if groupBy.Op() == opt.ScalarGroupByOp {
-		ep.root, err = b.factory.ConstructScalarGroupBy(input.root, aggInfos)
-	} else {
-		groupBy := groupBy.(*memo.GroupByExpr)
-		orderedInputCols := input.getColumnOrdinalSet(
-			ordering.StreamingGroupingCols(&groupBy.GroupingPrivate, &groupBy.RequiredPhysical().Ordering),
-		)
-		reqOrdering := ep.reqOrdering(groupBy)
-		ep.root, err = b.factory.ConstructGroupBy(
-			input.root, groupingColIdx, orderedInputCols, aggInfos, reqOrdering,
-		)
-	}
-	if err != nil {
-		return execPlan{}, err
-	}
-	return ep, nil
+	return aggInfos, nil
 }
 
 // extractAggregateConstArgs returns the list of constant arguments associated with a given aggregate
 // expression.
 func (b *Builder) extractAggregateConstArgs(agg opt.ScalarExpr) tree.Datums {
 	switch agg.Op() {
-	case opt.StringAggOp:
+	case opt.StringAggOp, opt.PercentileDiscOp, opt.PercentileContOp, opt.ApproxPercentileOp:
 		return tree.Datums{memo.ExtractConstDatum(agg.Child(1))}
 	default:
 		return nil
@@ -723,8 +915,15 @@ func (b *Builder) buildGroupByInput(groupBy memo.RelExpr) (execPlan, error) {
 	// We address just the GroupBy case for now because there is a particularly
 	// important case with COUNT(*) where we can remove all input columns, which
 	// leads to significant speedup.
-	private := groupBy.Private().(*memo.GroupingPrivate)
-	neededCols := private.GroupingCols.Copy()
+	var neededCols opt.ColSet
+	switch private := groupBy.Private().(type) {
+	case *memo.GroupingPrivate:
+		neededCols = private.GroupingCols.Copy()
+	case *memo.GroupingSetsPrivate:
+		neededCols = private.GroupingCols.Copy()
+	default:
+		return execPlan{}, errors.Errorf("unexpected grouping private %T", private)
+	}
 	aggs := *groupBy.Child(1).(*memo.AggregationsExpr)
 	for i := range aggs {
 		neededCols.UnionWith(memo.ExtractAggInputColumns(aggs[i].Agg))
@@ -963,6 +1162,11 @@ func (b *Builder) buildLookupJoin(join *memo.LookupJoinExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
+	if join.JoinType == opt.SemiJoinOp || join.JoinType == opt.AntiJoinOp {
+		// Semi and anti joins only produce the input columns.
+		res.outputCols = input.outputCols
+	}
+
 	tab := md.Table(join.Table)
 	res.root, err = b.factory.ConstructLookupJoin(
 		joinOpToJoinType(join.JoinType),
@@ -1075,6 +1279,74 @@ func (b *Builder) buildMax1Row(max1Row *memo.Max1RowExpr) (execPlan, error) {
 
 }
 
+func (b *Builder) buildRecursiveCTE(rec *memo.RecursiveCTEExpr) (execPlan, error) {
+	initial, err := b.buildRelational(rec.Initial)
+	if err != nil {
+		return execPlan{}, err
+	}
+	initial, err = b.ensureColumns(
+		initial, rec.InitialCols, nil /* colNames */, rec.Initial.ProvidedPhysical().Ordering,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The recursive side is built anew for each iteration, using a separate
+	// builder on the same memo; the working table of the iteration is made
+	// available to the WorkingTableScan expressions through workingTables.
+	fn := func(bufferRef exec.Node) (exec.Node, error) {
+		innerBld := New(b.factory, b.mem, rec.Recursive, b.evalCtx)
+		innerBld.workingTables = make(map[opt.WithID]exec.Node, len(b.workingTables)+1)
+		for id, ref := range b.workingTables {
+			innerBld.workingTables[id] = ref
+		}
+		innerBld.workingTables[rec.WithID] = bufferRef
+
+		plan, err := innerBld.buildRelational(rec.Recursive)
+		if err != nil {
+			return nil, err
+		}
+		if len(innerBld.subqueries) > 0 {
+			return nil, pgerror.Unimplemented(
+				"recursive-cte-subquery", "subqueries not supported in recursive CTE",
+			)
+		}
+		plan, err = innerBld.ensureColumns(
+			plan, rec.RecursiveCols, nil /* colNames */, rec.Recursive.ProvidedPhysical().Ordering,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return plan.root, nil
+	}
+
+	node, err := b.factory.ConstructRecursiveCTE(initial.root, fn, rec.Name, rec.Deduplicate)
+	if err != nil {
+		return execPlan{}, err
+	}
+	ep := execPlan{root: node}
+	for i, col := range rec.OutCols {
+		ep.outputCols.Set(int(col), i)
+	}
+	return ep, nil
+}
+
+func (b *Builder) buildWorkingTableScan(scan *memo.WorkingTableScanExpr) (execPlan, error) {
+	ref, ok := b.workingTables[scan.WithID]
+	if !ok {
+		return execPlan{}, errors.Errorf("working table for %s not available", scan.Name)
+	}
+	node, err := b.factory.ConstructScanBuffer(ref, scan.Name)
+	if err != nil {
+		return execPlan{}, err
+	}
+	ep := execPlan{root: node}
+	for i, col := range scan.Cols {
+		ep.outputCols.Set(int(col), i)
+	}
+	return ep, nil
+}
+
 func (b *Builder) buildProjectSet(projectSet *memo.ProjectSetExpr) (execPlan, error) {
 	input, err := b.buildRelational(projectSet.Input)
 	if err != nil {
@@ -1118,6 +1390,130 @@ func (b *Builder) buildProjectSet(projectSet *memo.ProjectSetExpr) (execPlan, er
 	return ep, nil
 }
 
+func (b *Builder) buildWindow(w *memo.WindowExpr) (execPlan, error) {
+	input, err := b.buildRelational(w.Input)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The window node expects the arguments of the window functions to be the
+	// first input columns, in function order, followed by the columns that are
+	// passed through. Project the input accordingly.
+	md := b.mem.Metadata()
+	var projectCols []exec.ColumnOrdinal
+	for i := range w.Windows {
+		fn := w.Windows[i].Function.(*memo.WindowFunctionExpr)
+		for _, arg := range fn.Args {
+			v, ok := arg.(*memo.VariableExpr)
+			if !ok {
+				return execPlan{}, errors.Errorf("only VariableOp args supported")
+			}
+			projectCols = append(projectCols, input.getColumnOrdinal(v.Col))
+		}
+	}
+	numArgs := len(projectCols)
+
+	// passthrough maps the input columns to their ordinals in the projection.
+	var passthrough execPlan
+	passthroughCols := w.Input.Relational().OutputCols
+	resultCols := make(
+		sqlbase.ResultColumns, len(w.Windows), len(w.Windows)+passthroughCols.Len(),
+	)
+	passthroughCols.ForEach(func(i int) {
+		passthrough.outputCols.Set(i, len(projectCols))
+		projectCols = append(projectCols, input.getColumnOrdinal(opt.ColumnID(i)))
+		colMeta := md.ColumnMeta(opt.ColumnID(i))
+		resultCols = append(resultCols, sqlbase.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type})
+	})
+	passthrough.root, err = b.factory.ConstructSimpleProject(
+		input.root, projectCols, nil /* colNames */, nil, /* reqOrdering */
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	partition := make([]exec.ColumnOrdinal, 0, w.Partition.Len())
+	partitionExprs := make(tree.Exprs, 0, w.Partition.Len())
+	w.Partition.ForEach(func(i int) {
+		ord := passthrough.getColumnOrdinal(opt.ColumnID(i))
+		partition = append(partition, ord)
+		partitionExprs = append(partitionExprs, tree.NewTypedOrdinalReference(
+			int(ord), md.ColumnMeta(opt.ColumnID(i)).Type,
+		))
+	})
+
+	optOrdering := w.Ordering.ToOrdering()
+	ordering := passthrough.sqlOrdering(optOrdering)
+	orderBy := make(tree.OrderBy, len(ordering))
+	for i := range ordering {
+		direction := tree.Ascending
+		if ordering[i].Direction == encoding.Descending {
+			direction = tree.Descending
+		}
+		orderBy[i] = &tree.Order{
+			OrderType: tree.OrderByColumn,
+			Expr: tree.NewTypedOrdinalReference(
+				ordering[i].ColIdx, md.ColumnMeta(optOrdering[i].ID()).Type,
+			),
+			Direction: direction,
+		}
+	}
+
+	var ep execPlan
+	exprs := make([]*tree.FuncExpr, len(w.Windows))
+	argIdx := 0
+	for i := range w.Windows {
+		item := &w.Windows[i]
+		fn := item.Function.(*memo.WindowFunctionExpr)
+		args := make(tree.TypedExprs, len(fn.Args))
+		for j := range fn.Args {
+			args[j] = tree.NewTypedOrdinalReference(argIdx, fn.Args[j].DataType())
+			argIdx++
+		}
+
+		windowDef := &tree.WindowDef{
+			Partitions: partitionExprs,
+			OrderBy:    orderBy,
+		}
+		if !item.Frame.IsDefault() {
+			windowDef.Frame = item.Frame.TreeFrame()
+		}
+
+		exprs[i] = tree.NewTypedFuncExpr(
+			tree.WrapFunction(fn.Name),
+			0, /* aggQualifier */
+			args,
+			nil, /* filter */
+			windowDef,
+			fn.Typ,
+			fn.Properties,
+			fn.Overload,
+		)
+
+		colMeta := md.ColumnMeta(item.Col)
+		resultCols[i] = sqlbase.ResultColumn{Name: colMeta.Alias, Typ: colMeta.Type}
+		ep.outputCols.Set(int(item.Col), i)
+	}
+
+	// The results of the window functions are followed by the passthrough
+	// columns.
+	passthroughCols.ForEach(func(i int) {
+		ord, _ := passthrough.outputCols.Get(i)
+		ep.outputCols.Set(i, len(w.Windows)+ord-numArgs)
+	})
+
+	ep.root, err = b.factory.ConstructWindow(passthrough.root, exec.WindowInfo{
+		Cols:      resultCols,
+		Exprs:     exprs,
+		Partition: partition,
+		Ordering:  ordering,
+	})
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
 func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 	// Build the input query and ensure that the input columns that correspond to
 	// the table columns are projected.
@@ -1134,14 +1530,23 @@ func (b *Builder) buildInsert(ins *memo.InsertExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	input, err = b.bufferMutationInput(input, &ins.MutationPrivate)
+	if err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the Insert node.
 	tab := b.mem.Metadata().Table(ins.Table)
 	insertOrds := ordinalSetFromColList(ins.InsertCols)
-	node, err := b.factory.ConstructInsert(input.root, tab, insertOrds, ins.NeedResults)
+	node, err := b.factory.ConstructInsert(
+		input.root, tab, insertOrds, !ins.FKFallback /* skipFKChecks */, ins.NeedResults,
+	)
 	if err != nil {
 		return execPlan{}, err
 	}
+	if err := b.buildFKChecksAndCascades(ins.Checks, ins.Cascades); err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the output column map.
 	ep := execPlan{root: node}
@@ -1177,16 +1582,30 @@ func (b *Builder) buildUpdate(upd *memo.UpdateExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	input, err = b.bufferMutationInput(input, &upd.MutationPrivate)
+	if err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the Update node.
 	md := b.mem.Metadata()
 	tab := md.Table(upd.Table)
 	fetchColOrds := ordinalSetFromColList(upd.FetchCols)
 	updateColOrds := ordinalSetFromColList(upd.UpdateCols)
-	node, err := b.factory.ConstructUpdate(input.root, tab, fetchColOrds, updateColOrds, upd.NeedResults)
+	node, err := b.factory.ConstructUpdate(
+		input.root,
+		tab,
+		fetchColOrds,
+		updateColOrds,
+		!upd.FKFallback, /* skipFKChecks */
+		upd.NeedResults,
+	)
 	if err != nil {
 		return execPlan{}, err
 	}
+	if err := b.buildFKChecksAndCascades(upd.Checks, upd.Cascades); err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the output column map.
 	ep := execPlan{root: node}
@@ -1197,6 +1616,12 @@ func (b *Builder) buildUpdate(upd *memo.UpdateExpr) (execPlan, error) {
 }
 
 func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
+	// Check for the fast path case of an upsert that can skip fetching the
+	// existing rows and overwrite them instead.
+	if insertInput, ok := b.canUseFastUpsert(ups); ok {
+		return b.buildFastUpsert(ups, insertInput)
+	}
+
 	// Build the input query and ensure that the insert, fetch, and update columns
 	// are projected.
 	input, err := b.buildRelational(ups.Input)
@@ -1226,6 +1651,10 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	input, err = b.bufferMutationInput(input, &ups.MutationPrivate)
+	if err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the Upsert node.
 	md := b.mem.Metadata()
@@ -1235,10 +1664,21 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 	fetchColOrds := ordinalSetFromColList(ups.FetchCols)
 	updateColOrds := ordinalSetFromColList(ups.UpdateCols)
 	node, err := b.factory.ConstructUpsert(
-		input.root, tab, canaryCol, insertColOrds, fetchColOrds, updateColOrds, ups.NeedResults)
+		input.root,
+		tab,
+		canaryCol,
+		insertColOrds,
+		fetchColOrds,
+		updateColOrds,
+		!ups.FKFallback, /* skipFKChecks */
+		ups.NeedResults,
+	)
 	if err != nil {
 		return execPlan{}, err
 	}
+	if err := b.buildFKChecksAndCascades(ups.Checks, ups.Cascades); err != nil {
+		return execPlan{}, err
+	}
 
 	// If UPSERT returns rows, they contain all non-mutation columns from the
 	// table, in the same order they're defined in the table. Each output column
@@ -1252,6 +1692,12 @@ func (b *Builder) buildUpsert(ups *memo.UpsertExpr) (execPlan, error) {
 }
 
 func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
+	// Check for the fast path case of a delete that can skip fetching the rows
+	// and delete the ranges they are stored in instead.
+	if b.canUseDeleteRange(del) {
+		return b.buildDeleteRange(del)
+	}
+
 	// Build the input query and ensure that the fetch columns are projected.
 	input, err := b.buildRelational(del.Input)
 	if err != nil {
@@ -1266,15 +1712,24 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	if err != nil {
 		return execPlan{}, err
 	}
+	input, err = b.bufferMutationInput(input, &del.MutationPrivate)
+	if err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the Delete node.
 	md := b.mem.Metadata()
 	tab := md.Table(del.Table)
 	fetchColOrds := ordinalSetFromColList(del.FetchCols)
-	node, err := b.factory.ConstructDelete(input.root, tab, fetchColOrds, del.NeedResults)
+	node, err := b.factory.ConstructDelete(
+		input.root, tab, fetchColOrds, !del.FKFallback /* skipFKChecks */, del.NeedResults,
+	)
 	if err != nil {
 		return execPlan{}, err
 	}
+	if err := b.buildFKChecksAndCascades(del.Checks, del.Cascades); err != nil {
+		return execPlan{}, err
+	}
 
 	// Construct the output column map.
 	ep := execPlan{root: node}
@@ -1284,6 +1739,305 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	return ep, nil
 }
 
+// canUseDeleteRange returns true if the given Delete operator can be executed
+// by deleting ranges of the primary index, instead of fetching and deleting
+// each row separately. This is the case for simple deletes like:
+//
+//   DELETE FROM t WHERE k = 1
+//
+// The execution engine performs additional checks at runtime (see
+// canDeleteFast), and falls back on a regular delete if the fast path turns out
+// not to be possible (e.g. because the table is referenced by a foreign key).
+func (b *Builder) canUseDeleteRange(del *memo.DeleteExpr) bool {
+	// If the deleted rows need to be returned, they have to be fetched.
+	if del.NeedResults {
+		return false
+	}
+
+	// The foreign key checks and cascades planned by the optimizer need the
+	// deleted rows.
+	if del.WithID != 0 {
+		return false
+	}
+
+	// Secondary indexes require the values of the indexed columns, so that the
+	// corresponding index entries can be deleted.
+	tab := b.mem.Metadata().Table(del.Table)
+	if tab.IndexCount() > 1 {
+		return false
+	}
+
+	// The input must be an unlimited scan of the primary index, without any
+	// remaining filter. A limited scan would delete only some of the rows in
+	// its spans.
+	scan, ok := del.Input.(*memo.ScanExpr)
+	if !ok {
+		return false
+	}
+	return scan.Index == cat.PrimaryIndex && !scan.HardLimit.IsSet()
+}
+
+// buildDeleteRange builds a DeleteRange operator for a Delete operator for
+// which canUseDeleteRange returned true.
+func (b *Builder) buildDeleteRange(del *memo.DeleteExpr) (execPlan, error) {
+	scan := del.Input.(*memo.ScanExpr)
+	tab := b.mem.Metadata().Table(del.Table)
+	fetchColOrds := ordinalSetFromColList(del.FetchCols)
+	node, err := b.factory.ConstructDeleteRange(tab, fetchColOrds, scan.Constraint)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return execPlan{root: node}, nil
+}
+
+// canUseFastUpsert returns true if the given Upsert operator can be executed by
+// writing each of its rows to the primary index, instead of fetching the
+// existing row with the same primary key in order to decide between inserting
+// and updating. This is the case for simple upserts like:
+//
+//   UPSERT INTO t VALUES (1, 2)
+//
+// where t has no secondary indexes, and where all of its columns are given a
+// value, so that an existing row is overwritten entirely. If so, it also
+// returns the expression that produces the insert columns, which is the input
+// of the left join that fetches the existing rows.
+func (b *Builder) canUseFastUpsert(ups *memo.UpsertExpr) (_ memo.RelExpr, ok bool) {
+	// If the upserted rows need to be returned, the existing rows have to be
+	// fetched, since the returned values depend on whether they exist.
+	if ups.NeedResults {
+		return nil, false
+	}
+
+	// The foreign key checks and cascades planned by the optimizer need the
+	// fetched rows.
+	if ups.WithID != 0 {
+		return nil, false
+	}
+
+	// Secondary indexes require the existing values of the indexed columns, so
+	// that the old index entries can be deleted.
+	tab := b.mem.Metadata().Table(ups.Table)
+	if tab.IndexCount() > 1 || tab.MutationIndexCount() > 0 {
+		return nil, false
+	}
+
+	// Every column must be inserted, and every column that is not part of the
+	// primary key must be updated to the inserted value. Computed and mutation
+	// columns require separate update values.
+	var pkCols util.FastIntSet
+	primary := tab.Index(cat.PrimaryIndex)
+	for i, n := 0, primary.KeyColumnCount(); i < n; i++ {
+		pkCols.Add(primary.Column(i).Ordinal)
+	}
+	var insertCols opt.ColSet
+	for i, n := 0, tab.ColumnCount(); i < n; i++ {
+		if cat.IsMutationColumn(tab, i) || tab.Column(i).IsComputed() {
+			return nil, false
+		}
+		insertCol := ups.InsertCols[i]
+		if insertCol == 0 {
+			return nil, false
+		}
+		if !pkCols.Contains(i) && ups.UpdateCols[i] != insertCol {
+			return nil, false
+		}
+		insertCols.Add(int(insertCol))
+	}
+
+	// Find the left join that fetches the existing rows. Only projections are
+	// allowed above it; a filter would prevent some rows from being upserted.
+	input := ups.Input
+	for input.Op() == opt.ProjectOp {
+		input = input.(*memo.ProjectExpr).Input
+	}
+	switch t := input.(type) {
+	case *memo.LeftJoinExpr:
+		scan, ok := t.Right.(*memo.ScanExpr)
+		if !ok || b.mem.Metadata().Table(scan.Table) != tab {
+			return nil, false
+		}
+		input = t.Left
+
+	case *memo.LookupJoinExpr:
+		if t.JoinType != opt.LeftJoinOp || b.mem.Metadata().Table(t.Table) != tab {
+			return nil, false
+		}
+		input = t.Input
+
+	default:
+		return nil, false
+	}
+
+	// The input of the join must produce all the insert columns.
+	if !insertCols.SubsetOf(input.Relational().OutputCols) {
+		return nil, false
+	}
+	return input, true
+}
+
+// buildFastUpsert builds a fast upsert node for an Upsert operator for which
+// canUseFastUpsert returned true, using the given expression as its input.
+func (b *Builder) buildFastUpsert(
+	ups *memo.UpsertExpr, insertInput memo.RelExpr,
+) (execPlan, error) {
+	input, err := b.buildRelational(insertInput)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// Ensure that the order of the input columns matches the order of the target
+	// table columns.
+	colList := make(opt.ColList, 0, len(ups.InsertCols))
+	colList = appendColsWhenPresent(colList, ups.InsertCols)
+	input, err = b.ensureColumns(input, colList, nil, nil /* provided */)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	tab := b.mem.Metadata().Table(ups.Table)
+	insertColOrds := ordinalSetFromColList(ups.InsertCols)
+	node, err := b.factory.ConstructFastUpsert(
+		input.root, tab, insertColOrds, !ups.FKFallback, /* skipFKChecks */
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return execPlan{root: node}, nil
+}
+
+// bufferMutationInput wraps the input of a mutation in a buffer node if the
+// foreign key checks or cascades of the mutation read the mutated rows (through
+// WithScan operators). The buffered plan is saved in b.buffers, from where it
+// is retrieved by buildWithScan.
+func (b *Builder) bufferMutationInput(
+	input execPlan, private *memo.MutationPrivate,
+) (execPlan, error) {
+	if private.WithID == 0 {
+		return input, nil
+	}
+	node, err := b.factory.ConstructBuffer(input.root, bufferLabel(private.WithID))
+	if err != nil {
+		return execPlan{}, err
+	}
+	input.root = node
+	if b.buffers == nil {
+		b.buffers = make(map[opt.WithID]execPlan)
+	}
+	b.buffers[private.WithID] = input
+	return input, nil
+}
+
+func (b *Builder) buildWithScan(withScan *memo.WithScanExpr) (execPlan, error) {
+	buffer, ok := b.buffers[withScan.WithID]
+	if !ok {
+		return execPlan{}, errors.Errorf("buffer for %s not available", withScan.Name)
+	}
+	node, err := b.factory.ConstructScanBuffer(buffer.root, bufferLabel(withScan.WithID))
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The buffer contains all the input columns of the mutation; project the
+	// scanned ones, which take on the IDs of the output columns.
+	cols := make([]exec.ColumnOrdinal, len(withScan.InCols))
+	ep := execPlan{}
+	for i, col := range withScan.InCols {
+		cols[i] = buffer.getColumnOrdinal(col)
+		ep.outputCols.Set(int(withScan.OutCols[i]), i)
+	}
+	ep.root, err = b.factory.ConstructSimpleProject(
+		node, cols, nil /* colNames */, nil, /* reqOrdering */
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+	return ep, nil
+}
+
+// buildFKChecksAndCascades builds the foreign key cascades and checks of a
+// mutation, and adds them to the list of postqueries. Each cascade is followed
+// by its own checks and cascades; the checks of the mutation come last, so that
+// they see the effects of all the cascades.
+func (b *Builder) buildFKChecksAndCascades(
+	checks memo.FKChecksExpr, cascades memo.FKCascadesExpr,
+) error {
+	for i := range cascades {
+		// Reserve the slot of the cascade before building it, since building it
+		// adds its own checks and cascades to the postqueries.
+		idx := len(b.postqueries)
+		b.postqueries = append(b.postqueries, nil)
+		cascade, err := b.buildRelational(cascades[i].Cascade)
+		if err != nil {
+			return err
+		}
+		b.postqueries[idx] = cascade.root
+	}
+
+	for i := range checks {
+		c := &checks[i]
+		query, err := b.buildRelational(c.Check)
+		if err != nil {
+			return err
+		}
+		query, err = b.ensureColumns(
+			query, c.KeyCols, nil /* colNames */, c.Check.ProvidedPhysical().Ordering,
+		)
+		if err != nil {
+			return err
+		}
+		node, err := b.factory.ConstructErrorIfRows(query.root, b.fkCheckErrorFn(c))
+		if err != nil {
+			return err
+		}
+		b.postqueries = append(b.postqueries, node)
+	}
+	return nil
+}
+
+// fkCheckErrorFn returns a function that creates the foreign key violation
+// error for the given check, from the first row returned by the check query.
+// The errors match those returned by the row-based checks in the row package.
+func (b *Builder) fkCheckErrorFn(c *memo.FKChecksItem) func(tree.Datums) error {
+	md := b.mem.Metadata()
+	originTab := md.Table(c.OriginTable)
+	refTab := md.Table(c.ReferencedTable)
+	originIdx := originTab.Index(c.OriginIndex)
+	fk, _ := originIdx.ForeignKey()
+	refIdx := refTab.Index(cat.FindIndexByID(refTab, fk.IndexID))
+	refColNames := indexColumnNames(refIdx, len(c.KeyCols))
+
+	if c.FKOutbound {
+		refTabName, refIdxName := string(refTab.Name().TableName), string(refIdx.Name())
+		return func(values tree.Datums) error {
+			return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+				"foreign key violation: value %s not found in %s@%s %s",
+				values, refTabName, refIdxName, refColNames)
+		}
+	}
+
+	originTabName := string(originTab.Name().TableName)
+	return func(values tree.Datums) error {
+		return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+			"foreign key violation: values %v in columns %s referenced in table %q",
+			values, refColNames, originTabName)
+	}
+}
+
+// indexColumnNames returns the names of the first n columns of the index.
+func indexColumnNames(idx cat.Index, n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = string(idx.Column(i).Column.ColName())
+	}
+	return names
+}
+
+// bufferLabel returns the label of the buffer node with the given WithID, and
+// of the nodes that scan it, in an EXPLAIN output.
+func bufferLabel(withID opt.WithID) string {
+	return fmt.Sprintf("buffer %d", withID)
+}
+
 func (b *Builder) buildCreateTable(ct *memo.CreateTableExpr) (execPlan, error) {
 	var root exec.Node
 	if ct.Syntax.As() {
@@ -1391,7 +2145,7 @@ func (b *Builder) buildExplain(explain *memo.ExplainExpr) (execPlan, error) {
 		return execPlan{}, err
 	}
 
-	plan, err := b.factory.ConstructPlan(input.root, b.subqueries)
+	plan, err := b.factory.ConstructPlan(input.root, b.subqueries, b.postqueries)
 	if err != nil {
 		return execPlan{}, err
 	}
@@ -1403,9 +2157,10 @@ func (b *Builder) buildExplain(explain *memo.ExplainExpr) (execPlan, error) {
 	for i := range explain.ColList {
 		ep.outputCols.Set(int(explain.ColList[i]), i)
 	}
-	// The subqueries are now owned by the explain node; remove them so they don't
-	// also show up in the final plan.
+	// The subqueries and postqueries are now owned by the explain node; remove
+	// them so they don't also show up in the final plan.
 	b.subqueries = b.subqueries[:0]
+	b.postqueries = b.postqueries[:0]
 	return ep, nil
 }
 
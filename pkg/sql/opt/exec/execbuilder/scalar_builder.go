@@ -134,7 +134,19 @@ func (b *Builder) buildNull(ctx *buildScalarCtx, scalar opt.ScalarExpr) (tree.Ty
 func (b *Builder) buildVariable(
 	ctx *buildScalarCtx, scalar opt.ScalarExpr,
 ) (tree.TypedExpr, error) {
-	return b.indexedVar(ctx, b.mem.Metadata(), *scalar.Private().(*opt.ColumnID)), nil
+	md := b.mem.Metadata()
+	colID := *scalar.Private().(*opt.ColumnID)
+	if _, ok := ctx.ivarMap.Get(int(colID)); !ok {
+		if d, ok := b.outerBindings[colID]; ok {
+			// The variable refers to the left side of an apply join; use its value
+			// in the current left row.
+			if d == tree.DNull {
+				return tree.ReType(tree.DNull, md.ColumnMeta(colID).Type)
+			}
+			return d, nil
+		}
+	}
+	return b.indexedVar(ctx, md, colID), nil
 }
 
 func (b *Builder) indexedVar(
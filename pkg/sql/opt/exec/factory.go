@@ -55,6 +55,9 @@ type Factory interface {
 	//     the scan.
 	//   - If maxResults > 0, the scan is guaranteed to return at most maxResults
 	//     rows.
+	//   - If locking is not ForNone, the scan acquires row-level locks on the
+	//     rows that it returns. waitPolicy determines how the scan handles
+	//     rows that are locked by other transactions.
 	ConstructScan(
 		table cat.Table,
 		index cat.Index,
@@ -64,6 +67,8 @@ type Factory interface {
 		reverse bool,
 		maxResults uint64,
 		reqOrdering OutputOrdering,
+		locking tree.LockingStrength,
+		waitPolicy tree.LockingWaitPolicy,
 	) (Node, error)
 
 	// ConstructVirtualScan returns a node that represents the scan of a virtual
@@ -107,6 +112,21 @@ type Factory interface {
 		extraOnCond tree.TypedExpr,
 	) (Node, error)
 
+	// ConstructApplyJoin returns a node that runs an apply join between the
+	// results of the left input node and a right side which is planned anew for
+	// each left row, using the ApplyJoinPlanRightSideFn. The right side must
+	// produce the given rightColumns.
+	//
+	// The onCond expression can refer to columns from both sides using
+	// IndexedVars (first the left columns, then the right columns).
+	ConstructApplyJoin(
+		joinType sqlbase.JoinType,
+		left Node,
+		rightColumns sqlbase.ResultColumns,
+		onCond tree.TypedExpr,
+		planRightSideFn ApplyJoinPlanRightSideFn,
+	) (Node, error)
+
 	// ConstructMergeJoin returns a node that (under distsql) runs a merge join.
 	// The ON expression can refer to columns from both inputs using IndexedVars
 	// (first the left columns, then the right columns). In addition, the i-th
@@ -139,6 +159,21 @@ type Factory interface {
 	// group) and has exactly one result row (even when there are no input rows).
 	ConstructScalarGroupBy(input Node, aggregations []AggInfo) (Node, error)
 
+	// ConstructGroupingSets returns a node that runs an aggregation for several
+	// grouping sets (as specified by a GROUP BY with ROLLUP, CUBE or GROUPING
+	// SETS). Each grouping set is a subset of groupCols; a set of aggregations is
+	// performed for each group of values on the columns of each grouping set.
+	// The output columns are the groupCols, followed by the aggregations,
+	// followed by an INT column with the ordinal of the grouping set that
+	// produced the row. The groupCols that are not part of that grouping set
+	// are NULL.
+	ConstructGroupingSets(
+		input Node,
+		groupCols []ColumnOrdinal,
+		groupingSets []ColumnOrdinalSet,
+		aggregations []AggInfo,
+	) (Node, error)
+
 	// ConstructDistinct returns a node that filters out rows such that only the
 	// first row is kept for each set of values along the distinct columns.
 	// The orderedCols are a subset of distinctCols; the input is required to be
@@ -221,12 +256,20 @@ type Factory interface {
 		n Node, exprs tree.TypedExprs, zipCols sqlbase.ResultColumns, numColsPerGen []int,
 	) (Node, error)
 
+	// ConstructWindow returns a node that computes the given window functions
+	// over the output of the given node. See WindowInfo for the expected
+	// layout of the input and output columns.
+	ConstructWindow(input Node, window WindowInfo) (Node, error)
+
 	// RenameColumns modifies the column names of a node.
 	RenameColumns(input Node, colNames []string) (Node, error)
 
 	// ConstructPlan creates a plan enclosing the given plan and (optionally)
-	// subqueries.
-	ConstructPlan(root Node, subqueries []Subquery) (Plan, error)
+	// subqueries and postqueries. Subqueries are executed before the root node,
+	// which can refer to subquery results. Postqueries are executed, in order,
+	// after the root node has been run to completion; they are used to check
+	// foreign keys and to run cascading actions.
+	ConstructPlan(root Node, subqueries []Subquery, postqueries []Node) (Plan, error)
 
 	// ConstructExplain returns a node that implements EXPLAIN, showing
 	// information about the given plan.
@@ -242,10 +285,16 @@ type Factory interface {
 	// positions of columns in the table into which values are inserted. All
 	// columns are expected to be present except delete-only mutation columns,
 	// since those do not need to participate in an insert operation. The
+	// skipFKChecks parameter is true if the foreign key checks were planned by
+	// the optimizer, in which case the node does not perform them itself. The
 	// rowsNeeded parameter is true if a RETURNING clause needs the inserted
 	// row(s) as output.
 	ConstructInsert(
-		input Node, table cat.Table, insertCols ColumnOrdinalSet, rowsNeeded bool,
+		input Node,
+		table cat.Table,
+		insertCols ColumnOrdinalSet,
+		skipFKChecks bool,
+		rowsNeeded bool,
 	) (Node, error)
 
 	// ConstructUpdate creates a node that implements an UPDATE statement. The
@@ -258,10 +307,18 @@ type Factory interface {
 	// The fetchCols and updateCols sets contain the ordinal positions of the
 	// fetch and update columns in the target table. The input must contain those
 	// columns in the same order as they appear in the table schema, with the
-	// fetch columns first and the update columns second. The rowsNeeded parameter
-	// is true if a RETURNING clause needs the updated row(s) as output.
+	// fetch columns first and the update columns second. The skipFKChecks
+	// parameter is true if the foreign key checks and cascades were planned by
+	// the optimizer, in which case the node does not perform them itself. The
+	// rowsNeeded parameter is true if a RETURNING clause needs the updated row(s)
+	// as output.
 	ConstructUpdate(
-		input Node, table cat.Table, fetchCols, updateCols ColumnOrdinalSet, rowsNeeded bool,
+		input Node,
+		table cat.Table,
+		fetchCols ColumnOrdinalSet,
+		updateCols ColumnOrdinalSet,
+		skipFKChecks bool,
+		rowsNeeded bool,
 	) (Node, error)
 
 	// ConstructUpsert creates a node that implements an INSERT..ON CONFLICT or
@@ -294,26 +351,99 @@ type Factory interface {
 		insertCols ColumnOrdinalSet,
 		fetchCols ColumnOrdinalSet,
 		updateCols ColumnOrdinalSet,
+		skipFKChecks bool,
 		rowsNeeded bool,
 	) (Node, error)
 
+	// ConstructFastUpsert creates a node that implements an UPSERT statement by
+	// writing each input row to the table's primary index, without first
+	// fetching the existing row with the same primary key. This is only possible
+	// if the table has no secondary indexes and if the input contains a value
+	// for each of the table's columns, so that any existing row is overwritten
+	// entirely. The insertCols set contains the ordinal positions of the table
+	// columns, which the input contains in the same order as they appear in the
+	// table schema.
+	ConstructFastUpsert(
+		input Node, table cat.Table, insertCols ColumnOrdinalSet, skipFKChecks bool,
+	) (Node, error)
+
 	// ConstructDelete creates a node that implements a DELETE statement. The
 	// input contains columns that were fetched from the target table, and that
 	// will be deleted.
 	//
 	// The fetchCols set contains the ordinal positions of the fetch columns in
 	// the target table. The input must contain those columns in the same order
-	// as they appear in the table schema. The rowsNeeded parameter is true if a
-	// RETURNING clause needs the deleted row(s) as output.
+	// as they appear in the table schema. The skipFKChecks parameter is true if
+	// the foreign key checks and cascades were planned by the optimizer, in which
+	// case the node does not perform them itself. The rowsNeeded parameter is
+	// true if a RETURNING clause needs the deleted row(s) as output.
 	ConstructDelete(
-		input Node, table cat.Table, fetchCols ColumnOrdinalSet, rowsNeeded bool,
+		input Node,
+		table cat.Table,
+		fetchCols ColumnOrdinalSet,
+		skipFKChecks bool,
+		rowsNeeded bool,
+	) (Node, error)
+
+	// ConstructDeleteRange creates a node that efficiently deletes contiguous
+	// rows stored in the given table's primary index, without first fetching
+	// them. The rows are the ones that a scan of the primary index would return
+	// for the given index constraint (the whole index if it is nil).
+	//
+	// The fetchCols set contains the ordinal positions of the columns that would
+	// be fetched by a regular Delete. It is used when the execution engine is
+	// unable to use the fast path at runtime (e.g. if a secondary index is being
+	// added to the table), in which case the rows are fetched and deleted one by
+	// one, as if ConstructDelete had been called with a scan as input.
+	ConstructDeleteRange(
+		table cat.Table, fetchCols ColumnOrdinalSet, indexConstraint *constraint.Constraint,
 	) (Node, error)
 
 	// ConstructCreateTable returns a node that implements a CREATE TABLE
 	// statement.
 	ConstructCreateTable(input Node, schema cat.Schema, ct *tree.CreateTable) (Node, error)
+
+	// ConstructRecursiveCTE returns a node that executes a recursive CTE:
+	//   - the initial plan is run first; the results are emitted and also saved
+	//     in a buffer.
+	//   - so long as the last buffer is not empty:
+	//     - the RecursiveCTEIterationFn is used to create a plan for the
+	//       recursive side; a reference to the last buffer is passed to this
+	//       function. The returned plan can refer to this reference using a
+	//       ConstructScanBuffer call.
+	//     - the plan is executed; the results are emitted and also saved in a new
+	//       buffer for the next iteration.
+	// If deduplicate is true, rows that were already emitted are discarded
+	// (UNION semantics); otherwise all rows are emitted (UNION ALL semantics).
+	ConstructRecursiveCTE(
+		initial Node, fn RecursiveCTEIterationFn, label string, deduplicate bool,
+	) (Node, error)
+
+	// ConstructScanBuffer returns a node that scans the buffer referenced by
+	// ref. The reference is either a node returned by ConstructBuffer, or the
+	// reference passed to a RecursiveCTEIterationFn (in which case the node is
+	// only valid inside the plan created by that function).
+	ConstructScanBuffer(ref Node, label string) (Node, error)
+
+	// ConstructBuffer returns a node that passes through the rows of its input
+	// and also saves them in a buffer, so that they can be scanned again (by
+	// nodes created with ConstructScanBuffer) once the node has been run.
+	ConstructBuffer(input Node, label string) (Node, error)
+
+	// ConstructErrorIfRows returns a node that runs its input and returns an
+	// error, created by mkErr from the first row, if the input has any rows.
+	ConstructErrorIfRows(input Node, mkErr func(tree.Datums) error) (Node, error)
 }
 
+// RecursiveCTEIterationFn creates a plan for an iteration of WITH RECURSIVE,
+// given the reference to the buffer that holds the results of the previous
+// iteration (see ConstructRecursiveCTE).
+type RecursiveCTEIterationFn func(bufferRef Node) (Node, error)
+
+// ApplyJoinPlanRightSideFn creates a plan for the right side of an apply
+// join, given a row of the left side (see ConstructApplyJoin).
+type ApplyJoinPlanRightSideFn func(leftRow tree.Datums) (Node, error)
+
 // OutputOrdering indicates the required output ordering on a Node that is being
 // created. It refers to the output columns of the node by ordinal.
 //
@@ -375,4 +505,33 @@ type AggInfo struct {
 	// ConstArgs is the list of any constant arguments to the aggregate,
 	// for instance, the separator in string_agg.
 	ConstArgs []tree.Datum
+
+	// Filter is the index of the boolean column used as the FILTER condition
+	// of the aggregate; only rows for which the column is true are aggregated.
+	// If there is no filter, Filter is -1.
+	Filter ColumnOrdinal
+}
+
+// WindowInfo represents the information about the window functions computed
+// by a window node (see ConstructWindow). All the functions share the same
+// partitioning and ordering.
+//
+// The input columns start with the arguments of the functions, in function
+// order, followed by the columns that are passed through. The output columns
+// are the results of the functions, in function order, followed by the columns
+// that are passed through.
+type WindowInfo struct {
+	// Cols is the set of columns that are returned from the window node.
+	Cols sqlbase.ResultColumns
+
+	// Exprs is the list of window function expressions. The arguments of the
+	// functions are ordinal references to input columns, and each expression
+	// holds the frame of its window in WindowDef.
+	Exprs []*tree.FuncExpr
+
+	// Partition is the set of input columns to partition on.
+	Partition []ColumnOrdinal
+
+	// Ordering is the ordering of the input columns within each partition.
+	Ordering sqlbase.ColumnOrdering
 }
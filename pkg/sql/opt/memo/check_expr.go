@@ -102,6 +102,12 @@ func (m *Memo) checkExpr(e opt.Expr) {
 			case opt.AggDistinctOp:
 				checkAggs(scalar.Child(0).(opt.ScalarExpr))
 
+			case opt.AggFilterOp:
+				if scalar.Child(1).Op() != opt.VariableOp {
+					panic(fmt.Sprintf("aggregate filter is not a variable: %s", scalar.Child(1).Op()))
+				}
+				checkAggs(scalar.Child(0).(opt.ScalarExpr))
+
 			case opt.VariableOp:
 
 			default:
@@ -125,6 +131,42 @@ func (m *Memo) checkExpr(e opt.Expr) {
 			}
 		}
 
+	case *GroupingSetsGroupByExpr:
+		for _, set := range t.GroupingSets {
+			if !set.SubsetOf(t.GroupingCols) {
+				panic(fmt.Sprintf("grouping set %s is not a subset of grouping columns %s",
+					set, t.GroupingCols))
+			}
+		}
+		if t.GroupingSetIDCol == 0 {
+			panic("grouping set id column cannot have id of 0")
+		}
+
+	case *WindowExpr:
+		inputCols := t.Input.Relational().OutputCols
+		if !t.Partition.SubsetOf(inputCols) {
+			panic(fmt.Sprintf("window partition columns %s not produced by input", t.Partition))
+		}
+		for _, item := range t.Windows {
+			// Check that windows only contain window functions of variables.
+			if item.Function.Op() != opt.WindowFunctionOp {
+				panic(fmt.Sprintf("window contains illegal op: %s", item.Function.Op()))
+			}
+			for _, arg := range item.Function.(*WindowFunctionExpr).Args {
+				if arg.Op() != opt.VariableOp {
+					panic(fmt.Sprintf("window function argument is not a variable: %s", arg.Op()))
+				}
+			}
+
+			// Check that column id is set and is not an input column.
+			if item.Col == 0 {
+				panic("windows column cannot have id of 0")
+			}
+			if inputCols.Contains(int(item.Col)) {
+				panic(fmt.Sprintf("window passes through column %d", item.Col))
+			}
+		}
+
 	case *DistinctOnExpr:
 		// Check that aggregates can be only FirstAgg or ConstAgg.
 		for _, item := range t.Aggregations {
@@ -187,6 +229,17 @@ func (m *Memo) checkExpr(e opt.Expr) {
 		m.checkColListLen(t.UpdateCols, tab.ColumnCount(), "UpdateCols")
 		m.checkMutationExpr(t, &t.MutationPrivate)
 
+	case *UpsertExpr:
+		m.checkMutationExpr(t, &t.MutationPrivate)
+
+	case *DeleteExpr:
+		m.checkMutationExpr(t, &t.MutationPrivate)
+
+	case *WithScanExpr:
+		if len(t.InCols) != len(t.OutCols) {
+			panic(fmt.Sprintf("with scan has mismatching in and out columns"))
+		}
+
 	case *ZigzagJoinExpr:
 		if len(t.LeftEqCols) != len(t.RightEqCols) {
 			panic(fmt.Sprintf("zigzag join with mismatching eq columns"))
@@ -202,8 +255,12 @@ func (m *Memo) checkExpr(e opt.Expr) {
 			}
 		}
 
-		if e.Op() == opt.StringAggOp && !CanExtractConstDatum(e.Child(1)) {
-			panic(fmt.Sprintf("second argument to StringAggOp must always be constant, but got %s", e.Child(1).Op()))
+		switch e.Op() {
+		case opt.StringAggOp, opt.PercentileDiscOp, opt.PercentileContOp, opt.ApproxPercentileOp:
+			if !CanExtractConstDatum(e.Child(1)) {
+				panic(fmt.Sprintf("second argument to %s must always be constant, but got %s",
+					e.Op(), e.Child(1).Op()))
+			}
 		}
 
 		if opt.IsJoinOp(e) {
@@ -234,6 +291,20 @@ func (m *Memo) checkMutationExpr(rel RelExpr, private *MutationPrivate) {
 	if rel.Relational().OutputCols.Intersects(mutCols) {
 		panic("output columns cannot include mutation columns")
 	}
+
+	// Foreign key checks and cascades read the mutation input through WithScan
+	// operators, so the input must be buffered if there are any.
+	checks := *rel.Child(1).(*FKChecksExpr)
+	cascades := *rel.Child(2).(*FKCascadesExpr)
+	if len(checks) == 0 && len(cascades) == 0 {
+		return
+	}
+	if private.WithID == 0 {
+		panic("foreign key checks or cascades without a WithID")
+	}
+	if private.FKFallback {
+		panic("foreign key checks or cascades with FKFallback")
+	}
 }
 
 // checkExprOrdering runs checks on orderings stored inside operators.
@@ -246,6 +317,8 @@ func checkExprOrdering(e opt.Expr) {
 		ordering = *t
 	case *RowNumberPrivate:
 		ordering = t.Ordering
+	case *WindowPrivate:
+		ordering = t.Ordering
 	case GroupingPrivate:
 		ordering = t.Ordering
 	default:
@@ -15,6 +15,7 @@
 package memo
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
@@ -194,6 +195,25 @@ func (n AggregationsExpr) OutputCols() opt.ColSet {
 	return colSet
 }
 
+// OuterCols returns the set of outer columns needed by any of the window
+// functions.
+func (n WindowsExpr) OuterCols(mem *Memo) opt.ColSet {
+	var colSet opt.ColSet
+	for i := range n {
+		colSet.UnionWith(n[i].ScalarProps(mem).OuterCols)
+	}
+	return colSet
+}
+
+// OutputCols returns the set of columns constructed by the Windows expression.
+func (n WindowsExpr) OutputCols() opt.ColSet {
+	var colSet opt.ColSet
+	for i := range n {
+		colSet.Add(int(n[i].Col))
+	}
+	return colSet
+}
+
 // OuterCols returns the set of outer columns needed by any of the zip
 // expressions.
 func (n ZipExpr) OuterCols(mem *Memo) opt.ColSet {
@@ -277,6 +297,73 @@ func (sf *ScanFlags) Empty() bool {
 	return !sf.NoIndexJoin && !sf.ForceIndex
 }
 
+// WindowFrame denotes the definition of a window frame for an individual
+// window function, excluding the OFFSET expressions, if present. Frames with
+// offsets are not supported by the optimizer.
+type WindowFrame struct {
+	Mode           tree.WindowFrameMode
+	StartBoundType tree.WindowFrameBoundType
+	EndBoundType   tree.WindowFrameBoundType
+}
+
+// DefaultWindowFrame is the frame used by window functions for which no frame
+// was specified: all the rows from the start of the partition up to the last
+// peer of the current row.
+var DefaultWindowFrame = WindowFrame{
+	Mode:           tree.RANGE,
+	StartBoundType: tree.UnboundedPreceding,
+	EndBoundType:   tree.CurrentRow,
+}
+
+// IsDefault returns true if the frame is the default frame.
+func (f *WindowFrame) IsDefault() bool {
+	return *f == DefaultWindowFrame
+}
+
+// String returns a string representation of the frame, in the format of the
+// SQL frame clause.
+func (f *WindowFrame) String() string {
+	return tree.AsString(f.TreeFrame())
+}
+
+// TreeFrame returns the tree.WindowFrame that corresponds to the frame.
+func (f *WindowFrame) TreeFrame() *tree.WindowFrame {
+	return &tree.WindowFrame{
+		Mode: f.Mode,
+		Bounds: tree.WindowFrameBounds{
+			StartBound: &tree.WindowFrameBound{BoundType: f.StartBoundType},
+			EndBound:   &tree.WindowFrameBound{BoundType: f.EndBoundType},
+		},
+	}
+}
+
+// GroupingSets is the list of grouping sets of a GroupingSetsGroupBy operator.
+// Each set contains the grouping columns that are part of the set.
+type GroupingSets []opt.ColSet
+
+// HasEmptySet returns true if one of the grouping sets is empty.
+func (gs GroupingSets) HasEmptySet() bool {
+	for i := range gs {
+		if gs[i].Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a string representation of the grouping sets, for example:
+//   (1,2) (1) ()
+func (gs GroupingSets) String() string {
+	var buf bytes.Buffer
+	for i := range gs {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(gs[i].String())
+	}
+	return buf.String()
+}
+
 // MapToInputID maps from the ID of a target table column to the ID of the
 // corresponding input column that provides the value for it:
 //
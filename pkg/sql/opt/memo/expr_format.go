@@ -17,6 +17,7 @@ package memo
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
@@ -167,7 +168,8 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		f.Buffer.WriteByte(')')
 
 	case *ScanExpr, *VirtualScanExpr, *IndexJoinExpr, *ShowTraceForSessionExpr,
-		*InsertExpr, *UpdateExpr, *UpsertExpr, *DeleteExpr:
+		*InsertExpr, *UpdateExpr, *UpsertExpr, *DeleteExpr,
+		*RecursiveCTEExpr, *WorkingTableScanExpr, *WithScanExpr:
 		fmt.Fprintf(f.Buffer, "%v", e.Op())
 		FormatPrivate(f, e.Private(), required)
 
@@ -202,6 +204,23 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		*UnionAllExpr, *IntersectAllExpr, *ExceptAllExpr:
 		colList = e.Private().(*SetPrivate).OutCols
 
+	case *RecursiveCTEExpr:
+		colList = t.OutCols
+
+	case *WorkingTableScanExpr:
+		colList = t.Cols
+
+	case *WithScanExpr:
+		colList = t.OutCols
+
+	case *WindowExpr:
+		// We want the pass-through columns first, and the window function
+		// columns at the end, mapping 1-to-1 to the windows.
+		colList = opt.ColSetToList(t.Input.Relational().OutputCols)
+		for i := range t.Windows {
+			colList = append(colList, t.Windows[i].Col)
+		}
+
 	default:
 		// Fall back to writing output columns in column id order.
 		colList = opt.ColSetToList(e.Relational().OutputCols)
@@ -221,6 +240,26 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			tp.Childf("internal-ordering: %s", private.Ordering)
 		}
 
+	// Special-case handling for GroupingSetsGroupBy private; print grouping
+	// columns, grouping sets, grouping set id column and internal ordering.
+	case *GroupingSetsGroupByExpr:
+		f.formatColList(e, tp, "grouping columns:", opt.ColSetToList(t.GroupingCols))
+		tp.Childf("grouping sets: %s", t.GroupingSets)
+		f.formatColList(e, tp, "grouping set id:", opt.ColList{t.GroupingSetIDCol})
+		if !t.Ordering.Any() {
+			tp.Childf("internal-ordering: %s", t.Ordering)
+		}
+
+	// Special-case handling for Window private; print partition columns and
+	// internal ordering.
+	case *WindowExpr:
+		if !t.Partition.Empty() {
+			f.formatColList(e, tp, "partition by:", opt.ColSetToList(t.Partition))
+		}
+		if !t.Ordering.Any() {
+			tp.Childf("internal-ordering: %s", t.Ordering)
+		}
+
 	case *LimitExpr:
 		if !t.Ordering.Any() {
 			tp.Childf("internal-ordering: %s", t.Ordering)
@@ -239,6 +278,13 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 		f.formatColList(e, tp, "left columns:", private.LeftCols)
 		f.formatColList(e, tp, "right columns:", private.RightCols)
 
+	case *RecursiveCTEExpr:
+		f.formatColList(e, tp, "initial columns:", t.InitialCols)
+		f.formatColList(e, tp, "recursive columns:", t.RecursiveCols)
+
+	case *WithScanExpr:
+		f.formatColList(e, tp, "buffered columns:", t.InCols)
+
 	case *ScanExpr:
 		if t.Constraint != nil {
 			tp.Childf("constraint: %s", t.Constraint)
@@ -262,6 +308,13 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 				tp.Childf("flags: force-index=%s%s", idx.Name(), dir)
 			}
 		}
+		if t.Locking != tree.ForNone {
+			locking := t.Locking.String()
+			if t.LockingWaitPolicy != tree.LockWaitBlock {
+				locking += " " + t.LockingWaitPolicy.String()
+			}
+			tp.Childf("locking: %s", strings.ToLower(locking))
+		}
 
 	case *LookupJoinExpr:
 		idxCols := make(opt.ColList, len(t.KeyCols))
@@ -428,8 +481,9 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 
 func (f *ExprFmtCtx) formatScalar(scalar opt.ScalarExpr, tp treeprinter.Node) {
 	switch scalar.Op() {
-	case opt.ProjectionsOp, opt.AggregationsOp:
-		// Omit empty Projections and Aggregations expressions.
+	case opt.ProjectionsOp, opt.AggregationsOp, opt.FKChecksOp, opt.FKCascadesOp:
+		// Omit empty Projections, Aggregations, FKChecks and FKCascades
+		// expressions.
 		if scalar.ChildCount() == 0 {
 			return
 		}
@@ -464,13 +518,18 @@ func (f *ExprFmtCtx) formatScalar(scalar opt.ScalarExpr, tp treeprinter.Node) {
 		f.Buffer.Reset()
 		propsExpr := scalar
 		switch scalar.Op() {
-		case opt.FiltersItemOp, opt.ProjectionsItemOp, opt.AggregationsItemOp, opt.ZipItemOp:
+		case opt.FiltersItemOp, opt.ProjectionsItemOp, opt.AggregationsItemOp, opt.ZipItemOp,
+			opt.WindowsItemOp:
 			// Use properties from the item, but otherwise omit it from output.
 			scalar = scalar.Child(0).(opt.ScalarExpr)
 		}
 
 		fmt.Fprintf(f.Buffer, "%v", scalar.Op())
 		f.formatScalarPrivate(scalar)
+		if item, ok := propsExpr.(*WindowsItem); ok && !item.Frame.IsDefault() {
+			// Only show the frame if it is not the default one.
+			fmt.Fprintf(f.Buffer, " frame=%q", item.Frame.String())
+		}
 		f.FormatScalarProps(propsExpr)
 		tp = tp.Child(f.Buffer.String())
 	}
@@ -733,6 +792,12 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 			fmt.Fprintf(f.Buffer, ",ordering=%s", t.Ordering)
 		}
 
+	case *GroupingSetsPrivate:
+		fmt.Fprintf(f.Buffer, " cols=%s,sets=%s", t.GroupingCols.String(), t.GroupingSets)
+		if !t.Ordering.Any() {
+			fmt.Fprintf(f.Buffer, ",ordering=%s", t.Ordering)
+		}
+
 	case *IndexJoinPrivate:
 		tab := f.Memo.metadata.Table(t.Table)
 		fmt.Fprintf(f.Buffer, " %s", tab.Name().TableName)
@@ -763,6 +828,38 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 	case *FunctionPrivate:
 		fmt.Fprintf(f.Buffer, " %s", t.Name)
 
+	case *RecursiveCTEPrivate:
+		fmt.Fprintf(f.Buffer, " %s", t.Name)
+		if !t.Deduplicate {
+			f.Buffer.WriteString(",all")
+		}
+
+	case *WorkingTableScanPrivate:
+		fmt.Fprintf(f.Buffer, " %s", t.Name)
+
+	case *WithScanPrivate:
+		fmt.Fprintf(f.Buffer, " %s", t.Name)
+
+	case *FKChecksItemPrivate:
+		origin := f.Memo.metadata.TableMeta(t.OriginTable)
+		referenced := f.Memo.metadata.TableMeta(t.ReferencedTable)
+		originIdx := origin.Table.Index(t.OriginIndex)
+		fk, _ := originIdx.ForeignKey()
+		refIdx := referenced.Table.Index(cat.FindIndexByID(referenced.Table, fk.IndexID))
+		fmt.Fprintf(f.Buffer, " %s(", tableName(f, t.OriginTable))
+		formatIndexCols(f, originIdx, int(fk.PrefixLen))
+		fmt.Fprintf(f.Buffer, ") -> %s(", tableName(f, t.ReferencedTable))
+		formatIndexCols(f, refIdx, int(fk.PrefixLen))
+		f.Buffer.WriteByte(')')
+
+	case *FKCascadesItemPrivate:
+		origin := f.Memo.metadata.TableMeta(t.OriginTable)
+		originIdx := origin.Table.Index(t.OriginIndex)
+		fk, _ := originIdx.ForeignKey()
+		fmt.Fprintf(f.Buffer, " %s(", tableName(f, t.OriginTable))
+		formatIndexCols(f, originIdx, int(fk.PrefixLen))
+		f.Buffer.WriteByte(')')
+
 	case *physical.OrderingChoice:
 		if !t.Any() {
 			fmt.Fprintf(f.Buffer, " ordering=%s", t)
@@ -776,6 +873,17 @@ func FormatPrivate(f *ExprFmtCtx, private interface{}, physProps *physical.Requi
 	}
 }
 
+// formatIndexCols writes the names of the first n columns of the given index,
+// separated by commas.
+func formatIndexCols(f *ExprFmtCtx, idx cat.Index, n int) {
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			f.Buffer.WriteString(", ")
+		}
+		f.Buffer.WriteString(string(idx.Column(i).Column.ColName()))
+	}
+}
+
 // tableName returns the alias for a table to be used for pretty-printing.
 func tableName(f *ExprFmtCtx, tabID opt.TableID) string {
 	if f.HasFlags(ExprFmtHideQualifications) {
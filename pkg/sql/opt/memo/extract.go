@@ -120,14 +120,22 @@ func ExtractAggInputColumns(e opt.ScalarExpr) opt.ColSet {
 
 	var res opt.ColSet
 	if e.ChildCount() > 0 {
-		res.Add(int(ExtractVarFromAggInput(e.Child(0).(opt.ScalarExpr)).Col))
+		arg := e.Child(0).(opt.ScalarExpr)
+		if filter, ok := arg.(*AggFilterExpr); ok {
+			res.Add(int(filter.Filter.(*VariableExpr).Col))
+		}
+		res.Add(int(ExtractVarFromAggInput(arg).Col))
 	}
 	return res
 }
 
 // ExtractVarFromAggInput is given an argument to an Aggregate and returns the
-// inner Variable expression, stripping out modifiers like AggDistinct.
+// inner Variable expression, stripping out modifiers like AggDistinct and
+// AggFilter.
 func ExtractVarFromAggInput(arg opt.ScalarExpr) *VariableExpr {
+	if filter, ok := arg.(*AggFilterExpr); ok {
+		arg = filter.Input
+	}
 	if distinct, ok := arg.(*AggDistinctExpr); ok {
 		arg = distinct.Input
 	}
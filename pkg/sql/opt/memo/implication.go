@@ -0,0 +1,125 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package memo
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// FiltersImplyPredicate returns true if every row that satisfies the given
+// filters is known to satisfy the given predicate, such as the predicate of a
+// partial index. Each conjunct of the predicate is implied if it is also one of
+// the conjuncts of the filters (scalar expressions are interned by the memo, so
+// identical expressions can be compared by pointer), or if its constraints are
+// tight and contain the constraints of the filters. For example, the filter
+// a > 10 implies the predicate a > 5, because /a: [/11 - ] is contained in
+// /a: [/6 - ]. The constraints are only used if evalCtx is not nil.
+//
+// FiltersImplyPredicate can return false even though the filters imply the
+// predicate, so it must only be used when returning false is safe.
+func FiltersImplyPredicate(
+	mem *Memo, evalCtx *tree.EvalContext, filters FiltersExpr, pred opt.ScalarExpr,
+) bool {
+	if len(filters) == 0 {
+		return false
+	}
+
+	var conjuncts []opt.ScalarExpr
+	var collect func(e opt.ScalarExpr)
+	collect = func(e opt.ScalarExpr) {
+		if and, ok := e.(*AndExpr); ok {
+			collect(and.Left)
+			collect(and.Right)
+			return
+		}
+		conjuncts = append(conjuncts, e)
+	}
+	for i := range filters {
+		collect(filters[i].Condition)
+	}
+
+	// filtersConstraints is the intersection of the constraints of the
+	// filters. It is built lazily, since it is not needed when every conjunct
+	// of the predicate is also a conjunct of the filters.
+	var filtersConstraints *constraint.Set
+
+	var implied func(e opt.ScalarExpr) bool
+	implied = func(e opt.ScalarExpr) bool {
+		if and, ok := e.(*AndExpr); ok {
+			return implied(and.Left) && implied(and.Right)
+		}
+		for i := range conjuncts {
+			if conjuncts[i] == e {
+				return true
+			}
+		}
+		if evalCtx == nil {
+			return false
+		}
+
+		item := FiltersItem{Condition: e}
+		predProps := item.ScalarProps(mem)
+		if predProps.Constraints == nil || !predProps.TightConstraints {
+			return false
+		}
+
+		if filtersConstraints == nil {
+			filtersConstraints = constraint.Unconstrained
+			for i := range conjuncts {
+				item := FiltersItem{Condition: conjuncts[i]}
+				if cs := item.ScalarProps(mem).Constraints; cs != nil {
+					filtersConstraints = filtersConstraints.Intersect(evalCtx, cs)
+				}
+			}
+		}
+		if filtersConstraints == constraint.Contradiction {
+			// The filters don't return any row.
+			return true
+		}
+		return constraintsContain(evalCtx, predProps.Constraints, filtersConstraints)
+	}
+	return implied(pred)
+}
+
+// constraintsContain returns true if each constraint of the outer set contains
+// the constraint of the inner set on the same columns. Since the constraints of
+// a set all hold at the same time, the inner set is then contained in the outer
+// set.
+func constraintsContain(evalCtx *tree.EvalContext, outer, inner *constraint.Set) bool {
+	for i, n := 0, outer.Length(); i < n; i++ {
+		outerConstraint := outer.Constraint(i)
+		contained := false
+		for j, m := 0, inner.Length(); j < m; j++ {
+			c := inner.Constraint(j)
+			if !c.Columns.Equals(&outerConstraint.Columns) {
+				continue
+			}
+			contained = true
+			for k, spans := 0, c.Spans.Count(); k < spans; k++ {
+				if !outerConstraint.ContainsSpan(evalCtx, c.Spans.Get(k)) {
+					contained = false
+					break
+				}
+			}
+			break
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
@@ -443,6 +443,11 @@ func (h *hasher) HashTableID(val opt.TableID) {
 	h.hash *= prime64
 }
 
+func (h *hasher) HashWithID(val opt.WithID) {
+	h.hash ^= internHash(val)
+	h.hash *= prime64
+}
+
 func (h *hasher) HashScanLimit(val ScanLimit) {
 	h.hash ^= internHash(val)
 	h.hash *= prime64
@@ -455,6 +460,31 @@ func (h *hasher) HashScanFlags(val ScanFlags) {
 	h.hash *= prime64
 }
 
+func (h *hasher) HashWindowFrame(val WindowFrame) {
+	h.hash ^= internHash(val.Mode)
+	h.hash *= prime64
+	h.hash ^= internHash(val.StartBoundType)
+	h.hash *= prime64
+	h.hash ^= internHash(val.EndBoundType)
+	h.hash *= prime64
+}
+
+func (h *hasher) HashGroupingSets(val GroupingSets) {
+	for i := range val {
+		h.HashColSet(val[i])
+	}
+}
+
+func (h *hasher) HashLockingStrength(val tree.LockingStrength) {
+	h.hash ^= internHash(val)
+	h.hash *= prime64
+}
+
+func (h *hasher) HashLockingWaitPolicy(val tree.LockingWaitPolicy) {
+	h.hash ^= internHash(val)
+	h.hash *= prime64
+}
+
 func (h *hasher) HashExplainOptions(val tree.ExplainOptions) {
 	h.HashColSet(val.Flags)
 	h.hash ^= internHash(val.Mode)
@@ -525,6 +555,37 @@ func (h *hasher) HashZipExpr(val ZipExpr) {
 	}
 }
 
+func (h *hasher) HashWindowsExpr(val WindowsExpr) {
+	for i := range val {
+		item := &val[i]
+		h.HashColumnID(item.Col)
+		h.HashWindowFrame(item.Frame)
+		h.HashScalarExpr(item.Function)
+	}
+}
+
+func (h *hasher) HashFKChecksExpr(val FKChecksExpr) {
+	for i := range val {
+		item := &val[i]
+		h.HashTableID(item.OriginTable)
+		h.HashTableID(item.ReferencedTable)
+		h.HashBool(item.FKOutbound)
+		h.HashInt(item.OriginIndex)
+		h.HashColList(item.KeyCols)
+		h.HashString(item.OpName)
+		h.HashRelExpr(item.Check)
+	}
+}
+
+func (h *hasher) HashFKCascadesExpr(val FKCascadesExpr) {
+	for i := range val {
+		item := &val[i]
+		h.HashTableID(item.OriginTable)
+		h.HashInt(item.OriginIndex)
+		h.HashRelExpr(item.Cascade)
+	}
+}
+
 func (h *hasher) HashPointer(val unsafe.Pointer) {
 	h.hash ^= internHash(uintptr(val))
 	h.hash *= prime64
@@ -680,6 +741,10 @@ func (h *hasher) IsTableIDEqual(l, r opt.TableID) bool {
 	return l == r
 }
 
+func (h *hasher) IsWithIDEqual(l, r opt.WithID) bool {
+	return l == r
+}
+
 func (h *hasher) IsScanLimitEqual(l, r ScanLimit) bool {
 	return l == r
 }
@@ -688,6 +753,30 @@ func (h *hasher) IsScanFlagsEqual(l, r ScanFlags) bool {
 	return l == r
 }
 
+func (h *hasher) IsWindowFrameEqual(l, r WindowFrame) bool {
+	return l == r
+}
+
+func (h *hasher) IsGroupingSetsEqual(l, r GroupingSets) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if !l[i].Equals(r[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hasher) IsLockingStrengthEqual(l, r tree.LockingStrength) bool {
+	return l == r
+}
+
+func (h *hasher) IsLockingWaitPolicyEqual(l, r tree.LockingWaitPolicy) bool {
+	return l == r
+}
+
 func (h *hasher) IsExplainOptionsEqual(l, r tree.ExplainOptions) bool {
 	return l.Mode == r.Mode && l.Flags.Equals(r.Flags)
 }
@@ -776,6 +865,49 @@ func (h *hasher) IsZipExprEqual(l, r ZipExpr) bool {
 	return true
 }
 
+func (h *hasher) IsWindowsExprEqual(l, r WindowsExpr) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if l[i].Col != r[i].Col || l[i].Frame != r[i].Frame || l[i].Function != r[i].Function {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hasher) IsFKChecksExprEqual(l, r FKChecksExpr) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if l[i].OriginTable != r[i].OriginTable ||
+			l[i].ReferencedTable != r[i].ReferencedTable ||
+			l[i].FKOutbound != r[i].FKOutbound ||
+			l[i].OriginIndex != r[i].OriginIndex ||
+			!l[i].KeyCols.Equals(r[i].KeyCols) ||
+			l[i].OpName != r[i].OpName ||
+			l[i].Check != r[i].Check {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *hasher) IsFKCascadesExprEqual(l, r FKCascadesExpr) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i := range l {
+		if l[i].OriginTable != r[i].OriginTable || l[i].OriginIndex != r[i].OriginIndex ||
+			l[i].Cascade != r[i].Cascade {
+			return false
+		}
+	}
+	return true
+}
+
 // encodeDatum turns the given datum into an encoded string of bytes. If two
 // datums are equivalent, then their encoded bytes will be identical.
 // Conversely, if two datums are not equivalent, then their encoded bytes will
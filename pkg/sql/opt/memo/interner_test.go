@@ -96,6 +96,33 @@ func TestInterner(t *testing.T) {
 	}
 	aggs5 := AggregationsExpr{{Agg: &CountRowsExpr{}, ColPrivate: ColPrivate{Col: 1}}}
 
+	frame1 := WindowFrame{
+		Mode:           tree.ROWS,
+		StartBoundType: tree.UnboundedPreceding,
+		EndBoundType:   tree.CurrentRow,
+	}
+	frame2 := WindowFrame{
+		Mode:           tree.ROWS,
+		StartBoundType: tree.UnboundedPreceding,
+		EndBoundType:   tree.UnboundedFollowing,
+	}
+	windows1 := WindowsExpr{{
+		Function:           andExpr,
+		WindowsItemPrivate: WindowsItemPrivate{Frame: DefaultWindowFrame, Col: 1},
+	}}
+	windows2 := WindowsExpr{{
+		Function:           andExpr,
+		WindowsItemPrivate: WindowsItemPrivate{Frame: DefaultWindowFrame, Col: 1},
+	}}
+	windows3 := WindowsExpr{{
+		Function:           andExpr,
+		WindowsItemPrivate: WindowsItemPrivate{Frame: frame1, Col: 1},
+	}}
+	windows4 := WindowsExpr{{
+		Function:           andExpr,
+		WindowsItemPrivate: WindowsItemPrivate{Frame: DefaultWindowFrame, Col: 2},
+	}}
+
 	type testVariation struct {
 		val1  interface{}
 		val2  interface{}
@@ -280,6 +307,11 @@ func TestInterner(t *testing.T) {
 			{val1: opt.SchemaID(0), val2: opt.SchemaID(1), equal: false},
 		}},
 
+		{hashFn: in.hasher.HashWithID, eqFn: in.hasher.IsWithIDEqual, variations: []testVariation{
+			{val1: opt.WithID(1), val2: opt.WithID(1), equal: true},
+			{val1: opt.WithID(1), val2: opt.WithID(2), equal: false},
+		}},
+
 		{hashFn: in.hasher.HashScanLimit, eqFn: in.hasher.IsScanLimitEqual, variations: []testVariation{
 			{val1: ScanLimit(100), val2: ScanLimit(100), equal: true},
 			{val1: ScanLimit(0), val2: ScanLimit(1), equal: false},
@@ -292,6 +324,30 @@ func TestInterner(t *testing.T) {
 			{val1: ScanFlags{NoIndexJoin: true, Index: 1}, val2: ScanFlags{NoIndexJoin: false, Index: 1}, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashWindowFrame, eqFn: in.hasher.IsWindowFrameEqual, variations: []testVariation{
+			{val1: DefaultWindowFrame, val2: DefaultWindowFrame, equal: true},
+			{val1: DefaultWindowFrame, val2: frame1, equal: false},
+			{val1: frame1, val2: frame2, equal: false},
+		}},
+
+		{hashFn: in.hasher.HashGroupingSets, eqFn: in.hasher.IsGroupingSetsEqual, variations: []testVariation{
+			{val1: GroupingSets{}, val2: GroupingSets{}, equal: true},
+			{val1: GroupingSets{util.MakeFastIntSet(1, 2), {}}, val2: GroupingSets{util.MakeFastIntSet(1, 2), {}}, equal: true},
+			{val1: GroupingSets{util.MakeFastIntSet(1, 2), {}}, val2: GroupingSets{util.MakeFastIntSet(1), {}}, equal: false},
+			{val1: GroupingSets{util.MakeFastIntSet(1, 2), {}}, val2: GroupingSets{{}, util.MakeFastIntSet(1, 2)}, equal: false},
+			{val1: GroupingSets{util.MakeFastIntSet(1, 2)}, val2: GroupingSets{util.MakeFastIntSet(1, 2), {}}, equal: false},
+		}},
+
+		{hashFn: in.hasher.HashLockingStrength, eqFn: in.hasher.IsLockingStrengthEqual, variations: []testVariation{
+			{val1: tree.ForUpdate, val2: tree.ForUpdate, equal: true},
+			{val1: tree.ForNone, val2: tree.ForShare, equal: false},
+		}},
+
+		{hashFn: in.hasher.HashLockingWaitPolicy, eqFn: in.hasher.IsLockingWaitPolicyEqual, variations: []testVariation{
+			{val1: tree.LockWaitError, val2: tree.LockWaitError, equal: true},
+			{val1: tree.LockWaitBlock, val2: tree.LockWaitSkip, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashPointer, eqFn: in.hasher.IsPointerEqual, variations: []testVariation{
 			{val1: unsafe.Pointer((*tree.Subquery)(nil)), val2: unsafe.Pointer((*tree.Subquery)(nil)), equal: true},
 			{val1: unsafe.Pointer(&tree.Subquery{}), val2: unsafe.Pointer(&tree.Subquery{}), equal: false},
@@ -353,6 +409,12 @@ func TestInterner(t *testing.T) {
 			{val1: aggs3, val2: aggs4, equal: false},
 			{val1: aggs3, val2: aggs5, equal: false},
 		}},
+
+		{hashFn: in.hasher.HashWindowsExpr, eqFn: in.hasher.IsWindowsExprEqual, variations: []testVariation{
+			{val1: windows1, val2: windows2, equal: true},
+			{val1: windows2, val2: windows3, equal: false},
+			{val1: windows2, val2: windows4, equal: false},
+		}},
 	}
 
 	computeHashValue := func(hashFn reflect.Value, val interface{}) internHash {
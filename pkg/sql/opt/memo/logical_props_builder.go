@@ -529,6 +529,73 @@ func (b *logicalPropsBuilder) buildGroupingExprProps(groupExpr RelExpr, rel *pro
 	}
 }
 
+func (b *logicalPropsBuilder) buildGroupingSetsGroupByProps(
+	groupBy *GroupingSetsGroupByExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, groupBy, &rel.Shared)
+
+	inputProps := groupBy.Input.Relational()
+	aggs := groupBy.Aggregations
+
+	// Output Columns
+	// --------------
+	// Output columns are the union of grouping columns with columns from the
+	// aggregate projection list and the grouping set id column.
+	rel.OutputCols = groupBy.GroupingCols.Copy()
+	for i := range aggs {
+		rel.OutputCols.Add(int(aggs[i].Col))
+	}
+	rel.OutputCols.Add(int(groupBy.GroupingSetIDCol))
+
+	// Not Null Columns
+	// ----------------
+	// A grouping column is NULL in the rows of the grouping sets that don't
+	// contain it, so only grouping columns that are part of every set can
+	// propagate the not null setting of the input. The grouping set id is
+	// never NULL.
+	notNullCols := inputProps.NotNullCols.Intersection(groupBy.GroupingCols)
+	for i := range groupBy.GroupingSets {
+		notNullCols.IntersectionWith(groupBy.GroupingSets[i])
+	}
+	rel.NotNullCols = notNullCols
+	rel.NotNullCols.Add(int(groupBy.GroupingSetIDCol))
+
+	// Outer Columns
+	// -------------
+	// Outer columns were derived by buildSharedProps; remove any that are bound
+	// by input columns.
+	rel.OuterCols.DifferenceWith(inputProps.OutputCols)
+
+	// Functional Dependencies
+	// -----------------------
+	// The dependencies of the input don't necessarily hold in the output, since
+	// some of the grouping columns are replaced by NULL values. However, the
+	// grouping columns together with the grouping set id always form a strict
+	// key, because each grouping set eliminates all duplicates in its columns.
+	keyCols := groupBy.GroupingCols.Copy()
+	keyCols.Add(int(groupBy.GroupingSetIDCol))
+	rel.FuncDeps.AddStrictKey(keyCols, rel.OutputCols)
+
+	// Cardinality
+	// -----------
+	// Each grouping set acts like a GroupBy, except for an empty set, which acts
+	// like a ScalarGroupBy.
+	rel.Cardinality = props.ZeroCardinality
+	for i := range groupBy.GroupingSets {
+		if groupBy.GroupingSets[i].Empty() {
+			rel.Cardinality = rel.Cardinality.Add(props.OneCardinality)
+		} else {
+			rel.Cardinality = rel.Cardinality.Add(inputProps.Cardinality.AsLowAs(1))
+		}
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildGroupingSetsGroupBy(groupBy, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildUnionProps(union *UnionExpr, rel *props.Relational) {
 	b.buildSetProps(union, rel)
 }
@@ -894,6 +961,53 @@ func (b *logicalPropsBuilder) buildRowNumberProps(rowNum *RowNumberExpr, rel *pr
 	}
 }
 
+func (b *logicalPropsBuilder) buildWindowProps(window *WindowExpr, rel *props.Relational) {
+	BuildSharedProps(b.mem, window, &rel.Shared)
+
+	inputProps := window.Input.Relational()
+
+	// Output Columns
+	// --------------
+	// Output columns are all the passthrough columns with the addition of the
+	// window function columns.
+	rel.OutputCols = inputProps.OutputCols.Copy()
+	for i := range window.Windows {
+		rel.OutputCols.Add(int(window.Windows[i].Col))
+	}
+
+	// Not Null Columns
+	// ----------------
+	// Inherit not null columns from input. The window function columns can
+	// be null.
+	rel.NotNullCols = inputProps.NotNullCols
+
+	// Outer Columns
+	// -------------
+	// Outer columns were already derived by buildSharedProps.
+
+	// Functional Dependencies
+	// -----------------------
+	// Inherit functional dependencies from input. The window function columns
+	// are not functionally determined by any set of input columns in general,
+	// since their values depend on the other rows of the partition.
+	rel.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+	if key, ok := rel.FuncDeps.StrictKey(); ok {
+		// Any existing keys are still keys.
+		rel.FuncDeps.AddStrictKey(key, rel.OutputCols)
+	}
+
+	// Cardinality
+	// -----------
+	// Window does not change the number of rows.
+	rel.Cardinality = inputProps.Cardinality
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildWindow(window, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildProjectSetProps(
 	projectSet *ProjectSetExpr, rel *props.Relational,
 ) {
@@ -950,6 +1064,138 @@ func (b *logicalPropsBuilder) buildProjectSetProps(
 	}
 }
 
+func (b *logicalPropsBuilder) buildRecursiveCTEProps(
+	rec *RecursiveCTEExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, rec, &rel.Shared)
+
+	initialProps := rec.Initial.Relational()
+	recursiveProps := rec.Recursive.Relational()
+	if len(rec.OutCols) != len(rec.InitialCols) || len(rec.OutCols) != len(rec.RecursiveCols) {
+		panic(fmt.Errorf("lists in RecursiveCTEPrivate are not all the same length. "+
+			"out:%d, initial:%d, recursive:%d",
+			len(rec.OutCols), len(rec.InitialCols), len(rec.RecursiveCols)))
+	}
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	rel.OutputCols = rec.OutCols.ToSet()
+
+	// Not Null Columns
+	// ----------------
+	// Columns have to be not-null in both the initial and the recursive
+	// expressions to be not-null in the result.
+	for i := range rec.OutCols {
+		if initialProps.NotNullCols.Contains(int(rec.InitialCols[i])) &&
+			recursiveProps.NotNullCols.Contains(int(rec.RecursiveCols[i])) {
+			rel.NotNullCols.Add(int(rec.OutCols[i]))
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// Outer columns were already derived by BuildSharedProps.
+
+	// Functional Dependencies
+	// -----------------------
+	// When duplicates are eliminated (UNION), all the columns form a strict key.
+	if rec.Deduplicate {
+		rel.FuncDeps.AddStrictKey(rel.OutputCols, rel.OutputCols)
+	}
+
+	// Cardinality
+	// -----------
+	// The recursive expression can be evaluated any number of times, so the
+	// only thing we know is that at least one row is returned if the initial
+	// expression returns rows (UNION can collapse duplicate initial rows).
+	rel.Cardinality = props.AnyCardinality
+	if !initialProps.Cardinality.CanBeZero() {
+		rel.Cardinality = rel.Cardinality.AtLeast(props.OneCardinality)
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildRecursiveCTE(rec, rel)
+	}
+}
+
+func (b *logicalPropsBuilder) buildWorkingTableScanProps(
+	scan *WorkingTableScanExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, scan, &rel.Shared)
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	rel.OutputCols = scan.Cols.ToSet()
+
+	// Not Null Columns
+	// ----------------
+	// All columns are assumed to be nullable.
+
+	// Outer Columns
+	// -------------
+	// The working table scan doesn't have outer columns.
+
+	// Functional Dependencies
+	// -----------------------
+	// The working table scan has an empty FD set.
+
+	// Cardinality
+	// -----------
+	// Don't make any assumptions about cardinality of the working table.
+	rel.Cardinality = props.AnyCardinality
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildWorkingTableScan(scan, rel)
+	}
+}
+
+func (b *logicalPropsBuilder) buildWithScanProps(
+	withScan *WithScanExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, withScan, &rel.Shared)
+	bindingProps := withScan.BindingProps
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	rel.OutputCols = withScan.OutCols.ToSet()
+
+	// Not Null Columns
+	// ----------------
+	// An output column is not null if the corresponding column of the buffered
+	// expression is not null.
+	for i, inCol := range withScan.InCols {
+		if bindingProps.NotNullCols.Contains(int(inCol)) {
+			rel.NotNullCols.Add(int(withScan.OutCols[i]))
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// The with scan doesn't have outer columns.
+
+	// Functional Dependencies
+	// -----------------------
+	// The with scan has an empty FD set.
+
+	// Cardinality
+	// -----------
+	// Inherit cardinality from the buffered expression.
+	rel.Cardinality = bindingProps.Cardinality
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildWithScan(withScan, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildInsertProps(ins *InsertExpr, rel *props.Relational) {
 	b.buildMutationProps(ins, rel)
 }
@@ -1098,6 +1344,11 @@ func (b *logicalPropsBuilder) buildZipItemProps(item *ZipItem, scalar *props.Sca
 	BuildSharedProps(b.mem, item.Func, &scalar.Shared)
 }
 
+func (b *logicalPropsBuilder) buildWindowsItemProps(item *WindowsItem, scalar *props.Scalar) {
+	item.Typ = item.Function.DataType()
+	BuildSharedProps(b.mem, item.Function, &scalar.Shared)
+}
+
 // BuildSharedProps fills in the shared properties derived from the given
 // expression's subtree.
 func BuildSharedProps(mem *Memo, e opt.Expr, shared *props.Shared) {
@@ -144,6 +144,12 @@ type Memo struct {
 	// searchPath is the current search path at the time the memo was compiled.
 	// If this changes, then the memo is invalidated.
 	searchPath sessiondata.SearchPath
+
+	// optimizerMutations is the value of the experimental_optimizer_mutations
+	// session setting at the time the memo was compiled. It determines whether
+	// UPDATE, UPSERT and DELETE statements can be compiled. If this changes,
+	// then the memo is invalidated.
+	optimizerMutations bool
 }
 
 // Init initializes a new empty memo instance, or resets existing state so it
@@ -162,6 +168,7 @@ func (m *Memo) Init(evalCtx *tree.EvalContext) {
 	m.locName = evalCtx.GetLocation().String()
 	m.dbName = evalCtx.SessionData.Database
 	m.searchPath = evalCtx.SessionData.SearchPath
+	m.optimizerMutations = evalCtx.SessionData.OptimizerMutations
 }
 
 // IsEmpty returns true if there are no expressions in the memo.
@@ -242,9 +249,11 @@ func (m *Memo) HasPlaceholders() bool {
 //   2. Current search path: this can change name resolution.
 //   3. Current location: this determines time zone, and can change how time-
 //      related types are constructed and compared.
-//   4. Data source schema: this determines most aspects of how the query is
+//   4. The experimental_optimizer_mutations setting: this determines whether
+//      mutations nested anywhere in the query can be compiled.
+//   5. Data source schema: this determines most aspects of how the query is
 //      compiled.
-//   5. Data source privileges: current user may no longer have access to one or
+//   6. Data source privileges: current user may no longer have access to one or
 //      more data sources.
 //
 // This function cannot swallow errors and return only a boolean, as it may
@@ -268,6 +277,12 @@ func (m *Memo) IsStale(
 		return true, nil
 	}
 
+	// Memo is stale if the experimental_optimizer_mutations setting has
+	// changed.
+	if m.optimizerMutations != evalCtx.SessionData.OptimizerMutations {
+		return true, nil
+	}
+
 	// Memo is stale if the fingerprint of any object in the memo's metadata has
 	// changed, or if the current user no longer has sufficient privilege to
 	// access the object.
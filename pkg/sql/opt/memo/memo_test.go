@@ -166,6 +166,15 @@ func TestMemoIsStale(t *testing.T) {
 	}
 	evalCtx.SessionData.DataConversion.Location = time.UTC
 
+	// Stale experimental_optimizer_mutations setting.
+	evalCtx.SessionData.OptimizerMutations = !evalCtx.SessionData.OptimizerMutations
+	if isStale, err := o.Memo().IsStale(ctx, &evalCtx, catalog); err != nil {
+		t.Fatal(err)
+	} else if !isStale {
+		t.Errorf("expected stale experimental_optimizer_mutations setting")
+	}
+	evalCtx.SessionData.OptimizerMutations = !evalCtx.SessionData.OptimizerMutations
+
 	// Stale data sources and schema. Create new catalog so that data sources are
 	// recreated and can be modified independently.
 	catalog = testcat.New()
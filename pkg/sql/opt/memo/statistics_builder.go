@@ -317,6 +317,9 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.GroupByOp, opt.ScalarGroupByOp, opt.DistinctOnOp:
 		return sb.colStatGroupBy(colSet, e)
 
+	case opt.GroupingSetsGroupByOp:
+		return sb.colStatGroupingSetsGroupBy(colSet, e.(*GroupingSetsGroupByExpr))
+
 	case opt.LimitOp:
 		return sb.colStatLimit(colSet, e.(*LimitExpr))
 
@@ -329,9 +332,21 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.RowNumberOp:
 		return sb.colStatRowNumber(colSet, e.(*RowNumberExpr))
 
+	case opt.WindowOp:
+		return sb.colStatWindow(colSet, e.(*WindowExpr))
+
 	case opt.ProjectSetOp:
 		return sb.colStatProjectSet(colSet, e.(*ProjectSetExpr))
 
+	case opt.RecursiveCTEOp:
+		return sb.colStatRecursiveCTE(colSet, e.(*RecursiveCTEExpr))
+
+	case opt.WorkingTableScanOp:
+		return sb.colStatWorkingTableScan(colSet, e.(*WorkingTableScanExpr))
+
+	case opt.WithScanOp:
+		return sb.colStatWithScan(colSet, e.(*WithScanExpr))
+
 	case opt.InsertOp, opt.UpdateOp, opt.UpsertOp, opt.DeleteOp:
 		return sb.colStatMutation(colSet, e)
 
@@ -443,6 +458,15 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 			if colStat, ok := stats.ColStats.Add(cols); ok {
 				colStat.DistinctCount = float64(stat.DistinctCount())
 				colStat.NullCount = float64(stat.NullCount())
+				if hist := stat.Histogram(); cols.Len() == 1 && len(hist) > 0 {
+					col, _ := cols.Next(0)
+					// Constraints on JSON columns may be derived from inverted index
+					// keys, which don't correspond to values of the column.
+					if sb.md.ColumnMeta(opt.ColumnID(col)).Type != types.JSON {
+						colStat.Histogram = &props.Histogram{}
+						colStat.Histogram.Init(sb.evalCtx, opt.ColumnID(col), hist, colStat.DistinctCount)
+					}
+				}
 			}
 		}
 	}
@@ -500,6 +524,7 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 		// Calculate row count and selectivity
 		// -----------------------------------
 		inputRowCount := s.RowCount
+		s.ApplySelectivity(sb.selectivityFromHistograms(cols, scan, s))
 		s.ApplySelectivity(sb.selectivityFromDistinctCounts(cols, scan, s))
 		s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
 
@@ -597,6 +622,7 @@ func (sb *statisticsBuilder) buildSelect(sel *SelectExpr, relProps *props.Relati
 	inputStats := &sel.Input.Relational().Stats
 	s.RowCount = inputStats.RowCount
 	inputRowCount := s.RowCount
+	s.ApplySelectivity(sb.selectivityFromHistograms(constrainedCols, sel, s))
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, sel, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, sel, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
@@ -810,6 +836,7 @@ func (sb *statisticsBuilder) buildJoin(
 	// -----------------------------------
 	s.RowCount = leftStats.RowCount * rightStats.RowCount
 	inputRowCount := s.RowCount
+	s.ApplySelectivity(sb.selectivityFromHistograms(constrainedCols, join, s))
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, join, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &h.filtersFD, join, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
@@ -1201,6 +1228,9 @@ func (sb *statisticsBuilder) colStatIndexJoin(
 		inputColStat := sb.colStatFromChild(reqInputCols, join, 0 /* childIdx */)
 		colStat.DistinctCount = inputColStat.DistinctCount
 		colStat.NullCount = inputColStat.NullCount
+		if colSet.Len() == 1 {
+			colStat.Histogram = inputColStat.Histogram
+		}
 	}
 
 	// Other requested columns may be from the primary index.
@@ -1221,6 +1251,9 @@ func (sb *statisticsBuilder) colStatIndexJoin(
 		// provided by the input index. Multiplying the counts gives a worst-case
 		// estimate of the joint distinct count.
 		colStat.DistinctCount *= lookupColStat.DistinctCount
+		if colSet.Len() == 1 {
+			colStat.Histogram = lookupColStat.Histogram
+		}
 
 		// Assuming null columns are completely independent, calculate
 		// the expected value of having nulls in either column set.
@@ -1309,6 +1342,7 @@ func (sb *statisticsBuilder) buildZigzagJoin(
 
 	// Calculate selectivity and row count.
 	inputRowCount := s.RowCount
+	s.ApplySelectivity(sb.selectivityFromHistograms(constrainedCols, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
@@ -1392,6 +1426,9 @@ func (sb *statisticsBuilder) colStatGroupBy(
 		// Make a copy so we don't modify the original
 		colStat = sb.copyColStatFromChild(colSet, groupNode, s)
 		inputColStat = sb.colStatFromChild(colSet, groupNode, 0 /* childIdx */)
+		// Each group has a single row, so the histogram of the input does not
+		// describe the output.
+		colStat.Histogram = nil
 	}
 
 	// For null counts - we either only have 1 possible null value (if we're
@@ -1413,6 +1450,73 @@ func (sb *statisticsBuilder) colStatGroupBy(
 	return colStat
 }
 
+// +-----------------------+
+// | Grouping Sets GroupBy |
+// +-----------------------+
+
+func (sb *statisticsBuilder) buildGroupingSetsGroupBy(
+	groupBy *GroupingSetsGroupByExpr, relProps *props.Relational,
+) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// Each grouping set produces as many rows as a GroupBy on the columns of
+	// the set would.
+	s.RowCount = 0
+	for i := range groupBy.GroupingSets {
+		s.RowCount += sb.groupingSetRowCount(groupBy.GroupingSets[i], groupBy)
+	}
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatGroupingSetsGroupBy(
+	colSet opt.ColSet, groupBy *GroupingSetsGroupByExpr,
+) *props.ColumnStatistic {
+	relProps := groupBy.Relational()
+	s := &relProps.Stats
+
+	colStat, _ := s.ColStats.Add(colSet)
+	if colSet.SubsetOf(groupBy.GroupingCols) {
+		// The grouping columns have the same distinct values as in the input
+		// (plus NULL, which isn't counted). They are NULL in all the rows of the
+		// grouping sets that don't contain all of them.
+		inputColStat := sb.colStatFromChild(colSet, groupBy, 0 /* childIdx */)
+		colStat.DistinctCount = min(inputColStat.DistinctCount, s.RowCount)
+		colStat.NullCount = 0
+		for i := range groupBy.GroupingSets {
+			if !colSet.SubsetOf(groupBy.GroupingSets[i]) {
+				colStat.NullCount += sb.groupingSetRowCount(groupBy.GroupingSets[i], groupBy)
+			}
+		}
+		colStat.NullCount = min(colStat.NullCount, s.RowCount)
+	} else {
+		// Some of the requested columns are aggregates or the grouping set id.
+		// Assume that every row is distinct.
+		colStat.DistinctCount = s.RowCount
+		colStat.NullCount = s.RowCount * unknownNullCountRatio
+	}
+
+	if colSet.SubsetOf(relProps.NotNullCols) {
+		colStat.NullCount = 0
+	}
+	return colStat
+}
+
+// groupingSetRowCount estimates the number of rows produced by the given
+// grouping set of a GroupingSetsGroupBy expression.
+func (sb *statisticsBuilder) groupingSetRowCount(
+	groupingSet opt.ColSet, groupBy *GroupingSetsGroupByExpr,
+) float64 {
+	if groupingSet.Empty() {
+		// An empty grouping set always produces a single row.
+		return 1
+	}
+	return sb.colStatFromChild(groupingSet, groupBy, 0 /* childIdx */).DistinctCount
+}
+
 // +--------+
 // | Set Op |
 // +--------+
@@ -1743,6 +1847,57 @@ func (sb *statisticsBuilder) colStatRowNumber(
 	return colStat
 }
 
+// +--------+
+// | Window |
+// +--------+
+
+func (sb *statisticsBuilder) buildWindow(window *WindowExpr, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	inputStats := &window.Input.Relational().Stats
+
+	// The row count of a window is equal to the row count of its input.
+	s.RowCount = inputStats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWindow(
+	colSet opt.ColSet, window *WindowExpr,
+) *props.ColumnStatistic {
+	relProps := window.Relational()
+	s := &relProps.Stats
+
+	colStat, _ := s.ColStats.Add(colSet)
+
+	inputCols := window.Input.Relational().OutputCols
+	if colSet.SubsetOf(inputCols) {
+		inputColStat := sb.colStatFromChild(colSet, window, 0 /* childIdx */)
+		colStat.DistinctCount = inputColStat.DistinctCount
+		colStat.NullCount = inputColStat.NullCount
+	} else {
+		// The window function columns can have a different value for every row,
+		// so assume that every row is distinct.
+		colStat.DistinctCount = s.RowCount
+		reqInputCols := colSet.Intersection(inputCols)
+		if reqInputCols.Empty() {
+			colStat.NullCount = s.RowCount * unknownNullCountRatio
+		} else {
+			// Copy NullCount from child.
+			inputColStat := sb.colStatFromChild(reqInputCols, window, 0 /* childIdx */)
+			colStat.NullCount = inputColStat.NullCount
+		}
+	}
+
+	if colSet.SubsetOf(relProps.NotNullCols) {
+		colStat.NullCount = 0
+	}
+	return colStat
+}
+
 // +-------------+
 // | Project Set |
 // +-------------+
@@ -1877,6 +2032,84 @@ func (sb *statisticsBuilder) colStatProjectSet(
 	return colStat
 }
 
+// +---------------+
+// | Recursive CTE |
+// +---------------+
+
+func (sb *statisticsBuilder) buildRecursiveCTE(
+	rec *RecursiveCTEExpr, relProps *props.Relational,
+) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// There is no way of knowing how many iterations will be performed, so
+	// assume that the recursive expression is evaluated a fixed number of
+	// times in addition to the initial expression.
+	initialStats := &rec.Initial.Relational().Stats
+	recursiveStats := &rec.Recursive.Relational().Stats
+	s.RowCount = initialStats.RowCount + recursiveStats.RowCount*unknownRecursiveCTEIterations
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatRecursiveCTE(
+	colSet opt.ColSet, rec *RecursiveCTEExpr,
+) *props.ColumnStatistic {
+	relProps := rec.Relational()
+	return sb.colStatLeaf(colSet, &relProps.Stats, &relProps.FuncDeps, relProps.NotNullCols)
+}
+
+// +--------------------+
+// | Working Table Scan |
+// +--------------------+
+
+func (sb *statisticsBuilder) buildWorkingTableScan(
+	scan *WorkingTableScanExpr, relProps *props.Relational,
+) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// The working table holds the rows produced by a single iteration; we
+	// don't know anything about it.
+	s.RowCount = unknownRowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWorkingTableScan(
+	colSet opt.ColSet, scan *WorkingTableScanExpr,
+) *props.ColumnStatistic {
+	relProps := scan.Relational()
+	return sb.colStatLeaf(colSet, &relProps.Stats, &relProps.FuncDeps, relProps.NotNullCols)
+}
+
+// +-----------+
+// | With Scan |
+// +-----------+
+
+func (sb *statisticsBuilder) buildWithScan(withScan *WithScanExpr, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	// The with scan returns all rows of the buffered expression.
+	s.RowCount = withScan.BindingProps.Stats.RowCount
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWithScan(
+	colSet opt.ColSet, withScan *WithScanExpr,
+) *props.ColumnStatistic {
+	relProps := withScan.Relational()
+	return sb.colStatLeaf(colSet, &relProps.Stats, &relProps.FuncDeps, relProps.NotNullCols)
+}
+
 // +--------------------------------+
 // | Insert, Update, Upsert, Delete |
 // +--------------------------------+
@@ -1955,6 +2188,10 @@ func (sb *statisticsBuilder) copyColStat(
 	colStat, _ := s.ColStats.Add(colSet)
 	colStat.DistinctCount = inputColStat.DistinctCount
 	colStat.NullCount = inputColStat.NullCount
+	if colSet.Len() == 1 {
+		// Histograms are immutable, so they can be shared.
+		colStat.Histogram = inputColStat.Histogram
+	}
 	return colStat
 }
 
@@ -2060,6 +2297,11 @@ const (
 	// Since the generator row count is so small, we need a larger distinct count
 	// ratio for generator functions.
 	unknownGeneratorDistinctCountRatio = 0.7
+
+	// This is the number of times the recursive expression of a recursive CTE
+	// is assumed to be evaluated, since the real number of iterations can't be
+	// known before execution.
+	unknownRecursiveCTEIterations = 10
 )
 
 // countJSONPaths returns the number of JSON paths in the specified
@@ -2174,7 +2416,13 @@ func (sb *statisticsBuilder) applyIndexConstraint(
 	}
 
 	applied := sb.updateDistinctCountsFromConstraint(c, e, relProps)
+	histCols := sb.updateHistogramsFromConstraint(c, e, relProps)
 	for i, n := applied, c.ConstrainedColumns(sb.evalCtx); i < n; i++ {
+		if histCols.Contains(int(c.Columns.Get(i).ID())) {
+			// The selectivity of the constraint on this column is estimated with
+			// its histogram.
+			continue
+		}
 		// Unlike the constraints found in Select and Join filters, an index
 		// constraint may represent multiple conjuncts. Therefore, we need to
 		// calculate the number of unapplied conjuncts for each constrained column.
@@ -2197,14 +2445,16 @@ func (sb *statisticsBuilder) applyConstraintSet(
 
 	numUnappliedConjuncts = 0
 	for i := 0; i < cs.Length(); i++ {
-		applied := sb.updateDistinctCountsFromConstraint(cs.Constraint(i), e, relProps)
-		if applied == 0 {
+		c := cs.Constraint(i)
+		applied := sb.updateDistinctCountsFromConstraint(c, e, relProps)
+		histCols := sb.updateHistogramsFromConstraint(c, e, relProps)
+		if applied == 0 && !histCols.Contains(int(c.Columns.Get(0).ID())) {
 			// If a constraint cannot be applied, it may represent an
 			// inequality like x < 1. As a result, distinctCounts does not fully
 			// represent the selectivity of the constraint set.
 			// We return an estimate of the number of unapplied conjuncts to the
 			// caller function to be used for selectivity calculation.
-			numUnappliedConjuncts += sb.numConjunctsInConstraint(cs.Constraint(i), 0 /* nth */)
+			numUnappliedConjuncts += sb.numConjunctsInConstraint(c, 0 /* nth */)
 		}
 	}
 
@@ -2354,6 +2604,46 @@ func (sb *statisticsBuilder) updateDistinctCountsFromConstraint(
 	return applied
 }
 
+// updateHistogramsFromConstraint filters the histograms of the columns of the
+// given constraint which can be filtered by it (see Histogram.CanFilter), and
+// updates their distinct counts accordingly. It returns the set of columns for
+// which a histogram was filtered. If the same column appears in multiple
+// constraints, its histogram is filtered by all of them.
+//
+// For example, consider the following constraint on column a, which has a
+// histogram:
+//
+//   /a: [/1 - /5] [/10 - /10]
+//
+// The histogram of column a is filtered so that it only contains the values
+// between 1 and 5, and the value 10. The number of values in the filtered
+// histogram is later used to determine the selectivity of the constraint (see
+// selectivityFromHistograms).
+func (sb *statisticsBuilder) updateHistogramsFromConstraint(
+	c *constraint.Constraint, e RelExpr, relProps *props.Relational,
+) (histCols opt.ColSet) {
+	s := &relProps.Stats
+	prefix := c.Prefix(sb.evalCtx)
+	for i := 0; i <= prefix && i < c.Columns.Count(); i++ {
+		colSet := util.MakeFastIntSet(int(c.Columns.Get(i).ID()))
+		colStat, ok := s.ColStats.Lookup(colSet)
+		if !ok {
+			inputColStat := sb.colStatFromInput(colSet, e)
+			if inputColStat.Histogram == nil {
+				continue
+			}
+			colStat = sb.copyColStat(colSet, s, inputColStat)
+		}
+		if colStat.Histogram == nil || !colStat.Histogram.CanFilter(c) {
+			continue
+		}
+		colStat.Histogram = colStat.Histogram.Filter(c)
+		colStat.DistinctCount = min(colStat.DistinctCount, colStat.Histogram.DistinctValuesCount())
+		histCols.UnionWith(colSet)
+	}
+	return histCols
+}
+
 func (sb *statisticsBuilder) applyEquivalencies(
 	equivReps opt.ColSet, filterFD *props.FuncDepSet, e RelExpr, relProps *props.Relational,
 ) {
@@ -2395,6 +2685,48 @@ func (sb *statisticsBuilder) updateDistinctNullCountsFromEquivalency(
 	})
 }
 
+// selectivityFromHistograms calculates the selectivity of a filter by taking
+// the product of selectivities of each constrained column which has a
+// histogram. This can be represented by the formula:
+//
+//                  ┬-┬ ⎛ new values(i) ⎞
+//   selectivity =  │ │ ⎜ ------------- ⎟
+//                  ┴ ┴ ⎝ old values(i) ⎠
+//                 i in
+//              {constrained
+//                columns}
+//
+// where the number of values of a column is the number of values in its
+// histogram. The histograms are filtered by updateHistogramsFromConstraint.
+// The selectivity of the constrained columns which have a histogram is not
+// included in selectivityFromDistinctCounts.
+//
+// In order to protect against stale statistics, a filter is always assumed to
+// return at least one value.
+func (sb *statisticsBuilder) selectivityFromHistograms(
+	cols opt.ColSet, e RelExpr, s *props.Statistics,
+) (selectivity float64) {
+	selectivity = 1.0
+	for col, ok := cols.Next(0); ok; col, ok = cols.Next(col + 1) {
+		colStat, ok := s.ColStats.Lookup(util.MakeFastIntSet(col))
+		if !ok || colStat.Histogram == nil {
+			continue
+		}
+
+		inputStat := sb.colStatFromInput(colStat.Cols, e)
+		if inputStat.Histogram == nil {
+			continue
+		}
+		newCount := colStat.Histogram.ValuesCount()
+		oldCount := inputStat.Histogram.ValuesCount()
+		if oldCount != 0 && newCount < oldCount {
+			selectivity *= min(max(newCount, 1)/oldCount, 1)
+		}
+	}
+
+	return selectivity
+}
+
 // selectivityFromDistinctCounts calculates the selectivity of a filter by
 // taking the product of selectivities of each constrained column. In the
 // general case, this can be represented by the formula:
@@ -2422,6 +2754,10 @@ func (sb *statisticsBuilder) selectivityFromDistinctCounts(
 		}
 
 		inputStat := sb.colStatFromInput(colStat.Cols, e)
+		if colStat.Histogram != nil && inputStat.Histogram != nil {
+			// The selectivity was calculated by selectivityFromHistograms.
+			continue
+		}
 		newDistinct := colStat.DistinctCount
 		oldDistinct := inputStat.DistinctCount
 
@@ -2544,6 +2880,12 @@ func (sb *statisticsBuilder) selectivityFromEquivalencies(
 func (sb *statisticsBuilder) selectivityFromEquivalency(
 	equivGroup opt.ColSet, e RelExpr, s *props.Statistics,
 ) (selectivity float64) {
+	if equivGroup.Len() == 2 {
+		if sel, ok := sb.selectivityFromHistogramEquivalency(equivGroup, e, s); ok {
+			return sel
+		}
+	}
+
 	// Find the maximum input distinct count for all columns in this equivalency
 	// group.
 	maxDistinctCount := float64(0)
@@ -2572,6 +2914,61 @@ func (sb *statisticsBuilder) selectivityFromEquivalency(
 	return selectivity
 }
 
+// selectivityFromHistogramEquivalency determines the selectivity of an
+// equality condition var1=var2 using the histograms of both columns, if they
+// are available. Only the values of each column which are within the bounds of
+// the histogram of the other column can match, so the selectivity is:
+//
+//                   overlap(var1)   overlap(var2)                  1
+//   selectivity = --------------- * --------------- * -------------------------
+//                  values(var1)      values(var2)     max(distinct(overlap(i)))
+//
+// where overlap(var) is the part of the histogram of var which is within the
+// bounds of the histogram of the other column.
+func (sb *statisticsBuilder) selectivityFromHistogramEquivalency(
+	equivGroup opt.ColSet, e RelExpr, s *props.Statistics,
+) (selectivity float64, ok bool) {
+	col1, _ := equivGroup.Next(0)
+	col2, _ := equivGroup.Next(col1 + 1)
+	typ1 := sb.md.ColumnMeta(opt.ColumnID(col1)).Type
+	typ2 := sb.md.ColumnMeta(opt.ColumnID(col2)).Type
+	if !typ1.Equivalent(typ2) {
+		// The values of the histograms can't be compared.
+		return 0, false
+	}
+	histogram := func(col int) *props.Histogram {
+		// If the column statistic was updated by the filter, we want to use the
+		// updated histogram.
+		colSet := util.MakeFastIntSet(col)
+		colStat, ok := s.ColStats.Lookup(colSet)
+		if !ok {
+			colStat = sb.colStatFromInput(colSet, e)
+		}
+		return colStat.Histogram
+	}
+	hist1, hist2 := histogram(col1), histogram(col2)
+	if hist1 == nil || hist2 == nil {
+		return 0, false
+	}
+	count1, count2 := hist1.ValuesCount(), hist2.ValuesCount()
+	if count1 == 0 || count2 == 0 {
+		return 0, false
+	}
+
+	overlap1, overlap2 := hist1.Overlap(hist2), hist2.Overlap(hist1)
+	// As in selectivityFromHistograms, assume that at least one value matches.
+	selectivity = max(overlap1.ValuesCount(), 1) / count1
+	selectivity *= max(overlap2.ValuesCount(), 1) / count2
+	maxDistinctCount := max(overlap1.DistinctValuesCount(), overlap2.DistinctValuesCount())
+	if maxDistinctCount > s.RowCount {
+		maxDistinctCount = s.RowCount
+	}
+	if maxDistinctCount > 1 {
+		selectivity /= maxDistinctCount
+	}
+	return min(selectivity, 1), true
+}
+
 func (sb *statisticsBuilder) selectivityFromUnappliedConjuncts(
 	numUnappliedConjuncts float64,
 ) (selectivity float64) {
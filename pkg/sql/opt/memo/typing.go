@@ -149,9 +149,13 @@ func init() {
 	typingFuncMap[opt.ConstNotNullAggOp] = typeAsFirstArg
 	typingFuncMap[opt.AnyNotNullAggOp] = typeAsFirstArg
 	typingFuncMap[opt.FirstAggOp] = typeAsFirstArg
+	typingFuncMap[opt.PercentileDiscOp] = typeAsFirstArg
+	typingFuncMap[opt.PercentileContOp] = typeAsFirstArg
+	typingFuncMap[opt.ModeOp] = typeAsFirstArg
 
 	// Modifiers for aggregations pass through their argument.
 	typingFuncMap[opt.AggDistinctOp] = typeAsFirstArg
+	typingFuncMap[opt.AggFilterOp] = typeAsFirstArg
 
 	for _, op := range opt.BinaryOperators {
 		typingFuncMap[op] = typeAsBinary
@@ -32,6 +32,11 @@ import (
 // See the comment for Metadata for more details on identifiers.
 type SchemaID int32
 
+// WithID uniquely identifies the working table of a recursive common table
+// expression within the scope of a query. WithID 0 is reserved to mean
+// "unknown working table". WithIDs are allocated by the optbuilder.
+type WithID uint64
+
 // privilegeBitmap stores a union of zero or more privileges. Each privilege
 // that is present in the bitmap is represented by a bit that is shifted by
 // 1 << privilege.Kind, so that multiple privileges can be stored.
@@ -684,6 +684,22 @@ func (c *CustomFuncs) ConstructNoColsRow() memo.RelExpr {
 	return c.f.ConstructValues(memo.ScalarListWithEmptyTuple, opt.ColList{})
 }
 
+// MakeProjectionsFromValues returns a list of projections which synthesize the
+// given Values columns from the elements of the given row (a tuple).
+func (c *CustomFuncs) MakeProjectionsFromValues(
+	row opt.ScalarExpr, cols opt.ColList,
+) memo.ProjectionsExpr {
+	elems := row.(*memo.TupleExpr).Elems
+	projections := make(memo.ProjectionsExpr, len(cols))
+	for i, col := range cols {
+		projections[i] = memo.ProjectionsItem{
+			Element:    elems[i],
+			ColPrivate: memo.ColPrivate{Col: col},
+		}
+	}
+	return projections
+}
+
 // referenceSingleColumn returns a Variable operator that refers to the one and
 // only column that is projected by the input expression.
 func (c *CustomFuncs) referenceSingleColumn(in memo.RelExpr) opt.ScalarExpr {
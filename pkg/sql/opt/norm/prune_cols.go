@@ -34,6 +34,17 @@ func (c *CustomFuncs) NeededColsRowNumber(private *memo.RowNumberPrivate) opt.Co
 	return private.Ordering.ColSet()
 }
 
+// NeededColsWindow returns the columns needed by a Window operator's window
+// functions, partition columns and requested ordering.
+func (c *CustomFuncs) NeededColsWindow(
+	windows memo.WindowsExpr, private *memo.WindowPrivate,
+) opt.ColSet {
+	colSet := windows.OuterCols(c.mem)
+	colSet.UnionWith(private.Partition)
+	colSet.UnionWith(private.Ordering.ColSet())
+	return colSet
+}
+
 // NeededColsExplain returns the columns needed by Explain's required physical
 // properties.
 func (c *CustomFuncs) NeededColsExplain(private *memo.ExplainPrivate) opt.ColSet {
@@ -56,6 +67,12 @@ func (c *CustomFuncs) CanPruneAggCols(target memo.AggregationsExpr, neededCols o
 	return !target.OutputCols().SubsetOf(neededCols)
 }
 
+// CanPruneWindows returns true if one or more of the target window functions
+// is not referenced and can be eliminated.
+func (c *CustomFuncs) CanPruneWindows(target memo.WindowsExpr, neededCols opt.ColSet) bool {
+	return !target.OutputCols().SubsetOf(neededCols)
+}
+
 // PruneCols creates an expression that discards any outputs columns of the
 // target expression that are not used. If the target expression type supports
 // column filtering (like Scan, Values, Projections, etc.), then create a new
@@ -109,6 +126,21 @@ func (c *CustomFuncs) PruneAggCols(
 	return aggs
 }
 
+// PruneWindows creates a new WindowsExpr that discards window functions whose
+// columns are not referenced by the neededCols set.
+func (c *CustomFuncs) PruneWindows(
+	target memo.WindowsExpr, neededCols opt.ColSet,
+) memo.WindowsExpr {
+	windows := make(memo.WindowsExpr, 0, len(target))
+	for i := range target {
+		item := &target[i]
+		if neededCols.Contains(int(item.Col)) {
+			windows = append(windows, *item)
+		}
+	}
+	return windows
+}
+
 // pruneScanCols constructs a new Scan operator based on the given existing Scan
 // operator, but projecting only the needed columns.
 func (c *CustomFuncs) pruneScanCols(scan *memo.ScanExpr, neededCols opt.ColSet) memo.RelExpr {
@@ -267,6 +299,16 @@ func DerivePruneCols(e memo.RelExpr) opt.ColSet {
 		inputPruneCols := DerivePruneCols(rowNum.Input)
 		relProps.Rule.PruneCols = inputPruneCols.Difference(rowNum.Ordering.ColSet())
 
+	case opt.WindowOp:
+		// Any pruneable input columns can potentially be pruned, as long as
+		// they're not used by the window functions, the partition columns or the
+		// ordering. The window function columns can also be pruned.
+		win := e.(*memo.WindowExpr)
+		relProps.Rule.PruneCols = DerivePruneCols(win.Input).Union(win.Windows.OutputCols())
+		relProps.Rule.PruneCols.DifferenceWith(win.Windows.OuterCols(e.Memo()))
+		relProps.Rule.PruneCols.DifferenceWith(win.Partition)
+		relProps.Rule.PruneCols.DifferenceWith(win.Ordering.ColSet())
+
 	case opt.IndexJoinOp, opt.LookupJoinOp:
 		// There is no need to prune columns projected by Index or Lookup joins,
 		// since its parent will always be an "alternate" expression in the memo.
@@ -0,0 +1,89 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package norm
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+)
+
+// WindowPartition returns the set of columns by which the rows of a Window
+// operator are partitioned.
+func (c *CustomFuncs) WindowPartition(private *memo.WindowPrivate) opt.ColSet {
+	return private.Partition
+}
+
+// CanReduceWindowPartitionCols is true if the given Window operator has one or
+// more redundant partition columns. A partition column is redundant if it is
+// functionally determined by the other partition columns.
+func (c *CustomFuncs) CanReduceWindowPartitionCols(
+	input memo.RelExpr, private *memo.WindowPrivate,
+) bool {
+	fdset := input.Relational().FuncDeps
+	return !fdset.ReduceCols(private.Partition).Equals(private.Partition)
+}
+
+// ReduceWindowPartitionCols constructs a new WindowPrivate, based on an
+// existing one. The new WindowPrivate will not retain any partition column
+// that is functionally determined by other partition columns.
+// CanReduceWindowPartitionCols should be called before calling this method, to
+// ensure it has work to do.
+func (c *CustomFuncs) ReduceWindowPartitionCols(
+	input memo.RelExpr, private *memo.WindowPrivate,
+) *memo.WindowPrivate {
+	fdset := input.Relational().FuncDeps
+	return &memo.WindowPrivate{
+		Partition: fdset.ReduceCols(private.Partition),
+		Ordering:  private.Ordering,
+	}
+}
+
+// CanSimplifyWindowOrdering returns true if the ordering of the rows within the
+// partitions of the given Window operator can be made less restrictive.
+func (c *CustomFuncs) CanSimplifyWindowOrdering(
+	input memo.RelExpr, private *memo.WindowPrivate,
+) bool {
+	if private.Ordering.Any() {
+		return false
+	}
+	return private.Ordering.CanSimplify(c.partitionFuncDeps(input, private))
+}
+
+// SimplifyWindowOrdering makes the ordering of the rows within the partitions
+// of the given Window operator less restrictive by removing optional columns,
+// adding equivalent columns, and removing redundant columns (including the
+// partition columns, which are constant within each partition).
+func (c *CustomFuncs) SimplifyWindowOrdering(
+	input memo.RelExpr, private *memo.WindowPrivate,
+) *memo.WindowPrivate {
+	// Copy WindowPrivate to stack and replace Ordering field.
+	copy := *private
+	copy.Ordering = private.Ordering.Copy()
+	copy.Ordering.Simplify(c.partitionFuncDeps(input, private))
+	return &copy
+}
+
+// partitionFuncDeps returns the functional dependencies which hold within each
+// partition of the given Window operator: the input dependencies, with the
+// partition columns being constant.
+func (c *CustomFuncs) partitionFuncDeps(
+	input memo.RelExpr, private *memo.WindowPrivate,
+) *props.FuncDepSet {
+	var fdset props.FuncDepSet
+	fdset.CopyFrom(&input.Relational().FuncDeps)
+	fdset.AddConstants(private.Partition)
+	return &fdset
+}
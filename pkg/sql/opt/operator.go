@@ -166,27 +166,31 @@ var UnaryOpReverseMap = map[Operator]tree.UnaryOperator{
 // AggregateOpReverseMap maps from an optimizer operator type to the name of an
 // aggregation function.
 var AggregateOpReverseMap = map[Operator]string{
-	ArrayAggOp:        "array_agg",
-	AvgOp:             "avg",
-	BoolAndOp:         "bool_and",
-	BoolOrOp:          "bool_or",
-	ConcatAggOp:       "concat_agg",
-	CountOp:           "count",
-	CountRowsOp:       "count_rows",
-	MaxOp:             "max",
-	MinOp:             "min",
-	SumIntOp:          "sum_int",
-	SumOp:             "sum",
-	SqrDiffOp:         "sqrdiff",
-	VarianceOp:        "variance",
-	StdDevOp:          "stddev",
-	XorAggOp:          "xor_agg",
-	JsonAggOp:         "json_agg",
-	JsonbAggOp:        "jsonb_agg",
-	StringAggOp:       "string_agg",
-	ConstAggOp:        "any_not_null",
-	ConstNotNullAggOp: "any_not_null",
-	AnyNotNullAggOp:   "any_not_null",
+	ArrayAggOp:         "array_agg",
+	AvgOp:              "avg",
+	BoolAndOp:          "bool_and",
+	BoolOrOp:           "bool_or",
+	ConcatAggOp:        "concat_agg",
+	CountOp:            "count",
+	CountRowsOp:        "count_rows",
+	MaxOp:              "max",
+	MinOp:              "min",
+	SumIntOp:           "sum_int",
+	SumOp:              "sum",
+	SqrDiffOp:          "sqrdiff",
+	VarianceOp:         "variance",
+	StdDevOp:           "stddev",
+	XorAggOp:           "xor_agg",
+	JsonAggOp:          "json_agg",
+	JsonbAggOp:         "jsonb_agg",
+	StringAggOp:        "string_agg",
+	PercentileDiscOp:   "percentile_disc",
+	PercentileContOp:   "percentile_cont",
+	ModeOp:             "mode",
+	ApproxPercentileOp: "approx_percentile",
+	ConstAggOp:         "any_not_null",
+	ConstNotNullAggOp:  "any_not_null",
+	AnyNotNullAggOp:    "any_not_null",
 }
 
 // NegateOpMap maps from a comparison operator type to its negated operator
@@ -246,7 +250,8 @@ func AggregateIgnoresNulls(op Operator) bool {
 	switch op {
 	case AvgOp, BoolAndOp, BoolOrOp, CountOp, MaxOp, MinOp, SumIntOp, SumOp,
 		SqrDiffOp, VarianceOp, StdDevOp, XorAggOp, ConstNotNullAggOp,
-		AnyNotNullAggOp, StringAggOp:
+		AnyNotNullAggOp, StringAggOp, PercentileDiscOp, PercentileContOp, ModeOp,
+		ApproxPercentileOp:
 		return true
 	}
 	return false
@@ -261,7 +266,8 @@ func AggregateIsNullOnEmpty(op Operator) bool {
 	switch op {
 	case AvgOp, BoolAndOp, BoolOrOp, MaxOp, MinOp, SumIntOp, SumOp, SqrDiffOp,
 		VarianceOp, StdDevOp, XorAggOp, ConstAggOp, ConstNotNullAggOp, ArrayAggOp,
-		ConcatAggOp, JsonAggOp, JsonbAggOp, AnyNotNullAggOp, StringAggOp:
+		ConcatAggOp, JsonAggOp, JsonbAggOp, AnyNotNullAggOp, StringAggOp,
+		PercentileDiscOp, PercentileContOp, ModeOp, ApproxPercentileOp:
 		return true
 	}
 	return false
@@ -17,6 +17,7 @@ package optbuilder
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -100,6 +101,14 @@ type Builder struct {
 	// subquery contains a pointer to the subquery which is currently being built
 	// (if any).
 	subquery *subquery
+
+	// locking contains the locking items (FOR UPDATE etc) which apply to the
+	// data sources that are currently being built (if any).
+	locking lockingSpec
+
+	// lastWithID is the last WithID that was allocated for the working table of
+	// a recursive CTE.
+	lastWithID opt.WithID
 }
 
 // New creates a new Builder structure initialized with the given
@@ -16,12 +16,15 @@ package optbuilder
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
@@ -29,6 +32,14 @@ import (
 // buildCreateTable constructs a CreateTable operator based on the CREATE TABLE
 // statement.
 func (b *Builder) buildCreateTable(ct *tree.CreateTable, inScope *scope) (outScope *scope) {
+	// Temporary tables are created in the temporary schema of the session,
+	// which is only known to the heuristic planner.
+	if ct.Temporary || (ct.Table.ExplicitSchema &&
+		strings.HasPrefix(ct.Table.Schema(), sessiondata.PgTempSchemaName)) {
+		panic(builderError{pgerror.Unimplemented(
+			"temporary tables", "temporary tables are not supported by the optimizer")})
+	}
+
 	sch := b.resolveSchemaForCreate(&ct.Table)
 	schID := b.factory.Metadata().AddSchema(sch)
 
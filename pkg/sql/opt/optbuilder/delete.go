@@ -48,7 +48,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	}
 
 	if del.With != nil {
-		inScope = b.buildCTE(del.With.CTEList, inScope)
+		inScope = b.buildCTE(del.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -58,7 +58,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(del.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab := b.resolveTable(tn, privilege.DELETE)
+	tab := b.resolveTableForMutation(tn, privilege.DELETE)
 
 	// Check Select permission as well, since existing values must be read.
 	b.checkPrivilege(tab, privilege.SELECT)
@@ -88,12 +88,18 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 // buildDelete constructs a Delete operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildDelete(returning tree.ReturningExprs) {
+	mb.buildFKChecksAndCascades()
+
 	private := memo.MutationPrivate{
 		Table:       mb.tabID,
 		FetchCols:   mb.fetchColList,
 		NeedResults: returning != nil,
+		WithID:      mb.withID,
+		FKFallback:  mb.fkFallback,
 	}
-	mb.outScope.expr = mb.b.factory.ConstructDelete(mb.outScope.expr, &private)
+	mb.outScope.expr = mb.b.factory.ConstructDelete(
+		mb.outScope.expr, mb.checks, mb.cascades, &private,
+	)
 
 	mb.buildReturning(returning)
 }
@@ -0,0 +1,548 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// buildFKChecksAndCascades builds the queries that enforce the foreign key
+// constraints which involve the target table, and stores them in mb.checks and
+// mb.cascades. It must be called once the input of the mutation has been fully
+// built, right before the mutation operator is constructed.
+//
+// The checks and cascades refer to the mutated rows through WithScan operators
+// (see buildWithScan); the execution engine runs them after the mutation.
+//
+// If the optimizer_foreign_keys setting is off, or if any of the constraints
+// uses a feature that is not (yet) supported by the optimizer, no checks or
+// cascades are built and mb.fkFallback is set instead, in which case the
+// execution engine performs the checks and cascades row by row.
+func (mb *mutationBuilder) buildFKChecksAndCascades() {
+	if mb.b.evalCtx.SessionData.OptimizerFKs && mb.buildFKs(nil /* cascadePath */) {
+		return
+	}
+	mb.withID = 0
+	mb.checks = nil
+	mb.cascades = nil
+	mb.fkFallback = true
+}
+
+// buildFKs builds the foreign key checks and cascades for the mutation,
+// according to its operator. The cascadePath contains the IDs of the tables
+// which are mutated by the cascades that led to this mutation (it is empty
+// unless the mutation is itself a cascade). buildFKs returns false if the
+// checks and cascades must be left to the execution engine.
+func (mb *mutationBuilder) buildFKs(cascadePath []cat.StableID) bool {
+	// Copy the path, since it is shared with the sibling cascades.
+	cascadePath = append(cascadePath[:len(cascadePath):len(cascadePath)], mb.tab.ID())
+
+	switch mb.op {
+	case opt.InsertOp:
+		return mb.buildOutboundFKChecks(mb.insertColList, false /* onlyUpdated */)
+
+	case opt.UpdateOp:
+		newVals := make(opt.ColList, len(mb.fetchColList))
+		for i := range newVals {
+			newVals[i] = mb.fetchColList[i]
+			if mb.updateColList[i] != 0 {
+				newVals[i] = mb.updateColList[i]
+			}
+		}
+		return mb.buildOutboundFKChecks(newVals, true /* onlyUpdated */) &&
+			mb.buildInboundFKs(mb.fetchColList, newVals, cascadePath)
+
+	case opt.UpsertOp:
+		// The Upsert operator doesn't provide the values of the updated rows
+		// before the update, which are needed to check or cascade the inbound
+		// foreign keys.
+		for i, n := 0, mb.tab.IndexCount(); i < n; i++ {
+			idx := mb.tab.Index(i)
+			if idx.InboundForeignKeyCount() > 0 && mb.indexColsUpdated(idx, idx.KeyColumnCount()) {
+				return false
+			}
+
+			// The new value of a column is only known up front if the column is
+			// both inserted and updated, in which case the insert and update
+			// columns are the same (see projectUpsertColumns). Otherwise, it
+			// depends on whether the row ends up being inserted or updated.
+			if fk, ok := idx.ForeignKey(); ok {
+				for j := 0; j < int(fk.PrefixLen); j++ {
+					ord := idx.Column(j).Ordinal
+					if mb.insertColList[ord] != mb.updateColList[ord] {
+						return false
+					}
+				}
+			}
+		}
+		return mb.buildOutboundFKChecks(mb.insertColList, false /* onlyUpdated */)
+
+	case opt.DeleteOp:
+		return mb.buildInboundFKs(mb.fetchColList, nil /* newVals */, cascadePath)
+	}
+	return true
+}
+
+// buildOutboundFKChecks builds a check for each foreign key of the target
+// table, which verifies that the new values of the foreign key columns exist
+// in the referenced table. The newVals list contains the input columns that
+// hold the new value of each table column. If onlyUpdated is true, only the
+// foreign keys which have an updated column are checked.
+//
+// Each check is an anti-join of the (non-NULL) new values against the
+// referenced index:
+//
+//   SELECT fk FROM [WithScan of the input] AS new
+//   WHERE fk IS NOT NULL
+//   AND NOT EXISTS (SELECT * FROM parent WHERE parent.pk = new.fk)
+//
+func (mb *mutationBuilder) buildOutboundFKChecks(newVals opt.ColList, onlyUpdated bool) bool {
+	for i, n := 0, mb.tab.IndexCount(); i < n; i++ {
+		idx := mb.tab.Index(i)
+		fk, ok := idx.ForeignKey()
+		if !ok {
+			continue
+		}
+		numCols := int(fk.PrefixLen)
+		if onlyUpdated && !mb.indexColsUpdated(idx, numCols) {
+			continue
+		}
+		if !fkSupported(fk) {
+			return false
+		}
+
+		refTab, refIdx, ok := mb.resolveFKIndex(fk.TableID, fk.IndexID)
+		if !ok {
+			return false
+		}
+		mb.b.checkPrivilege(refTab, privilege.SELECT)
+
+		// Build the new values of the foreign key columns. Rows with a NULL in
+		// any of these columns satisfy the constraint (MATCH SIMPLE).
+		inCols := make(opt.ColList, numCols)
+		for j := range inCols {
+			inCols[j] = newVals[idx.Column(j).Ordinal]
+		}
+		input, keyCols := mb.buildWithScan(inCols)
+		input = mb.b.factory.ConstructSelect(input, mb.notNullFilters(keyCols))
+
+		refTabID, refCols, scan := mb.buildFKScan(refTab, refIdx, numCols)
+		check := mb.b.factory.ConstructAntiJoin(input, scan, mb.eqFilters(keyCols, refCols))
+
+		mb.checks = append(mb.checks, memo.FKChecksItem{
+			Check: check,
+			FKChecksItemPrivate: memo.FKChecksItemPrivate{
+				OriginTable:     mb.tabID,
+				ReferencedTable: refTabID,
+				FKOutbound:      true,
+				OriginIndex:     i,
+				KeyCols:         keyCols,
+				OpName:          mb.opName(),
+			},
+		})
+	}
+	return true
+}
+
+// buildInboundFKs builds the checks and cascades for the foreign keys that
+// reference the target table. The oldVals list contains the input columns
+// that hold the existing value of each table column. For an Update, newVals
+// contains the input columns that hold the new value of each table column, and
+// only the foreign keys that reference an updated column are considered. For a
+// Delete, newVals is nil.
+//
+// For a foreign key with a NO ACTION or RESTRICT action, the check is a
+// semi-join of the removed values against the origin index:
+//
+//   SELECT pk FROM [WithScan of the input] AS old
+//   WHERE EXISTS (SELECT * FROM child WHERE child.fk = old.pk)
+//
+// For an Update, the removed values are the old values EXCEPT the new values.
+// For other actions, a cascade mutation of the origin table is built (see
+// buildFKCascade).
+func (mb *mutationBuilder) buildInboundFKs(
+	oldVals, newVals opt.ColList, cascadePath []cat.StableID,
+) bool {
+	for i, n := 0, mb.tab.IndexCount(); i < n; i++ {
+		idx := mb.tab.Index(i)
+		for k, m := 0, idx.InboundForeignKeyCount(); k < m; k++ {
+			ref := idx.InboundForeignKey(k)
+			originTab, originIdx, ok := mb.resolveFKIndex(ref.TableID, ref.IndexID)
+			if !ok {
+				return false
+			}
+			fk, ok := originIdx.ForeignKey()
+			if !ok || !fkSupported(fk) {
+				return false
+			}
+			numCols := int(fk.PrefixLen)
+			if newVals != nil && !mb.indexColsUpdated(idx, numCols) {
+				continue
+			}
+			mb.b.checkPrivilege(originTab, privilege.SELECT)
+
+			action := fk.OnDelete
+			if newVals != nil {
+				action = fk.OnUpdate
+			}
+			if action != tree.NoAction && action != tree.Restrict {
+				cascade, originTabID, ok := mb.buildFKCascade(
+					originTab, originIdx, idx, numCols, action, oldVals, newVals, cascadePath,
+				)
+				if !ok {
+					return false
+				}
+				mb.cascades = append(mb.cascades, memo.FKCascadesItem{
+					Cascade: cascade,
+					FKCascadesItemPrivate: memo.FKCascadesItemPrivate{
+						OriginTable: originTabID,
+						OriginIndex: cat.FindIndexByID(originTab, originIdx.ID()),
+					},
+				})
+				continue
+			}
+
+			// Build the removed values of the referenced columns.
+			oldCols := make(opt.ColList, numCols)
+			for j := range oldCols {
+				oldCols[j] = oldVals[idx.Column(j).Ordinal]
+			}
+			input, keyCols := mb.buildWithScan(oldCols)
+			if newVals != nil {
+				newCols := make(opt.ColList, numCols)
+				for j := range newCols {
+					newCols[j] = newVals[idx.Column(j).Ordinal]
+				}
+				newInput, newKeyCols := mb.buildWithScan(newCols)
+				input = mb.b.factory.ConstructExcept(input, newInput, &memo.SetPrivate{
+					LeftCols:  keyCols,
+					RightCols: newKeyCols,
+					OutCols:   keyCols,
+				})
+			}
+
+			originTabID, originCols, scan := mb.buildFKScan(originTab, originIdx, numCols)
+			check := mb.b.factory.ConstructSemiJoin(input, scan, mb.eqFilters(keyCols, originCols))
+
+			mb.checks = append(mb.checks, memo.FKChecksItem{
+				Check: check,
+				FKChecksItemPrivate: memo.FKChecksItemPrivate{
+					OriginTable:     originTabID,
+					ReferencedTable: mb.tabID,
+					FKOutbound:      false,
+					OriginIndex:     cat.FindIndexByID(originTab, originIdx.ID()),
+					KeyCols:         keyCols,
+					OpName:          mb.opName(),
+				},
+			})
+		}
+	}
+	return true
+}
+
+// buildFKCascade builds the Delete or Update operator that carries out the
+// given action on the rows of the origin table (the "child" table) which
+// reference the mutated rows of the target table, through the given origin
+// index. The first numCols columns of refIdx are the referenced columns of the
+// target table. See buildInboundFKs for the meaning of oldVals and newVals.
+//
+// The cascade mutation has the following input, depending on the action:
+//
+//   ON DELETE CASCADE:
+//     SELECT * FROM child WHERE EXISTS (
+//       SELECT * FROM [WithScan of the input] AS old WHERE child.fk = old.pk
+//     )
+//
+//   ON DELETE/UPDATE SET NULL, SET DEFAULT:
+//     SELECT *, NULL (or DEFAULT) AS fk_new FROM child WHERE EXISTS (...)
+//
+//   ON UPDATE CASCADE:
+//     SELECT child.*, new.pk AS fk_new
+//     FROM child INNER JOIN [WithScan of the input] AS (old, new)
+//     ON child.fk = old.pk AND old.pk IS DISTINCT FROM new.pk
+//
+// The cascade mutation builds its own checks and cascades. It returns false if
+// the cascade must be left to the execution engine, e.g. because the origin
+// table is already mutated by the chain of cascades that led to it.
+func (mb *mutationBuilder) buildFKCascade(
+	originTab cat.Table,
+	originIdx cat.Index,
+	refIdx cat.Index,
+	numCols int,
+	action tree.ReferenceAction,
+	oldVals, newVals opt.ColList,
+	cascadePath []cat.StableID,
+) (_ memo.RelExpr, _ opt.TableID, ok bool) {
+	for _, id := range cascadePath {
+		if id == originTab.ID() {
+			return nil, 0, false
+		}
+	}
+
+	op := opt.UpdateOp
+	if action == tree.Cascade && newVals == nil {
+		op = opt.DeleteOp
+		mb.b.checkPrivilege(originTab, privilege.DELETE)
+	} else {
+		mb.b.checkPrivilege(originTab, privilege.UPDATE)
+	}
+
+	// The execution engine raises a dedicated error when a cascade sets a NOT
+	// NULL column to NULL, so leave such cascades to it.
+	if op == opt.UpdateOp {
+		for j := 0; j < numCols; j++ {
+			col := originTab.Column(originIdx.Column(j).Ordinal)
+			if col.IsNullable() {
+				continue
+			}
+			switch action {
+			case tree.SetNull:
+				return nil, 0, false
+			case tree.SetDefault:
+				if !col.HasDefault() {
+					return nil, 0, false
+				}
+			case tree.Cascade:
+				if mb.tab.Column(refIdx.Column(j).Ordinal).IsNullable() {
+					return nil, 0, false
+				}
+			}
+		}
+	}
+
+	var cmb mutationBuilder
+	cmb.init(mb.b, op, originTab, nil /* alias */)
+	cmb.buildInputForUpdateOrDelete(mb.b.allocScope(), nil /* where */, nil /* limit */, nil)
+
+	// Build the old (and new) values of the referenced columns.
+	inCols := make(opt.ColList, 0, numCols*2)
+	for j := 0; j < numCols; j++ {
+		inCols = append(inCols, oldVals[refIdx.Column(j).Ordinal])
+	}
+	if newVals != nil {
+		for j := 0; j < numCols; j++ {
+			inCols = append(inCols, newVals[refIdx.Column(j).Ordinal])
+		}
+	}
+	withScan, withScanCols := mb.buildWithScan(inCols)
+	oldCols, newCols := withScanCols[:numCols], withScanCols[numCols:]
+
+	childCols := make(opt.ColList, numCols)
+	for j := range childCols {
+		childCols[j] = cmb.fetchColList[originIdx.Column(j).Ordinal]
+	}
+	on := mb.eqFilters(childCols, oldCols)
+	if newVals != nil {
+		// Only the rows whose referenced values are changed by the update need
+		// to be cascaded.
+		var changed opt.ScalarExpr
+		for j := range oldCols {
+			cond := mb.b.factory.ConstructIsNot(
+				mb.b.factory.ConstructVariable(oldCols[j]),
+				mb.b.factory.ConstructVariable(newCols[j]),
+			)
+			if changed == nil {
+				changed = cond
+			} else {
+				changed = mb.b.factory.ConstructOr(changed, cond)
+			}
+		}
+		on = append(on, memo.FiltersItem{Condition: changed})
+	}
+
+	switch {
+	case op == opt.DeleteOp:
+		cmb.outScope.expr = mb.b.factory.ConstructSemiJoin(cmb.outScope.expr, withScan, on)
+
+	case action == tree.Cascade:
+		cmb.outScope.expr = mb.b.factory.ConstructInnerJoin(cmb.outScope.expr, withScan, on)
+		for _, col := range newCols {
+			cmb.outScope.cols = append(cmb.outScope.cols, scopeColumn{
+				typ: mb.md.ColumnMeta(col).Type,
+				id:  col,
+			})
+		}
+		cmb.updateColList = make(opt.ColList, cap(cmb.targetColList))
+		for j, col := range newCols {
+			ord := originIdx.Column(j).Ordinal
+			cmb.addTargetCol(ord)
+			cmb.updateColList[ord] = col
+		}
+		cmb.addComputedColsForUpdate()
+
+	default:
+		cmb.outScope.expr = mb.b.factory.ConstructSemiJoin(cmb.outScope.expr, withScan, on)
+		var expr tree.Expr = tree.DNull
+		if action == tree.SetDefault {
+			expr = tree.DefaultVal{}
+		}
+		exprs := make(tree.UpdateExprs, numCols)
+		for j := range exprs {
+			colName := originTab.Column(originIdx.Column(j).Ordinal).ColName()
+			exprs[j] = &tree.UpdateExpr{Names: tree.NameList{colName}, Expr: expr}
+		}
+		cmb.addTargetColsForUpdate(exprs)
+		cmb.addUpdateCols(exprs)
+		cmb.addComputedColsForUpdate()
+	}
+
+	if !cmb.buildFKs(cascadePath) {
+		return nil, 0, false
+	}
+
+	private := memo.MutationPrivate{
+		Table:      cmb.tabID,
+		FetchCols:  cmb.fetchColList,
+		UpdateCols: cmb.updateColList,
+		WithID:     cmb.withID,
+	}
+	if op == opt.DeleteOp {
+		cascade := mb.b.factory.ConstructDelete(cmb.outScope.expr, cmb.checks, cmb.cascades, &private)
+		return cascade, cmb.tabID, true
+	}
+	cascade := mb.b.factory.ConstructUpdate(cmb.outScope.expr, cmb.checks, cmb.cascades, &private)
+	return cascade, cmb.tabID, true
+}
+
+// buildWithScan constructs a WithScan operator that returns the values of the
+// given input columns for each row of the mutation input. The input of the
+// mutation is buffered under mb.withID, which is allocated on first use. The
+// WithScan produces new columns, returned in the same order as inCols.
+func (mb *mutationBuilder) buildWithScan(inCols opt.ColList) (memo.RelExpr, opt.ColList) {
+	if mb.withID == 0 {
+		mb.b.lastWithID++
+		mb.withID = mb.b.lastWithID
+	}
+	outCols := make(opt.ColList, len(inCols))
+	for i, col := range inCols {
+		colMeta := mb.md.ColumnMeta(col)
+		outCols[i] = mb.md.AddColumn(colMeta.Alias, colMeta.Type)
+	}
+	return mb.b.factory.ConstructWithScan(&memo.WithScanPrivate{
+		Name:         string(mb.alias.TableName),
+		WithID:       mb.withID,
+		InCols:       inCols,
+		OutCols:      outCols,
+		BindingProps: mb.outScope.expr.Relational(),
+	}), outCols
+}
+
+// buildFKScan constructs a Scan operator that returns the first numCols
+// columns of the given index. It returns the metadata ID of the new table
+// reference, and the scanned columns in index order.
+func (mb *mutationBuilder) buildFKScan(
+	tab cat.Table, idx cat.Index, numCols int,
+) (opt.TableID, opt.ColList, memo.RelExpr) {
+	tabID := mb.md.AddTable(tab)
+	cols := make(opt.ColList, numCols)
+	var colSet opt.ColSet
+	for j := range cols {
+		cols[j] = tabID.ColumnID(idx.Column(j).Ordinal)
+		colSet.Add(int(cols[j]))
+	}
+	scan := mb.b.factory.ConstructScan(&memo.ScanPrivate{Table: tabID, Cols: colSet})
+	return tabID, cols, scan
+}
+
+// resolveFKIndex returns the table and index with the given IDs. It returns
+// false if the table cannot be resolved (e.g. because it is still being
+// added), or if the index is not public.
+func (mb *mutationBuilder) resolveFKIndex(
+	tabID, idxID cat.StableID,
+) (_ cat.Table, _ cat.Index, ok bool) {
+	ds, err := mb.b.catalog.ResolveDataSourceByID(mb.b.ctx, tabID)
+	if err != nil {
+		return nil, nil, false
+	}
+	tab, ok := ds.(cat.Table)
+	if !ok {
+		return nil, nil, false
+	}
+	idxOrd := cat.FindIndexByID(tab, idxID)
+	if idxOrd == -1 {
+		return nil, nil, false
+	}
+	return tab, tab.Index(idxOrd), true
+}
+
+// indexColsUpdated returns true if any of the first numCols columns of the
+// given index of the target table is updated by the mutation.
+func (mb *mutationBuilder) indexColsUpdated(idx cat.Index, numCols int) bool {
+	if mb.updateColList == nil {
+		return false
+	}
+	for j := 0; j < numCols; j++ {
+		if mb.updateColList[idx.Column(j).Ordinal] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// notNullFilters returns a filter that requires all of the given columns to be
+// non-NULL.
+func (mb *mutationBuilder) notNullFilters(cols opt.ColList) memo.FiltersExpr {
+	filters := make(memo.FiltersExpr, len(cols))
+	for i, col := range cols {
+		filters[i] = memo.FiltersItem{
+			Condition: mb.b.factory.ConstructIsNot(
+				mb.b.factory.ConstructVariable(col), memo.NullSingleton,
+			),
+		}
+	}
+	return filters
+}
+
+// eqFilters returns a filter that requires each of the left columns to be
+// equal to the corresponding right column.
+func (mb *mutationBuilder) eqFilters(left, right opt.ColList) memo.FiltersExpr {
+	filters := make(memo.FiltersExpr, len(left))
+	for i := range left {
+		filters[i] = memo.FiltersItem{
+			Condition: mb.b.factory.ConstructEq(
+				mb.b.factory.ConstructVariable(left[i]),
+				mb.b.factory.ConstructVariable(right[i]),
+			),
+		}
+	}
+	return filters
+}
+
+// opName returns the name of the mutation operation, as used in the foreign
+// key checks.
+func (mb *mutationBuilder) opName() string {
+	switch mb.op {
+	case opt.InsertOp:
+		return "insert"
+	case opt.UpdateOp:
+		return "update"
+	case opt.UpsertOp:
+		return "upsert"
+	default:
+		return "delete"
+	}
+}
+
+// fkSupported returns true if the optimizer can plan the checks and cascades
+// of the given foreign key. Deferrable constraints can't be checked at the end
+// of the statement, and MATCH FULL constraints are checked by the execution
+// engine, which raises a dedicated error for mixed NULL and non-NULL values.
+func fkSupported(fk cat.ForeignKeyReference) bool {
+	return !fk.Deferrable && fk.Match == tree.MatchSimple
+}
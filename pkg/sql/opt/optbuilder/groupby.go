@@ -71,6 +71,16 @@ type groupby struct {
 	// projects that expression.
 	groupStrs groupByStrSet
 
+	// groupingSets contains the grouping sets of a GROUP BY with ROLLUP, CUBE
+	// or GROUPING SETS, as sets of grouping columns. It is nil if there is only
+	// a single grouping set, which is the case for a plain GROUP BY.
+	groupingSets []opt.ColSet
+
+	// groupingSetIDCol is the column in aggOutScope that contains the ordinal
+	// of the grouping set that produced each row. It is only set if there are
+	// groupingSets, and is used to build the GROUPING function.
+	groupingSetIDCol opt.ColumnID
+
 	// inAgg is true within the body of an aggregate function. inAgg is used
 	// to ensure that nested aggregates are disallowed.
 	inAgg bool
@@ -89,6 +99,10 @@ type aggregateInfo struct {
 	distinct bool
 	args     memo.ScalarListExpr
 
+	// filter is the built FILTER expression of the aggregation, or nil if there
+	// is no FILTER clause.
+	filter opt.ScalarExpr
+
 	// col is the output column of the aggregation.
 	col *scopeColumn
 
@@ -134,6 +148,43 @@ func (b *Builder) needsAggregation(sel *tree.SelectClause, scope *scope) bool {
 func (b *Builder) constructGroupBy(
 	input memo.RelExpr, groupingColSet opt.ColSet, aggCols []scopeColumn, ordering opt.Ordering,
 ) memo.RelExpr {
+	aggs := b.constructAggregations(aggCols)
+	private := memo.GroupingPrivate{GroupingCols: groupingColSet}
+
+	// The ordering of the GROUP BY is inherited from the input. This ordering is
+	// only useful for intra-group ordering (for order-sensitive aggregations like
+	// ARRAY_AGG). So we add the grouping columns as optional columns.
+	private.Ordering.FromOrderingWithOptCols(ordering, groupingColSet)
+
+	if groupingColSet.Empty() {
+		return b.factory.ConstructScalarGroupBy(input, aggs, &private)
+	}
+	return b.factory.ConstructGroupBy(input, aggs, &private)
+}
+
+// constructGroupingSetsGroupBy constructs a GroupingSetsGroupBy operator that
+// groups the input by each of the grouping sets in turn.
+func (b *Builder) constructGroupingSetsGroupBy(
+	input memo.RelExpr,
+	groupingColSet opt.ColSet,
+	groupingSets []opt.ColSet,
+	groupingSetIDCol opt.ColumnID,
+	aggCols []scopeColumn,
+	ordering opt.Ordering,
+) memo.RelExpr {
+	aggs := b.constructAggregations(aggCols)
+	private := memo.GroupingSetsPrivate{
+		GroupingCols:     groupingColSet,
+		GroupingSets:     memo.GroupingSets(groupingSets),
+		GroupingSetIDCol: groupingSetIDCol,
+	}
+	private.Ordering.FromOrderingWithOptCols(ordering, groupingColSet)
+	return b.factory.ConstructGroupingSetsGroupBy(input, aggs, &private)
+}
+
+// constructAggregations constructs the aggregations of a grouping operator
+// from the given aggregate columns.
+func (b *Builder) constructAggregations(aggCols []scopeColumn) memo.AggregationsExpr {
 	aggs := make(memo.AggregationsExpr, 0, len(aggCols))
 
 	// Deduplicate the columns; we don't need to produce the same aggregation
@@ -153,18 +204,7 @@ func (b *Builder) constructGroupBy(
 			colSet.Add(int(id))
 		}
 	}
-
-	private := memo.GroupingPrivate{GroupingCols: groupingColSet}
-
-	// The ordering of the GROUP BY is inherited from the input. This ordering is
-	// only useful for intra-group ordering (for order-sensitive aggregations like
-	// ARRAY_AGG). So we add the grouping columns as optional columns.
-	private.Ordering.FromOrderingWithOptCols(ordering, groupingColSet)
-
-	if groupingColSet.Empty() {
-		return b.factory.ConstructScalarGroupBy(input, aggs, &private)
-	}
-	return b.factory.ConstructGroupBy(input, aggs, &private)
+	return aggs
 }
 
 // buildAggregation builds the pre-projection and the aggregation operators.
@@ -185,6 +225,8 @@ func (b *Builder) buildAggregation(
 	//     specifically:
 	//      - columns for the results of the aggregate functions.
 	//      - the grouping columns
+	//      - the ordinal of the grouping set, if there are grouping sets (see
+	//        buildGroupingList)
 	//
 	// For example:
 	//
@@ -219,6 +261,17 @@ func (b *Builder) buildAggregation(
 	groupingCols := aggInScope.getGroupingCols(groupingsLen)
 	aggOutScope.appendColumns(groupingCols)
 
+	groupingSets := fromScope.groupby.groupingSets
+	if groupingSets != nil {
+		// The GroupingSetsGroupBy operator also produces the ordinal of the
+		// grouping set of each row, which is used by the GROUPING function.
+		col := b.synthesizeColumn(
+			aggOutScope, "grouping_set_id", types.Int, nil /* expr */, nil, /* scalar */
+		)
+		col.hidden = true
+		fromScope.groupby.groupingSetIDCol = col.id
+	}
+
 	var having opt.ScalarExpr
 	if sel.Having != nil {
 		// Any "grouping" columns are visible to both the "having" and "projection"
@@ -257,10 +310,25 @@ func (b *Builder) buildAggregation(
 			// Append any constant arguments without further processing.
 			args = append(args, agg.args[1:]...)
 		}
-
-		aggCols[i].scalar = b.constructAggregate(agg.def.Name, args).(opt.ScalarExpr)
 		argCols = argCols[len(agg.args):]
 
+		name := agg.def.Name
+		if agg.filter != nil {
+			filter := b.factory.ConstructVariable(argCols[0].id)
+			argCols = argCols[1:]
+			if len(args) == 0 {
+				// count_rows is the only aggregate without arguments. With a FILTER,
+				// it is built as a count of the filter column instead, which is true
+				// (and therefore not NULL) on every row that passes the filter.
+				name = "count"
+				args = append(args, filter)
+			}
+			// Wrap the argument with AggFilter.
+			args[0] = b.factory.ConstructAggFilter(args[0], filter)
+		}
+
+		aggCols[i].scalar = b.constructAggregate(name, args).(opt.ScalarExpr)
+
 		if opt.AggregateIsOrderingSensitive(aggCols[i].scalar.Op()) {
 			haveOrderingSensitiveAgg = true
 		}
@@ -290,12 +358,23 @@ func (b *Builder) buildAggregation(
 		groupingColSet.Add(int(groupingCols[i].id))
 	}
 
-	aggOutScope.expr = b.constructGroupBy(
-		aggInScope.expr.(memo.RelExpr),
-		groupingColSet,
-		aggCols,
-		aggInScope.ordering,
-	)
+	if groupingSets != nil {
+		aggOutScope.expr = b.constructGroupingSetsGroupBy(
+			aggInScope.expr.(memo.RelExpr),
+			groupingColSet,
+			groupingSets,
+			fromScope.groupby.groupingSetIDCol,
+			aggCols,
+			aggInScope.ordering,
+		)
+	} else {
+		aggOutScope.expr = b.constructGroupBy(
+			aggInScope.expr.(memo.RelExpr),
+			groupingColSet,
+			aggCols,
+			aggInScope.ordering,
+		)
+	}
 
 	// Wrap with having filter if it exists.
 	if having != nil {
@@ -332,6 +411,14 @@ func (b *Builder) buildHaving(having tree.TypedExpr, inScope *scope) opt.ScalarE
 	return b.buildScalar(having, inScope, nil, nil, nil)
 }
 
+// maxGroupingSets is the maximum number of grouping sets that a GROUP BY
+// clause can expand to.
+const maxGroupingSets = 4096
+
+// maxCubeLen is the maximum number of elements of a CUBE. A CUBE with n
+// elements expands to 2^n grouping sets.
+const maxCubeLen = 12
+
 // buildGroupingList builds a set of memo groups that represent a list of
 // GROUP BY expressions.
 //
@@ -341,6 +428,13 @@ func (b *Builder) buildHaving(having tree.TypedExpr, inScope *scope) opt.ScalarE
 //              SELECT count(*), k FROM t GROUP BY 2
 //          indicates that the grouping is on the second select expression, k.
 //
+// If the GROUP BY contains ROLLUP, CUBE or GROUPING SETS items, the grouping
+// sets are stored in inScope.groupby.groupingSets. Like in Postgres, the
+// grouping sets of a GROUP BY list are the cross product of the grouping sets
+// of its items. For example:
+//   GROUP BY a, ROLLUP (b, c)
+// has the grouping sets (a, b, c), (a, b) and (a).
+//
 // See Builder.buildStmt for a description of the remaining input values.
 func (b *Builder) buildGroupingList(
 	groupBy tree.GroupBy, selects tree.SelectExprs, inScope *scope, outScope *scope,
@@ -351,14 +445,109 @@ func (b *Builder) buildGroupingList(
 	}
 
 	inScope.startBuildingGroupingCols()
+	groupingSets := []opt.ColSet{{}}
+	hasGroupingSetItems := false
 	for _, e := range groupBy {
-		b.buildGrouping(e, selects, inScope, outScope)
+		if _, ok := tree.StripParens(e).(*tree.GroupingSet); ok {
+			hasGroupingSetItems = true
+		}
+		itemSets := b.buildGroupingSets(e, selects, inScope, outScope)
+		if len(groupingSets)*len(itemSets) > maxGroupingSets {
+			panic(builderError{errTooManyGroupingSets})
+		}
+		product := make([]opt.ColSet, 0, len(groupingSets)*len(itemSets))
+		for _, set := range groupingSets {
+			for _, itemSet := range itemSets {
+				product = append(product, set.Union(itemSet))
+			}
+		}
+		groupingSets = product
 	}
 	inScope.endBuildingGroupingCols()
+
+	if hasGroupingSetItems && len(groupingSets) > 1 {
+		inScope.groupby.groupingSets = groupingSets
+	}
+}
+
+var errTooManyGroupingSets = pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+	"too many grouping sets (maximum %d)", maxGroupingSets,
+)
+
+// buildGroupingSets builds the columns of a GROUP BY item and returns its
+// grouping sets. A plain grouping expression has a single grouping set, which
+// contains its columns. The grouping sets of ROLLUP, CUBE and GROUPING SETS
+// items are computed as follows:
+//
+//   ROLLUP (a, b, c)            => (a, b, c), (a, b), (a), ()
+//   CUBE (a, b)                 => (a, b), (a), (b), ()
+//   GROUPING SETS (a, (b, c))   => (a), (b, c)
+//
+// See buildGrouping for a description of the input values.
+func (b *Builder) buildGroupingSets(
+	groupBy tree.Expr, selects tree.SelectExprs, inScope, outScope *scope,
+) []opt.ColSet {
+	groupingSet, ok := tree.StripParens(groupBy).(*tree.GroupingSet)
+	if !ok {
+		return []opt.ColSet{b.buildGrouping(groupBy, selects, inScope, outScope)}
+	}
+
+	var sets []opt.ColSet
+	switch groupingSet.Type {
+	case tree.GroupingSets:
+		for _, e := range groupingSet.Exprs {
+			sets = append(sets, b.buildGroupingSets(e, selects, inScope, outScope)...)
+			if len(sets) > maxGroupingSets {
+				panic(builderError{errTooManyGroupingSets})
+			}
+		}
+
+	case tree.Rollup:
+		elems := make([]opt.ColSet, len(groupingSet.Exprs))
+		for i, e := range groupingSet.Exprs {
+			elems[i] = b.buildGrouping(e, selects, inScope, outScope)
+		}
+		sets = make([]opt.ColSet, len(elems)+1)
+		for i := range elems {
+			sets[i+1] = sets[i].Union(elems[i])
+		}
+		// Order the sets from the longest prefix to the empty set.
+		for i, j := 0, len(sets)-1; i < j; i, j = i+1, j-1 {
+			sets[i], sets[j] = sets[j], sets[i]
+		}
+
+	case tree.Cube:
+		if len(groupingSet.Exprs) > maxCubeLen {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"CUBE is limited to %d elements", maxCubeLen,
+			)})
+		}
+		elems := make([]opt.ColSet, len(groupingSet.Exprs))
+		for i, e := range groupingSet.Exprs {
+			elems[i] = b.buildGrouping(e, selects, inScope, outScope)
+		}
+		// Each bit of the mask corresponds to an element; the first element is
+		// the most significant bit, so the sets are ordered like in Postgres.
+		n := len(elems)
+		sets = make([]opt.ColSet, 0, 1<<uint(n))
+		for mask := (1 << uint(n)) - 1; mask >= 0; mask-- {
+			var set opt.ColSet
+			for i := range elems {
+				if mask&(1<<uint(n-1-i)) != 0 {
+					set = set.Union(elems[i])
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	default:
+		panic(fmt.Sprintf("unhandled grouping set type: %s", groupingSet.Type))
+	}
+	return sets
 }
 
 // buildGrouping builds a set of memo groups that represent a GROUP BY
-// expression.
+// expression, and returns the set of grouping columns of the expression.
 //
 // groupBy  The given GROUP BY expression.
 // selects  The select expressions are needed in case the GROUP BY expression
@@ -367,7 +556,7 @@ func (b *Builder) buildGroupingList(
 // See Builder.buildStmt for a description of the remaining input values.
 func (b *Builder) buildGrouping(
 	groupBy tree.Expr, selects tree.SelectExprs, inScope, outScope *scope,
-) {
+) opt.ColSet {
 	// Unwrap parenthesized expressions like "((a))" to "a".
 	groupBy = tree.StripParens(groupBy)
 
@@ -393,14 +582,23 @@ func (b *Builder) buildGrouping(
 	exprs = flattenTuples(exprs)
 
 	// Finally, build each of the GROUP BY columns.
+	var cols opt.ColSet
 	for _, e := range exprs {
 		// Save a representation of the GROUP BY expression for validation of the
 		// SELECT and HAVING expressions. This enables queries such as:
 		//   SELECT x+y FROM t GROUP BY x+y
-		col := b.addColumn(outScope, alias, e)
-		b.buildScalar(e, inScope, outScope, col, nil)
-		inScope.groupby.groupStrs[symbolicExprStr(e)] = col
+		// Expressions that appear multiple times (which is common with grouping
+		// sets) are only built once.
+		exprStr := symbolicExprStr(e)
+		col, ok := inScope.groupby.groupStrs[exprStr]
+		if !ok {
+			col = b.addColumn(outScope, alias, e)
+			b.buildScalar(e, inScope, outScope, col, nil)
+			inScope.groupby.groupStrs[exprStr] = col
+		}
+		cols.Add(int(col.id))
 	}
+	return cols
 }
 
 // buildAggregateFunction is called when we are building a function which is an
@@ -422,11 +620,12 @@ func (b *Builder) buildAggregateFunction(
 	tempScope := inScope.startAggFunc()
 	tempScopeColsBefore := len(tempScope.cols)
 
+	aggArgs := f.AggregateArgs()
 	info := aggregateInfo{
 		FuncExpr: f,
 		def:      *def,
 		distinct: (f.Type == tree.DistinctFuncType),
-		args:     make(memo.ScalarListExpr, len(f.Exprs)),
+		args:     make(memo.ScalarListExpr, len(aggArgs)),
 	}
 
 	// Temporarily set b.subquery to nil so we don't add outer columns to the
@@ -435,18 +634,24 @@ func (b *Builder) buildAggregateFunction(
 	b.subquery = nil
 	defer func() { b.subquery = subq }()
 
-	for i, pexpr := range f.Exprs {
+	buildArg := func(texpr tree.TypedExpr) opt.ScalarExpr {
 		// This synthesizes a new tempScope column, unless the argument is a
 		// simple VariableOp.
-		texpr := pexpr.(tree.TypedExpr)
-
 		col := b.addColumn(tempScope, "" /* alias */, texpr)
 		b.buildScalar(texpr, inScope, tempScope, col, &info.colRefs)
 		if col.scalar != nil {
-			info.args[i] = col.scalar
-		} else {
-			info.args[i] = b.factory.ConstructVariable(col.id)
+			return col.scalar
 		}
+		return b.factory.ConstructVariable(col.id)
+	}
+	for i, pexpr := range aggArgs {
+		info.args[i] = buildArg(pexpr.(tree.TypedExpr))
+	}
+
+	// The column for the FILTER expression, if any, follows the columns for the
+	// arguments.
+	if f.Filter != nil {
+		info.filter = buildArg(f.Filter.(tree.TypedExpr))
 	}
 
 	// Find the appropriate aggregation scopes for this aggregate now that we
@@ -481,6 +686,88 @@ func (b *Builder) buildAggregateFunction(
 	return &info
 }
 
+// maxGroupingArgs is the maximum number of arguments of the GROUPING function;
+// the result of the function must fit in an INT4.
+const maxGroupingArgs = 31
+
+// buildGroupingFunction builds the GROUPING function, which returns a bit mask
+// of its arguments that are not part of the grouping set of the current row.
+// The arguments must be grouping expressions. For example:
+//
+//   SELECT a, b, GROUPING(a, b) FROM t GROUP BY ROLLUP (a, b)
+//
+// returns 0 for rows grouped by (a, b), 1 for rows grouped by (a) and 3 for
+// the row grouped by (). The bit mask of each grouping set is known up front,
+// so the function is built as a CASE expression over the ordinal of the
+// grouping set. Without grouping sets, the result is always 0.
+//
+// See buildFunction for a description of the input values.
+func (b *Builder) buildGroupingFunction(
+	f *tree.FuncExpr, inScope, outScope *scope, outCol *scopeColumn, colRefs *opt.ColSet,
+) opt.ScalarExpr {
+	if inScope.groupby.inAgg {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeGroupingError,
+			"aggregate function calls cannot contain grouping operations",
+		)})
+	}
+	if !inScope.inGroupingContext() || inScope.groupby.buildingGroupingCols {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeGroupingError,
+			"GROUPING() can only be used in a query with GROUP BY",
+		)})
+	}
+	if len(f.Exprs) > maxGroupingArgs {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeTooManyArgumentsError,
+			"GROUPING must have fewer than %d arguments", maxGroupingArgs+1,
+		)})
+	}
+
+	args := make([]opt.ColumnID, len(f.Exprs))
+	for i, e := range f.Exprs {
+		col, ok := inScope.groupby.groupStrs[symbolicExprStr(e)]
+		if !ok {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeGroupingError,
+				"arguments to GROUPING must be grouping expressions of the associated query level",
+			)})
+		}
+		args[i] = col.id
+	}
+
+	// mask returns the result of the function for the given grouping set. The
+	// last argument corresponds to the least significant bit.
+	mask := func(set opt.ColSet) opt.ScalarExpr {
+		var res tree.DInt
+		for _, col := range args {
+			res <<= 1
+			if !set.Contains(int(col)) {
+				res |= 1
+			}
+		}
+		return b.factory.ConstructConst(tree.NewDInt(res))
+	}
+
+	groupingSets := inScope.groupby.groupingSets
+	if groupingSets == nil {
+		out := b.factory.ConstructConst(tree.NewDInt(0))
+		return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+	}
+
+	idCol := inScope.groupby.groupingSetIDCol
+	if colRefs != nil {
+		colRefs.Add(int(idCol))
+	}
+	last := len(groupingSets) - 1
+	whens := make(memo.ScalarListExpr, last)
+	for i := range whens {
+		whens[i] = b.factory.ConstructWhen(
+			b.factory.ConstructConst(tree.NewDInt(tree.DInt(i))), mask(groupingSets[i]),
+		)
+	}
+	out := b.factory.ConstructCase(
+		b.factory.ConstructVariable(idCol), whens, mask(groupingSets[last]),
+	)
+	return b.finishBuildScalar(f, out, inScope, outScope, outCol)
+}
+
 func (b *Builder) constructAggregate(name string, args []opt.ScalarExpr) opt.ScalarExpr {
 	switch name {
 	case "array_agg":
@@ -518,16 +805,31 @@ func (b *Builder) constructAggregate(name string, args []opt.ScalarExpr) opt.Sca
 	case "jsonb_agg":
 		return b.factory.ConstructJsonbAgg(args[0])
 	case "string_agg":
-		if !memo.CanExtractConstDatum(args[1]) {
-			panic(builderError{
-				fmt.Errorf("unimplemented: aggregate functions with multiple non-constant expressions are not supported"),
-			})
-		}
-		return b.factory.ConstructStringAgg(args[0], args[1])
+		return b.factory.ConstructStringAgg(args[0], b.constAggregateArg(args[1]))
+	case "percentile_disc":
+		return b.factory.ConstructPercentileDisc(args[0], b.constAggregateArg(args[1]))
+	case "percentile_cont":
+		return b.factory.ConstructPercentileCont(args[0], b.constAggregateArg(args[1]))
+	case "mode":
+		return b.factory.ConstructMode(args[0])
+	case "approx_percentile":
+		return b.factory.ConstructApproxPercentile(args[0], b.constAggregateArg(args[1]))
 	}
 	panic(fmt.Sprintf("unhandled aggregate: %s", name))
 }
 
+// constAggregateArg returns the given argument of an aggregate function after
+// checking that it is a constant; the aggregate functions only support
+// constant arguments after the first one.
+func (b *Builder) constAggregateArg(arg opt.ScalarExpr) opt.ScalarExpr {
+	if !memo.CanExtractConstDatum(arg) {
+		panic(builderError{
+			fmt.Errorf("unimplemented: aggregate functions with multiple non-constant expressions are not supported"),
+		})
+	}
+	return arg
+}
+
 func isAggregate(def *tree.FunctionDefinition) bool {
 	return def.Class == tree.AggregateClass
 }
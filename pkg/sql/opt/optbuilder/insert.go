@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -145,7 +146,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	}
 
 	if ins.With != nil {
-		inScope = b.buildCTE(ins.With.CTEList, inScope)
+		inScope = b.buildCTE(ins.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -155,7 +156,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(ins.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab := b.resolveTable(tn, privilege.INSERT)
+	tab := b.resolveTableForMutation(tn, privilege.INSERT)
 
 	if ins.OnConflict != nil {
 		// UPSERT and INDEX ON CONFLICT will read from the table to check for
@@ -253,7 +254,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	case ins.OnConflict.IsUpsertAlias():
 		// Left-join each input row to the target table, using conflict columns
 		// derived from the primary index as the join condition.
-		mb.buildInputForUpsert(inScope, mb.getPrimaryKeyColumnNames(), nil)
+		mb.buildInputForUpsert(inScope, mb.getPrimaryKeyColumnNames(), nil, nil)
 
 		// Add columns which will be updated by the Upsert when a conflict occurs.
 		// These are derived from the insert columns.
@@ -270,7 +271,9 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	default:
 		// Left-join each input row to the target table, using the conflict columns
 		// as the join condition.
-		mb.buildInputForUpsert(inScope, ins.OnConflict.Columns, ins.OnConflict.Where)
+		mb.buildInputForUpsert(
+			inScope, ins.OnConflict.Columns, ins.OnConflict.ArbiterPredicate, ins.OnConflict.Where,
+		)
 
 		// Derive the columns that will be updated from the SET expressions.
 		mb.addTargetColsForUpdate(ins.OnConflict.Exprs)
@@ -531,12 +534,18 @@ func (mb *mutationBuilder) addDefaultAndComputedColsForInsert() {
 // buildInsert constructs an Insert operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
+	mb.buildFKChecksAndCascades()
+
 	private := memo.MutationPrivate{
 		Table:       mb.tabID,
 		InsertCols:  mb.insertColList,
 		NeedResults: returning != nil,
+		WithID:      mb.withID,
+		FKFallback:  mb.fkFallback,
 	}
-	mb.outScope.expr = mb.b.factory.ConstructInsert(mb.outScope.expr, &private)
+	mb.outScope.expr = mb.b.factory.ConstructInsert(
+		mb.outScope.expr, mb.checks, mb.cascades, &private,
+	)
 
 	mb.buildReturning(returning)
 }
@@ -546,6 +555,10 @@ func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
 // filter that discards rows that have a conflict (by checking a not-null table
 // column to see if it was null-extended by the left join). See the comment
 // header for Builder.buildInsert for an example.
+//
+// A unique partial index only enforces uniqueness among the rows that satisfy
+// its predicate, so the left join for such an index only matches the insert
+// rows and the existing rows that both satisfy the predicate.
 func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tree.OnConflict) {
 	// DO NOTHING clause does not require ON CONFLICT columns.
 	var conflictIndex cat.Index
@@ -554,7 +567,7 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 		// ensuring they match columns of a UNIQUE index. Using LEFT OUTER JOIN
 		// to detect conflicts relies upon this being true (otherwise result
 		// cardinality could increase). This is also a Postgres requirement.
-		conflictIndex = mb.ensureUniqueConflictCols(onConflict.Columns)
+		conflictIndex = mb.ensureUniqueConflictCols(onConflict.Columns, onConflict.ArbiterPredicate)
 	}
 
 	insertColSet := mb.outScope.expr.Relational().OutputCols
@@ -612,6 +625,11 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 			)
 			on = append(on, memo.FiltersItem{Condition: condition})
 		}
+		scanCols := make(opt.ColList, len(scanScope.cols))
+		for i := range scanScope.cols {
+			scanCols[i] = scanScope.cols[i].id
+		}
+		on = mb.addPartialIndexPredicateFilters(on, index, scanCols)
 
 		// Construct the left join + filter.
 		// TODO(andyk): Convert this to use anti-join once we have support for
@@ -646,13 +664,13 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 // given insert row conflicts with an existing row in the table. If it is null,
 // then there is no conflict.
 func (mb *mutationBuilder) buildInputForUpsert(
-	inScope *scope, conflictCols tree.NameList, whereClause *tree.Where,
+	inScope *scope, conflictCols tree.NameList, arbiterPredicate tree.Expr, whereClause *tree.Where,
 ) {
 	// Check that the ON CONFLICT columns reference at most one target row.
 	// Using LEFT OUTER JOIN to detect conflicts relies upon this being true
 	// (otherwise result cardinality could increase). This is also a Postgres
 	// requirement.
-	mb.ensureUniqueConflictCols(conflictCols)
+	conflictIndex := mb.ensureUniqueConflictCols(conflictCols, arbiterPredicate)
 
 	// Re-alias all INSERT columns so that they are accessible as if they were
 	// part of a special data source named "crdb_internal.excluded".
@@ -708,6 +726,10 @@ func (mb *mutationBuilder) buildInputForUpsert(
 		}
 	}
 
+	// A partial conflict index only conflicts with the insert rows that
+	// satisfy its predicate.
+	on = mb.addPartialIndexPredicateFilters(on, conflictIndex, mb.fetchColList)
+
 	// Construct the left join.
 	mb.outScope.expr = mb.b.factory.ConstructLeftJoin(
 		mb.outScope.expr,
@@ -773,6 +795,8 @@ func (mb *mutationBuilder) setUpsertCols(insertCols tree.NameList) {
 func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 	mb.projectUpsertColumns()
 
+	mb.buildFKChecksAndCascades()
+
 	private := memo.MutationPrivate{
 		Table:       mb.tabID,
 		InsertCols:  mb.insertColList,
@@ -780,8 +804,12 @@ func (mb *mutationBuilder) buildUpsert(returning tree.ReturningExprs) {
 		UpdateCols:  mb.updateColList,
 		CanaryCol:   mb.canaryColID,
 		NeedResults: returning != nil,
+		WithID:      mb.withID,
+		FKFallback:  mb.fkFallback,
 	}
-	mb.outScope.expr = mb.b.factory.ConstructUpsert(mb.outScope.expr, &private)
+	mb.outScope.expr = mb.b.factory.ConstructUpsert(
+		mb.outScope.expr, mb.checks, mb.cascades, &private,
+	)
 
 	mb.buildReturning(returning)
 }
@@ -898,7 +926,14 @@ func (mb *mutationBuilder) projectUpsertColumns() {
 // correspond to the columns of at least one UNIQUE index on the target table.
 // If true, then ensureUniqueConflictCols returns the matching index. Otherwise,
 // it reports an error.
-func (mb *mutationBuilder) ensureUniqueConflictCols(cols tree.NameList) cat.Index {
+//
+// A unique partial index only matches if the given arbiter predicate, which is
+// the WHERE clause of the ON CONFLICT target, implies the predicate of the
+// index. Non-partial indexes are preferred over partial indexes.
+func (mb *mutationBuilder) ensureUniqueConflictCols(
+	cols tree.NameList, arbiterPredicate tree.Expr,
+) cat.Index {
+	var partialIndex, unmatchedPartialIndex cat.Index
 	for idx, idxCount := 0, mb.tab.IndexCount(); idx < idxCount; idx++ {
 		index := mb.tab.Index(idx)
 
@@ -919,13 +954,94 @@ func (mb *mutationBuilder) ensureUniqueConflictCols(cols tree.NameList) cat.Inde
 		}
 
 		if found {
+			if pred, isPartial := index.Predicate(); isPartial {
+				if partialIndex == nil && mb.arbiterPredicateImplies(arbiterPredicate, pred) {
+					partialIndex = index
+				} else {
+					unmatchedPartialIndex = index
+				}
+				continue
+			}
 			return index
 		}
 	}
+	if partialIndex != nil {
+		return partialIndex
+	}
+	if unmatchedPartialIndex != nil {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+			"unique partial index %q cannot be used as an ON CONFLICT arbiter unless the "+
+				"WHERE clause of the conflict target implies its predicate", unmatchedPartialIndex.Name())})
+	}
 	panic(builderError{errors.New(
 		"there is no unique or exclusion constraint matching the ON CONFLICT specification")})
 }
 
+// arbiterPredicateImplies returns true if the given arbiter predicate of an ON
+// CONFLICT target implies the given predicate of a partial index. Both are
+// built over the insert columns, so that identical expressions are interned
+// as the same scalar expression.
+func (mb *mutationBuilder) arbiterPredicateImplies(arbiterPredicate tree.Expr, pred string) bool {
+	if arbiterPredicate == nil {
+		return false
+	}
+	filters := memo.FiltersExpr{{Condition: mb.buildPredicateForCols(arbiterPredicate, mb.insertColList)}}
+	return memo.FiltersImplyPredicate(
+		mb.b.factory.Memo(), mb.b.evalCtx, filters, mb.buildPartialIndexPredicate(pred, mb.insertColList),
+	)
+}
+
+// addPartialIndexPredicateFilters appends to the given join filters the
+// predicate of the given index, once over the insert columns and once over the
+// given table columns, if the index is partial. The join then only matches
+// the insert rows and the table rows that both have entries in the index.
+// tabCols contains the IDs of the table columns, by ordinal.
+func (mb *mutationBuilder) addPartialIndexPredicateFilters(
+	on memo.FiltersExpr, index cat.Index, tabCols opt.ColList,
+) memo.FiltersExpr {
+	pred, isPartial := index.Predicate()
+	if !isPartial {
+		return on
+	}
+	return append(on,
+		memo.FiltersItem{Condition: mb.buildPartialIndexPredicate(pred, mb.insertColList)},
+		memo.FiltersItem{Condition: mb.buildPartialIndexPredicate(pred, tabCols)},
+	)
+}
+
+// buildPartialIndexPredicate parses the given predicate of a partial index and
+// builds it over the given columns. See buildPredicateForCols.
+func (mb *mutationBuilder) buildPartialIndexPredicate(
+	pred string, cols opt.ColList,
+) opt.ScalarExpr {
+	expr, err := parser.ParseExpr(pred)
+	if err != nil {
+		panic(builderError{err})
+	}
+	return mb.buildPredicateForCols(expr, cols)
+}
+
+// buildPredicateForCols builds the given boolean expression, which refers to
+// the columns of the target table by name, as a scalar expression over the
+// given columns. cols contains the ID of each table column, by ordinal. The
+// mutation columns can't be referenced.
+func (mb *mutationBuilder) buildPredicateForCols(pred tree.Expr, cols opt.ColList) opt.ScalarExpr {
+	predScope := mb.b.allocScope()
+	for i := range cols {
+		if cols[i] == 0 || cat.IsMutationColumn(mb.tab, i) {
+			continue
+		}
+		tabCol := mb.tab.Column(i)
+		predScope.cols = append(predScope.cols, scopeColumn{
+			name: tabCol.ColName(),
+			typ:  tabCol.DatumType(),
+			id:   cols[i],
+		})
+	}
+	texpr := predScope.resolveAndRequireType(pred, types.Bool)
+	return mb.b.buildScalar(texpr, predScope, nil, nil, nil)
+}
+
 // getPrimaryKeyColumnNames returns the names of all primary key columns in the
 // target table.
 func (mb *mutationBuilder) getPrimaryKeyColumnNames() tree.NameList {
@@ -33,7 +33,14 @@ import (
 // return values.
 func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope *scope) {
 	leftScope := b.buildDataSource(join.Left, nil /* indexFlags */, inScope)
-	rightScope := b.buildDataSource(join.Right, nil /* indexFlags */, inScope)
+
+	// A LATERAL data source on the right side can reference the columns of the
+	// left side.
+	rightInScope := inScope
+	if isLateral(join.Right) {
+		rightInScope = b.buildLateralScope(inScope, leftScope)
+	}
+	rightScope := b.buildDataSource(join.Right, nil /* indexFlags */, rightInScope)
 
 	// Check that the same table name is not used on both sides.
 	b.validateJoinTableNames(leftScope, rightScope)
@@ -139,9 +146,24 @@ func (b *Builder) findJoinColsToValidate(scope *scope) util.FastIntSet {
 	return ords
 }
 
+// constructJoin constructs a join of the given type. If the right side
+// references columns of the left side (which is only possible if it is a
+// LATERAL data source), the corresponding apply join is constructed instead.
 func (b *Builder) constructJoin(
 	joinType sqlbase.JoinType, left, right memo.RelExpr, on memo.FiltersExpr,
 ) memo.RelExpr {
+	if right.Relational().OuterCols.Intersects(left.Relational().OutputCols) {
+		switch joinType {
+		case sqlbase.InnerJoin:
+			return b.factory.ConstructInnerJoinApply(left, right, on)
+		case sqlbase.LeftOuterJoin:
+			return b.factory.ConstructLeftJoinApply(left, right, on)
+		default:
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+				"the combining JOIN type must be INNER or LEFT for a LATERAL reference")})
+		}
+	}
+
 	switch joinType {
 	case sqlbase.InnerJoin:
 		return b.factory.ConstructInnerJoin(left, right, on)
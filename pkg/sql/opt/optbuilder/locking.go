@@ -0,0 +1,104 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// lockingSpec contains the locking items (FOR UPDATE, FOR SHARE, etc) which
+// apply to the data sources of the query that is currently being built. The
+// locking clause of a query applies to the tables in its FROM clause, and
+// also to the tables inside any views and subqueries in the FROM clause.
+// However, it does not apply to subqueries in other clauses, or to the WITH
+// queries referenced by the query.
+type lockingSpec []*tree.LockingItem
+
+// isSet returns true if the spec contains any locking items.
+func (lm lockingSpec) isSet() bool {
+	return len(lm) != 0
+}
+
+// get returns the strongest locking strength which applies to all of the
+// tables in the current data source; that is, the strength of the items
+// which have no targets.
+func (lm lockingSpec) get() tree.LockingStrength {
+	var strength tree.LockingStrength
+	for _, item := range lm {
+		if len(item.Targets) == 0 {
+			strength = strength.Max(item.Strength)
+		}
+	}
+	return strength
+}
+
+// waitPolicy returns the wait policy which applies to all of the tables in
+// the current data source; that is, the strictest policy of the items which
+// have no targets.
+func (lm lockingSpec) waitPolicy() tree.LockingWaitPolicy {
+	var policy tree.LockingWaitPolicy
+	for _, item := range lm {
+		if len(item.Targets) == 0 {
+			policy = policy.Max(item.WaitPolicy)
+		}
+	}
+	return policy
+}
+
+// max returns the strongest locking strength of any of the items, which is
+// used in error messages.
+func (lm lockingSpec) max() tree.LockingStrength {
+	var strength tree.LockingStrength
+	for _, item := range lm {
+		strength = strength.Max(item.Strength)
+	}
+	return strength
+}
+
+// filter returns the locking items that apply to the data source with the
+// given name. Items which name the data source as one of their targets apply
+// to all of the tables inside the data source, so their targets are cleared.
+// Items without targets apply to the data source as is, and items which only
+// target other data sources are dropped.
+func (lm lockingSpec) filter(name tree.Name) lockingSpec {
+	var res lockingSpec
+	for _, item := range lm {
+		if len(item.Targets) == 0 {
+			res = append(res, item)
+			continue
+		}
+		for i := range item.Targets {
+			if item.Targets[i].TableName == name {
+				res = append(res, &tree.LockingItem{
+					Strength:   item.Strength,
+					WaitPolicy: item.WaitPolicy,
+				})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// checkLockingAllowed raises an error if a locking clause applies to the
+// query that is currently being built, since the query contains a construct
+// (given by clause) whose result rows do not correspond to table rows.
+func (b *Builder) checkLockingAllowed(clause string) {
+	if b.locking.isSet() {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"%s is not allowed with %s", b.locking.max(), clause)})
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -83,6 +84,24 @@ type mutationBuilder struct {
 	// an insert; otherwise it's an update.
 	canaryColID opt.ColumnID
 
+	// withID is the WithID under which the input of the mutation is buffered,
+	// so that the foreign key checks and cascades can refer to the mutated rows.
+	// It is zero if there are no such references. See buildWithScan.
+	withID opt.WithID
+
+	// checks contains the foreign key check queries for the mutation. See
+	// buildFKChecksAndCascades.
+	checks memo.FKChecksExpr
+
+	// cascades contains the foreign key cascade mutations that are triggered by
+	// the mutation. See buildFKChecksAndCascades.
+	cascades memo.FKCascadesExpr
+
+	// fkFallback is true if the foreign key checks and cascades of the mutation
+	// are left to the execution engine (see the comment for FKFallback in
+	// MutationPrivate).
+	fkFallback bool
+
 	// subqueries temporarily stores subqueries that were built during initial
 	// analysis of SET expressions. They will be used later when the subqueries
 	// are joined into larger LEFT OUTER JOIN expressions.
@@ -151,6 +170,7 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 	projectionsScope.appendColumnsFromScope(mb.outScope)
 	orderByScope := mb.b.analyzeOrderBy(orderBy, mb.outScope, projectionsScope)
 	mb.b.buildOrderBy(mb.outScope, projectionsScope, orderByScope)
+	mb.b.buildWindow(mb.outScope, mb.outScope)
 	mb.b.constructProjectForScope(mb.outScope, projectionsScope)
 
 	// LIMIT
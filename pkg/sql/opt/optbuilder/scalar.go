@@ -87,6 +87,12 @@ func (b *Builder) buildScalar(
 	case *aggregateInfo:
 		return b.finishBuildScalarRef(t.col, inScope.groupby.aggOutScope, outScope, outCol, colRefs)
 
+	case *windowInfo:
+		if inScope.groupby.inAgg {
+			panic(builderError{sqlbase.NewWindowInAggError()})
+		}
+		return b.finishBuildScalarRef(t.col, inScope, outScope, outCol, colRefs)
+
 	case *tree.AndExpr:
 		left := b.buildScalar(t.TypedLeft(), inScope, nil, nil, colRefs)
 		right := b.buildScalar(t.TypedRight(), inScope, nil, nil, colRefs)
@@ -427,10 +433,7 @@ func (b *Builder) buildFunction(
 	f *tree.FuncExpr, inScope, outScope *scope, outCol *scopeColumn, colRefs *opt.ColSet,
 ) (out opt.ScalarExpr) {
 	if f.WindowDef != nil {
-		if inScope.groupby.inAgg {
-			panic(builderError{sqlbase.NewWindowInAggError()})
-		}
-		panic(unimplementedf("window functions are not supported"))
+		panic("window function should have been replaced")
 	}
 
 	def, err := f.Func.Resolve(b.semaCtx.SearchPath)
@@ -442,6 +445,10 @@ func (b *Builder) buildFunction(
 		panic("aggregate function should have been replaced")
 	}
 
+	if def.Name == "grouping" {
+		return b.buildGroupingFunction(f, inScope, outScope, outCol, colRefs)
+	}
+
 	args := make(memo.ScalarListExpr, len(f.Exprs))
 	for i, pexpr := range f.Exprs {
 		args[i] = b.buildScalar(pexpr.(tree.TypedExpr), inScope, nil, nil, colRefs)
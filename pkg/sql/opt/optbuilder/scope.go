@@ -70,6 +70,20 @@ type scope struct {
 	// cross join between the input and a Zip of all the srfs in this slice.
 	srfs []*srf
 
+	// windows contains all the window functions that were replaced in this
+	// scope. It will be used by the Builder to compute the window functions on
+	// top of the input of the final projection (see Builder.buildWindow).
+	windows []*windowInfo
+
+	// windowDefs contains the named window definitions of the WINDOW clause of
+	// the SELECT clause that is being built with this scope.
+	windowDefs tree.Window
+
+	// inWindowFunc is true while the arguments and the window definition of a
+	// window function are being resolved. It is used to disallow nested window
+	// functions.
+	inWindowFunc bool
+
 	// ctes contains the CTEs which were created at this scope. This set
 	// is not exhaustive because expressions can reference CTEs from parent
 	// scopes.
@@ -78,6 +92,10 @@ type scope struct {
 	// context is the current context in the SQL query (e.g., "SELECT" or
 	// "HAVING"). It is used for error messages.
 	context string
+
+	// lateral is true if this scope contains the columns of the data sources
+	// which can be referenced by a LATERAL data source (see buildLateralScope).
+	lateral bool
 }
 
 // cteSource represents a CTE in the given query.
@@ -90,6 +108,10 @@ type cteSource struct {
 	// to only having a single reference to a given CTE, so if this is set then
 	// this CTE has already been referenced and may not be referenced again.
 	used bool
+
+	// withID is non-zero if this source is the working table of a recursive
+	// CTE, which is only visible inside the recursive term of that CTE.
+	withID opt.WithID
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -343,6 +365,12 @@ func (s *scope) isOuterColumn(id opt.ColumnID) bool {
 		}
 	}
 
+	for _, w := range s.windows {
+		if w.col.id == id {
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -460,7 +488,8 @@ func (s *scope) findAggregate(agg aggregateInfo) *scopeColumn {
 
 	for i, a := range s.groupby.aggs {
 		// Find an existing aggregate that uses the same function overload.
-		if a.def.Overload == agg.def.Overload && a.distinct == agg.distinct {
+		if a.def.Overload == agg.def.Overload && a.distinct == agg.distinct &&
+			a.filter == agg.filter {
 			// Now check that the arguments are identical.
 			if len(a.args) == len(agg.args) {
 				match := true
@@ -524,6 +553,12 @@ func (s *scope) endAggFunc(cols opt.ColSet) (aggInScope, aggOutScope *scope) {
 
 	for curr := s; curr != nil; curr = curr.parent {
 		if cols.Len() == 0 || cols.Intersects(curr.colSet()) {
+			if curr.lateral {
+				// The aggregate only references columns of the FROM clause which
+				// contains the LATERAL data source.
+				panic(builderError{pgerror.NewErrorf(pgerror.CodeGroupingError,
+					"aggregate functions are not allowed in FROM clause of their own query level")})
+			}
 			if curr.groupby.aggInScope == nil {
 				curr.groupby.aggInScope = curr.replace()
 			}
@@ -805,15 +840,16 @@ func (s *scope) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
 		return false, colI.(*scopeColumn)
 
 	case *tree.FuncExpr:
-		if t.WindowDef != nil {
-			panic(unimplementedf("window functions are not supported"))
-		}
-
 		def, err := t.Func.Resolve(s.builder.semaCtx.SearchPath)
 		if err != nil {
 			panic(builderError{err})
 		}
 
+		if t.WindowDef != nil {
+			expr = s.replaceWindowFn(t, def)
+			break
+		}
+
 		if isGenerator(def) && s.replaceSRFs {
 			expr = s.replaceSRF(t, def)
 			break
@@ -929,10 +965,6 @@ func (s *scope) replaceSRF(f *tree.FuncExpr, def *tree.FunctionDefinition) *srf
 // aggregate references no variables). The aggOutScope.groupby.aggs slice is
 // used later by the Builder to build aggregations in the aggregation scope.
 func (s *scope) replaceAggregate(f *tree.FuncExpr, def *tree.FunctionDefinition) tree.Expr {
-	if f.Filter != nil {
-		panic(unimplementedf("aggregates with FILTER are not supported yet"))
-	}
-
 	f, def = s.replaceCount(f, def)
 
 	// We need to save and restore the previous value of the field in
@@ -940,8 +972,19 @@ func (s *scope) replaceAggregate(f *tree.FuncExpr, def *tree.FunctionDefinition)
 	// context.
 	defer s.builder.semaCtx.Properties.Restore(s.builder.semaCtx.Properties)
 
-	s.builder.semaCtx.Properties.Require(s.context,
-		tree.RejectNestedAggregates|tree.RejectWindowApplications)
+	if f.Filter != nil {
+		// The FILTER clause is resolved before the arguments, since aggregates,
+		// window functions and subqueries are not allowed in it. The function
+		// call is copied so that the original FILTER expression is not modified.
+		s.builder.semaCtx.Properties.Require("FILTER", tree.RejectSpecial)
+		fCopy := *f
+		fCopy.Filter = s.resolveType(f.Filter, types.Bool)
+		f = &fCopy
+	}
+
+	// Window functions in the arguments are replaced while walking them, and
+	// are rejected when the arguments are built (see buildScalar).
+	s.builder.semaCtx.Properties.Require(s.context, tree.RejectNestedAggregates)
 
 	expr := f.Walk(s)
 	typedFunc, err := tree.TypeCheck(expr, s.builder.semaCtx, types.Any)
@@ -963,6 +1006,73 @@ func (s *scope) replaceAggregate(f *tree.FuncExpr, def *tree.FunctionDefinition)
 	return s.builder.buildAggregateFunction(f, &private, s)
 }
 
+// replaceWindowFn returns a windowInfo that can be used to replace a raw
+// window function call. When a windowInfo is encountered during the build
+// process, it is replaced with a reference to the column returned by the
+// window function.
+//
+// replaceWindowFn also stores the windowInfo in this scope's windows slice.
+// The slice is used later by the Builder to compute the window functions on
+// top of the input of the final projection. See Builder.buildWindow in
+// window.go for more details.
+func (s *scope) replaceWindowFn(f *tree.FuncExpr, def *tree.FunctionDefinition) tree.Expr {
+	if def.Class != tree.AggregateClass && def.Class != tree.WindowClass {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"OVER specified, but %s() is neither a window function nor an aggregate function",
+			&f.Func)})
+	}
+	if s.inWindowFunc {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeWindowingError,
+			"window function calls cannot be nested")})
+	}
+	if f.Filter != nil {
+		panic(unimplementedf("window functions with FILTER are not supported"))
+	}
+	if f.Type == tree.DistinctFuncType {
+		panic(unimplementedf("DISTINCT is not implemented for window functions"))
+	}
+
+	f, def = s.replaceCount(f, def)
+
+	// Resolve any reference to a named window. The function call is copied so
+	// that the original window definition is not modified.
+	windowDef := constructWindowDef(*f.WindowDef, s.windowDefs)
+	fCopy := *f
+	fCopy.WindowDef = &windowDef
+
+	s.inWindowFunc = true
+	expr := fCopy.Walk(s)
+	s.inWindowFunc = false
+
+	typedFunc, err := tree.TypeCheck(expr, s.builder.semaCtx, types.Any)
+	if err != nil {
+		panic(builderError{err})
+	}
+	if typedFunc == tree.DNull {
+		return tree.DNull
+	}
+
+	f = typedFunc.(*tree.FuncExpr)
+	typ := f.ResolvedType()
+	info := &windowInfo{
+		FuncExpr: f,
+		def: memo.FunctionPrivate{
+			Name:       def.Name,
+			Typ:        typ,
+			Properties: &def.FunctionProperties,
+			Overload:   f.ResolvedOverload(),
+		},
+	}
+	info.col = &scopeColumn{
+		name: tree.Name(def.Name),
+		typ:  typ,
+		id:   s.builder.factory.Metadata().AddColumn(def.Name, typ),
+		expr: info,
+	}
+	s.windows = append(s.windows, info)
+	return info
+}
+
 // replaceCount replaces count(*) with count_rows().
 func (s *scope) replaceCount(
 	f *tree.FuncExpr, def *tree.FunctionDefinition,
@@ -1053,6 +1163,10 @@ func (s *scope) replaceSubquery(
 	defer func() { s.builder.subquery = outer }()
 	s.builder.subquery = &subq
 
+	// The locking clause of the enclosing query does not apply to the subquery.
+	defer func(locking lockingSpec) { s.builder.locking = locking }(s.builder.locking)
+	s.builder.locking = nil
+
 	outScope := s.builder.buildStmt(sub.Select, s)
 	ord := outScope.ordering
 
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/pkg/errors"
 )
@@ -53,6 +54,19 @@ func (b *Builder) buildDataSource(
 			indexFlags = source.IndexFlags
 		}
 
+		if b.locking.isSet() {
+			// Only keep the locking items which apply to this data source, which
+			// is identified by its alias (or by its name if there is no alias).
+			name := source.As.Alias
+			if tn, ok := source.Expr.(*tree.TableName); ok && name == "" {
+				name = tn.TableName
+			}
+			if name != "" {
+				defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+				b.locking = b.locking.filter(name)
+			}
+		}
+
 		outScope = b.buildDataSource(source.Expr, indexFlags, inScope)
 
 		if source.Ordinality {
@@ -72,6 +86,9 @@ func (b *Builder) buildDataSource(
 
 		// CTEs take precedence over other data sources.
 		if cte := inScope.resolveCTE(tn); cte != nil {
+			if cte.withID != 0 {
+				return b.buildWorkingTableScan(cte, inScope)
+			}
 			if cte.used {
 				panic(builderError{fmt.Errorf("unsupported multiple use of CTE clause %q", tn)})
 			}
@@ -114,6 +131,10 @@ func (b *Builder) buildDataSource(
 		return outScope
 
 	case *tree.StatementSource:
+		// The locking clause of the enclosing query does not apply to the
+		// statement.
+		defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+		b.locking = nil
 		outScope = b.buildStmt(source.Statement, inScope)
 		if len(outScope.cols) == 0 {
 			panic(builderError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
@@ -353,7 +374,12 @@ func (b *Builder) buildScan(
 		private := memo.VirtualScanPrivate{Table: tabID, Cols: tabColIDs}
 		outScope.expr = b.factory.ConstructVirtualScan(&private)
 	} else {
-		private := memo.ScanPrivate{Table: tabID, Cols: tabColIDs}
+		private := memo.ScanPrivate{
+			Table:             tabID,
+			Cols:              tabColIDs,
+			Locking:           b.locking.get(),
+			LockingWaitPolicy: b.locking.waitPolicy(),
+		}
 
 		if indexFlags != nil {
 			private.Flags.NoIndexJoin = indexFlags.NoIndexJoin
@@ -375,17 +401,47 @@ func (b *Builder) buildScan(
 					}
 					panic(builderError{err})
 				}
+				if _, isPartial := tab.Index(idx).Predicate(); isPartial {
+					panic(builderError{errors.Errorf(
+						"index %q is a partial index and cannot be forced", tab.Index(idx).Name())})
+				}
 				private.Flags.ForceIndex = true
 				private.Flags.Index = idx
 				private.Flags.Direction = indexFlags.Direction
 			}
 		}
 
+		// The predicates of partial indexes can only be built when all the
+		// columns of the table are in scope.
+		if ordinals == nil {
+			b.addPartialIndexPredicates(tab, tabID, outScope)
+		}
+
 		outScope.expr = b.factory.ConstructScan(&private)
 	}
 	return outScope
 }
 
+// addPartialIndexPredicates builds the predicates of the partial indexes of
+// the given table as scalar expressions over the columns of tabScope, and adds
+// them to the table metadata. The optimizer only uses a partial index to scan
+// rows when the filters of the query imply its predicate.
+func (b *Builder) addPartialIndexPredicates(tab cat.Table, tabID opt.TableID, tabScope *scope) {
+	tabMeta := b.factory.Metadata().TableMeta(tabID)
+	for i, n := 0, tab.IndexCount(); i < n; i++ {
+		predicate, isPartial := tab.Index(i).Predicate()
+		if !isPartial {
+			continue
+		}
+		expr, err := parser.ParseExpr(predicate)
+		if err != nil {
+			panic(builderError{err})
+		}
+		texpr := tabScope.resolveAndRequireType(expr, types.Bool)
+		tabMeta.AddPartialIndexPredicate(i, b.buildScalar(texpr, tabScope, nil, nil, nil))
+	}
+}
+
 // buildWithOrdinality builds a group which appends an increasing integer column to
 // the output. colName optionally denotes the name this column is given, or can
 // be blank for none.
@@ -407,12 +463,18 @@ func (b *Builder) buildWithOrdinality(colName string, inScope *scope) (outScope
 	return inScope
 }
 
-func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
+func (b *Builder) buildCTE(with *tree.With, inScope *scope) (outScope *scope) {
 	outScope = inScope.push()
 
+	ctes := with.CTEList
 	outScope.ctes = make(map[string]*cteSource)
 	for i := range ctes {
-		cteScope := b.buildStmt(ctes[i].Stmt, outScope)
+		var cteScope *scope
+		if with.Recursive {
+			cteScope = b.buildRecursiveCTE(ctes[i], outScope)
+		} else {
+			cteScope = b.buildStmt(ctes[i].Stmt, outScope)
+		}
 		cols := cteScope.cols
 		name := ctes[i].Name.Alias
 
@@ -457,6 +519,119 @@ func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
 	return outScope
 }
 
+// buildRecursiveCTE builds a common table expression of a WITH RECURSIVE
+// clause. Such a CTE has the form:
+//
+//   <initial> UNION [ALL] <recursive>
+//
+// where the recursive term can refer to the CTE itself. That reference is
+// built as a WorkingTableScan, which at execution time returns the rows
+// produced by the previous iteration. A CTE which does not have this form, or
+// which doesn't refer to itself, is built as a regular CTE.
+func (b *Builder) buildRecursiveCTE(cte *tree.CTE, inScope *scope) (outScope *scope) {
+	initial, recursive, all, ok := cte.RecursiveTerms()
+	if !ok {
+		return b.buildStmt(cte.Stmt, inScope)
+	}
+
+	initialScope := b.buildSelect(initial, nil /* desiredTypes */, inScope)
+	initialScope.removeHiddenCols()
+
+	name := cte.Name.Alias
+	if cte.Name.Cols != nil && len(initialScope.cols) != len(cte.Name.Cols) {
+		panic(builderError{
+			fmt.Errorf(
+				"source %q has %d columns available but %d columns specified",
+				name, len(initialScope.cols), len(cte.Name.Cols),
+			),
+		})
+	}
+
+	// The columns of the working table have the names of the CTE columns and
+	// the types of the initial term.
+	tableName := tree.MakeUnqualifiedTableName(name)
+	cols := make([]scopeColumn, len(initialScope.cols))
+	copy(cols, initialScope.cols)
+	desiredTypes := make([]types.T, len(cols))
+	for i := range cols {
+		if cte.Name.Cols != nil {
+			cols[i].name = cte.Name.Cols[i]
+		}
+		cols[i].table = tableName
+		desiredTypes[i] = cols[i].typ
+	}
+
+	b.lastWithID++
+	workingTable := &cteSource{name: cte.Name, cols: cols, withID: b.lastWithID}
+	recursiveInScope := inScope.push()
+	recursiveInScope.ctes = map[string]*cteSource{name.String(): workingTable}
+	recursiveScope := b.buildSelect(recursive, desiredTypes, recursiveInScope)
+	recursiveScope.removeHiddenCols()
+
+	if !workingTable.used {
+		// The CTE does not refer to itself; there is nothing recursive about it,
+		// so it is a regular UNION of the terms. The terms are not built again,
+		// since they may refer to other CTEs, which can only be used once.
+		return b.buildSetOp(tree.UnionOp, all, inScope, initialScope, recursiveScope)
+	}
+
+	if len(recursiveScope.cols) != len(cols) {
+		panic(builderError{pgerror.NewErrorf(
+			pgerror.CodeSyntaxError,
+			"each UNION query must have the same number of columns: %d vs %d",
+			len(cols), len(recursiveScope.cols),
+		)})
+	}
+
+	outScope = inScope.push()
+	for i := range cols {
+		l := &cols[i]
+		r := &recursiveScope.cols[i]
+		if !(l.typ.Equivalent(r.typ) || r.typ == types.Unknown) {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+				name, i+1, l.typ, r.typ)})
+		}
+		col := b.synthesizeColumn(outScope, string(l.name), l.typ, nil, nil /* scalar */)
+		col.table = tableName
+	}
+
+	private := memo.RecursiveCTEPrivate{
+		Name:          string(name),
+		WithID:        workingTable.withID,
+		InitialCols:   colsToColList(initialScope.cols),
+		RecursiveCols: colsToColList(recursiveScope.cols),
+		OutCols:       colsToColList(outScope.cols),
+		Deduplicate:   !all,
+	}
+	outScope.expr = b.factory.ConstructRecursiveCTE(
+		initialScope.expr.(memo.RelExpr), recursiveScope.expr.(memo.RelExpr), &private,
+	)
+	return outScope
+}
+
+// buildWorkingTableScan builds a reference to the working table of a recursive
+// CTE from inside its recursive term. See buildRecursiveCTE.
+func (b *Builder) buildWorkingTableScan(cte *cteSource, inScope *scope) (outScope *scope) {
+	if cte.used {
+		panic(builderError{pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+			"recursive reference to query %q must not appear more than once", tree.ErrString(&cte.name.Alias))})
+	}
+	cte.used = true
+
+	outScope = inScope.push()
+	for i := range cte.cols {
+		col := b.synthesizeColumn(outScope, string(cte.cols[i].name), cte.cols[i].typ, nil, nil /* scalar */)
+		col.table = cte.cols[i].table
+	}
+	outScope.expr = b.factory.ConstructWorkingTableScan(&memo.WorkingTableScanPrivate{
+		Name:   string(cte.name.Alias),
+		WithID: cte.withID,
+		Cols:   colsToColList(outScope.cols),
+	})
+	return outScope
+}
+
 // checkCTEUsage ensures that a CTE that contains a mutation (like INSERT) is
 // used at least once by the query. Otherwise, it might not be executed.
 func (b *Builder) checkCTEUsage(inScope *scope) {
@@ -507,9 +682,11 @@ func (b *Builder) buildSelect(
 	orderBy := stmt.OrderBy
 	limit := stmt.Limit
 	with := stmt.With
+	locking := stmt.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		stmt = s.Select
+		locking = append(locking[:len(locking):len(locking)], stmt.Locking...)
 		if stmt.With != nil {
 			if with != nil {
 				// (WITH ... (WITH ...))
@@ -539,10 +716,16 @@ func (b *Builder) buildSelect(
 		}
 	}
 
+	// The locking clause of an enclosing query does not apply to the WITH
+	// queries, and neither does the locking clause of this query.
+	outerLocking := b.locking
+	defer func() { b.locking = outerLocking }()
 	if with != nil {
-		inScope = b.buildCTE(with.CTEList, inScope)
+		b.locking = nil
+		inScope = b.buildCTE(with, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
+	b.locking = append(outerLocking[:len(outerLocking):len(outerLocking)], locking...)
 
 	// NB: The case statements are sorted lexicographically.
 	switch t := stmt.Select.(type) {
@@ -550,9 +733,11 @@ func (b *Builder) buildSelect(
 		outScope = b.buildSelectClause(t, orderBy, desiredTypes, inScope)
 
 	case *tree.UnionClause:
+		b.checkLockingAllowed("UNION/INTERSECT/EXCEPT")
 		outScope = b.buildUnion(t, desiredTypes, inScope)
 
 	case *tree.ValuesClause:
+		b.checkLockingAllowed("VALUES")
 		outScope = b.buildValuesClause(t, desiredTypes, inScope)
 
 	default:
@@ -569,6 +754,7 @@ func (b *Builder) buildSelect(
 		}
 		orderByScope := b.analyzeOrderBy(orderBy, outScope, projectionsScope)
 		b.buildOrderBy(outScope, projectionsScope, orderByScope)
+		b.buildWindow(outScope, outScope)
 		b.constructProjectForScope(outScope, projectionsScope)
 		outScope = projectionsScope
 	}
@@ -592,11 +778,25 @@ func (b *Builder) buildSelect(
 func (b *Builder) buildSelectClause(
 	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []types.T, inScope *scope,
 ) (outScope *scope) {
+	if sel.Distinct {
+		b.checkLockingAllowed("DISTINCT clause")
+	}
+	if len(sel.GroupBy) > 0 {
+		b.checkLockingAllowed("GROUP BY clause")
+	}
+	if sel.Having != nil {
+		b.checkLockingAllowed("HAVING clause")
+	}
+
 	fromScope := b.buildFrom(sel.From, inScope)
 	b.buildWhere(sel.Where, fromScope)
 
 	projectionsScope := fromScope.replace()
 
+	// The named windows of the WINDOW clause are visible to the window functions
+	// in the SELECT list and the ORDER BY clause.
+	b.analyzeWindowDefs(sel.Window, fromScope)
+
 	// This is where the magic happens. When this call reaches an aggregate
 	// function that refers to variables in fromScope or an ancestor scope,
 	// buildAggregateFunction is called which adds columns to the appropriate
@@ -610,6 +810,7 @@ func (b *Builder) buildSelectClause(
 	distinctOnScope := b.analyzeDistinctOnArgs(sel.DistinctOn, fromScope, projectionsScope)
 
 	if b.needsAggregation(sel, fromScope) {
+		b.checkLockingAllowed("aggregate functions")
 		outScope = b.buildAggregation(
 			sel, havingExpr, fromScope, projectionsScope, orderByScope, distinctOnScope,
 		)
@@ -623,6 +824,10 @@ func (b *Builder) buildSelectClause(
 		outScope = fromScope
 	}
 
+	// Any window functions are computed after the aggregation (if any), and
+	// before the projection.
+	b.buildWindow(outScope, fromScope)
+
 	// Construct the projection.
 	b.constructProjectForScope(outScope, projectionsScope)
 	outScope = projectionsScope
@@ -651,7 +856,7 @@ func (b *Builder) buildFrom(from *tree.From, inScope *scope) (outScope *scope) {
 	}
 
 	if len(from.Tables) > 0 {
-		outScope = b.buildFromTables(from.Tables, inScope)
+		outScope = b.buildFromTables(from.Tables, nil /* lateralScope */, inScope)
 	} else {
 		outScope = inScope.push()
 		outScope.expr = b.factory.ConstructValues(memo.ScalarListWithEmptyTuple, opt.ColList{})
@@ -699,17 +904,50 @@ func (b *Builder) buildWhere(where *tree.Where, inScope *scope) {
 //
 //   SELECT * FROM a JOIN (b JOIN c ON true) ON true
 //
+// LATERAL tables can reference the columns of the tables which precede them
+// in the list. lateralScope contains those columns (it is nil if there are no
+// preceding tables), and is used instead of inScope to build LATERAL tables.
+// For example:
+//
+//   SELECT * FROM a, b, LATERAL (SELECT * FROM c WHERE c.x = a.x AND c.y = b.y)
+//
+// is joined like:
+//
+//   SELECT * FROM a JOIN LATERAL (b JOIN LATERAL (SELECT ...) ON true) ON true
+//
+// where both joins are correlated. Any correlation which cannot be removed by
+// the decorrelation rules is executed using apply joins.
+//
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
-func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outScope *scope) {
-	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, inScope)
+func (b *Builder) buildFromTables(
+	tables tree.TableExprs, lateralScope, inScope *scope,
+) (outScope *scope) {
+	tableInScope := inScope
+	if lateralScope != nil && isLateral(tables[0]) {
+		tableInScope = lateralScope
+	}
+	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, tableInScope)
 
 	// Recursively build table join.
 	tables = tables[1:]
 	if len(tables) == 0 {
 		return outScope
 	}
-	tableScope := b.buildFromTables(tables, inScope)
+
+	// Only construct a new lateral scope if one of the remaining tables can
+	// make use of it.
+	var tablesLateralScope *scope
+	for i := range tables {
+		if isLateral(tables[i]) {
+			if lateralScope == nil {
+				lateralScope = inScope
+			}
+			tablesLateralScope = b.buildLateralScope(lateralScope, outScope)
+			break
+		}
+	}
+	tableScope := b.buildFromTables(tables, tablesLateralScope, inScope)
 
 	// Check that the same table name is not used multiple times.
 	b.validateJoinTableNames(outScope, tableScope)
@@ -718,14 +956,46 @@ func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outSc
 
 	left := outScope.expr.(memo.RelExpr)
 	right := tableScope.expr.(memo.RelExpr)
-	outScope.expr = b.factory.ConstructInnerJoin(left, right, memo.TrueFilter)
+	outScope.expr = b.constructJoin(sqlbase.InnerJoin, left, right, memo.TrueFilter)
 	return outScope
 }
 
+// isLateral returns true if the given table expression is or contains a
+// LATERAL data source, which can reference the columns of the data sources
+// which precede it in the FROM clause. Set-returning functions are always
+// treated as LATERAL, as in Postgres.
+func isLateral(texpr tree.TableExpr) bool {
+	switch source := texpr.(type) {
+	case *tree.AliasedTableExpr:
+		return source.Lateral || isLateral(source.Expr)
+
+	case *tree.JoinTableExpr:
+		return isLateral(source.Left) || isLateral(source.Right)
+
+	case *tree.ParenTableExpr:
+		return isLateral(source.Expr)
+
+	case *tree.RowsFromExpr:
+		return true
+	}
+	return false
+}
+
+// buildLateralScope returns a new scope which contains the columns of
+// leftScope, and which is used to build a LATERAL data source that is joined
+// to the data source of leftScope. The columns of inScope remain visible as
+// outer columns.
+func (b *Builder) buildLateralScope(inScope, leftScope *scope) *scope {
+	lateralScope := inScope.push()
+	lateralScope.appendColumnsFromScope(leftScope)
+	lateralScope.lateral = true
+	return lateralScope
+}
+
 // validateAsOf ensures that any AS OF SYSTEM TIME timestamp is consistent with
 // that of the root statement.
 func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
-	ts, err := tree.EvalAsOfTimestamp(asOf, hlc.MaxTimestamp, b.semaCtx, b.evalCtx)
+	asOfTS, err := tree.EvalAsOf(asOf, hlc.MaxTimestamp, b.semaCtx, b.evalCtx)
 	if err != nil {
 		panic(builderError{err})
 	}
@@ -734,7 +1004,13 @@ func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
 		panic(builderError{errors.Errorf("AS OF SYSTEM TIME must be provided on a top-level statement")})
 	}
 
-	if *b.semaCtx.AsOfTimestamp != ts {
+	// Bounded staleness reads are planned at a negotiated timestamp; the
+	// clauses must agree on the staleness bound instead.
+	expected := *b.semaCtx.AsOfTimestamp
+	if asOfTS.BoundedStaleness {
+		expected = b.semaCtx.AsOfMinTimestamp
+	}
+	if asOfTS.Timestamp != expected {
 		panic(builderError{errors.Errorf("cannot specify AS OF SYSTEM TIME with different timestamps")})
 	}
 }
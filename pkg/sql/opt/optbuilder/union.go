@@ -34,7 +34,17 @@ func (b *Builder) buildUnion(
 ) (outScope *scope) {
 	leftScope := b.buildSelect(clause.Left, desiredTypes, inScope)
 	rightScope := b.buildSelect(clause.Right, desiredTypes, inScope)
+	return b.buildSetOp(clause.Type, clause.All, inScope, leftScope, rightScope)
+}
 
+// buildSetOp builds a set operation (UNION, INTERSECT or EXCEPT) of the given
+// type, whose left and right sides have already been built.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildSetOp(
+	typ tree.UnionType, all bool, inScope, leftScope, rightScope *scope,
+) (outScope *scope) {
 	// Remove any hidden columns, as they are not included in the Union.
 	leftScope.removeHiddenCols()
 	rightScope.removeHiddenCols()
@@ -44,7 +54,7 @@ func (b *Builder) buildUnion(
 		panic(builderError{pgerror.NewErrorf(
 			pgerror.CodeSyntaxError,
 			"each %v query must have the same number of columns: %d vs %d",
-			clause.Type, len(leftScope.cols), len(rightScope.cols),
+			typ, len(leftScope.cols), len(rightScope.cols),
 		)})
 	}
 
@@ -65,7 +75,7 @@ func (b *Builder) buildUnion(
 	//   SELECT NULL UNION SELECT 1
 	// The type of NULL is unknown, and the type of 1 is int. We need to
 	// synthesize a new column so the output column will have the correct type.
-	newColsNeeded := clause.Type == tree.UnionOp
+	newColsNeeded := typ == tree.UnionOp
 	if newColsNeeded {
 		// Create a new scope to hold the new synthesized columns.
 		outScope = outScope.push()
@@ -81,11 +91,11 @@ func (b *Builder) buildUnion(
 		// http://www.postgresql.org/docs/9.5/static/typeconv-union-case.html.
 		if !(l.typ.Equivalent(r.typ) || l.typ == types.Unknown || r.typ == types.Unknown) {
 			panic(builderError{pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
-				"%v types %s and %s cannot be matched", clause.Type, l.typ, r.typ)})
+				"%v types %s and %s cannot be matched", typ, l.typ, r.typ)})
 		}
 		if l.hidden != r.hidden {
 			// This should never happen.
-			panic(fmt.Errorf("%v types cannot be matched", clause.Type))
+			panic(fmt.Errorf("%v types cannot be matched", typ))
 		}
 
 		if newColsNeeded {
@@ -115,8 +125,8 @@ func (b *Builder) buildUnion(
 	right := rightScope.expr.(memo.RelExpr)
 	private := memo.SetPrivate{LeftCols: leftCols, RightCols: rightCols, OutCols: newCols}
 
-	if clause.All {
-		switch clause.Type {
+	if all {
+		switch typ {
 		case tree.UnionOp:
 			outScope.expr = b.factory.ConstructUnionAll(left, right, &private)
 		case tree.IntersectOp:
@@ -125,7 +135,7 @@ func (b *Builder) buildUnion(
 			outScope.expr = b.factory.ConstructExceptAll(left, right, &private)
 		}
 	} else {
-		switch clause.Type {
+		switch typ {
 		case tree.UnionOp:
 			outScope.expr = b.factory.ConstructUnion(left, right, &private)
 		case tree.IntersectOp:
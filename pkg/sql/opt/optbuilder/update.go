@@ -81,7 +81,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	}
 
 	if upd.With != nil {
-		inScope = b.buildCTE(upd.With.CTEList, inScope)
+		inScope = b.buildCTE(upd.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -91,7 +91,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(upd.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab := b.resolveTable(tn, privilege.UPDATE)
+	tab := b.resolveTableForMutation(tn, privilege.UPDATE)
 
 	// Check Select permission as well, since existing values must be read.
 	b.checkPrivilege(tab, privilege.SELECT)
@@ -331,13 +331,19 @@ func (mb *mutationBuilder) addComputedColsForUpdate() {
 // buildUpdate constructs an Update operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
+	mb.buildFKChecksAndCascades()
+
 	private := memo.MutationPrivate{
 		Table:       mb.tabID,
 		FetchCols:   mb.fetchColList,
 		UpdateCols:  mb.updateColList,
 		NeedResults: returning != nil,
+		WithID:      mb.withID,
+		FKFallback:  mb.fkFallback,
 	}
-	mb.outScope.expr = mb.b.factory.ConstructUpdate(mb.outScope.expr, &private)
+	mb.outScope.expr = mb.b.factory.ConstructUpdate(
+		mb.outScope.expr, mb.checks, mb.cascades, &private,
+	)
 
 	mb.buildReturning(returning)
 }
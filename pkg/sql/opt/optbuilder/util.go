@@ -431,6 +431,17 @@ func (b *Builder) resolveTable(tn *tree.TableName, priv privilege.Kind) cat.Tabl
 	return tab
 }
 
+// resolveTableForMutation is like resolveTable, but also raises an error if
+// the table is a materialized view, whose contents can't be modified by
+// mutation statements.
+func (b *Builder) resolveTableForMutation(tn *tree.TableName, priv privilege.Kind) cat.Table {
+	tab := b.resolveTable(tn, priv)
+	if tab.IsMaterializedView() {
+		panic(builderError{sqlbase.NewWrongObjectTypeError(tn, "table")})
+	}
+	return tab
+}
+
 // resolveDataSource returns the data source in the catalog with the given name.
 // If the name does not resolve to a table, or if the current user does not have
 // the given privilege, then resolveDataSource raises an error.
@@ -0,0 +1,279 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+// This file has builder code specific to window functions.
+//
+// We build the window functions of a SELECT clause on top of the input of the
+// final projection, which is either the FROM clause or the aggregation (if
+// there is one), using two kinds of operators:
+//
+//  - a pre-projection: a ProjectOp which passes through all the input columns
+//    and generates the columns for the arguments of the window functions and
+//    for the PARTITION BY and ORDER BY expressions of their windows.
+//
+//  - one or more WindowOps: each one computes all the window functions that
+//    share the same partitioning and ordering, and passes through all its
+//    input columns.
+//
+// For example:
+//   SELECT rank() OVER (ORDER BY k+1), sum(v*2) OVER (PARTITION BY w) FROM kvw
+//
+//   pre-projection:  k+1 (as col1), v*2 (as col2)
+//   window:          ordered by col1, calculate rank() (as col3)
+//   window:          partitioned by w, calculate sum(col2) (as col4)
+//   post-projection: col3, col4
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// windowInfo stores information about a window function call.
+type windowInfo struct {
+	// FuncExpr is the type checked window function call. Its WindowDef has been
+	// resolved against the named windows of the SELECT clause.
+	*tree.FuncExpr
+
+	def memo.FunctionPrivate
+
+	// col is the output column of the window function.
+	col *scopeColumn
+}
+
+// Walk is part of the tree.Expr interface.
+func (w *windowInfo) Walk(v tree.Visitor) tree.Expr {
+	return w
+}
+
+// TypeCheck is part of the tree.Expr interface.
+func (w *windowInfo) TypeCheck(ctx *tree.SemaContext, desired types.T) (tree.TypedExpr, error) {
+	if _, err := w.FuncExpr.TypeCheck(ctx, desired); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Eval is part of the tree.TypedExpr interface.
+func (w *windowInfo) Eval(_ *tree.EvalContext) (tree.Datum, error) {
+	panic("windowInfo must be replaced before evaluation")
+}
+
+var _ tree.Expr = &windowInfo{}
+var _ tree.TypedExpr = &windowInfo{}
+
+// analyzeWindowDefs makes the named window definitions of the given WINDOW
+// clause visible to the window functions in inScope. It raises an error if a
+// name is defined more than once.
+func (b *Builder) analyzeWindowDefs(window tree.Window, inScope *scope) {
+	for i, def := range window {
+		for _, prev := range window[:i] {
+			if def.Name == prev.Name {
+				panic(builderError{pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"window %q is already defined", string(def.Name))})
+			}
+		}
+	}
+	inScope.windowDefs = window
+}
+
+// constructWindowDef returns the window definition of a window function call,
+// which may reference one of the named window definitions in windowDefs. The
+// returned definition does not share any state with the given ones, so it can
+// be type checked in place.
+func constructWindowDef(def tree.WindowDef, windowDefs tree.Window) tree.WindowDef {
+	modifyRef := false
+	var refName tree.Name
+	switch {
+	case def.RefName != "":
+		// SELECT rank() OVER (w) FROM t WINDOW w AS (...)
+		// We copy the referenced window definition, and modify it if necessary.
+		refName = def.RefName
+		modifyRef = true
+	case def.Name != "":
+		// SELECT rank() OVER w FROM t WINDOW w AS (...)
+		// We use the referenced window definition directly, without modification.
+		refName = def.Name
+	}
+
+	if refName != "" {
+		var referenced *tree.WindowDef
+		for _, d := range windowDefs {
+			if d.Name == refName {
+				referenced = d
+				break
+			}
+		}
+		if referenced == nil {
+			panic(builderError{pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"window %q does not exist", string(refName))})
+		}
+
+		if !modifyRef {
+			def = *referenced
+		} else {
+			// The PARTITION BY clause of the referenced window is always used.
+			if len(def.Partitions) > 0 {
+				panic(builderError{pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"cannot override PARTITION BY clause of window %q", string(refName))})
+			}
+			def.Partitions = referenced.Partitions
+
+			// The ORDER BY clause of the referenced window is used if set.
+			if len(referenced.OrderBy) > 0 {
+				if len(def.OrderBy) > 0 {
+					panic(builderError{pgerror.NewErrorf(pgerror.CodeWindowingError,
+						"cannot override ORDER BY clause of window %q", string(refName))})
+				}
+				def.OrderBy = referenced.OrderBy
+			}
+
+			if referenced.Frame != nil {
+				panic(builderError{pgerror.NewErrorf(pgerror.CodeWindowingError,
+					"cannot copy window %q because it has a frame clause", string(refName))})
+			}
+		}
+	}
+
+	// Type checking replaces the PARTITION BY and ORDER BY expressions in
+	// place, so copy them.
+	res := tree.WindowDef{
+		Partitions: append(tree.Exprs(nil), def.Partitions...),
+		OrderBy:    make(tree.OrderBy, len(def.OrderBy)),
+		Frame:      def.Frame,
+	}
+	for i := range def.OrderBy {
+		order := *def.OrderBy[i]
+		res.OrderBy[i] = &order
+	}
+	return res
+}
+
+// buildWindow builds the window functions that were replaced in inScope (see
+// scope.replaceWindowFn) on top of outScope.expr, which is the input of the
+// projection that references their output columns. For a SELECT clause, this
+// is either the FROM clause or the aggregation (if there is one).
+func (b *Builder) buildWindow(outScope, inScope *scope) {
+	if len(inScope.windows) == 0 {
+		return
+	}
+
+	// argScope contains the columns of outScope, followed by the columns for
+	// the arguments, PARTITION BY and ORDER BY expressions of the window
+	// functions.
+	argScope := outScope.push()
+	argScope.appendColumnsFromScope(outScope)
+
+	// buildArg builds the given expression as a column of argScope, and returns
+	// its ID. A column of the input is passed through without a new column.
+	buildArg := func(texpr tree.TypedExpr) opt.ColumnID {
+		col := b.addColumn(argScope, "" /* alias */, texpr)
+		b.buildScalar(texpr, inScope, argScope, col, nil)
+		return col.id
+	}
+
+	windows := make([]windowGroup, 0, len(inScope.windows))
+	for _, w := range inScope.windows {
+		item := memo.WindowsItem{
+			WindowsItemPrivate: memo.WindowsItemPrivate{
+				Frame: b.buildWindowFrame(w.WindowDef.Frame),
+				Col:   w.col.id,
+			},
+		}
+
+		args := make(memo.ScalarListExpr, len(w.Exprs))
+		for i, pexpr := range w.Exprs {
+			args[i] = b.factory.ConstructVariable(buildArg(pexpr.(tree.TypedExpr)))
+		}
+		item.Function = b.factory.ConstructWindowFunction(args, &w.def)
+
+		var private memo.WindowPrivate
+		for _, e := range w.WindowDef.Partitions {
+			private.Partition.Add(int(buildArg(e.(tree.TypedExpr))))
+		}
+		var ordering opt.Ordering
+		var orderingCols opt.ColSet
+		for _, o := range w.WindowDef.OrderBy {
+			id := buildArg(o.Expr.(tree.TypedExpr))
+			// Ordering by a column that was already ordered on, or that is
+			// constant within each partition, has no effect.
+			if orderingCols.Contains(int(id)) || private.Partition.Contains(int(id)) {
+				continue
+			}
+			orderingCols.Add(int(id))
+			ordering = append(ordering, opt.MakeOrderingColumn(id, o.Direction == tree.Descending))
+		}
+		private.Ordering.FromOrderingWithOptCols(ordering, private.Partition)
+
+		// Window functions with the same partitioning and ordering are computed
+		// by the same WindowOp.
+		found := false
+		for i := range windows {
+			if windows[i].private.Partition.Equals(private.Partition) &&
+				windows[i].ordering.Equals(ordering) {
+				windows[i].items = append(windows[i].items, item)
+				found = true
+				break
+			}
+		}
+		if !found {
+			windows = append(windows, windowGroup{
+				private:  private,
+				ordering: ordering,
+				items:    memo.WindowsExpr{item},
+			})
+		}
+	}
+
+	// Construct the pre-projection, followed by the window operators.
+	b.constructProjectForScope(outScope, argScope)
+	input := argScope.expr.(memo.RelExpr)
+	for i := range windows {
+		input = b.factory.ConstructWindow(input, windows[i].items, &windows[i].private)
+	}
+	outScope.expr = input
+}
+
+// windowGroup is a set of window functions which share the same partitioning
+// and ordering, and which are computed by the same WindowOp.
+type windowGroup struct {
+	private  memo.WindowPrivate
+	ordering opt.Ordering
+	items    memo.WindowsExpr
+}
+
+// buildWindowFrame returns the memo.WindowFrame for the given frame clause.
+// The frame is nil if the window definition has no frame clause, in which case
+// the default frame is used.
+func (b *Builder) buildWindowFrame(frame *tree.WindowFrame) memo.WindowFrame {
+	if frame == nil {
+		return memo.DefaultWindowFrame
+	}
+	if frame.Bounds.HasOffset() {
+		panic(unimplementedf("window frames with offsets are not supported"))
+	}
+	res := memo.WindowFrame{
+		Mode:           frame.Mode,
+		StartBoundType: frame.Bounds.StartBound.BoundType,
+		EndBoundType:   tree.CurrentRow,
+	}
+	if frame.Bounds.EndBound != nil {
+		res.EndBoundType = frame.Bounds.EndBound.BoundType
+	}
+	return res
+}
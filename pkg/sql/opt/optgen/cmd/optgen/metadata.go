@@ -143,37 +143,43 @@ func newMetadata(compiled *lang.CompiledExpr, pkg string) *metadata {
 
 	// Add all types used in Optgen defines here.
 	md.types = map[string]*typeDef{
-		"RelExpr":        {fullName: "memo.RelExpr", isExpr: true, isPointer: true},
-		"Expr":           {fullName: "opt.Expr", isExpr: true, isPointer: true},
-		"ScalarExpr":     {fullName: "opt.ScalarExpr", isExpr: true, isPointer: true},
-		"Operator":       {fullName: "opt.Operator", passByVal: true},
-		"ColumnID":       {fullName: "opt.ColumnID", passByVal: true},
-		"ColSet":         {fullName: "opt.ColSet", passByVal: true},
-		"ColList":        {fullName: "opt.ColList", passByVal: true},
-		"TableID":        {fullName: "opt.TableID", passByVal: true},
-		"SchemaID":       {fullName: "opt.SchemaID", passByVal: true},
-		"Ordering":       {fullName: "opt.Ordering", passByVal: true},
-		"OrderingChoice": {fullName: "physical.OrderingChoice", passByVal: true},
-		"TupleOrdinal":   {fullName: "memo.TupleOrdinal", passByVal: true},
-		"ScanLimit":      {fullName: "memo.ScanLimit", passByVal: true},
-		"ScanFlags":      {fullName: "memo.ScanFlags", passByVal: true},
-		"ExplainOptions": {fullName: "tree.ExplainOptions", passByVal: true},
-		"ShowTraceType":  {fullName: "tree.ShowTraceType", passByVal: true},
-		"bool":           {fullName: "bool", passByVal: true},
-		"int":            {fullName: "int", passByVal: true},
-		"string":         {fullName: "string", passByVal: true},
-		"DatumType":      {fullName: "types.T", isPointer: true},
-		"ColType":        {fullName: "coltypes.T", isPointer: true},
-		"Datum":          {fullName: "tree.Datum", isPointer: true},
-		"TypedExpr":      {fullName: "tree.TypedExpr", isPointer: true},
-		"Subquery":       {fullName: "*tree.Subquery", isPointer: true, usePointerIntern: true},
-		"CreateTable":    {fullName: "*tree.CreateTable", isPointer: true, usePointerIntern: true},
-		"Constraint":     {fullName: "*constraint.Constraint", isPointer: true, usePointerIntern: true},
-		"FuncProps":      {fullName: "*tree.FunctionProperties", isPointer: true, usePointerIntern: true},
-		"FuncOverload":   {fullName: "*tree.Overload", isPointer: true, usePointerIntern: true},
-		"PhysProps":      {fullName: "*physical.Required", isPointer: true},
-		"RelProps":       {fullName: "props.Relational"},
-		"ScalarProps":    {fullName: "props.Scalar"},
+		"RelExpr":           {fullName: "memo.RelExpr", isExpr: true, isPointer: true},
+		"Expr":              {fullName: "opt.Expr", isExpr: true, isPointer: true},
+		"ScalarExpr":        {fullName: "opt.ScalarExpr", isExpr: true, isPointer: true},
+		"Operator":          {fullName: "opt.Operator", passByVal: true},
+		"ColumnID":          {fullName: "opt.ColumnID", passByVal: true},
+		"ColSet":            {fullName: "opt.ColSet", passByVal: true},
+		"ColList":           {fullName: "opt.ColList", passByVal: true},
+		"TableID":           {fullName: "opt.TableID", passByVal: true},
+		"SchemaID":          {fullName: "opt.SchemaID", passByVal: true},
+		"WithID":            {fullName: "opt.WithID", passByVal: true},
+		"Ordering":          {fullName: "opt.Ordering", passByVal: true},
+		"OrderingChoice":    {fullName: "physical.OrderingChoice", passByVal: true},
+		"TupleOrdinal":      {fullName: "memo.TupleOrdinal", passByVal: true},
+		"ScanLimit":         {fullName: "memo.ScanLimit", passByVal: true},
+		"ScanFlags":         {fullName: "memo.ScanFlags", passByVal: true},
+		"WindowFrame":       {fullName: "memo.WindowFrame", passByVal: true},
+		"GroupingSets":      {fullName: "memo.GroupingSets", passByVal: true},
+		"LockingStrength":   {fullName: "tree.LockingStrength", passByVal: true},
+		"LockingWaitPolicy": {fullName: "tree.LockingWaitPolicy", passByVal: true},
+		"ExplainOptions":    {fullName: "tree.ExplainOptions", passByVal: true},
+		"ShowTraceType":     {fullName: "tree.ShowTraceType", passByVal: true},
+		"bool":              {fullName: "bool", passByVal: true},
+		"int":               {fullName: "int", passByVal: true},
+		"string":            {fullName: "string", passByVal: true},
+		"DatumType":         {fullName: "types.T", isPointer: true},
+		"ColType":           {fullName: "coltypes.T", isPointer: true},
+		"Datum":             {fullName: "tree.Datum", isPointer: true},
+		"TypedExpr":         {fullName: "tree.TypedExpr", isPointer: true},
+		"Subquery":          {fullName: "*tree.Subquery", isPointer: true, usePointerIntern: true},
+		"CreateTable":       {fullName: "*tree.CreateTable", isPointer: true, usePointerIntern: true},
+		"Constraint":        {fullName: "*constraint.Constraint", isPointer: true, usePointerIntern: true},
+		"FuncProps":         {fullName: "*tree.FunctionProperties", isPointer: true, usePointerIntern: true},
+		"FuncOverload":      {fullName: "*tree.Overload", isPointer: true, usePointerIntern: true},
+		"PhysProps":         {fullName: "*physical.Required", isPointer: true},
+		"RelProps":          {fullName: "props.Relational"},
+		"RelPropsPtr":       {fullName: "*props.Relational", isPointer: true, usePointerIntern: true},
+		"ScalarProps":       {fullName: "props.Scalar"},
 	}
 
 	// Add types of generated op and private structs.
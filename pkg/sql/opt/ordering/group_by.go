@@ -30,6 +30,17 @@ func scalarGroupByBuildChildReqOrdering(
 	return parent.(*memo.ScalarGroupByExpr).Ordering
 }
 
+func groupingSetsGroupByBuildChildReqOrdering(
+	parent memo.RelExpr, required *physical.OrderingChoice, childIdx int,
+) physical.OrderingChoice {
+	if childIdx != 0 {
+		return physical.OrderingChoice{}
+	}
+	// The ordering in the private only determines the order of the values
+	// within each group.
+	return parent.(*memo.GroupingSetsGroupByExpr).Ordering
+}
+
 func groupByCanProvideOrdering(expr memo.RelExpr, required *physical.OrderingChoice) bool {
 	// GroupBy may require a certain ordering of its input, but can also pass
 	// through a stronger ordering on the grouping columns.
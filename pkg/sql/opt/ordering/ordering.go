@@ -166,6 +166,22 @@ func init() {
 		buildChildReqOrdering: distinctOnBuildChildReqOrdering,
 		buildProvidedOrdering: distinctOnBuildProvided,
 	}
+	funcMap[opt.GroupingSetsGroupByOp] = funcs{
+		// GroupingSetsGroupBy always uses a hash table, so the rows are not
+		// returned in any particular order.
+		canProvideOrdering:    canNeverProvideOrdering,
+		buildChildReqOrdering: groupingSetsGroupByBuildChildReqOrdering,
+		buildProvidedOrdering: noProvidedOrdering,
+	}
+	funcMap[opt.WindowOp] = funcs{
+		// The window execution engine buffers the input rows and sorts them
+		// by the partition and ordering columns on its own, so Window doesn't
+		// require an ordering from its input. The rows are not necessarily
+		// returned in the input order, so Window cannot provide any ordering.
+		canProvideOrdering:    canNeverProvideOrdering,
+		buildChildReqOrdering: noChildReqOrdering,
+		buildProvidedOrdering: noProvidedOrdering,
+	}
 	funcMap[opt.SortOp] = funcs{
 		canProvideOrdering:    nil, // should never get called
 		buildChildReqOrdering: noChildReqOrdering,
@@ -0,0 +1,523 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// Histogram captures the distribution of values for a particular column within
+// a relational expression. NULL values are not included in the histogram (they
+// are accounted for by the null count of the column statistic).
+//
+// The histogram is made of buckets, ordered by their upper bound. Each bucket
+// counts the values equal to its upper bound, as well as the values in the
+// range between the upper bound of the previous bucket and its own upper
+// bound (both exclusive). The lower bound of the range of the first bucket is
+// unknown, unless that range is empty.
+//
+// Histograms are immutable: Filter and ApplySelectivity return new histograms.
+type Histogram struct {
+	evalCtx *tree.EvalContext
+	col     opt.ColumnID
+	buckets []HistogramBucket
+}
+
+// HistogramBucket is a bucket of a Histogram.
+type HistogramBucket struct {
+	// NumEq is the estimated number of values equal to UpperBound.
+	NumEq float64
+
+	// NumRange is the estimated number of values between the upper bound of
+	// the previous bucket and UpperBound (both boundaries are exclusive).
+	NumRange float64
+
+	// DistinctRange is the estimated number of distinct values between the
+	// upper bound of the previous bucket and UpperBound (both boundaries are
+	// exclusive).
+	DistinctRange float64
+
+	// UpperBound is the upper bound of the bucket.
+	UpperBound tree.Datum
+}
+
+// Init initializes the histogram with the given catalog buckets, which are
+// ordered by their upper bound. distinctCount is the number of distinct
+// non-NULL values of the column; it is used to estimate the number of distinct
+// values in the range of each bucket, which the catalog doesn't provide.
+func (h *Histogram) Init(
+	evalCtx *tree.EvalContext, col opt.ColumnID, buckets []cat.HistogramBucket, distinctCount float64,
+) {
+	h.evalCtx = evalCtx
+	h.col = col
+	h.buckets = make([]HistogramBucket, len(buckets))
+
+	// The distinct values which are not the upper bound of a bucket are spread
+	// over the bucket ranges, in proportion to the number of values in each
+	// range.
+	var numRange float64
+	distinctRange := distinctCount
+	for i := range buckets {
+		numRange += buckets[i].NumRange
+		if buckets[i].NumEq > 0 {
+			distinctRange--
+		}
+	}
+	distinctRange = math.Max(distinctRange, 0)
+
+	for i := range buckets {
+		b := &h.buckets[i]
+		b.NumEq = buckets[i].NumEq
+		b.NumRange = buckets[i].NumRange
+		b.UpperBound = buckets[i].UpperBound
+		if numRange > 0 {
+			b.DistinctRange = distinctRange * b.NumRange / numRange
+		}
+		// Every value in the range may be distinct, but there can't be more
+		// distinct values than values.
+		if b.NumRange > 0 {
+			b.DistinctRange = math.Max(b.DistinctRange, 1)
+		}
+		b.DistinctRange = math.Min(b.DistinctRange, b.NumRange)
+		if i > 0 {
+			if n, ok := valuesInRange(h.buckets[i-1].UpperBound, b.UpperBound); ok {
+				b.DistinctRange = math.Min(b.DistinctRange, n)
+			}
+		}
+	}
+}
+
+// Column returns the column described by the histogram.
+func (h *Histogram) Column() opt.ColumnID {
+	return h.col
+}
+
+// BucketCount returns the number of buckets in the histogram.
+func (h *Histogram) BucketCount() int {
+	return len(h.buckets)
+}
+
+// Bucket returns the ith bucket of the histogram, where i < BucketCount.
+func (h *Histogram) Bucket(i int) *HistogramBucket {
+	return &h.buckets[i]
+}
+
+// ValuesCount returns the estimated number of values in the histogram. The
+// selectivity of a predicate can be estimated by comparing the values count
+// before and after filtering the histogram.
+func (h *Histogram) ValuesCount() float64 {
+	var count float64
+	for i := range h.buckets {
+		count += h.buckets[i].NumEq + h.buckets[i].NumRange
+	}
+	return count
+}
+
+// DistinctValuesCount returns the estimated number of distinct values in the
+// histogram.
+func (h *Histogram) DistinctValuesCount() float64 {
+	var count float64
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		count += b.DistinctRange + math.Min(b.NumEq, 1)
+	}
+	return count
+}
+
+// ApplySelectivity returns a new histogram in which the number of values in
+// each bucket is reduced according to the given selectivity. The number of
+// distinct values is reduced the same way as in ColumnStatistic.
+func (h *Histogram) ApplySelectivity(selectivity float64) *Histogram {
+	res := &Histogram{
+		evalCtx: h.evalCtx,
+		col:     h.col,
+		buckets: make([]HistogramBucket, len(h.buckets)),
+	}
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		r := &res.buckets[i]
+		r.UpperBound = b.UpperBound
+		r.NumEq = b.NumEq * selectivity
+		r.NumRange = b.NumRange * selectivity
+		if b.DistinctRange > 0 {
+			d := b.DistinctRange
+			r.DistinctRange = d - d*math.Pow(1-selectivity, b.NumRange/d)
+		}
+	}
+	return res
+}
+
+// CanFilter returns true if the histogram can be filtered by the given
+// constraint. This is the case if the histogram column is one of the columns
+// of the constraint for which all the spans have the same start and end
+// values, or the first column following them (see Constraint.Prefix).
+func (h *Histogram) CanFilter(c *constraint.Constraint) bool {
+	_, ok := h.constraintColumn(c)
+	return ok
+}
+
+// constraintColumn returns the position of the histogram column in the given
+// constraint, if the histogram can be filtered by the constraint.
+func (h *Histogram) constraintColumn(c *constraint.Constraint) (colIdx int, ok bool) {
+	if c.IsUnconstrained() {
+		return 0, false
+	}
+	prefix := c.Prefix(h.evalCtx)
+	for i := 0; i <= prefix && i < c.Columns.Count(); i++ {
+		if c.Columns.Get(i).ID() == h.col {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Filter returns a new histogram which only contains the values allowed by
+// the given constraint. CanFilter must be true for the constraint.
+func (h *Histogram) Filter(c *constraint.Constraint) *Histogram {
+	colIdx, ok := h.constraintColumn(c)
+	if !ok {
+		panic(fmt.Sprintf("histogram on column %d cannot be filtered by %s", h.col, c))
+	}
+	desc := c.Columns.Get(colIdx).Descending()
+
+	// Project the spans of the constraint onto the histogram column, as ranges
+	// of values.
+	ranges := make([]valueRange, 0, c.Spans.Count())
+	for i := 0; i < c.Spans.Count(); i++ {
+		sp := c.Spans.Get(i)
+		var r valueRange
+		if desc {
+			r.lower, r.lowerIncl = spanKeyValue(sp.EndKey(), sp.EndBoundary(), colIdx)
+			r.upper, r.upperIncl = spanKeyValue(sp.StartKey(), sp.StartBoundary(), colIdx)
+		} else {
+			r.lower, r.lowerIncl = spanKeyValue(sp.StartKey(), sp.StartBoundary(), colIdx)
+			r.upper, r.upperIncl = spanKeyValue(sp.EndKey(), sp.EndBoundary(), colIdx)
+		}
+		// NULL values are not part of the histogram, and NULL sorts before all
+		// the other values.
+		if r.upper == tree.DNull {
+			continue
+		}
+		if r.lower == tree.DNull {
+			r.lower = nil
+		}
+		if r.isEmpty(h.evalCtx) {
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	return h.filterRanges(ranges)
+}
+
+// Overlap returns a new histogram which only contains the values of h which
+// are within the bounds of the other histogram. The two histograms must be on
+// columns of the same type.
+func (h *Histogram) Overlap(other *Histogram) *Histogram {
+	if len(other.buckets) == 0 {
+		return h.filterRanges(nil)
+	}
+	r := valueRange{
+		upper:     other.buckets[len(other.buckets)-1].UpperBound,
+		upperIncl: true,
+	}
+	if other.buckets[0].NumRange == 0 {
+		// Otherwise, the lower bound of other is unknown.
+		r.lower, r.lowerIncl = other.buckets[0].UpperBound, true
+	}
+	return h.filterRanges([]valueRange{r})
+}
+
+func (h *Histogram) String() string {
+	var buf bytes.Buffer
+	for i := range h.buckets {
+		b := &h.buckets[i]
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		fmt.Fprintf(&buf, "{%s: eq=%.9g, range=%.9g, distinct-range=%.9g}",
+			b.UpperBound, b.NumEq, b.NumRange, b.DistinctRange)
+	}
+	return buf.String()
+}
+
+// valueRange is a range of values on the histogram column. A nil bound means
+// that the range is not bounded on that side.
+type valueRange struct {
+	lower, upper         tree.Datum
+	lowerIncl, upperIncl bool
+}
+
+// isEmpty returns true if the range doesn't contain any value.
+func (r *valueRange) isEmpty(evalCtx *tree.EvalContext) bool {
+	if r.lower == nil || r.upper == nil {
+		return false
+	}
+	cmp := r.lower.Compare(evalCtx, r.upper)
+	return cmp > 0 || (cmp == 0 && (!r.lowerIncl || !r.upperIncl))
+}
+
+// spanKeyValue returns the value of the given span key on the nth column, or
+// nil if the key doesn't constrain that column. The value is inclusive if the
+// key has more columns, since the span then contains some of the keys which
+// have this value on the nth column.
+func spanKeyValue(
+	key constraint.Key, boundary constraint.SpanBoundary, nth int,
+) (tree.Datum, bool) {
+	if key.Length() <= nth {
+		return nil, false
+	}
+	return key.Value(nth), key.Length() > nth+1 || boundary == constraint.IncludeBoundary
+}
+
+// filterRanges returns a new histogram which only contains the values within
+// the given ranges. The ranges can overlap.
+func (h *Histogram) filterRanges(ranges []valueRange) *Histogram {
+	ranges = h.mergeRanges(ranges)
+	f := histogramFilter{res: &Histogram{evalCtx: h.evalCtx, col: h.col}}
+
+	for i, r := 0, 0; i < len(h.buckets) && r < len(ranges); i++ {
+		b := &h.buckets[i]
+		var lower tree.Datum
+		if i > 0 {
+			lower = h.buckets[i-1].UpperBound
+		}
+
+		// Process the ranges which overlap with the bucket, i.e. with the values
+		// in (lower, b.UpperBound].
+		for ; r < len(ranges); r++ {
+			rng := &ranges[r]
+			if rng.lower != nil {
+				cmp := h.compare(rng.lower, b.UpperBound)
+				if cmp > 0 || (cmp == 0 && !rng.lowerIncl) {
+					// The range starts after the bucket.
+					break
+				}
+				if cmp == 0 {
+					// The range only contains the upper bound of the bucket.
+					f.add(nil /* lower */, b.UpperBound, 0 /* numRange */, 0 /* distinctRange */, b.NumEq)
+					if h.endsWithin(rng, b.UpperBound) {
+						continue
+					}
+					break
+				}
+			}
+
+			// The part of the range of the bucket covered by rng starts at
+			// pieceLower (exclusive).
+			pieceLower := lower
+			if rng.lower != nil && (lower == nil || h.compare(rng.lower, lower) > 0) {
+				pieceLower = rng.lower
+				if rng.lowerIncl {
+					f.add(nil /* lower */, rng.lower, 0 /* numRange */, 0 /* distinctRange */, b.valueCount())
+				}
+			}
+
+			if rng.upper == nil || h.compare(rng.upper, b.UpperBound) >= 0 {
+				// The range covers the end of the bucket range.
+				frac := h.rangeFraction(lower, b.UpperBound, pieceLower, b.UpperBound)
+				var numEq float64
+				if rng.upper == nil || h.compare(rng.upper, b.UpperBound) > 0 || rng.upperIncl {
+					numEq = b.NumEq
+				}
+				f.add(pieceLower, b.UpperBound, b.NumRange*frac, b.DistinctRange*frac, numEq)
+				if h.endsWithin(rng, b.UpperBound) {
+					continue
+				}
+				// The range continues in the next bucket.
+				break
+			}
+
+			// The range ends within the bucket range.
+			if pieceLower == nil || h.compare(pieceLower, rng.upper) < 0 {
+				frac := h.rangeFraction(lower, b.UpperBound, pieceLower, rng.upper)
+				var numEq float64
+				if rng.upperIncl {
+					numEq = b.valueCount()
+				}
+				f.add(pieceLower, rng.upper, b.NumRange*frac, b.DistinctRange*frac, numEq)
+			}
+		}
+	}
+	return f.res
+}
+
+// endsWithin returns true if the given range doesn't contain any value greater
+// than the given upper bound.
+func (h *Histogram) endsWithin(r *valueRange, upper tree.Datum) bool {
+	return r.upper != nil && h.compare(r.upper, upper) <= 0
+}
+
+// mergeRanges sorts the given ranges by their lower bound and merges the ones
+// which overlap, so that the resulting ranges are disjoint.
+func (h *Histogram) mergeRanges(ranges []valueRange) []valueRange {
+	if len(ranges) <= 1 {
+		return ranges
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		if ranges[i].lower == nil || ranges[j].lower == nil {
+			return ranges[i].lower == nil && ranges[j].lower != nil
+		}
+		cmp := h.compare(ranges[i].lower, ranges[j].lower)
+		return cmp < 0 || (cmp == 0 && ranges[i].lowerIncl && !ranges[j].lowerIncl)
+	})
+	res := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &res[len(res)-1]
+		if last.upper != nil && r.lower != nil {
+			cmp := h.compare(r.lower, last.upper)
+			if cmp > 0 || (cmp == 0 && !r.lowerIncl && !last.upperIncl) {
+				// The ranges are disjoint.
+				res = append(res, r)
+				continue
+			}
+		}
+		if last.upper == nil {
+			continue
+		}
+		if r.upper == nil {
+			last.upper = nil
+			continue
+		}
+		if cmp := h.compare(r.upper, last.upper); cmp > 0 {
+			last.upper, last.upperIncl = r.upper, r.upperIncl
+		} else if cmp == 0 {
+			last.upperIncl = last.upperIncl || r.upperIncl
+		}
+	}
+	return res
+}
+
+func (h *Histogram) compare(a, b tree.Datum) int {
+	return a.Compare(h.evalCtx, b)
+}
+
+// rangeFraction estimates the fraction of the values in the range (lower,
+// upper) which are within the range (pieceLower, pieceUpper); both ranges
+// exclude their boundaries, and the second is included in the first. A nil
+// lower bound means that the bound is unknown.
+func (h *Histogram) rangeFraction(lower, upper, pieceLower, pieceUpper tree.Datum) float64 {
+	lowerCut := pieceLower != lower
+	upperCut := pieceUpper != upper
+	if !lowerCut && !upperCut {
+		return 1
+	}
+	if lower != nil {
+		// Assume that the values are uniformly distributed in the range. This is
+		// only possible for the types for which the size of a range can be
+		// computed.
+		if total, ok := valuesInRange(lower, upper); ok && total > 0 {
+			if piece, ok := valuesInRange(pieceLower, pieceUpper); ok {
+				return math.Max(math.Min(piece/total, 1), 0)
+			}
+		}
+	}
+	// Without any knowledge of the distribution, assume that each boundary of
+	// the piece cuts the range in half.
+	frac := 1.0
+	if lowerCut {
+		frac /= 2
+	}
+	if upperCut {
+		frac /= 2
+	}
+	return frac
+}
+
+// valueCount returns the estimated number of rows for each distinct value in
+// the range of the bucket.
+func (b *HistogramBucket) valueCount() float64 {
+	if b.DistinctRange <= 1 {
+		return b.NumRange
+	}
+	return b.NumRange / b.DistinctRange
+}
+
+// valuesInRange returns the size of the range (lower, upper), if it can be
+// computed for the type of the bounds. For discrete types, this is the number
+// of values in the range.
+func valuesInRange(lower, upper tree.Datum) (_ float64, ok bool) {
+	if lower == nil || upper == nil {
+		return 0, false
+	}
+	switch l := lower.(type) {
+	case *tree.DInt:
+		if u, ok := upper.(*tree.DInt); ok {
+			return math.Max(float64(*u)-float64(*l)-1, 0), true
+		}
+	case *tree.DDate:
+		if u, ok := upper.(*tree.DDate); ok {
+			return math.Max(float64(*u)-float64(*l)-1, 0), true
+		}
+	case *tree.DFloat:
+		if u, ok := upper.(*tree.DFloat); ok {
+			return math.Max(float64(*u)-float64(*l), 0), true
+		}
+	case *tree.DDecimal:
+		if u, ok := upper.(*tree.DDecimal); ok {
+			lf, err := l.Float64()
+			if err != nil {
+				return 0, false
+			}
+			uf, err := u.Float64()
+			if err != nil {
+				return 0, false
+			}
+			return math.Max(uf-lf, 0), true
+		}
+	case *tree.DTimestamp:
+		if u, ok := upper.(*tree.DTimestamp); ok {
+			return math.Max(float64(u.Sub(l.Time)), 0), true
+		}
+	case *tree.DTimestampTZ:
+		if u, ok := upper.(*tree.DTimestampTZ); ok {
+			return math.Max(float64(u.Sub(l.Time)), 0), true
+		}
+	}
+	return 0, false
+}
+
+// histogramFilter builds the histogram resulting from filtering another
+// histogram.
+type histogramFilter struct {
+	res *Histogram
+}
+
+// add appends a bucket to the resulting histogram. The range of the bucket is
+// (lower, upper); if lower is not nil and isn't the upper bound of the last
+// bucket, an empty bucket is first added to exclude the values before lower.
+func (f *histogramFilter) add(
+	lower, upper tree.Datum, numRange, distinctRange, numEq float64,
+) {
+	buckets := f.res.buckets
+	if lower != nil {
+		if n := len(buckets); n == 0 || buckets[n-1].UpperBound.Compare(f.res.evalCtx, lower) != 0 {
+			buckets = append(buckets, HistogramBucket{UpperBound: lower})
+		}
+	}
+	f.res.buckets = append(buckets, HistogramBucket{
+		NumEq:         numEq,
+		NumRange:      numRange,
+		DistinctRange: distinctRange,
+		UpperBound:    upper,
+	})
+}
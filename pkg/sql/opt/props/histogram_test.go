@@ -0,0 +1,115 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package props_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestHistogram(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+
+	//   0  1  2  3  4  5  6  7  8  9  10 11 12 13 14 15 16 17 18 19 20
+	// --------------------------------------------------------------------
+	// |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |  |
+	// 1  10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 10 20
+	var h props.Histogram
+	h.Init(&evalCtx, opt.ColumnID(1), []cat.HistogramBucket{
+		{NumEq: 1, NumRange: 0, UpperBound: tree.NewDInt(0)},
+		{NumEq: 10, NumRange: 90, UpperBound: tree.NewDInt(10)},
+		{NumEq: 20, NumRange: 90, UpperBound: tree.NewDInt(20)},
+	}, 21 /* distinctCount */)
+
+	expectCounts := func(t *testing.T, h *props.Histogram, values, distinct float64) {
+		t.Helper()
+		if actual := h.ValuesCount(); math.Abs(actual-values) > 1e-6 {
+			t.Errorf("expected %g values, found %g (%s)", values, actual, h)
+		}
+		if actual := h.DistinctValuesCount(); math.Abs(actual-distinct) > 1e-6 {
+			t.Errorf("expected %g distinct values, found %g (%s)", distinct, actual, h)
+		}
+	}
+
+	expectCounts(t, &h, 211, 21)
+	for i, expected := range []float64{0, 9, 9} {
+		if actual := h.Bucket(i).DistinctRange; actual != expected {
+			t.Errorf("expected %g distinct values in the range of bucket %d, found %g",
+				expected, i, actual)
+		}
+	}
+
+	testData := []struct {
+		constraint string
+		canFilter  bool
+		values     float64
+		distinct   float64
+	}{
+		{constraint: "/1: [/0 - /0]", canFilter: true, values: 1, distinct: 1},
+		{constraint: "/1: [/10 - /10]", canFilter: true, values: 10, distinct: 1},
+		{constraint: "/1: [/5 - /5]", canFilter: true, values: 10, distinct: 1},
+		{constraint: "/1: [/5 - /15]", canFilter: true, values: 110, distinct: 11},
+		{constraint: "/-1: [/15 - /5]", canFilter: true, values: 110, distinct: 11},
+		{constraint: "/1: (/10 - ]", canFilter: true, values: 110, distinct: 10},
+		{constraint: "/1: [/20 - ]", canFilter: true, values: 20, distinct: 1},
+		{constraint: "/1: [/21 - ]", canFilter: true, values: 0, distinct: 0},
+		{constraint: "/1: [/NULL - /NULL]", canFilter: true, values: 0, distinct: 0},
+		{constraint: "/1: (/NULL - /0]", canFilter: true, values: 1, distinct: 1},
+		{constraint: "/1: [/1 - /1] [/19 - /20]", canFilter: true, values: 40, distinct: 3},
+		{constraint: "/2/1: [/3/5 - /3/15]", canFilter: true, values: 110, distinct: 11},
+		{constraint: "/2/1: [/3/5 - /4/15]", canFilter: false},
+		{constraint: "/1/2: [/5/1 - /5/1] [/5/3 - /5/3]", canFilter: true, values: 10, distinct: 1},
+		{constraint: "/2: [/5 - /15]", canFilter: false},
+	}
+
+	for _, tc := range testData {
+		t.Run(tc.constraint, func(t *testing.T) {
+			c := constraint.ParseConstraint(&evalCtx, tc.constraint)
+			if canFilter := h.CanFilter(&c); canFilter != tc.canFilter {
+				t.Fatalf("expected CanFilter to be %t", tc.canFilter)
+			}
+			if !tc.canFilter {
+				return
+			}
+			expectCounts(t, h.Filter(&c), tc.values, tc.distinct)
+		})
+	}
+
+	t.Run("overlap", func(t *testing.T) {
+		var other props.Histogram
+		other.Init(&evalCtx, opt.ColumnID(2), []cat.HistogramBucket{
+			{NumEq: 1, NumRange: 0, UpperBound: tree.NewDInt(5)},
+			{NumEq: 1, NumRange: 9, UpperBound: tree.NewDInt(15)},
+		}, 11 /* distinctCount */)
+		expectCounts(t, h.Overlap(&other), 110, 11)
+
+		// The lower bound of the other histogram is unknown.
+		other.Init(&evalCtx, opt.ColumnID(2), []cat.HistogramBucket{
+			{NumEq: 1, NumRange: 9, UpperBound: tree.NewDInt(15)},
+		}, 10 /* distinctCount */)
+		expectCounts(t, h.Overlap(&other), 151, 16)
+	})
+
+	t.Run("selectivity", func(t *testing.T) {
+		expectCounts(t, h.ApplySelectivity(0.5), 105.5, 20.482421875)
+	})
+}
@@ -84,6 +84,7 @@ func (s *Statistics) ApplySelectivity(selectivity float64) {
 			colStat := s.ColStats.Get(i)
 			colStat.DistinctCount = 0
 			colStat.NullCount = 0
+			colStat.Histogram = nil
 		}
 		return
 	}
@@ -142,17 +143,27 @@ type ColumnStatistic struct {
 	// count tracks all instances of at least one null value in the
 	// column set.
 	NullCount float64
+
+	// Histogram is only used when the size of Cols is one. It contains
+	// the approximate distribution of values for that column, represented
+	// by a slice of histogram buckets. It is nil if no histogram is available.
+	Histogram *Histogram
 }
 
-// ApplySelectivity updates the distinct count according to a given selectivity.
+// ApplySelectivity updates the distinct count and the histogram according to
+// a given selectivity.
 func (c *ColumnStatistic) ApplySelectivity(selectivity, inputRows float64) {
 	if selectivity == 1 || c.DistinctCount == 0 {
 		return
 	}
 	if selectivity == 0 {
 		c.DistinctCount = 0
+		c.Histogram = nil
 		return
 	}
+	if c.Histogram != nil {
+		c.Histogram = c.Histogram.ApplySelectivity(selectivity)
+	}
 
 	n := inputRows
 	d := c.DistinctCount
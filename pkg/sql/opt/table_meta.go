@@ -123,6 +123,11 @@ type TableMeta struct {
 
 	// anns annotates the table metadata with arbitrary data.
 	anns [maxTableAnnIDCount]interface{}
+
+	// partialIndexPredicates maps the ordinals of the table's partial indexes
+	// to their predicates, built as scalar expressions over the table's
+	// columns. It is nil if the predicates were not built.
+	partialIndexPredicates map[int]ScalarExpr
 }
 
 // Name returns the table alias, if it was specified, or else the table's name
@@ -148,6 +153,23 @@ func (tm *TableMeta) IndexColumns(indexOrd int) ColSet {
 	return indexCols
 }
 
+// AddPartialIndexPredicate adds the predicate of the partial index with the
+// given ordinal to the table metadata.
+func (tm *TableMeta) AddPartialIndexPredicate(indexOrd int, pred ScalarExpr) {
+	if tm.partialIndexPredicates == nil {
+		tm.partialIndexPredicates = make(map[int]ScalarExpr)
+	}
+	tm.partialIndexPredicates[indexOrd] = pred
+}
+
+// PartialIndexPredicate returns the predicate of the partial index with the
+// given ordinal. It returns false for the second return value if the index is
+// not partial, or if its predicate was not added to the table metadata.
+func (tm *TableMeta) PartialIndexPredicate(indexOrd int) (ScalarExpr, bool) {
+	pred, ok := tm.partialIndexPredicates[indexOrd]
+	return pred, ok
+}
+
 // TableAnnotation returns the given annotation that is associated with the
 // given table. If the table has no such annotation, TableAnnotation returns
 // nil.
@@ -130,8 +130,10 @@ func NewOptTester(catalog cat.Catalog, sql string) *OptTester {
 
 	// Set any OptTester-wide session flags here.
 
-	// Enable CBO planning for UPDATE statements, for all tests.
+	// Enable CBO planning for UPDATE statements and foreign key checks, for all
+	// tests.
 	ot.evalCtx.SessionData.OptimizerMutations = true
+	ot.evalCtx.SessionData.OptimizerFKs = true
 
 	// Enable zigzag joins for all opt tests. Execbuilder tests exercise
 	// cases where this flag is false.
@@ -189,15 +189,32 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 		))
 	}
 
+	// setFK sets up the outbound reference on the given source index and the
+	// corresponding inbound reference on the target index.
+	setFK := func(idx *Index) {
+		idx.foreignKey = cat.ForeignKeyReference{
+			TableID:    targetTable.ID(),
+			IndexID:    targetIndex.ID(),
+			PrefixLen:  int32(len(fromCols)),
+			Match:      d.Match,
+			Name:       string(d.Name),
+			OnDelete:   d.Actions.Delete,
+			OnUpdate:   d.Actions.Update,
+			Deferrable: d.Deferrable != tree.NotDeferrable,
+		}
+		idx.fkSet = true
+		targetIndex.inboundFKs = append(targetIndex.inboundFKs, cat.ForeignKeyReference{
+			TableID: tab.ID(),
+			IndexID: idx.ID(),
+		})
+	}
+
 	// 2. Search for an existing index in the source table; add it if necessary.
 	found := false
 	for _, idx := range tab.Indexes {
 		if matches(idx, fromCols, false /* strict */) {
 			found = true
-			idx.foreignKey.TableID = targetTable.ID()
-			idx.foreignKey.IndexID = targetIndex.ID()
-			idx.foreignKey.PrefixLen = int32(len(fromCols))
-			idx.fkSet = true
+			setFK(idx)
 			break
 		}
 	}
@@ -217,11 +234,7 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 			idx.Columns[i].Column = tab.Columns[c].ColName()
 			idx.Columns[i].Direction = tree.Ascending
 		}
-		index := tab.addIndex(&idx, nonUniqueIndex)
-		index.foreignKey.TableID = targetTable.ID()
-		index.foreignKey.IndexID = targetIndex.ID()
-		index.foreignKey.PrefixLen = int32(len(fromCols))
-		index.fkSet = true
+		setFK(tab.addIndex(&idx, nonUniqueIndex))
 	}
 }
 
@@ -270,6 +283,9 @@ func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) *Index {
 		Inverted: def.Inverted,
 		table:    tt,
 	}
+	if def.Predicate != nil {
+		idx.PredicateText = tree.Serialize(def.Predicate)
+	}
 
 	// Add explicit columns and mark primary key columns as not null.
 	notNullIndex := true
@@ -415,6 +415,7 @@ type Table struct {
 	Indexes    []*Index
 	Stats      TableStats
 	IsVirtual  bool
+	IsMatView  bool
 	Catalog    cat.Catalog
 	Mutations  []cat.MutationColumn
 
@@ -454,6 +455,11 @@ func (tt *Table) IsVirtualTable() bool {
 	return tt.IsVirtual
 }
 
+// IsMaterializedView is part of the cat.Table interface.
+func (tt *Table) IsMaterializedView() bool {
+	return tt.IsMatView
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (tt *Table) ColumnCount() int {
 	return len(tt.Columns) + len(tt.Mutations)
@@ -477,6 +483,11 @@ func (tt *Table) Index(i int) cat.Index {
 	return tt.Indexes[i]
 }
 
+// MutationIndexCount is part of the cat.Table interface.
+func (tt *Table) MutationIndexCount() int {
+	return 0
+}
+
 // StatisticCount is part of the cat.Table interface.
 func (tt *Table) StatisticCount() int {
 	return len(tt.Stats)
@@ -523,6 +534,10 @@ type Index struct {
 	// Inverted is true when this index is an inverted index.
 	Inverted bool
 
+	// PredicateText is the predicate of a partial index, or the empty string
+	// if the index is not partial.
+	PredicateText string
+
 	Columns []cat.IndexColumn
 
 	// table is a back reference to the table this index is on.
@@ -533,6 +548,10 @@ type Index struct {
 	// index reference.
 	foreignKey cat.ForeignKeyReference
 	fkSet      bool
+
+	// inboundFKs contains the foreign key references from other indexes that
+	// target this index.
+	inboundFKs []cat.ForeignKeyReference
 }
 
 // ID is part of the cat.Index interface.
@@ -560,6 +579,11 @@ func (ti *Index) IsInverted() bool {
 	return ti.Inverted
 }
 
+// Predicate is part of the cat.Index interface.
+func (ti *Index) Predicate() (string, bool) {
+	return ti.PredicateText, ti.PredicateText != ""
+}
+
 // ColumnCount is part of the cat.Index interface.
 func (ti *Index) ColumnCount() int {
 	return len(ti.Columns)
@@ -585,6 +609,16 @@ func (ti *Index) ForeignKey() (cat.ForeignKeyReference, bool) {
 	return ti.foreignKey, ti.fkSet
 }
 
+// InboundForeignKeyCount is part of the cat.Index interface.
+func (ti *Index) InboundForeignKeyCount() int {
+	return len(ti.inboundFKs)
+}
+
+// InboundForeignKey is part of the cat.Index interface.
+func (ti *Index) InboundForeignKey(i int) cat.ForeignKeyReference {
+	return ti.inboundFKs[i]
+}
+
 // Column implements the cat.Column interface for testing purposes.
 type Column struct {
 	Ordinal      int
@@ -654,8 +688,9 @@ func (tc *Column) ComputedExprStr() string {
 
 // TableStat implements the cat.TableStatistic interface for testing purposes.
 type TableStat struct {
-	js stats.JSONStatistic
-	tt *Table
+	js        stats.JSONStatistic
+	tt        *Table
+	histogram []cat.HistogramBucket
 }
 
 var _ cat.TableStatistic = &TableStat{}
@@ -694,6 +729,11 @@ func (ts *TableStat) NullCount() uint64 {
 	return ts.js.NullCount
 }
 
+// Histogram is part of the cat.TableStatistic interface.
+func (ts *TableStat) Histogram() []cat.HistogramBucket {
+	return ts.histogram
+}
+
 // TableStats is a slice of TableStat pointers.
 type TableStats []*TableStat
 
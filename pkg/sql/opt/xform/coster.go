@@ -135,6 +135,9 @@ func (c *coster) ComputeCost(candidate memo.RelExpr, required *physical.Required
 	case opt.GroupByOp, opt.ScalarGroupByOp, opt.DistinctOnOp:
 		cost = c.computeGroupingCost(candidate, required)
 
+	case opt.GroupingSetsGroupByOp:
+		cost = c.computeGroupingSetsCost(candidate.(*memo.GroupingSetsGroupByExpr))
+
 	case opt.LimitOp:
 		cost = c.computeLimitCost(candidate.(*memo.LimitExpr))
 
@@ -147,6 +150,9 @@ func (c *coster) ComputeCost(candidate memo.RelExpr, required *physical.Required
 	case opt.ProjectSetOp:
 		cost = c.computeProjectSetCost(candidate.(*memo.ProjectSetExpr))
 
+	case opt.WindowOp:
+		cost = c.computeWindowCost(candidate.(*memo.WindowExpr))
+
 	case opt.ExplainOp:
 		// Technically, the cost of an Explain operation is independent of the cost
 		// of the underlying plan. However, we want to explain the plan we would get
@@ -387,6 +393,23 @@ func (c *coster) computeGroupingCost(grouping memo.RelExpr, required *physical.R
 	return cost
 }
 
+func (c *coster) computeGroupingSetsCost(groupBy *memo.GroupingSetsGroupByExpr) memo.Cost {
+	// Add the CPU cost of emitting the rows.
+	cost := memo.Cost(groupBy.Relational().Stats.RowCount) * cpuCostFactor
+
+	// GroupingSetsGroupBy must process each input row once for every grouping
+	// set, always using a hash table. Cost per row depends on the number of
+	// grouping columns and the number of aggregates.
+	inputRowCount := groupBy.Input.Relational().Stats.RowCount
+	aggsCount := len(groupBy.Aggregations)
+	for i := range groupBy.GroupingSets {
+		groupingColCount := groupBy.GroupingSets[i].Len()
+		cost += memo.Cost(inputRowCount) * memo.Cost(aggsCount+groupingColCount+1) * cpuCostFactor
+	}
+
+	return cost
+}
+
 func (c *coster) computeLimitCost(limit *memo.LimitExpr) memo.Cost {
 	// Add the CPU cost of emitting the rows.
 	cost := memo.Cost(limit.Relational().Stats.RowCount) * cpuCostFactor
@@ -411,6 +434,22 @@ func (c *coster) computeProjectSetCost(projectSet *memo.ProjectSetExpr) memo.Cos
 	return cost
 }
 
+func (c *coster) computeWindowCost(window *memo.WindowExpr) memo.Cost {
+	// The window operator buffers the rows of its input and sorts them by the
+	// partition and ordering columns, so its cost is similar to the cost of a
+	// sort (see computeSortCost).
+	rowCount := window.Relational().Stats.RowCount
+	perRowCost := c.rowSortCost(window.Partition.Len() + len(window.Ordering.Columns))
+	cost := memo.Cost(rowCount) * perRowCost
+	if rowCount > 1 {
+		cost *= (1 + memo.Cost(math.Log2(rowCount)))
+	}
+
+	// Add the CPU cost of computing the window functions for each row.
+	cost += memo.Cost(rowCount) * memo.Cost(len(window.Windows)) * cpuCostFactor
+	return cost
+}
+
 // rowSortCost is the CPU cost to sort one row, which depends on the number of
 // columns in the sort key.
 func (c *coster) rowSortCost(numKeyCols int) memo.Cost {
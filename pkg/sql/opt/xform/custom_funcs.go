@@ -183,9 +183,12 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 	var sb indexScanBuilder
 	sb.init(c, scanPrivate.Table)
 
-	// Iterate over all indexes.
+	// Iterate over all indexes, including the partial indexes whose predicate
+	// is implied by the filters.
 	var iter scanIndexIter
 	iter.init(c.e.mem, scanPrivate)
+	iter.filters = filters
+	iter.evalCtx = c.e.evalCtx
 	for iter.next() {
 		// Check whether the filter can constrain the index.
 		constraint, remaining, ok := c.tryConstrainIndex(
@@ -655,6 +658,10 @@ func (c *CustomFuncs) GenerateMergeJoins(
 //     "sides" (in this example x,y on the left and z on the right) but there is
 //     no overlap.
 //
+//     This case is not supported for semi and anti joins: the lower LookupJoin
+//     cannot decide whether an input row has a match without the columns
+//     retrieved by the index join.
+//
 func (c *CustomFuncs) GenerateLookupJoins(
 	grp memo.RelExpr,
 	joinType opt.Operator,
@@ -662,6 +669,12 @@ func (c *CustomFuncs) GenerateLookupJoins(
 	scanPrivate *memo.ScanPrivate,
 	on memo.FiltersExpr,
 ) {
+	if scanPrivate.Locking != tree.ForNone {
+		// The lookup join would read the table without acquiring the row-level
+		// locks.
+		return
+	}
+
 	inputProps := input.Relational()
 
 	leftEq, rightEq := memo.ExtractJoinEqualityColumns(inputProps.OutputCols, scanPrivate.Cols, on)
@@ -713,6 +726,9 @@ func (c *CustomFuncs) GenerateLookupJoins(
 		if scanPrivate.Flags.NoIndexJoin {
 			continue
 		}
+		if joinType == opt.SemiJoinOp || joinType == opt.AntiJoinOp {
+			continue
+		}
 
 		if pkCols == nil {
 			pkIndex := iter.tab.Index(cat.PrimaryIndex)
@@ -1385,6 +1401,12 @@ type scanIndexIter struct {
 	indexOrdinal int
 	index        cat.Index
 	cols         opt.ColSet
+
+	// filters, if set, are the filters applied to the rows of the Scan. Partial
+	// indexes are only enumerated if the filters imply their predicate, which
+	// is determined with the help of evalCtx.
+	filters memo.FiltersExpr
+	evalCtx *tree.EvalContext
 }
 
 func (it *scanIndexIter) init(mem *memo.Memo, scanPrivate *memo.ScanPrivate) {
@@ -1393,14 +1415,18 @@ func (it *scanIndexIter) init(mem *memo.Memo, scanPrivate *memo.ScanPrivate) {
 	it.tab = mem.Metadata().Table(scanPrivate.Table)
 	it.indexOrdinal = -1
 	it.index = nil
+	it.filters = nil
+	it.evalCtx = nil
 }
 
 // next advances iteration to the next index of the Scan operator's table. This
 // is the primary index if it's the first time next is called, or a secondary
-// index thereafter. Inverted index are skipped. If the ForceIndex flag is set,
-// then all indexes except the forced index are skipped. When there are no more
-// indexes to enumerate, next returns false. The current index is accessible via
-// the iterator's "index" field.
+// index thereafter. Inverted index are skipped, and so are partial indexes
+// unless the iterator's filters imply their predicate. If the ForceIndex flag
+// is set, then all indexes except the forced index are skipped. If the scan
+// acquires row-level locks, then all indexes except the primary index are
+// skipped. When there are no more indexes to enumerate, next returns false. The
+// current index is accessible via the iterator's "index" field.
 func (it *scanIndexIter) next() bool {
 	for {
 		it.indexOrdinal++
@@ -1412,10 +1438,22 @@ func (it *scanIndexIter) next() bool {
 		if it.index.IsInverted() {
 			continue
 		}
+		if !it.filtersImplyPredicate() {
+			// The partial index doesn't contain all the rows needed by the Scan.
+			continue
+		}
 		if it.scanPrivate.Flags.ForceIndex && it.scanPrivate.Flags.Index != it.indexOrdinal {
 			// If we are forcing a specific index, ignore the others.
 			continue
 		}
+		if it.scanPrivate.Locking != tree.ForNone && it.indexOrdinal != cat.PrimaryIndex {
+			// Row-level locks are acquired on the keys of the primary index row,
+			// which covers all of its column families. A scan of a secondary index
+			// would only return the index keys, and an index join would read the
+			// primary index rows without locking them, so a locking scan is
+			// restricted to the primary index.
+			continue
+		}
 		it.cols = opt.ColSet{}
 		return true
 	}
@@ -1441,11 +1479,31 @@ func (it *scanIndexIter) nextInverted() bool {
 			// If we are forcing a specific index, ignore the others.
 			continue
 		}
+		if it.scanPrivate.Locking != tree.ForNone && it.indexOrdinal != cat.PrimaryIndex {
+			// Row-level locks are acquired on the primary index, so a locking scan
+			// cannot use any other index.
+			continue
+		}
 		it.cols = opt.ColSet{}
 		return true
 	}
 }
 
+// filtersImplyPredicate returns true if the current index is not a partial
+// index, or if the iterator's filters imply the predicate of the partial index.
+// See memo.FiltersImplyPredicate.
+func (it *scanIndexIter) filtersImplyPredicate() bool {
+	if _, isPartial := it.index.Predicate(); !isPartial {
+		return true
+	}
+	tabMeta := it.mem.Metadata().TableMeta(it.scanPrivate.Table)
+	pred, ok := tabMeta.PartialIndexPredicate(it.indexOrdinal)
+	if !ok {
+		return false
+	}
+	return memo.FiltersImplyPredicate(it.mem, it.evalCtx, it.filters, pred)
+}
+
 // indexCols returns the set of columns contained in the current index.
 func (it *scanIndexIter) indexCols() opt.ColSet {
 	if it.cols.Empty() {
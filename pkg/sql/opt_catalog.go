@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 )
 
 // optCatalog implements the cat.Catalog interface over the SchemaResolver
@@ -99,6 +100,13 @@ func (oc *optCatalog) ResolveSchema(ctx context.Context, name *cat.SchemaName) (
 		return nil, pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError,
 			"target database or schema does not exist")
 	}
+	// The optimizer only knows about schemas which have the ID of their
+	// database, so objects in user-defined schemas are left to the heuristic
+	// planner.
+	if isUserSchemaName(oc.tn.Schema()) {
+		return nil, pgerror.Unimplemented(
+			"user-defined schemas", "user-defined schemas are not supported by the optimizer")
+	}
 	*name = oc.tn.TableNamePrefix
 	return &optSchema{desc: desc.(*DatabaseDescriptor)}, nil
 }
@@ -170,7 +178,7 @@ func (oc *optCatalog) newDataSource(
 	// Create wrapper for the data source now.
 	var ds cat.DataSource
 	switch {
-	case desc.IsTable():
+	case desc.IsTable() || desc.MaterializedView():
 		stats, err := oc.statsCache.GetTableStats(context.TODO(), desc.ID)
 		if err != nil {
 			// Ignore any error. We still want to be able to run queries even if we lose
@@ -406,6 +414,11 @@ func (ot *optTable) IsVirtualTable() bool {
 	return ot.desc.IsVirtualTable()
 }
 
+// IsMaterializedView is part of the cat.Table interface.
+func (ot *optTable) IsMaterializedView() bool {
+	return ot.desc.MaterializedView()
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optTable) ColumnCount() int {
 	return len(ot.desc.Columns) + len(ot.mutations)
@@ -428,6 +441,11 @@ func (ot *optTable) IndexCount() int {
 	return 1 + len(ot.desc.Indexes)
 }
 
+// MutationIndexCount is part of the cat.Table interface.
+func (ot *optTable) MutationIndexCount() int {
+	return len(ot.desc.MutationIndexes())
+}
+
 // Index is part of the cat.Table interface.
 func (ot *optTable) Index(i int) cat.Index {
 	// Primary index is always 0th index.
@@ -533,7 +551,7 @@ func (oi *optIndex) init(tab *optTable, desc *sqlbase.IndexDescriptor) {
 		oi.numCols = len(desc.ColumnIDs) + len(desc.ExtraColumnIDs) + len(desc.StoreColumnIDs)
 	}
 
-	if desc.Unique {
+	if desc.HasUniqueKeys() {
 		notNull := true
 		for _, id := range desc.ColumnIDs {
 			ord, _ := tab.lookupColumnOrdinal(id)
@@ -564,9 +582,15 @@ func (oi *optIndex) init(tab *optTable, desc *sqlbase.IndexDescriptor) {
 	}
 
 	if desc.ForeignKey.IsSet() {
-		oi.foreignKey.TableID = cat.StableID(desc.ForeignKey.Table)
-		oi.foreignKey.IndexID = cat.StableID(desc.ForeignKey.Index)
-		oi.foreignKey.PrefixLen = desc.ForeignKey.SharedPrefixLen
+		fk := &desc.ForeignKey
+		oi.foreignKey.TableID = cat.StableID(fk.Table)
+		oi.foreignKey.IndexID = cat.StableID(fk.Index)
+		oi.foreignKey.PrefixLen = fk.SharedPrefixLen
+		oi.foreignKey.Match = sqlbase.ForeignKeyReferenceMatchValue[fk.Match]
+		oi.foreignKey.Name = fk.Name
+		oi.foreignKey.OnDelete = sqlbase.ForeignKeyReferenceActionType[fk.OnDelete]
+		oi.foreignKey.OnUpdate = sqlbase.ForeignKeyReferenceActionType[fk.OnUpdate]
+		oi.foreignKey.Deferrable = fk.Deferrable
 	}
 }
 
@@ -582,7 +606,7 @@ func (oi *optIndex) Name() tree.Name {
 
 // IsUnique is part of the cat.Index interface.
 func (oi *optIndex) IsUnique() bool {
-	return oi.desc.Unique
+	return oi.desc.HasUniqueKeys()
 }
 
 // IsInverted is part of the cat.Index interface.
@@ -590,6 +614,11 @@ func (oi *optIndex) IsInverted() bool {
 	return oi.desc.Type == sqlbase.IndexDescriptor_INVERTED
 }
 
+// Predicate is part of the cat.Index interface.
+func (oi *optIndex) Predicate() (string, bool) {
+	return oi.desc.Predicate, oi.desc.IsPartial()
+}
+
 // ColumnCount is part of the cat.Index interface.
 func (oi *optIndex) ColumnCount() int {
 	return oi.numCols
@@ -631,16 +660,23 @@ func (oi *optIndex) Column(i int) cat.IndexColumn {
 
 // ForeignKey is part of the cat.Index interface.
 func (oi *optIndex) ForeignKey() (cat.ForeignKeyReference, bool) {
-	desc := oi.desc
-	if desc.ForeignKey.IsSet() {
-		oi.foreignKey.TableID = cat.StableID(desc.ForeignKey.Table)
-		oi.foreignKey.IndexID = cat.StableID(desc.ForeignKey.Index)
-		oi.foreignKey.PrefixLen = desc.ForeignKey.SharedPrefixLen
-		oi.foreignKey.Match = sqlbase.ForeignKeyReferenceMatchValue[desc.ForeignKey.Match]
-	}
 	return oi.foreignKey, oi.desc.ForeignKey.IsSet()
 }
 
+// InboundForeignKeyCount is part of the cat.Index interface.
+func (oi *optIndex) InboundForeignKeyCount() int {
+	return len(oi.desc.ReferencedBy)
+}
+
+// InboundForeignKey is part of the cat.Index interface.
+func (oi *optIndex) InboundForeignKey(i int) cat.ForeignKeyReference {
+	ref := &oi.desc.ReferencedBy[i]
+	return cat.ForeignKeyReference{
+		TableID: cat.StableID(ref.Table),
+		IndexID: cat.StableID(ref.Index),
+	}
+}
+
 // Table is part of the cat.Index interface.
 func (oi *optIndex) Table() cat.Table {
 	return oi.tab
@@ -652,6 +688,7 @@ type optTableStat struct {
 	rowCount       uint64
 	distinctCount  uint64
 	nullCount      uint64
+	histogram      []cat.HistogramBucket
 }
 
 var _ cat.TableStatistic = &optTableStat{}
@@ -672,9 +709,41 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 			return false
 		}
 	}
+	if stat.Histogram != nil {
+		os.histogram = decodeHistogram(tab, os.columnOrdinals[0], stat.Histogram)
+	}
 	return true
 }
 
+// decodeHistogram converts the histogram of a statistic to the format used by
+// the optimizer. It returns nil if the histogram can't be used, which is the
+// case if the type of the column has changed since the statistic was
+// calculated.
+func decodeHistogram(tab *optTable, ord int, h *stats.HistogramData) []cat.HistogramBucket {
+	if len(h.Buckets) == 0 {
+		return nil
+	}
+	typ := h.ColumnType.ToDatumType()
+	if !typ.Equivalent(tab.Column(ord).DatumType()) {
+		return nil
+	}
+	var a sqlbase.DatumAlloc
+	res := make([]cat.HistogramBucket, len(h.Buckets))
+	for i := range h.Buckets {
+		b := &h.Buckets[i]
+		datum, _, err := sqlbase.DecodeTableKey(&a, typ, b.UpperBound, encoding.Ascending)
+		if err != nil {
+			return nil
+		}
+		res[i] = cat.HistogramBucket{
+			NumEq:      float64(b.NumEq),
+			NumRange:   float64(b.NumRange),
+			UpperBound: datum,
+		}
+	}
+	return res
+}
+
 func (os *optTableStat) equals(other *optTableStat) bool {
 	// Two table statistics are considered equal if they have been created at the
 	// same time, on the same set of columns.
@@ -718,3 +787,8 @@ func (os *optTableStat) DistinctCount() uint64 {
 func (os *optTableStat) NullCount() uint64 {
 	return os.nullCount
 }
+
+// Histogram is part of the cat.TableStatistic interface.
+func (os *optTableStat) Histogram() []cat.HistogramBucket {
+	return os.histogram
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
@@ -71,7 +72,14 @@ func (ef *execFactory) ConstructScan(
 	reverse bool,
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
+	waitPolicy tree.LockingWaitPolicy,
 ) (exec.Node, error) {
+	if locking != tree.ForNone &&
+		!ef.planner.ExecCfg().Settings.Version.IsActive(cluster.VersionRowLocking) {
+		return nil, fmt.Errorf("cluster version does not support row-level locking (required: %s)",
+			cluster.VersionByKey(cluster.VersionRowLocking))
+	}
 	tabDesc := table.(*optTable).desc
 	indexDesc := index.(*optIndex).desc
 	// Create a scanNode.
@@ -97,6 +105,8 @@ func (ef *execFactory) ConstructScan(
 	scan.hardLimit = hardLimit
 	scan.reverse = reverse
 	scan.maxResults = maxResults
+	scan.lockingStrength = locking
+	scan.lockingWaitPolicy = waitPolicy
 	scan.parallelScansEnabled = sqlbase.ParallelScans.Get(&ef.planner.extendedEvalCtx.Settings.SV)
 	var err error
 	scan.spans, err = spansFromConstraint(
@@ -285,6 +295,38 @@ func (ef *execFactory) ConstructHashJoin(
 	return p.makeJoinNode(leftSrc, rightSrc, pred), nil
 }
 
+// ConstructApplyJoin is part of the exec.Factory interface.
+func (ef *execFactory) ConstructApplyJoin(
+	joinType sqlbase.JoinType,
+	left exec.Node,
+	rightColumns sqlbase.ResultColumns,
+	onCond tree.TypedExpr,
+	planRightSideFn exec.ApplyJoinPlanRightSideFn,
+) (exec.Node, error) {
+	leftPlan := left.(planNode)
+	leftCols := planColumns(leftPlan)
+	n := &applyJoinNode{
+		joinType:        joinType,
+		input:           leftPlan,
+		planRightSideFn: planRightSideFn,
+		leftCols:        leftCols,
+		rightCols:       rightColumns,
+	}
+	n.ivarHelper = tree.MakeIndexedVarHelper(n, len(leftCols)+len(rightColumns))
+	if onCond != nil {
+		n.onCond = n.ivarHelper.Rebind(onCond, false /* alsoReset */, false /* normalizeToNonNil */)
+	}
+	switch joinType {
+	case sqlbase.InnerJoin, sqlbase.LeftOuterJoin:
+		n.columns = append(leftCols[:len(leftCols):len(leftCols)], rightColumns...)
+	case sqlbase.LeftSemiJoin, sqlbase.LeftAntiJoin:
+		n.columns = leftCols
+	default:
+		return nil, errors.Errorf("unsupported apply join type %s", joinType)
+	}
+	return n, nil
+}
+
 // ConstructMergeJoin is part of the exec.Factory interface.
 func (ef *execFactory) ConstructMergeJoin(
 	joinType sqlbase.JoinType,
@@ -400,6 +442,57 @@ func (ef *execFactory) ConstructGroupBy(
 	return n, nil
 }
 
+// ConstructGroupingSets is part of the exec.Factory interface.
+func (ef *execFactory) ConstructGroupingSets(
+	input exec.Node,
+	groupCols []exec.ColumnOrdinal,
+	groupingSets []exec.ColumnOrdinalSet,
+	aggregations []exec.AggInfo,
+) (exec.Node, error) {
+	n := &groupNode{
+		plan:         input.(planNode),
+		funcs:        make([]*aggregateFuncHolder, 0, len(groupCols)+len(aggregations)+1),
+		columns:      make(sqlbase.ResultColumns, 0, len(groupCols)+len(aggregations)+1),
+		groupCols:    make([]int, len(groupCols)),
+		groupingSets: make([]util.FastIntSet, len(groupingSets)),
+		isScalar:     false,
+	}
+	inputCols := planColumns(n.plan)
+	for i := range groupCols {
+		col := int(groupCols[i])
+		n.groupCols[i] = col
+		f := n.newAggregateFuncHolder(
+			builtins.AnyNotNull,
+			inputCols[col].Typ,
+			col,
+			builtins.NewAnyNotNullAggregate,
+			nil, /* arguments */
+			ef.planner.EvalContext().Mon.MakeBoundAccount(),
+		)
+		n.funcs = append(n.funcs, f)
+		n.columns = append(n.columns, inputCols[col])
+	}
+	for i := range groupingSets {
+		n.groupingSets[i] = groupingSets[i].Copy()
+	}
+	if err := ef.addAggregations(n, aggregations); err != nil {
+		return nil, err
+	}
+
+	// The last column is the ordinal of the grouping set.
+	f := n.newAggregateFuncHolder(
+		builtins.AnyNotNull,
+		types.Int,
+		n.groupingSetRenderIdx(),
+		builtins.NewAnyNotNullAggregate,
+		nil, /* arguments */
+		ef.planner.EvalContext().Mon.MakeBoundAccount(),
+	)
+	n.funcs = append(n.funcs, f)
+	n.columns = append(n.columns, sqlbase.ResultColumn{Name: "grouping_set_id", Typ: types.Int})
+	return n, nil
+}
+
 func (ef *execFactory) addAggregations(n *groupNode, aggregations []exec.AggInfo) error {
 	inputCols := planColumns(n.plan)
 	for i := range aggregations {
@@ -438,6 +531,9 @@ func (ef *execFactory) addAggregations(n *groupNode, aggregations []exec.AggInfo
 		if agg.Distinct {
 			f.setDistinct()
 		}
+		if agg.Filter != -1 {
+			f.setFilter(int(agg.Filter))
+		}
 		n.funcs = append(n.funcs, f)
 		n.columns = append(n.columns, sqlbase.ResultColumn{
 			Name: fmt.Sprintf("agg%d", i),
@@ -589,6 +685,12 @@ func (ef *execFactory) ConstructLookupJoin(
 		n.keyCols[i] = int(c)
 	}
 	inputCols := planColumns(input.(planNode))
+	if joinType == sqlbase.LeftSemiJoin || joinType == sqlbase.LeftAntiJoin {
+		// Semi and anti joins only produce the input columns; the scanNode
+		// columns can still be referenced by the ON condition.
+		n.columns = inputCols
+		return n, nil
+	}
 	scanCols := planColumns(tableScan)
 	n.columns = make(sqlbase.ResultColumns, 0, len(inputCols)+len(scanCols))
 	n.columns = append(n.columns, inputCols...)
@@ -763,12 +865,71 @@ func (ef *execFactory) ConstructProjectSet(
 	return p, nil
 }
 
+// ConstructWindow is part of the exec.Factory interface.
+func (ef *execFactory) ConstructWindow(
+	n exec.Node, wi exec.WindowInfo,
+) (exec.Node, error) {
+	src := asDataSource(n)
+	p := &windowNode{
+		plan: src.plan,
+		// The renders of the window functions are followed by nil renders for
+		// the columns that are passed through.
+		windowRender: make([]tree.TypedExpr, len(wi.Cols)),
+		funcs:        make([]*windowFuncHolder, len(wi.Exprs)),
+		run: windowRun{
+			values:       valuesNode{columns: wi.Cols},
+			windowFrames: make([]*tree.WindowFrame, len(wi.Exprs)),
+		},
+	}
+
+	partitionIdxs := make([]int, len(wi.Partition))
+	for i, idx := range wi.Partition {
+		partitionIdxs[i] = int(idx)
+	}
+
+	// The arguments of the window functions are the first input columns, in
+	// function order.
+	argIdxStart := 0
+	for i, expr := range wi.Exprs {
+		holder := &windowFuncHolder{
+			window:         p,
+			expr:           expr,
+			args:           expr.Exprs,
+			funcIdx:        i,
+			argIdxStart:    argIdxStart,
+			argCount:       len(expr.Exprs),
+			filterColIdx:   noFilterIdx,
+			partitionIdxs:  partitionIdxs,
+			columnOrdering: wi.Ordering,
+		}
+		argIdxStart += holder.argCount
+		p.funcs[i] = holder
+		p.windowRender[i] = holder
+		if expr.WindowDef != nil {
+			p.run.windowFrames[i] = expr.WindowDef.Frame
+		}
+	}
+
+	p.colAndAggContainer = makeWindowNodeColAndAggContainer(
+		&p.run, src.info, len(src.info.SourceColumns),
+	)
+	p.run.wrappedRenderVals = sqlbase.NewRowContainer(
+		ef.planner.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(src.info.SourceColumns),
+		0, /* rowCapacity */
+	)
+
+	return p, nil
+}
+
 // ConstructPlan is part of the exec.Factory interface.
 func (ef *execFactory) ConstructPlan(
-	root exec.Node, subqueries []exec.Subquery,
+	root exec.Node, subqueries []exec.Subquery, postqueries []exec.Node,
 ) (exec.Plan, error) {
-	// Enable auto-commit if the planner setting allows it.
-	if ef.planner.autoCommit {
+	// Enable auto-commit if the planner setting allows it. The postqueries run
+	// after the root node in the same transaction, so auto-commit is not
+	// possible if there are any.
+	if ef.planner.autoCommit && len(postqueries) == 0 {
 		if ac, ok := root.(autoCommitNode); ok {
 			ac.enableAutoCommit()
 		}
@@ -803,6 +964,12 @@ func (ef *execFactory) ConstructPlan(
 			out.plan = in.Root.(planNode)
 		}
 	}
+	if len(postqueries) > 0 {
+		res.postqueryPlans = make([]planNode, len(postqueries))
+		for i := range postqueries {
+			res.postqueryPlans[i] = postqueries[i].(planNode)
+		}
+	}
 	return res, nil
 }
 
@@ -837,6 +1004,7 @@ func (ef *execFactory) ConstructExplain(
 			false, /* optimizeSubqueries */
 			p.plan,
 			p.subqueryPlans,
+			p.postqueryPlans,
 		)
 
 	default:
@@ -867,31 +1035,33 @@ func (ef *execFactory) ConstructShowTrace(typ tree.ShowTraceType, compact bool)
 }
 
 func (ef *execFactory) ConstructInsert(
-	input exec.Node, table cat.Table, insertCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	insertCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	// Derive insert table and column descriptors.
 	tabDesc := table.(*optTable).desc
 	colDescs := makeColDescList(table, insertCols)
 
 	// Determine the foreign key tables involved in the update.
-	fkTables, err := row.TablesNeededForFKs(
-		ef.planner.extendedEvalCtx.Context,
-		tabDesc,
-		row.CheckInserts,
-		ef.planner.LookupTableByID,
-		ef.planner.CheckPrivilege,
-		ef.planner.analyzeExpr,
-	)
+	fkTables, err := ef.tablesNeededForFKs(tabDesc, row.CheckInserts, skipFKChecks)
 	if err != nil {
 		return nil, err
 	}
+	checkFKs := row.CheckFKs
+	if skipFKChecks {
+		checkFKs = row.SkipFKs
+	}
 
 	// Create the table insert, which does the bulk of the work.
 	ri, err := row.MakeInserter(ef.planner.txn, tabDesc, fkTables, colDescs,
-		row.CheckFKs, &ef.planner.alloc)
+		checkFKs, ef.planner.EvalContext(), &ef.planner.alloc)
 	if err != nil {
 		return nil, err
 	}
+	ri.SetDeferredChecks(ef.planner.deferredChecks())
 
 	// Determine the relational type of the generated insert node.
 	// If rows are not needed, no columns are returned.
@@ -932,7 +1102,12 @@ func (ef *execFactory) ConstructInsert(
 }
 
 func (ef *execFactory) ConstructUpdate(
-	input exec.Node, table cat.Table, fetchCols, updateCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	updateCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
 	tabDesc := table.(*optTable).desc
@@ -948,17 +1123,14 @@ func (ef *execFactory) ConstructUpdate(
 	}
 
 	// Determine the foreign key tables involved in the update.
-	fkTables, err := row.TablesNeededForFKs(
-		ef.planner.extendedEvalCtx.Context,
-		tabDesc,
-		row.CheckUpdates,
-		ef.planner.LookupTableByID,
-		ef.planner.CheckPrivilege,
-		ef.planner.analyzeExpr,
-	)
+	fkTables, err := ef.tablesNeededForFKs(tabDesc, row.CheckUpdates, skipFKChecks)
 	if err != nil {
 		return nil, err
 	}
+	checkFKs := row.CheckFKs
+	if skipFKChecks {
+		checkFKs = row.SkipFKs
+	}
 
 	// Create the table updater, which does the bulk of the work. In the HP,
 	// the updater derives the columns that need to be fetched. By contrast, the
@@ -971,12 +1143,14 @@ func (ef *execFactory) ConstructUpdate(
 		updateColDescs,
 		fetchColDescs,
 		row.UpdaterDefault,
+		checkFKs,
 		ef.planner.EvalContext(),
 		&ef.planner.alloc,
 	)
 	if err != nil {
 		return nil, err
 	}
+	ru.SetDeferredChecks(ef.planner.deferredChecks())
 
 	// Determine the relational type of the generated update node.
 	// If rows are not needed, no columns are returned.
@@ -1032,6 +1206,7 @@ func (ef *execFactory) ConstructUpsert(
 	insertCols exec.ColumnOrdinalSet,
 	fetchCols exec.ColumnOrdinalSet,
 	updateCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
 	rowsNeeded bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
@@ -1049,24 +1224,22 @@ func (ef *execFactory) ConstructUpsert(
 	}
 
 	// Determine the foreign key tables involved in the upsert.
-	fkTables, err := row.TablesNeededForFKs(
-		ef.planner.extendedEvalCtx.Context,
-		tabDesc,
-		fkCheckType,
-		ef.planner.LookupTableByID,
-		ef.planner.CheckPrivilege,
-		ef.planner.analyzeExpr,
-	)
+	fkTables, err := ef.tablesNeededForFKs(tabDesc, fkCheckType, skipFKChecks)
 	if err != nil {
 		return nil, err
 	}
+	checkFKs := row.CheckFKs
+	if skipFKChecks {
+		checkFKs = row.SkipFKs
+	}
 
 	// Create the table inserter, which does the bulk of the insert-related work.
 	ri, err := row.MakeInserter(ef.planner.txn, tabDesc, fkTables, insertColDescs,
-		row.CheckFKs, &ef.planner.alloc)
+		checkFKs, ef.planner.EvalContext(), &ef.planner.alloc)
 	if err != nil {
 		return nil, err
 	}
+	ri.SetDeferredChecks(ef.planner.deferredChecks())
 
 	// Create the table updater, which does the bulk of the update-related work.
 	// In the HP, the updater derives the columns that need to be fetched. By
@@ -1080,12 +1253,14 @@ func (ef *execFactory) ConstructUpsert(
 		updateColDescs,
 		fetchColDescs,
 		row.UpdaterDefault,
+		checkFKs,
 		ef.planner.EvalContext(),
 		&ef.planner.alloc,
 	)
 	if err != nil {
 		return nil, err
 	}
+	ru.SetDeferredChecks(ef.planner.deferredChecks())
 
 	// Determine the relational type of the generated upsert node.
 	// If rows are not needed, no columns are returned.
@@ -1123,6 +1298,7 @@ func (ef *execFactory) ConstructUpsert(
 				},
 				canaryOrdinal: int(canaryCol),
 				fkTables:      fkTables,
+				skipFKs:       skipFKChecks,
 				fetchCols:     fetchColDescs,
 				updateCols:    updateColDescs,
 				ru:            ru,
@@ -1142,25 +1318,78 @@ func (ef *execFactory) ConstructUpsert(
 	return &rowCountNode{source: ups}, nil
 }
 
+func (ef *execFactory) ConstructFastUpsert(
+	input exec.Node, table cat.Table, insertCols exec.ColumnOrdinalSet, skipFKChecks bool,
+) (exec.Node, error) {
+	// Derive table and column descriptors.
+	tabDesc := table.(*optTable).desc
+	insertColDescs := makeColDescList(table, insertCols)
+
+	// Determine the foreign key tables involved in the upsert. Since the primary
+	// key of an existing row is never changed, and since the table has no other
+	// indexes, only the inserted values need to be checked.
+	fkTables, err := ef.tablesNeededForFKs(tabDesc, row.CheckInserts, skipFKChecks)
+	if err != nil {
+		return nil, err
+	}
+	checkFKs := row.CheckFKs
+	if skipFKChecks {
+		checkFKs = row.SkipFKs
+	}
+
+	// Create the table inserter, which writes the rows without checking for
+	// conflicts.
+	ri, err := row.MakeInserter(ef.planner.txn, tabDesc, fkTables, insertColDescs,
+		checkFKs, ef.planner.EvalContext(), &ef.planner.alloc)
+	if err != nil {
+		return nil, err
+	}
+	ri.SetDeferredChecks(ef.planner.deferredChecks())
+
+	// Instantiate the upsert node.
+	ups := upsertNodePool.Get().(*upsertNode)
+	*ups = upsertNode{
+		source: input.(planNode),
+		run: upsertRun{
+			checkHelper: fkTables[tabDesc.ID].CheckHelper,
+			insertCols:  insertColDescs,
+			iVarContainerForComputedCols: sqlbase.RowIndexedVarContainer{
+				Cols:    tabDesc.Columns,
+				Mapping: ri.InsertColIDtoRowIndex,
+			},
+			tw: &fastTableUpserter{
+				tableUpserterBase: tableUpserterBase{
+					ri: ri,
+				},
+			},
+		},
+	}
+
+	// The fast path never returns rows, so use rowCountNode. See the comment in
+	// ConstructUpsert.
+	return &rowCountNode{source: ups}, nil
+}
+
 func (ef *execFactory) ConstructDelete(
-	input exec.Node, table cat.Table, fetchCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	skipFKChecks bool,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
 	tabDesc := table.(*optTable).desc
 	fetchColDescs := makeColDescList(table, fetchCols)
 
 	// Determine the foreign key tables involved in the update.
-	fkTables, err := row.TablesNeededForFKs(
-		ef.planner.extendedEvalCtx.Context,
-		tabDesc,
-		row.CheckDeletes,
-		ef.planner.LookupTableByID,
-		ef.planner.CheckPrivilege,
-		ef.planner.analyzeExpr,
-	)
+	fkTables, err := ef.tablesNeededForFKs(tabDesc, row.CheckDeletes, skipFKChecks)
 	if err != nil {
 		return nil, err
 	}
+	checkFKs := row.CheckFKs
+	if skipFKChecks {
+		checkFKs = row.SkipFKs
+	}
 
 	// Create the table deleter, which does the bulk of the work. In the HP,
 	// the deleter derives the columns that need to be fetched. By contrast, the
@@ -1171,13 +1400,14 @@ func (ef *execFactory) ConstructDelete(
 		tabDesc,
 		fkTables,
 		fetchColDescs,
-		row.CheckFKs,
+		checkFKs,
 		ef.planner.EvalContext(),
 		&ef.planner.alloc,
 	)
 	if err != nil {
 		return nil, err
 	}
+	rd.SetDeferredChecks(ef.planner.deferredChecks())
 
 	// Determine the relational type of the generated delete node.
 	// If rows are not needed, no columns are returned.
@@ -1211,6 +1441,71 @@ func (ef *execFactory) ConstructDelete(
 	return &rowCountNode{source: del}, nil
 }
 
+// ConstructDeleteRange is part of the exec.Factory interface.
+func (ef *execFactory) ConstructDeleteRange(
+	table cat.Table, fetchCols exec.ColumnOrdinalSet, indexConstraint *constraint.Constraint,
+) (exec.Node, error) {
+	tabDesc := table.(*optTable).desc
+
+	// The delete node determines the ranges to delete from the spans of a scan
+	// of the primary index. If the fast path turns out not to be available when
+	// the delete node runs, it falls back on reading the fetch columns from the
+	// scan.
+	input, err := ef.ConstructScan(
+		table,
+		table.Index(cat.PrimaryIndex),
+		fetchCols,
+		indexConstraint,
+		0,     /* hardLimit */
+		false, /* reverse */
+		0,     /* maxResults */
+		nil,   /* reqOrdering */
+		tree.ForNone,
+		tree.LockWaitBlock,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the scan as the source of a delete, so that its spans include all
+	// the column families of the rows, even if only some of them are fetched.
+	// Otherwise, a single row delete would leave some of its families behind.
+	if scan, ok := input.(*scanNode); ok {
+		scan.isDeleteSource = true
+		scan.spans, err = spansFromConstraint(
+			tabDesc, scan.index, indexConstraint, fetchCols, true /* forDelete */)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ef.ConstructDelete(
+		input, table, fetchCols, false /* skipFKChecks */, false, /* rowsNeeded */
+	)
+}
+
+// tablesNeededForFKs returns the tables that the row writers of a mutation
+// need in order to check foreign keys and run cascades. If skipFKChecks is
+// true, the checks and cascades were planned by the optimizer, and the row
+// writers only need the mutated table itself.
+func (ef *execFactory) tablesNeededForFKs(
+	tabDesc *sqlbase.ImmutableTableDescriptor, usage row.FKCheck, skipFKChecks bool,
+) (row.TableLookupsByID, error) {
+	if skipFKChecks {
+		return row.TablesNeededWithoutFKs(
+			ef.planner.extendedEvalCtx.Context, tabDesc, ef.planner.analyzeExpr,
+		)
+	}
+	return row.TablesNeededForFKs(
+		ef.planner.extendedEvalCtx.Context,
+		tabDesc,
+		usage,
+		ef.planner.LookupTableByID,
+		ef.planner.CheckPrivilege,
+		ef.planner.analyzeExpr,
+	)
+}
+
 func (ef *execFactory) ConstructCreateTable(
 	input exec.Node, schema cat.Schema, ct *tree.CreateTable,
 ) (exec.Node, error) {
@@ -1221,6 +1516,46 @@ func (ef *execFactory) ConstructCreateTable(
 	return nd, nil
 }
 
+// ConstructRecursiveCTE is part of the exec.Factory interface.
+func (ef *execFactory) ConstructRecursiveCTE(
+	initial exec.Node, fn exec.RecursiveCTEIterationFn, label string, deduplicate bool,
+) (exec.Node, error) {
+	return &recursiveCTENode{
+		initial:        initial.(planNode),
+		genIterationFn: fn,
+		label:          label,
+		deduplicate:    deduplicate,
+	}, nil
+}
+
+// ConstructScanBuffer is part of the exec.Factory interface.
+func (ef *execFactory) ConstructScanBuffer(ref exec.Node, label string) (exec.Node, error) {
+	buffer := ref.(rowBuffer)
+	return &scanBufferNode{
+		buffer:  buffer,
+		label:   label,
+		columns: planColumns(buffer),
+	}, nil
+}
+
+// ConstructBuffer is part of the exec.Factory interface.
+func (ef *execFactory) ConstructBuffer(input exec.Node, label string) (exec.Node, error) {
+	return &bufferNode{
+		plan:  input.(planNode),
+		label: label,
+	}, nil
+}
+
+// ConstructErrorIfRows is part of the exec.Factory interface.
+func (ef *execFactory) ConstructErrorIfRows(
+	input exec.Node, mkErr func(tree.Datums) error,
+) (exec.Node, error) {
+	return &errorIfRowsNode{
+		plan:  input.(planNode),
+		mkErr: mkErr,
+	}, nil
+}
+
 // renderBuilder encapsulates the code to build a renderNode.
 type renderBuilder struct {
 	r   *renderNode
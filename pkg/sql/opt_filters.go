@@ -249,6 +249,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *recursiveCTENode:
+		if n.initial, err = p.triggerFilterPropagation(ctx, n.initial); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *windowNode:
 		if n.plan, err = p.triggerFilterPropagation(ctx, n.plan); err != nil {
 			return plan, extraFilter, err
@@ -271,6 +276,13 @@ func (p *planner) propagateFilters(
 			}
 		}
 
+	case *createViewNode:
+		if n.sourcePlan != nil {
+			if n.sourcePlan, err = p.triggerFilterPropagation(ctx, n.sourcePlan); err != nil {
+				return plan, extraFilter, err
+			}
+		}
+
 	case *deleteNode:
 		if n.source, err = p.triggerFilterPropagation(ctx, n.source); err != nil {
 			return plan, extraFilter, err
@@ -364,11 +376,13 @@ func (p *planner) propagateFilters(
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *commentOnColumnNode:
@@ -377,10 +391,12 @@ func (p *planner) propagateFilters(
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *CreateUserNode:
-	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
@@ -389,9 +405,11 @@ func (p *planner) propagateFilters(
 	case *hookFnNode:
 	case *valuesNode:
 	case *virtualTableNode:
+	case *scanBufferNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setZoneConfigNode:
 	case *showZoneConfigNode:
 	case *showFingerprintsNode:
@@ -122,6 +122,10 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			if s.desc.Indexes[i].IsPartial() {
+				// Partial indexes don't contain all the rows of the table.
+				continue
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  s.desc,
 				index: &s.desc.Indexes[i],
@@ -305,7 +309,7 @@ func calculateMaxResults(
 	if c == nil || c.IsContradiction() || c.IsUnconstrained() {
 		return 0
 	}
-	if !i.Unique {
+	if !i.HasUniqueKeys() {
 		return 0
 	}
 
@@ -467,7 +471,7 @@ func (v *indexInfo) makeIndexConstraints(
 	isInverted := (v.index.Type == sqlbase.IndexDescriptor_INVERTED)
 	// TODO(radu): we currently don't support index constraints on PK
 	// columns on an inverted index.
-	if !isInverted && !v.index.Unique {
+	if !isInverted && !v.index.HasUniqueKeys() {
 		// We have a non-unique index; the extra columns are added to the key and we
 		// can use them for index constraints.
 		numExtraCols = len(v.index.ExtraColumnIDs)
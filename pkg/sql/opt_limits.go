@@ -127,6 +127,9 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *max1RowNode:
 		p.setUnlimited(n.plan)
 
+	case *recursiveCTENode:
+		p.setUnlimited(n.initial)
+
 	case *joinNode:
 		p.setUnlimited(n.left.plan)
 		p.setUnlimited(n.right.plan)
@@ -176,6 +179,10 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		if n.sourcePlan != nil {
 			p.applyLimit(n.sourcePlan, numRows, soft)
 		}
+	case *createViewNode:
+		if n.sourcePlan != nil {
+			p.setUnlimited(n.sourcePlan)
+		}
 	case *explainDistSQLNode:
 		// EXPLAIN ANALYZE is special: it handles its own limit propagation, since
 		// it fully executes during startExec.
@@ -209,11 +216,13 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *commentOnColumnNode:
@@ -222,21 +231,25 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *CreateUserNode:
-	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
 	case *DropUserNode:
 	case *zeroNode:
+	case *scanBufferNode:
 	case *unaryNode:
 	case *hookFnNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setZoneConfigNode:
 	case *showZoneConfigNode:
 	case *showFingerprintsNode:
@@ -31,6 +31,11 @@ func setNeededColumns(plan planNode, needed []bool) {
 			setNeededColumns(n.sourcePlan, allColumns(n.sourcePlan))
 		}
 
+	case *createViewNode:
+		if n.sourcePlan != nil {
+			setNeededColumns(n.sourcePlan, allColumns(n.sourcePlan))
+		}
+
 	case *explainDistSQLNode:
 		setNeededColumns(n.plan, allColumns(n.plan))
 
@@ -48,6 +53,11 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *max1RowNode:
 		setNeededColumns(n.plan, needed)
 
+	case *recursiveCTENode:
+		// All the columns of the initial query are needed, since its rows make
+		// up the first working table.
+		setNeededColumns(n.initial, allColumns(n.initial))
+
 	case *spoolNode:
 		setNeededColumns(n.source, needed)
 
@@ -257,11 +267,13 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *commentOnColumnNode:
@@ -270,21 +282,25 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *CreateUserNode:
-	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
 	case *DropUserNode:
 	case *zeroNode:
+	case *scanBufferNode:
 	case *unaryNode:
 	case *hookFnNode:
 	case *sequenceSelectNode:
 	case *setVarNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setZoneConfigNode:
 	case *showZoneConfigNode:
 	case *showFingerprintsNode:
@@ -67,6 +67,9 @@ func TestContextualHelp(t *testing.T) {
 		{`ALTER SEQUENCE blah RENAME ??`, `ALTER SEQUENCE`},
 		{`ALTER SEQUENCE blah RENAME TO blih ??`, `ALTER SEQUENCE`},
 
+		{`ALTER TYPE ??`, `ALTER TYPE`},
+		{`ALTER TYPE blah ADD ??`, `ALTER TYPE`},
+
 		{`ALTER USER IF ??`, `ALTER USER`},
 		{`ALTER USER foo WITH PASSWORD ??`, `ALTER USER`},
 
@@ -110,11 +113,18 @@ func TestContextualHelp(t *testing.T) {
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
 		{`CREATE VIEW blah AS (??`, `<SELECTCLAUSE>`},
+		{`CREATE MATERIALIZED VIEW blah (??`, `CREATE VIEW`},
 
 		{`CREATE SEQUENCE ??`, `CREATE SEQUENCE`},
 
 		{`CREATE STATISTICS ??`, `CREATE STATISTICS`},
 
+		{`CREATE SCHEMA ??`, `CREATE SCHEMA`},
+		{`CREATE SCHEMA IF NOT ??`, `CREATE SCHEMA`},
+
+		{`CREATE TYPE ??`, `CREATE TYPE`},
+		{`CREATE TYPE blah AS ENUM (??`, `CREATE TYPE`},
+
 		{`CREATE TABLE blah (??`, `CREATE TABLE`},
 		{`CREATE TABLE IF NOT ??`, `CREATE TABLE`},
 		{`CREATE TABLE blah (x, y) AS ??`, `CREATE TABLE`},
@@ -136,6 +146,9 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP DATABASE IF ??`, `DROP DATABASE`},
 		{`DROP DATABASE IF EXISTS blah ??`, `DROP DATABASE`},
 
+		{`DROP SCHEMA ??`, `DROP SCHEMA`},
+		{`DROP SCHEMA IF EXISTS blah, ??`, `DROP SCHEMA`},
+
 		{`DROP INDEX blah, ??`, `DROP INDEX`},
 		{`DROP INDEX blah@blih ??`, `DROP INDEX`},
 
@@ -154,6 +167,7 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
+		{`DROP MATERIALIZED VIEW blah ??`, `DROP VIEW`},
 
 		{`DROP USER ??`, `DROP USER`},
 		{`DROP USER IF ??`, `DROP USER`},
@@ -325,6 +339,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SET TRANSACTION ??`, `SET TRANSACTION`},
 		{`SET TRANSACTION ISOLATION LEVEL SNAPSHOT ??`, `SET TRANSACTION`},
+		{`SET CONSTRAINTS ??`, `SET CONSTRAINTS`},
+		{`SET CONSTRAINTS ALL ??`, `SET CONSTRAINTS`},
 		{`SET TIME ??`, `SET SESSION`},
 		{`SET TIME ZONE 'UTC' ??`, `SET SESSION`},
 		{`SET blah TO ??`, `SET SESSION`},
@@ -335,6 +351,9 @@ func TestContextualHelp(t *testing.T) {
 
 		{`USE ??`, `USE`},
 
+		{`REFRESH ??`, `REFRESH`},
+		{`REFRESH MATERIALIZED VIEW blah ??`, `REFRESH`},
+
 		{`RESET blah ??`, `RESET`},
 		{`RESET SESSION ??`, `RESET`},
 		{`RESET CLUSTER SETTING ??`, `RESET CLUSTER SETTING`},
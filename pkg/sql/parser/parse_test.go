@@ -76,6 +76,10 @@ func TestParse(t *testing.T) {
 		{`CREATE DATABASE IF NOT EXISTS a LC_CTYPE = 'INVALID'`},
 		{`CREATE DATABASE IF NOT EXISTS a TEMPLATE = 'template0' ENCODING = 'UTF8' LC_COLLATE = 'C.UTF-8' LC_CTYPE = 'INVALID'`},
 
+		{`CREATE SCHEMA a`},
+		{`EXPLAIN CREATE SCHEMA a`},
+		{`CREATE SCHEMA IF NOT EXISTS a`},
+
 		{`CREATE INDEX a ON b (c)`},
 		{`EXPLAIN CREATE INDEX a ON b (c)`},
 		{`CREATE INDEX a ON b.c (d)`},
@@ -84,6 +88,9 @@ func TestParse(t *testing.T) {
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c (d)`},
 		{`CREATE INDEX ON a (b) INTERLEAVE IN PARENT c.d (e)`},
 		{`CREATE INDEX ON a (b ASC, c DESC)`},
+		{`CREATE INDEX ON a (b) WHERE c > 0`},
+		{`CREATE UNIQUE INDEX a ON b (c) STORING (d) WHERE (e IS NOT NULL) AND (f = 'g')`},
+		{`CREATE INDEX IF NOT EXISTS a ON b (c) WHERE d`},
 		{`CREATE UNIQUE INDEX a ON b (c)`},
 		{`CREATE UNIQUE INDEX a ON b (c) STORING (d)`},
 		{`CREATE UNIQUE INDEX a ON b (c) INTERLEAVE IN PARENT d (e, f)`},
@@ -97,6 +104,10 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a ()`},
 		{`EXPLAIN CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT8)`},
+		{`CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a (b INT8)`},
+		{`CREATE TEMPORARY SEQUENCE a`},
+		{`CREATE TEMPORARY VIEW a AS SELECT b`},
 		{`CREATE TABLE a (b INT8, c INT8)`},
 		{`CREATE TABLE a (b CHAR)`},
 		{`CREATE TABLE a (b CHAR(3))`},
@@ -161,18 +172,24 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON UPDATE SET NULL)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT)`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT s FOREIGN KEY (b, c) REFERENCES other (x, y) MATCH FULL ON DELETE SET DEFAULT ON UPDATE SET NULL)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c STRING, FOREIGN KEY (b) REFERENCES other ON DELETE CASCADE DEFERRABLE INITIALLY DEFERRED)`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX d (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c))`},
 		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c) INTERLEAVE IN PARENT d (e, f))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b))`},
 		{`CREATE TABLE a (b INT8, UNIQUE (b) STORING (c))`},
+		{`CREATE TABLE a (b INT8, UNIQUE (b) DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b, c) WHERE b > 0 DEFERRABLE INITIALLY DEFERRED)`},
 		{`CREATE TABLE a (b INT8, INDEX (b))`},
 		{`CREATE TABLE a (b INT8, INVERTED INDEX (b))`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON UPDATE RESTRICT)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON DELETE RESTRICT)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON DELETE RESTRICT ON UPDATE RESTRICT)`},
+		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo DEFERRABLE)`},
+		{`CREATE TABLE a (b INT8, c INT8 CONSTRAINT d REFERENCES foo DEFERRABLE INITIALLY DEFERRED)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON UPDATE CASCADE)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON DELETE CASCADE)`},
 		{`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON DELETE CASCADE ON UPDATE CASCADE)`},
@@ -198,6 +215,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT8, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
+		{`CREATE TABLE a (b INT8, INDEX (b) WHERE b > 0)`},
+		{`CREATE TABLE a (b INT8, c STRING, CONSTRAINT d UNIQUE (b) WHERE c IS NOT NULL)`},
 		{`CREATE TABLE a (b INT8, FAMILY (b))`},
 		{`CREATE TABLE a (b INT8, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT8) INTERLEAVE IN PARENT foo (c, d)`},
@@ -240,6 +259,8 @@ func TestParse(t *testing.T) {
 
 		{`CREATE TABLE a AS SELECT * FROM b`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY TABLE a AS SELECT * FROM b`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a AS SELECT * FROM b`},
 		{`CREATE TABLE a AS SELECT * FROM b ORDER BY c`},
 		{`CREATE TABLE IF NOT EXISTS a AS SELECT * FROM b ORDER BY c`},
 		{`CREATE TABLE a AS SELECT * FROM b LIMIT 3`},
@@ -265,6 +286,24 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`EXPLAIN CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW a.b`},
+		{`REFRESH MATERIALIZED VIEW CONCURRENTLY a`},
+		{`EXPLAIN REFRESH MATERIALIZED VIEW a`},
+
+		{`CREATE TYPE a AS ENUM ()`},
+		{`CREATE TYPE a AS ENUM ('b')`},
+		{`CREATE TYPE a.b AS ENUM ('c', 'd')`},
+		{`ALTER TYPE a ADD VALUE 'b'`},
+		{`ALTER TYPE a ADD VALUE IF NOT EXISTS 'b'`},
+		{`ALTER TYPE a ADD VALUE 'b' BEFORE 'c'`},
+		{`ALTER TYPE a ADD VALUE 'b' AFTER 'c'`},
+		{`CREATE TABLE a (b c)`},
+		{`SELECT CAST(1 AS a)`},
+		{`SELECT ANNOTATE_TYPE('b', a)`},
 
 		{`CREATE SEQUENCE a`},
 		{`EXPLAIN CREATE SEQUENCE a`},
@@ -312,6 +351,11 @@ func TestParse(t *testing.T) {
 		{`DROP DATABASE IF EXISTS a`},
 		{`DROP DATABASE a CASCADE`},
 		{`DROP DATABASE a RESTRICT`},
+		{`DROP SCHEMA a`},
+		{`EXPLAIN DROP SCHEMA a`},
+		{`DROP SCHEMA IF EXISTS a, b`},
+		{`DROP SCHEMA a CASCADE`},
+		{`DROP SCHEMA a RESTRICT`},
 		{`DROP TABLE a`},
 		{`EXPLAIN DROP TABLE a`},
 		{`DROP TABLE a.b`},
@@ -341,6 +385,9 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW IF EXISTS a, b RESTRICT`},
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
+		{`DROP MATERIALIZED VIEW a`},
+		{`DROP MATERIALIZED VIEW IF EXISTS a, b RESTRICT`},
+		{`DROP MATERIALIZED VIEW a.b CASCADE`},
 		{`DROP SEQUENCE a`},
 		{`EXPLAIN DROP SEQUENCE a`},
 		{`DROP SEQUENCE a.b`},
@@ -458,6 +505,7 @@ func TestParse(t *testing.T) {
 		{`SHOW GRANTS ON TABLE foo, db.foo`},
 		{`SHOW GRANTS ON DATABASE foo, bar`},
 		{`SHOW GRANTS ON DATABASE foo FOR bar`},
+		{`SHOW GRANTS ON SCHEMA foo, bar`},
 		{`SHOW GRANTS FOR bar, baz`},
 
 		{`SHOW GRANTS ON ROLE`},
@@ -522,6 +570,8 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT CREATE ON SCHEMA foo TO root`},
+		{`GRANT ALL ON SCHEMA foo, bar TO root, test`},
 		{`GRANT rolea, roleb TO usera, userb`},
 		{`GRANT rolea, roleb TO usera, userb WITH ADMIN OPTION`},
 
@@ -531,6 +581,7 @@ func TestParse(t *testing.T) {
 		{`REVOKE UPDATE, DELETE ON TABLE foo, db.foo FROM root, bar`},
 		{`REVOKE INSERT ON DATABASE foo FROM root`},
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
+		{`REVOKE CREATE ON SCHEMA foo FROM root`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
 		{`REVOKE rolea, roleb FROM usera, userb`},
@@ -568,11 +619,13 @@ func TestParse(t *testing.T) {
 
 		{`INSERT INTO a VALUES (1) ON CONFLICT DO NOTHING`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO NOTHING`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT (a) WHERE b > 2 DO NOTHING`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT DO UPDATE SET a = 1`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a, b) DO UPDATE SET a = 1`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1, b = excluded.a`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 1 WHERE b > 2`},
+		{`INSERT INTO a VALUES (1) ON CONFLICT (a) WHERE b > 2 DO UPDATE SET a = 1 WHERE b > 3`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET a = DEFAULT`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2)`},
 		{`INSERT INTO a VALUES (1) ON CONFLICT (a) DO UPDATE SET (a, b) = (SELECT 1, 2) RETURNING a, b`},
@@ -722,7 +775,31 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar (bar1, bar2, bar3)`},
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY`},
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY AS bar`},
+
+		{`WITH a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH RECURSIVE a AS (TABLE b) SELECT c`},
+		{`WITH RECURSIVE a (x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM a WHERE x < 10) SELECT x FROM a`},
+		{`WITH RECURSIVE a (x) AS (SELECT 1 UNION SELECT x FROM a), b AS (SELECT 2) SELECT * FROM a, b`},
 		{`SELECT a FROM ROWS FROM (a(x), b(y), c(z))`},
+		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv)`},
+		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv WHERE k = a) AS s`},
+		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv) WITH ORDINALITY AS s (x, y, z)`},
+		{`SELECT * FROM ab, LATERAL foo(a)`},
+		{`SELECT * FROM ab, LATERAL foo(a) WITH ORDINALITY AS f`},
+		{`SELECT * FROM ab INNER JOIN LATERAL (SELECT * FROM kv WHERE k = a) AS s ON true`},
+		{`SELECT * FROM ab LEFT JOIN LATERAL (SELECT * FROM kv WHERE k = a LIMIT 2) AS s ON v > b`},
+
+		{`SELECT * FROM t FOR UPDATE`},
+		{`SELECT * FROM t FOR NO KEY UPDATE`},
+		{`SELECT * FROM t FOR SHARE`},
+		{`SELECT * FROM t FOR KEY SHARE`},
+		{`SELECT * FROM t FOR UPDATE OF t`},
+		{`SELECT * FROM t, u FOR UPDATE OF t, u NOWAIT`},
+		{`SELECT * FROM t FOR SHARE SKIP LOCKED`},
+		{`SELECT * FROM t, u FOR UPDATE OF t FOR SHARE OF u`},
+		{`SELECT * FROM t ORDER BY a LIMIT 1 FOR UPDATE`},
+		{`WITH a AS (SELECT 1) SELECT * FROM t FOR UPDATE`},
+		{`SELECT * FROM (SELECT * FROM t FOR UPDATE) AS s FOR SHARE`},
 		{`SELECT a FROM t1, t2`},
 		{`SELECT a FROM t AS t1`},
 		{`SELECT a FROM t AS t1 (c1)`},
@@ -810,6 +887,12 @@ func TestParse(t *testing.T) {
 
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
+		{`SELECT 1 FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT 1 FROM t GROUP BY CUBE (a, (b, c))`},
+		{`SELECT 1 FROM t GROUP BY GROUPING SETS ((a, b), a, ())`},
+		{`SELECT 1 FROM t GROUP BY a, GROUPING SETS (ROLLUP (b, c), CUBE (d))`},
+		{`SELECT grouping(a, b) FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT rollup, cube, sets FROM t GROUP BY rollup, cube`},
 
 		{`SELECT a FROM t HAVING a = b`},
 
@@ -897,6 +980,9 @@ func TestParse(t *testing.T) {
 
 		{`SELECT avg(1) FILTER (WHERE a > b)`},
 		{`SELECT avg(1) FILTER (WHERE a > b) OVER (ORDER BY c)`},
+		{`SELECT percentile_disc(0.5) WITHIN GROUP (ORDER BY c)`},
+		{`SELECT percentile_cont(0.5) WITHIN GROUP (ORDER BY c) FILTER (WHERE a > b)`},
+		{`SELECT mode() WITHIN GROUP (ORDER BY c DESC)`},
 
 		{`SELECT a FROM t UNION SELECT 1 FROM t`},
 		{`SELECT a FROM t UNION SELECT 1 FROM t UNION SELECT 1 FROM t`},
@@ -941,6 +1027,10 @@ func TestParse(t *testing.T) {
 		{`SET TRANSACTION PRIORITY LOW`},
 		{`SET TRANSACTION PRIORITY NORMAL`},
 		{`SET TRANSACTION PRIORITY HIGH`},
+		{`SET CONSTRAINTS ALL DEFERRED`},
+		{`SET CONSTRAINTS ALL IMMEDIATE`},
+		{`SET CONSTRAINTS foo, bar DEFERRED`},
+		{`SET CONSTRAINTS foo IMMEDIATE`},
 		{`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE, PRIORITY HIGH`},
 
 		{`SET TRACING = off`},
@@ -1271,6 +1361,11 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+		{`CREATE TEMP TABLE a (b INT8)`, `CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE LOCAL TEMP TABLE a (b INT8)`, `CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE GLOBAL TEMPORARY TABLE a (b INT8)`, `CREATE TEMPORARY TABLE a (b INT8)`},
+		{`SELECT 'f'::"blah"`, `SELECT 'f'::blah`},
+		{`CREATE TYPE a AS ENUM (e'b')`, `CREATE TYPE a AS ENUM ('b')`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
@@ -1281,6 +1376,8 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
 		{`CREATE TABLE a (UNIQUE INDEX (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`,
 			`CREATE TABLE a (UNIQUE (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`},
+		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) WHERE b > 0)`,
+			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b) WHERE b > 0)`},
 		{`CREATE INDEX ON a (b) COVERING (c)`, `CREATE INDEX ON a (b) STORING (c)`},
 
 		{`CREATE INDEX a ON b USING GIN (c)`,
@@ -1807,6 +1904,22 @@ func TestParse2(t *testing.T) {
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo MATCH SIMPLE)`,
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`,
 		},
+		{
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo DEFERRABLE INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo DEFERRABLE)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo INITIALLY IMMEDIATE)`,
+			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES foo INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, FOREIGN KEY (b) REFERENCES foo DEFERRABLE INITIALLY DEFERRED)`,
+		},
+		{
+			`CREATE TABLE a (b INT8, UNIQUE (b) INITIALLY DEFERRED)`,
+			`CREATE TABLE a (b INT8, UNIQUE (b) DEFERRABLE INITIALLY DEFERRED)`,
+		},
 		{
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo MATCH SIMPLE ON UPDATE RESTRICT)`,
 			`CREATE TABLE a (b INT8, c INT8 REFERENCES foo ON UPDATE RESTRICT)`,
@@ -2120,6 +2233,10 @@ CREATE TABLE test (
   foo INT8 FAMILY a FAMILY b
 )
 ^
+`},
+		{`CREATE TABLE a (b INT8, CHECK (b > 0) DEFERRABLE)`, `CHECK constraints cannot be marked DEFERRABLE at or near ")"
+CREATE TABLE a (b INT8, CHECK (b > 0) DEFERRABLE)
+                                                ^
 `},
 		{`SELECT family FROM test`, `syntax error at or near "from"
 SELECT family FROM test
@@ -2177,11 +2294,6 @@ HINT: try \h SELECT`},
 SELECT 1e-
        ^
 HINT: try \h SELECT`},
-		{"SELECT foo''",
-			`type does not exist at or near ""
-SELECT foo''
-          ^
-`},
 		{
 			`SELECT 0x FROM t`,
 			`invalid hexadecimal numeric literal
@@ -2272,20 +2384,6 @@ HINT: try \h INSERT`,
 ALTER TABLE t RENAME COLUMN x TO family
                                  ^
 HINT: try \h ALTER TABLE`,
-		},
-		{
-			`SELECT CAST(1.2+2.3 AS notatype)`,
-			`type does not exist at or near "notatype"
-SELECT CAST(1.2+2.3 AS notatype)
-                       ^
-`,
-		},
-		{
-			`SELECT ANNOTATE_TYPE(1.2+2.3, notatype)`,
-			`type does not exist at or near "notatype"
-SELECT ANNOTATE_TYPE(1.2+2.3, notatype)
-                              ^
-`,
 		},
 		{
 			`CREATE USER foo WITH PASSWORD`,
@@ -2348,13 +2446,6 @@ SELECT EXISTS(SELECT 1)[1]
 			`+ ANY <array> is invalid because "+" is not a boolean operator at or near "EOF"
 SELECT 1 + ANY ARRAY[1, 2, 3]
                              ^
-`,
-		},
-		{
-			`SELECT 'f'::"blah"`,
-			`type does not exist at or near "blah"
-SELECT 'f'::"blah"
-            ^
 `,
 		},
 		// Ensure that the support for ON ROLE <namelist> doesn't leak
@@ -2736,11 +2827,9 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE FUNCTION a`, 17511, `create`},
 		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`},
 		{`CREATE LANGUAGE a`, 17511, `create language a`},
-		{`CREATE MATERIALIZED VIEW a`, 24747, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`},
 		{`CREATE PUBLICATION a`, 0, `create publication`},
 		{`CREATE RULE a`, 0, `create rule`},
-		{`CREATE SCHEMA a`, 26443, `create`},
 		{`CREATE SERVER a`, 0, `create server`},
 		{`CREATE SUBSCRIPTION a`, 0, `create subscription`},
 		{`CREATE TEXT SEARCH a`, 7821, `create text`},
@@ -2759,7 +2848,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP OPERATOR a`, 0, `drop operator`},
 		{`DROP PUBLICATION a`, 0, `drop publication`},
 		{`DROP RULE a`, 0, `drop rule`},
-		{`DROP SCHEMA a`, 26443, `drop`},
 		{`DROP SERVER a`, 0, `drop server`},
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`},
@@ -2771,14 +2859,10 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DISCARD TEMP`, 0, `discard temp`},
 		{`DISCARD TEMPORARY`, 0, `discard temp`},
 
-		{`SET CONSTRAINTS foo`, 0, `set constraints`},
 		{`SET LOCAL foo = bar`, 32562, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`},
 
-		{`CREATE TEMP TABLE a(b INT8)`, 5807, ``},
 		{`CREATE UNLOGGED TABLE a(b INT8)`, 0, `create unlogged`},
-		{`CREATE TEMP VIEW a AS SELECT b`, 5807, ``},
-		{`CREATE TEMP SEQUENCE a`, 5807, ``},
 
 		{`CREATE TABLE a(LIKE b)`, 30840, ``},
 
@@ -2791,14 +2875,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b INT8 REFERENCES c(x) MATCH PARTIAL`, 20305, `match partial`},
 		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) MATCH PARTIAL)`, 20305, `match partial`},
 
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE)`, 31632, `deferrable`},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY DEFERRED)`, 31632, `initially deferred`},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) INITIALLY IMMEDIATE)`, 31632, `initially immediate`},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY DEFERRED)`, 31632, `initially deferred`},
-		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) DEFERRABLE INITIALLY IMMEDIATE)`, 31632, `initially immediate`},
-		{`CREATE TABLE a(b INT8, UNIQUE (b) DEFERRABLE)`, 31632, `deferrable`},
-		{`CREATE TABLE a(b INT8, CHECK (b > 0) DEFERRABLE)`, 31632, `deferrable`},
-
 		{`CREATE SEQUENCE a AS DOUBLE PRECISION`, 25110, `FLOAT8`},
 		{`CREATE SEQUENCE a OWNED BY b`, 26382, ``},
 
@@ -2806,13 +2882,11 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE RECURSIVE VIEW a AS SELECT b`, 0, `create recursive view`},
 
 		{`CREATE TYPE a AS (b)`, 27792, ``},
-		{`CREATE TYPE a AS ENUM (b)`, 24873, ``},
 		{`CREATE TYPE a AS RANGE b`, 27791, ``},
 		{`CREATE TYPE a (b)`, 27793, `base`},
 		{`CREATE TYPE a`, 27793, `shell`},
 		{`CREATE DOMAIN a`, 27796, `create`},
 
-		{`CREATE INDEX a ON b(c) WHERE d > 0`, 9683, ``},
 		{`CREATE INDEX a ON b USING HASH (c)`, 0, `index using hash`},
 		{`CREATE INDEX a ON b USING GIST (c)`, 0, `index using gist`},
 		{`CREATE INDEX a ON b USING SPGIST (c)`, 0, `index using spgist`},
@@ -2825,11 +2899,8 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`INSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``},
 		{`INSERT INTO foo VALUES (1,2) ON CONFLICT ON CONSTRAINT a DO NOTHING`, 28161, ``},
 
-		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv)`, 24560, `select`},
-		{`SELECT * FROM ab, LATERAL foo(a)`, 24560, `srf`},
 		{`SELECT max(a ORDER BY b) FROM ab`, 23620, ``},
 
-		{`SELECT * FROM a FOR UPDATE`, 6583, ``},
 		{`SELECT * FROM ROWS FROM (a(b) AS (d))`, 0, `ROWS FROM with col_def_list`},
 
 		{`SELECT 123 AT TIME ZONE 'b'`, 32005, ``},
@@ -2856,14 +2927,12 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SELECT a(b) 'c'`, 0, `a(...) SCONST`},
 		{`SELECT (a,b) OVERLAPS (c,d)`, 0, `overlaps`},
 		{`SELECT UNIQUE (SELECT b)`, 0, `UNIQUE predicate`},
-		{`SELECT GROUPING (a,b,c)`, 0, `d_expr grouping`},
 		{`SELECT a(VARIADIC b)`, 0, `variadic`},
 		{`SELECT a(b, c, VARIADIC b)`, 0, `variadic`},
 		{`SELECT COLLATION FOR (a)`, 32563, ``},
 		{`SELECT CURRENT_TIME`, 26097, `current_time`},
 		{`SELECT CURRENT_TIME()`, 26097, `current_time`},
 		{`SELECT TREAT (a AS INT8)`, 0, `treat`},
-		{`SELECT a(b) WITHIN GROUP (ORDER BY c)`, 0, `within group`},
 
 		{`CREATE TABLE a(b BOX)`, 21286, `box`},
 		{`CREATE TABLE a(b CIDR)`, 18846, `cidr`},
@@ -2883,10 +2952,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE TABLE a(b XML)`, 0, `xml`},
 		{`CREATE TABLE a(b TIMETZ)`, 26097, `type`},
 
-		{`INSERT INTO a VALUES (1) ON CONFLICT (x) WHERE x > 3 DO NOTHING`, 32557, ``},
-
-		{`WITH RECURSIVE a AS (TABLE b) SELECT c`, 21085, ``},
-
 		{`UPDATE foo SET (a, a.b) = (1, 2)`, 27792, ``},
 		{`UPDATE foo SET a.b = 1`, 27792, ``},
 		{`UPDATE foo SET x = y FROM a, b`, 7841, ``},
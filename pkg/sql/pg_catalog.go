@@ -26,6 +26,7 @@ import (
 	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -449,6 +450,7 @@ var (
 	relKindIndex    = tree.NewDString("i")
 	relKindView     = tree.NewDString("v")
 	relKindSequence = tree.NewDString("S")
+	relKindMatView  = tree.NewDString("m")
 
 	relPersistencePermanent = tree.NewDString("p")
 )
@@ -491,7 +493,9 @@ CREATE TABLE pg_catalog.pg_class (
 			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
 				// The only difference between tables, views and sequences is the relkind column.
 				relKind := relKindTable
-				if table.IsView() {
+				if table.MaterializedView() {
+					relKind = relKindMatView
+				} else if table.IsView() {
 					relKind = relKindView
 				} else if table.IsSequence() {
 					relKind = relKindSequence
@@ -702,6 +706,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 				consrc := tree.DNull
 				conbin := tree.DNull
 				condef := tree.DNull
+				condeferrable := tree.DBoolFalse
+				condeferred := tree.DBoolFalse
 
 				// Determine constraint kind-specific fields.
 				var err error
@@ -754,6 +760,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 						return err
 					}
 					condef = tree.NewDString(buf.String())
+					condeferrable = tree.MakeDBool(tree.DBool(con.FK.Deferrable))
+					condeferred = tree.MakeDBool(tree.DBool(con.FK.InitiallyDeferred))
 
 				case sqlbase.ConstraintTypeUnique:
 					oid = h.UniqueConstraintOid(db, scName, table, con.Index)
@@ -767,7 +775,15 @@ CREATE TABLE pg_catalog.pg_constraint (
 					f.WriteString("UNIQUE (")
 					con.Index.ColNamesFormat(f)
 					f.WriteByte(')')
+					if con.Index.Deferrable {
+						f.WriteString(" DEFERRABLE")
+						if con.Index.InitiallyDeferred {
+							f.WriteString(" INITIALLY DEFERRED")
+						}
+					}
 					condef = tree.NewDString(f.CloseAndGetString())
+					condeferrable = tree.MakeDBool(tree.DBool(con.Index.Deferrable))
+					condeferred = tree.MakeDBool(tree.DBool(con.Index.InitiallyDeferred))
 
 				case sqlbase.ConstraintTypeCheck:
 					oid = h.CheckConstraintOid(db, scName, table, con.CheckConstraint)
@@ -785,8 +801,8 @@ CREATE TABLE pg_catalog.pg_constraint (
 					dNameOrNull(conName), // conname
 					namespaceOid,         // connamespace
 					contype,              // contype
-					tree.DBoolFalse,      // condeferrable
-					tree.DBoolFalse,      // condeferred
+					condeferrable,        // condeferrable
+					condeferred,          // condeferred
 					tree.MakeDBool(tree.DBool(!con.Unvalidated)), // convalidated
 					tblOid,         // conrelid
 					oidZero,        // contypid
@@ -1069,9 +1085,24 @@ CREATE TABLE pg_catalog.pg_enum (
   enumsortorder FLOAT,
   enumlabel STRING
 )`,
-	populate: func(_ context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// Enum types are not currently supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachTypeDesc(ctx, p, dbContext, func(_ *DatabaseDescriptor, typ *sqlbase.TypeDescriptor) error {
+			enumTypOid := typOid(typ.DatumType())
+			for i := range typ.EnumMembers {
+				label := typ.EnumMembers[i].LogicalRepresentation
+				sortOrder := tree.NewDFloat(tree.DFloat(i + 1))
+				if err := addRow(
+					h.EnumLabelOid(typ.ID, label), // oid
+					enumTypOid,                    // enumtypid
+					sortOrder,                     // enumsortorder
+					tree.NewDString(label),        // enumlabel
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -1224,26 +1255,33 @@ CREATE TABLE pg_catalog.pg_index (
 					if err != nil {
 						return err
 					}
+					indpred := tree.DNull
+					if index.IsPartial() {
+						indpred = tree.NewDString(index.Predicate)
+					}
+					// The uniqueness of a deferrable unique index isn't enforced
+					// immediately when rows are written.
+					indimmediate := tree.MakeDBool(tree.DBool(index.HasUniqueKeys()))
 					return addRow(
 						h.IndexOid(db, scName, table, index), // indexrelid
 						tableOid,                             // indrelid
 						tree.NewDInt(tree.DInt(len(index.ColumnNames))),                                          // indnatts
 						tree.MakeDBool(tree.DBool(index.Unique)),                                                 // indisunique
 						tree.MakeDBool(tree.DBool(table.IsPhysicalTable() && index.ID == table.PrimaryIndex.ID)), // indisprimary
-						tree.DBoolFalse,                          // indisexclusion
-						tree.MakeDBool(tree.DBool(index.Unique)), // indimmediate
-						tree.DBoolFalse,                          // indisclustered
-						tree.MakeDBool(tree.DBool(!isMutation)),  // indisvalid
-						tree.DBoolFalse,                          // indcheckxmin
-						tree.MakeDBool(tree.DBool(isReady)),      // indisready
-						tree.DBoolTrue,                           // indislive
-						tree.DBoolFalse,                          // indisreplident
-						indkey,                                   // indkey
-						collationOidVector,                       // indcollation
-						indclass,                                 // indclass
-						indoption,                                // indoption
-						tree.DNull,                               // indexprs
-						tree.DNull,                               // indpred
+						tree.DBoolFalse,                         // indisexclusion
+						indimmediate,                            // indimmediate
+						tree.DBoolFalse,                         // indisclustered
+						tree.MakeDBool(tree.DBool(!isMutation)), // indisvalid
+						tree.DBoolFalse,                         // indcheckxmin
+						tree.MakeDBool(tree.DBool(isReady)),     // indisready
+						tree.DBoolTrue,                          // indislive
+						tree.DBoolFalse,                         // indisreplident
+						indkey,                                  // indkey
+						collationOidVector,                      // indcollation
+						indclass,                                // indclass
+						indoption,                               // indoption
+						tree.DNull,                              // indexprs
+						indpred,                                 // indpred
 					)
 				})
 			})
@@ -1343,6 +1381,13 @@ func indexDefFromDescriptor(
 		}
 		indexDef.Interleave = intlDef
 	}
+	if index.IsPartial() {
+		predicate, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return "", err
+		}
+		indexDef.Predicate = predicate
+	}
 	return indexDef.String(), nil
 }
 
@@ -2052,7 +2097,7 @@ CREATE TABLE pg_catalog.pg_type (
 )`,
 	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
-		return forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
+		if err := forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
 			nspOid := h.NamespaceOid(db, pgCatalogName)
 
 			for o, typ := range types.OidToType {
@@ -2126,6 +2171,49 @@ CREATE TABLE pg_catalog.pg_type (
 				}
 			}
 			return nil
+		}); err != nil {
+			return err
+		}
+
+		// Add the user-defined types.
+		return forEachTypeDesc(ctx, p, dbContext, func(db *DatabaseDescriptor, desc *sqlbase.TypeDescriptor) error {
+			typ := desc.DatumType()
+			return addRow(
+				typOid(typ),                           // oid
+				tree.NewDName(desc.Name),              // typname
+				h.NamespaceOid(db, tree.PublicSchema), // typnamespace
+				tree.DNull,                            // typowner
+				typLen(typ),                           // typlen
+				typByVal(typ),                         // typbyval
+				typTypeEnum,                           // typtype
+				typCategory(typ),                      // typcategory
+				tree.DBoolFalse,                       // typispreferred
+				tree.DBoolTrue,                        // typisdefined
+				typDelim,                              // typdelim
+				oidZero,                               // typrelid
+				oidZero,                               // typelem
+				oidZero,                               // typarray
+
+				// regproc references
+				h.RegProc("enum_in"),   // typinput
+				h.RegProc("enum_out"),  // typoutput
+				h.RegProc("enum_recv"), // typreceive
+				h.RegProc("enum_send"), // typsend
+				oidZero,                // typmodin
+				oidZero,                // typmodout
+				oidZero,                // typanalyze
+
+				tree.DNull,      // typalign
+				tree.DNull,      // typstorage
+				tree.DBoolFalse, // typnotnull
+				oidZero,         // typbasetype
+				negOneVal,       // typtypmod
+				zeroVal,         // typndims
+				oidZero,         // typcollation
+				tree.DNull,      // typdefaultbin
+				tree.DNull,      // typdefault
+				tree.DNull,      // typacl
+			)
 		})
 	},
 }
@@ -2298,6 +2386,7 @@ var datumToTypeCategory = map[reflect.Type]*tree.DString{
 	reflect.TypeOf(types.Oid):         typCategoryNumeric,
 	reflect.TypeOf(types.UUID):        typCategoryUserDefined,
 	reflect.TypeOf(types.INet):        typCategoryNetworkAddr,
+	reflect.TypeOf(types.FamEnum):     typCategoryEnum,
 }
 
 func typCategory(typ types.T) tree.Datum {
@@ -2324,7 +2413,7 @@ CREATE TABLE pg_catalog.pg_views (
 		// because it does not distinguish views in separate databases.
 		return forEachTableDesc(ctx, p, dbContext, hideVirtual, /*virtual schemas do not have views*/
 			func(db *sqlbase.DatabaseDescriptor, scName string, desc *sqlbase.TableDescriptor) error {
-				if !desc.IsView() {
+				if !desc.IsView() || desc.MaterializedView() {
 					return nil
 				}
 				// Note that the view query printed will not include any column aliases
@@ -2419,6 +2508,7 @@ const (
 	userTypeTag
 	collationTypeTag
 	operatorTypeTag
+	enumLabelTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -2600,6 +2690,13 @@ func (h oidHasher) CollationOid(collation string) *tree.DOid {
 	return h.getOid()
 }
 
+func (h oidHasher) EnumLabelOid(typeID sqlbase.ID, label string) *tree.DOid {
+	h.writeTypeTag(enumLabelTypeTag)
+	h.writeUInt32(uint32(typeID))
+	h.writeStr(label)
+	return h.getOid()
+}
+
 func (h oidHasher) OperatorOid(name string, leftType, rightType, returnType *tree.DOid) *tree.DOid {
 	h.writeTypeTag(operatorTypeTag)
 	h.writeStr(name)
@@ -69,9 +69,16 @@ func (a UncachedPhysicalAccessor) GetDatabaseDesc(
 }
 
 // IsValidSchema implements the SchemaAccessor interface.
-func (a UncachedPhysicalAccessor) IsValidSchema(dbDesc *DatabaseDescriptor, scName string) bool {
-	// At this point, only the public schema is recognized.
-	return scName == tree.PublicSchema
+func (a UncachedPhysicalAccessor) IsValidSchema(
+	ctx context.Context, txn *client.Txn, dbDesc *DatabaseDescriptor, scName string,
+) (bool, error) {
+	// The public schema and the temporary schemas are always valid, the
+	// other schemas must have been created by the user.
+	if scName == tree.PublicSchema || isTemporarySchemaName(scName) {
+		return true, nil
+	}
+	id, err := GetUserSchemaID(ctx, txn, dbDesc.ID, scName)
+	return id != 0, err
 }
 
 // GetObjectNames implements the SchemaAccessor interface.
@@ -82,16 +89,21 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 	scName string,
 	flags DatabaseListFlags,
 ) (TableNames, error) {
-	if ok := a.IsValidSchema(dbDesc, scName); !ok {
-		if flags.required {
-			tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), "")
-			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(&tn.TableNamePrefix))
+	// The names of the tables in the public schema are recorded under the
+	// database, and those of the other tables under their schema.
+	parentID, err := getSchemaID(ctx, txn, dbDesc.ID, scName)
+	if err != nil {
+		return nil, err
+	}
+	if parentID == 0 {
+		if flags.required && !isTemporarySchemaName(scName) {
+			return nil, sqlbase.NewUndefinedSchemaError(scName)
 		}
 		return nil, nil
 	}
 
 	log.Eventf(ctx, "fetching list of objects for %q", dbDesc.Name)
-	prefix := sqlbase.MakeNameMetadataKey(dbDesc.ID, "")
+	prefix := sqlbase.MakeNameMetadataKey(parentID, "")
 	sr, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
 	if err != nil {
 		return nil, err
@@ -104,7 +116,13 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		if err != nil {
 			return nil, err
 		}
-		tn := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(tableName))
+		if parentID == dbDesc.ID && (isTemporarySchemaName(tableName) ||
+			tableName == typeNamespaceName || tableName == schemaNamespaceName) {
+			// The temporary schemas and the type and schema namespaces are
+			// recorded alongside the tables of the public schema.
+			continue
+		}
+		tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), tree.Name(tableName))
 		tn.ExplicitCatalog = flags.explicitPrefix
 		tn.ExplicitSchema = flags.explicitPrefix
 		tableNames = append(tableNames, tn)
@@ -116,14 +134,6 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 func (a UncachedPhysicalAccessor) GetObjectDesc(
 	ctx context.Context, txn *client.Txn, name *ObjectName, flags ObjectLookupFlags,
 ) (ObjectDescriptor, *DatabaseDescriptor, error) {
-	// At this point, only the public schema is recognized.
-	if name.Schema() != tree.PublicSchema {
-		if flags.required {
-			return nil, nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(name))
-		}
-		return nil, nil, nil
-	}
-
 	// Look up the database.
 	dbDesc, err := a.GetDatabaseDesc(ctx, txn, name.Catalog(), flags.CommonLookupFlags)
 	if dbDesc == nil || err != nil {
@@ -132,14 +142,24 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 		return nil, dbDesc, err
 	}
 
-	// Look up the table using the discovered database descriptor.
-	desc := &sqlbase.TableDescriptor{}
-	found, err := getDescriptor(ctx, txn,
-		tableKey{parentID: dbDesc.ID, name: name.Table()}, desc)
+	// The names of the tables which are not in the public schema are
+	// recorded under their schema.
+	parentID, err := getSchemaID(ctx, txn, dbDesc.ID, name.Schema())
 	if err != nil {
 		return nil, nil, err
 	}
 
+	// Look up the table using the discovered database descriptor.
+	desc := &sqlbase.TableDescriptor{}
+	found := false
+	if parentID != 0 {
+		found, err = getDescriptor(ctx, txn,
+			tableKey{parentID: parentID, name: name.Table()}, desc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if found {
 		// We have a descriptor. Is it in the right state? We'll keep it if
 		// it is in the ADD state.
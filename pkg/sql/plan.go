@@ -161,22 +161,29 @@ type planNodeFastPath interface {
 var _ planNode = &alterIndexNode{}
 var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
+var _ planNode = &alterTypeAddValueNode{}
+var _ planNode = &applyJoinNode{}
+var _ planNode = &bufferNode{}
 var _ planNode = &createDatabaseNode{}
 var _ planNode = &createIndexNode{}
 var _ planNode = &createSequenceNode{}
+var _ planNode = &createSchemaNode{}
 var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
+var _ planNode = &createTypeNode{}
 var _ planNode = &CreateUserNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
 var _ planNode = &deleteNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
+var _ planNode = &dropSchemaNode{}
 var _ planNode = &dropIndexNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
 var _ planNode = &DropUserNode{}
 var _ planNode = &dropViewNode{}
+var _ planNode = &errorIfRowsNode{}
 var _ planNode = &explainDistSQLNode{}
 var _ planNode = &explainPlanNode{}
 var _ planNode = &filterNode{}
@@ -188,6 +195,8 @@ var _ planNode = &joinNode{}
 var _ planNode = &limitNode{}
 var _ planNode = &ordinalityNode{}
 var _ planNode = &projectSetNode{}
+var _ planNode = &recursiveCTENode{}
+var _ planNode = &refreshMaterializedViewNode{}
 var _ planNode = &relocateNode{}
 var _ planNode = &renameColumnNode{}
 var _ planNode = &renameDatabaseNode{}
@@ -195,9 +204,11 @@ var _ planNode = &renameIndexNode{}
 var _ planNode = &renameTableNode{}
 var _ planNode = &renderNode{}
 var _ planNode = &rowCountNode{}
+var _ planNode = &scanBufferNode{}
 var _ planNode = &scanNode{}
 var _ planNode = &scatterNode{}
 var _ planNode = &serializeNode{}
+var _ planNode = &setConstraintsNode{}
 var _ planNode = &showFingerprintsNode{}
 var _ planNode = &showTraceNode{}
 var _ planNode = &sortNode{}
@@ -279,6 +290,13 @@ type planTop struct {
 	// subqueryPlans contains all the sub-query plans.
 	subqueryPlans []subquery
 
+	// postqueryPlans contains the plans that run, in order, after the main plan
+	// has been run to completion. These are currently the plans that check
+	// foreign keys and run cascading actions when those are planned by the
+	// optimizer. A postquery does not produce any rows; it returns an error if
+	// the main plan violated a constraint.
+	postqueryPlans []planNode
+
 	// auditEvents becomes non-nil if any of the descriptors used by
 	// current statement is causing an auditing event. See exec_log.go.
 	auditEvents []auditEvent
@@ -396,6 +414,13 @@ func (p *planTop) close(ctx context.Context) {
 			p.subqueryPlans[i].plan = nil
 		}
 	}
+
+	for i := range p.postqueryPlans {
+		if p.postqueryPlans[i] != nil {
+			p.postqueryPlans[i].Close(ctx)
+			p.postqueryPlans[i] = nil
+		}
+	}
 }
 
 // start starts the plan.
@@ -406,6 +431,26 @@ func (p *planTop) start(params runParams) error {
 	return startPlan(params, p.plan)
 }
 
+// runPostqueries runs the postqueries of the plan, in order. It must only be
+// called once the main plan has been run to completion.
+func (p *planTop) runPostqueries(params runParams) error {
+	for _, plan := range p.postqueryPlans {
+		if err := startPlan(params, plan); err != nil {
+			return err
+		}
+		for {
+			ok, err := plan.Next(params)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+		}
+	}
+	return nil
+}
+
 // columns retrieves the plan's columns.
 func (p *planTop) columns() sqlbase.ResultColumns {
 	return planColumns(p.plan)
@@ -619,6 +664,8 @@ func (p *planner) newPlan(
 		return p.AlterTable(ctx, n)
 	case *tree.AlterSequence:
 		return p.AlterSequence(ctx, n)
+	case *tree.AlterTypeAddValue:
+		return p.AlterTypeAddValue(ctx, n)
 	case *tree.AlterUserSetPassword:
 		return p.AlterUserSetPassword(ctx, n)
 	case *tree.CancelQueries:
@@ -639,6 +686,8 @@ func (p *planner) newPlan(
 		return p.CreateDatabase(ctx, n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreateSchema:
+		return p.CreateSchema(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
 	case *tree.CreateUser:
@@ -647,6 +696,8 @@ func (p *planner) newPlan(
 		return p.CreateView(ctx, n)
 	case *tree.CreateSequence:
 		return p.CreateSequence(ctx, n)
+	case *tree.CreateType:
+		return p.CreateType(ctx, n)
 	case *tree.CreateStats:
 		return p.CreateStatistics(ctx, n)
 	case *tree.Deallocate:
@@ -659,6 +710,8 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *tree.DropSchema:
+		return p.DropSchema(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
 	case *tree.DropView:
@@ -675,6 +728,8 @@ func (p *planner) newPlan(
 		return p.Insert(ctx, n, desiredTypes)
 	case *tree.ParenSelect:
 		return p.newPlan(ctx, n.Select, desiredTypes)
+	case *tree.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *tree.Relocate:
 		return p.Relocate(ctx, n)
 	case *tree.RenameColumn:
@@ -702,6 +757,8 @@ func (p *planner) newPlan(
 		return p.SetVar(ctx, n)
 	case *tree.SetTransaction:
 		return p.SetTransaction(n)
+	case *tree.SetConstraints:
+		return p.SetConstraints(ctx, n)
 	case *tree.SetSessionCharacteristics:
 		return p.SetSessionCharacteristics(n)
 	case *tree.ShowClusterSetting:
@@ -807,6 +864,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.ControlJobs(ctx, n)
 	case *tree.CreateUser:
 		return p.CreateUser(ctx, n)
+	case *tree.CreateSchema:
+		return p.CreateSchema(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
 	case *tree.Delete:
@@ -872,6 +931,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.Split(ctx, n)
 	case *tree.Truncate:
 		return p.Truncate(ctx, n)
+	case *tree.RefreshMaterializedView:
+		return p.RefreshMaterializedView(ctx, n)
 	case *tree.Relocate:
 		return p.Relocate(ctx, n)
 	case *tree.Scatter:
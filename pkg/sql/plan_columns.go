@@ -49,6 +49,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 	switch n := plan.(type) {
 
 	// Nodes that define their own schema.
+	case *applyJoinNode:
+		return n.columns
 	case *delayedNode:
 		return n.columns
 	case *groupNode:
@@ -63,6 +65,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *scanNode:
 		return n.resultColumns
+	case *scanBufferNode:
+		return n.columns
 	case *sortNode:
 		return n.columns
 	case *unionNode:
@@ -124,6 +128,10 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return getPlanColumns(n.source.plan, mut)
 	case *max1RowNode:
 		return getPlanColumns(n.plan, mut)
+	case *recursiveCTENode:
+		return getPlanColumns(n.initial, mut)
+	case *bufferNode:
+		return getPlanColumns(n.plan, mut)
 	case *limitNode:
 		return getPlanColumns(n.plan, mut)
 	case *spoolNode:
@@ -97,6 +97,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *alterIndexNode:
 	case *alterSequenceNode:
 	case *alterTableNode:
+	case *alterTypeAddValueNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueriesNode:
 	case *cancelSessionsNode:
@@ -104,11 +105,14 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
+	case *createSchemaNode:
 	case *createStatsNode:
 	case *createTableNode:
+	case *createTypeNode:
 	case *createViewNode:
 	case *delayedNode:
 	case *dropDatabaseNode:
+	case *dropSchemaNode:
 	case *dropIndexNode:
 	case *dropSequenceNode:
 	case *dropTableNode:
@@ -116,6 +120,8 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *explainDistSQLNode:
 	case *hookFnNode:
 	case *iterativeSortStrategy:
+	case *recursiveCTENode:
+	case *refreshMaterializedViewNode:
 	case *relocateNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
@@ -123,10 +129,12 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *renameTableNode:
 	case *rowCountNode:
 	case *rowSourceToPlanNode:
+	case *scanBufferNode:
 	case *scatterNode:
 	case *scrubNode:
 	case *sequenceSelectNode:
 	case *setClusterSettingNode:
+	case *setConstraintsNode:
 	case *setVarNode:
 	case *setZoneConfigNode:
 	case *showFingerprintsNode:
@@ -178,6 +178,11 @@ func insertNodeWithValuesSpans(
 		}
 		addWriteKey(primaryKey)
 		for _, secondaryKey := range secondaryKeys {
+			// Partial indexes have no entry for the rows that don't satisfy their
+			// predicate.
+			if len(secondaryKey.Key) == 0 {
+				continue
+			}
 			addWriteKey(secondaryKey.Key)
 		}
 
@@ -72,7 +72,15 @@ type extendedEvalContext struct {
 
 	SchemaChangers *schemaChangerCollection
 
+	// DeferredChecks accumulates the deferred foreign key and uniqueness
+	// checks of the transaction. It is nil if the checks can't be deferred.
+	DeferredChecks *row.DeferredChecks
+
 	schemaAccessors *schemaInterface
+
+	// SessionID is the ID of the session, which determines the name of its
+	// temporary schema.
+	SessionID ClusterWideID
 }
 
 // schemaInterface provides access to the database and table descriptors.
@@ -238,6 +246,7 @@ func newInternalPlanner(
 	p.semaCtx = tree.MakeSemaContext(sd.User == security.RootUser /* privileged */)
 	p.semaCtx.Location = &sd.DataConversion.Location
 	p.semaCtx.SearchPath = sd.SearchPath
+	p.semaCtx.TypeResolver = p
 
 	plannerMon := mon.MakeUnlimitedMonitor(ctx,
 		fmt.Sprintf("internal-planner.%s.%s", user, opName),
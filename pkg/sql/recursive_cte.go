@@ -0,0 +1,215 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// recursiveCTEMaxRows is the maximum number of rows that a recursive CTE can
+// produce, counting the rows of the initial query and of every iteration of
+// the recursive query. It guards against queries that recurse forever because
+// their recursive query never stops producing rows, as well as against
+// iterations that produce an unbounded number of rows. Since an iteration
+// which produces no rows ends the recursion, it also bounds the number of
+// iterations.
+var recursiveCTEMaxRows = settings.RegisterNonNegativeIntSetting(
+	"sql.recursive_cte.max_rows",
+	"maximum number of rows produced by a WITH RECURSIVE clause (0 = unlimited)",
+	1000000,
+)
+
+// recursiveCTENode implements the logic for a recursive CTE:
+//  1. Evaluate the initial query; emit the results and also save them in
+//     a "working" table.
+//  2. So long as the working table is not empty:
+//     - evaluate the recursive query, substituting the current contents of
+//       the working table for the recursive self-reference;
+//     - emit all resulting rows, and save them as the next iteration's
+//       working table.
+// The recursive query tree is regenerated each time using a callback
+// (implemented by the execbuilder).
+type recursiveCTENode struct {
+	initial planNode
+
+	genIterationFn exec.RecursiveCTEIterationFn
+
+	// label is a string used to describe the node in an EXPLAIN output.
+	label string
+
+	// deduplicate is set for UNION (as opposed to UNION ALL); in this case rows
+	// which were already emitted are discarded.
+	deduplicate bool
+
+	run recursiveCTERun
+}
+
+type recursiveCTERun struct {
+	// source is the plan which is currently producing rows: either the initial
+	// plan, or the plan of the current iteration.
+	source planNode
+	// iterating is set once the initial plan is exhausted and source refers to
+	// the plan of an iteration, which is owned by this node.
+	iterating bool
+
+	// workingRows contains the rows produced by the previous iteration (aka the
+	// "working" table); these are the rows returned by a scanBufferNode.
+	workingRows *sqlbase.RowContainer
+	// nextRows accumulates the rows produced by the current iteration.
+	nextRows *sqlbase.RowContainer
+
+	// seen contains the encoded rows emitted so far, if deduplicate is set. Its
+	// memory is accounted for in seenAcc.
+	seen    map[string]struct{}
+	seenAcc mon.BoundAccount
+	// rows is the number of rows produced so far, and maxRows is the value of
+	// the sql.recursive_cte.max_rows setting when the execution started.
+	rows    int64
+	maxRows int64
+	// scratch is a preallocated buffer for encoding the rows.
+	scratch []byte
+
+	values tree.Datums
+}
+
+// startExec implements the execStartable interface.
+func (n *recursiveCTENode) startExec(params runParams) error {
+	typs := sqlbase.ColTypeInfoFromResCols(planColumns(n.initial))
+	n.run.workingRows = sqlbase.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(), typs, 0, /* rowCapacity */
+	)
+	n.run.nextRows = sqlbase.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(), typs, 0, /* rowCapacity */
+	)
+	if n.deduplicate {
+		n.run.seen = make(map[string]struct{})
+		n.run.seenAcc = params.EvalContext().Mon.MakeBoundAccount()
+	}
+	n.run.maxRows = recursiveCTEMaxRows.Get(&params.p.ExecCfg().Settings.SV)
+	n.run.source = n.initial
+	return nil
+}
+
+// Next is part of the planNode interface.
+func (n *recursiveCTENode) Next(params runParams) (bool, error) {
+	for {
+		if n.run.source == nil {
+			if n.run.nextRows.Len() == 0 {
+				// The last iteration did not produce any rows; we are done.
+				return false, nil
+			}
+			if err := n.startIteration(params); err != nil {
+				return false, err
+			}
+		}
+
+		ok, err := n.run.source.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			if n.run.iterating {
+				n.run.source.Close(params.ctx)
+			}
+			n.run.source = nil
+			continue
+		}
+
+		row := n.run.source.Values()
+		if n.deduplicate {
+			n.run.scratch, err = sqlbase.EncodeDatumsKeyAscending(n.run.scratch[:0], row)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := n.run.seen[string(n.run.scratch)]; ok {
+				continue
+			}
+			if err := n.run.seenAcc.Grow(params.ctx, int64(len(n.run.scratch))); err != nil {
+				return false, err
+			}
+			n.run.seen[string(n.run.scratch)] = struct{}{}
+		}
+		n.run.rows++
+		if n.run.maxRows > 0 && n.run.rows > n.run.maxRows {
+			return false, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"recursive query %q exceeded the maximum of %d rows", n.label, n.run.maxRows)
+		}
+		n.run.values, err = n.run.nextRows.AddRow(params.ctx, row)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+}
+
+// startIteration makes the rows of the last iteration the new working table,
+// and sets up the plan of the next iteration.
+func (n *recursiveCTENode) startIteration(params runParams) error {
+	if err := params.p.cancelChecker.Check(); err != nil {
+		return err
+	}
+	n.run.workingRows, n.run.nextRows = n.run.nextRows, n.run.workingRows
+	n.run.nextRows.Clear(params.ctx)
+
+	newPlan, err := n.genIterationFn(n)
+	if err != nil {
+		return err
+	}
+	plan := newPlan.(planNode)
+	n.run.source = plan
+	n.run.iterating = true
+	if err := startExec(params, plan); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Values is part of the planNode interface.
+func (n *recursiveCTENode) Values() tree.Datums {
+	return n.run.values
+}
+
+// bufferedRows is part of the rowBuffer interface.
+func (n *recursiveCTENode) bufferedRows() *sqlbase.RowContainer {
+	return n.run.workingRows
+}
+
+// Close is part of the planNode interface.
+func (n *recursiveCTENode) Close(ctx context.Context) {
+	n.initial.Close(ctx)
+	if n.run.iterating && n.run.source != nil {
+		n.run.source.Close(ctx)
+		n.run.source = nil
+	}
+	if n.run.workingRows != nil {
+		n.run.workingRows.Close(ctx)
+		n.run.workingRows = nil
+	}
+	if n.run.nextRows != nil {
+		n.run.nextRows.Close(ctx)
+		n.run.nextRows = nil
+	}
+	if n.run.seen != nil {
+		n.run.seenAcc.Close(ctx)
+		n.run.seen = nil
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type refreshMaterializedViewNode struct {
+	n    *tree.RefreshMaterializedView
+	desc *sqlbase.MutableTableDescriptor
+}
+
+// RefreshMaterializedView recomputes the contents of a materialized view.
+// Privileges: CREATE on view.
+//   Notes: postgres requires the view owner.
+//
+// The refresh is carried out by the schema changer: the results of the view
+// query are written into new indexes, which replace the current indexes of the
+// view once they are complete. The current contents of the view remain
+// readable in the meantime. CONCURRENTLY, which in postgres updates the
+// existing rows of the view in place, is not supported.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *tree.RefreshMaterializedView,
+) (planNode, error) {
+	if n.Concurrently {
+		err := pgerror.Unimplemented("refresh concurrently",
+			"REFRESH MATERIALIZED VIEW CONCURRENTLY is not supported")
+		err.Hint = "REFRESH MATERIALIZED VIEW does not block reads of the view"
+		return nil, err
+	}
+
+	desc, err := p.ResolveMutableTableDescriptor(ctx, &n.Name, true /* required */, requireViewDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MaterializedView() {
+		return nil, sqlbase.NewWrongObjectTypeError(&n.Name, "materialized view")
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	// The mutations of a table created in the current transaction are
+	// applied within the transaction, which the refresh does not support.
+	if desc.IsNewTable() {
+		return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot refresh materialized view %q in the transaction that created it", desc.Name)
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) startExec(params runParams) error {
+	if err := n.desc.AddMaterializedViewRefreshMutation(); err != nil {
+		return err
+	}
+
+	mutationID, err := params.p.createOrUpdateSchemaChangeJob(params.ctx, n.desc,
+		tree.AsStringWithFlags(n.n, tree.FmtAlwaysQualifyTableNames))
+	if err != nil {
+		return err
+	}
+	if err := params.p.writeSchemaChange(params.ctx, n.desc, mutationID); err != nil {
+		return err
+	}
+
+	// Record the refresh in the event log. This is an auditable log event and
+	// is recorded in the same transaction as the table descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogRefreshMaterializedView,
+		int32(n.desc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		struct {
+			ViewName   string
+			Statement  string
+			User       string
+			MutationID uint32
+		}{
+			n.n.Name.FQString(), n.n.String(), params.SessionData().User, uint32(mutationID),
+		},
+	)
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Values() tree.Datums          { return tree.Datums{} }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
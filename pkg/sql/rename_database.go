@@ -17,6 +17,7 @@ package sql
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -80,14 +81,28 @@ func (n *renameDatabaseNode) startExec(params runParams) error {
 	lookupFlags := p.CommonLookupFlags(true /*required*/)
 	// DDL statements bypass the cache.
 	lookupFlags.avoidCached = true
-	tbNames, err := phyAccessor.GetObjectNames(
-		ctx, p.txn, dbDesc, tree.PublicSchema, DatabaseListFlags{
-			CommonLookupFlags: lookupFlags,
-			explicitPrefix:    true,
-		})
+	schemas, err := listUserSchemas(ctx, p.txn, dbDesc.ID)
 	if err != nil {
 		return err
 	}
+	scNames := make([]string, 0, len(schemas)+1)
+	for scName := range schemas {
+		scNames = append(scNames, scName)
+	}
+	sort.Strings(scNames)
+	scNames = append([]string{tree.PublicSchema}, scNames...)
+	var tbNames TableNames
+	for _, scName := range scNames {
+		scTbNames, err := phyAccessor.GetObjectNames(
+			ctx, p.txn, dbDesc, scName, DatabaseListFlags{
+				CommonLookupFlags: lookupFlags,
+				explicitPrefix:    true,
+			})
+		if err != nil {
+			return err
+		}
+		tbNames = append(tbNames, scTbNames...)
+	}
 	lookupFlags.required = false
 	for i := range tbNames {
 		objDesc, _, err := phyAccessor.GetObjectDesc(ctx, p.txn, &tbNames[i],
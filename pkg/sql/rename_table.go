@@ -20,8 +20,10 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -83,14 +85,24 @@ func (n *renameTableNode) startExec(params runParams) error {
 	newTn := n.newTn
 	tableDesc := n.tableDesc
 
-	prevDbDesc, err := p.ResolveUncachedDatabase(ctx, oldTn)
-	if err != nil {
-		return err
+	var targetDbDesc *DatabaseDescriptor
+	var err error
+	if tableDesc.Temporary {
+		// Temporary tables can only be renamed within their temporary schema.
+		if (newTn.ExplicitCatalog && newTn.Catalog() != oldTn.Catalog()) ||
+			(newTn.ExplicitSchema && newTn.Schema() != oldTn.Schema() &&
+				newTn.Schema() != sessiondata.PgTempSchemaName) {
+			return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"cannot move temporary table %q out of its temporary schema", tree.ErrString(oldTn))
+		}
+		newTn.CatalogName = oldTn.CatalogName
+		newTn.SchemaName = oldTn.SchemaName
+		targetDbDesc, err = MustGetDatabaseDescByID(ctx, p.txn, tableDesc.ParentID)
+	} else {
+		// Check if target database exists.
+		// We also look at uncached descriptors here.
+		targetDbDesc, err = p.ResolveUncachedDatabase(ctx, newTn)
 	}
-
-	// Check if target database exists.
-	// We also look at uncached descriptors here.
-	targetDbDesc, err := p.ResolveUncachedDatabase(ctx, newTn)
 	if err != nil {
 		return err
 	}
@@ -107,11 +119,27 @@ func (n *renameTableNode) startExec(params runParams) error {
 		return nil
 	}
 
+	// The names of temporary tables and of the tables of user-defined
+	// schemas are recorded under their schema, not their database.
+	prevParentID := tableDesc.GetNamespaceParentID()
+
 	tableDesc.SetName(newTn.Table())
 	tableDesc.ParentID = targetDbDesc.ID
+	if !tableDesc.Temporary {
+		// The names of the tables of user-defined schemas are recorded under
+		// their schema.
+		parentSchemaID, err := p.resolveCreateSchemaID(ctx, targetDbDesc, newTn)
+		if err != nil {
+			return err
+		}
+		tableDesc.UnexposedParentSchemaID = 0
+		if parentSchemaID != targetDbDesc.ID {
+			tableDesc.UnexposedParentSchemaID = parentSchemaID
+		}
+	}
 
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
-	newTbKey := tableKey{targetDbDesc.ID, newTn.Table()}.Key()
+	newTbKey := tableKey{tableDesc.GetNamespaceParentID(), newTn.Table()}.Key()
 
 	if err := tableDesc.Validate(ctx, p.txn, p.EvalContext().Settings); err != nil {
 		return err
@@ -121,7 +149,7 @@ func (n *renameTableNode) startExec(params runParams) error {
 	descDesc := sqlbase.WrapDescriptor(tableDesc)
 
 	renameDetails := sqlbase.TableDescriptor_NameInfo{
-		ParentID: prevDbDesc.ID,
+		ParentID: prevParentID,
 		Name:     oldTn.Table()}
 	tableDesc.DrainingNames = append(tableDesc.DrainingNames, renameDetails)
 	if err := p.writeSchemaChange(ctx, tableDesc, sqlbase.InvalidMutationID); err != nil {
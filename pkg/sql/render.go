@@ -99,8 +99,15 @@ func (p *planner) Select(
 	orderBy := n.OrderBy
 	with := n.With
 
+	if err := checkLockingClause(n.Locking); err != nil {
+		return nil, err
+	}
+
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
+		if err := checkLockingClause(s.Select.Locking); err != nil {
+			return nil, err
+		}
 		if s.Select.With != nil {
 			if with != nil {
 				return nil, pgerror.UnimplementedWithIssueError(24303,
@@ -164,6 +171,22 @@ func (p *planner) Select(
 	}
 }
 
+// checkLockingClause returns an error if a locking clause (FOR UPDATE etc) is
+// specified; row-level locking is only supported by the cost-based optimizer.
+func checkLockingClause(locking tree.LockingClause) error {
+	for _, item := range locking {
+		if item.WaitPolicy != tree.LockWaitBlock {
+			return pgerror.Unimplemented("locking wait policy",
+				"%s is not supported", item.WaitPolicy)
+		}
+	}
+	if len(locking) > 0 {
+		return pgerror.UnimplementedWithIssueErrorf(6583,
+			"%s is only supported by the cost-based optimizer", locking[0].Strength)
+	}
+	return nil
+}
+
 // SelectClause selects rows from a single table. Select is the workhorse of the
 // SQL statements. In the slowest and most general case, select must perform
 // full table scans across multiple tables and sort and join the resulting rows
@@ -497,15 +520,21 @@ func (p *planner) getTimestamp(asOf tree.AsOfClause) (hlc.Timestamp, bool, error
 		// level. We accept AS OF SYSTEM TIME in multiple places (e.g. in
 		// subqueries or view queries) but they must all point to the same
 		// timestamp.
-		ts, err := p.EvalAsOfTimestamp(asOf, hlc.MaxTimestamp)
+		// For bounded staleness reads, the clauses must all specify the
+		// same staleness bound.
+		asOfTS, err := tree.EvalAsOf(asOf, hlc.MaxTimestamp, &p.semaCtx, p.EvalContext())
 		if err != nil {
 			return hlc.MaxTimestamp, false, err
 		}
-		if ts != *p.semaCtx.AsOfTimestamp {
+		expected := *p.semaCtx.AsOfTimestamp
+		if asOfTS.BoundedStaleness {
+			expected = p.semaCtx.AsOfMinTimestamp
+		}
+		if asOfTS.Timestamp != expected {
 			return hlc.MaxTimestamp, false,
 				fmt.Errorf("cannot specify AS OF SYSTEM TIME with different timestamps")
 		}
-		return ts, true, nil
+		return *p.semaCtx.AsOfTimestamp, true, nil
 	}
 	return hlc.MaxTimestamp, false, nil
 }
@@ -218,7 +218,9 @@ func ResolveTargetObject(
 			"cannot create %q because the target database or schema does not exist",
 			tree.ErrString(tn)).SetHintf("verify that the current database and search_path are valid and/or the target database exists")
 	}
-	if tn.Schema() != tree.PublicSchema {
+	// Objects can be created in the public schema and in user-defined
+	// schemas. The temporary schemas are handled by resolveCreateTableTarget.
+	if tn.Schema() != tree.PublicSchema && !isUserSchemaName(tn.Schema()) {
 		return nil, pgerror.NewErrorf(pgerror.CodeInvalidNameError,
 			"schema cannot be modified: %q", tree.ErrString(&tn.TableNamePrefix))
 	}
@@ -262,7 +264,11 @@ func (p *planner) LookupSchema(
 	if err != nil || dbDesc == nil {
 		return false, nil, err
 	}
-	return sc.IsValidSchema(dbDesc, scName), dbDesc, nil
+	found, err = sc.IsValidSchema(ctx, p.txn, dbDesc, scName)
+	if err != nil || !found {
+		return false, nil, err
+	}
+	return true, dbDesc, nil
 }
 
 // LookupObject implements the tree.TableNameExistingResolver interface.
@@ -311,6 +317,31 @@ func getDescriptorsFromTargetList(
 		return descs, nil
 	}
 
+	if targets.Schemas != nil {
+		if p.CurrentDatabase() == "" {
+			return nil, errNoDatabase
+		}
+		dbDesc, err := p.ResolveUncachedDatabaseByName(ctx, p.CurrentDatabase(), true /*required*/)
+		if err != nil {
+			return nil, err
+		}
+		descs := make([]sqlbase.DescriptorProto, 0, len(targets.Schemas))
+		for _, schema := range targets.Schemas {
+			descriptor, err := getUserSchemaDesc(ctx, p.txn, dbDesc.ID, string(schema))
+			if err != nil {
+				return nil, err
+			}
+			if descriptor == nil {
+				return nil, sqlbase.NewUndefinedSchemaError(string(schema))
+			}
+			descs = append(descs, descriptor)
+		}
+		if len(descs) == 0 {
+			return nil, errNoMatch
+		}
+		return descs, nil
+	}
+
 	if len(targets.Tables) == 0 {
 		return nil, errNoTable
 	}
@@ -349,6 +380,13 @@ func (p *planner) getQualifiedTableName(
 		return "", err
 	}
 	tbName := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(desc.Name))
+	if desc.UnexposedParentSchemaID != 0 && !desc.Temporary {
+		scDesc := &sqlbase.SchemaDescriptor{}
+		if err := getDescriptorByID(ctx, p.txn, desc.UnexposedParentSchemaID, scDesc); err != nil {
+			return "", err
+		}
+		tbName.SchemaName = tree.Name(scDesc.Name)
+	}
 	return tbName.String(), nil
 }
 
@@ -602,6 +640,7 @@ type internalLookupCtx struct {
 	dbNames map[sqlbase.ID]string
 	dbIDs   []sqlbase.ID
 	dbDescs map[sqlbase.ID]*DatabaseDescriptor
+	scDescs map[sqlbase.ID]*sqlbase.SchemaDescriptor
 	tbDescs map[sqlbase.ID]*TableDescriptor
 	tbIDs   []sqlbase.ID
 }
@@ -615,6 +654,7 @@ func newInternalLookupCtx(
 ) *internalLookupCtx {
 	dbNames := make(map[sqlbase.ID]string)
 	dbDescs := make(map[sqlbase.ID]*DatabaseDescriptor)
+	scDescs := make(map[sqlbase.ID]*sqlbase.SchemaDescriptor)
 	tbDescs := make(map[sqlbase.ID]*TableDescriptor)
 	var tbIDs, dbIDs []sqlbase.ID
 	// Record database descriptors for name lookups.
@@ -626,6 +666,8 @@ func newInternalLookupCtx(
 			if prefix == nil || prefix.ID == d.ID {
 				dbIDs = append(dbIDs, d.ID)
 			}
+		case *sqlbase.SchemaDescriptor:
+			scDescs[d.ID] = d
 		case *sqlbase.TableDescriptor:
 			tbDescs[d.ID] = d
 			if prefix == nil || prefix.ID == d.ParentID {
@@ -637,6 +679,7 @@ func newInternalLookupCtx(
 	return &internalLookupCtx{
 		dbNames: dbNames,
 		dbDescs: dbDescs,
+		scDescs: scDescs,
 		tbDescs: tbDescs,
 		tbIDs:   tbIDs,
 		dbIDs:   dbIDs,
@@ -34,6 +34,9 @@ type cascader struct {
 	alloc      *sqlbase.DatumAlloc
 	evalCtx    *tree.EvalContext
 
+	// deferredChecks, if set, is passed on to the row deleters and updaters.
+	deferredChecks *DeferredChecks
+
 	indexPKRowFetchers map[ID]map[sqlbase.IndexID]Fetcher // PK RowFetchers by Table ID and Index ID
 
 	// Row Deleters
@@ -417,6 +420,7 @@ func (c *cascader) addRowDeleter(
 	if err != nil {
 		return Deleter{}, Fetcher{}, err
 	}
+	rowDeleter.SetDeferredChecks(c.deferredChecks)
 
 	// Create the row fetcher that will retrive the rows and columns needed for
 	// deletion.
@@ -472,11 +476,14 @@ func (c *cascader) addRowUpdater(
 		table.Columns,
 		nil, /* requestedCol */
 		UpdaterDefault,
+		CheckFKs,
+		c.evalCtx,
 		c.alloc,
 	)
 	if err != nil {
 		return Updater{}, Fetcher{}, err
 	}
+	rowUpdater.SetDeferredChecks(c.deferredChecks)
 
 	// Create the row fetcher that will retrive the rows and columns needed for
 	// deletion.
@@ -1137,6 +1144,14 @@ func (c *cascader) cascadeAll(
 		}
 	}
 
+	// Check the deferrable unique constraints of the updated rows, unless they
+	// are deferred.
+	for _, rowUpdater := range c.rowUpdaters {
+		if err := rowUpdater.uniqueChecks.Run(ctx, c.txn); err != nil {
+			return err
+		}
+	}
+
 	// Check all foreign key constraints that have been affected by the cascading
 	// operation.
 
@@ -38,7 +38,7 @@ import (
 // Only unique secondary indexes have extra columns to decode (namely the
 // primary index columns).
 func cHasExtraCols(table *cTableInfo) bool {
-	return table.isSecondaryIndex && table.index.Unique
+	return table.isSecondaryIndex && table.index.HasUniqueKeys()
 }
 
 type cTableInfo struct {
@@ -422,7 +422,9 @@ func (rf *CFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVBatchFetcher(
+		txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo, tree.LockWaitBlock,
+	)
 	if err != nil {
 		return err
 	}
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/scrub"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -199,6 +200,16 @@ type Fetcher struct {
 	// If set, GetRangeInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// locking, if set, indicates that the caller acquires row-level locks on
+	// the rows returned by NextRow using LockRow. It is used for SELECT FOR
+	// UPDATE. lockWaitPolicy determines how rows which are locked by other
+	// transactions are dealt with.
+	locking        bool
+	lockWaitPolicy tree.LockingWaitPolicy
+	// lockRowPrefix is the row prefix of the keys of the row last returned by
+	// NextRow, if locking is set.
+	lockRowPrefix roachpb.Key
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -433,6 +444,59 @@ func (rf *Fetcher) Init(
 	return nil
 }
 
+// SetLocking configures the Fetcher for a scan whose caller acquires
+// row-level locks with the given wait policy on the rows that it returns,
+// using LockRow. It must be called before StartScan, and it can only be used
+// to scan the primary index of a single table.
+func (rf *Fetcher) SetLocking(waitPolicy tree.LockingWaitPolicy) {
+	rf.locking = true
+	rf.lockWaitPolicy = waitPolicy
+}
+
+// LockRow acquires an exclusive row-level lock on the row last returned by
+// NextRow for the given transaction, by locking the keys of all of the
+// column families of the row. The lock is held until the transaction
+// finishes. LockRow returns false if the row is locked by another
+// transaction and the wait policy is LockWaitSkip, in which case the row
+// should be skipped. With LockWaitError, an error is returned instead.
+func (rf *Fetcher) LockRow(ctx context.Context, txn *client.Txn) (bool, error) {
+	if !rf.locking {
+		return false, pgerror.NewAssertionErrorf("LockRow called on a Fetcher without locking")
+	}
+	desc := rf.rowReadyTable.desc
+	b := txn.NewBatch()
+	if rf.lockWaitPolicy != tree.LockWaitBlock {
+		b.Header.WaitPolicy = roachpb.WaitPolicy_Error
+	}
+	for i := range desc.Families {
+		// Lock the keys of all of the families, including the ones which
+		// aren't present in the row, since writers would create them.
+		prefix := rf.lockRowPrefix[:len(rf.lockRowPrefix):len(rf.lockRowPrefix)]
+		b.Lock(keys.MakeFamilyKey(prefix, uint32(desc.Families[i].ID)))
+	}
+	if rf.traceKV {
+		log.VEventf(ctx, 2, "Lock %s", rf.lockRowPrefix)
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		if _, ok := err.(*roachpb.WriteIntentError); ok && rf.lockWaitPolicy == tree.LockWaitSkip {
+			return false, nil
+		}
+		return false, rf.convertLockError(err)
+	}
+	return true, nil
+}
+
+// convertLockError converts a WriteIntentError, which the scans and locks of
+// a Fetcher with the LockWaitError wait policy return for rows that are
+// locked by other transactions, into the error that NOWAIT raises.
+func (rf *Fetcher) convertLockError(err error) error {
+	if _, ok := err.(*roachpb.WriteIntentError); ok && rf.lockWaitPolicy == tree.LockWaitError {
+		return pgerror.NewErrorf(pgerror.CodeLockNotAvailableError,
+			"could not obtain lock on row in relation %q", rf.rowReadyTable.desc.Name)
+	}
+	return err
+}
+
 // StartScan initializes and starts the key-value scan. Can be used multiple
 // times.
 func (rf *Fetcher) StartScan(
@@ -463,7 +527,9 @@ func (rf *Fetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVBatchFetcher(
+		txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo, rf.lockWaitPolicy,
+	)
 	if err != nil {
 		return err
 	}
@@ -488,7 +554,7 @@ func (rf *Fetcher) NextKey(ctx context.Context) (rowDone bool, err error) {
 	for {
 		ok, rf.kv, _, err = rf.kvFetcher.nextKV(ctx)
 		if err != nil {
-			return false, err
+			return false, rf.convertLockError(err)
 		}
 		rf.kvEnd = !ok
 		if rf.kvEnd {
@@ -974,6 +1040,15 @@ func (rf *Fetcher) NextRow(
 	// ID to lookup the column and decode the value. All of these values go
 	// into a map keyed by column name. When the index key changes we
 	// output a row containing the current values.
+	if rf.locking {
+		rowPrefix, err := keys.EnsureSafeSplitKey(rf.kv.Key)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		// The key is copied since it aliases the fetched batch, which is
+		// overwritten by subsequent fetches.
+		rf.lockRowPrefix = append(rf.lockRowPrefix[:0], rowPrefix...)
+	}
 	for {
 		prettyKey, prettyVal, err := rf.processKV(ctx, rf.kv)
 		if err != nil {
@@ -1303,7 +1378,7 @@ func (rf *Fetcher) GetRangeInfo() []roachpb.RangeInfo {
 // Only unique secondary indexes have extra columns to decode (namely the
 // primary index columns).
 func hasExtraCols(table *tableInfo) bool {
-	return table.isSecondaryIndex && table.index.Unique
+	return table.isSecondaryIndex && table.index.HasUniqueKeys()
 }
 
 // consumeIndexKeyWithoutTableIDIndexIDPrefix consumes an index key that's
@@ -271,6 +271,23 @@ func TablesNeededForFKs(
 	}
 }
 
+// TablesNeededWithoutFKs returns a TableLookupsByID that contains only the
+// given table. It is used instead of TablesNeededForFKs by mutations whose
+// foreign key checks and cascades are planned by the optimizer, and whose row
+// writers are created with SkipFKs. The AnalyzeExpr function, if provided, is
+// used to initialize the CheckHelper of the table.
+func TablesNeededWithoutFKs(
+	ctx context.Context,
+	table *sqlbase.ImmutableTableDescriptor,
+	analyzeExpr sqlbase.AnalyzeExprFunction,
+) (TableLookupsByID, error) {
+	tableLookup := TableLookup{Table: table}
+	if err := tableLookup.addCheckHelper(ctx, analyzeExpr); err != nil {
+		return nil, err
+	}
+	return TableLookupsByID{table.ID: tableLookup}, nil
+}
+
 // SpanKVFetcher is an kvBatchFetcher that returns a set slice of kvs.
 type SpanKVFetcher struct {
 	KVs []roachpb.KeyValue
@@ -302,6 +319,9 @@ type fkBatchChecker struct {
 	// to the baseFKHelper that created it.
 	batchIdxToFk []*baseFKHelper
 	txn          *client.Txn
+	// deferred, if set, queues the checks of the deferred foreign key
+	// constraints until the end of the transaction.
+	deferred *DeferredChecks
 }
 
 func (f *fkBatchChecker) reset() {
@@ -313,6 +333,12 @@ func (f *fkBatchChecker) reset() {
 func (f *fkBatchChecker) addCheck(
 	ctx context.Context, row tree.Datums, source *baseFKHelper, traceKV bool,
 ) error {
+	if f.deferred != nil {
+		deferred, err := f.deferred.maybeAdd(source, row)
+		if err != nil || deferred {
+			return err
+		}
+	}
 	span, err := source.spanForValues(row)
 	if err != nil {
 		return err
@@ -419,7 +445,7 @@ func makeFKInsertHelper(
 	}
 	for _, idx := range table.AllNonDropIndexes() {
 		if idx.ForeignKey.IsSet() {
-			fk, err := makeBaseFKHelper(txn, otherTables, idx, idx.ForeignKey, colMap, alloc, CheckInserts)
+			fk, err := makeBaseFKHelper(txn, table, otherTables, idx, idx.ForeignKey, colMap, alloc, CheckInserts)
 			if err == errSkipUnusedFK {
 				continue
 			}
@@ -547,7 +573,7 @@ func makeFKDeleteHelper(
 				// and thus does not need to be checked for FK violations.
 				continue
 			}
-			fk, err := makeBaseFKHelper(txn, otherTables, idx, ref, colMap, alloc, CheckDeletes)
+			fk, err := makeBaseFKHelper(txn, table, otherTables, idx, ref, colMap, alloc, CheckDeletes)
 			if err == errSkipUnusedFK {
 				continue
 			}
@@ -617,6 +643,10 @@ func makeFKUpdateHelper(
 func (fks fkUpdateHelper) addCheckForIndex(
 	indexID sqlbase.IndexID, descriptorType sqlbase.IndexDescriptor_Type,
 ) {
+	// The map is nil when the Updater was created with SkipFKs.
+	if fks.indexIDsToCheck == nil {
+		return
+	}
 	if descriptorType == sqlbase.IndexDescriptor_FORWARD {
 		fks.indexIDsToCheck[indexID] = struct{}{}
 	}
@@ -666,15 +696,19 @@ type baseFKHelper struct {
 	searchTable  *sqlbase.ImmutableTableDescriptor // the table being searched (for err msg)
 	searchIdx    *sqlbase.IndexDescriptor          // the index that must (not) contain a value
 	prefixLen    int
-	writeIdx     sqlbase.IndexDescriptor  // the index we want to modify
-	searchPrefix []byte                   // prefix of keys in searchIdx
-	ids          map[sqlbase.ColumnID]int // col IDs
-	dir          FKCheck                  // direction of check
+	writeTable   *sqlbase.ImmutableTableDescriptor // the table we want to modify
+	writeIdx     sqlbase.IndexDescriptor           // the index we want to modify
+	searchPrefix []byte                            // prefix of keys in searchIdx
+	writePrefix  []byte                            // prefix of keys in writeIdx
+	ids          map[sqlbase.ColumnID]int          // col IDs
+	writeIds     map[sqlbase.ColumnID]int          // col IDs in writeIdx
+	dir          FKCheck                           // direction of check
 	ref          sqlbase.ForeignKeyReference
 }
 
 func makeBaseFKHelper(
 	txn *client.Txn,
+	writeTable *sqlbase.ImmutableTableDescriptor,
 	otherTables TableLookupsByID,
 	writeIdx sqlbase.IndexDescriptor,
 	ref sqlbase.ForeignKeyReference,
@@ -684,6 +718,7 @@ func makeBaseFKHelper(
 ) (baseFKHelper, error) {
 	b := baseFKHelper{
 		txn:         txn,
+		writeTable:  writeTable,
 		writeIdx:    writeIdx,
 		searchTable: otherTables[ref.Table].Table,
 		dir:         dir,
@@ -693,6 +728,7 @@ func makeBaseFKHelper(
 		return b, errors.Errorf("referenced table %d not in provided table map %+v", ref.Table, otherTables)
 	}
 	b.searchPrefix = sqlbase.MakeIndexKeyPrefix(b.searchTable.TableDesc(), ref.Index)
+	b.writePrefix = sqlbase.MakeIndexKeyPrefix(writeTable.TableDesc(), writeIdx.ID)
 	searchIdx, err := b.searchTable.FindIndexByID(ref.Index)
 	if err != nil {
 		return b, err
@@ -718,11 +754,13 @@ func makeBaseFKHelper(
 	// https://www.postgresql.org/docs/11/sql-createtable.html for details on the
 	// different composite foreign key matching methods.
 	b.ids = make(map[sqlbase.ColumnID]int, len(writeIdx.ColumnIDs))
+	b.writeIds = make(map[sqlbase.ColumnID]int, len(writeIdx.ColumnIDs))
 	switch ref.Match {
 	case sqlbase.ForeignKeyReference_SIMPLE:
 		for i, writeColID := range writeIdx.ColumnIDs[:b.prefixLen] {
 			if found, ok := colMap[writeColID]; ok {
 				b.ids[searchIdx.ColumnIDs[i]] = found
+				b.writeIds[writeColID] = found
 			} else {
 				return b, errSkipUnusedFK
 			}
@@ -733,6 +771,7 @@ func makeBaseFKHelper(
 		for i, writeColID := range writeIdx.ColumnIDs[:b.prefixLen] {
 			if found, ok := colMap[writeColID]; ok {
 				b.ids[searchIdx.ColumnIDs[i]] = found
+				b.writeIds[writeColID] = found
 			} else {
 				missingColumns = append(missingColumns, writeIdx.ColumnNames[i])
 			}
@@ -0,0 +1,387 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package row
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// deferredCheckBatchSize is the maximum number of foreign key or uniqueness
+// checks that are sent to KV in a single batch.
+const deferredCheckBatchSize = 1000
+
+// checkMode is the mode of a foreign key or unique constraint set by SET
+// CONSTRAINTS.
+type checkMode int
+
+const (
+	// checkModeDefault means that the mode wasn't set, in which case the constraint
+	// is checked according to its INITIALLY DEFERRED/IMMEDIATE clause.
+	checkModeDefault checkMode = iota
+	checkModeImmediate
+	checkModeDeferred
+)
+
+// DeferredChecks accumulates the checks of the deferrable foreign key and
+// unique constraints that are deferred until the end of a transaction.
+//
+// Unlike the checks performed after each row is written, a deferred check
+// doesn't capture the direction of the write that queued it: when it is run,
+// it verifies that if the referencing index contains the values that were
+// written, then the referenced index contains them too. This makes deferred
+// checks insensitive to the order in which the rows are written, and
+// harmless if the write that queued them was later undone. The same goes for
+// the deferred uniqueness checks, which verify that the unique index contains
+// at most one row with the values that were written.
+//
+// A DeferredChecks can be shared by the writers of concurrently executing
+// statements.
+type DeferredChecks struct {
+	mu struct {
+		syncutil.Mutex
+		// allMode is the mode set by SET CONSTRAINTS ALL.
+		allMode checkMode
+		// modes are the modes set by SET CONSTRAINTS for specific constraints,
+		// by constraint name. They take precedence over allMode.
+		modes  map[string]checkMode
+		checks []deferredFKCheck
+		// seen is used to avoid queuing the same check multiple times, which
+		// commonly happens when many rows reference the same row.
+		seen map[deferredFKCheckKey]struct{}
+		// uniqueChecks and uniqueSeen are the same as checks and seen for the
+		// unique constraints.
+		uniqueChecks []uniqueCheck
+		uniqueSeen   map[string]struct{}
+	}
+}
+
+// deferredFKCheckSide is one side of a deferred foreign key check: the span of
+// an index which contains the values being checked.
+type deferredFKCheckSide struct {
+	table *sqlbase.ImmutableTableDescriptor
+	index *sqlbase.IndexDescriptor
+	span  roachpb.Span
+}
+
+type deferredFKCheck struct {
+	name              string
+	initiallyDeferred bool
+
+	referencing deferredFKCheckSide
+	referenced  deferredFKCheckSide
+	prefixLen   int
+	// values are the values of the foreign key columns (for err msg).
+	values tree.Datums
+}
+
+type deferredFKCheckKey struct {
+	name                    string
+	referencing, referenced string
+}
+
+func (c *deferredFKCheck) key() deferredFKCheckKey {
+	return deferredFKCheckKey{
+		name:        c.name,
+		referencing: string(c.referencing.span.Key),
+		referenced:  string(c.referenced.span.Key),
+	}
+}
+
+// Reset clears the queued checks and the modes set by SET CONSTRAINTS. It is
+// called when a transaction finishes or restarts.
+func (d *DeferredChecks) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.allMode = checkModeDefault
+	d.mu.modes = nil
+	d.mu.checks = nil
+	d.mu.seen = nil
+	d.mu.uniqueChecks = nil
+	d.mu.uniqueSeen = nil
+}
+
+// SetAllDeferred implements SET CONSTRAINTS ALL {DEFERRED | IMMEDIATE}. The
+// checks which are no longer deferred must be run with RunImmediate.
+func (d *DeferredChecks) SetAllDeferred(deferred bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.mu.allMode = makeCheckMode(deferred)
+	d.mu.modes = nil
+}
+
+// SetDeferred implements SET CONSTRAINTS name [, ...] {DEFERRED | IMMEDIATE}.
+// The checks which are no longer deferred must be run with RunImmediate.
+func (d *DeferredChecks) SetDeferred(names []string, deferred bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.modes == nil {
+		d.mu.modes = make(map[string]checkMode, len(names))
+	}
+	for _, name := range names {
+		d.mu.modes[name] = makeCheckMode(deferred)
+	}
+}
+
+func makeCheckMode(deferred bool) checkMode {
+	if deferred {
+		return checkModeDeferred
+	}
+	return checkModeImmediate
+}
+
+// isDeferredLocked returns whether the checks of the constraint with the given
+// name are currently deferred. The constraint must be
+// deferrable.
+func (d *DeferredChecks) isDeferredLocked(name string, initiallyDeferred bool) bool {
+	mode := d.mu.modes[name]
+	if mode == checkModeDefault {
+		mode = d.mu.allMode
+	}
+	if mode == checkModeDefault {
+		return initiallyDeferred
+	}
+	return mode == checkModeDeferred
+}
+
+// maybeAdd queues the check of the given row by the given baseFKHelper if the
+// checks of its foreign key constraint are deferred. It returns false if the
+// check must be performed immediately instead.
+func (d *DeferredChecks) maybeAdd(f *baseFKHelper, row tree.Datums) (bool, error) {
+	// The constraint is described by the reference on the referencing side.
+	ref := &f.ref
+	if f.dir == CheckDeletes {
+		ref = &f.searchIdx.ForeignKey
+		// ON DELETE/UPDATE RESTRICT requires the referenced values to be checked
+		// immediately, even if the constraint is deferred.
+		if ref.OnDelete == sqlbase.ForeignKeyReference_RESTRICT ||
+			ref.OnUpdate == sqlbase.ForeignKeyReference_RESTRICT {
+			return false, nil
+		}
+	}
+	if !ref.Deferrable {
+		return false, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.isDeferredLocked(ref.Name, ref.InitiallyDeferred) {
+		return false, nil
+	}
+
+	searchSpan, err := f.spanForValues(row)
+	if err != nil {
+		return false, err
+	}
+	writeIdx, err := f.writeTable.FindIndexByID(f.writeIdx.ID)
+	if err != nil {
+		return false, err
+	}
+	writeSpan, _, err := sqlbase.EncodePartialIndexSpan(
+		f.writeTable.TableDesc(), writeIdx, f.prefixLen, f.writeIds, row, f.writePrefix)
+	if err != nil {
+		return false, err
+	}
+	search := deferredFKCheckSide{table: f.searchTable, index: f.searchIdx, span: searchSpan}
+	write := deferredFKCheckSide{table: f.writeTable, index: writeIdx, span: writeSpan}
+
+	c := deferredFKCheck{
+		name:              ref.Name,
+		initiallyDeferred: ref.InitiallyDeferred,
+		prefixLen:         f.prefixLen,
+	}
+	if f.dir == CheckInserts {
+		c.referencing, c.referenced = write, search
+	} else {
+		c.referencing, c.referenced = search, write
+	}
+	key := c.key()
+	if _, ok := d.mu.seen[key]; ok {
+		return true, nil
+	}
+	c.values = make(tree.Datums, f.prefixLen)
+	for valueIdx, colID := range f.searchIdx.ColumnIDs[:f.prefixLen] {
+		c.values[valueIdx] = row[f.ids[colID]]
+	}
+	if d.mu.seen == nil {
+		d.mu.seen = make(map[deferredFKCheckKey]struct{})
+	}
+	d.mu.seen[key] = struct{}{}
+	d.mu.checks = append(d.mu.checks, c)
+	return true, nil
+}
+
+// maybeAddUnique queues the given uniqueness check if the checks of its unique
+// constraint are deferred. It returns false if the check must be performed at
+// the end of the statement instead.
+func (d *DeferredChecks) maybeAddUnique(c uniqueCheck) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.isDeferredLocked(c.index.Name, c.index.InitiallyDeferred) {
+		return false
+	}
+	key := string(c.span.Key)
+	if _, ok := d.mu.uniqueSeen[key]; ok {
+		return true
+	}
+	if d.mu.uniqueSeen == nil {
+		d.mu.uniqueSeen = make(map[string]struct{})
+	}
+	d.mu.uniqueSeen[key] = struct{}{}
+	d.mu.uniqueChecks = append(d.mu.uniqueChecks, c)
+	return true
+}
+
+// RunAll runs all the queued checks. It is called before the transaction
+// commits. A pgerror.CodeForeignKeyViolationError or
+// pgerror.CodeUniqueViolationError is returned if a violation is detected.
+func (d *DeferredChecks) RunAll(ctx context.Context, txn *client.Txn) error {
+	d.mu.Lock()
+	checks, uniqueChecks := d.mu.checks, d.mu.uniqueChecks
+	d.mu.checks, d.mu.uniqueChecks = nil, nil
+	d.mu.seen, d.mu.uniqueSeen = nil, nil
+	d.mu.Unlock()
+	if err := runDeferredFKChecks(ctx, txn, checks); err != nil {
+		return err
+	}
+	return runUniqueChecks(ctx, txn, uniqueChecks)
+}
+
+// RunImmediate runs the queued checks of the constraints which are no longer
+// deferred, after SET CONSTRAINTS ... IMMEDIATE.
+func (d *DeferredChecks) RunImmediate(ctx context.Context, txn *client.Txn) error {
+	d.mu.Lock()
+	var immediate []deferredFKCheck
+	deferred := d.mu.checks[:0]
+	for _, c := range d.mu.checks {
+		if d.isDeferredLocked(c.name, c.initiallyDeferred) {
+			deferred = append(deferred, c)
+		} else {
+			immediate = append(immediate, c)
+			delete(d.mu.seen, c.key())
+		}
+	}
+	d.mu.checks = deferred
+	var immediateUnique []uniqueCheck
+	deferredUnique := d.mu.uniqueChecks[:0]
+	for _, c := range d.mu.uniqueChecks {
+		if d.isDeferredLocked(c.index.Name, c.index.InitiallyDeferred) {
+			deferredUnique = append(deferredUnique, c)
+		} else {
+			immediateUnique = append(immediateUnique, c)
+			delete(d.mu.uniqueSeen, string(c.span.Key))
+		}
+	}
+	d.mu.uniqueChecks = deferredUnique
+	d.mu.Unlock()
+	if err := runDeferredFKChecks(ctx, txn, immediate); err != nil {
+		return err
+	}
+	return runUniqueChecks(ctx, txn, immediateUnique)
+}
+
+// runDeferredFKChecks scans the referencing and referenced spans of the given
+// checks. There is a violation if a referencing span contains a row while the
+// corresponding referenced span doesn't.
+func runDeferredFKChecks(ctx context.Context, txn *client.Txn, checks []deferredFKCheck) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	log.VEventf(ctx, 2, "running %d deferred FK checks", len(checks))
+
+	var alloc sqlbase.DatumAlloc
+	type fetcherKey struct {
+		tableID ID
+		indexID sqlbase.IndexID
+	}
+	fetchers := make(map[fetcherKey]*Fetcher)
+	// isEmpty returns whether the scan response of the given side of a check
+	// contains no row of the side's index. The spans of interleaved indexes can
+	// contain the rows of other tables, so we decode the rows to find out.
+	isEmpty := func(side *deferredFKCheckSide, resp roachpb.ResponseUnion) (bool, error) {
+		k := fetcherKey{tableID: side.table.ID, indexID: side.index.ID}
+		rf, ok := fetchers[k]
+		if !ok {
+			rf = &Fetcher{}
+			tableArgs := FetcherTableArgs{
+				Desc:             side.table,
+				Index:            side.index,
+				ColIdxMap:        side.table.ColumnIdxMap(),
+				IsSecondaryIndex: side.index.ID != side.table.PrimaryIndex.ID,
+				Cols:             side.table.Columns,
+			}
+			if err := rf.Init(
+				false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, &alloc, tableArgs,
+			); err != nil {
+				return false, err
+			}
+			fetchers[k] = rf
+		}
+		fetcher := SpanKVFetcher{KVs: resp.GetInner().(*roachpb.ScanResponse).Rows}
+		if err := rf.StartScanFrom(ctx, &fetcher); err != nil {
+			return false, err
+		}
+		return rf.kvEnd, nil
+	}
+
+	for len(checks) > 0 {
+		batchChecks := checks
+		if len(batchChecks) > deferredCheckBatchSize {
+			batchChecks = batchChecks[:deferredCheckBatchSize]
+		}
+		checks = checks[len(batchChecks):]
+
+		var ba roachpb.BatchRequest
+		for i := range batchChecks {
+			c := &batchChecks[i]
+			ba.Add(
+				&roachpb.ScanRequest{RequestHeader: roachpb.RequestHeaderFromSpan(c.referencing.span)},
+				&roachpb.ScanRequest{RequestHeader: roachpb.RequestHeaderFromSpan(c.referenced.span)},
+			)
+		}
+		br, pErr := txn.Send(ctx, ba)
+		if pErr != nil {
+			return pErr.GoError()
+		}
+		for i := range batchChecks {
+			c := &batchChecks[i]
+			noReferencing, err := isEmpty(&c.referencing, br.Responses[2*i])
+			if err != nil {
+				return err
+			}
+			if noReferencing {
+				continue
+			}
+			noReferenced, err := isEmpty(&c.referenced, br.Responses[2*i+1])
+			if err != nil {
+				return err
+			}
+			if noReferenced {
+				return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+					"foreign key violation: value %s not found in %s@%s %s (deferred constraint %q)",
+					c.values, c.referenced.table.Name, c.referenced.index.Name,
+					c.referenced.index.ColumnNames[:c.prefixLen], c.name)
+			}
+		}
+	}
+	return nil
+}
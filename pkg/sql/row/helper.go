@@ -30,6 +30,10 @@ type rowHelper struct {
 	Indexes      []sqlbase.IndexDescriptor
 	indexEntries []sqlbase.IndexEntry
 
+	// predicates is used to skip the entries of the partial indexes for the
+	// rows that don't satisfy their predicate. See initPartialIndexPredicates.
+	predicates sqlbase.PartialIndexPredicates
+
 	// Computed during initialization for pretty-printing.
 	primIndexValDirs []encoding.Direction
 	secIndexValDirs  [][]encoding.Direction
@@ -57,6 +61,17 @@ func newRowHelper(
 	return rh
 }
 
+// initPartialIndexPredicates makes the rowHelper skip the entries of partial
+// indexes for the rows that don't satisfy the index predicate. A skipped entry
+// is reported as an IndexEntry with an empty key. Without it, entries are
+// produced for every index, which is only appropriate when deleting rows:
+// deleting an entry that was never written is a no-op.
+func (rh *rowHelper) initPartialIndexPredicates(evalCtx *tree.EvalContext) error {
+	var err error
+	rh.predicates, err = sqlbase.MakePartialIndexPredicates(rh.TableDesc.TableDesc(), rh.Indexes, evalCtx)
+	return err
+}
+
 // encodeIndexes encodes the primary and secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
 // encodeSecondaryIndexes.
@@ -93,6 +108,19 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 	if err != nil {
 		return nil, err
 	}
+	if rh.predicates.HasPredicates() {
+		// Partial indexes are never inverted, so their entry is always the one at
+		// the position of the index.
+		for i := range rh.Indexes {
+			ok, err := rh.predicates.Satisfies(i, colIDtoRowIndex, values)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				rh.indexEntries[i] = sqlbase.IndexEntry{}
+			}
+		}
+	}
 	return rh.indexEntries, nil
 }
 
@@ -19,7 +19,9 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -53,6 +55,12 @@ type txnKVFetcher struct {
 	// returnRangeInfo, if set, causes the kvBatchFetcher to populate rangeInfos.
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
+	// lockWaitPolicy is the wait policy of the row-level locks that are
+	// acquired on the fetched rows, if any. With LockWaitSkip and
+	// LockWaitError, the scans don't wait for conflicting transactions. Rows
+	// which are locked by other transactions are skipped in the former case,
+	// and return a WriteIntentError in the latter.
+	lockWaitPolicy tree.LockingWaitPolicy
 
 	fetchEnd bool
 	batchIdx int
@@ -136,6 +144,9 @@ func (f *txnKVFetcher) getBatchSizeForIdx(batchIdx int) int64 {
 // Subsequent batches are larger, up to kvBatchSize.
 //
 // Batch limits can only be used if the spans are ordered.
+//
+// lockWaitPolicy is the wait policy of the row-level locks that the caller
+// acquires on the fetched rows, if any.
 func makeKVBatchFetcher(
 	txn *client.Txn,
 	spans roachpb.Spans,
@@ -143,6 +154,7 @@ func makeKVBatchFetcher(
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
+	lockWaitPolicy tree.LockingWaitPolicy,
 ) (txnKVFetcher, error) {
 	if firstBatchLimit < 0 || (!useBatchLimit && firstBatchLimit != 0) {
 		return txnKVFetcher{}, errors.Errorf("invalid batch limit %d (useBatchLimit: %t)",
@@ -194,6 +206,7 @@ func makeKVBatchFetcher(
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
+		lockWaitPolicy:  lockWaitPolicy,
 	}, nil
 }
 
@@ -202,6 +215,9 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	var ba roachpb.BatchRequest
 	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
 	ba.Header.ReturnRangeInfo = f.returnRangeInfo
+	if f.lockWaitPolicy != tree.LockWaitBlock {
+		ba.Header.WaitPolicy = roachpb.WaitPolicy_Error
+	}
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
 	if f.reverse {
 		scans := make([]roachpb.ReverseScanRequest, len(f.spans))
@@ -241,6 +257,10 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 
 	br, err := f.txn.Send(ctx, ba)
 	if err != nil {
+		if wiErr, ok := err.GetDetail().(*roachpb.WriteIntentError); ok &&
+			f.lockWaitPolicy == tree.LockWaitSkip {
+			return f.skipLockedRows(ctx, wiErr)
+		}
 		return err.GoError()
 	}
 	if br != nil {
@@ -287,6 +307,60 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	return nil
 }
 
+// skipLockedRows retries the last fetch without the rows on which the given
+// WriteIntentError found intents of other transactions, which are considered
+// locked and skipped by SKIP LOCKED.
+func (f *txnKVFetcher) skipLockedRows(ctx context.Context, wiErr *roachpb.WriteIntentError) error {
+	spans := append(roachpb.Spans(nil), f.requestSpans...)
+	for _, intent := range wiErr.Intents {
+		rowPrefix, err := keys.EnsureSafeSplitKey(intent.Key)
+		if err != nil {
+			return err
+		}
+		rowSpan := roachpb.Span{Key: rowPrefix, EndKey: rowPrefix.PrefixEnd()}
+		if log.ExpensiveLogEnabled(ctx, 2) {
+			log.VEventf(ctx, 2, "skipping locked row %s", rowSpan)
+		}
+		spans = subtractSpan(spans, rowSpan, f.reverse)
+	}
+	if len(spans) == 0 {
+		f.fetchEnd = true
+		f.responses = nil
+		f.requestSpans = f.requestSpans[:0]
+		return nil
+	}
+	f.spans = spans
+	return f.fetch(ctx)
+}
+
+// subtractSpan returns the parts of the given spans that don't overlap with s,
+// preserving their order.
+func subtractSpan(spans roachpb.Spans, s roachpb.Span, reverse bool) roachpb.Spans {
+	res := make(roachpb.Spans, 0, len(spans)+1)
+	for _, sp := range spans {
+		if !sp.Overlaps(s) {
+			res = append(res, sp)
+			continue
+		}
+		var parts [2]roachpb.Span
+		n := 0
+		if sp.Key.Compare(s.Key) < 0 {
+			parts[n] = roachpb.Span{Key: sp.Key, EndKey: s.Key}
+			n++
+		}
+		if s.EndKey.Compare(sp.EndKey) < 0 {
+			parts[n] = roachpb.Span{Key: s.EndKey, EndKey: sp.EndKey}
+			n++
+		}
+		if reverse && n == 2 {
+			// Reverse scans receive the spans in decreasing order.
+			parts[0], parts[1] = parts[1], parts[0]
+		}
+		res = append(res, parts[:n]...)
+	}
+	return res
+}
+
 // nextBatch returns the next batch of key/value pairs. If there are none
 // available, a fetch is initiated. When there are no more keys, ok is false.
 // origSpan returns the span that batch was fetched from, and bounds all of the
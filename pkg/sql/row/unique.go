@@ -0,0 +1,336 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package row
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// UniqueChecks accumulates the uniqueness checks of the deferrable unique
+// constraints of a table for the rows written by a statement.
+//
+// The index of a deferrable unique constraint is encoded like a non-unique
+// index, so that the constraint can be violated temporarily. Instead of
+// relying on the conditional puts of the index entries, the writers queue a
+// check of the span of the index which contains the unique values of every
+// written entry. A check is run at the end of the statement which queued it,
+// unless the constraint is deferred, in which case it is handed to the
+// DeferredChecks of the transaction and run before the transaction commits.
+//
+// All the methods of UniqueChecks can be called on a nil receiver, which is
+// used when the table has no deferrable unique constraint.
+type UniqueChecks struct {
+	table   *sqlbase.ImmutableTableDescriptor
+	indexes []uniqueIndex
+	// deferred receives the checks of the deferred constraints.
+	deferred *DeferredChecks
+
+	checks []uniqueCheck
+	// seen is used to avoid queuing the same check multiple times.
+	seen map[string]struct{}
+}
+
+// uniqueIndex is a deferrable unique index written by a writer.
+type uniqueIndex struct {
+	// pos is the position of the index in the indexes written by the writer,
+	// and of its entries in the index entries of a row.
+	pos       int
+	index     *sqlbase.IndexDescriptor
+	keyPrefix []byte
+}
+
+// uniqueCheck is the check of the rows of a unique index which contain the
+// same unique values. There is a violation if the span contains more than one
+// row.
+type uniqueCheck struct {
+	table *sqlbase.ImmutableTableDescriptor
+	index *sqlbase.IndexDescriptor
+	span  roachpb.Span
+}
+
+// makeUniqueChecks returns the UniqueChecks of the given indexes of a table, or
+// nil if none of them is the index of a deferrable unique constraint.
+func makeUniqueChecks(
+	table *sqlbase.ImmutableTableDescriptor, indexes []sqlbase.IndexDescriptor,
+) *UniqueChecks {
+	var u *UniqueChecks
+	for i := range indexes {
+		if !indexes[i].Unique || !indexes[i].Deferrable {
+			continue
+		}
+		if u == nil {
+			u = &UniqueChecks{table: table}
+		}
+		u.indexes = append(u.indexes, uniqueIndex{
+			pos:       i,
+			index:     &indexes[i],
+			keyPrefix: sqlbase.MakeIndexKeyPrefix(table.TableDesc(), indexes[i].ID),
+		})
+	}
+	return u
+}
+
+// addRow queues the checks of the given row for the deferrable unique indexes
+// which have an entry for it. newEntries are the entries of the row in the
+// indexes written by the writer. If oldEntries is not nil, the checks are only
+// queued for the indexes in which the entry of the row changed.
+func (u *UniqueChecks) addRow(
+	colMap map[sqlbase.ColumnID]int,
+	values tree.Datums,
+	newEntries []sqlbase.IndexEntry,
+	oldEntries []sqlbase.IndexEntry,
+) error {
+	if u == nil {
+		return nil
+	}
+	for _, idx := range u.indexes {
+		// Partial indexes have no entry for the rows that don't satisfy their
+		// predicate.
+		newKey := newEntries[idx.pos].Key
+		if len(newKey) == 0 {
+			continue
+		}
+		if oldEntries != nil && bytes.Equal(newKey, oldEntries[idx.pos].Key) {
+			continue
+		}
+		if err := u.add(&idx, colMap, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *UniqueChecks) add(
+	idx *uniqueIndex, colMap map[sqlbase.ColumnID]int, values tree.Datums,
+) error {
+	key, containsNull, err := sqlbase.EncodePartialIndexKey(
+		u.table.TableDesc(), idx.index, len(idx.index.ColumnIDs), colMap, values, idx.keyPrefix,
+	)
+	if err != nil {
+		return err
+	}
+	// NULLs are distinct from each other, so rows with a NULL unique value
+	// never conflict.
+	if containsNull {
+		return nil
+	}
+	c := uniqueCheck{
+		table: u.table,
+		index: idx.index,
+		span:  roachpb.Span{Key: key, EndKey: roachpb.Key(key).PrefixEnd()},
+	}
+	if u.deferred != nil && u.deferred.maybeAddUnique(c) {
+		return nil
+	}
+	if _, ok := u.seen[string(key)]; ok {
+		return nil
+	}
+	if u.seen == nil {
+		u.seen = make(map[string]struct{})
+	}
+	u.seen[string(key)] = struct{}{}
+	u.checks = append(u.checks, c)
+	return nil
+}
+
+// Run runs the queued checks which aren't deferred. It is called at the end of
+// the statement which wrote the rows. A pgerror.CodeUniqueViolationError is
+// returned if a uniqueness violation is detected.
+func (u *UniqueChecks) Run(ctx context.Context, txn *client.Txn) error {
+	if u == nil {
+		return nil
+	}
+	checks := u.checks
+	u.checks = nil
+	u.seen = nil
+	return runUniqueChecks(ctx, txn, checks)
+}
+
+// Pending returns whether there are queued checks to run.
+func (u *UniqueChecks) Pending() bool {
+	return u != nil && len(u.checks) > 0
+}
+
+// runUniqueChecks scans the spans of the given checks. There is a violation if
+// a span contains more than one row.
+func runUniqueChecks(ctx context.Context, txn *client.Txn, checks []uniqueCheck) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	log.VEventf(ctx, 2, "running %d unique checks", len(checks))
+
+	var alloc sqlbase.DatumAlloc
+	type fetcherKey struct {
+		tableID ID
+		indexID sqlbase.IndexID
+	}
+	fetchers := make(map[fetcherKey]*Fetcher)
+	// violation returns the values of the first row of the scan response of
+	// the given check if the response contains more than one row of the index.
+	// The spans of interleaved indexes can contain the rows of other tables, so
+	// we decode the rows to find out.
+	violation := func(c *uniqueCheck, resp roachpb.ResponseUnion) (tree.Datums, error) {
+		k := fetcherKey{tableID: c.table.ID, indexID: c.index.ID}
+		rf, ok := fetchers[k]
+		if !ok {
+			var err error
+			if rf, _, err = makeUniqueIndexFetcher(c.table, c.index, &alloc); err != nil {
+				return nil, err
+			}
+			fetchers[k] = rf
+		}
+		fetcher := SpanKVFetcher{KVs: resp.GetInner().(*roachpb.ScanResponse).Rows}
+		if err := rf.StartScanFrom(ctx, &fetcher); err != nil {
+			return nil, err
+		}
+		var first tree.Datums
+		for {
+			datums, _, _, err := rf.NextRowDecoded(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if datums == nil {
+				return nil, nil
+			}
+			if first != nil {
+				return first, nil
+			}
+			first = append(tree.Datums(nil), datums...)
+		}
+	}
+
+	for len(checks) > 0 {
+		batchChecks := checks
+		if len(batchChecks) > deferredCheckBatchSize {
+			batchChecks = batchChecks[:deferredCheckBatchSize]
+		}
+		checks = checks[len(batchChecks):]
+
+		var ba roachpb.BatchRequest
+		for i := range batchChecks {
+			ba.Add(&roachpb.ScanRequest{RequestHeader: roachpb.RequestHeaderFromSpan(batchChecks[i].span)})
+		}
+		br, pErr := txn.Send(ctx, ba)
+		if pErr != nil {
+			return pErr.GoError()
+		}
+		for i := range batchChecks {
+			c := &batchChecks[i]
+			values, err := violation(c, br.Responses[i])
+			if err != nil {
+				return err
+			}
+			if values != nil {
+				return NewUniquenessConstraintViolationError(c.index, values)
+			}
+		}
+	}
+	return nil
+}
+
+// makeUniqueIndexFetcher returns a Fetcher which decodes the values of the
+// unique columns of the given index, along with the map of the IDs of these
+// columns to their position in the decoded rows.
+func makeUniqueIndexFetcher(
+	table *sqlbase.ImmutableTableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	alloc *sqlbase.DatumAlloc,
+) (*Fetcher, map[sqlbase.ColumnID]int, error) {
+	var valNeededForCol util.FastIntSet
+	valNeededForCol.AddRange(0, len(index.ColumnIDs)-1)
+	colIdxMap := make(map[sqlbase.ColumnID]int, len(index.ColumnIDs))
+	cols := make([]sqlbase.ColumnDescriptor, len(index.ColumnIDs))
+	for i, colID := range index.ColumnIDs {
+		colIdxMap[colID] = i
+		col, err := table.FindColumnByID(colID)
+		if err != nil {
+			return nil, nil, err
+		}
+		cols[i] = *col
+	}
+	rf := &Fetcher{}
+	tableArgs := FetcherTableArgs{
+		Desc:             table,
+		Index:            index,
+		ColIdxMap:        colIdxMap,
+		IsSecondaryIndex: true,
+		Cols:             cols,
+		ValNeededForCol:  valNeededForCol,
+	}
+	if err := rf.Init(
+		false /* reverse */, false /* returnRangeInfo */, false /* isCheck */, alloc, tableArgs,
+	); err != nil {
+		return nil, nil, err
+	}
+	return rf, colIdxMap, nil
+}
+
+// ValidateUniqueIndex scans the given deferrable unique index and returns a
+// pgerror.CodeUniqueViolationError if two of its rows contain the same unique
+// values. It is used to validate the index of a deferrable unique constraint
+// added to an existing table: the backfill of such an index doesn't detect
+// the duplicates, since it is encoded like a non-unique index.
+func ValidateUniqueIndex(
+	ctx context.Context,
+	txn *client.Txn,
+	table *sqlbase.ImmutableTableDescriptor,
+	index *sqlbase.IndexDescriptor,
+) error {
+	var alloc sqlbase.DatumAlloc
+	rf, colIdxMap, err := makeUniqueIndexFetcher(table, index, &alloc)
+	if err != nil {
+		return err
+	}
+	if err := rf.StartScan(
+		ctx, txn, roachpb.Spans{table.IndexSpan(index.ID)}, true /* limitBatches */, 0, /* limitHint */
+		false, /* traceKV */
+	); err != nil {
+		return err
+	}
+	keyPrefix := sqlbase.MakeIndexKeyPrefix(table.TableDesc(), index.ID)
+	// The rows with the same unique values are adjacent in the index, so it is
+	// enough to compare the unique values of each row to the previous row's.
+	var prevKey []byte
+	for {
+		datums, _, _, err := rf.NextRowDecoded(ctx)
+		if err != nil {
+			return err
+		}
+		if datums == nil {
+			return nil
+		}
+		key, containsNull, err := sqlbase.EncodePartialIndexKey(
+			table.TableDesc(), index, len(index.ColumnIDs), colIdxMap, datums, keyPrefix,
+		)
+		if err != nil {
+			return err
+		}
+		if containsNull {
+			continue
+		}
+		if bytes.Equal(key, prevKey) {
+			return NewUniquenessConstraintViolationError(index, datums)
+		}
+		prevKey = key
+	}
+}
@@ -45,6 +45,10 @@ type Inserter struct {
 	InsertColIDtoRowIndex map[sqlbase.ColumnID]int
 	Fks                   fkInsertHelper
 
+	// uniqueChecks queues the checks of the deferrable unique constraints.
+	uniqueChecks   *UniqueChecks
+	deferredChecks *DeferredChecks
+
 	// For allocation avoidance.
 	marshaled []roachpb.Value
 	key       roachpb.Key
@@ -61,6 +65,7 @@ func MakeInserter(
 	fkTables TableLookupsByID,
 	insertCols []sqlbase.ColumnDescriptor,
 	checkFKs checkFKConstraints,
+	evalCtx *tree.EvalContext,
 	alloc *sqlbase.DatumAlloc,
 ) (Inserter, error) {
 	ri := Inserter{
@@ -69,6 +74,10 @@ func MakeInserter(
 		InsertColIDtoRowIndex: ColIDtoRowIndexFromCols(insertCols),
 		marshaled:             make([]roachpb.Value, len(insertCols)),
 	}
+	if err := ri.Helper.initPartialIndexPredicates(evalCtx); err != nil {
+		return Inserter{}, err
+	}
+	ri.uniqueChecks = makeUniqueChecks(tableDesc, ri.Helper.Indexes)
 
 	for i, col := range tableDesc.PrimaryIndex.ColumnIDs {
 		if _, ok := ri.InsertColIDtoRowIndex[col]; !ok {
@@ -86,6 +95,31 @@ func MakeInserter(
 	return ri, nil
 }
 
+// SetDeferredChecks makes the Inserter queue the checks of the deferred
+// foreign key and unique constraints in d instead of performing them
+// immediately.
+func (ri *Inserter) SetDeferredChecks(d *DeferredChecks) {
+	ri.deferredChecks = d
+	if ri.Fks.checker != nil {
+		ri.Fks.checker.deferred = d
+	}
+	if ri.uniqueChecks != nil {
+		ri.uniqueChecks.deferred = d
+	}
+}
+
+// DeferredChecks returns the DeferredChecks set with SetDeferredChecks,
+// if any.
+func (ri *Inserter) DeferredChecks() *DeferredChecks {
+	return ri.deferredChecks
+}
+
+// UniqueChecks returns the checks of the deferrable unique constraints queued
+// by the Inserter, which must be run once all the rows are written.
+func (ri *Inserter) UniqueChecks() *UniqueChecks {
+	return ri.uniqueChecks
+}
+
 // insertCPutFn is used by insertRow when conflicts (i.e. the key already exists)
 // should generate errors.
 func insertCPutFn(
@@ -177,6 +211,11 @@ func (ri *Inserter) InsertRow(
 	if err != nil {
 		return err
 	}
+	if err := ri.uniqueChecks.addRow(
+		ri.InsertColIDtoRowIndex, values, secondaryIndexEntries, nil, /* oldEntries */
+	); err != nil {
+		return err
+	}
 
 	// Add the new values.
 	ri.valueBuf, err = prepareInsertOrUpdateBatch(ctx, b,
@@ -191,6 +230,11 @@ func (ri *Inserter) InsertRow(
 	putFn = insertInvertedPutFn
 	for i := range secondaryIndexEntries {
 		e := &secondaryIndexEntries[i]
+		// Partial indexes have no entry for the rows that don't satisfy their
+		// predicate.
+		if len(e.Key) == 0 {
+			continue
+		}
 		putFn(ctx, b, &e.Key, &e.Value, traceKV)
 	}
 
@@ -361,6 +405,9 @@ type Updater struct {
 	Fks      fkUpdateHelper
 	cascader *cascader
 
+	// uniqueChecks queues the checks of the deferrable unique constraints.
+	uniqueChecks *UniqueChecks
+
 	// For allocation avoidance.
 	marshaled       []roachpb.Value
 	newValues       []tree.Datum
@@ -389,6 +436,9 @@ const (
 // The returned Updater contains a FetchCols field that defines the
 // expectation of which values are passed as oldValues to UpdateRow. All the columns
 // passed in requestedCols will be included in FetchCols at the beginning.
+//
+// If checkFKs is SkipFKs, the Updater neither checks nor cascades the foreign
+// keys, and fkTables only needs to contain the table itself.
 func MakeUpdater(
 	txn *client.Txn,
 	tableDesc *sqlbase.ImmutableTableDescriptor,
@@ -396,24 +446,49 @@ func MakeUpdater(
 	updateCols []sqlbase.ColumnDescriptor,
 	requestedCols []sqlbase.ColumnDescriptor,
 	updateType rowUpdaterType,
+	checkFKs checkFKConstraints,
 	evalCtx *tree.EvalContext,
 	alloc *sqlbase.DatumAlloc,
 ) (Updater, error) {
 	rowUpdater, err := makeUpdaterWithoutCascader(
-		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, alloc,
+		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, checkFKs, evalCtx, alloc,
 	)
 	if err != nil {
 		return Updater{}, err
 	}
-	rowUpdater.cascader, err = makeUpdateCascader(
-		txn, tableDesc, fkTables, updateCols, evalCtx, alloc,
-	)
-	if err != nil {
-		return Updater{}, err
+	if checkFKs == CheckFKs {
+		rowUpdater.cascader, err = makeUpdateCascader(
+			txn, tableDesc, fkTables, updateCols, evalCtx, alloc,
+		)
+		if err != nil {
+			return Updater{}, err
+		}
 	}
 	return rowUpdater, nil
 }
 
+// SetDeferredChecks makes the Updater, and the Updaters and Deleters used by
+// its cascades, queue the checks of the deferred foreign key and unique
+// constraints in d instead of performing them immediately.
+func (ru *Updater) SetDeferredChecks(d *DeferredChecks) {
+	if ru.Fks.checker != nil {
+		ru.Fks.checker.deferred = d
+	}
+	if ru.uniqueChecks != nil {
+		ru.uniqueChecks.deferred = d
+	}
+	if ru.cascader != nil {
+		ru.cascader.deferredChecks = d
+	}
+}
+
+// UniqueChecks returns the checks of the deferrable unique constraints queued
+// by the Updater, which must be run once all the rows are written. The checks
+// queued by the cascades are run by the cascades themselves.
+func (ru *Updater) UniqueChecks() *UniqueChecks {
+	return ru.uniqueChecks
+}
+
 type returnTrue struct{}
 
 func (returnTrue) Error() string { panic("unimplemented") }
@@ -429,6 +504,8 @@ func makeUpdaterWithoutCascader(
 	updateCols []sqlbase.ColumnDescriptor,
 	requestedCols []sqlbase.ColumnDescriptor,
 	updateType rowUpdaterType,
+	checkFKs checkFKConstraints,
+	evalCtx *tree.EvalContext,
 	alloc *sqlbase.DatumAlloc,
 ) (Updater, error) {
 	updateColIDtoRowIndex := ColIDtoRowIndexFromCols(updateCols)
@@ -446,6 +523,35 @@ func makeUpdaterWithoutCascader(
 		}
 	}
 
+	writableIndexes := tableDesc.WritableIndexes()
+
+	// Whether a row has an entry in a partial index depends on the columns
+	// referenced by the predicate of the index, so those are needed to update
+	// the index too.
+	predicateCols := make(map[sqlbase.IndexID][]sqlbase.ColumnID)
+	for _, indexes := range [][]sqlbase.IndexDescriptor{writableIndexes, tableDesc.DeleteOnlyIndexes()} {
+		for i := range indexes {
+			colIDs, err := indexes[i].PredicateColumnIDs(tableDesc.TableDesc())
+			if err != nil {
+				return Updater{}, err
+			}
+			if len(colIDs) > 0 {
+				predicateCols[indexes[i].ID] = colIDs
+			}
+		}
+	}
+	runOverIndexColumns := func(index sqlbase.IndexDescriptor, f func(sqlbase.ColumnID) error) error {
+		if err := index.RunOverAllColumns(f); err != nil {
+			return err
+		}
+		for _, colID := range predicateCols[index.ID] {
+			if err := f(colID); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Secondary indexes needing updating.
 	needsUpdate := func(index sqlbase.IndexDescriptor) bool {
 		if updateType == UpdaterOnlyColumns {
@@ -456,7 +562,7 @@ func makeUpdaterWithoutCascader(
 		if primaryKeyColChange {
 			return true
 		}
-		return index.RunOverAllColumns(func(id sqlbase.ColumnID) error {
+		return runOverIndexColumns(index, func(id sqlbase.ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
 			}
@@ -464,7 +570,6 @@ func makeUpdaterWithoutCascader(
 		}) != nil
 	}
 
-	writableIndexes := tableDesc.WritableIndexes()
 	includeIndexes := make([]sqlbase.IndexDescriptor, 0, len(writableIndexes))
 	for _, index := range writableIndexes {
 		if needsUpdate(index) {
@@ -501,6 +606,10 @@ func makeUpdaterWithoutCascader(
 		marshaled:             make([]roachpb.Value, len(updateCols)),
 		newValues:             make([]tree.Datum, len(tableCols)),
 	}
+	if err := ru.Helper.initPartialIndexPredicates(evalCtx); err != nil {
+		return Updater{}, err
+	}
+	ru.uniqueChecks = makeUniqueChecks(tableDesc, ru.Helper.Indexes)
 
 	if primaryKeyColChange {
 		// These fields are only used when the primary key is changing.
@@ -515,9 +624,12 @@ func makeUpdaterWithoutCascader(
 		ru.FetchCols = ru.rd.FetchCols
 		ru.FetchColIDtoRowIndex = ColIDtoRowIndexFromCols(ru.FetchCols)
 		if ru.ri, err = MakeInserter(txn, tableDesc, fkTables,
-			tableCols, SkipFKs, alloc); err != nil {
+			tableCols, SkipFKs, evalCtx, alloc); err != nil {
 			return Updater{}, err
 		}
+		// All the indexes are rewritten, so the Inserter can queue the
+		// uniqueness checks in place of the Updater.
+		ru.ri.uniqueChecks = ru.uniqueChecks
 	} else {
 		ru.FetchCols = requestedCols[:len(requestedCols):len(requestedCols)]
 		ru.FetchColIDtoRowIndex = ColIDtoRowIndexFromCols(ru.FetchCols)
@@ -567,21 +679,23 @@ func makeUpdaterWithoutCascader(
 		// Fetch all columns from indices that are being update so that they can
 		// be used to create the new kv pairs for those indices.
 		for _, index := range includeIndexes {
-			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
+			if err := runOverIndexColumns(index, maybeAddCol); err != nil {
 				return Updater{}, err
 			}
 		}
 		for _, index := range deleteOnlyIndexes {
-			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
+			if err := runOverIndexColumns(index, maybeAddCol); err != nil {
 				return Updater{}, err
 			}
 		}
 	}
 
-	var err error
-	if ru.Fks, err = makeFKUpdateHelper(txn, tableDesc, fkTables,
-		ru.FetchColIDtoRowIndex, alloc); err != nil {
-		return Updater{}, err
+	if checkFKs == CheckFKs {
+		var err error
+		if ru.Fks, err = makeFKUpdateHelper(txn, tableDesc, fkTables,
+			ru.FetchColIDtoRowIndex, alloc); err != nil {
+			return Updater{}, err
+		}
 	}
 	return ru, nil
 }
@@ -720,6 +834,12 @@ func (ru *Updater) UpdateRow(
 		return ru.newValues, nil
 	}
 
+	if err := ru.uniqueChecks.addRow(
+		ru.FetchColIDtoRowIndex, ru.newValues, newSecondaryIndexEntries, oldSecondaryIndexEntries,
+	); err != nil {
+		return nil, err
+	}
+
 	// Update secondary indexes.
 	// We're iterating through all of the indexes, which should have corresponding entries in both oldSecondaryIndexEntries
 	// and newSecondaryIndexEntries. Inverted indexes could potentially have more entries at the end of both and we will
@@ -738,13 +858,22 @@ func (ru *Updater) UpdateRow(
 			continue
 		}
 
+		// Partial indexes have no entry for the rows that don't satisfy their
+		// predicate, which is represented by an empty key.
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key) {
 			ru.Fks.addCheckForIndex(ru.Helper.Indexes[i].ID, ru.Helper.Indexes[i].Type)
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+			if len(oldSecondaryIndexEntry.Key) > 0 {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+				}
+				batch.Del(oldSecondaryIndexEntry.Key)
 			}
-			batch.Del(oldSecondaryIndexEntry.Key)
+			if len(newSecondaryIndexEntry.Key) == 0 {
+				continue
+			}
+		} else if len(newSecondaryIndexEntry.Key) == 0 {
+			continue
 		} else if !newSecondaryIndexEntry.Value.EqualData(oldSecondaryIndexEntry.Value) {
 			expValue = &oldSecondaryIndexEntry.Value
 		} else {
@@ -866,6 +995,18 @@ func MakeDeleter(
 	return rowDeleter, nil
 }
 
+// SetDeferredChecks makes the Deleter, and the Updaters and Deleters used by
+// its cascades, queue the checks of the deferred foreign key and unique
+// constraints in d instead of performing them immediately.
+func (rd *Deleter) SetDeferredChecks(d *DeferredChecks) {
+	if rd.Fks.checker != nil {
+		rd.Fks.checker.deferred = d
+	}
+	if rd.cascader != nil {
+		rd.cascader.deferredChecks = d
+	}
+}
+
 // makeRowDeleterWithoutCascader creates a rowDeleter but does not create an
 // additional cascader.
 func makeRowDeleterWithoutCascader(
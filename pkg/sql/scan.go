@@ -121,6 +121,13 @@ type scanNode struct {
 
 	// Indicates if this scan is the source for a delete node.
 	isDeleteSource bool
+
+	// lockingStrength is the strength of the row-level locks that the scan
+	// acquires on the rows that pass its filter (SELECT FOR UPDATE etc), or
+	// ForNone if it acquires no locks. lockingWaitPolicy determines how the
+	// scan handles rows that are locked by other transactions.
+	lockingStrength   tree.LockingStrength
+	lockingWaitPolicy tree.LockingWaitPolicy
 }
 
 // scanVisibility represents which table columns should be included in a scan.
@@ -219,8 +226,14 @@ func (n *scanNode) startExec(params runParams) error {
 		Cols:             n.cols,
 		ValNeededForCol:  n.valNeededForCol.Copy(),
 	}
-	return n.run.fetcher.Init(n.reverse, false, /* returnRangeInfo */
-		false /* isCheck */, &params.p.alloc, tableArgs)
+	if err := n.run.fetcher.Init(n.reverse, false, /* returnRangeInfo */
+		false /* isCheck */, &params.p.alloc, tableArgs); err != nil {
+		return err
+	}
+	if n.lockingStrength != tree.ForNone {
+		n.run.fetcher.SetLocking(n.lockingWaitPolicy)
+	}
+	return nil
 }
 
 func (n *scanNode) Close(context.Context) {
@@ -249,6 +262,17 @@ func (n *scanNode) Next(params runParams) (bool, error) {
 			return false, err
 		}
 		if passesFilter {
+			if n.lockingStrength != tree.ForNone {
+				// Only the rows that pass the filter are locked. A row that is
+				// locked by another transaction is skipped under SKIP LOCKED.
+				locked, err := n.run.fetcher.LockRow(params.ctx, params.p.txn)
+				if err != nil {
+					return false, err
+				}
+				if !locked {
+					continue
+				}
+			}
 			n.run.rowIndex++
 			return true, nil
 		}
@@ -380,6 +404,9 @@ func (n *scanNode) lookupSpecifiedIndex(indexFlags *tree.IndexFlags) error {
 			return errors.Errorf("index [%d] not found", indexFlags.IndexID)
 		}
 	}
+	if n.specifiedIndex.IsPartial() {
+		return errors.Errorf("index %q is a partial index and cannot be forced", n.specifiedIndex.Name)
+	}
 	if indexFlags.Direction == tree.Descending {
 		n.specifiedIndexReverse = true
 	}
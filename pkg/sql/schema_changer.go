@@ -908,6 +908,12 @@ func (sc *SchemaChanger) RunStateMachineBeforeBackfill(ctx context.Context) erro
 					// DELETE_AND_WRITE_ONLY state to fill in the missing elements of the
 					// index (INSERT and UPDATE that happened in the interim).
 					desc.Mutations[i].State = sqlbase.DescriptorMutation_DELETE_AND_WRITE_ONLY
+					if refresh := desc.Mutations[i].GetMaterializedViewRefresh(); refresh != nil {
+						// The refresh reads the query of the view as of a
+						// timestamp after the REFRESH statement committed,
+						// which stays the same if the refresh is resumed.
+						refresh.AsOf = sc.clock.Now()
+					}
 					modified = true
 
 				case sqlbase.DescriptorMutation_DELETE_AND_WRITE_ONLY:
@@ -991,6 +997,28 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 						})
 				}
 			}
+			if refresh := mutation.GetMaterializedViewRefresh(); refresh != nil {
+				// Once a refresh is complete the previous indexes of the view are
+				// removed, and the new indexes if it was rolled back.
+				var oldIndexes []sqlbase.IndexDescriptor
+				if mutation.Direction == sqlbase.DescriptorMutation_ADD {
+					oldIndexes = append(oldIndexes, desc.PrimaryIndex)
+					oldIndexes = append(oldIndexes, desc.Indexes...)
+				} else {
+					oldIndexes = append(oldIndexes, refresh.NewPrimaryIndex)
+					oldIndexes = append(oldIndexes, refresh.NewIndexes...)
+				}
+				jobSucceeded = false
+				for _, idx := range oldIndexes {
+					desc.GCMutations = append(
+						desc.GCMutations,
+						sqlbase.TableDescriptor_GCDescriptorMutation{
+							IndexID:  idx.ID,
+							DropTime: now,
+							JobID:    *sc.job.ID(),
+						})
+				}
+			}
 			if err := desc.MakeMutationComplete(mutation); err != nil {
 				return err
 			}
@@ -1381,6 +1409,7 @@ type TestingSchemaChangerCollection struct {
 // run.
 func (tscc TestingSchemaChangerCollection) ClearSchemaChangers() {
 	tscc.scc.schemaChangers = tscc.scc.schemaChangers[:0]
+	tscc.scc.typeSchemaChangers = tscc.scc.typeSchemaChangers[:0]
 }
 
 // SyncSchemaChangersFilter is the type of a hook to be installed through the
@@ -1457,9 +1486,12 @@ type SchemaChangeManager struct {
 	schemaChangers map[sqlbase.ID]SchemaChanger
 	// Create a schema changer for every table that is dropped or has
 	// dropped indexes that needs to be GC-ed.
-	forGC          map[sqlbase.ID]SchemaChanger
-	distSQLPlanner *DistSQLPlanner
-	ieFactory      sqlutil.SessionBoundInternalExecutorFactory
+	forGC map[sqlbase.ID]SchemaChanger
+	// Create a type schema changer for every type which has members being
+	// added.
+	typeSchemaChangers map[sqlbase.ID]typeSchemaChanger
+	distSQLPlanner     *DistSQLPlanner
+	ieFactory          sqlutil.SessionBoundInternalExecutorFactory
 }
 
 // NewSchemaChangeManager returns a new SchemaChangeManager.
@@ -1473,13 +1505,14 @@ func NewSchemaChangeManager(
 	ieFactory sqlutil.SessionBoundInternalExecutorFactory,
 ) *SchemaChangeManager {
 	return &SchemaChangeManager{
-		ambientCtx:     ambientCtx,
-		execCfg:        execCfg,
-		testingKnobs:   testingKnobs,
-		schemaChangers: make(map[sqlbase.ID]SchemaChanger),
-		forGC:          make(map[sqlbase.ID]SchemaChanger),
-		distSQLPlanner: dsp,
-		ieFactory:      ieFactory,
+		ambientCtx:         ambientCtx,
+		execCfg:            execCfg,
+		testingKnobs:       testingKnobs,
+		schemaChangers:     make(map[sqlbase.ID]SchemaChanger),
+		forGC:              make(map[sqlbase.ID]SchemaChanger),
+		typeSchemaChangers: make(map[sqlbase.ID]typeSchemaChanger),
+		distSQLPlanner:     dsp,
+		ieFactory:          ieFactory,
 	}
 }
 
@@ -1500,6 +1533,22 @@ func (s *SchemaChangeManager) newTimer(changers map[sqlbase.ID]SchemaChanger) *t
 	return time.NewTimer(waitDuration)
 }
 
+// newTypeTimer is like newTimer, for the type schema changers.
+func (s *SchemaChangeManager) newTypeTimer() *time.Timer {
+	if len(s.typeSchemaChangers) == 0 {
+		return &time.Timer{}
+	}
+	waitDuration := time.Duration(math.MaxInt64)
+	now := timeutil.Now()
+	for _, sc := range s.typeSchemaChangers {
+		d := sc.execAfter.Sub(now)
+		if d < waitDuration {
+			waitDuration = d
+		}
+	}
+	return time.NewTimer(waitDuration)
+}
+
 // Start starts a goroutine that runs outstanding schema changes
 // for tables received in the latest system configuration via gossip.
 func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
@@ -1512,6 +1561,7 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 		gossipUpdateC := s.execCfg.Gossip.RegisterSystemConfigChannel()
 		timer := &time.Timer{}
 		gcTimer := &time.Timer{}
+		typeTimer := &time.Timer{}
 		// A jitter is added to reduce contention between nodes
 		// attempting to run the schema change.
 		delay := time.Duration(float64(asyncSchemaChangeDelay) * (0.9 + 0.2*rand.Float64()))
@@ -1744,7 +1794,26 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 							delete(s.schemaChangers, table.ID)
 						}
 
-					case *sqlbase.Descriptor_Database:
+					case *sqlbase.Descriptor_Type:
+						// Keep track of the types with members being added.
+						typeDesc := union.Type
+						if typeDesc.HasReadOnlyEnumMembers() {
+							if log.V(2) {
+								log.Infof(ctx, "%s: queue up pending type schema change; type: %d",
+									kv.Key, typeDesc.ID)
+							}
+							s.typeSchemaChangers[typeDesc.ID] = typeSchemaChanger{
+								typeID:    typeDesc.ID,
+								db:        s.execCfg.DB,
+								leaseMgr:  s.execCfg.LeaseManager,
+								settings:  s.execCfg.Settings,
+								execAfter: execAfter,
+							}
+						} else {
+							delete(s.typeSchemaChangers, typeDesc.ID)
+						}
+
+					case *sqlbase.Descriptor_Database, *sqlbase.Descriptor_Schema:
 						// Ignore.
 					}
 				})
@@ -1752,6 +1821,7 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 				if resetTimer {
 					timer = s.newTimer(s.schemaChangers)
 					gcTimer = s.newTimer(s.forGC)
+					typeTimer = s.newTypeTimer()
 				}
 
 			case <-timer.C:
@@ -1776,6 +1846,33 @@ func (s *SchemaChangeManager) Start(stopper *stop.Stopper) {
 
 				gcTimer = s.newTimer(s.forGC)
 
+			case <-typeTimer.C:
+				if s.testingKnobs.AsyncExecNotification != nil &&
+					s.testingKnobs.AsyncExecNotification() != nil {
+					typeTimer = s.newTypeTimer()
+					continue
+				}
+
+				// Only attempt to run one type schema changer.
+				for typeID, sc := range s.typeSchemaChangers {
+					if timeutil.Since(sc.execAfter) > 0 {
+						execCtx, cleanup := tracing.EnsureContext(ctx, s.ambientCtx.Tracer, "type schema change [async]")
+						err := sc.exec(execCtx)
+						cleanup()
+						if err != nil {
+							log.Warningf(ctx, "Error executing type schema change: %s", err)
+							// Don't try to run this schema changer again for a while.
+							sc.execAfter = timeutil.Now().Add(delay)
+							s.typeSchemaChangers[typeID] = sc
+						} else {
+							delete(s.typeSchemaChangers, typeID)
+						}
+						break
+					}
+				}
+
+				typeTimer = s.newTypeTimer()
+
 			case <-stopper.ShouldStop():
 				return
 			}
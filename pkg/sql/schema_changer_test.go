@@ -1340,6 +1340,146 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
 	dropIndexSchemaChange(t, sqlDB, kvDB, maxValue, 2)
 }
 
+// Test that the refresh of a materialized view is written in chunks, that it
+// is resumed from its checkpoint when it is retried, and that it doesn't write
+// any row twice.
+func TestRefreshMaterializedViewRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := tests.CreateTestServerParams()
+
+	const chunkSize = 100
+	currChunk := 0
+	seenSpan := roachpb.Span{}
+	checkSpan := func(sp roachpb.Span) error {
+		currChunk++
+		// Fail somewhere in the middle.
+		if currChunk == 3 {
+			return context.DeadlineExceeded
+		}
+		if seenSpan.Key != nil {
+			// Check that the rows are never rewritten.
+			if seenSpan.Key.Compare(sp.Key) >= 0 {
+				t.Errorf("reprocessing span %s, already seen span %s", sp, seenSpan)
+			}
+			if !seenSpan.EndKey.Equal(sp.EndKey) {
+				t.Errorf("different EndKey: span %s, already seen span %s", sp, seenSpan)
+			}
+		}
+		seenSpan = sp
+		return nil
+	}
+
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			// Disable asynchronous schema change execution to allow
+			// synchronous path to run schema changes.
+			AsyncExecNotification:   asyncSchemaChangerDisabled,
+			BackfillChunkSize:       chunkSize,
+			WriteCheckpointInterval: time.Nanosecond,
+		},
+		DistSQL: &distsqlrun.TestingKnobs{RunBeforeBackfillChunk: checkSpan},
+		// Disable backfill migrations, we still need the jobs table migration.
+		SQLMigrationManager: &sqlmigrations.MigrationManagerTestingKnobs{
+			DisableBackfillMigrations: true,
+		},
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	// The array and JSON columns can't be ordered on their own.
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+CREATE MATERIALIZED VIEW t.mv AS
+  SELECT k, v, ARRAY[k] AS a, json_build_object('k', k) AS j FROM t.test;
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	const maxValue = 2000
+	if err := bulkInsertIntoTable(sqlDB, maxValue); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlDB.Exec(`REFRESH MATERIALIZED VIEW t.mv`); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (maxValue+1)/chunkSize + 1; currChunk < expected {
+		t.Fatalf("expected at least %d chunks, but found %d", expected, currChunk)
+	}
+
+	var count, distinct, sum, consistent int
+	if err := sqlDB.QueryRow(`
+SELECT count(*), count(DISTINCT k), sum(k), count(*) FILTER (WHERE a[1] = k AND j->>'k' = k::STRING)
+  FROM t.mv`,
+	).Scan(&count, &distinct, &sum, &consistent); err != nil {
+		t.Fatal(err)
+	}
+	if count != maxValue+1 || distinct != maxValue+1 || consistent != maxValue+1 {
+		t.Fatalf("expected %d distinct rows, but found %d rows, %d distinct, %d consistent",
+			maxValue+1, count, distinct, consistent)
+	}
+	if expected := maxValue * (maxValue + 1) / 2; sum != expected {
+		t.Fatalf("expected sum %d, but found %d", expected, sum)
+	}
+}
+
+// Test that a value added to a type is read-only until the type schema
+// changer makes it public, and that the value is made public by the
+// asynchronous schema changer when the synchronous one doesn't run.
+func TestAlterTypeAddValueAsync(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := tests.CreateTestServerParams()
+	params.UseDatabase = "t"
+	var blockAsync int32 = 1
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			SyncFilter: func(tscc sql.TestingSchemaChangerCollection) {
+				tscc.ClearSchemaChangers()
+			},
+			AsyncExecNotification: func() error {
+				if atomic.LoadInt32(&blockAsync) == 1 {
+					return errors.New("async schema changer blocked")
+				}
+				return nil
+			},
+			AsyncExecQuickly: true,
+		},
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TYPE mood AS ENUM ('happy', 'sad');
+CREATE TABLE person (name STRING PRIMARY KEY, current_mood mood);
+INSERT INTO person VALUES ('alice', 'happy');
+ALTER TYPE mood ADD VALUE 'ok';
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sqlDB.Exec(`INSERT INTO person VALUES ('bob', 'ok')`); !testutils.IsError(
+		err, `enum value "ok" is not yet public`,
+	) {
+		t.Fatalf("expected the value to be read-only, but found %v", err)
+	}
+
+	atomic.StoreInt32(&blockAsync, 0)
+	testutils.SucceedsSoon(t, func() error {
+		_, err := sqlDB.Exec(`INSERT INTO person VALUES ('bob', 'ok')`)
+		return err
+	})
+
+	var count int
+	if err := sqlDB.QueryRow(
+		`SELECT count(*) FROM person WHERE current_mood = 'ok'`,
+	).Scan(&count); err != nil {
+		t.Fatal(err)
+	} else if count != 1 {
+		t.Fatalf("expected 1 row, but found %d", count)
+	}
+}
+
 // Test schema changes are retried and complete properly when the table
 // version changes. This also checks that a mutation checkpoint reduces
 // the number of chunks operated on during a retry.
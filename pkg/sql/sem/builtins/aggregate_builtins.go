@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"unsafe"
 
 	"github.com/cockroachdb/apd"
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/cockroachdb/cockroach/pkg/util/tdigest"
 )
 
 func initAggregateBuiltins() {
@@ -73,6 +75,12 @@ func aggPropsNullableArgs() tree.FunctionProperties {
 	return f
 }
 
+func orderedSetAggProps() tree.FunctionProperties {
+	f := aggProps()
+	f.OrderedSetAgg = true
+	return f
+}
+
 // aggregates are a special class of builtin functions that are wrapped
 // at execution in a bucketing layer to combine (aggregate) the result
 // of the function being run over many rows.
@@ -290,6 +298,59 @@ var aggregates = map[string]builtinDefinition{
 	"json_object_agg":  makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 33285, Class: tree.AggregateClass, Impure: true}),
 	"jsonb_object_agg": makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 33285, Class: tree.AggregateClass, Impure: true}),
 
+	// Ordered-set aggregates are called with a WITHIN GROUP clause, the
+	// expression of which is passed as the first argument, e.g.
+	// percentile_disc(0.5) WITHIN GROUP (ORDER BY k) is percentile_disc(k, 0.5).
+	// The aggregates buffer their input and sort it when computing the result.
+
+	"percentile_disc": collectOverloads(orderedSetAggProps(), types.AnyNonArray,
+		func(t types.T) tree.Overload {
+			return makeAggOverload([]types.T{t, types.Float}, t, newPercentileDiscAggregate,
+				"Returns the first ordered value whose position is at or above the given fraction "+
+					"of the values.")
+		}),
+
+	"percentile_cont": makeBuiltin(orderedSetAggProps(),
+		makeAggOverload([]types.T{types.Float, types.Float}, types.Float,
+			newPercentileContAggregate,
+			"Returns the value at the given fraction of the ordered values, interpolating "+
+				"between adjacent values if needed."),
+		makeAggOverload([]types.T{types.Interval, types.Float}, types.Interval,
+			newPercentileContAggregate,
+			"Returns the value at the given fraction of the ordered values, interpolating "+
+				"between adjacent values if needed."),
+	),
+
+	"mode": collectOverloads(orderedSetAggProps(), types.AnyNonArray,
+		func(t types.T) tree.Overload {
+			return makeAggOverload([]types.T{t}, t, newModeAggregate,
+				"Returns the most frequent value, choosing the first one in the ordering if "+
+					"there are multiple equally frequent values.")
+		}),
+
+	"approx_percentile": makeBuiltin(orderedSetAggProps(),
+		makeAggOverload([]types.T{types.Float, types.Float}, types.Float,
+			newApproxPercentileAggregate,
+			"Returns an approximation of the value at the given fraction of the ordered "+
+				"values, computed using a t-digest."),
+	),
+
+	// approx_percentile_digest and final_approx_percentile are the local and
+	// final stages of the distributed approx_percentile: the local stage
+	// summarizes its values in a serialized t-digest and the final stage
+	// merges the digests.
+	"approx_percentile_digest": makePrivate(makeBuiltin(aggProps(),
+		makeAggOverload([]types.T{types.Float}, types.Bytes, newApproxPercentileDigestAggregate,
+			"Summarizes the selected values in a serialized t-digest."),
+	)),
+
+	"final_approx_percentile": makePrivate(makeBuiltin(aggProps(),
+		makeAggOverload([]types.T{types.Bytes, types.Float}, types.Float,
+			newFinalApproxPercentileAggregate,
+			"Returns an approximation of the value at the given fraction of the values "+
+				"summarized by the selected locally-computed t-digests."),
+	)),
+
 	AnyNotNull: makePrivate(makeBuiltin(aggProps(),
 		makeAggOverloadWithReturnType(
 			[]types.T{types.Any},
@@ -405,6 +466,12 @@ var _ tree.AggregateFunc = &boolOrAggregate{}
 var _ tree.AggregateFunc = &bytesXorAggregate{}
 var _ tree.AggregateFunc = &intXorAggregate{}
 var _ tree.AggregateFunc = &jsonAggregate{}
+var _ tree.AggregateFunc = &percentileDiscAggregate{}
+var _ tree.AggregateFunc = &percentileContAggregate{}
+var _ tree.AggregateFunc = &modeAggregate{}
+var _ tree.AggregateFunc = &approxPercentileAggregate{}
+var _ tree.AggregateFunc = &approxPercentileDigestAggregate{}
+var _ tree.AggregateFunc = &finalApproxPercentileAggregate{}
 
 const sizeOfArrayAggregate = int64(unsafe.Sizeof(arrayAggregate{}))
 const sizeOfAvgAggregate = int64(unsafe.Sizeof(avgAggregate{}))
@@ -433,6 +500,14 @@ const sizeOfBoolOrAggregate = int64(unsafe.Sizeof(boolOrAggregate{}))
 const sizeOfBytesXorAggregate = int64(unsafe.Sizeof(bytesXorAggregate{}))
 const sizeOfIntXorAggregate = int64(unsafe.Sizeof(intXorAggregate{}))
 const sizeOfJSONAggregate = int64(unsafe.Sizeof(jsonAggregate{}))
+const sizeOfPercentileDiscAggregate = int64(unsafe.Sizeof(percentileDiscAggregate{}))
+const sizeOfPercentileContAggregate = int64(unsafe.Sizeof(percentileContAggregate{}))
+const sizeOfModeAggregate = int64(unsafe.Sizeof(modeAggregate{}))
+const sizeOfApproxPercentileAggregate = int64(unsafe.Sizeof(approxPercentileAggregate{}))
+const sizeOfApproxPercentileDigestAggregate = int64(
+	unsafe.Sizeof(approxPercentileDigestAggregate{}),
+)
+const sizeOfFinalApproxPercentileAggregate = int64(unsafe.Sizeof(finalApproxPercentileAggregate{}))
 
 // See NewAnyNotNullAggregate.
 type anyNotNullAggregate struct {
@@ -1940,3 +2015,341 @@ func (a *jsonAggregate) Close(ctx context.Context) {
 func (a *jsonAggregate) Size() int64 {
 	return sizeOfJSONAggregate
 }
+
+// sortedValues buffers the non-NULL values passed to an ordered-set aggregate
+// and sorts them when the result is needed.
+type sortedValues struct {
+	evalCtx *tree.EvalContext
+	values  tree.Datums
+	sorted  bool
+	acc     mon.BoundAccount
+}
+
+func makeSortedValues(evalCtx *tree.EvalContext) sortedValues {
+	return sortedValues{
+		evalCtx: evalCtx,
+		acc:     evalCtx.Mon.MakeBoundAccount(),
+	}
+}
+
+func (s *sortedValues) add(ctx context.Context, datum tree.Datum) error {
+	if datum == tree.DNull {
+		return nil
+	}
+	if err := s.acc.Grow(ctx, int64(datum.Size())); err != nil {
+		return err
+	}
+	s.values = append(s.values, datum)
+	s.sorted = false
+	return nil
+}
+
+// sort sorts the buffered values, unless they are already sorted.
+func (s *sortedValues) sort() {
+	if s.sorted {
+		return
+	}
+	sort.Slice(s.values, func(i, j int) bool {
+		return s.values[i].Compare(s.evalCtx, s.values[j]) < 0
+	})
+	s.sorted = true
+}
+
+func (s *sortedValues) close(ctx context.Context) {
+	s.acc.Close(ctx)
+}
+
+// percentileFraction returns the fraction passed as the constant argument of
+// a percentile aggregate. ok is false if the fraction is NULL.
+func percentileFraction(arguments tree.Datums) (fraction float64, ok bool, err error) {
+	if len(arguments) != 1 {
+		return 0, false, pgerror.NewAssertionErrorf(
+			"expected a single constant fraction argument, got %d arguments", len(arguments))
+	}
+	if arguments[0] == tree.DNull {
+		return 0, false, nil
+	}
+	fraction = float64(*arguments[0].(*tree.DFloat))
+	if !(fraction >= 0 && fraction <= 1) {
+		return 0, false, pgerror.NewErrorf(pgerror.CodeNumericValueOutOfRangeError,
+			"percentile value %g is not between 0 and 1", fraction)
+	}
+	return fraction, true, nil
+}
+
+type percentileDiscAggregate struct {
+	sortedValues
+	arguments tree.Datums
+}
+
+func newPercentileDiscAggregate(
+	_ []types.T, evalCtx *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	return &percentileDiscAggregate{
+		sortedValues: makeSortedValues(evalCtx),
+		arguments:    arguments,
+	}
+}
+
+// Add buffers the passed datum.
+func (a *percentileDiscAggregate) Add(
+	ctx context.Context, datum tree.Datum, _ ...tree.Datum,
+) error {
+	return a.add(ctx, datum)
+}
+
+// Result returns the first value in the ordering whose position is at or
+// above the fraction of the number of values.
+func (a *percentileDiscAggregate) Result() (tree.Datum, error) {
+	fraction, ok, err := percentileFraction(a.arguments)
+	if err != nil || !ok || len(a.values) == 0 {
+		return tree.DNull, err
+	}
+	a.sort()
+	idx := int(math.Ceil(fraction*float64(len(a.values)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return a.values[idx], nil
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *percentileDiscAggregate) Close(ctx context.Context) {
+	a.close(ctx)
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *percentileDiscAggregate) Size() int64 {
+	return sizeOfPercentileDiscAggregate
+}
+
+type percentileContAggregate struct {
+	sortedValues
+	arguments tree.Datums
+}
+
+func newPercentileContAggregate(
+	_ []types.T, evalCtx *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	return &percentileContAggregate{
+		sortedValues: makeSortedValues(evalCtx),
+		arguments:    arguments,
+	}
+}
+
+// Add buffers the passed datum.
+func (a *percentileContAggregate) Add(
+	ctx context.Context, datum tree.Datum, _ ...tree.Datum,
+) error {
+	return a.add(ctx, datum)
+}
+
+// Result returns the value at the fraction of the ordered values, linearly
+// interpolated between the two values surrounding that position.
+func (a *percentileContAggregate) Result() (tree.Datum, error) {
+	fraction, ok, err := percentileFraction(a.arguments)
+	if err != nil || !ok || len(a.values) == 0 {
+		return tree.DNull, err
+	}
+	a.sort()
+	pos := fraction * float64(len(a.values)-1)
+	lowerIdx := int(math.Floor(pos))
+	lower := a.values[lowerIdx]
+	frac := pos - float64(lowerIdx)
+	if frac == 0 {
+		return lower, nil
+	}
+	upper := a.values[lowerIdx+1]
+	switch t := lower.(type) {
+	case *tree.DFloat:
+		return tree.NewDFloat(*t + tree.DFloat(frac)*(*upper.(*tree.DFloat)-*t)), nil
+	case *tree.DInterval:
+		diff := upper.(*tree.DInterval).Duration.Sub(t.Duration)
+		return &tree.DInterval{Duration: t.Duration.Add(diff.MulFloat(frac))}, nil
+	default:
+		return nil, pgerror.NewAssertionErrorf("unexpected type %s in percentile_cont",
+			lower.ResolvedType())
+	}
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *percentileContAggregate) Close(ctx context.Context) {
+	a.close(ctx)
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *percentileContAggregate) Size() int64 {
+	return sizeOfPercentileContAggregate
+}
+
+type modeAggregate struct {
+	sortedValues
+}
+
+func newModeAggregate(_ []types.T, evalCtx *tree.EvalContext, _ tree.Datums) tree.AggregateFunc {
+	return &modeAggregate{sortedValues: makeSortedValues(evalCtx)}
+}
+
+// Add buffers the passed datum.
+func (a *modeAggregate) Add(ctx context.Context, datum tree.Datum, _ ...tree.Datum) error {
+	return a.add(ctx, datum)
+}
+
+// Result returns the most frequent value. Of several equally frequent values,
+// the first one in the ordering is returned.
+func (a *modeAggregate) Result() (tree.Datum, error) {
+	if len(a.values) == 0 {
+		return tree.DNull, nil
+	}
+	a.sort()
+	var mode tree.Datum
+	modeCount := 0
+	for i := 0; i < len(a.values); {
+		j := i + 1
+		for j < len(a.values) && a.values[j].Compare(a.evalCtx, a.values[i]) == 0 {
+			j++
+		}
+		if j-i > modeCount {
+			mode, modeCount = a.values[i], j-i
+		}
+		i = j
+	}
+	return mode, nil
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *modeAggregate) Close(ctx context.Context) {
+	a.close(ctx)
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *modeAggregate) Size() int64 {
+	return sizeOfModeAggregate
+}
+
+// approxPercentileAggregate summarizes its input in a t-digest, the size of
+// which is bounded regardless of the number of values.
+type approxPercentileAggregate struct {
+	digest    *tdigest.TDigest
+	arguments tree.Datums
+}
+
+func newApproxPercentileAggregate(
+	_ []types.T, _ *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	return &approxPercentileAggregate{
+		digest:    tdigest.New(),
+		arguments: arguments,
+	}
+}
+
+// Add adds the passed datum to the digest.
+func (a *approxPercentileAggregate) Add(
+	_ context.Context, datum tree.Datum, _ ...tree.Datum,
+) error {
+	if datum == tree.DNull {
+		return nil
+	}
+	a.digest.Add(float64(*datum.(*tree.DFloat)))
+	return nil
+}
+
+// Result returns the approximate value at the fraction of the values.
+func (a *approxPercentileAggregate) Result() (tree.Datum, error) {
+	return digestPercentile(a.digest, a.arguments)
+}
+
+func digestPercentile(digest *tdigest.TDigest, arguments tree.Datums) (tree.Datum, error) {
+	fraction, ok, err := percentileFraction(arguments)
+	if err != nil || !ok || digest.Count() == 0 {
+		return tree.DNull, err
+	}
+	return tree.NewDFloat(tree.DFloat(digest.Quantile(fraction))), nil
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *approxPercentileAggregate) Close(context.Context) {}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *approxPercentileAggregate) Size() int64 {
+	return sizeOfApproxPercentileAggregate
+}
+
+type approxPercentileDigestAggregate struct {
+	digest *tdigest.TDigest
+}
+
+func newApproxPercentileDigestAggregate(
+	_ []types.T, _ *tree.EvalContext, _ tree.Datums,
+) tree.AggregateFunc {
+	return &approxPercentileDigestAggregate{digest: tdigest.New()}
+}
+
+// Add adds the passed datum to the digest.
+func (a *approxPercentileDigestAggregate) Add(
+	_ context.Context, datum tree.Datum, _ ...tree.Datum,
+) error {
+	if datum == tree.DNull {
+		return nil
+	}
+	a.digest.Add(float64(*datum.(*tree.DFloat)))
+	return nil
+}
+
+// Result returns the serialized digest, or NULL if no values were added.
+func (a *approxPercentileDigestAggregate) Result() (tree.Datum, error) {
+	if a.digest.Count() == 0 {
+		return tree.DNull, nil
+	}
+	return tree.NewDBytes(tree.DBytes(a.digest.Encode(nil))), nil
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *approxPercentileDigestAggregate) Close(context.Context) {}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *approxPercentileDigestAggregate) Size() int64 {
+	return sizeOfApproxPercentileDigestAggregate
+}
+
+type finalApproxPercentileAggregate struct {
+	digest    *tdigest.TDigest
+	arguments tree.Datums
+}
+
+func newFinalApproxPercentileAggregate(
+	_ []types.T, _ *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	return &finalApproxPercentileAggregate{
+		digest:    tdigest.New(),
+		arguments: arguments,
+	}
+}
+
+// Add merges the passed serialized digest into the digest.
+func (a *finalApproxPercentileAggregate) Add(
+	_ context.Context, datum tree.Datum, _ ...tree.Datum,
+) error {
+	if datum == tree.DNull {
+		return nil
+	}
+	digest, err := tdigest.Decode([]byte(*datum.(*tree.DBytes)))
+	if err != nil {
+		return pgerror.NewAssertionErrorf("could not decode t-digest: %v", err)
+	}
+	a.digest.Merge(digest)
+	return nil
+}
+
+// Result returns the approximate value at the fraction of the values.
+func (a *finalApproxPercentileAggregate) Result() (tree.Datum, error) {
+	return digestPercentile(a.digest, a.arguments)
+}
+
+// Close is part of the tree.AggregateFunc interface.
+func (a *finalApproxPercentileAggregate) Close(context.Context) {}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *finalApproxPercentileAggregate) Size() int64 {
+	return sizeOfFinalApproxPercentileAggregate
+}
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -1604,6 +1605,25 @@ CockroachDB supports the following flags:
 		},
 	),
 
+	// GROUPING is evaluated by the optimizer from the grouping set that produced
+	// each row, so it is never evaluated as a regular function.
+	"grouping": makeBuiltin(
+		tree.FunctionProperties{
+			NullableArgs: true,
+		},
+		tree.Overload{
+			Types:      tree.VariadicType{VarType: types.Any},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(_ *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.NewError(pgerror.CodeGroupingError,
+					"GROUPING() can only be used in a query with GROUP BY")
+			},
+			Info: "Returns a bit mask of the arguments that are not part of the grouping " +
+				"set of the current row. The last argument corresponds to the least " +
+				"significant bit.",
+		},
+	),
+
 	// Timestamp/Date functions.
 
 	"experimental_strftime": makeBuiltin(
@@ -1736,6 +1756,66 @@ CockroachDB supports the following flags:
 		},
 	),
 
+	tree.FollowerReadTimestampFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				offset := closedts.FollowerReadOffset(&ctx.Settings.SV)
+				return tree.MakeDTimestampTZ(ctx.GetStmtTimestamp().Add(-offset), time.Microsecond), nil
+			},
+			Info: `Returns a timestamp at which reads are very likely to be servable
+by follower replicas.
+
+This function is intended to be used with an AS OF SYSTEM TIME clause to perform
+historical reads at a time which is recent but sufficiently old for the reads
+to be served by the closest replica rather than by the lease holder of each
+range. The timestamp trails the statement time by a multiple of the
+kv.closed_timestamp.target_duration cluster setting.`,
+		},
+	),
+
+	tree.WithMaxStalenessFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"max_staleness", types.Interval}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"%s() can only be used in an AS OF SYSTEM TIME clause",
+					tree.WithMaxStalenessFunctionName)
+			},
+			Info: `Requests a bounded staleness read when used in the AS OF SYSTEM TIME
+clause of a SELECT statement.
+
+The statement is performed at the newest timestamp at which all the data it
+reads can be served by replicas local to the gateway node, provided that this
+timestamp is no older than ` + "`max_staleness`" + ` before the statement time.
+Otherwise, the statement fails.`,
+		},
+	),
+
+	tree.WithMinTimestampFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"min_timestamp", types.TimestampTZ}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"%s() can only be used in an AS OF SYSTEM TIME clause",
+					tree.WithMinTimestampFunctionName)
+			},
+			Info: `Requests a bounded staleness read when used in the AS OF SYSTEM TIME
+clause of a SELECT statement.
+
+The statement is performed at the newest timestamp at which all the data it
+reads can be served by replicas local to the gateway node, provided that this
+timestamp is no older than ` + "`min_timestamp`" + `. Otherwise, the statement
+fails.`,
+		},
+	),
+
 	"cluster_logical_timestamp": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
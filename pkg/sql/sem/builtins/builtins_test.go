@@ -16,13 +16,17 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 func TestCategory(t *testing.T) {
@@ -59,6 +63,53 @@ func TestGenerateUniqueIDOrder(t *testing.T) {
 	}
 }
 
+// TestFollowerReadTimestamp verifies that follower_read_timestamp() trails
+// the statement timestamp by the follower read offset, and that it can be used
+// in AS OF SYSTEM TIME clauses.
+func TestFollowerReadTimestamp(t *testing.T) {
+	st := cluster.MakeTestingClusterSettings()
+	evalCtx := tree.NewTestingEvalContext(st)
+	defer evalCtx.Stop(context.Background())
+
+	stmtTimestamp := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	evalCtx.SetStmtTimestamp(stmtTimestamp)
+
+	for _, targetDuration := range []time.Duration{30 * time.Second, time.Second, 0} {
+		t.Run(targetDuration.String(), func(t *testing.T) {
+			closedts.TargetDuration.Override(&st.SV, targetDuration)
+			offset := closedts.FollowerReadOffset(&st.SV)
+			if targetDuration > 0 && offset <= targetDuration {
+				t.Fatalf("expected an offset greater than %s, got %s", targetDuration, offset)
+			}
+			expected := stmtTimestamp.Add(-offset)
+
+			expr := &tree.FuncExpr{Func: tree.WrapFunction(tree.FollowerReadTimestampFunctionName)}
+			typedExpr, err := expr.TypeCheck(&tree.SemaContext{}, types.TimestampTZ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err := typedExpr.Eval(evalCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts := d.(*tree.DTimestampTZ).Time; !ts.Equal(expected) {
+				t.Fatalf("expected %s, got %s", expected, ts)
+			}
+
+			asOf := tree.AsOfClause{Expr: &tree.FuncExpr{
+				Func: tree.WrapFunction(tree.FollowerReadTimestampFunctionName),
+			}}
+			ts, err := tree.EvalAsOfTimestamp(asOf, hlc.MaxTimestamp, &tree.SemaContext{}, evalCtx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts.WallTime != expected.UnixNano() {
+				t.Fatalf("expected AS OF SYSTEM TIME %d, got %d", expected.UnixNano(), ts.WallTime)
+			}
+		})
+	}
+}
+
 func TestStringToArrayAndBack(t *testing.T) {
 	// s allows us to have a string pointer literal.
 	s := func(x string) *string { return &x }
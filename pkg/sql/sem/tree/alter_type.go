@@ -0,0 +1,52 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/lex"
+
+// AlterTypeAddValue represents an ALTER TYPE ... ADD VALUE statement.
+type AlterTypeAddValue struct {
+	Name        TableName
+	NewVal      string
+	IfNotExists bool
+	// Placement is nil if the new value is added after the existing ones.
+	Placement *AlterTypeAddValuePlacement
+}
+
+// AlterTypeAddValuePlacement represents the placement clause of an ALTER
+// TYPE ... ADD VALUE statement.
+type AlterTypeAddValuePlacement struct {
+	Before      bool
+	ExistingVal string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTypeAddValue) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER TYPE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ADD VALUE ")
+	if node.IfNotExists {
+		ctx.WriteString("IF NOT EXISTS ")
+	}
+	lex.EncodeSQLString(ctx.Buffer, node.NewVal)
+	if node.Placement != nil {
+		if node.Placement.Before {
+			ctx.WriteString(" BEFORE ")
+		} else {
+			ctx.WriteString(" AFTER ")
+		}
+		lex.EncodeSQLString(ctx.Buffer, node.Placement.ExistingVal)
+	}
+}
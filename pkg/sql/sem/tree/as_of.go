@@ -26,10 +26,57 @@ import (
 	"github.com/pkg/errors"
 )
 
+// FollowerReadTimestampFunctionName is the name of the function which returns
+// a timestamp which is likely to be old enough for reads to be served by any
+// replica. Unlike other impure functions, it can be used in AS OF SYSTEM TIME
+// clauses.
+const FollowerReadTimestampFunctionName = "follower_read_timestamp"
+
+// WithMaxStalenessFunctionName and WithMinTimestampFunctionName are the names
+// of the functions which make an AS OF SYSTEM TIME clause a bounded staleness
+// read: rather than at a fixed timestamp, the read is performed at the newest
+// timestamp at which it can be served locally, provided that this timestamp is
+// not staler than the bound given as argument.
+const (
+	WithMaxStalenessFunctionName = "with_max_staleness"
+	WithMinTimestampFunctionName = "with_min_timestamp"
+)
+
+// ErrBoundedStalenessStatement is returned when a bounded staleness read is
+// requested by a statement other than SELECT.
+var ErrBoundedStalenessStatement = errors.New(
+	"AS OF SYSTEM TIME: bounded staleness reads are only supported for SELECT statements")
+
+// AsOfSystemTime is the result of evaluating an AS OF SYSTEM TIME clause.
+type AsOfSystemTime struct {
+	// Timestamp is the timestamp of the read. For bounded staleness reads, it is
+	// the minimum timestamp at which the read may be performed.
+	Timestamp hlc.Timestamp
+	// BoundedStaleness is set if the clause uses with_max_staleness() or
+	// with_min_timestamp(), in which case the timestamp of the read remains to be
+	// negotiated.
+	BoundedStaleness bool
+}
+
 // EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
+// Bounded staleness reads are rejected; use EvalAsOf to accept them.
 func EvalAsOfTimestamp(
 	asOf AsOfClause, max hlc.Timestamp, semaCtx *SemaContext, evalCtx *EvalContext,
 ) (hlc.Timestamp, error) {
+	res, err := EvalAsOf(asOf, max, semaCtx, evalCtx)
+	if err != nil {
+		return hlc.Timestamp{}, err
+	}
+	if res.BoundedStaleness {
+		return hlc.Timestamp{}, ErrBoundedStalenessStatement
+	}
+	return res.Timestamp, nil
+}
+
+// EvalAsOf evaluates an AS OF SYSTEM TIME clause.
+func EvalAsOf(
+	asOf AsOfClause, max hlc.Timestamp, semaCtx *SemaContext, evalCtx *EvalContext,
+) (AsOfSystemTime, error) {
 	// We need to save and restore the previous value of the field in
 	// semaCtx in case we are recursively called within a subquery
 	// context.
@@ -39,14 +86,22 @@ func EvalAsOfTimestamp(
 
 	te, err := asOf.Expr.TypeCheck(semaCtx, types.String)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return AsOfSystemTime{}, err
 	}
-	if !IsConst(evalCtx, te) {
-		return hlc.Timestamp{}, errors.Errorf("AS OF SYSTEM TIME: only constant expressions are allowed")
+	var d Datum
+	fn := asOfFuncName(te)
+	boundedStaleness := fn == WithMaxStalenessFunctionName || fn == WithMinTimestampFunctionName
+	if boundedStaleness {
+		d, err = evalBoundedStalenessBound(te.(*FuncExpr), fn, evalCtx)
+	} else if IsConst(evalCtx, te) || fn == FollowerReadTimestampFunctionName {
+		d, err = te.Eval(evalCtx)
+	} else {
+		err = errors.Errorf(
+			"AS OF SYSTEM TIME: only constant expressions or %s() are allowed",
+			FollowerReadTimestampFunctionName)
 	}
-	d, err := te.Eval(evalCtx)
 	if err != nil {
-		return hlc.Timestamp{}, err
+		return AsOfSystemTime{}, err
 	}
 
 	var ts hlc.Timestamp
@@ -72,6 +127,8 @@ func EvalAsOfTimestamp(
 			break
 		}
 		convErr = errors.Errorf("AS OF SYSTEM TIME: value is neither timestamp, decimal, nor interval")
+	case *DTimestampTZ:
+		ts.WallTime = d.Time.UnixNano()
 	case *DInt:
 		ts.WallTime = int64(*d)
 	case *DDecimal:
@@ -81,19 +138,60 @@ func EvalAsOfTimestamp(
 	default:
 		convErr = errors.Errorf("AS OF SYSTEM TIME: expected timestamp, decimal, or interval, got %s (%T)", d.ResolvedType(), d)
 	}
+	res := AsOfSystemTime{Timestamp: ts, BoundedStaleness: boundedStaleness}
 	if convErr != nil {
-		return ts, convErr
+		return res, convErr
 	}
 
 	var zero hlc.Timestamp
 	if ts == zero {
-		return ts, errors.Errorf("AS OF SYSTEM TIME: zero timestamp is invalid")
+		return res, errors.Errorf("AS OF SYSTEM TIME: zero timestamp is invalid")
 	} else if ts.Less(zero) {
-		return ts, errors.Errorf("AS OF SYSTEM TIME: timestamp before 1970-01-01T00:00:00Z is invalid")
+		return res, errors.Errorf("AS OF SYSTEM TIME: timestamp before 1970-01-01T00:00:00Z is invalid")
 	} else if max.Less(ts) {
-		return ts, errors.Errorf("AS OF SYSTEM TIME: cannot specify timestamp in the future")
+		return res, errors.Errorf("AS OF SYSTEM TIME: cannot specify timestamp in the future")
+	}
+	return res, nil
+}
+
+// asOfFuncName returns the name of the function called by the type-checked
+// expression, or the empty string if the expression is not a function call.
+func asOfFuncName(expr TypedExpr) string {
+	f, ok := expr.(*FuncExpr)
+	if !ok {
+		return ""
+	}
+	def, ok := f.Func.FunctionReference.(*FunctionDefinition)
+	if !ok {
+		return ""
+	}
+	return def.Name
+}
+
+// evalBoundedStalenessBound evaluates the minimum timestamp allowed by a call
+// to with_max_staleness() or with_min_timestamp(). The function itself is not
+// evaluated; it only marks the AS OF SYSTEM TIME clause.
+func evalBoundedStalenessBound(f *FuncExpr, name string, evalCtx *EvalContext) (Datum, error) {
+	arg := f.Exprs[0].(TypedExpr)
+	if !IsConst(evalCtx, arg) {
+		return nil, errors.Errorf(
+			"AS OF SYSTEM TIME: the argument of %s() must be a constant expression", name)
+	}
+	d, err := arg.Eval(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	switch d := d.(type) {
+	case *DInterval:
+		if d.Duration.Compare(duration.Duration{}) <= 0 {
+			return nil, errors.Errorf("AS OF SYSTEM TIME: the staleness bound must be positive")
+		}
+		minTime := duration.Add(evalCtx, evalCtx.GetStmtTimestamp(), d.Duration.Mul(-1))
+		return MakeDTimestampTZ(minTime, time.Nanosecond), nil
+	case *DTimestampTZ:
+		return d, nil
 	}
-	return ts, nil
+	return nil, errors.Errorf("AS OF SYSTEM TIME: the argument of %s() must not be NULL", name)
 }
 
 // DecimalToHLC performs the conversion from an inputted DECIMAL datum for an
@@ -110,6 +110,9 @@ type CreateIndex struct {
 	Storing     NameList
 	Interleave  *InterleaveDef
 	PartitionBy *PartitionBy
+	// Predicate, if not nil, restricts the index to the rows for which it
+	// evaluates to true (i.e. a partial index).
+	Predicate Expr
 }
 
 // Format implements the NodeFormatter interface.
@@ -145,6 +148,10 @@ func (node *CreateIndex) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // TableDef represents a column, index or constraint definition within a CREATE
@@ -210,6 +217,7 @@ type ColumnTableDef struct {
 		ConstraintName Name
 		Actions        ReferenceActions
 		Match          CompositeKeyMatchMethod
+		Deferrable     ConstraintDeferrability
 	}
 	Computed struct {
 		Computed bool
@@ -316,6 +324,7 @@ func NewColumnTableDef(
 			d.References.ConstraintName = c.Name
 			d.References.Actions = t.Actions
 			d.References.Match = t.Match
+			d.References.Deferrable = t.Deferrable
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
@@ -419,6 +428,7 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 			ctx.WriteString(node.References.Match.String())
 		}
 		ctx.FormatNode(&node.References.Actions)
+		ctx.FormatNode(node.References.Deferrable)
 	}
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
@@ -492,10 +502,11 @@ type ColumnCheckConstraint struct {
 
 // ColumnFKConstraint represents a FK-constaint on a column.
 type ColumnFKConstraint struct {
-	Table   TableName
-	Col     Name // empty-string means use PK
-	Actions ReferenceActions
-	Match   CompositeKeyMatchMethod
+	Table      TableName
+	Col        Name // empty-string means use PK
+	Actions    ReferenceActions
+	Match      CompositeKeyMatchMethod
+	Deferrable ConstraintDeferrability
 }
 
 // ColumnComputedDef represents the description of a computed column.
@@ -519,6 +530,9 @@ type IndexTableDef struct {
 	Interleave  *InterleaveDef
 	Inverted    bool
 	PartitionBy *PartitionBy
+	// Predicate, if not nil, restricts the index to the rows for which it
+	// evaluates to true (i.e. a partial index).
+	Predicate Expr
 }
 
 // SetName implements the TableDef interface.
@@ -550,6 +564,10 @@ func (node *IndexTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
 }
 
 // ConstraintTableDef represents a constraint definition within a CREATE TABLE
@@ -568,6 +586,7 @@ func (*UniqueConstraintTableDef) constraintTableDef() {}
 type UniqueConstraintTableDef struct {
 	IndexTableDef
 	PrimaryKey bool
+	Deferrable ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -596,6 +615,11 @@ func (node *UniqueConstraintTableDef) Format(ctx *FmtCtx) {
 	if node.PartitionBy != nil {
 		ctx.FormatNode(node.PartitionBy)
 	}
+	if node.Predicate != nil {
+		ctx.WriteString(" WHERE ")
+		ctx.FormatNode(node.Predicate)
+	}
+	ctx.FormatNode(node.Deferrable)
 }
 
 // ReferenceAction is the method used to maintain referential integrity through
@@ -663,14 +687,46 @@ func (c CompositeKeyMatchMethod) String() string {
 	return compositeKeyMatchMethodName[c]
 }
 
+// ConstraintDeferrability specifies whether the checks of a constraint can be
+// deferred until the end of the transaction, and whether they are deferred by
+// default. See https://www.postgresql.org/docs/11/sql-set-constraints.html.
+type ConstraintDeferrability int
+
+// The values for ConstraintDeferrability.
+const (
+	NotDeferrable ConstraintDeferrability = iota
+	DeferrableInitiallyImmediate
+	DeferrableInitiallyDeferred
+)
+
+var constraintDeferrabilityName = [...]string{
+	NotDeferrable:                "NOT DEFERRABLE",
+	DeferrableInitiallyImmediate: "DEFERRABLE",
+	DeferrableInitiallyDeferred:  "DEFERRABLE INITIALLY DEFERRED",
+}
+
+func (d ConstraintDeferrability) String() string {
+	return constraintDeferrabilityName[d]
+}
+
+// Format implements the NodeFormatter interface.
+func (d ConstraintDeferrability) Format(ctx *FmtCtx) {
+	// We omit NOT DEFERRABLE because it is the default.
+	if d != NotDeferrable {
+		ctx.WriteByte(' ')
+		ctx.WriteString(d.String())
+	}
+}
+
 // ForeignKeyConstraintTableDef represents a FOREIGN KEY constraint in the AST.
 type ForeignKeyConstraintTableDef struct {
-	Name     Name
-	Table    TableName
-	FromCols NameList
-	ToCols   NameList
-	Actions  ReferenceActions
-	Match    CompositeKeyMatchMethod
+	Name       Name
+	Table      TableName
+	FromCols   NameList
+	ToCols     NameList
+	Actions    ReferenceActions
+	Match      CompositeKeyMatchMethod
+	Deferrable ConstraintDeferrability
 }
 
 // Format implements the NodeFormatter interface.
@@ -698,6 +754,7 @@ func (node *ForeignKeyConstraintTableDef) Format(ctx *FmtCtx) {
 	}
 
 	ctx.FormatNode(&node.Actions)
+	ctx.FormatNode(node.Deferrable)
 }
 
 // SetName implements the TableDef interface.
@@ -877,6 +934,7 @@ func (node *RangePartition) Format(ctx *FmtCtx) {
 type CreateTable struct {
 	IfNotExists   bool
 	Table         TableName
+	Temporary     bool
 	Interleave    *InterleaveDef
 	PartitionBy   *PartitionBy
 	Defs          TableDefs
@@ -892,7 +950,11 @@ func (node *CreateTable) As() bool {
 
 // Format implements the NodeFormatter interface.
 func (node *CreateTable) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE TABLE ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	ctx.WriteString("TABLE ")
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
 	}
@@ -968,12 +1030,13 @@ func (node *CreateTable) HoistConstraints() {
 					targetCol = append(targetCol, col.References.Col)
 				}
 				node.Defs = append(node.Defs, &ForeignKeyConstraintTableDef{
-					Table:    *col.References.Table,
-					FromCols: NameList{col.Name},
-					ToCols:   targetCol,
-					Name:     col.References.ConstraintName,
-					Actions:  col.References.Actions,
-					Match:    col.References.Match,
+					Table:      *col.References.Table,
+					FromCols:   NameList{col.Name},
+					ToCols:     targetCol,
+					Name:       col.References.ConstraintName,
+					Actions:    col.References.Actions,
+					Match:      col.References.Match,
+					Deferrable: col.References.Deferrable,
 				})
 				col.References.Table = nil
 			}
@@ -981,16 +1044,56 @@ func (node *CreateTable) HoistConstraints() {
 	}
 }
 
+// CreateSchema represents a CREATE SCHEMA statement.
+type CreateSchema struct {
+	IfNotExists bool
+	Schema      Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSchema) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEMA ")
+	if node.IfNotExists {
+		ctx.WriteString("IF NOT EXISTS ")
+	}
+	ctx.FormatNode(&node.Schema)
+}
+
+// CreateType represents a CREATE TYPE ... AS ENUM statement.
+type CreateType struct {
+	Name       TableName
+	EnumLabels []string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateType) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TYPE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" AS ENUM (")
+	for i, label := range node.EnumLabels {
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		lex.EncodeSQLString(ctx.Buffer, label)
+	}
+	ctx.WriteByte(')')
+}
+
 // CreateSequence represents a CREATE SEQUENCE statement.
 type CreateSequence struct {
 	IfNotExists bool
 	Name        TableName
+	Temporary   bool
 	Options     SequenceOptions
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateSequence) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE SEQUENCE ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	ctx.WriteString("SEQUENCE ")
 	if node.IfNotExists {
 		ctx.WriteString("IF NOT EXISTS ")
 	}
@@ -1139,14 +1242,23 @@ func (node *CreateRole) Format(ctx *FmtCtx) {
 
 // CreateView represents a CREATE VIEW statement.
 type CreateView struct {
-	Name        TableName
-	ColumnNames NameList
-	AsSource    *Select
+	Name         TableName
+	ColumnNames  NameList
+	AsSource     *Select
+	Temporary    bool
+	Materialized bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *CreateView) Format(ctx *FmtCtx) {
-	ctx.WriteString("CREATE VIEW ")
+	ctx.WriteString("CREATE ")
+	if node.Temporary {
+		ctx.WriteString("TEMPORARY ")
+	}
+	if node.Materialized {
+		ctx.WriteString("MATERIALIZED ")
+	}
+	ctx.WriteString("VIEW ")
 	ctx.FormatNode(&node.Name)
 
 	if len(node.ColumnNames) > 0 {
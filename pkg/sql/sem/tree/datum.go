@@ -1254,6 +1254,145 @@ func (d *DCollatedString) IsComposite() bool {
 	return true
 }
 
+// DEnum is the Datum for a member of a user-defined ENUM type. The struct
+// members are intended to be immutable.
+type DEnum struct {
+	EnumTyp *types.TEnum
+	// PhysicalRep is the encoding of the member. The physical representations
+	// of the members of a type sort in the order of the members.
+	PhysicalRep []byte
+	// LogicalRep is the label of the member.
+	LogicalRep string
+}
+
+// NewDEnumFromLogicalRep returns the member of the ENUM type with the given
+// label. The read-only members of the type, which are being added to it,
+// can't be input.
+func NewDEnumFromLogicalRep(typ *types.TEnum, logicalRep string) (*DEnum, error) {
+	for i := range typ.LogicalReps {
+		if typ.LogicalReps[i] != logicalRep {
+			continue
+		}
+		if typ.IsReadOnly(i) {
+			return nil, pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+				"enum value %q is not yet public", logicalRep)
+		}
+		return &DEnum{EnumTyp: typ, PhysicalRep: typ.PhysicalReps[i], LogicalRep: logicalRep}, nil
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+		"invalid input value for enum %s: %q", typ.TypeName, logicalRep)
+}
+
+// NewDEnumFromPhysicalRep returns the member of the ENUM type with the given
+// physical representation.
+func NewDEnumFromPhysicalRep(typ *types.TEnum, physicalRep []byte) (*DEnum, error) {
+	logicalRep, ok := typ.LogicalRepOf(physicalRep)
+	if !ok {
+		return nil, pgerror.NewAssertionErrorf(
+			"could not find member of enum %s with representation %x", typ.TypeName, physicalRep)
+	}
+	// Use the representation of the type, so that the datum doesn't alias the
+	// buffer it was decoded from.
+	physicalRep, _ = typ.PhysicalRepOf(logicalRep)
+	return &DEnum{EnumTyp: typ, PhysicalRep: physicalRep, LogicalRep: logicalRep}, nil
+}
+
+// memberIdx returns the position of the member in its type.
+func (d *DEnum) memberIdx() int {
+	for i := range d.EnumTyp.PhysicalReps {
+		if bytes.Equal(d.EnumTyp.PhysicalReps[i], d.PhysicalRep) {
+			return i
+		}
+	}
+	panic(fmt.Sprintf("member %q not found in enum %s", d.LogicalRep, d.EnumTyp.TypeName))
+}
+
+func (d *DEnum) memberAt(idx int) *DEnum {
+	return &DEnum{
+		EnumTyp:     d.EnumTyp,
+		PhysicalRep: d.EnumTyp.PhysicalReps[idx],
+		LogicalRep:  d.EnumTyp.LogicalReps[idx],
+	}
+}
+
+// AmbiguousFormat implements the Datum interface.
+//
+// The members of an ENUM are formatted as their labels. This is not
+// ambiguous in practice: the type of the value is always recovered from the
+// context in which it is re-parsed, like the column it is compared with.
+func (*DEnum) AmbiguousFormat() bool { return false }
+
+// Format implements the NodeFormatter interface.
+func (d *DEnum) Format(ctx *FmtCtx) {
+	buf, f := ctx.Buffer, ctx.flags
+	if f.HasFlags(fmtRawStrings) {
+		buf.WriteString(d.LogicalRep)
+	} else {
+		lex.EncodeSQLStringWithFlags(buf, d.LogicalRep, f.EncodeFlags())
+	}
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DEnum) ResolvedType() types.T {
+	return d.EnumTyp
+}
+
+// Compare implements the Datum interface.
+func (d *DEnum) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DEnum)
+	if !ok || d.EnumTyp.ID != v.EnumTyp.ID {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return bytes.Compare(d.PhysicalRep, v.PhysicalRep)
+}
+
+// Prev implements the Datum interface.
+func (d *DEnum) Prev(_ *EvalContext) (Datum, bool) {
+	idx := d.memberIdx()
+	if idx == 0 {
+		return nil, false
+	}
+	return d.memberAt(idx - 1), true
+}
+
+// Next implements the Datum interface.
+func (d *DEnum) Next(_ *EvalContext) (Datum, bool) {
+	idx := d.memberIdx()
+	if idx == len(d.EnumTyp.PhysicalReps)-1 {
+		return nil, false
+	}
+	return d.memberAt(idx + 1), true
+}
+
+// IsMax implements the Datum interface.
+func (d *DEnum) IsMax(_ *EvalContext) bool {
+	return d.memberIdx() == len(d.EnumTyp.PhysicalReps)-1
+}
+
+// IsMin implements the Datum interface.
+func (d *DEnum) IsMin(_ *EvalContext) bool {
+	return d.memberIdx() == 0
+}
+
+// Min implements the Datum interface.
+func (d *DEnum) Min(_ *EvalContext) (Datum, bool) {
+	return d.memberAt(0), true
+}
+
+// Max implements the Datum interface.
+func (d *DEnum) Max(_ *EvalContext) (Datum, bool) {
+	return d.memberAt(len(d.EnumTyp.PhysicalReps) - 1), true
+}
+
+// Size implements the Datum interface.
+func (d *DEnum) Size() uintptr {
+	return unsafe.Sizeof(*d) + uintptr(len(d.PhysicalRep)) + uintptr(len(d.LogicalRep))
+}
+
 // DBytes is the bytes Datum. The underlying type is a string because we want
 // the immutability, but this may contain arbitrary bytes.
 type DBytes string
@@ -2433,6 +2572,8 @@ func AsJSON(d Datum) (json.JSON, error) {
 		return json.FromString(string(*t)), nil
 	case *DCollatedString:
 		return json.FromString(t.Contents), nil
+	case *DEnum:
+		return json.FromString(t.LogicalRep), nil
 	case *DJSON:
 		return t.JSON, nil
 	case *DArray:
@@ -3510,6 +3651,9 @@ func DatumTypeSize(t types.T) (uintptr, bool) {
 	case types.TCollatedString:
 		return unsafe.Sizeof(DCollatedString{"", "", nil}), variableSize
 
+	case *types.TEnum:
+		return unsafe.Sizeof(DEnum{}), variableSize
+
 	case types.TTuple:
 		sz := uintptr(0)
 		variable := false
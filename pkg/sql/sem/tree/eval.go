@@ -1693,6 +1693,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEqFn(types.Date, types.Date),
 		makeEqFn(types.Decimal, types.Decimal),
 		makeEqFn(types.FamCollatedString, types.FamCollatedString),
+		makeEqFn(types.FamEnum, types.FamEnum),
 		makeEqFn(types.Float, types.Float),
 		makeEqFn(types.INet, types.INet),
 		makeEqFn(types.Int, types.Int),
@@ -1737,6 +1738,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeLtFn(types.Date, types.Date),
 		makeLtFn(types.Decimal, types.Decimal),
 		makeLtFn(types.FamCollatedString, types.FamCollatedString),
+		makeLtFn(types.FamEnum, types.FamEnum),
 		makeLtFn(types.Float, types.Float),
 		makeLtFn(types.INet, types.INet),
 		makeLtFn(types.Int, types.Int),
@@ -1780,6 +1782,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeLeFn(types.Date, types.Date),
 		makeLeFn(types.Decimal, types.Decimal),
 		makeLeFn(types.FamCollatedString, types.FamCollatedString),
+		makeLeFn(types.FamEnum, types.FamEnum),
 		makeLeFn(types.Float, types.Float),
 		makeLeFn(types.INet, types.INet),
 		makeLeFn(types.Int, types.Int),
@@ -1831,6 +1834,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeIsFn(types.Date, types.Date),
 		makeIsFn(types.Decimal, types.Decimal),
 		makeIsFn(types.FamCollatedString, types.FamCollatedString),
+		makeIsFn(types.FamEnum, types.FamEnum),
 		makeIsFn(types.Float, types.Float),
 		makeIsFn(types.INet, types.INet),
 		makeIsFn(types.Int, types.Int),
@@ -1878,6 +1882,7 @@ var CmpOps = map[ComparisonOperator]cmpOpOverload{
 		makeEvalTupleIn(types.Date),
 		makeEvalTupleIn(types.Decimal),
 		makeEvalTupleIn(types.FamCollatedString),
+		makeEvalTupleIn(types.FamEnum),
 		makeEvalTupleIn(types.FamTuple),
 		makeEvalTupleIn(types.Float),
 		makeEvalTupleIn(types.INet),
@@ -3112,6 +3117,8 @@ func PerformCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 			s = string(*t)
 		case *DCollatedString:
 			s = t.Contents
+		case *DEnum:
+			s = t.LogicalRep
 		case *DBytes:
 			s = lex.EncodeByteArrayToRawBytes(string(*t),
 				ctx.SessionData.DataConversion.BytesEncodeFormat, false /* skipHexPrefix */)
@@ -3283,6 +3290,20 @@ func PerformCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 		case *DJSON:
 			return v, nil
 		}
+	case *coltypes.TEnum:
+		if typ.Typ == nil {
+			return nil, pgerror.NewAssertionErrorf("type %s has not been resolved", typ.Name)
+		}
+		switch v := d.(type) {
+		case *DString:
+			return NewDEnumFromLogicalRep(typ.Typ, string(*v))
+		case *DCollatedString:
+			return NewDEnumFromLogicalRep(typ.Typ, v.Contents)
+		case *DEnum:
+			if v.EnumTyp.ID == typ.Typ.ID {
+				return v, nil
+			}
+		}
 	case *coltypes.TArray:
 		switch v := d.(type) {
 		case *DString:
@@ -3378,12 +3399,13 @@ func PerformCast(ctx *EvalContext, d Datum, t coltypes.CastTargetType) (Datum, e
 				return queryOid(ctx, typ, NewDString(funcDef.Name))
 			case coltypes.RegType:
 				colType, err := ctx.Planner.ParseType(s)
-				if err == nil {
+				if _, isUserDefined := colType.(*coltypes.TEnum); err == nil && !isUserDefined {
 					datumType := coltypes.CastTargetToDatumType(colType)
 					return &DOid{semanticType: typ, DInt: DInt(datumType.Oid()), name: datumType.SQLName()}, nil
 				}
 				// Fall back to searching pg_type, since we don't provide syntax for
-				// every postgres type that we understand OIDs for.
+				// every postgres type that we understand OIDs for. This is also where
+				// the user-defined types are found.
 				// Trim type modifiers, e.g. `numeric(10,3)` becomes `numeric`.
 				s = pgSignatureRegexp.ReplaceAllString(s, "$1")
 				return queryOid(ctx, typ, NewDString(s))
@@ -3944,6 +3966,11 @@ func (t *DCollatedString) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
 }
 
+// Eval implements the TypedExpr interface.
+func (t *DEnum) Eval(_ *EvalContext) (Datum, error) {
+	return t, nil
+}
+
 // Eval implements the TypedExpr interface.
 func (t *DTimestamp) Eval(_ *EvalContext) (Datum, error) {
 	return t, nil
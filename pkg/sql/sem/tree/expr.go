@@ -1223,11 +1223,31 @@ type FuncExpr struct {
 	Filter    Expr
 	WindowDef *WindowDef
 
+	// AggType is used to specify the type of aggregation.
+	AggType AggType
+	// OrderBy is used for aggregations which specify an order. This is
+	// currently only used by ordered-set aggregates:
+	// PERCENTILE_DISC(0.5) WITHIN GROUP (ORDER BY k)
+	OrderBy OrderBy
+
 	typeAnnotation
 	fnProps *FunctionProperties
 	fn      *Overload
 }
 
+// AggType specifies the type of aggregation.
+type AggType int
+
+// FuncExpr.AggType
+const (
+	// GeneralAgg is used for general-purpose aggregate functions.
+	// sum(col)
+	GeneralAgg AggType = iota
+	// OrderedSetAgg is used for ordered-set aggregate functions.
+	// percentile_disc(fraction) WITHIN GROUP (ORDER BY col)
+	OrderedSetAgg
+)
+
 // NewTypedFuncExpr returns a FuncExpr that is already well-typed and resolved.
 func NewTypedFuncExpr(
 	ref ResolvableFunctionReference,
@@ -1255,6 +1275,20 @@ func NewTypedFuncExpr(
 	return f
 }
 
+// AggregateArgs returns the arguments passed to the aggregate function
+// implementation. For ordered-set aggregates, the expression in the WITHIN
+// GROUP clause precedes the direct arguments of the call.
+func (node *FuncExpr) AggregateArgs() Exprs {
+	if node.AggType != OrderedSetAgg {
+		return node.Exprs
+	}
+	args := make(Exprs, 0, len(node.OrderBy)+len(node.Exprs))
+	for _, o := range node.OrderBy {
+		args = append(args, o.Expr)
+	}
+	return append(args, node.Exprs...)
+}
+
 // ResolvedOverload returns the builtin definition; can only be called after
 // Resolve (which happens during TypeCheck).
 func (node *FuncExpr) ResolvedOverload() *Overload {
@@ -1345,6 +1379,11 @@ func (node *FuncExpr) Format(ctx *FmtCtx) {
 	ctx.WriteString(typ)
 	ctx.FormatNode(&node.Exprs)
 	ctx.WriteByte(')')
+	if node.AggType == OrderedSetAgg {
+		ctx.WriteString(" WITHIN GROUP (")
+		ctx.FormatNode(&node.OrderBy)
+		ctx.WriteByte(')')
+	}
 	if ctx.HasFlags(FmtParsable) && node.typ != nil {
 		if node.fnProps.AmbiguousReturnType {
 			if typ, err := coltypes.DatumTypeToColumnType(node.typ); err == nil {
@@ -1502,7 +1541,8 @@ var (
 	stringCastTypes = []types.T{types.Unknown, types.Bool, types.Int, types.Float, types.Decimal, types.String, types.FamCollatedString,
 		types.BitArray,
 		types.FamArray, types.FamTuple,
-		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Time, types.Oid, types.INet, types.JSON}
+		types.Bytes, types.Timestamp, types.TimestampTZ, types.Interval, types.UUID, types.Date, types.Time, types.Oid, types.INet, types.JSON,
+		types.FamEnum}
 	bytesCastTypes = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Bytes, types.UUID}
 	dateCastTypes  = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Date, types.Timestamp, types.TimestampTZ, types.Int}
 	timeCastTypes  = []types.T{types.Unknown, types.String, types.FamCollatedString, types.Time,
@@ -1558,6 +1598,9 @@ func validCastTypes(t types.T) []types.T {
 			ret := make([]types.T, len(arrayCastTypes))
 			copy(ret, arrayCastTypes)
 			return ret
+		} else if e, ok := types.UnwrapType(t).(*types.TEnum); ok {
+			// An ENUM can only be cast to from strings and from itself.
+			return []types.T{types.Unknown, types.String, types.FamCollatedString, e}
 		}
 		return nil
 	}
@@ -1719,6 +1762,7 @@ func (node *DUuid) String() string            { return AsString(node) }
 func (node *DIPAddr) String() string          { return AsString(node) }
 func (node *DString) String() string          { return AsString(node) }
 func (node *DCollatedString) String() string  { return AsString(node) }
+func (node *DEnum) String() string            { return AsString(node) }
 func (node *DTimestamp) String() string       { return AsString(node) }
 func (node *DTimestampTZ) String() string     { return AsString(node) }
 func (node *DTuple) String() string           { return AsString(node) }
@@ -1743,6 +1787,7 @@ func (node *ParenExpr) String() string        { return AsString(node) }
 func (node *RangeCond) String() string        { return AsString(node) }
 func (node *StrVal) String() string           { return AsString(node) }
 func (node *Subquery) String() string         { return AsString(node) }
+func (node *GroupingSet) String() string      { return AsString(node) }
 func (node *Tuple) String() string            { return AsString(node) }
 func (node *TupleStar) String() string        { return AsString(node) }
 func (node *AnnotateTypeExpr) String() string { return AsString(node) }
@@ -84,6 +84,12 @@ type FunctionProperties struct {
 	// Class is the kind of built-in function (normal/aggregate/window/etc.)
 	Class FunctionClass
 
+	// OrderedSetAgg is set to true for ordered-set aggregate functions, which
+	// must be called with a WITHIN GROUP clause. The expression in the
+	// WITHIN GROUP clause is passed to the aggregate as its first argument,
+	// ahead of the direct arguments of the call.
+	OrderedSetAgg bool
+
 	// Category is used to generate documentation strings.
 	Category string
 
@@ -295,6 +295,7 @@ func (t *TableName) ResolveExisting(
 	curDb string,
 	searchPath sessiondata.SearchPath,
 ) (bool, NameResolutionResult, error) {
+	t.resolveTemporarySchemaAlias(searchPath)
 	if t.ExplicitSchema {
 		if t.ExplicitCatalog {
 			// Already 3 parts: nothing to search. Delegate to the resolver.
@@ -346,6 +347,7 @@ func (t *TableName) ResolveExisting(
 func (t *TableName) ResolveTarget(
 	ctx context.Context, r TableNameTargetResolver, curDb string, searchPath sessiondata.SearchPath,
 ) (found bool, scMeta SchemaMeta, err error) {
+	t.resolveTemporarySchemaAlias(searchPath)
 	if t.ExplicitSchema {
 		if t.ExplicitCatalog {
 			// Already 3 parts: nothing to do.
@@ -392,6 +394,7 @@ func (t *TableName) ResolveTarget(
 func (tp *TableNamePrefix) Resolve(
 	ctx context.Context, r TableNameTargetResolver, curDb string, searchPath sessiondata.SearchPath,
 ) (found bool, scMeta SchemaMeta, err error) {
+	tp.resolveTemporarySchemaAlias(searchPath)
 	if tp.ExplicitSchema {
 		if tp.ExplicitCatalog {
 			// Catalog name is explicit; nothing to do.
@@ -433,6 +436,16 @@ func (tp *TableNamePrefix) Resolve(
 	return found, scMeta, err
 }
 
+// resolveTemporarySchemaAlias replaces an explicit pg_temp schema with the
+// name of the temporary schema of the session, if the session has one.
+func (tp *TableNamePrefix) resolveTemporarySchemaAlias(searchPath sessiondata.SearchPath) {
+	if tp.ExplicitSchema && tp.SchemaName == sessiondata.PgTempSchemaName {
+		if scName := searchPath.GetTemporarySchemaName(); scName != "" {
+			tp.SchemaName = Name(scName)
+		}
+	}
+}
+
 // ResolveFunction transforms an UnresolvedName to a FunctionDefinition.
 //
 // Function resolution currently takes a "short path" using the
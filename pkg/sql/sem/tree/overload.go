@@ -768,7 +768,7 @@ func checkReturn(
 		o := s.overloads[idx]
 		p := o.params()
 		for _, i := range s.constIdxs {
-			des := p.GetAt(i)
+			des := concreteEnumType(s, p.GetAt(i))
 			typ, err := s.exprs[i].TypeCheck(ctx, des)
 			if err != nil {
 				return s.typedExprs, nil, true, errors.Wrap(err, "error type checking constant value")
@@ -779,7 +779,7 @@ func checkReturn(
 		}
 
 		for _, i := range s.placeholderIdxs {
-			des := p.GetAt(i)
+			des := concreteEnumType(s, p.GetAt(i))
 			typ, err := s.exprs[i].TypeCheck(ctx, des)
 			if err != nil {
 				if des.IsAmbiguous() {
@@ -795,6 +795,22 @@ func checkReturn(
 	}
 }
 
+// concreteEnumType returns the ENUM type of one of the resolved arguments if
+// the given parameter type is the ENUM type family, so that the constants and
+// placeholders passed for the parameter are typed as members of that type.
+// Otherwise, the parameter type is returned unchanged.
+func concreteEnumType(s typeCheckOverloadState, param types.T) types.T {
+	if param == nil || !param.FamilyEqual(types.FamEnum) || !param.IsAmbiguous() {
+		return param
+	}
+	for _, i := range s.resolvableIdxs {
+		if typ := s.typedExprs[i].ResolvedType(); typ.FamilyEqual(types.FamEnum) && !typ.IsAmbiguous() {
+			return typ
+		}
+	}
+	return param
+}
+
 func formatCandidates(prefix string, candidates []overloadImpl) string {
 	var buf bytes.Buffer
 	for _, candidate := range candidates {
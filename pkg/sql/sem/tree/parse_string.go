@@ -97,6 +97,13 @@ func parseStringAs(t types.T, s string, ctx ParseTimeContext) (Datum, error) {
 	case types.UUID:
 		return ParseDUuidFromString(s)
 	default:
+		if e, ok := t.(*types.TEnum); ok {
+			if e.IsAmbiguous() {
+				// The members of the type are not known.
+				return nil, makeParseError(s, t, nil)
+			}
+			return NewDEnumFromLogicalRep(e, s)
+		}
 		return nil, nil
 	}
 }
@@ -484,6 +484,9 @@ func (node *Select) docTable(p *PrettyCfg) []pretty.RLTableRow {
 	}
 	items = append(items, node.OrderBy.docRow(p))
 	items = append(items, node.Limit.docTable(p)...)
+	for _, l := range node.Locking {
+		items = append(items, p.row("", p.Doc(l)))
+	}
 	return items
 }
 
@@ -565,7 +568,11 @@ func (node *With) docRow(p *PrettyCfg) pretty.RLTableRow {
 			pretty.Bracket("AS (", p.Doc(cte.Stmt), ")"),
 		)
 	}
-	return p.row("WITH", pretty.Join(",", d...))
+	kw := "WITH"
+	if node.Recursive {
+		kw = "WITH RECURSIVE"
+	}
+	return p.row(kw, pretty.Join(",", d...))
 }
 
 func (node *Subquery) doc(p *PrettyCfg) pretty.Doc {
@@ -584,6 +591,12 @@ func (node *Subquery) doc(p *PrettyCfg) pretty.Doc {
 
 func (node *AliasedTableExpr) doc(p *PrettyCfg) pretty.Doc {
 	d := p.Doc(node.Expr)
+	if node.Lateral {
+		d = pretty.Concat(
+			pretty.Text("LATERAL "),
+			d,
+		)
+	}
 	if node.IndexFlags != nil {
 		d = pretty.Concat(
 			d,
@@ -623,6 +636,12 @@ func (node *FuncExpr) doc(p *PrettyCfg) pretty.Doc {
 	} else {
 		d = pretty.Concat(d, pretty.Text("()"))
 	}
+	if node.AggType == OrderedSetAgg {
+		d = pretty.Fold(pretty.ConcatSpace,
+			d,
+			pretty.Text("WITHIN GROUP"),
+			pretty.Bracket("(", p.Doc(&node.OrderBy), ")"))
+	}
 	if node.Filter != nil {
 		d = pretty.Fold(pretty.ConcatSpace,
 			d,
@@ -800,6 +819,9 @@ func (node *Insert) doc(p *PrettyCfg) pretty.Doc {
 			cond = pretty.Bracket("(", p.Doc(&node.OnConflict.Columns), ")")
 		}
 		items = append(items, p.row("ON CONFLICT", cond))
+		if node.OnConflict.ArbiterPredicate != nil {
+			items = append(items, p.row("WHERE", p.Doc(node.OnConflict.ArbiterPredicate)))
+		}
 
 		if node.OnConflict.DoNothing {
 			items = append(items, p.row("DO", pretty.Text("NOTHING")))
@@ -1016,6 +1038,9 @@ func (node *UpdateExpr) doc(p *PrettyCfg) pretty.Doc {
 
 func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 	title := "CREATE TABLE "
+	if node.Temporary {
+		title = "CREATE TEMPORARY TABLE "
+	}
 	if node.IfNotExists {
 		title += "IF NOT EXISTS "
 	}
@@ -1054,8 +1079,14 @@ func (node *CreateTable) doc(p *PrettyCfg) pretty.Doc {
 }
 
 func (node *CreateView) doc(p *PrettyCfg) pretty.Doc {
+	title := "CREATE VIEW"
+	if node.Temporary {
+		title = "CREATE TEMPORARY VIEW"
+	} else if node.Materialized {
+		title = "CREATE MATERIALIZED VIEW"
+	}
 	d := pretty.ConcatSpace(
-		pretty.Text("CREATE VIEW"),
+		pretty.Text(title),
 		p.Doc(&node.Name),
 	)
 	if len(node.ColumnNames) > 0 {
@@ -1271,6 +1302,9 @@ func (node *CreateIndex) doc(p *PrettyCfg) pretty.Doc {
 	if node.PartitionBy != nil {
 		docs = append(docs, p.Doc(node.PartitionBy))
 	}
+	if node.Predicate != nil {
+		docs = append(docs, p.nestUnder(pretty.Text("WHERE"), p.Doc(node.Predicate)))
+	}
 	return pretty.Group(pretty.Stack(docs...))
 }
 
@@ -1470,6 +1504,9 @@ func (node *TargetList) docRow(p *PrettyCfg) pretty.RLTableRow {
 	if node.Databases != nil {
 		return p.row("DATABASE", p.Doc(&node.Databases))
 	}
+	if node.Schemas != nil {
+		return p.row("SCHEMA", p.Doc(&node.Schemas))
+	}
 	return p.row("TABLE", p.Doc(&node.Tables))
 }
 
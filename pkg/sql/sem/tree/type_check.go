@@ -63,9 +63,26 @@ type SemaContext struct {
 	// globally for the entire txn and this field would not be needed.
 	AsOfTimestamp *hlc.Timestamp
 
+	// AsOfMinTimestamp is set for bounded staleness reads, in which case it is
+	// the minimum timestamp allowed by the AS OF SYSTEM TIME clause while
+	// AsOfTimestamp is the timestamp at which the query is planned.
+	AsOfMinTimestamp hlc.Timestamp
+
+	// TypeResolver is used to resolve the names of user-defined types. If it
+	// is nil, user-defined types can't be referenced by name.
+	TypeResolver TypeReferenceResolver
+
 	Properties SemaProperties
 }
 
+// TypeReferenceResolver is the interface used during semantic analysis to
+// resolve the names of user-defined types.
+type TypeReferenceResolver interface {
+	// ResolveType returns the type with the given name, or an error if no
+	// such type exists.
+	ResolveType(name string) (types.T, error)
+}
+
 // SemaProperties is a holder for required and derived properties
 // during semantic analysis. It provides scoping semantics via its
 // Restore() method, see below.
@@ -414,6 +431,10 @@ func isCastDeepValid(castFrom, castTo types.T) bool {
 	if castTo.FamilyEqual(types.FamArray) && castFrom.FamilyEqual(types.FamArray) {
 		return isCastDeepValid(castFrom.(types.TArray).Typ, castTo.(types.TArray).Typ)
 	}
+	if castTo.FamilyEqual(types.FamEnum) && castFrom.FamilyEqual(types.FamEnum) {
+		// Different ENUM types can't be cast to each other.
+		return castFrom.Equivalent(castTo)
+	}
 	for _, t := range validCastTypes(castTo) {
 		if castFrom.FamilyEqual(t) {
 			return true
@@ -422,8 +443,36 @@ func isCastDeepValid(castFrom, castTo types.T) bool {
 	return false
 }
 
+// ResolveUserDefinedType resolves the name of the user-defined type
+// referenced by a cast or a type annotation. Other types are returned as is.
+func (sc *SemaContext) ResolveUserDefinedType(
+	typ coltypes.CastTargetType,
+) (coltypes.CastTargetType, error) {
+	e, ok := typ.(*coltypes.TEnum)
+	if !ok || e.Typ != nil {
+		return typ, nil
+	}
+	if sc == nil || sc.TypeResolver == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError, "type %q does not exist", e.Name)
+	}
+	resolved, err := sc.TypeResolver.ResolveType(e.Name)
+	if err != nil {
+		return nil, err
+	}
+	enumTyp, ok := resolved.(*types.TEnum)
+	if !ok {
+		return nil, pgerror.NewAssertionErrorf("unexpected user-defined type %s", resolved)
+	}
+	return &coltypes.TEnum{Name: e.Name, Typ: enumTyp}, nil
+}
+
 // TypeCheck implements the Expr interface.
 func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ types.T) (TypedExpr, error) {
+	colType, err := ctx.ResolveUserDefinedType(expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = colType
 	returnType := expr.castType()
 
 	// The desired type provided to a CastExpr is ignored. Instead,
@@ -503,6 +552,11 @@ func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired types.T) (Typed
 
 // TypeCheck implements the Expr interface.
 func (expr *AnnotateTypeExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, error) {
+	colType, err := ctx.ResolveUserDefinedType(expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = colType
 	annotType := expr.annotationType()
 	subExpr, err := typeCheckAndRequire(ctx, expr.Expr, annotType,
 		fmt.Sprintf("type annotation for %v as %s, found", expr.Expr, annotType))
@@ -658,13 +712,15 @@ func (expr *ComparisonExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedE
 }
 
 var (
-	errOrderByIndexInWindow = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in window definition is not supported")
-	errStarNotAllowed       = pgerror.NewError(pgerror.CodeSyntaxError, "cannot use \"*\" in this context")
-	errInvalidDefaultUsage  = pgerror.NewError(pgerror.CodeSyntaxError, "DEFAULT can only appear in a VALUES list within INSERT or on the right side of a SET")
-	errInvalidMaxUsage      = pgerror.NewError(pgerror.CodeSyntaxError, "MAXVALUE can only appear within a range partition expression")
-	errInvalidMinUsage      = pgerror.NewError(pgerror.CodeSyntaxError, "MINVALUE can only appear within a range partition expression")
-	errPrivateFunction      = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "function reserved for internal use")
-	errInsufficientPriv     = pgerror.NewError(pgerror.CodeInsufficientPrivilegeError, "insufficient privilege")
+	errOrderByIndexInWindow      = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in window definition is not supported")
+	errOrderByIndexInWithinGroup = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in WITHIN GROUP is not supported")
+	errStarNotAllowed            = pgerror.NewError(pgerror.CodeSyntaxError, "cannot use \"*\" in this context")
+	errInvalidDefaultUsage       = pgerror.NewError(pgerror.CodeSyntaxError, "DEFAULT can only appear in a VALUES list within INSERT or on the right side of a SET")
+	errInvalidMaxUsage           = pgerror.NewError(pgerror.CodeSyntaxError, "MAXVALUE can only appear within a range partition expression")
+	errInvalidMinUsage           = pgerror.NewError(pgerror.CodeSyntaxError, "MINVALUE can only appear within a range partition expression")
+	errInvalidGroupingSet        = pgerror.NewError(pgerror.CodeSyntaxError, "GROUPING SETS, ROLLUP and CUBE can only appear at the top level of a GROUP BY clause")
+	errPrivateFunction           = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "function reserved for internal use")
+	errInsufficientPriv          = pgerror.NewError(pgerror.CodeInsufficientPrivilegeError, "insufficient privilege")
 )
 
 // NewAggInAggError creates an error for the case when an aggregate function is
@@ -792,7 +848,32 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		ctx.Properties.Derived.inFuncExpr = true
 	}
 
-	typedSubExprs, fns, err := typeCheckOverloadedExprs(ctx, desired, def.Definition, false, expr.Exprs...)
+	if expr.AggType == OrderedSetAgg {
+		if !def.OrderedSetAgg {
+			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"%s is not an ordered-set aggregate, so it cannot have WITHIN GROUP", &expr.Func)
+		}
+		if expr.WindowDef != nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"OVER is not supported for ordered-set aggregate %s", &expr.Func)
+		}
+		for _, o := range expr.OrderBy {
+			if o.OrderType != OrderByColumn {
+				return nil, errOrderByIndexInWithinGroup
+			}
+			if o.Direction == Descending {
+				return nil, pgerror.Unimplemented("within group desc",
+					"DESC is not supported in WITHIN GROUP")
+			}
+		}
+	} else if def.OrderedSetAgg {
+		return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"WITHIN GROUP is required for ordered-set aggregate %s", &expr.Func)
+	}
+
+	typedSubExprs, fns, err := typeCheckOverloadedExprs(
+		ctx, desired, def.Definition, false, expr.AggregateArgs()...,
+	)
 	if err != nil {
 		return nil, errors.Wrapf(err, "%s()", def.Name)
 	}
@@ -887,7 +968,20 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		expr.Filter = typedFilter
 	}
 
-	for i, subExpr := range typedSubExprs {
+	args := typedSubExprs
+	if expr.AggType == OrderedSetAgg {
+		// The arguments of an ordered-set aggregate start with the expressions
+		// in the WITHIN GROUP clause; see AggregateArgs.
+		orderBy := make(OrderBy, len(expr.OrderBy))
+		for i, o := range expr.OrderBy {
+			orderCopy := *o
+			orderCopy.Expr = args[i]
+			orderBy[i] = &orderCopy
+		}
+		expr.OrderBy = orderBy
+		args = args[len(expr.OrderBy):]
+	}
+	for i, subExpr := range args {
 		expr.Exprs[i] = subExpr
 	}
 	expr.fn = overloadImpl
@@ -1186,6 +1280,11 @@ func (expr PartitionMinVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExp
 	return nil, errInvalidMinUsage
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
+	return nil, errInvalidGroupingSet
+}
+
 // TypeCheck implements the Expr interface.
 func (expr PartitionMaxVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
 	return nil, errInvalidMaxUsage
@@ -1357,6 +1456,10 @@ func (d *DString) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { retu
 // identity function for Datum.
 func (d *DCollatedString) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DEnum) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DBytes) TypeCheck(_ *SemaContext, _ types.T) (TypedExpr, error) { return d, nil }
@@ -1695,9 +1798,10 @@ func typeCheckComparisonOp(
 	// Throw a typing error if overload resolution found either no compatible candidates
 	// or if it found an ambiguity.
 	collationMismatch := leftReturn.FamilyEqual(types.FamCollatedString) && !leftReturn.Equivalent(rightReturn)
-	if len(fns) != 1 || collationMismatch {
+	enumMismatch := leftReturn.FamilyEqual(types.FamEnum) && !leftReturn.Equivalent(rightReturn)
+	if len(fns) != 1 || collationMismatch || enumMismatch {
 		sig := fmt.Sprintf(compSignatureFmt, leftReturn, op, rightReturn)
-		if len(fns) == 0 || collationMismatch {
+		if len(fns) == 0 || collationMismatch || enumMismatch {
 			return nil, nil, nil, false,
 				pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError, unsupportedCompErrFmt, sig)
 		}
@@ -16,7 +16,8 @@ package tree
 
 // With represents a WITH statement.
 type With struct {
-	CTEList []*CTE
+	Recursive bool
+	CTEList   []*CTE
 }
 
 // CTE represents a common table expression inside of a WITH clause.
@@ -31,6 +32,9 @@ func (node *With) Format(ctx *FmtCtx) {
 		return
 	}
 	ctx.WriteString("WITH ")
+	if node.Recursive {
+		ctx.WriteString("RECURSIVE ")
+	}
 	for i, cte := range node.CTEList {
 		if i != 0 {
 			ctx.WriteString(", ")
@@ -41,3 +45,19 @@ func (node *With) Format(ctx *FmtCtx) {
 		ctx.WriteString(") ")
 	}
 }
+
+// RecursiveTerms splits the statement of a recursive CTE into its
+// non-recursive and recursive terms. The statement must have the form
+// `non-recursive-term UNION [ALL] recursive-term`; ok is false otherwise. all
+// is true if the terms are combined with UNION ALL.
+func (cte *CTE) RecursiveTerms() (initial, recursive *Select, all bool, ok bool) {
+	sel, isSelect := cte.Stmt.(*Select)
+	if !isSelect || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil {
+		return nil, nil, false, false
+	}
+	union, isUnion := sel.Select.(*UnionClause)
+	if !isUnion || union.Type != UnionOp {
+		return nil, nil, false, false
+	}
+	return union.Left, union.Right, union.All, true
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package types
+
+import (
+	"github.com/lib/pq/oid"
+)
+
+// UserDefinedTypeOIDOffset is added to the descriptor ID of a user-defined
+// type to form its OID. It is above all the OIDs of the builtin Postgres
+// types, so the two can't collide.
+const UserDefinedTypeOIDOffset = 100000
+
+// FamEnum is the type family of a DEnum. It is equivalent to every ENUM
+// type. CANNOT be compared with ==.
+var FamEnum T = &TEnum{}
+
+// TEnum is the type of a user-defined ENUM. Two ENUM types are only
+// equivalent if they are the same user-defined type, which is identified by
+// the ID of its descriptor.
+type TEnum struct {
+	// ID is the ID of the descriptor of the type. It is zero for FamEnum.
+	ID uint32
+	// TypeName is the name of the type.
+	TypeName string
+	// LogicalReps are the labels of the members of the type, in the order of
+	// the members.
+	LogicalReps []string
+	// PhysicalReps are the encodings of the members of the type, parallel to
+	// LogicalReps. They sort in the order of the members.
+	PhysicalReps [][]byte
+	// IsMemberReadOnly is parallel to LogicalReps. The read-only members are
+	// being added to the type: they can be decoded, but not input.
+	IsMemberReadOnly []bool
+}
+
+// String implements the fmt.Stringer interface.
+func (t *TEnum) String() string {
+	if t.ID == 0 {
+		return "anyenum"
+	}
+	return t.TypeName
+}
+
+// Equivalent implements the T interface.
+func (t *TEnum) Equivalent(other T) bool {
+	if other == Any {
+		return true
+	}
+	u, ok := UnwrapType(other).(*TEnum)
+	if ok {
+		return t.ID == 0 || u.ID == 0 || t.ID == u.ID
+	}
+	return false
+}
+
+// FamilyEqual implements the T interface.
+func (*TEnum) FamilyEqual(other T) bool {
+	_, ok := UnwrapType(other).(*TEnum)
+	return ok
+}
+
+// Oid implements the T interface.
+func (t *TEnum) Oid() oid.Oid {
+	if t.ID == 0 {
+		return oid.T_anyenum
+	}
+	return oid.Oid(t.ID + UserDefinedTypeOIDOffset)
+}
+
+// SQLName implements the T interface.
+func (t *TEnum) SQLName() string {
+	return t.String()
+}
+
+// IsAmbiguous implements the T interface.
+func (t *TEnum) IsAmbiguous() bool {
+	return t.ID == 0
+}
+
+// LogicalRepOf returns the label of the member with the given physical
+// representation.
+func (t *TEnum) LogicalRepOf(physicalRep []byte) (string, bool) {
+	for i := range t.PhysicalReps {
+		if string(t.PhysicalReps[i]) == string(physicalRep) {
+			return t.LogicalReps[i], true
+		}
+	}
+	return "", false
+}
+
+// IsReadOnly returns whether the member at the given position is read-only.
+func (t *TEnum) IsReadOnly(idx int) bool {
+	return idx < len(t.IsMemberReadOnly) && t.IsMemberReadOnly[idx]
+}
+
+// PhysicalRepOf returns the physical representation of the member with the
+// given label.
+func (t *TEnum) PhysicalRepOf(logicalRep string) ([]byte, bool) {
+	for i := range t.LogicalReps {
+		if t.LogicalReps[i] == logicalRep {
+			return t.PhysicalReps[i], true
+		}
+	}
+	return nil, false
+}
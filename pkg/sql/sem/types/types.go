@@ -516,6 +516,9 @@ func IsStringType(t T) bool {
 // IsValidArrayElementType returns true if the T
 // can be used in TArray.
 func IsValidArrayElementType(t T) bool {
+	if _, ok := t.(*TEnum); ok {
+		return false
+	}
 	switch t {
 	case JSON:
 		return false
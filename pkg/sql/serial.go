@@ -96,6 +96,12 @@ func (p *planner) processSerialInColumnDef(
 	// The constraint on the name is that an object of this name must not exist already.
 	seqName := tree.NewUnqualifiedTableName(
 		tree.Name(tableName.Table() + "_" + string(d.Name) + "_seq"))
+	// The sequences of the tables of user-defined schemas are created in the
+	// schema of their table.
+	if isUserSchemaName(tableName.Schema()) {
+		seqName.SchemaName = tableName.SchemaName
+		seqName.ExplicitSchema = true
+	}
 
 	// The first step in the search is to prepare the seqName to fill in
 	// the catalog/schema parent. This is what ResolveUncachedDatabase does.
@@ -122,9 +128,13 @@ func (p *planner) processSerialInColumnDef(
 		}
 	}
 
+	seqRef := seqName.Table()
+	if isUserSchemaName(seqName.Schema()) {
+		seqRef = tree.NameString(seqName.Schema()) + "." + tree.NameString(seqName.Table())
+	}
 	defaultExpr := &tree.FuncExpr{
 		Func:  tree.WrapFunction("nextval"),
-		Exprs: tree.Exprs{tree.NewStrVal(seqName.Table())},
+		Exprs: tree.Exprs{tree.NewStrVal(seqRef)},
 	}
 
 	seqType := ""
@@ -26,29 +26,60 @@ const DefaultDatabaseName = "defaultdb"
 // PgCatalogName is the name of the pg_catalog system schema.
 const PgCatalogName = "pg_catalog"
 
+// PgTempSchemaName is the alias for the temporary schema of the session. It
+// is also the prefix of the names of the temporary schemas.
+const PgTempSchemaName = "pg_temp"
+
 // SearchPath represents a list of namespaces to search builtins in.
 // The names must be normalized (as per Name.Normalize) already.
 type SearchPath struct {
-	paths             []string
-	containsPgCatalog bool
+	paths                []string
+	containsPgCatalog    bool
+	containsPgTempSchema bool
+	// tempSchemaName is the name of the temporary schema of the session, if
+	// the session has created any temporary objects.
+	tempSchemaName string
 }
 
 // MakeSearchPath returns a new immutable SearchPath struct. The paths slice
 // must not be modified after hand-off to MakeSearchPath.
 func MakeSearchPath(paths []string) SearchPath {
 	containsPgCatalog := false
+	containsPgTempSchema := false
 	for _, e := range paths {
-		if e == PgCatalogName {
+		switch e {
+		case PgCatalogName:
 			containsPgCatalog = true
-			break
+		case PgTempSchemaName:
+			containsPgTempSchema = true
 		}
 	}
 	return SearchPath{
-		paths:             paths,
-		containsPgCatalog: containsPgCatalog,
+		paths:                paths,
+		containsPgCatalog:    containsPgCatalog,
+		containsPgTempSchema: containsPgTempSchema,
 	}
 }
 
+// WithTemporarySchemaName returns a copy of the search path which resolves
+// the temporary schema to the given name.
+func (s SearchPath) WithTemporarySchemaName(tempSchemaName string) SearchPath {
+	s.tempSchemaName = tempSchemaName
+	return s
+}
+
+// UpdatePaths returns a copy of the search path with the given paths, which
+// keeps the temporary schema of the search path.
+func (s SearchPath) UpdatePaths(paths []string) SearchPath {
+	return MakeSearchPath(paths).WithTemporarySchemaName(s.tempSchemaName)
+}
+
+// GetTemporarySchemaName returns the name of the temporary schema of the
+// session, or the empty string if the session has no temporary schema.
+func (s SearchPath) GetTemporarySchemaName() string {
+	return s.tempSchemaName
+}
+
 // Iter returns an iterator through the search path. We must include the
 // implicit pg_catalog at the beginning of the search path, unless it has been
 // explicitly set later by the user.
@@ -57,17 +88,22 @@ func MakeSearchPath(paths []string) SearchPath {
 // searched in the specified order. If pg_catalog is not in the path then it
 // will be searched before searching any of the path items."
 // - https://www.postgresql.org/docs/9.1/static/runtime-config-client.html
+//
+// Likewise, the temporary schema of the session is searched first, unless
+// pg_temp is mentioned in the path.
 func (s SearchPath) Iter() SearchPathIter {
-	if s.containsPgCatalog {
-		return SearchPathIter{paths: s.paths, i: 0}
+	return SearchPathIter{
+		paths:                s.paths,
+		implicitPgCatalog:    !s.containsPgCatalog,
+		implicitPgTempSchema: !s.containsPgTempSchema,
+		tempSchemaName:       s.tempSchemaName,
 	}
-	return SearchPathIter{paths: s.paths, i: -1}
 }
 
 // IterWithoutImplicitPGCatalog is the same as Iter, but does not include the
-// implicit pg_catalog.
+// implicit pg_catalog and temporary schema.
 func (s SearchPath) IterWithoutImplicitPGCatalog() SearchPathIter {
-	return SearchPathIter{paths: s.paths, i: 0}
+	return SearchPathIter{paths: s.paths, tempSchemaName: s.tempSchemaName}
 }
 
 // GetPathArray returns the underlying path array of this SearchPath. The
@@ -81,6 +117,9 @@ func (s SearchPath) Equals(other *SearchPath) bool {
 	if s.containsPgCatalog != other.containsPgCatalog {
 		return false
 	}
+	if s.tempSchemaName != other.tempSchemaName {
+		return false
+	}
 	if len(s.paths) != len(other.paths) {
 		return false
 	}
@@ -104,19 +143,37 @@ func (s SearchPath) String() string {
 // iterator, and then repeatedly call the Next method in order to iterate over
 // each search path.
 type SearchPathIter struct {
-	paths []string
-	i     int
+	paths                []string
+	implicitPgCatalog    bool
+	implicitPgTempSchema bool
+	tempSchemaName       string
+	i                    int
 }
 
 // Next returns the next search path, or false if there are no remaining paths.
+// The pg_temp alias is replaced by the name of the temporary schema of the
+// session, and skipped if the session has no temporary schema.
 func (iter *SearchPathIter) Next() (path string, ok bool) {
-	if iter.i == -1 {
-		iter.i++
+	if iter.implicitPgTempSchema {
+		iter.implicitPgTempSchema = false
+		if iter.tempSchemaName != "" {
+			return iter.tempSchemaName, true
+		}
+	}
+	if iter.implicitPgCatalog {
+		iter.implicitPgCatalog = false
 		return PgCatalogName, true
 	}
-	if iter.i < len(iter.paths) {
+	for iter.i < len(iter.paths) {
 		iter.i++
-		return iter.paths[iter.i-1], true
+		path = iter.paths[iter.i-1]
+		if path == PgTempSchemaName {
+			if iter.tempSchemaName == "" {
+				continue
+			}
+			path = iter.tempSchemaName
+		}
+		return path, true
 	}
 	return "", false
 }
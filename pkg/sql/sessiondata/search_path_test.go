@@ -61,6 +61,44 @@ func TestImpliedSearchPath(t *testing.T) {
 	}
 }
 
+func TestTemporarySchemaSearchPath(t *testing.T) {
+	testCases := []struct {
+		explicitSearchPath                         []string
+		expectedSearchPath                         []string
+		expectedSearchPathWithoutImplicitPgCatalog []string
+	}{
+		{[]string{}, []string{`pg_temp_1`, `pg_catalog`}, []string{}},
+		{[]string{`foobar`}, []string{`pg_temp_1`, `pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_temp`}, []string{`pg_catalog`, `foobar`, `pg_temp_1`}, []string{`foobar`, `pg_temp_1`}},
+		{[]string{`pg_temp`, `pg_catalog`}, []string{`pg_temp_1`, `pg_catalog`}, []string{`pg_temp_1`, `pg_catalog`}},
+	}
+
+	collect := func(iter SearchPathIter) []string {
+		res := make([]string, 0)
+		for p, ok := iter.Next(); ok; p, ok = iter.Next() {
+			res = append(res, p)
+		}
+		return res
+	}
+
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.explicitSearchPath, ","), func(t *testing.T) {
+			searchPath := MakeSearchPath(tc.explicitSearchPath).WithTemporarySchemaName(`pg_temp_1`)
+			assert.Equal(t, tc.expectedSearchPath, collect(searchPath.Iter()))
+			assert.Equal(t, tc.expectedSearchPathWithoutImplicitPgCatalog,
+				collect(searchPath.IterWithoutImplicitPGCatalog()))
+		})
+	}
+
+	// The pg_temp alias is skipped if the session has no temporary schema.
+	searchPath := MakeSearchPath([]string{`pg_temp`, `foobar`})
+	assert.Equal(t, []string{`pg_catalog`, `foobar`}, collect(searchPath.Iter()))
+
+	// Updating the paths keeps the temporary schema.
+	searchPath = searchPath.WithTemporarySchemaName(`pg_temp_1`).UpdatePaths([]string{`foobar`})
+	assert.Equal(t, `pg_temp_1`, searchPath.GetTemporarySchemaName())
+}
+
 func TestSearchPathEquals(t *testing.T) {
 	a1 := MakeSearchPath([]string{"x", "y", "z"})
 	a2 := MakeSearchPath([]string{"x", "y", "z"})
@@ -80,4 +118,7 @@ func TestSearchPathEquals(t *testing.T) {
 
 	d := MakeSearchPath([]string{"x"})
 	assert.False(t, a1.Equals(&d))
+
+	e := a1.WithTemporarySchemaName("pg_temp_1")
+	assert.False(t, a1.Equals(&e))
 }
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
@@ -36,6 +37,9 @@ type SessionData struct {
 	// DefaultReadOnly indicates the default read-only status of newly created
 	// transactions.
 	DefaultReadOnly bool
+	// DefaultIsolation indicates the isolation level of newly created explicit
+	// transactions. Implicit transactions are always SERIALIZABLE.
+	DefaultIsolation enginepb.IsolationType
 	// DistSQLMode indicates whether to run queries using the distributed
 	// execution engine.
 	DistSQLMode DistSQLExecMode
@@ -52,6 +56,10 @@ type SessionData struct {
 	// OptimizerMutations indicates whether to use the cost-based optimizer to
 	// plan UPDATE statements.
 	OptimizerMutations bool
+	// OptimizerFKs indicates whether the cost-based optimizer should plan
+	// foreign key checks and cascades, instead of leaving them to the row-by-row
+	// execution engine.
+	OptimizerFKs bool
 	// SerialNormalizationMode indicates how to handle the SERIAL pseudo-type.
 	SerialNormalizationMode SerialNormalizationMode
 	// SearchPath is a list of namespaces to search builtins in.
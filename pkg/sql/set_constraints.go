@@ -0,0 +1,125 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type setConstraintsNode struct {
+	n *tree.SetConstraints
+}
+
+// SetConstraints sets the checking mode of the deferrable constraints for the
+// current transaction.
+// Privileges: None.
+//   Notes: postgres only looks up the named constraints in the search path;
+//          we look them up in the current database.
+func (p *planner) SetConstraints(ctx context.Context, n *tree.SetConstraints) (planNode, error) {
+	if !n.All {
+		if err := p.checkDeferrableConstraints(ctx, n.Names); err != nil {
+			return nil, err
+		}
+	}
+	return &setConstraintsNode{n: n}, nil
+}
+
+// checkDeferrableConstraints returns an error if one of the given names isn't
+// the name of a deferrable constraint of a table in the current database.
+func (p *planner) checkDeferrableConstraints(ctx context.Context, names tree.NameList) error {
+	dbDesc, err := p.ResolveUncachedDatabaseByName(ctx, p.CurrentDatabase(), true /* required */)
+	if err != nil {
+		return err
+	}
+	// deferrable maps the constraint names to whether one of the constraints
+	// with that name is deferrable.
+	deferrable := make(map[string]bool)
+	add := func(name string, isDeferrable bool) {
+		deferrable[name] = deferrable[name] || isDeferrable
+	}
+	if err := forEachTableDesc(ctx, p, dbDesc, hideVirtual,
+		func(_ *sqlbase.DatabaseDescriptor, _ string, table *sqlbase.TableDescriptor) error {
+			for _, idx := range table.AllNonDropIndexes() {
+				if idx.ForeignKey.IsSet() {
+					add(idx.ForeignKey.Name, idx.ForeignKey.Deferrable)
+				}
+				if idx.Unique {
+					add(idx.Name, idx.Deferrable)
+				}
+			}
+			for _, check := range table.Checks {
+				add(check.Name, false)
+			}
+			return nil
+		}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		isDeferrable, ok := deferrable[string(name)]
+		if !ok {
+			return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"constraint %q does not exist", tree.ErrString(&name))
+		}
+		if !isDeferrable {
+			return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+				"constraint %q is not deferrable", tree.ErrString(&name))
+		}
+	}
+	return nil
+}
+
+// deferredChecks returns the DeferredChecks that the row writers should
+// use to queue the checks of the deferred foreign key and unique constraints,
+// or nil if the checks must be performed immediately. Implicit transactions
+// check all the constraints immediately, since they commit with the statement.
+func (p *planner) deferredChecks() *row.DeferredChecks {
+	if p.EvalContext().TxnImplicit {
+		return nil
+	}
+	return p.extendedEvalCtx.DeferredChecks
+}
+
+func (n *setConstraintsNode) startExec(params runParams) error {
+	d := params.p.deferredChecks()
+	if d == nil {
+		// Like in postgres, SET CONSTRAINTS has no effect outside of a
+		// transaction block.
+		return nil
+	}
+	if n.n.All {
+		d.SetAllDeferred(n.n.Deferred)
+	} else {
+		names := make([]string, len(n.n.Names))
+		for i := range n.n.Names {
+			names[i] = string(n.n.Names[i])
+		}
+		d.SetDeferred(names, n.n.Deferred)
+	}
+	if n.n.Deferred {
+		return nil
+	}
+	// The pending checks of the constraints which are now immediate are
+	// performed by the SET CONSTRAINTS statement.
+	return d.RunImmediate(params.ctx, params.p.txn)
+}
+
+func (*setConstraintsNode) Next(runParams) (bool, error) { return false, nil }
+func (*setConstraintsNode) Values() tree.Datums          { return tree.Datums{} }
+func (*setConstraintsNode) Close(context.Context)        {}
@@ -19,14 +19,21 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 )
 
 func (p *planner) SetSessionCharacteristics(n *tree.SetSessionCharacteristics) (planNode, error) {
 	// Note: We also support SET DEFAULT_TRANSACTION_ISOLATION TO ' .... ' above.
 	// Ensure both versions stay in sync.
 	switch n.Modes.Isolation {
-	case tree.SerializableIsolation, tree.UnspecifiedIsolation:
-		// Do nothing. All transactions execute with serializable isolation.
+	case tree.SerializableIsolation:
+		p.sessionDataMutator.SetDefaultIsolation(enginepb.SERIALIZABLE)
+	case tree.ReadCommittedIsolation:
+		if err := checkIsolationSupported(p.ExecCfg().Settings, enginepb.READ_COMMITTED); err != nil {
+			return nil, err
+		}
+		p.sessionDataMutator.SetDefaultIsolation(enginepb.READ_COMMITTED)
+	case tree.UnspecifiedIsolation:
 	default:
 		return nil, fmt.Errorf("unsupported default isolation level: %s", n.Modes.Isolation)
 	}
@@ -43,17 +43,26 @@ func (p *planner) ShowCreate(ctx context.Context, n *tree.ShowCreate) (planNode,
 // ShowCreateView returns a valid SQL representation of the CREATE
 // VIEW statement used to create the given view.
 func ShowCreateView(
-	ctx context.Context, tn *tree.Name, desc *sqlbase.TableDescriptor,
+	ctx context.Context, tn tree.NodeFormatter, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
-	f.WriteString("CREATE VIEW ")
+	f.WriteString("CREATE ")
+	if desc.MaterializedView() {
+		f.WriteString("MATERIALIZED ")
+	}
+	f.WriteString("VIEW ")
 	f.FormatNode(tn)
 	f.WriteString(" (")
+	// The hidden rowid column of a materialized view is not a column of its
+	// query.
+	comma := ""
 	for i := range desc.Columns {
-		if i > 0 {
-			f.WriteString(", ")
+		if desc.Columns[i].Hidden {
+			continue
 		}
+		f.WriteString(comma)
 		f.FormatNameP(&desc.Columns[i].Name)
+		comma = ", "
 	}
 	f.WriteString(") AS ")
 	f.WriteString(desc.ViewQuery)
@@ -87,8 +96,7 @@ func printForeignKeyConstraint(
 			return err
 		}
 		refNames = fkIdx.ColumnNames
-		fkTableName = tree.MakeTableName(tree.Name(fkDb.Name), tree.Name(fkTable.Name))
-		fkTableName.ExplicitSchema = fkDb.Name != dbPrefix
+		fkTableName = makeReferencedTableName(fkDb.Name, dbPrefix, fkTable, lCtx)
 	} else {
 		refNames = []string{"???"}
 		fkTableName = tree.MakeTableName(tree.Name(""), tree.Name(fmt.Sprintf("[%d as ref]", fk.Table)))
@@ -117,13 +125,20 @@ func printForeignKeyConstraint(
 		buf.WriteString(" ON UPDATE ")
 		buf.WriteString(fk.OnUpdate.String())
 	}
+	// We omit NOT DEFERRABLE because it is the default.
+	if fk.Deferrable {
+		buf.WriteString(" DEFERRABLE")
+		if fk.InitiallyDeferred {
+			buf.WriteString(" INITIALLY DEFERRED")
+		}
+	}
 	return nil
 }
 
 // ShowCreateSequence returns a valid SQL representation of the
 // CREATE SEQUENCE statement used to create the given sequence.
 func ShowCreateSequence(
-	ctx context.Context, tn *tree.Name, desc *sqlbase.TableDescriptor,
+	ctx context.Context, tn tree.NodeFormatter, desc *sqlbase.TableDescriptor,
 ) (string, error) {
 	f := tree.NewFmtCtxWithBuf(tree.FmtSimple)
 	f.WriteString("CREATE SEQUENCE ")
@@ -149,7 +164,7 @@ func ShowCreateSequence(
 // current database.
 func ShowCreateTable(
 	ctx context.Context,
-	tn *tree.Name,
+	tn tree.NodeFormatter,
 	dbPrefix string,
 	desc *sqlbase.TableDescriptor,
 	lCtx *internalLookupCtx,
@@ -194,7 +209,22 @@ func ShowCreateTable(
 		if idx.ID != desc.PrimaryIndex.ID {
 			// Showing the primary index is handled above.
 			f.WriteString(",\n\t")
-			f.WriteString(idx.SQLString(&sqlbase.AnonymousTable))
+			if idx.Deferrable {
+				// Deferrable unique indexes can only be created by a UNIQUE
+				// constraint.
+				f.WriteString("CONSTRAINT ")
+				f.FormatNameP(&idx.Name)
+				f.WriteString(" UNIQUE (")
+				idx.ColNamesFormat(f)
+				f.WriteByte(')')
+				if len(idx.StoreColumnNames) > 0 {
+					f.WriteString(" STORING (")
+					formatQuoteNames(f.Buffer, idx.StoreColumnNames...)
+					f.WriteByte(')')
+				}
+			} else {
+				f.WriteString(idx.SQLString(&sqlbase.AnonymousTable))
+			}
 			// Showing the INTERLEAVE and PARTITION BY for the primary index are
 			// handled last.
 			if err := showCreateInterleave(ctx, idx, f.Buffer, dbPrefix, lCtx); err != nil {
@@ -205,6 +235,17 @@ func ShowCreateTable(
 			); err != nil {
 				return "", err
 			}
+			if idx.IsPartial() {
+				f.WriteString(" WHERE ")
+				f.WriteString(idx.Predicate)
+			}
+			// We omit NOT DEFERRABLE because it is the default.
+			if idx.Deferrable {
+				f.WriteString(" DEFERRABLE")
+				if idx.InitiallyDeferred {
+					f.WriteString(" INITIALLY DEFERRED")
+				}
+			}
 		}
 	}
 
@@ -259,6 +300,25 @@ func formatQuoteNames(buf *bytes.Buffer, names ...string) {
 	}
 }
 
+// makeReferencedTableName returns the name of a table referenced by the
+// CREATE statement of another table. The name is prefixed by the name of the
+// database of the referenced table unless it is equal to the given dbPrefix,
+// and by the name of its schema if it belongs to a user-defined schema.
+func makeReferencedTableName(
+	dbName, dbPrefix string, desc *sqlbase.TableDescriptor, lCtx *internalLookupCtx,
+) tree.TableName {
+	scName := tree.PublicSchemaName
+	if desc.UnexposedParentSchemaID != 0 && !desc.Temporary {
+		if scDesc, ok := lCtx.scDescs[desc.UnexposedParentSchemaID]; ok {
+			scName = tree.Name(scDesc.Name)
+		}
+	}
+	tn := tree.MakeTableNameWithSchema(tree.Name(dbName), scName, tree.Name(desc.Name))
+	tn.ExplicitCatalog = dbName != dbPrefix
+	tn.ExplicitSchema = tn.ExplicitCatalog || scName != tree.PublicSchemaName
+	return tn
+}
+
 // showCreateInterleave returns an INTERLEAVE IN PARENT clause for the specified
 // index, if applicable.
 //
@@ -288,8 +348,7 @@ func showCreateInterleave(
 		if err != nil {
 			return err
 		}
-		parentName = tree.MakeTableName(tree.Name(parentDbDesc.Name), tree.Name(parentTable.Name))
-		parentName.ExplicitSchema = parentDbDesc.Name != dbPrefix
+		parentName = makeReferencedTableName(parentDbDesc.Name, dbPrefix, parentTable, lCtx)
 	} else {
 		parentName = tree.MakeTableName(tree.Name(""), tree.Name(fmt.Sprintf("[%d as parent]", parentTableID)))
 		parentName.ExplicitCatalog = false
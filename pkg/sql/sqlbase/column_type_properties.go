@@ -94,6 +94,13 @@ func DatumTypeToColumnType(ptyp types.T) (ColumnType, error) {
 		}
 		ctyp.TupleLabels = t.Labels
 		return ctyp, nil
+	case *types.TEnum:
+		if t.ID == 0 {
+			return ColumnType{}, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"type %q does not exist", t.TypeName)
+		}
+		ctyp.SemanticType = ColumnType_ENUM
+		ctyp.EnumMetadata = MakeEnumMetadata(t)
 	default:
 		semanticType, err := datumTypeToColumnSemanticType(ptyp)
 		if err != nil {
@@ -185,6 +192,7 @@ func PopulateTypeAttrs(base ColumnType, typ coltypes.T) (ColumnType, error) {
 	case *coltypes.TBool:
 	case *coltypes.TBytes:
 	case *coltypes.TDate:
+	case *coltypes.TEnum:
 	case *coltypes.TIPAddr:
 	case *coltypes.TInterval:
 	case *coltypes.TJSON:
@@ -297,6 +305,8 @@ func (c *ColumnType) SQLString() string {
 		}
 	case ColumnType_ARRAY:
 		return c.elementColumnType().SQLString() + "[]"
+	case ColumnType_ENUM:
+		return tree.NameString(c.EnumMetadata.TypeName)
 	}
 	if c.VisibleType != ColumnType_NONE {
 		return c.VisibleType.String()
@@ -365,6 +375,8 @@ func (c *ColumnType) InformationSchemaVisibleType() string {
 		return "record"
 	case ColumnType_ARRAY:
 		return "ARRAY"
+	case ColumnType_ENUM:
+		return "USER-DEFINED"
 	}
 
 	// The name of the remaining semantic type constants are suitable
@@ -548,6 +560,9 @@ func datumTypeToColumnSemanticType(ptyp types.T) (ColumnType_SemanticType, error
 		if ptyp.FamilyEqual(types.FamTuple) {
 			return ColumnType_TUPLE, nil
 		}
+		if ptyp.FamilyEqual(types.FamEnum) {
+			return ColumnType_ENUM, nil
+		}
 		if wrapper, ok := ptyp.(types.TOidWrapper); ok {
 			return datumTypeToColumnSemanticType(wrapper.T)
 		}
@@ -607,6 +622,11 @@ func columnSemanticTypeToDatumType(c *ColumnType, k ColumnType_SemanticType) typ
 		return types.IntVector
 	case ColumnType_OIDVECTOR:
 		return types.OidVector
+	case ColumnType_ENUM:
+		if c.EnumMetadata == nil {
+			return types.FamEnum
+		}
+		return c.EnumMetadata.DatumType()
 	}
 	return nil
 }
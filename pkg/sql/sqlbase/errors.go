@@ -111,6 +111,11 @@ func NewUndefinedDatabaseError(name string) error {
 		pgerror.CodeInvalidCatalogNameError, "database %q does not exist", name)
 }
 
+// NewUndefinedSchemaError creates an error that represents a missing schema.
+func NewUndefinedSchemaError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError, "schema %q does not exist", name)
+}
+
 // NewInvalidWildcardError creates an error that represents the result of expanding
 // a table wildcard over an invalid database or schema prefix.
 func NewInvalidWildcardError(name string) error {
@@ -140,6 +145,11 @@ func NewRelationAlreadyExistsError(name string) error {
 	return pgerror.NewErrorf(pgerror.CodeDuplicateRelationError, "relation %q already exists", name)
 }
 
+// NewSchemaAlreadyExistsError creates an error for a preexisting schema.
+func NewSchemaAlreadyExistsError(name string) error {
+	return pgerror.NewErrorf(pgerror.CodeDuplicateSchemaError, "schema %q already exists", name)
+}
+
 // NewWrongObjectTypeError creates a wrong object type error.
 func NewWrongObjectTypeError(name *tree.TableName, desiredObjType string) error {
 	return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError, "%q is not a %s",
@@ -221,7 +221,7 @@ func MakeSpanFromEncDatums(
 	}
 
 	var endKey roachpb.Key
-	if complete && index.Unique {
+	if complete && index.HasUniqueKeys() {
 		// If all values in the input index were specified and the input index is
 		// unique, indicating that it might have child interleaves, append an
 		// interleave marker instead of PrefixEnding the key, to avoid including
@@ -606,7 +606,7 @@ func ExtractIndexKey(
 		dirs[i] = IndexDescriptor_ASC
 	}
 	extraKey := key
-	if index.Unique {
+	if index.HasUniqueKeys() {
 		extraKey, err = entry.Value.GetBytes()
 		if err != nil {
 			return nil, err
@@ -748,7 +748,7 @@ func EncodeSecondaryIndex(
 	for i, key := range secondaryKeys {
 		entry := IndexEntry{Key: key}
 
-		if !secondaryIndex.Unique || containsNull {
+		if !secondaryIndex.HasUniqueKeys() || containsNull {
 			// If the index is not unique or it contains a NULL value, append
 			// extraKey to the key in order to make it unique.
 			entry.Key = append(entry.Key, extraKey...)
@@ -759,7 +759,7 @@ func EncodeSecondaryIndex(
 		entry.Key = keys.MakeFamilyKey(entry.Key, 0)
 
 		var entryValue []byte
-		if secondaryIndex.Unique {
+		if secondaryIndex.HasUniqueKeys() {
 			// Note that a unique secondary index that contains a NULL column value
 			// will have extraKey appended to the key and stored in the value. We
 			// require extraKey to be appended to the key in order to make the key
@@ -1015,7 +1015,7 @@ func maxKeyTokens(index *IndexDescriptor, containsNull bool) int {
 	// value have additional columns in the key that may appear in a span
 	// (e.g. primary key columns not part of the index).
 	// See EncodeSecondaryIndex.
-	if !index.Unique || containsNull {
+	if !index.HasUniqueKeys() || containsNull {
 		nKeyCols += len(index.ExtraColumnIDs)
 	}
 
@@ -0,0 +1,205 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/pkg/errors"
+)
+
+// PartialIndexPredicates evaluates the predicates of the partial indexes
+// among a list of indexes against rows of their table. A row only has an
+// entry in a partial index if the predicate of the index evaluates to true
+// for it; NULL counts as false.
+//
+// The zero value is usable and treats every index as a non-partial index.
+type PartialIndexPredicates struct {
+	// exprs is parallel to the list of indexes the struct was created for. It
+	// contains the typed predicate of every partial index and nil for the other
+	// indexes. exprs is nil if there are no partial indexes.
+	exprs []tree.TypedExpr
+
+	// colIDs is the sorted set of columns referenced by any of the predicates.
+	colIDs []ColumnID
+
+	ivars   RowIndexedVarContainer
+	evalCtx *tree.EvalContext
+}
+
+// MakePartialIndexPredicates parses and type checks the predicates of the
+// partial indexes among the given indexes of the table.
+func MakePartialIndexPredicates(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor, evalCtx *tree.EvalContext,
+) (PartialIndexPredicates, error) {
+	var p PartialIndexPredicates
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		if p.exprs == nil {
+			p.exprs = make([]tree.TypedExpr, len(indexes))
+		}
+		typedExpr, err := makeIndexPredicateExpr(tableDesc, &indexes[i], evalCtx)
+		if err != nil {
+			return PartialIndexPredicates{}, err
+		}
+		p.exprs[i] = typedExpr
+	}
+	if p.exprs == nil {
+		return p, nil
+	}
+
+	colIDs := make(map[ColumnID]struct{})
+	for _, expr := range p.exprs {
+		if expr == nil {
+			continue
+		}
+		if _, err := tree.SimpleVisit(expr, func(expr tree.Expr) (error, bool, tree.Expr) {
+			if ivar, ok := expr.(*tree.IndexedVar); ok {
+				colIDs[tableDesc.Columns[ivar.Idx].ID] = struct{}{}
+			}
+			return nil, true, expr
+		}); err != nil {
+			return PartialIndexPredicates{}, err
+		}
+	}
+	p.colIDs = make([]ColumnID, 0, len(colIDs))
+	for colID := range colIDs {
+		p.colIDs = append(p.colIDs, colID)
+	}
+	sort.Sort(ColumnIDs(p.colIDs))
+
+	p.ivars.Cols = tableDesc.Columns
+	p.evalCtx = evalCtx
+	return p, nil
+}
+
+// makeIndexPredicateExpr returns the typed predicate of a partial index. The
+// IndexedVars in the result refer to the ordinals of the table's columns.
+func makeIndexPredicateExpr(
+	tableDesc *TableDescriptor, index *IndexDescriptor, evalCtx *tree.EvalContext,
+) (tree.TypedExpr, error) {
+	expr, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse predicate of index %q", index.Name)
+	}
+	return MakeTablePredicateExpr(tableDesc, expr, evalCtx)
+}
+
+// MakeTablePredicateExpr resolves and type checks a boolean expression that
+// refers to the columns of the table by name, such as the predicate of a
+// partial index. The IndexedVars in the result refer to the ordinals of the
+// table's columns.
+func MakeTablePredicateExpr(
+	tableDesc *TableDescriptor, expr tree.Expr, evalCtx *tree.EvalContext,
+) (tree.TypedExpr, error) {
+	iv := &descContainer{tableDesc.Columns}
+	ivarHelper := tree.MakeIndexedVarHelper(iv, len(tableDesc.Columns))
+	sources := MakeMultiSourceInfo(NewSourceInfoForSingleTable(
+		AnonymousTable, ResultColumnsFromColDescs(tableDesc.Columns),
+	))
+	searchPath := DefaultSearchPath
+	if evalCtx.SessionData != nil {
+		searchPath = evalCtx.SessionData.SearchPath
+	}
+	expr, _, _, err := ResolveNames(expr, sources, ivarHelper, searchPath)
+	if err != nil {
+		return nil, err
+	}
+
+	semaCtx := tree.MakeSemaContext(false)
+	semaCtx.IVarContainer = iv
+	return tree.TypeCheck(expr, &semaCtx, types.Bool)
+}
+
+// HasPredicates returns true if at least one of the indexes is partial.
+func (p *PartialIndexPredicates) HasPredicates() bool {
+	return p.exprs != nil
+}
+
+// ColumnIDs returns the sorted IDs of the columns referenced by the
+// predicates. The values of these columns must be provided to Satisfies.
+func (p *PartialIndexPredicates) ColumnIDs() []ColumnID {
+	return p.colIDs
+}
+
+// Satisfies returns whether the given row should have entries in the i-th
+// index. It always returns true for an index that is not partial.
+func (p *PartialIndexPredicates) Satisfies(
+	i int, colMap map[ColumnID]int, values tree.Datums,
+) (bool, error) {
+	if p.exprs == nil || p.exprs[i] == nil {
+		return true, nil
+	}
+	p.ivars.Mapping = colMap
+	p.ivars.CurSourceRow = values
+	p.evalCtx.PushIVarContainer(&p.ivars)
+	defer p.evalCtx.PopIVarContainer()
+	d, err := p.exprs[i].Eval(p.evalCtx)
+	if err != nil {
+		return false, err
+	}
+	if d == tree.DNull {
+		return false, nil
+	}
+	res, err := tree.GetBool(d)
+	return bool(res), err
+}
+
+// PredicateColumnIDs returns the IDs of the columns referenced by the
+// predicate of a partial index.
+func (desc *IndexDescriptor) PredicateColumnIDs(tableDesc *TableDescriptor) ([]ColumnID, error) {
+	if !desc.IsPartial() {
+		return nil, nil
+	}
+	parsed, err := parser.ParseExpr(desc.Predicate)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse predicate of index %q", desc.Name)
+	}
+
+	colIDsUsed := make(map[ColumnID]struct{})
+	visitFn := func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if vBase, ok := expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			if c, ok := v.(*tree.ColumnItem); ok {
+				col, err := tableDesc.FindActiveColumnByName(string(c.ColumnName))
+				if err != nil {
+					return errors.Errorf("column %q not found for predicate of index %q",
+						c.ColumnName, desc.Name), false, nil
+				}
+				colIDsUsed[col.ID] = struct{}{}
+			}
+			return nil, false, v
+		}
+		return nil, true, expr
+	}
+	if _, err := tree.SimpleVisit(parsed, visitFn); err != nil {
+		return nil, err
+	}
+
+	colIDs := make([]ColumnID, 0, len(colIDsUsed))
+	for colID := range colIDsUsed {
+		colIDs = append(colIDs, colID)
+	}
+	sort.Sort(ColumnIDs(colIDs))
+	return colIDs, nil
+}
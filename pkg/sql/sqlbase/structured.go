@@ -392,7 +392,7 @@ func (desc *IndexDescriptor) ContainsColumnID(colID ColumnID) bool {
 // stored (old STORING encoding)) column IDs for non-unique indexes. It also
 // returns the direction with which each column was encoded.
 func (desc *IndexDescriptor) FullColumnIDs() ([]ColumnID, []IndexDescriptor_Direction) {
-	if desc.Unique {
+	if desc.HasUniqueKeys() {
 		return desc.ColumnIDs, desc.ColumnDirections
 	}
 	// Non-unique indexes have some of the primary-key columns appended to
@@ -463,6 +463,25 @@ func (desc *IndexDescriptor) SQLString(tableName *tree.TableName) string {
 	return f.CloseAndGetString()
 }
 
+// IsPartial returns whether the index is a partial index, i.e. whether it only
+// contains entries for the rows that satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
+// HasUniqueKeys returns whether the keys of the index are unique: unless one
+// of the values of the index columns is NULL, the keys only contain these
+// values, and the values of the extra columns are stored in the KV value.
+//
+// An index which enforces a deferrable unique constraint is unique, but its
+// keys aren't: they are encoded like the keys of a non-unique index, since the
+// index can contain duplicates until the constraint is checked. For the same
+// reason, such an index doesn't guarantee uniqueness to the queries reading
+// it.
+func (desc *IndexDescriptor) HasUniqueKeys() bool {
+	return desc.Unique && !desc.Deferrable
+}
+
 // IsInterleaved returns whether the index is interleaved or not.
 func (desc *IndexDescriptor) IsInterleaved() bool {
 	return len(desc.Interleave.Ancestors) > 0 || len(desc.InterleavedBy) > 0
@@ -501,6 +520,13 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// MaterializedView returns true if the TableDescriptor describes a
+// materialized view, that is, a view whose results are stored like the rows
+// of a table.
+func (desc *TableDescriptor) MaterializedView() bool {
+	return desc.IsView() && desc.IsMaterializedView
+}
+
 // IsSequence returns true if the TableDescriptor actually describes a
 // Sequence resource rather than a Table.
 func (desc *TableDescriptor) IsSequence() bool {
@@ -518,10 +544,11 @@ func (desc *TableDescriptor) IsVirtualTable() bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
-// Sequences count as physical tables because their values are stored in
-// the KV layer.
+// Sequences and materialized views count as physical tables because their
+// values are stored in the KV layer.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsSequence() || (desc.IsTable() && !desc.IsVirtualTable())
+	return desc.IsSequence() || desc.MaterializedView() ||
+		(desc.IsTable() && !desc.IsVirtualTable())
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -1188,6 +1215,11 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 		return fmt.Errorf("invalid parent ID %d", desc.ParentID)
 	}
 
+	if desc.Temporary && desc.UnexposedParentSchemaID == 0 {
+		return fmt.Errorf("invalid parent schema ID %d for temporary table %q",
+			desc.UnexposedParentSchemaID, desc.Name)
+	}
+
 	// We maintain forward compatibility, so if you see this error message with a
 	// version older that what this client supports, then there's a
 	// MaybeFillInDescriptor missing from some codepath.
@@ -1269,6 +1301,10 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 				idx := desc.Index
 				return errors.Errorf("mutation in state %s, direction %s, index %s, id %v", m.State, m.Direction, idx.Name, idx.ID)
 			}
+		case *DescriptorMutation_MaterializedViewRefresh:
+			if unSetEnums {
+				return errors.Errorf("mutation in state %s, direction %s, materialized view refresh", m.State, m.Direction)
+			}
 		default:
 			return errors.Errorf("mutation in state %s, direction %s, and no column/index descriptor", m.State, m.Direction)
 		}
@@ -1421,6 +1457,13 @@ func (desc *TableDescriptor) validateTableIndexes(
 			return fmt.Errorf("index %q must contain at least 1 column", index.Name)
 		}
 
+		if index.Deferrable && (!index.Unique || index.ID == desc.PrimaryIndex.ID) {
+			return fmt.Errorf("index %q cannot be deferrable", index.Name)
+		}
+		if index.InitiallyDeferred && !index.Deferrable {
+			return fmt.Errorf("index %q is initially deferred but not deferrable", index.Name)
+		}
+
 		validateIndexDup := make(map[ColumnID]struct{})
 		for i, name := range index.ColumnNames {
 			colID, ok := columnNames[name]
@@ -1436,6 +1479,15 @@ func (desc *TableDescriptor) validateTableIndexes(
 			}
 			validateIndexDup[colID] = struct{}{}
 		}
+
+		if index.IsPartial() {
+			if index.ID == desc.PrimaryIndex.ID {
+				return fmt.Errorf("primary index %q cannot be partial", index.Name)
+			}
+			if index.Type == IndexDescriptor_INVERTED {
+				return fmt.Errorf("inverted index %q cannot be partial", index.Name)
+			}
+		}
 	}
 
 	for _, colID := range desc.PrimaryIndex.ColumnIDs {
@@ -2157,6 +2209,12 @@ func (desc *MutableTableDescriptor) MakeMutationComplete(m DescriptorMutation) e
 			if err := desc.AddIndex(*t.Index, false); err != nil {
 				return err
 			}
+
+		case *DescriptorMutation_MaterializedViewRefresh:
+			// The new indexes are populated and replace the old ones, whose
+			// data is gc-ed.
+			desc.PrimaryIndex = t.MaterializedViewRefresh.NewPrimaryIndex
+			desc.Indexes = t.MaterializedViewRefresh.NewIndexes
 		}
 
 	case DescriptorMutation_DROP:
@@ -2165,7 +2223,8 @@ func (desc *MutableTableDescriptor) MakeMutationComplete(m DescriptorMutation) e
 			desc.RemoveColumnFromFamily(t.Column.ID)
 		}
 		// Nothing else to be done. The column/index was already removed from the
-		// set of column/index descriptors at mutation creation time.
+		// set of column/index descriptors at mutation creation time. A rolled
+		// back materialized view refresh leaves the view unchanged.
 	}
 	return nil
 }
@@ -2199,6 +2258,29 @@ func (desc *MutableTableDescriptor) AddIndexMutation(
 	return nil
 }
 
+// AddMaterializedViewRefreshMutation adds a mutation to desc.Mutations which
+// populates copies of the indexes of the materialized view and swaps them in
+// for the current indexes. The copies are allocated new index IDs.
+func (desc *MutableTableDescriptor) AddMaterializedViewRefreshMutation() error {
+	if !desc.MaterializedView() {
+		return errors.Errorf("%q is not a materialized view", desc.Name)
+	}
+	refresh := MaterializedViewRefresh{NewPrimaryIndex: desc.PrimaryIndex}
+	refresh.NewPrimaryIndex.ID = desc.NextIndexID
+	desc.NextIndexID++
+	for _, idx := range desc.Indexes {
+		idx.ID = desc.NextIndexID
+		desc.NextIndexID++
+		refresh.NewIndexes = append(refresh.NewIndexes, idx)
+	}
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_MaterializedViewRefresh{MaterializedViewRefresh: &refresh},
+		Direction:   DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+	return nil
+}
+
 func (desc *MutableTableDescriptor) addMutation(m DescriptorMutation) {
 	switch m.Direction {
 	case DescriptorMutation_ADD:
@@ -2352,6 +2434,10 @@ func (desc *Descriptor) GetID() ID {
 		return t.Table.ID
 	case *Descriptor_Database:
 		return t.Database.ID
+	case *Descriptor_Type:
+		return t.Type.ID
+	case *Descriptor_Schema:
+		return t.Schema.ID
 	default:
 		return 0
 	}
@@ -2364,6 +2450,10 @@ func (desc *Descriptor) GetName() string {
 		return t.Table.Name
 	case *Descriptor_Database:
 		return t.Database.Name
+	case *Descriptor_Type:
+		return t.Type.Name
+	case *Descriptor_Schema:
+		return t.Schema.Name
 	default:
 		return ""
 	}
@@ -2425,9 +2515,20 @@ func (desc TableDescriptor) GetDescMetadataKey() roachpb.Key {
 	return MakeDescMetadataKey(desc.ID)
 }
 
+// GetNamespaceParentID returns the ID under which the name of the table is
+// stored in system.namespace: the ID of its schema for tables in temporary or
+// user-defined schemas, and the ID of the parent database for tables in the
+// public schema.
+func (desc TableDescriptor) GetNamespaceParentID() ID {
+	if desc.UnexposedParentSchemaID != 0 {
+		return desc.UnexposedParentSchemaID
+	}
+	return desc.ParentID
+}
+
 // GetNameMetadataKey returns the namespace key for the table.
 func (desc TableDescriptor) GetNameMetadataKey() roachpb.Key {
-	return MakeNameMetadataKey(desc.ParentID, desc.Name)
+	return MakeNameMetadataKey(desc.GetNamespaceParentID(), desc.Name)
 }
 
 // SQLString returns the SQL statement describing the column.
@@ -2553,6 +2654,17 @@ var ForeignKeyReferenceActionValue = [...]ForeignKeyReference_Action{
 	tree.Cascade:    ForeignKeyReference_CASCADE,
 }
 
+// ForeignKeyReferenceActionType allows the conversion from a
+// ForeignKeyReference_Action to a tree.ReferenceAction. This should match
+// ForeignKeyReferenceActionValue.
+var ForeignKeyReferenceActionType = [...]tree.ReferenceAction{
+	ForeignKeyReference_NO_ACTION:   tree.NoAction,
+	ForeignKeyReference_RESTRICT:    tree.Restrict,
+	ForeignKeyReference_SET_DEFAULT: tree.SetDefault,
+	ForeignKeyReference_SET_NULL:    tree.SetNull,
+	ForeignKeyReference_CASCADE:     tree.Cascade,
+}
+
 // String implements the fmt.Stringer interface.
 func (x ForeignKeyReference_Action) String() string {
 	switch x {
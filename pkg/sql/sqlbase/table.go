@@ -79,7 +79,8 @@ func SanitizeVarFreeExpr(
 // expression.
 //
 // semaCtx and evalCtx can be nil if no default expression is used for the
-// column.
+// column. semaCtx is also used to resolve the name of a user-defined column
+// type.
 //
 // The DEFAULT expression is returned in TypedExpr form for analysis (e.g. recording
 // sequence dependencies).
@@ -110,6 +111,15 @@ func MakeColumnDefDescs(
 		Nullable: d.Nullable.Nullability != tree.NotNull && !d.PrimaryKey,
 	}
 
+	// Resolve the name of a user-defined type.
+	if e, ok := d.Type.(*coltypes.TEnum); ok && e.Typ == nil {
+		resolved, err := semaCtx.ResolveUserDefinedType(e)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		d.Type = resolved.(*coltypes.TEnum)
+	}
+
 	// Set Type.SemanticType and Type.Locale.
 	colDatumType := coltypes.CastTargetToDatumType(d.Type)
 	colTyp, err := DatumTypeToColumnType(colDatumType)
@@ -213,6 +213,16 @@ func RandDatumWithNullChance(rng *rand.Rand, typ ColumnType, nullChance int) tre
 		return tree.DNull
 	case ColumnType_OIDVECTOR:
 		return tree.DNull
+	case ColumnType_ENUM:
+		t := typ.EnumMetadata.DatumType()
+		if len(t.PhysicalReps) == 0 {
+			return tree.DNull
+		}
+		d, err := tree.NewDEnumFromPhysicalRep(t, t.PhysicalReps[rng.Intn(len(t.PhysicalReps))])
+		if err != nil {
+			panic(err)
+		}
+		return d
 	default:
 		panic(fmt.Sprintf("invalid type %s", typ.String()))
 	}
@@ -228,6 +238,11 @@ var (
 
 func init() {
 	for k := range ColumnType_SemanticType_name {
+		// ENUM types are only defined by their metadata, which the random
+		// column types don't have.
+		if ColumnType_SemanticType(k) == ColumnType_ENUM {
+			continue
+		}
 		columnSemanticTypes = append(columnSemanticTypes, ColumnType_SemanticType(k))
 	}
 	for _, t := range types.AnyNonArray {
@@ -0,0 +1,243 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
+
+// NewEnumTypeDescriptor returns the descriptor of a new ENUM type with the
+// given labels.
+func NewEnumTypeDescriptor(
+	id ID, parentID ID, name string, labels []string, privileges *PrivilegeDescriptor,
+) (*TypeDescriptor, error) {
+	desc := &TypeDescriptor{
+		Name:       name,
+		ID:         id,
+		ParentID:   parentID,
+		Privileges: privileges,
+	}
+	reps := encoding.GenerateEnumPhysicalReps(len(labels))
+	for i, label := range labels {
+		if desc.EnumMemberIndex(label) != -1 {
+			return nil, pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+				"enum label %q used more than once", label)
+		}
+		desc.EnumMembers = append(desc.EnumMembers, EnumMember{
+			LogicalRepresentation:  label,
+			PhysicalRepresentation: reps[i],
+		})
+	}
+	return desc, nil
+}
+
+// SetID implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetID(id ID) {
+	desc.ID = id
+}
+
+// TypeName returns the plain type of this descriptor.
+func (desc *TypeDescriptor) TypeName() string {
+	return "type"
+}
+
+// SetName implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetName(name string) {
+	desc.Name = name
+}
+
+// GetAuditMode is part of the DescriptorProto interface.
+// This is a stub, types are not audited.
+func (desc *TypeDescriptor) GetAuditMode() TableDescriptor_AuditMode {
+	return TableDescriptor_DISABLED
+}
+
+// Validate validates that the type descriptor is well formed. Checks include
+// verifying that the labels of the members are unique and that their
+// physical representations sort in the order of the members.
+func (desc *TypeDescriptor) Validate() error {
+	if err := validateName(desc.Name, "type"); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid type ID %d", desc.ID)
+	}
+	if desc.ParentID == 0 {
+		return fmt.Errorf("invalid parent ID %d for type %q", desc.ParentID, desc.Name)
+	}
+	labels := make(map[string]struct{}, len(desc.EnumMembers))
+	for i, m := range desc.EnumMembers {
+		if _, ok := labels[m.LogicalRepresentation]; ok {
+			return fmt.Errorf("duplicate label %q in type %q", m.LogicalRepresentation, desc.Name)
+		}
+		labels[m.LogicalRepresentation] = struct{}{}
+		if len(m.PhysicalRepresentation) == 0 {
+			return fmt.Errorf("member %q of type %q has no physical representation",
+				m.LogicalRepresentation, desc.Name)
+		}
+		if i > 0 && bytes.Compare(
+			desc.EnumMembers[i-1].PhysicalRepresentation, m.PhysicalRepresentation) >= 0 {
+			return fmt.Errorf("members %q and %q of type %q are out of order",
+				desc.EnumMembers[i-1].LogicalRepresentation, m.LogicalRepresentation, desc.Name)
+		}
+	}
+
+	// Validate the privilege descriptor.
+	return desc.Privileges.Validate(desc.GetID())
+}
+
+// EnumMemberIndex returns the position of the member with the given label,
+// or -1 if there is no such member.
+func (desc *TypeDescriptor) EnumMemberIndex(label string) int {
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].LogicalRepresentation == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddEnumMember inserts a member with the given label at the given position
+// among the members of the type. The physical representations of the
+// existing members are left untouched, so existing data remains valid.
+//
+// The member is added as read-only: nodes using an older version of the
+// tables of the type can't decode it, so it can only be input once every node
+// has caught up; see PromoteEnumMembers.
+func (desc *TypeDescriptor) AddEnumMember(label string, pos int) error {
+	var prev, next []byte
+	if pos > 0 {
+		prev = desc.EnumMembers[pos-1].PhysicalRepresentation
+	}
+	if pos < len(desc.EnumMembers) {
+		next = desc.EnumMembers[pos].PhysicalRepresentation
+	}
+	rep, err := encoding.EnumPhysicalRepBetween(prev, next)
+	if err != nil {
+		return err
+	}
+	desc.EnumMembers = append(desc.EnumMembers, EnumMember{})
+	copy(desc.EnumMembers[pos+1:], desc.EnumMembers[pos:])
+	desc.EnumMembers[pos] = EnumMember{
+		LogicalRepresentation:  label,
+		PhysicalRepresentation: rep,
+		Capability:             EnumMember_READ_ONLY,
+	}
+	return nil
+}
+
+// HasReadOnlyEnumMembers returns whether some members of the type are being
+// added.
+func (desc *TypeDescriptor) HasReadOnlyEnumMembers() bool {
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].Capability == EnumMember_READ_ONLY {
+			return true
+		}
+	}
+	return false
+}
+
+// PromoteEnumMembers makes the read-only members of the type public, and
+// returns whether there were any.
+func (desc *TypeDescriptor) PromoteEnumMembers() bool {
+	promoted := false
+	for i := range desc.EnumMembers {
+		if desc.EnumMembers[i].Capability == EnumMember_READ_ONLY {
+			desc.EnumMembers[i].Capability = EnumMember_ALL
+			promoted = true
+		}
+	}
+	return promoted
+}
+
+// AddReferencingDescriptorID records that the table with the given ID has
+// columns of the type. It returns false if the table was already recorded.
+func (desc *TypeDescriptor) AddReferencingDescriptorID(id ID) bool {
+	for _, refID := range desc.ReferencingDescriptorIDs {
+		if refID == id {
+			return false
+		}
+	}
+	desc.ReferencingDescriptorIDs = append(desc.ReferencingDescriptorIDs, id)
+	return true
+}
+
+// RemoveReferencingDescriptorID removes the table with the given ID from the
+// tables which have columns of the type.
+func (desc *TypeDescriptor) RemoveReferencingDescriptorID(id ID) {
+	for i, refID := range desc.ReferencingDescriptorIDs {
+		if refID == id {
+			desc.ReferencingDescriptorIDs = append(
+				desc.ReferencingDescriptorIDs[:i], desc.ReferencingDescriptorIDs[i+1:]...)
+			return
+		}
+	}
+}
+
+// EnumMetadata returns the metadata stored in the column types of the
+// columns of this type.
+func (desc *TypeDescriptor) EnumMetadata() *EnumMetadata {
+	return &EnumMetadata{
+		TypeID:   desc.ID,
+		TypeName: desc.Name,
+		Members:  append([]EnumMember(nil), desc.EnumMembers...),
+	}
+}
+
+// DatumType returns the datum type of the type.
+func (desc *TypeDescriptor) DatumType() *types.TEnum {
+	return desc.EnumMetadata().DatumType()
+}
+
+// DatumType returns the datum type described by the metadata.
+func (m *EnumMetadata) DatumType() *types.TEnum {
+	t := &types.TEnum{
+		ID:               uint32(m.TypeID),
+		TypeName:         m.TypeName,
+		LogicalReps:      make([]string, len(m.Members)),
+		PhysicalReps:     make([][]byte, len(m.Members)),
+		IsMemberReadOnly: make([]bool, len(m.Members)),
+	}
+	for i := range m.Members {
+		t.LogicalReps[i] = m.Members[i].LogicalRepresentation
+		t.PhysicalReps[i] = m.Members[i].PhysicalRepresentation
+		t.IsMemberReadOnly[i] = m.Members[i].Capability == EnumMember_READ_ONLY
+	}
+	return t
+}
+
+// MakeEnumMetadata returns the metadata describing the given datum type.
+func MakeEnumMetadata(t *types.TEnum) *EnumMetadata {
+	m := &EnumMetadata{
+		TypeID:   ID(t.ID),
+		TypeName: t.TypeName,
+		Members:  make([]EnumMember, len(t.LogicalReps)),
+	}
+	for i := range t.LogicalReps {
+		m.Members[i] = EnumMember{
+			LogicalRepresentation:  t.LogicalReps[i],
+			PhysicalRepresentation: t.PhysicalReps[i],
+		}
+		if i < len(t.IsMemberReadOnly) && t.IsMemberReadOnly[i] {
+			m.Members[i].Capability = EnumMember_READ_ONLY
+		}
+	}
+	return m
+}
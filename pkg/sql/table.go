@@ -93,7 +93,7 @@ func GetKeysForTableDescriptor(
 	tableDesc *sqlbase.TableDescriptor,
 ) (zoneKey roachpb.Key, nameKey roachpb.Key, descKey roachpb.Key) {
 	zoneKey = config.MakeZoneKey(uint32(tableDesc.ID))
-	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.ParentID, tableDesc.GetName())
+	nameKey = sqlbase.MakeNameMetadataKey(tableDesc.GetNamespaceParentID(), tableDesc.GetName())
 	descKey = sqlbase.MakeDescMetadataKey(tableDesc.ID)
 	return
 }
@@ -200,13 +200,6 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		log.Infof(ctx, "reading mutable descriptor on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
-		if flags.required {
-			return nil, nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
-		}
-		return nil, nil, nil
-	}
-
 	refuseFurtherLookup, dbID, err := tc.getUncommittedDatabaseID(tn.Catalog(), flags.required)
 	if refuseFurtherLookup || err != nil {
 		return nil, nil, err
@@ -223,7 +216,12 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		}
 	}
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	scID, err := getSchemaIDForLookup(ctx, txn, dbID, tn, flags.required)
+	if err != nil || scID == 0 {
+		return nil, nil, err
+	}
+
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(scID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, nil, err
 	} else if mut := table.MutableTableDescriptor; mut != nil {
 		log.VEventf(ctx, 2, "found uncommitted table %d", mut.ID)
@@ -256,13 +254,6 @@ func (tc *TableCollection) getTableVersion(
 		log.Infof(ctx, "planner acquiring lease on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
-		if flags.required {
-			return nil, nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
-		}
-		return nil, nil, nil
-	}
-
 	refuseFurtherLookup, dbID, err := tc.getUncommittedDatabaseID(tn.Catalog(), flags.required)
 	if refuseFurtherLookup || err != nil {
 		return nil, nil, err
@@ -279,6 +270,11 @@ func (tc *TableCollection) getTableVersion(
 		}
 	}
 
+	scID, err := getSchemaIDForLookup(ctx, txn, dbID, tn, flags.required)
+	if err != nil || scID == 0 {
+		return nil, nil, err
+	}
+
 	// TODO(vivek): Ideally we'd avoid caching for only the
 	// system.descriptor and system.lease tables, because they are
 	// used for acquiring leases, creating a chicken&egg problem.
@@ -286,10 +282,14 @@ func (tc *TableCollection) getTableVersion(
 	// disabling caching of system.eventlog, system.rangelog, and
 	// system.users. For now we're sticking to disabling caching of
 	// all system descriptors except the role-members-table.
+	//
+	// Temporary tables are only used by the session that created them, so
+	// they are never leased.
 	avoidCache := flags.avoidCached || testDisableTableLeases ||
-		(tn.Catalog() == sqlbase.SystemDB.Name && tn.TableName.String() != sqlbase.RoleMembersTable.Name)
+		(tn.Catalog() == sqlbase.SystemDB.Name && tn.TableName.String() != sqlbase.RoleMembersTable.Name) ||
+		isTemporarySchemaName(tn.Schema())
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(scID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, nil, err
 	} else if immut := table.ImmutableTableDescriptor; immut != nil {
 		// If not forcing to resolve using KV, tables being added aren't visible.
@@ -324,14 +324,14 @@ func (tc *TableCollection) getTableVersion(
 	// transaction.
 	for _, table := range tc.leasedTables {
 		if table.Name == string(tn.TableName) &&
-			table.ParentID == dbID {
+			table.ParentID == dbID && table.GetNamespaceParentID() == scID && !table.Temporary {
 			log.VEventf(ctx, 2, "found table in table collection for table '%s'", tn)
 			return table, nil, nil
 		}
 	}
 
 	origTimestamp := txn.OrigTimestamp()
-	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, origTimestamp, dbID, tn.Table())
+	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, origTimestamp, scID, tn.Table())
 	if err != nil {
 		// Read the descriptor from the store in the face of some specific errors
 		// because of a known limitation of AcquireByName. See the known
@@ -576,14 +576,40 @@ func (tc *TableCollection) getUncommittedDatabaseID(
 // a known deletion of that table, so it would be invalid to miss the
 // cache and go to KV (where the descriptor prior to the DROP may
 // still exist).
+// getSchemaIDForLookup returns the ID under which the name of the given table
+// is recorded in system.namespace: the ID of the database for the tables of
+// the public schema, and the ID of their schema for the other tables. If the
+// schema does not exist, 0 is returned, along with an error if required is
+// true.
+func getSchemaIDForLookup(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, tn *tree.TableName, required bool,
+) (sqlbase.ID, error) {
+	if tn.Schema() == tree.PublicSchema {
+		return dbID, nil
+	}
+	scID, err := getSchemaID(ctx, txn, dbID, tn.Schema())
+	if err == nil && scID == 0 && required {
+		err = sqlbase.NewUndefinedRelationError(tn)
+	}
+	return scID, err
+}
+
 func (tc *TableCollection) getUncommittedTable(
-	dbID sqlbase.ID, tn *tree.TableName, required bool,
+	scID sqlbase.ID, tn *tree.TableName, required bool,
 ) (refuseFurtherLookup bool, table uncommittedTable, err error) {
 	// Walk latest to earliest so that a DROP TABLE followed by a CREATE TABLE
 	// with the same name will result in the CREATE TABLE being seen.
 	for i := len(tc.uncommittedTables) - 1; i >= 0; i-- {
 		table := tc.uncommittedTables[i]
 		mutTbl := table.MutableTableDescriptor
+		// Temporary tables are only visible in their temporary schema, where
+		// their names are recorded under the ID of the schema. The names of
+		// the tables of user-defined schemas are recorded under the ID of their
+		// schema too, and the names of the tables of the public schema under
+		// the ID of their database, which is what scID is for that schema.
+		if isTemporarySchemaName(tn.Schema()) != mutTbl.Temporary {
+			continue
+		}
 		// If a table has gotten renamed we'd like to disallow using the old names.
 		// The renames could have happened in another transaction but it's still okay
 		// to disallow the use of the old name in this transaction because the other
@@ -591,7 +617,7 @@ func (tc *TableCollection) getUncommittedTable(
 		// effect of it.
 		for _, drain := range mutTbl.DrainingNames {
 			if drain.Name == string(tn.TableName) &&
-				drain.ParentID == dbID {
+				drain.ParentID == scID {
 				// Table name has gone away.
 				if required {
 					// If it's required here, say it doesn't exist.
@@ -605,7 +631,7 @@ func (tc *TableCollection) getUncommittedTable(
 
 		// Do we know about a table with this name?
 		if mutTbl.Name == string(tn.TableName) &&
-			mutTbl.ParentID == dbID {
+			mutTbl.GetNamespaceParentID() == scID {
 			// Right state?
 			if err = filterTableState(mutTbl.TableDesc()); err != nil && err != errTableAdding {
 				if !required {
@@ -687,8 +713,13 @@ func (p *planner) createOrUpdateSchemaChangeJob(
 		spanList = job.Details().(jobspb.SchemaChangeDetails).ResumeSpanList
 	}
 
-	span := tableDesc.PrimaryIndexSpan()
 	for i := len(tableDesc.ClusterVersion.Mutations) + len(spanList); i < len(tableDesc.Mutations); i++ {
+		span := tableDesc.PrimaryIndexSpan()
+		if refresh := tableDesc.Mutations[i].GetMaterializedViewRefresh(); refresh != nil {
+			// The refresh of a materialized view doesn't scan the view: its
+			// progress is tracked in the new primary index it writes.
+			span = tableDesc.IndexSpan(refresh.NewPrimaryIndex.ID)
+		}
 		spanList = append(spanList,
 			jobspb.ResumeSpanList{
 				ResumeSpans: []roachpb.Span{span},
@@ -891,6 +922,10 @@ func (p *planner) writeTableDescToBatch(
 		return err
 	}
 
+	if err := p.addTypeBackReferences(ctx, tableDesc.TableDesc()); err != nil {
+		return err
+	}
+
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
 	descVal := sqlbase.WrapDescriptor(tableDesc)
 	if p.extendedEvalCtx.Tracing.KVTracingEnabled() {
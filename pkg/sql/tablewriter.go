@@ -153,11 +153,20 @@ func (tb *tableWriterBase) flushAndStartNewBatch(
 // curBatchSize shares the common curBatchSize() code between extendedTableWriters().
 func (tb *tableWriterBase) curBatchSize() int { return tb.batchSize }
 
-// finalize shares the common finalize code between extendedTableWriters.
+// finalize shares the common finalize code between extendedTableWriters. The
+// uniqueness checks queued by the row writers are run once the last batch is
+// written, before the transaction is committed.
 func (tb *tableWriterBase) finalize(
-	ctx context.Context, autoCommit autoCommitOpt, tableDesc *sqlbase.ImmutableTableDescriptor,
+	ctx context.Context,
+	autoCommit autoCommitOpt,
+	tableDesc *sqlbase.ImmutableTableDescriptor,
+	uniqueChecks ...*row.UniqueChecks,
 ) (err error) {
-	if autoCommit == autoCommitEnabled {
+	pendingChecks := false
+	for _, u := range uniqueChecks {
+		pendingChecks = pendingChecks || u.Pending()
+	}
+	if autoCommit == autoCommitEnabled && !pendingChecks {
 		// An auto-txn can commit the transaction with the batch. This is an
 		// optimization to avoid an extra round-trip to the transaction
 		// coordinator.
@@ -169,6 +178,17 @@ func (tb *tableWriterBase) finalize(
 	if err != nil {
 		return row.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	if !pendingChecks {
+		return nil
+	}
+	for _, u := range uniqueChecks {
+		if err := u.Run(ctx, tb.txn); err != nil {
+			return err
+		}
+	}
+	if autoCommit == autoCommitEnabled {
+		return tb.txn.Commit(ctx)
+	}
 	return nil
 }
 
@@ -56,7 +56,7 @@ func (ti *tableInserter) flushAndStartNewBatch(ctx context.Context) error {
 func (ti *tableInserter) finalize(
 	ctx context.Context, autoCommit autoCommitOpt, _ bool,
 ) (*sqlbase.RowContainer, error) {
-	return nil, ti.tableWriterBase.finalize(ctx, autoCommit, ti.tableDesc())
+	return nil, ti.tableWriterBase.finalize(ctx, autoCommit, ti.tableDesc(), ti.ri.UniqueChecks())
 }
 
 // tableDesc is part of the tableWriter interface.
@@ -66,7 +66,7 @@ func (tu *tableUpdater) flushAndStartNewBatch(ctx context.Context) error {
 func (tu *tableUpdater) finalize(
 	ctx context.Context, autoCommit autoCommitOpt, _ bool,
 ) (*sqlbase.RowContainer, error) {
-	return nil, tu.tableWriterBase.finalize(ctx, autoCommit, tu.tableDesc())
+	return nil, tu.tableWriterBase.finalize(ctx, autoCommit, tu.tableDesc(), tu.ru.UniqueChecks())
 }
 
 // tableDesc is part of the tableWriter interface.
@@ -163,7 +163,7 @@ func (tu *tableUpserterBase) close(ctx context.Context) {
 func (tu *tableUpserterBase) finalize(
 	ctx context.Context, autoCommit autoCommitOpt, traceKV bool,
 ) (*sqlbase.RowContainer, error) {
-	return nil, tu.tableWriterBase.finalize(ctx, autoCommit, tu.tableDesc())
+	return nil, tu.tableWriterBase.finalize(ctx, autoCommit, tu.tableDesc(), tu.ri.UniqueChecks())
 }
 
 // makeResultFromRow reshapes a row that was inserted or updated to a row
@@ -248,6 +248,12 @@ type tableUpserter struct {
 	conflictIndex sqlbase.IndexDescriptor
 	anyComputed   bool
 
+	// conflictIndexPredicate is used to skip the conflict check for the rows
+	// that don't satisfy the predicate of the conflict index, if it is a
+	// partial index. Such rows have no entry in the index, so they can't
+	// conflict with the rows in it.
+	conflictIndexPredicate sqlbase.PartialIndexPredicates
+
 	evalCtx *tree.EvalContext
 
 	// These are set for ON CONFLICT DO UPDATE, but not for DO NOTHING
@@ -274,6 +280,15 @@ type tableUpserter struct {
 // desc is part of the tableWriter interface.
 func (*tableUpserter) desc() string { return "upserter" }
 
+// finalize is part of the tableWriter interface.
+func (tu *tableUpserter) finalize(
+	ctx context.Context, autoCommit autoCommitOpt, traceKV bool,
+) (*sqlbase.RowContainer, error) {
+	return nil, tu.tableWriterBase.finalize(
+		ctx, autoCommit, tu.tableDesc(), tu.ri.UniqueChecks(), tu.ru.UniqueChecks(),
+	)
+}
+
 // init is part of the tableWriter interface.
 func (tu *tableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) error {
 	tu.tableWriterBase.init(txn)
@@ -287,6 +302,13 @@ func (tu *tableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) error
 
 	tableDesc := tu.tableDesc()
 
+	tu.conflictIndexPredicate, err = sqlbase.MakePartialIndexPredicates(
+		tableDesc.TableDesc(), []sqlbase.IndexDescriptor{tu.conflictIndex}, evalCtx,
+	)
+	if err != nil {
+		return err
+	}
+
 	requestedCols := tableDesc.Columns
 
 	if len(tu.updateCols) == 0 {
@@ -300,12 +322,14 @@ func (tu *tableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) error
 			tu.updateCols,
 			requestedCols,
 			row.UpdaterDefault,
+			row.CheckFKs,
 			evalCtx,
 			tu.alloc,
 		)
 		if err != nil {
 			return err
 		}
+		tu.ru.SetDeferredChecks(tu.ri.DeferredChecks())
 
 		// t.ru.fetchCols can also contain columns undergoing mutation.
 		tu.fetchCols = tu.ru.FetchCols
@@ -816,10 +840,20 @@ func (tu *tableUpserter) upsertRowPKSpans(
 	// Otherwise, compute the keys for the conflict index and look them up. The
 	// primary key can be constructed from the entries that come back. In this
 	// case, some spots in the slice will be nil (indicating no conflict) and the
-	// others will be conflicting rows.
+	// others will be conflicting rows. The rows that don't satisfy the predicate
+	// of a partial conflict index are not looked up, so rowIdxs maps the
+	// results of the lookups to the rows.
 	b := tu.txn.NewBatch()
+	rowIdxs := make([]int, 0, tu.insertRows.Len())
 	for i := 0; i < tu.insertRows.Len(); i++ {
 		insertRow := tu.insertRows.At(i)
+		ok, err := tu.conflictIndexPredicate.Satisfies(0, tu.ri.InsertColIDtoRowIndex, insertRow)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
 		entries, err := sqlbase.EncodeSecondaryIndex(
 			tableDesc.TableDesc(), &tu.conflictIndex, tu.ri.InsertColIDtoRowIndex, insertRow)
 		if err != nil {
@@ -831,6 +865,7 @@ func (tu *tableUpserter) upsertRowPKSpans(
 				log.VEventf(ctx, 2, "Get %s", entry.Key)
 			}
 			b.Get(entry.Key)
+			rowIdxs = append(rowIdxs, i)
 		}
 	}
 
@@ -838,7 +873,8 @@ func (tu *tableUpserter) upsertRowPKSpans(
 		return nil, nil, err
 	}
 	conflictingPKs := make(map[int]roachpb.Key)
-	for i, result := range b.Results {
+	for resultIdx, result := range b.Results {
+		i := rowIdxs[resultIdx]
 		if len(result.Rows) == 1 {
 			if result.Rows[0].Value != nil {
 				upsertRowPK, err := sqlbase.ExtractIndexKey(tu.alloc, tableDesc.TableDesc(), result.Rows[0])
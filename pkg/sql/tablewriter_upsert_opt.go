@@ -68,6 +68,10 @@ type optTableUpserter struct {
 	// fkTables is used for foreign key checks in the update case.
 	fkTables row.TableLookupsByID
 
+	// skipFKs is true if the foreign key checks and cascades are planned by
+	// the optimizer, in which case the row writers don't perform them.
+	skipFKs bool
+
 	// ru is used when updating rows.
 	ru row.Updater
 }
@@ -85,6 +89,10 @@ func (tu *optTableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) err
 		tu.resultRow = make(tree.Datums, len(tu.colIDToReturnIndex))
 	}
 
+	checkFKs := row.CheckFKs
+	if tu.skipFKs {
+		checkFKs = row.SkipFKs
+	}
 	tu.ru, err = row.MakeUpdater(
 		txn,
 		tu.tableDesc(),
@@ -92,15 +100,29 @@ func (tu *optTableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) err
 		tu.updateCols,
 		tu.fetchCols,
 		row.UpdaterDefault,
+		checkFKs,
 		evalCtx,
 		tu.alloc,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	tu.ru.SetDeferredChecks(tu.ri.DeferredChecks())
+	return nil
 }
 
 // desc is part of the tableWriter interface.
 func (*optTableUpserter) desc() string { return "opt upserter" }
 
+// finalize is part of the tableWriter interface.
+func (tu *optTableUpserter) finalize(
+	ctx context.Context, autoCommit autoCommitOpt, traceKV bool,
+) (*sqlbase.RowContainer, error) {
+	return nil, tu.tableWriterBase.finalize(
+		ctx, autoCommit, tu.tableDesc(), tu.ri.UniqueChecks(), tu.ru.UniqueChecks(),
+	)
+}
+
 // row is part of the tableWriter interface.
 func (tu *optTableUpserter) row(ctx context.Context, row tree.Datums, traceKV bool) error {
 	tu.batchSize++
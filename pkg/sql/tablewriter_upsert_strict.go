@@ -38,6 +38,12 @@ type strictTableUpserter struct {
 
 	// internal state
 	conflictIndexes []sqlbase.IndexDescriptor
+
+	// conflictIndexPredicates is used to skip the conflict checks on the
+	// partial indexes among conflictIndexes for the rows that don't satisfy
+	// their predicate. Such rows have no entry in the index, so they can't
+	// conflict with the rows in it.
+	conflictIndexPredicates sqlbase.PartialIndexPredicates
 }
 
 // desc is part of the tableWriter interface.
@@ -52,7 +58,7 @@ func (tu *strictTableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext)
 		return err
 	}
 
-	return tu.getUniqueIndexes()
+	return tu.getUniqueIndexes(evalCtx)
 }
 
 // atBatchEnd is part of the extendedTableWriter interface.
@@ -101,16 +107,20 @@ func (tu *strictTableUpserter) atBatchEnd(ctx context.Context, traceKV bool) err
 	return nil
 }
 
-// Get all unique indexes and store them in tu.ConflictIndexes.
-func (tu *strictTableUpserter) getUniqueIndexes() (err error) {
+// Get all unique indexes and store them in tu.ConflictIndexes, along with the
+// predicates of the partial ones in tu.conflictIndexPredicates.
+func (tu *strictTableUpserter) getUniqueIndexes(evalCtx *tree.EvalContext) (err error) {
 	tableDesc := tu.tableDesc()
 	indexes := tableDesc.Indexes
 	for _, index := range indexes {
-		if index.Unique {
+		if index.HasUniqueKeys() {
 			tu.conflictIndexes = append(tu.conflictIndexes, index)
 		}
 	}
-	return nil
+	tu.conflictIndexPredicates, err = sqlbase.MakePartialIndexPredicates(
+		tableDesc.TableDesc(), tu.conflictIndexes, evalCtx,
+	)
+	return err
 }
 
 // getConflictingRows returns all of the the rows that are in conflict.
@@ -127,11 +137,16 @@ func (tu *strictTableUpserter) getConflictingRows(
 	// marker for the caller to indicate whether a row should be inserted or not.
 
 	// The first phase will issue KV requests.
-	// For every row there will be 1 + len(tu.conflictIndexes) requests/responses.
+	// For every row there will be 1 + len(tu.conflictIndexes) requests/responses,
+	// minus one for every partial index whose predicate the row doesn't satisfy.
+	// reqStarts[i] is the index of the first request of the i-th row.
 	b := tu.txn.NewBatch()
+	reqStarts := make([]int, tu.insertRows.Len()+1)
+	numReqs := 0
 
 	for i := 0; i < tu.insertRows.Len(); i++ {
 		row := tu.insertRows.At(i)
+		reqStarts[i] = numReqs
 
 		// Get the primary key of the insert row.
 		upsertRowPKBytes, _, err := sqlbase.EncodeIndexKey(
@@ -145,12 +160,22 @@ func (tu *strictTableUpserter) getConflictingRows(
 			log.VEventf(ctx, 2, "Get %s", upsertRowPK)
 		}
 		b.Get(upsertRowPK)
+		numReqs++
 
 		// Ditto for secondary indexes.
 
-		for _, idx := range tu.conflictIndexes {
+		for j := range tu.conflictIndexes {
+			ok, err := tu.conflictIndexPredicates.Satisfies(j, tu.ri.InsertColIDtoRowIndex, row)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				// The row has no entry in the partial index.
+				continue
+			}
+
 			entries, err := sqlbase.EncodeSecondaryIndex(
-				tableDesc.TableDesc(), &idx, tu.ri.InsertColIDtoRowIndex, row)
+				tableDesc.TableDesc(), &tu.conflictIndexes[j], tu.ri.InsertColIDtoRowIndex, row)
 			if err != nil {
 				return nil, err
 			}
@@ -166,8 +191,10 @@ func (tu *strictTableUpserter) getConflictingRows(
 				log.VEventf(ctx, 2, "Get %s", entry.Key)
 			}
 			b.Get(entry.Key)
+			numReqs++
 		}
 	}
+	reqStarts[tu.insertRows.Len()] = numReqs
 
 	// Now run the batch to collect the existence booleans.
 	if err := tu.txn.Run(ctx, b); err != nil {
@@ -188,7 +215,6 @@ func (tu *strictTableUpserter) getConflictingRows(
 	// conflictingRows = true.
 	seenKeys := make(map[string]struct{})
 
-	reqsPerRow := 1 + len(tu.conflictIndexes)
 	for insertRowIdx := 0; insertRowIdx < tu.insertRows.Len(); insertRowIdx++ {
 		// We will want to operate in two phases: process the existence
 		// results from storage, and only then populate seenKeys.
@@ -196,8 +222,8 @@ func (tu *strictTableUpserter) getConflictingRows(
 		// Process the results of the existence checks.
 		// We iterate on the subset of b.Results that correspond to the
 		// current insert row.
-		startRequestIdx := reqsPerRow * insertRowIdx
-		endRequestIdx := startRequestIdx + reqsPerRow
+		startRequestIdx := reqStarts[insertRowIdx]
+		endRequestIdx := reqStarts[insertRowIdx+1]
 		for requestIdx := startRequestIdx; requestIdx < endRequestIdx; requestIdx++ {
 			row := b.Results[requestIdx].Rows[0]
 			// If any of the result values are not nil, the row exists in storage.
@@ -229,8 +255,8 @@ func (tu *strictTableUpserter) getConflictingRows(
 		// - we cannot do it in the first loop over b.Results above,
 		//   because it's possible the conflict is only detected on a secondary index.
 		if _, ok := conflictingRows[insertRowIdx]; !ok {
-			startRequestIdx := reqsPerRow * insertRowIdx
-			endRequestIdx := startRequestIdx + reqsPerRow
+			startRequestIdx := reqStarts[insertRowIdx]
+			endRequestIdx := reqStarts[insertRowIdx+1]
 			for requestIdx := startRequestIdx; requestIdx < endRequestIdx; requestIdx++ {
 				seenKeys[string(b.Results[requestIdx].Rows[0].Key)] = struct{}{}
 			}
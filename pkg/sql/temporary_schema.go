@@ -0,0 +1,372 @@
+// Copyright 2018 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/pkg/errors"
+)
+
+// This file contains the support for temporary schemas, which hold the
+// temporary tables of a session.
+//
+// Each session has its own temporary schema in every database in which it
+// creates temporary tables. The schema is named pg_temp_<hi>_<lo> after the
+// ID of the session, and it is recorded in system.namespace under the ID of
+// the database, with an ID allocated like a descriptor ID. The names of the
+// temporary tables are in turn recorded in system.namespace under the ID of
+// the temporary schema, so they don't conflict with the tables of the public
+// schema or with the temporary tables of other sessions.
+//
+// The temporary schemas of a session, and the tables in them, are dropped
+// when the session ends. The schemas left behind by sessions which did not
+// end cleanly (for example, because their node died) are dropped by the
+// TemporaryObjectCleaner.
+
+// TempObjectCleanupInterval is the interval at which the temporary objects of
+// sessions which are not running anymore are dropped.
+var TempObjectCleanupInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.temp_object_cleaner.cleanup_interval",
+	"how often to drop the temporary objects of sessions which are not running anymore",
+	30*time.Minute,
+)
+
+// temporarySchemaName returns the name of the temporary schema of the session
+// with the given ID.
+func temporarySchemaName(sessionID ClusterWideID) string {
+	return fmt.Sprintf("%s_%d_%d", sessiondata.PgTempSchemaName, sessionID.Hi, sessionID.Lo)
+}
+
+// isTemporarySchemaName returns true if the given name is the name of a
+// temporary schema.
+func isTemporarySchemaName(scName string) bool {
+	return strings.HasPrefix(scName, sessiondata.PgTempSchemaName+"_")
+}
+
+// temporarySchemaSessionID returns the ID of the session which owns the
+// temporary schema with the given name.
+func temporarySchemaSessionID(scName string) (ClusterWideID, error) {
+	parts := strings.Split(strings.TrimPrefix(scName, sessiondata.PgTempSchemaName+"_"), "_")
+	if len(parts) != 2 {
+		return ClusterWideID{}, errors.Errorf("invalid temporary schema name %q", scName)
+	}
+	hi, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, errors.Wrapf(err, "invalid temporary schema name %q", scName)
+	}
+	lo, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, errors.Wrapf(err, "invalid temporary schema name %q", scName)
+	}
+	return ClusterWideID{Uint128: uint128.FromInts(hi, lo)}, nil
+}
+
+// getTemporarySchemaID returns the ID of the temporary schema with the given
+// name in the given database, or 0 if the schema does not exist.
+func getTemporarySchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (sqlbase.ID, error) {
+	kv, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(dbID, scName))
+	if err != nil || !kv.Exists() {
+		return 0, err
+	}
+	return sqlbase.ID(kv.ValueInt()), nil
+}
+
+// getOrCreateTemporarySchemaID returns the ID of the temporary schema of the
+// session in the given database, creating the schema if it does not exist.
+func (p *planner) getOrCreateTemporarySchemaID(
+	ctx context.Context, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	scName := temporarySchemaName(p.ExtendedEvalContext().SessionID)
+	id, err := getTemporarySchemaID(ctx, p.txn, dbID, scName)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		id, err = GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return 0, err
+		}
+		key := sqlbase.MakeNameMetadataKey(dbID, scName)
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "CPut %s -> %d", key, id)
+		}
+		if err := p.txn.CPut(ctx, key, id, nil); err != nil {
+			return 0, err
+		}
+	}
+	p.sessionDataMutator.SetTemporarySchemaName(scName)
+	return id, nil
+}
+
+// resolveCreateTableTarget resolves the name of the table created by the given
+// CREATE TABLE statement, and returns the descriptor of its database. The
+// names of temporary tables are qualified with the temporary schema of the
+// session. Like in PostgreSQL, tables created in the pg_temp schema are
+// temporary.
+func (p *planner) resolveCreateTableTarget(
+	ctx context.Context, n *tree.CreateTable,
+) (*DatabaseDescriptor, error) {
+	tn := &n.Table
+	scName := temporarySchemaName(p.ExtendedEvalContext().SessionID)
+	if tn.ExplicitSchema {
+		switch {
+		case tn.Schema() == sessiondata.PgTempSchemaName || tn.Schema() == scName:
+			n.Temporary = true
+		case isTemporarySchemaName(tn.Schema()):
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create relations in temporary schemas of other sessions")
+		case n.Temporary:
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
+				"cannot create temporary relation in non-temporary schema")
+		}
+	}
+	if !n.Temporary {
+		return p.ResolveUncachedDatabase(ctx, tn)
+	}
+
+	tn.SchemaName = tree.Name(scName)
+	tn.ExplicitSchema = true
+	var found bool
+	var scMeta tree.SchemaMeta
+	var err error
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		found, scMeta, err = tn.ResolveTarget(ctx, p, p.CurrentDatabase(), p.CurrentSearchPath())
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError,
+			"cannot create %q because the target database does not exist",
+			tree.ErrString(tn)).SetHintf("verify that the current database is valid and/or the target database exists")
+	}
+	return scMeta.(*DatabaseDescriptor), nil
+}
+
+// listTemporarySchemas returns the names and IDs of the temporary schemas in
+// the given database.
+func listTemporarySchemas(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (map[string]sqlbase.ID, error) {
+	prefix := sqlbase.MakeNameMetadataKey(dbID, "")
+	kvs, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[string]sqlbase.ID)
+	for _, kv := range kvs {
+		_, name, err := encoding.DecodeUnsafeStringAscending(bytes.TrimPrefix(kv.Key, prefix), nil)
+		if err != nil {
+			return nil, err
+		}
+		if isTemporarySchemaName(name) {
+			schemas[name] = sqlbase.ID(kv.ValueInt())
+		}
+	}
+	return schemas, nil
+}
+
+// cleanupTemporarySchema drops the given temporary schema of the given
+// database, along with all of the tables in it.
+func cleanupTemporarySchema(
+	ctx context.Context,
+	txn *client.Txn,
+	ie sqlutil.InternalExecutor,
+	descs []sqlbase.DescriptorProto,
+	dbDesc *sqlbase.DatabaseDescriptor,
+	scName string,
+	scID sqlbase.ID,
+) error {
+	var tables tree.TableNames
+	for _, desc := range descs {
+		if table, ok := desc.(*sqlbase.TableDescriptor); ok &&
+			table.Temporary && table.UnexposedParentSchemaID == scID && !table.Dropped() {
+			tables = append(tables, tree.MakeTableNameWithSchema(
+				tree.Name(dbDesc.Name), tree.Name(scName), tree.Name(table.Name)))
+		}
+	}
+	if len(tables) > 0 {
+		if _, err := ie.Exec(
+			ctx, "drop-temp-tables", txn,
+			fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", tree.AsString(&tables)),
+		); err != nil {
+			return err
+		}
+	}
+	return txn.Del(ctx, sqlbase.MakeNameMetadataKey(dbDesc.ID, scName))
+}
+
+// cleanupSessionTempObjects drops the temporary schemas of the session with
+// the given ID, along with all of the tables in them.
+func cleanupSessionTempObjects(
+	ctx context.Context, db *client.DB, ie sqlutil.InternalExecutor, sessionID ClusterWideID,
+) error {
+	scName := temporarySchemaName(sessionID)
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		descs, err := GetAllDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, desc := range descs {
+			dbDesc, ok := desc.(*sqlbase.DatabaseDescriptor)
+			if !ok {
+				continue
+			}
+			scID, err := getTemporarySchemaID(ctx, txn, dbDesc.ID, scName)
+			if err != nil {
+				return err
+			}
+			if scID == 0 {
+				continue
+			}
+			if err := cleanupTemporarySchema(
+				ctx, txn, ie, descs, dbDesc, scName, scID,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TemporaryObjectCleaner periodically drops the temporary schemas, and the
+// tables in them, of the sessions which are not running anymore. Sessions
+// normally drop their temporary objects when they end, so this only finds
+// the objects of sessions that did not end cleanly; that is, sessions which
+// ran on a node that is not live anymore, or on a previous incarnation of
+// this node.
+type TemporaryObjectCleaner struct {
+	settings *cluster.Settings
+	db       *client.DB
+	ie       sqlutil.InternalExecutor
+	nodeID   *base.NodeIDContainer
+	registry *SessionRegistry
+	// isLive returns whether the given node is live, according to node
+	// liveness.
+	isLive func(roachpb.NodeID) (bool, error)
+}
+
+// NewTemporaryObjectCleaner creates a TemporaryObjectCleaner.
+func NewTemporaryObjectCleaner(
+	settings *cluster.Settings,
+	db *client.DB,
+	ie sqlutil.InternalExecutor,
+	nodeID *base.NodeIDContainer,
+	registry *SessionRegistry,
+	isLive func(roachpb.NodeID) (bool, error),
+) *TemporaryObjectCleaner {
+	return &TemporaryObjectCleaner{
+		settings: settings,
+		db:       db,
+		ie:       ie,
+		nodeID:   nodeID,
+		registry: registry,
+		isLive:   isLive,
+	}
+}
+
+// Start starts the background worker which periodically drops the temporary
+// objects of sessions which are not running anymore.
+func (c *TemporaryObjectCleaner) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(TempObjectCleanupInterval.Get(&c.settings.SV)):
+				if err := c.doTemporaryObjectCleanup(ctx); err != nil {
+					log.Warningf(ctx, "failed to clean up temporary objects: %v", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
+}
+
+// isSessionRunning returns true if the session with the given ID may still be
+// running. Sessions on other nodes are considered to be running as long as
+// their node is live; the sessions of this node are looked up in the session
+// registry, since this node may have restarted since the session was created.
+func (c *TemporaryObjectCleaner) isSessionRunning(sessionID ClusterWideID) (bool, error) {
+	nodeID := roachpb.NodeID(sessionID.GetNodeID())
+	if nodeID == c.nodeID.Get() {
+		return c.registry.hasSession(sessionID), nil
+	}
+	return c.isLive(nodeID)
+}
+
+// doTemporaryObjectCleanup drops the temporary schemas of all the sessions
+// which are not running anymore.
+func (c *TemporaryObjectCleaner) doTemporaryObjectCleanup(ctx context.Context) error {
+	return c.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		descs, err := GetAllDescriptors(ctx, txn)
+		if err != nil {
+			return err
+		}
+		for _, desc := range descs {
+			dbDesc, ok := desc.(*sqlbase.DatabaseDescriptor)
+			if !ok {
+				continue
+			}
+			schemas, err := listTemporarySchemas(ctx, txn, dbDesc.ID)
+			if err != nil {
+				return err
+			}
+			for scName, scID := range schemas {
+				sessionID, err := temporarySchemaSessionID(scName)
+				if err != nil {
+					return err
+				}
+				running, err := c.isSessionRunning(sessionID)
+				if err != nil {
+					log.Warningf(ctx, "unable to determine whether session %s is running: %v", sessionID, err)
+					continue
+				}
+				if running {
+					continue
+				}
+				log.Infof(ctx, "dropping temporary schema %s.%s", dbDesc.Name, scName)
+				if err := cleanupTemporarySchema(
+					ctx, txn, c.ie, descs, dbDesc, scName, scID,
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -267,7 +267,7 @@ func (p *planner) truncateTable(
 	newTableDesc.Mutations = nil
 	newTableDesc.GCMutations = nil
 	newTableDesc.ModificationTime = p.txn.CommitTimestamp()
-	tKey := tableKey{parentID: newTableDesc.ParentID, name: newTableDesc.Name}
+	tKey := tableKey{parentID: newTableDesc.GetNamespaceParentID(), name: newTableDesc.Name}
 	key := tKey.Key()
 	if err := p.createDescriptorWithID(
 		ctx, key, newID, newTableDesc, p.ExtendedEvalContext().Settings); err != nil {
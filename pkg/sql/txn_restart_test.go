@@ -1606,9 +1606,9 @@ func TestRollbackToSavepointFromUnusualStates(t *testing.T) {
 		}
 	}
 
-	// ROLLBACK TO SAVEPOINT with a wrong name
+	// ROLLBACK TO SAVEPOINT outside of a transaction
 	_, err := sqlDB.Exec("ROLLBACK TO SAVEPOINT foo")
-	if !testutils.IsError(err, "SAVEPOINT not supported except for cockroach_restart") {
+	if !testutils.IsError(err, "ROLLBACK TO SAVEPOINT can only be used in transaction blocks") {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -87,6 +88,11 @@ type txnState struct {
 	// The transaction's read only state.
 	readOnly bool
 
+	// The transaction's isolation level. The statements of READ_COMMITTED
+	// transactions read from their own snapshots and are retried individually
+	// on retryable errors; see execStmtInOpenState.
+	isolation enginepb.IsolationType
+
 	// mon tracks txn-bound objects like the running state of
 	// planNode in the midst of performing a computation.
 	mon *mon.BytesMonitor
@@ -108,6 +114,11 @@ type txnState struct {
 	// activeSavepointName stores the name of the active savepoint,
 	// or is empty if no savepoint is active.
 	activeSavepointName tree.Name
+
+	// savepoints is the stack of the savepoints established in the transaction,
+	// other than the cockroach_restart savepoint. The savepoints are forgotten
+	// when the transaction restarts.
+	savepoints []sqlSavepoint
 }
 
 // txnType represents the type of a SQL transaction.
@@ -133,6 +144,7 @@ const (
 // sqlTimestamp: The timestamp to report for current_timestamp(), now() etc.
 // priority: The transaction's priority.
 // readOnly: The read-only character of the new txn.
+// isolation: The isolation level of the new txn.
 // txn: If not nil, this txn will be used instead of creating a new txn. If so,
 //      all the other arguments need to correspond to the attributes of this txn.
 // tranCtx: A bag of extra execution context.
@@ -142,6 +154,7 @@ func (ts *txnState) resetForNewSQLTxn(
 	sqlTimestamp time.Time,
 	priority roachpb.UserPriority,
 	readOnly tree.ReadWriteMode,
+	isolation enginepb.IsolationType,
 	txn *client.Txn,
 	tranCtx transitionCtx,
 ) {
@@ -213,9 +226,17 @@ func (ts *txnState) resetForNewSQLTxn(
 	if err := ts.setReadOnlyMode(readOnly); err != nil {
 		panic(err)
 	}
+	if txn == nil {
+		if err := ts.setIsolation(isolation); err != nil {
+			panic(err)
+		}
+	} else {
+		ts.isolation = isolation
+	}
 
 	// Discard the old schemaChangers, if any.
 	ts.schemaChangers = schemaChangerCollection{}
+	ts.savepoints = nil
 }
 
 // finishSQLTxn finalizes a transaction's results and closes the root span for
@@ -248,12 +269,26 @@ func (ts *txnState) finishSQLTxn() {
 	ts.sp.Finish()
 	ts.sp = nil
 	ts.Ctx = nil
+	ts.savepoints = nil
 	ts.mu.Lock()
 	ts.mu.txn = nil
 	ts.mu.Unlock()
 	ts.recordingThreshold = 0
 }
 
+// rollbackKeptTxn rolls back the KV txn of a SQL txn in the Aborted state if
+// the KV txn was kept open so that the client could roll back to one of the
+// savepoints. It is called when the SQL txn ends instead.
+func (ts *txnState) rollbackKeptTxn() {
+	if len(ts.savepoints) == 0 {
+		return
+	}
+	ts.savepoints = nil
+	if err := ts.mu.txn.Rollback(ts.Ctx); err != nil {
+		log.Warningf(ts.Ctx, "txn rollback failed: %s", err)
+	}
+}
+
 // finishExternalTxn is a stripped-down version of finishSQLTxn used by
 // connExecutors that run within a higher-level transaction (through the
 // InternalExecutor). These guys don't want to mess with the transaction per-se,
@@ -289,6 +324,17 @@ func (ts *txnState) setPriority(userPriority roachpb.UserPriority) error {
 	return nil
 }
 
+func (ts *txnState) setIsolation(iso enginepb.IsolationType) error {
+	ts.mu.Lock()
+	err := ts.mu.txn.SetIsolation(iso)
+	ts.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	ts.isolation = iso
+	return nil
+}
+
 func (ts *txnState) setReadOnlyMode(mode tree.ReadWriteMode) error {
 	switch mode {
 	case tree.UnspecifiedReadWriteMode:
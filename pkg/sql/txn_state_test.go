@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	// We dot-import fsm to use common names such as fsm.True/False.
 	. "github.com/cockroachdb/cockroach/pkg/util/fsm"
@@ -297,9 +298,10 @@ func TestTransitions(t *testing.T) {
 				s, ts := testCon.createNoTxnState()
 				return s, ts, nil
 			},
-			ev:        eventTxnStart{ImplicitTxn: True},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(), tranCtx),
-			expState:  stateOpen{ImplicitTxn: True, RetryIntent: False},
+			ev: eventTxnStart{ImplicitTxn: True},
+			evPayload: makeEventTxnStartPayload(
+				pri, tree.ReadWrite, enginepb.SERIALIZABLE, timeutil.Now(), tranCtx),
+			expState: stateOpen{ImplicitTxn: True, RetryIntent: False},
 			expAdv: expAdvance{
 				// We expect to stayInPlace; upon starting a txn the statement is
 				// executed again, this time in state Open.
@@ -321,9 +323,10 @@ func TestTransitions(t *testing.T) {
 				s, ts := testCon.createNoTxnState()
 				return s, ts, nil
 			},
-			ev:        eventTxnStart{ImplicitTxn: False},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(), tranCtx),
-			expState:  stateOpen{ImplicitTxn: False, RetryIntent: False},
+			ev: eventTxnStart{ImplicitTxn: False},
+			evPayload: makeEventTxnStartPayload(
+				pri, tree.ReadWrite, enginepb.SERIALIZABLE, timeutil.Now(), tranCtx),
+			expState: stateOpen{ImplicitTxn: False, RetryIntent: False},
 			expAdv: expAdvance{
 				expCode: advanceOne,
 				expEv:   txnStart,
@@ -647,9 +650,10 @@ func TestTransitions(t *testing.T) {
 				s, ts := testCon.createAbortedState(retryIntentSet)
 				return s, ts, nil
 			},
-			ev:        eventTxnStart{ImplicitTxn: False},
-			evPayload: makeEventTxnStartPayload(pri, tree.ReadWrite, timeutil.Now(), tranCtx),
-			expState:  stateOpen{ImplicitTxn: False, RetryIntent: True},
+			ev: eventTxnStart{ImplicitTxn: False},
+			evPayload: makeEventTxnStartPayload(
+				pri, tree.ReadWrite, enginepb.SERIALIZABLE, timeutil.Now(), tranCtx),
+			expState: stateOpen{ImplicitTxn: False, RetryIntent: True},
 			expAdv: expAdvance{
 				expCode: advanceOne,
 				expEv:   noEvent,
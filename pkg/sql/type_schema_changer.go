@@ -0,0 +1,159 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/pkg/errors"
+)
+
+// typeSchemaChanger makes public the members being added to a user-defined
+// type. The members are first added as read-only to the type and to the
+// tables which use it; the typeSchemaChanger waits for every node to use a
+// version of these tables in which the members can be decoded, and then
+// makes the members public in the type and its tables in a single
+// transaction.
+//
+// Like the SchemaChanger, the typeSchemaChanger is run by the node which
+// altered the type once the transaction commits, and by the
+// SchemaChangeManager of any node if that node fails to complete it.
+type typeSchemaChanger struct {
+	typeID   sqlbase.ID
+	db       *client.DB
+	leaseMgr *LeaseManager
+	settings *cluster.Settings
+	// The SchemaChangeManager can attempt to execute this schema
+	// changer after this time.
+	execAfter time.Time
+}
+
+// queueTypeSchemaChange queues up a schema changer to make the members being
+// added to the given type public.
+func (p *planner) queueTypeSchemaChange(typeID sqlbase.ID) {
+	p.extendedEvalCtx.SchemaChangers.queueTypeSchemaChanger(typeSchemaChanger{
+		typeID:   typeID,
+		db:       p.ExecCfg().DB,
+		leaseMgr: p.LeaseMgr(),
+		settings: p.ExecCfg().Settings,
+	})
+}
+
+var errTypeReferencesChanged = errors.New("the tables of the type changed")
+
+// exec makes the read-only members of the type public. It is a no-op if the
+// type has no read-only members, or does not exist anymore.
+func (sc *typeSchemaChanger) exec(ctx context.Context) error {
+	if log.V(2) {
+		log.Infof(ctx, "exec pending type schema change; type: %d", sc.typeID)
+	}
+	for r := retry.StartWithCtx(ctx, base.DefaultRetryOptions()); r.Next(); {
+		err := sc.promoteMembers(ctx)
+		if err != errTypeReferencesChanged {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// promoteMembers waits for the tables of the type to have a single version,
+// and then makes the read-only members of the type public. It returns
+// errTypeReferencesChanged if the tables of the type changed while waiting.
+func (sc *typeSchemaChanger) promoteMembers(ctx context.Context) error {
+	var tableIDs []sqlbase.ID
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		tableIDs = nil
+		typeDesc, err := getTypeDescByID(ctx, txn, sc.typeID)
+		if err != nil || typeDesc == nil || !typeDesc.HasReadOnlyEnumMembers() {
+			return err
+		}
+		for _, id := range typeDesc.ReferencingDescriptorIDs {
+			if _, err := sqlbase.GetTableDescFromID(ctx, txn, id); err != nil {
+				if err == sqlbase.ErrDescriptorNotFound {
+					continue
+				}
+				return err
+			}
+			tableIDs = append(tableIDs, id)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Wait until every node uses a version of the tables in which the
+	// read-only members can be decoded. The members can then be written
+	// without violating the two version invariant: the nodes which use the
+	// version of a table preceding the one published below can decode them.
+	retryOpts := retry.Options{
+		InitialBackoff: 20 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+		Multiplier:     2,
+	}
+	versions := make(map[sqlbase.ID]sqlbase.DescriptorVersion, len(tableIDs))
+	for _, id := range tableIDs {
+		version, err := sc.leaseMgr.WaitForOneVersion(ctx, id, retryOpts)
+		if err != nil {
+			return err
+		}
+		versions[id] = version
+	}
+
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		typeDesc, err := getTypeDescByID(ctx, txn, sc.typeID)
+		if err != nil || typeDesc == nil || !typeDesc.PromoteEnumMembers() {
+			return err
+		}
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		b := txn.NewBatch()
+		for _, id := range append([]sqlbase.ID(nil), typeDesc.ReferencingDescriptorIDs...) {
+			tableDesc, err := sqlbase.GetMutableTableDescFromID(ctx, txn, id)
+			if err != nil {
+				if err == sqlbase.ErrDescriptorNotFound {
+					typeDesc.RemoveReferencingDescriptorID(id)
+					continue
+				}
+				return err
+			}
+			if tableDesc.Dropped() || !setEnumColumnTypes(tableDesc.TableDesc(), typeDesc) {
+				typeDesc.RemoveReferencingDescriptorID(id)
+				continue
+			}
+			if version, ok := versions[id]; !ok || version != tableDesc.Version {
+				// A table started using the type, or a table of the type was
+				// altered, while waiting.
+				return errTypeReferencesChanged
+			}
+			if err := maybeIncrementVersion(ctx, tableDesc, txn); err != nil {
+				return err
+			}
+			if err := tableDesc.ValidateTable(sc.settings); err != nil {
+				return err
+			}
+			b.Put(sqlbase.MakeDescMetadataKey(id), sqlbase.WrapDescriptor(tableDesc))
+		}
+		b.Put(sqlbase.MakeDescMetadataKey(typeDesc.ID), sqlbase.WrapDescriptor(typeDesc))
+		return txn.CommitInBatch(ctx, b)
+	})
+}
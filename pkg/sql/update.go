@@ -210,12 +210,14 @@ func (p *planner) Update(
 		updateCols,
 		requestedCols,
 		row.UpdaterDefault,
+		row.CheckFKs,
 		p.EvalContext(),
 		&p.alloc,
 	)
 	if err != nil {
 		return nil, err
 	}
+	ru.SetDeferredChecks(p.deferredChecks())
 
 	tracing.AnnotateTrace()
 
@@ -19,6 +19,11 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/xform"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -66,7 +71,9 @@ func (p *planner) newUpsertNode(
 	// Extract the index that will detect upsert conflicts
 	// (conflictIndex) and the assignment expressions to use when
 	// conflicts are detected (updateExprs).
-	autoGenUpdates, updateExprs, conflictIndex, err := upsertExprsAndIndex(desc, *n.OnConflict, ri.InsertCols)
+	autoGenUpdates, updateExprs, conflictIndex, err := p.upsertExprsAndIndex(
+		ctx, desc, *n.OnConflict, ri.InsertCols,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -754,7 +761,8 @@ func (uh *upsertHelper) shouldUpdate(insertRow tree.Datums, existingRow tree.Dat
 // - updateExprs: the assignment expressions in ON CONFLICT DO UPDATE,
 //   or auto-generated assignments for UPSERT.
 // - conflictIdx: the conflicting index, if specified.
-func upsertExprsAndIndex(
+func (p *planner) upsertExprsAndIndex(
+	ctx context.Context,
 	tableDesc *sqlbase.ImmutableTableDescriptor,
 	onConflict tree.OnConflict,
 	insertCols []sqlbase.ColumnDescriptor,
@@ -805,7 +813,7 @@ func upsertExprsAndIndex(
 	// General case: INSERT with an ON CONFLICT clause.
 
 	indexMatch := func(index sqlbase.IndexDescriptor) bool {
-		if !index.Unique {
+		if !index.HasUniqueKeys() {
 			return false
 		}
 		if len(index.ColumnNames) != len(onConflict.Columns) {
@@ -822,10 +830,86 @@ func upsertExprsAndIndex(
 	if indexMatch(tableDesc.PrimaryIndex) {
 		return false, onConflict.Exprs, &tableDesc.PrimaryIndex, nil
 	}
+	// A unique partial index can only be used if the WHERE clause of the ON
+	// CONFLICT target implies its predicate. Non-partial indexes are preferred.
+	var partialIndex, unmatchedPartialIndex *sqlbase.IndexDescriptor
 	for i := range tableDesc.Indexes {
 		if indexMatch(tableDesc.Indexes[i]) {
+			if tableDesc.Indexes[i].IsPartial() {
+				if partialIndex != nil {
+					continue
+				}
+				implied, err := p.arbiterPredicateImplies(
+					ctx, tableDesc, &tableDesc.Indexes[i], onConflict.ArbiterPredicate,
+				)
+				if err != nil {
+					return false, nil, nil, err
+				}
+				if implied {
+					partialIndex = &tableDesc.Indexes[i]
+				} else {
+					unmatchedPartialIndex = &tableDesc.Indexes[i]
+				}
+				continue
+			}
 			return false, onConflict.Exprs, &tableDesc.Indexes[i], nil
 		}
 	}
+	if partialIndex != nil {
+		return false, onConflict.Exprs, partialIndex, nil
+	}
+	if unmatchedPartialIndex != nil {
+		return false, nil, nil, pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+			"unique partial index %q cannot be used as an ON CONFLICT arbiter unless the "+
+				"WHERE clause of the conflict target implies its predicate", unmatchedPartialIndex.Name)
+	}
 	return false, nil, nil, fmt.Errorf("there is no unique or exclusion constraint matching the ON CONFLICT specification")
 }
+
+// arbiterPredicateImplies returns true if the given arbiter predicate, which
+// is the WHERE clause of an ON CONFLICT target, implies the predicate of the
+// given partial index. Both are built as scalar expressions of the optimizer,
+// so that the implication is detected as it is when the optimizer plans the
+// statement. See memo.FiltersImplyPredicate.
+func (p *planner) arbiterPredicateImplies(
+	ctx context.Context,
+	tableDesc *sqlbase.ImmutableTableDescriptor,
+	index *sqlbase.IndexDescriptor,
+	arbiterPredicate tree.Expr,
+) (bool, error) {
+	if arbiterPredicate == nil {
+		return false, nil
+	}
+	predicate, err := parser.ParseExpr(index.Predicate)
+	if err != nil {
+		return false, err
+	}
+
+	var optimizer xform.Optimizer
+	optimizer.Init(p.EvalContext())
+	md := optimizer.Memo().Metadata()
+	for i := range tableDesc.Columns {
+		md.AddColumn(tableDesc.Columns[i].Name, tableDesc.Columns[i].Type.ToDatumType())
+	}
+	build := func(expr tree.Expr) (opt.ScalarExpr, error) {
+		typedExpr, err := sqlbase.MakeTablePredicateExpr(tableDesc.TableDesc(), expr, p.EvalContext())
+		if err != nil {
+			return nil, err
+		}
+		bld := optbuilder.NewScalar(ctx, &p.semaCtx, p.EvalContext(), optimizer.Factory())
+		if err := bld.Build(typedExpr); err != nil {
+			return nil, err
+		}
+		return optimizer.Memo().RootExpr().(opt.ScalarExpr), nil
+	}
+	arbiter, err := build(arbiterPredicate)
+	if err != nil {
+		return false, err
+	}
+	pred, err := build(predicate)
+	if err != nil {
+		return false, err
+	}
+	filters := memo.FiltersExpr{{Condition: arbiter}}
+	return memo.FiltersImplyPredicate(optimizer.Memo(), p.EvalContext(), filters, pred), nil
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
@@ -259,16 +260,23 @@ var varGen = map[string]sessionVar{
 	`default_transaction_isolation`: {
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
 			switch strings.ToUpper(s) {
-			case `READ UNCOMMITTED`, `READ COMMITTED`, `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`, `DEFAULT`:
-				// Do nothing. All transactions execute with serializable isolation.
+			case `READ UNCOMMITTED`, `READ COMMITTED`:
+				if err := checkIsolationSupported(m.settings, enginepb.READ_COMMITTED); err != nil {
+					return err
+				}
+				m.SetDefaultIsolation(enginepb.READ_COMMITTED)
+			case `SNAPSHOT`, `REPEATABLE READ`, `SERIALIZABLE`, `DEFAULT`:
+				// SNAPSHOT and REPEATABLE READ are upgraded to SERIALIZABLE.
+				m.SetDefaultIsolation(enginepb.SERIALIZABLE)
 			default:
-				return newVarValueError(`default_transaction_isolation`, s, "serializable")
+				return newVarValueError(`default_transaction_isolation`, s,
+					"serializable", "read committed")
 			}
 
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext) string {
-			return "serializable"
+			return isolationName(evalCtx.SessionData.DefaultIsolation)
 		},
 		GlobalDefault: func(sv *settings.Values) string { return "default" },
 	},
@@ -415,6 +423,25 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`optimizer_foreign_keys`: {
+		GetStringVal: makeBoolGetStringValFn(`optimizer_foreign_keys`),
+		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
+			b, err := parsePostgresBool(s)
+			if err != nil {
+				return err
+			}
+			m.SetOptimizerFKs(b)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData.OptimizerFKs)
+		},
+		GlobalDefault: func(sv *settings.Values) string {
+			return formatBoolAsPostgresSetting(OptimizerFKsClusterMode.Get(sv))
+		},
+	},
+
 	// CockroachDB extension.
 	`experimental_serial_normalization`: {
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
@@ -541,7 +568,7 @@ var varGen = map[string]sessionVar{
 		},
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
 			paths := strings.Split(s, ",")
-			m.SetSearchPath(sessiondata.MakeSearchPath(paths))
+			m.SetSearchPath(m.data.SearchPath.UpdatePaths(paths))
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext) string {
@@ -631,14 +658,14 @@ var varGen = map[string]sessionVar{
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
 		Get: func(evalCtx *extendedEvalContext) string {
-			return "serializable"
+			return isolationName(evalCtx.Txn.Isolation())
 		},
 		RuntimeSet: func(_ context.Context, evalCtx *extendedEvalContext, s string) error {
-			_, ok := tree.IsolationLevelMap[s]
+			level, ok := tree.IsolationLevelMap[s]
 			if !ok {
-				return newVarValueError(`transaction_isolation`, s, "serializable")
+				return newVarValueError(`transaction_isolation`, s, "serializable", "read committed")
 			}
-			return nil
+			return evalCtx.TxnModesSetter.setTransactionModes(tree.TransactionModes{Isolation: level})
 		},
 		GlobalDefault: func(_ *settings.Values) string { return "serializable" },
 	},
@@ -19,10 +19,17 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 // planDependencyInfo collects the dependencies related to a single
@@ -95,3 +102,158 @@ func (p *planner) analyzeViewQuery(
 
 	return p.curPlan.deps, planColumns(sourcePlan), nil
 }
+
+// writeMaterializedViewRows writes the rows returned by nextRow, which are
+// results of the query of the materialized view desc, into the indexes of
+// desc. nextRow returns a nil row once the results are exhausted. The value of
+// the hidden rowid column, which is the last column of desc, is returned by
+// nextRowID for every row, or synthesized by its default expression if
+// nextRowID is nil. The number of rows written is returned.
+//
+// This is a very simplified version of the INSERT logic, like the one of
+// CREATE TABLE AS: materialized views have no CHECK expressions and no
+// foreign keys.
+func writeMaterializedViewRows(
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	desc *sqlbase.ImmutableTableDescriptor,
+	nextRow func() (tree.Datums, error),
+	nextRowID func() tree.Datum,
+	traceKV bool,
+) (int, error) {
+	var alloc sqlbase.DatumAlloc
+	ri, err := row.MakeInserter(
+		txn, desc, nil /* fkTables */, desc.Columns, row.SkipFKs, evalCtx, &alloc)
+	if err != nil {
+		return 0, err
+	}
+	ti := tableInserter{ri: ri}
+	if err := ti.init(txn, evalCtx); err != nil {
+		return 0, err
+	}
+	defer ti.close(ctx)
+
+	defaultExprs, err := sqlbase.MakeDefaultExprs(
+		desc.Columns, &transform.ExprTransformContext{}, evalCtx)
+	if err != nil {
+		return 0, err
+	}
+	rowIDIdx := len(desc.Columns) - 1
+	rowBuffer := make(tree.Datums, len(desc.Columns))
+
+	rowCount := 0
+	for {
+		values, err := nextRow()
+		if err != nil {
+			return 0, err
+		}
+		if values == nil {
+			break
+		}
+		copy(rowBuffer, values)
+		if nextRowID != nil {
+			rowBuffer[rowIDIdx] = nextRowID()
+		} else if rowBuffer[rowIDIdx], err = defaultExprs[rowIDIdx].Eval(evalCtx); err != nil {
+			return 0, err
+		}
+		if err := ti.row(ctx, rowBuffer, traceKV); err != nil {
+			return 0, err
+		}
+		rowCount++
+
+		if ti.curBatchSize() >= maxInsertBatchSize {
+			if err := ti.flushAndStartNewBatch(ctx); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if _, err := ti.finalize(ctx, noAutoCommit, traceKV); err != nil {
+		return 0, err
+	}
+	return rowCount, nil
+}
+
+// materializedViewRefreshQuery returns a query which returns the rows of the
+// materialized view desc from the given offset on. The rows are ordered by
+// the text of each of their columns, which orders any column type, so that
+// running the query at the same timestamp always returns the rows in the same
+// order: rows that compare equal are identical.
+func materializedViewRefreshQuery(desc *sqlbase.TableDescriptor, offset int64) string {
+	// The last column is the hidden rowid column.
+	cols := desc.Columns[:len(desc.Columns)-1]
+	names := make(tree.NameList, len(cols))
+	var orderBy bytes.Buffer
+	for i := range cols {
+		names[i] = tree.Name(cols[i].Name)
+		if i > 0 {
+			orderBy.WriteString(", ")
+		}
+		fmt.Fprintf(&orderBy, "%s::STRING", tree.AsString(&names[i]))
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS refresh (%s) ORDER BY %s OFFSET %d",
+		desc.ViewQuery, tree.AsString(&names), orderBy.String(), offset)
+}
+
+// runMaterializedViewQuery runs query, which reads from the query of a
+// materialized view, with DistSQL using the given transaction, and sends its
+// results to rw as they are produced.
+func runMaterializedViewQuery(
+	ctx context.Context, txn *client.Txn, execCfg *ExecutorConfig, query string, rw rowResultWriter,
+) error {
+	p, cleanup := newInternalPlanner(
+		"refresh-materialized-view", txn, security.RootUser, &MemoryMetrics{}, execCfg)
+	defer cleanup()
+	defer p.Tables().releaseTables(ctx)
+
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return err
+	}
+	if err := p.makePlan(ctx, Statement{Statement: stmt}); err != nil {
+		return err
+	}
+	defer p.curPlan.close(ctx)
+
+	recv := MakeDistSQLReceiver(
+		ctx,
+		rw,
+		tree.Rows,
+		execCfg.RangeDescriptorCache,
+		execCfg.LeaseHolderCache,
+		txn,
+		func(ts hlc.Timestamp) {
+			_ = execCfg.Clock.Update(ts)
+		},
+		p.ExtendedEvalContext().Tracing,
+	)
+	defer recv.Release()
+
+	dsp := execCfg.DistSQLPlanner
+	distribute := shouldDistributePlan(ctx, sessiondata.DistSQLAuto, dsp, p.curPlan.plan)
+	evalCtx := p.ExtendedEvalContext()
+	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, txn)
+	planCtx.isLocal = !distribute
+	planCtx.planner = p
+	planCtx.stmtType = recv.stmtType
+
+	if len(p.curPlan.subqueryPlans) != 0 {
+		evalCtxFactory := func() *extendedEvalContext {
+			ret := *evalCtx
+			return &ret
+		}
+		if !dsp.PlanAndRunSubqueries(
+			ctx, p, evalCtxFactory, p.curPlan.subqueryPlans, recv, distribute,
+		) {
+			if err := rw.Err(); err != nil {
+				return err
+			}
+			return recv.commErr
+		}
+	}
+	dsp.PlanAndRun(ctx, evalCtx, planCtx, txn, p.curPlan.plan, recv)
+	if err := rw.Err(); err != nil {
+		return err
+	}
+	return recv.commErr
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -196,6 +197,13 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 			if n.hardLimit > 0 && isFilterTrue(n.filter) {
 				v.observer.attr(name, "limit", fmt.Sprintf("%d", n.hardLimit))
 			}
+			if n.lockingStrength != tree.ForNone {
+				locking := n.lockingStrength.String()
+				if n.lockingWaitPolicy != tree.LockWaitBlock {
+					locking += " " + n.lockingWaitPolicy.String()
+				}
+				v.observer.attr(name, "locking", strings.ToLower(locking))
+			}
 		}
 		if v.observer.expr != nil {
 			v.expr(name, "filter", -1, n.filter)
@@ -290,6 +298,15 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 		n.left.plan = v.visit(n.left.plan)
 		n.right.plan = v.visit(n.right.plan)
 
+	case *applyJoinNode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "type", joinTypeStr(n.joinType))
+		}
+		if v.observer.expr != nil && n.onCond != nil && n.onCond != tree.DBoolTrue {
+			v.expr(name, "pred", -1, n.onCond)
+		}
+		n.input = v.visit(n.input)
+
 	case *limitNode:
 		if v.observer.expr != nil {
 			v.expr(name, "count", -1, n.countExpr)
@@ -300,6 +317,26 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 	case *max1RowNode:
 		n.plan = v.visit(n.plan)
 
+	case *recursiveCTENode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "label", n.label)
+		}
+		n.initial = v.visit(n.initial)
+
+	case *scanBufferNode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "label", n.label)
+		}
+
+	case *bufferNode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "label", n.label)
+		}
+		n.plan = v.visit(n.plan)
+
+	case *errorIfRowsNode:
+		n.plan = v.visit(n.plan)
+
 	case *distinctNode:
 		if v.observer.attr == nil {
 			n.plan = v.visit(n.plan)
@@ -364,7 +401,10 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 					buf.WriteString(inputCols[groupingCol].Name)
 				} else {
 					fmt.Fprintf(&buf, "%s(", agg.funcName)
-					if agg.argRenderIdx != noRenderIdx {
+					if agg.argRenderIdx == len(inputCols) {
+						// This is the ordinal of the grouping set.
+						buf.WriteString("grouping_set_id")
+					} else if agg.argRenderIdx != noRenderIdx {
 						if agg.isDistinct() {
 							buf.WriteString("DISTINCT ")
 						}
@@ -380,6 +420,18 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 			if len(n.groupCols) > 0 {
 				v.observer.attr(name, "group by", colListStr(n.groupCols))
 			}
+			if n.groupingSets != nil {
+				var buf bytes.Buffer
+				for i, set := range n.groupingSets {
+					if i > 0 {
+						buf.WriteByte(' ')
+					}
+					var cols []int
+					set.ForEach(func(c int) { cols = append(cols, c) })
+					fmt.Fprintf(&buf, "(%s)", colListStr(cols))
+				}
+				v.observer.attr(name, "grouping sets", buf.String())
+			}
 			if len(n.orderedGroupCols) > 0 {
 				v.observer.attr(name, "ordered", colListStr(n.orderedGroupCols))
 			}
@@ -519,6 +571,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 		if v.observer.attr != nil {
 			v.observer.attr(name, "query", tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable))
 		}
+		if n.sourcePlan != nil {
+			n.sourcePlan = v.visit(n.sourcePlan)
+		}
 
 	case *setVarNode:
 		if v.observer.expr != nil {
@@ -677,76 +732,87 @@ func colListStr(cols []int) string {
 // strings are constant and not precomputed so that the type names can
 // be changed without changing the output of "EXPLAIN".
 var planNodeNames = map[reflect.Type]string{
-	reflect.TypeOf(&alterIndexNode{}):           "alter index",
-	reflect.TypeOf(&alterSequenceNode{}):        "alter sequence",
-	reflect.TypeOf(&alterTableNode{}):           "alter table",
-	reflect.TypeOf(&alterUserSetPasswordNode{}): "alter user",
-	reflect.TypeOf(&commentOnColumnNode{}):      "comment on column",
-	reflect.TypeOf(&commentOnDatabaseNode{}):    "comment on database",
-	reflect.TypeOf(&commentOnTableNode{}):       "comment on table",
-	reflect.TypeOf(&cancelQueriesNode{}):        "cancel queries",
-	reflect.TypeOf(&cancelSessionsNode{}):       "cancel sessions",
-	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
-	reflect.TypeOf(&createDatabaseNode{}):       "create database",
-	reflect.TypeOf(&createIndexNode{}):          "create index",
-	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
-	reflect.TypeOf(&createStatsNode{}):          "create statistics",
-	reflect.TypeOf(&createTableNode{}):          "create table",
-	reflect.TypeOf(&CreateUserNode{}):           "create user/role",
-	reflect.TypeOf(&createViewNode{}):           "create view",
-	reflect.TypeOf(&delayedNode{}):              "virtual table",
-	reflect.TypeOf(&deleteNode{}):               "delete",
-	reflect.TypeOf(&distinctNode{}):             "distinct",
-	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
-	reflect.TypeOf(&dropIndexNode{}):            "drop index",
-	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
-	reflect.TypeOf(&dropTableNode{}):            "drop table",
-	reflect.TypeOf(&DropUserNode{}):             "drop user/role",
-	reflect.TypeOf(&dropViewNode{}):             "drop view",
-	reflect.TypeOf(&explainDistSQLNode{}):       "explain distsql",
-	reflect.TypeOf(&explainPlanNode{}):          "explain plan",
-	reflect.TypeOf(&filterNode{}):               "filter",
-	reflect.TypeOf(&groupNode{}):                "group",
-	reflect.TypeOf(&hookFnNode{}):               "plugin",
-	reflect.TypeOf(&indexJoinNode{}):            "index-join",
-	reflect.TypeOf(&insertNode{}):               "insert",
-	reflect.TypeOf(&joinNode{}):                 "join",
-	reflect.TypeOf(&limitNode{}):                "limit",
-	reflect.TypeOf(&lookupJoinNode{}):           "lookup-join",
-	reflect.TypeOf(&max1RowNode{}):              "max1row",
-	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
-	reflect.TypeOf(&projectSetNode{}):           "project set",
-	reflect.TypeOf(&relocateNode{}):             "relocate",
-	reflect.TypeOf(&renameColumnNode{}):         "rename column",
-	reflect.TypeOf(&renameDatabaseNode{}):       "rename database",
-	reflect.TypeOf(&renameIndexNode{}):          "rename index",
-	reflect.TypeOf(&renameTableNode{}):          "rename table",
-	reflect.TypeOf(&renderNode{}):               "render",
-	reflect.TypeOf(&rowCountNode{}):             "count",
-	reflect.TypeOf(&rowSourceToPlanNode{}):      "row source to plan node",
-	reflect.TypeOf(&scanNode{}):                 "scan",
-	reflect.TypeOf(&scatterNode{}):              "scatter",
-	reflect.TypeOf(&scrubNode{}):                "scrub",
-	reflect.TypeOf(&sequenceSelectNode{}):       "sequence select",
-	reflect.TypeOf(&serializeNode{}):            "run",
-	reflect.TypeOf(&setClusterSettingNode{}):    "set cluster setting",
-	reflect.TypeOf(&setVarNode{}):               "set",
-	reflect.TypeOf(&setZoneConfigNode{}):        "configure zone",
-	reflect.TypeOf(&showFingerprintsNode{}):     "showFingerprints",
-	reflect.TypeOf(&showTraceNode{}):            "show trace for",
-	reflect.TypeOf(&showTraceReplicaNode{}):     "replica trace",
-	reflect.TypeOf(&showZoneConfigNode{}):       "show zone configuration",
-	reflect.TypeOf(&sortNode{}):                 "sort",
-	reflect.TypeOf(&splitNode{}):                "split",
-	reflect.TypeOf(&spoolNode{}):                "spool",
-	reflect.TypeOf(&truncateNode{}):             "truncate",
-	reflect.TypeOf(&unaryNode{}):                "emptyrow",
-	reflect.TypeOf(&unionNode{}):                "union",
-	reflect.TypeOf(&updateNode{}):               "update",
-	reflect.TypeOf(&upsertNode{}):               "upsert",
-	reflect.TypeOf(&valuesNode{}):               "values",
-	reflect.TypeOf(&virtualTableNode{}):         "virtual table values",
-	reflect.TypeOf(&windowNode{}):               "window",
-	reflect.TypeOf(&zeroNode{}):                 "norows",
-	reflect.TypeOf(&zigzagJoinNode{}):           "zigzag-join",
+	reflect.TypeOf(&alterIndexNode{}):              "alter index",
+	reflect.TypeOf(&alterSequenceNode{}):           "alter sequence",
+	reflect.TypeOf(&alterTableNode{}):              "alter table",
+	reflect.TypeOf(&alterTypeAddValueNode{}):       "alter type",
+	reflect.TypeOf(&alterUserSetPasswordNode{}):    "alter user",
+	reflect.TypeOf(&applyJoinNode{}):               "apply-join",
+	reflect.TypeOf(&bufferNode{}):                  "buffer node",
+	reflect.TypeOf(&commentOnColumnNode{}):         "comment on column",
+	reflect.TypeOf(&commentOnDatabaseNode{}):       "comment on database",
+	reflect.TypeOf(&commentOnTableNode{}):          "comment on table",
+	reflect.TypeOf(&cancelQueriesNode{}):           "cancel queries",
+	reflect.TypeOf(&cancelSessionsNode{}):          "cancel sessions",
+	reflect.TypeOf(&controlJobsNode{}):             "control jobs",
+	reflect.TypeOf(&createDatabaseNode{}):          "create database",
+	reflect.TypeOf(&createIndexNode{}):             "create index",
+	reflect.TypeOf(&createSequenceNode{}):          "create sequence",
+	reflect.TypeOf(&createSchemaNode{}):            "create schema",
+	reflect.TypeOf(&createStatsNode{}):             "create statistics",
+	reflect.TypeOf(&createTableNode{}):             "create table",
+	reflect.TypeOf(&createTypeNode{}):              "create type",
+	reflect.TypeOf(&CreateUserNode{}):              "create user/role",
+	reflect.TypeOf(&createViewNode{}):              "create view",
+	reflect.TypeOf(&delayedNode{}):                 "virtual table",
+	reflect.TypeOf(&deleteNode{}):                  "delete",
+	reflect.TypeOf(&distinctNode{}):                "distinct",
+	reflect.TypeOf(&dropDatabaseNode{}):            "drop database",
+	reflect.TypeOf(&dropSchemaNode{}):              "drop schema",
+	reflect.TypeOf(&dropIndexNode{}):               "drop index",
+	reflect.TypeOf(&dropSequenceNode{}):            "drop sequence",
+	reflect.TypeOf(&dropTableNode{}):               "drop table",
+	reflect.TypeOf(&DropUserNode{}):                "drop user/role",
+	reflect.TypeOf(&dropViewNode{}):                "drop view",
+	reflect.TypeOf(&errorIfRowsNode{}):             "error if rows",
+	reflect.TypeOf(&explainDistSQLNode{}):          "explain distsql",
+	reflect.TypeOf(&explainPlanNode{}):             "explain plan",
+	reflect.TypeOf(&filterNode{}):                  "filter",
+	reflect.TypeOf(&groupNode{}):                   "group",
+	reflect.TypeOf(&hookFnNode{}):                  "plugin",
+	reflect.TypeOf(&indexJoinNode{}):               "index-join",
+	reflect.TypeOf(&insertNode{}):                  "insert",
+	reflect.TypeOf(&joinNode{}):                    "join",
+	reflect.TypeOf(&limitNode{}):                   "limit",
+	reflect.TypeOf(&lookupJoinNode{}):              "lookup-join",
+	reflect.TypeOf(&max1RowNode{}):                 "max1row",
+	reflect.TypeOf(&ordinalityNode{}):              "ordinality",
+	reflect.TypeOf(&projectSetNode{}):              "project set",
+	reflect.TypeOf(&recursiveCTENode{}):            "recursive cte node",
+	reflect.TypeOf(&refreshMaterializedViewNode{}): "refresh materialized view",
+	reflect.TypeOf(&relocateNode{}):                "relocate",
+	reflect.TypeOf(&renameColumnNode{}):            "rename column",
+	reflect.TypeOf(&renameDatabaseNode{}):          "rename database",
+	reflect.TypeOf(&renameIndexNode{}):             "rename index",
+	reflect.TypeOf(&renameTableNode{}):             "rename table",
+	reflect.TypeOf(&renderNode{}):                  "render",
+	reflect.TypeOf(&rowCountNode{}):                "count",
+	reflect.TypeOf(&rowSourceToPlanNode{}):         "row source to plan node",
+	reflect.TypeOf(&scanBufferNode{}):              "scan buffer node",
+	reflect.TypeOf(&scanNode{}):                    "scan",
+	reflect.TypeOf(&scatterNode{}):                 "scatter",
+	reflect.TypeOf(&scrubNode{}):                   "scrub",
+	reflect.TypeOf(&sequenceSelectNode{}):          "sequence select",
+	reflect.TypeOf(&serializeNode{}):               "run",
+	reflect.TypeOf(&setClusterSettingNode{}):       "set cluster setting",
+	reflect.TypeOf(&setConstraintsNode{}):          "set constraints",
+	reflect.TypeOf(&setVarNode{}):                  "set",
+	reflect.TypeOf(&setZoneConfigNode{}):           "configure zone",
+	reflect.TypeOf(&showFingerprintsNode{}):        "showFingerprints",
+	reflect.TypeOf(&showTraceNode{}):               "show trace for",
+	reflect.TypeOf(&showTraceReplicaNode{}):        "replica trace",
+	reflect.TypeOf(&showZoneConfigNode{}):          "show zone configuration",
+	reflect.TypeOf(&sortNode{}):                    "sort",
+	reflect.TypeOf(&splitNode{}):                   "split",
+	reflect.TypeOf(&spoolNode{}):                   "spool",
+	reflect.TypeOf(&truncateNode{}):                "truncate",
+	reflect.TypeOf(&unaryNode{}):                   "emptyrow",
+	reflect.TypeOf(&unionNode{}):                   "union",
+	reflect.TypeOf(&updateNode{}):                  "update",
+	reflect.TypeOf(&upsertNode{}):                  "upsert",
+	reflect.TypeOf(&valuesNode{}):                  "values",
+	reflect.TypeOf(&virtualTableNode{}):            "virtual table values",
+	reflect.TypeOf(&windowNode{}):                  "window",
+	reflect.TypeOf(&zeroNode{}):                    "norows",
+	reflect.TypeOf(&zigzagJoinNode{}):              "zigzag-join",
 }
@@ -17,8 +17,10 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -66,6 +68,20 @@ func (e cteNameEnvironment) pop() cteNameEnvironment {
 	return e[:len(e)-1]
 }
 
+// numUsed returns the number of CTEs in the environment that have been used as
+// a statement source.
+func (e cteNameEnvironment) numUsed() int {
+	var n int
+	for _, frame := range e {
+		for _, src := range frame {
+			if src.used {
+				n++
+			}
+		}
+	}
+	return n
+}
+
 func popCteNameEnvironment(p *planner) error {
 	e := p.curPlan.cteNameEnvironment
 	for alias, src := range e[len(e)-1] {
@@ -114,7 +130,13 @@ func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planne
 					"WITH query name %s specified more than once",
 					cte.Name.Alias)
 			}
-			ctePlan, err := p.newPlan(ctx, cte.Stmt, nil)
+			var ctePlan planNode
+			var err error
+			if with.Recursive {
+				ctePlan, err = p.newRecursiveCTEPlan(ctx, cte)
+			} else {
+				ctePlan, err = p.newPlan(ctx, cte.Stmt, nil)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -125,6 +147,105 @@ func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planne
 	return nil, nil
 }
 
+// newRecursiveCTEPlan plans a common table expression of a WITH RECURSIVE
+// clause. A CTE of the form `<initial> UNION [ALL] <recursive>`, where the
+// recursive term refers to the CTE itself, is planned as a recursiveCTENode.
+// In the recursive term, the CTE refers to the working table of the node. The
+// recursive term is planned anew for each iteration, as plans can only be run
+// once. Other CTEs are planned as regular CTEs.
+func (p *planner) newRecursiveCTEPlan(ctx context.Context, cte *tree.CTE) (planNode, error) {
+	initial, recursive, all, ok := cte.RecursiveTerms()
+	if !ok {
+		return p.newPlan(ctx, cte.Stmt, nil)
+	}
+
+	initialPlan, err := p.newPlan(ctx, initial, nil)
+	if err != nil {
+		return nil, err
+	}
+	cols := planColumns(initialPlan)
+	desiredTypes := make([]types.T, len(cols))
+	for i := range cols {
+		desiredTypes[i] = cols[i].Typ
+	}
+
+	name := cte.Name.Alias
+	node := &recursiveCTENode{
+		initial:     initialPlan,
+		label:       string(name),
+		deduplicate: !all,
+	}
+
+	// planRecursive plans the recursive term in the given naming environment,
+	// extended with the working table of the node. It also returns whether the
+	// recursive term refers to the working table.
+	planRecursive := func(env cteNameEnvironment) (planNode, bool, error) {
+		frame := cteNameEnvironmentFrame{name: cteSource{
+			plan: &scanBufferNode{
+				buffer:  node,
+				label:   string(name),
+				columns: append(sqlbase.ResultColumns(nil), cols...),
+			},
+			alias: cte.Name,
+		}}
+		defer func(e cteNameEnvironment) { p.curPlan.cteNameEnvironment = e }(p.curPlan.cteNameEnvironment)
+		p.curPlan.cteNameEnvironment = env.push(frame)
+		plan, err := p.newPlan(ctx, recursive, desiredTypes)
+		return plan, frame[name].used, err
+	}
+
+	numUsed := p.curPlan.cteNameEnvironment.numUsed()
+	numSubqueries := len(p.curPlan.subqueryPlans)
+	recursivePlan, isRecursive, err := planRecursive(p.curPlan.cteNameEnvironment)
+	if err != nil {
+		return nil, err
+	}
+	if !isRecursive {
+		// The CTE does not refer to itself; there is nothing recursive about it.
+		return p.newUnionNode(tree.UnionOp, all, initialPlan, recursivePlan)
+	}
+	// The plan of the recursive term is only used to validate it; the plans of
+	// the iterations are created at execution time.
+	defer recursivePlan.Close(ctx)
+
+	// The plans of the iterations are created after the naming environment of
+	// the statement is gone, and their subqueries would never be run.
+	if p.curPlan.cteNameEnvironment.numUsed() != numUsed {
+		return nil, pgerror.UnimplementedWithIssueError(21085,
+			"references to other WITH queries in the recursive query of WITH RECURSIVE "+
+				"are only supported by the cost-based optimizer")
+	}
+	if len(p.curPlan.subqueryPlans) != numSubqueries {
+		return nil, pgerror.UnimplementedWithIssueError(21085,
+			"subqueries in the recursive query of WITH RECURSIVE are only supported by the "+
+				"cost-based optimizer")
+	}
+
+	recursiveCols := planColumns(recursivePlan)
+	if len(recursiveCols) != len(cols) {
+		return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"each UNION query must have the same number of columns: %d vs %d",
+			len(cols), len(recursiveCols))
+	}
+	for i := range cols {
+		l, r := cols[i].Typ, recursiveCols[i].Typ
+		if !(l.Equivalent(r) || r == types.Unknown) {
+			return nil, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+				name, i+1, l, r)
+		}
+	}
+
+	node.genIterationFn = func(exec.Node) (exec.Node, error) {
+		plan, _, err := planRecursive(nil /* env */)
+		if err != nil {
+			return nil, err
+		}
+		return p.optimizePlan(ctx, plan, allColumns(plan))
+	}
+	return node, nil
+}
+
 // getCTEDataSource looks up the table name in the planner's CTE name
 // environment, returning the planDataSource corresponding to the CTE if it was
 // found. The second return parameter returns true if a CTE was found.
@@ -141,6 +262,11 @@ func (p *planner) getCTEDataSource(tn *tree.TableName) (planDataSource, bool, er
 	for i := len(env) - 1; i >= 0; i-- {
 		frame := p.curPlan.cteNameEnvironment[i]
 		if cteSource, ok := frame[tn.TableName]; ok {
+			if _, ok := cteSource.plan.(*scanBufferNode); ok && cteSource.used {
+				// The CTE refers to the working table of a recursive CTE.
+				return planDataSource{}, false, pgerror.NewErrorf(pgerror.CodeInvalidRecursionError,
+					"recursive reference to query %q must not appear more than once", tree.ErrString(tn))
+			}
 			if cteSource.used {
 				// TODO(jordan): figure out how to lift this restriction.
 				// CTE expressions that are used more than once will need to be
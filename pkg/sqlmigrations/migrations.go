@@ -761,6 +761,9 @@ func upgradeDescsWithFn(
 						}
 					}
 
+				case *sqlbase.Descriptor_Type, *sqlbase.Descriptor_Schema:
+					// Type and schema descriptors are not upgraded.
+
 				default:
 					return errors.Errorf("Descriptor.Union has unexpected type %T", t)
 				}
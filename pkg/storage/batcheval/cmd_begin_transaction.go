@@ -105,6 +105,20 @@ func BeginTransaction(
 				return result.Result{}, nil
 			}
 
+		case roachpb.STAGING:
+			if h.Txn.Epoch > tmpTxn.Epoch {
+				// The transaction attempted a parallel commit in an earlier
+				// epoch but failed to commit and restarted. Its record moves
+				// back to the PENDING status and its in-flight writes from the
+				// earlier epoch are forgotten.
+				reply.Txn.Update(&tmpTxn)
+				reply.Txn.Status = roachpb.PENDING
+				reply.Txn.InFlightWrites = nil
+			} else {
+				// See the PENDING case above.
+				return result.Result{}, nil
+			}
+
 		case roachpb.COMMITTED:
 			return result.Result{}, roachpb.NewTransactionStatusError(
 				fmt.Sprintf("BeginTransaction can't overwrite %s", tmpTxn),
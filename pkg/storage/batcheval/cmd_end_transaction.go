@@ -236,7 +236,10 @@ func evalEndTransaction(
 			return result.FromEndTxn(reply.Txn, true /* alwaysReturn */, args.Poison),
 				roachpb.NewTransactionAbortedError(roachpb.ABORT_REASON_ABORTED_RECORD_FOUND)
 
-		case roachpb.PENDING:
+		case roachpb.PENDING, roachpb.STAGING:
+			// A STAGING record is rewritten by a parallel commit that is retried
+			// at a higher timestamp or epoch and is committed explicitly once
+			// all of its in-flight writes have succeeded.
 			if h.Txn.Epoch < reply.Txn.Epoch {
 				// TODO(tschottdorf): this leaves the Txn record (and more
 				// importantly, intents) dangling; we can't currently write on
@@ -245,10 +248,13 @@ func evalEndTransaction(
 				return result.Result{}, roachpb.NewTransactionStatusError(
 					fmt.Sprintf("epoch regression: %d", h.Txn.Epoch),
 				)
-			} else if h.Txn.Epoch == reply.Txn.Epoch && reply.Txn.Timestamp.Less(h.Txn.OrigTimestamp) {
+			} else if h.Txn.Epoch == reply.Txn.Epoch && h.Txn.Isolation == enginepb.SERIALIZABLE &&
+				reply.Txn.Timestamp.Less(h.Txn.OrigTimestamp) {
 				// The transaction record can only ever be pushed forward, so it's an
 				// error if somehow the transaction record has an earlier timestamp
-				// than the original transaction timestamp.
+				// than the original transaction timestamp. READ_COMMITTED
+				// transactions are exempt, as they advance their original timestamp
+				// before each statement.
 
 				// TODO(tschottdorf): see above comment on epoch regression.
 				return result.Result{}, roachpb.NewTransactionStatusError(
@@ -291,6 +297,9 @@ func evalEndTransaction(
 				"transaction deadline exceeded")
 		}
 
+		if args.IsParallelCommit() {
+			return evalStagingEndTransaction(ctx, batch, cArgs, key, reply)
+		}
 		reply.Txn.Status = roachpb.COMMITTED
 
 		// Merge triggers must run before intent resolution as the merge trigger
@@ -316,6 +325,7 @@ func evalEndTransaction(
 	} else {
 		reply.Txn.Status = roachpb.ABORTED
 	}
+	reply.Txn.InFlightWrites = nil
 
 	desc := cArgs.EvalCtx.Desc()
 	externalIntents, err := resolveLocalIntents(ctx, desc, batch, ms, *args, reply.Txn, cArgs.EvalCtx)
@@ -368,6 +378,36 @@ func evalEndTransaction(
 	return pd, nil
 }
 
+// evalStagingEndTransaction moves the transaction into the STAGING status on
+// behalf of a parallel commit. The transaction is implicitly committed once
+// all of its in-flight writes have succeeded at or below the timestamp of its
+// STAGING record, at which point its coordinator commits it explicitly. Until
+// then, none of its intents can be resolved and no commit triggers are run.
+func evalStagingEndTransaction(
+	ctx context.Context,
+	batch engine.ReadWriter,
+	cArgs CommandArgs,
+	key roachpb.Key,
+	reply *roachpb.EndTransactionResponse,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.EndTransactionRequest)
+	if args.InternalCommitTrigger != nil {
+		return result.Result{}, errors.Errorf(
+			"cannot perform parallel commit with commit trigger: %s", reply.Txn)
+	}
+	reply.Txn.Status = roachpb.STAGING
+	reply.Txn.InFlightWrites = args.InFlightWrites
+	reply.Txn.Intents = args.IntentSpans
+	reply.StagingTimestamp = reply.Txn.Timestamp
+	txnRecord := reply.Txn.AsRecord()
+	if err := engine.MVCCPutProto(
+		ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil /* txn */, &txnRecord,
+	); err != nil {
+		return result.Result{}, err
+	}
+	return result.Result{}, nil
+}
+
 // IsEndTransactionExceedingDeadline returns true if the transaction
 // exceeded its deadline.
 func IsEndTransactionExceedingDeadline(t hlc.Timestamp, args roachpb.EndTransactionRequest) bool {
@@ -390,8 +430,10 @@ func IsEndTransactionTriggeringRetryError(
 		isTxnPushed := txn.Timestamp != origTimestamp
 
 		// Return a transaction retry error if the commit timestamp isn't equal to
-		// the txn timestamp.
-		if isTxnPushed {
+		// the txn timestamp. READ_COMMITTED transactions can commit above the
+		// timestamp of their reads without refreshing them, unless that
+		// timestamp has been observed and thus must be the commit timestamp.
+		if isTxnPushed && (txn.Isolation == enginepb.SERIALIZABLE || txn.OrigTimestampWasObserved) {
 			retry, reason = true, roachpb.RETRY_SERIALIZABLE
 		}
 	}
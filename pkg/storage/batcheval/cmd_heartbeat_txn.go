@@ -77,7 +77,9 @@ func HeartbeatTxn(
 		}
 	}
 
-	if txn.Status == roachpb.PENDING {
+	// STAGING transactions continue to be heartbeat by their coordinator
+	// until they are explicitly committed.
+	if !txn.Status.IsFinalized() {
 		txn.LastHeartbeat.Forward(args.Now)
 		txnRecord := txn.AsRecord()
 		if err := engine.MVCCPutProto(ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil, &txnRecord); err != nil {
@@ -69,6 +69,15 @@ func declareKeysPushTransaction(
 // Txn already committed/aborted: If the pushee txn is committed or
 // aborted return success.
 //
+// Txn staging: If the pushee txn is STAGING, it may already be
+// implicitly committed, which the pushee's record alone cannot tell.
+// The push proceeds as for a pending txn up to the point where the
+// pusher would win, at which point an IndeterminateCommitError is
+// returned instead. The pusher must then run the transaction recovery
+// procedure, which determines whether all of the pushee's in-flight
+// writes succeeded and sends a second PushTxn with RecoverStaging set
+// to finalize the pushee's record accordingly.
+//
 // Txn record expired: If the pushee txn is pending, its last
 // heartbeat timestamp is observed to determine the latest client
 // activity. This heartbeat is forwarded by the conflicting intent's
@@ -166,6 +175,9 @@ func PushTxn(
 			result.Local.UpdatedTxns = &[]*roachpb.Transaction{&reply.PusheeTxn}
 			return result, nil
 		}
+	} else if args.RecoverStaging {
+		// The pusher has determined the outcome of a STAGING pushee.
+		return recoverStagingTxn(ctx, batch, cArgs, reply, key, existTxn)
 	} else {
 		// Start with the persisted transaction record.
 		reply.PusheeTxn = existTxn.Clone()
@@ -177,10 +189,15 @@ func PushTxn(
 	}
 
 	// If already committed or aborted, return success.
-	if reply.PusheeTxn.Status != roachpb.PENDING {
+	if reply.PusheeTxn.Status.IsFinalized() {
 		// Trivial noop.
 		return result.Result{}, nil
 	}
+	if args.RecoverStaging {
+		// The pushee's record does not exist and cannot be written, so the
+		// pushee is not implicitly committed and has been finalized above.
+		return result.Result{}, errors.Errorf("cannot recover missing txn record %s", args.PusheeTxn)
+	}
 
 	// If we're trying to move the timestamp forward, and it's already
 	// far enough forward, return success.
@@ -236,6 +253,17 @@ func PushTxn(
 		return result.Result{}, err
 	}
 
+	// A STAGING pushee may already be implicitly committed, in which case
+	// aborting it or pushing its timestamp would be incorrect. Let the pusher
+	// determine the outcome of the pushee instead.
+	if reply.PusheeTxn.Status == roachpb.STAGING {
+		err := roachpb.NewIndeterminateCommitError(*existTxn)
+		if log.V(1) {
+			log.Infof(ctx, "%v", err)
+		}
+		return result.Result{}, err
+	}
+
 	// Upgrade priority of pushed transaction to one less than pusher's.
 	reply.PusheeTxn.UpgradePriority(args.PusherTxn.Priority - 1)
 
@@ -285,3 +313,59 @@ func PushTxn(
 	result.Local.UpdatedTxns = &[]*roachpb.Transaction{&reply.PusheeTxn}
 	return result, nil
 }
+
+// recoverStagingTxn finalizes the transaction record of a STAGING pushee on
+// behalf of the transaction recovery procedure. See PushTxnRequest's
+// RecoverStaging field.
+func recoverStagingTxn(
+	ctx context.Context,
+	batch engine.ReadWriter,
+	cArgs CommandArgs,
+	reply *roachpb.PushTxnResponse,
+	key roachpb.Key,
+	existTxn *roachpb.Transaction,
+) (result.Result, error) {
+	args := cArgs.Args.(*roachpb.PushTxnRequest)
+	reply.PusheeTxn = existTxn.Clone()
+
+	switch reply.PusheeTxn.Status {
+	case roachpb.COMMITTED, roachpb.ABORTED:
+		// Already recovered or finalized by its coordinator.
+		return result.Result{}, nil
+	case roachpb.PENDING:
+		if args.ImplicitlyCommitted {
+			return result.Result{}, errors.Errorf(
+				"found PENDING record for implicitly committed txn %s", reply.PusheeTxn)
+		}
+		// The pushee has restarted since the recovery procedure inspected it.
+		return result.Result{}, nil
+	}
+
+	// If the pushee has re-staged at a higher epoch or timestamp, the
+	// in-flight writes inspected by the recovery procedure say nothing about
+	// its current outcome.
+	if args.PusheeTxn.Epoch < reply.PusheeTxn.Epoch ||
+		args.PusheeTxn.Timestamp.Less(reply.PusheeTxn.Timestamp) {
+		return result.Result{}, nil
+	}
+
+	if args.ImplicitlyCommitted {
+		reply.PusheeTxn.Status = roachpb.COMMITTED
+	} else {
+		reply.PusheeTxn.Status = roachpb.ABORTED
+		// Forward the timestamp to accommodate AbortSpan GC, as is done when
+		// a push aborts a pushee.
+		reply.PusheeTxn.Timestamp.Forward(reply.PusheeTxn.LastActive())
+	}
+	reply.PusheeTxn.InFlightWrites = nil
+	txnRecord := reply.PusheeTxn.AsRecord()
+	if err := engine.MVCCPutProto(
+		ctx, batch, cArgs.Stats, key, hlc.Timestamp{}, nil /* txn */, &txnRecord,
+	); err != nil {
+		return result.Result{}, err
+	}
+
+	result := result.Result{}
+	result.Local.UpdatedTxns = &[]*roachpb.Transaction{&reply.PusheeTxn}
+	return result, nil
+}
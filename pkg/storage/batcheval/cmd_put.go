@@ -48,6 +48,14 @@ func Put(
 			defer batch.Close()
 		}
 	}
+	if args.LockOnly {
+		if h.Txn == nil {
+			// A transaction that commits in one phase holds no locks after it
+			// evaluates, so there is nothing to lock.
+			return result.Result{}, nil
+		}
+		return result.Result{}, engine.MVCCPutLock(ctx, batch, ms, args.Key, ts, h.Txn)
+	}
 	if args.Blind {
 		return result.Result{}, engine.MVCCBlindPut(ctx, batch, ms, args.Key, ts, args.Value, h.Txn)
 	}
@@ -101,6 +101,9 @@ func QueryIntent(
 		case roachpb.QueryIntentRequest_PREVENT:
 			// The intent will be prevented by bumping the timestamp cache for
 			// the key to the txn timestamp in Replica.updateTimestampCache.
+			// The transaction recovery procedure relies on this to ensure
+			// that a STAGING transaction's missing in-flight write can never
+			// succeed at or below the transaction's staging timestamp.
 		default:
 			return result.Result{},
 				errors.Errorf("unexpected QueryIntent IfMissing behavior %v", args.IfMissing)
@@ -28,6 +28,16 @@ var TargetDuration = settings.RegisterNonNegativeDurationSetting(
 	30*time.Second,
 )
 
+// FollowerReadsEnabled controls whether replicas attempt to serve follower
+// reads. The closed timestamp machinery is unaffected by this, i.e. the same
+// information is collected and passed around, regardless of the value of this
+// setting.
+var FollowerReadsEnabled = settings.RegisterBoolSetting(
+	"kv.closed_timestamp.follower_reads_enabled",
+	"allow (all) replicas to serve consistent historical reads based on closed timestamp information",
+	false,
+)
+
 // CloseFraction is the fraction of TargetDuration determining how often closed
 // timestamp updates are to be attempted.
 var CloseFraction = settings.RegisterValidatedFloatSetting(
@@ -40,3 +50,21 @@ var CloseFraction = settings.RegisterValidatedFloatSetting(
 		}
 		return nil
 	})
+
+// followerReadMultiple is the number of closed timestamp update intervals by
+// which follower reads trail the target closed timestamp, to account for the
+// delay with which closed timestamp updates reach the followers.
+const followerReadMultiple = 3
+
+// FollowerReadOffset returns how far in the past a read must be for it to be
+// expected to be servable by any replica: closed timestamps trail the present
+// by approximately TargetDuration and are advanced every CloseFraction of it.
+// Zero is returned if closed timestamps are disabled, in which case no read can
+// be served by a follower.
+func FollowerReadOffset(sv *settings.Values) time.Duration {
+	target := TargetDuration.Get(sv)
+	if target == 0 {
+		return 0
+	}
+	return time.Duration(float64(target) * (1 + followerReadMultiple*CloseFraction.Get(sv)))
+}
@@ -132,6 +132,11 @@ func (meta MVCCMetadata) IsInline() bool {
 	return meta.RawBytes != nil
 }
 
+// IsLockOnly returns true if the metadata describes a lock-only intent.
+func (meta MVCCMetadata) IsLockOnly() bool {
+	return meta.LockOnly != nil && *meta.LockOnly
+}
+
 // AddToIntentHistory adds the sequence and value to the intent history.
 func (meta *MVCCMetadata) AddToIntentHistory(seq int32, val []byte) {
 	meta.IntentHistory = append(meta.IntentHistory,
@@ -161,3 +166,14 @@ func (meta *MVCCMetadata) GetIntentValue(seq int32) ([]byte, bool) {
 	}
 	return nil, false
 }
+
+// TxnSeqIsIgnored returns true if the given sequence number falls in one of
+// the given ranges of ignored sequence numbers.
+func TxnSeqIsIgnored(seq int32, ignored []IgnoredSeqNumRange) bool {
+	for _, r := range ignored {
+		if r.Start <= seq && seq <= r.End {
+			return true
+		}
+	}
+	return false
+}
@@ -1074,6 +1074,55 @@ func MVCCDelete(
 
 var noValue = roachpb.Value{}
 
+// MVCCPutLock acquires a lock on the specified key for the transaction by
+// writing a lock-only intent. A lock-only intent holds the key's existing
+// value, so the transaction continues to read the key as before, and it is
+// removed without leaving a new version of the key when the transaction is
+// resolved. Locking a key on which the transaction already wrote an intent
+// in its current epoch rewrites the intent's value at the txn's sequence and
+// leaves it a regular intent.
+//
+// Note that, as with MVCCPut, the txn's timestamps dictate the timestamp of
+// the operation, and the timestamp parameter must equal the txn's read
+// timestamp. See the comment on mvccPutInternal for details.
+func MVCCPutLock(
+	ctx context.Context,
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+) error {
+	if txn == nil {
+		return errors.Errorf("%q: locks can only be acquired within transactions", key)
+	}
+	iter := engine.NewIterator(IterOptions{Prefix: true})
+	defer iter.Close()
+
+	buf := newPutBuffer()
+	defer buf.release()
+	metaKey := MakeMVCCMetadataKey(key)
+	ok, _, _, err := mvccGetMetadata(iter, metaKey, &buf.meta)
+	if err != nil {
+		return err
+	}
+	lockOnly := true
+	if ok && buf.meta.Txn != nil && buf.meta.Txn.ID == txn.ID && buf.meta.Txn.Epoch == txn.Epoch {
+		// The transaction already holds an intent on the key. The intent is
+		// still rewritten at the txn's sequence so that the lock can be
+		// verified like any other write, but it must retain its value if it
+		// isn't lock-only.
+		lockOnly = buf.meta.IsLockOnly()
+	}
+	valueFn := func(existVal *roachpb.Value) ([]byte, error) {
+		if existVal == nil {
+			return nil, nil
+		}
+		return existVal.RawBytes, nil
+	}
+	return mvccPutInternal(ctx, engine, iter, ms, key, timestamp, nil, txn, buf, valueFn, lockOnly)
+}
+
 // mvccPutUsingIter sets the value for a specified key using the provided
 // Iterator. The function takes a value and a valueFn, only one of which
 // should be provided. If the valueFn is nil, value's raw bytes will be set
@@ -1100,7 +1149,7 @@ func mvccPutUsingIter(
 	buf := newPutBuffer()
 
 	err := mvccPutInternal(ctx, engine, iter, ms, key, timestamp, rawBytes,
-		txn, buf, valueFn)
+		txn, buf, valueFn, false /* lockOnly */)
 
 	// Using defer would be more convenient, but it is measurably slower.
 	buf.release()
@@ -1243,7 +1292,8 @@ func replayTransactionalWrite(
 // the existing value (or nil if none exists) and returns the value
 // to write or an error. If valueFn is supplied, value should be nil
 // and vice versa. valueFn can delete by returning nil. Returning
-// []byte{} will write an empty value, not delete.
+// []byte{} will write an empty value, not delete. If lockOnly is set,
+// the intent is marked as a lock-only intent; see MVCCPutLock.
 //
 // Note that, when writing transactionally, the txn's timestamps
 // dictate the timestamp of the operation, and the timestamp parameter
@@ -1277,6 +1327,7 @@ func mvccPutInternal(
 	txn *roachpb.Transaction,
 	buf *putBuffer,
 	valueFn func(*roachpb.Value) ([]byte, error),
+	lockOnly bool,
 ) error {
 	if len(key) == 0 {
 		return emptyKeyError()
@@ -1505,6 +1556,9 @@ func mvccPutInternal(
 		}
 		buf.newMeta.Txn = txnMeta
 		buf.newMeta.Timestamp = hlc.LegacyTimestamp(writeTimestamp)
+		if lockOnly && txn != nil {
+			buf.newMeta.LockOnly = &lockOnly
+		}
 	}
 	newMeta := &buf.newMeta
 
@@ -1837,7 +1891,7 @@ func MVCCDeleteRange(
 
 	for i := range kvs {
 		err = mvccPutInternal(
-			ctx, engine, iter, ms, kvs[i].Key, timestamp, nil, txn, buf, nil)
+			ctx, engine, iter, ms, kvs[i].Key, timestamp, nil, txn, buf, nil, false /* lockOnly */)
 		if err != nil {
 			break
 		}
@@ -2063,6 +2117,64 @@ func MVCCIterate(
 	return intents, wiErr
 }
 
+// mvccRollbackIntentToSavepoint reverts the intent described by buf.meta to
+// the latest write in its intent history whose sequence number isn't ignored
+// by the given intent. Returns false if there is no such write, in which case
+// the intent is to be removed entirely.
+func mvccRollbackIntentToSavepoint(
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	metaKey MVCCKey,
+	origMetaKeySize, origMetaValSize int64,
+	intent roachpb.Intent,
+	buf *putBuffer,
+) (bool, error) {
+	meta := &buf.meta
+	i := len(meta.IntentHistory) - 1
+	for ; i >= 0; i-- {
+		if !enginepb.TxnSeqIsIgnored(meta.IntentHistory[i].Sequence, intent.IgnoredSeqNums) {
+			break
+		}
+	}
+	if i < 0 {
+		return false, nil
+	}
+	restored := meta.IntentHistory[i]
+
+	buf.newMeta = *meta
+	newTxn := *meta.Txn
+	newTxn.Sequence = restored.Sequence
+	buf.newMeta.Txn = &newTxn
+	buf.newMeta.IntentHistory = meta.IntentHistory[:i]
+	buf.newMeta.ValBytes = int64(len(restored.Value))
+	buf.newMeta.Deleted = len(restored.Value) == 0
+
+	metaKeySize, metaValSize, err := buf.putMeta(engine, metaKey, &buf.newMeta)
+	if err != nil {
+		return false, err
+	}
+	// The restored value replaces the intent's value at the same timestamp.
+	versionKey := MVCCKey{Key: intent.Key, Timestamp: hlc.Timestamp(meta.Timestamp)}
+	var value []byte
+	if !buf.newMeta.Deleted {
+		value = restored.Value
+	}
+	if err := engine.Put(versionKey, value); err != nil {
+		return false, err
+	}
+	if ms != nil {
+		ms.Add(updateStatsOnPut(intent.Key, 0 /* prevValSize */, origMetaKeySize, origMetaValSize,
+			metaKeySize, metaValSize, meta, &buf.newMeta))
+	}
+
+	engine.LogLogicalOp(MVCCUpdateIntentOpType, MVCCLogicalOpDetails{
+		Txn:       newTxn,
+		Key:       intent.Key,
+		Timestamp: hlc.Timestamp(meta.Timestamp),
+	})
+	return true, nil
+}
+
 // MVCCResolveWriteIntent either commits or aborts (rolls back) an
 // extant write intent for a given txn according to commit parameter.
 // ResolveWriteIntent will skip write intents of other txns.
@@ -2223,7 +2335,24 @@ func mvccResolveWriteIntent(
 	// restart in EndTransaction, so the replay won't resolve intents.
 	epochsMatch := meta.Txn.Epoch == intent.Txn.Epoch
 	timestampsValid := !intent.Txn.Timestamp.Less(hlc.Timestamp(meta.Timestamp))
-	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
+	// A lock-only intent holds the key's existing value and must not leave a
+	// new version behind, so it is removed as if it were aborted, even when
+	// its transaction commits.
+	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid &&
+		!meta.IsLockOnly()
+
+	// If the transaction rolled back the latest write to this key to a
+	// savepoint, revert the intent to its latest write which isn't rolled
+	// back, or remove it altogether if there is none.
+	rolledBack := intent.Status == roachpb.PENDING && epochsMatch &&
+		enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, intent.IgnoredSeqNums)
+	if rolledBack {
+		if ok, err := mvccRollbackIntentToSavepoint(
+			engine, ms, metaKey, origMetaKeySize, origMetaValSize, intent, buf,
+		); err != nil || ok {
+			return ok, err
+		}
+	}
 
 	// Note the small difference to commit epoch handling here: We allow
 	// a push from a previous epoch to move a newer intent. That's not
@@ -2250,7 +2379,7 @@ func mvccResolveWriteIntent(
 	// used for resolving), but that costs latency.
 	// TODO(tschottdorf): various epoch-related scenarios here deserve more
 	// testing.
-	pushed := intent.Status == roachpb.PENDING &&
+	pushed := intent.Status == roachpb.PENDING && !rolledBack &&
 		hlc.Timestamp(meta.Timestamp).Less(intent.Txn.Timestamp) &&
 		meta.Txn.Epoch >= intent.Txn.Epoch
 
@@ -2350,8 +2479,9 @@ func mvccResolveWriteIntent(
 	// - ResolveIntent with epoch 0 aborts intent from epoch 1.
 
 	// There's nothing to do if meta's epoch is greater than or equal txn's epoch
-	// and the state is still PENDING.
-	if intent.Status == roachpb.PENDING && meta.Txn.Epoch >= intent.Txn.Epoch {
+	// and the state is still PENDING, unless all the writes of the intent were
+	// rolled back to a savepoint.
+	if intent.Status == roachpb.PENDING && meta.Txn.Epoch >= intent.Txn.Epoch && !rolledBack {
 		return false, nil
 	}
 
@@ -2921,6 +2921,137 @@ func TestMVCCResolveTxn(t *testing.T) {
 	}
 }
 
+// TestMVCCResolveIntentRolledBackToSavepoint verifies that resolving a
+// PENDING intent with ignored sequence numbers reverts the intent to its
+// latest write which isn't ignored, or removes it if there is none.
+func TestMVCCResolveIntentRolledBackToSavepoint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	txn := *txn1
+	for _, v := range []roachpb.Value{value1, value2, value3} {
+		txn.Sequence++
+		if err := MVCCPut(ctx, engine, nil, testKey1, txn.OrigTimestamp, v, &txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectValue := func(expected *roachpb.Value) {
+		t.Helper()
+		value, _, err := MVCCGet(ctx, engine, testKey1, txn.OrigTimestamp, MVCCGetOptions{Txn: &txn})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected == nil {
+			if value != nil {
+				t.Fatalf("expected no value, found %s", value.RawBytes)
+			}
+			return
+		}
+		if value == nil || !bytes.Equal(expected.RawBytes, value.RawBytes) {
+			t.Fatalf("expected value %s, found %v", expected.RawBytes, value)
+		}
+	}
+	rollback := func(start, end int32) {
+		t.Helper()
+		if err := MVCCResolveWriteIntent(ctx, engine, nil, roachpb.Intent{
+			Span:           roachpb.Span{Key: testKey1},
+			Txn:            txn.TxnMeta,
+			Status:         roachpb.PENDING,
+			IgnoredSeqNums: []enginepb.IgnoredSeqNumRange{{Start: start, End: end}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ignoring writes which aren't the latest one leaves the intent alone.
+	rollback(1, 2)
+	expectValue(&value3)
+
+	// Ignoring the latest writes reverts the intent to the last write
+	// which isn't ignored.
+	rollback(2, 3)
+	expectValue(&value1)
+
+	// Ignoring all the writes removes the intent.
+	rollback(1, 3)
+	expectValue(nil)
+	if ok, _, _, err := engine.GetProto(MakeMVCCMetadataKey(testKey1), &enginepb.MVCCMetadata{}); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatalf("expected the intent on %s to be removed", testKey1)
+	}
+}
+
+// TestMVCCPutLock verifies that a lock-only intent conflicts with other
+// transactions, preserves the key's value for its own transaction and is
+// removed without leaving a new version when its transaction commits.
+func TestMVCCPutLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	engine := createTestEngine()
+	defer engine.Close()
+
+	var ms enginepb.MVCCStats
+	if err := MVCCPut(ctx, engine, &ms, testKey1, hlc.Timestamp{WallTime: 1}, value1, nil); err != nil {
+		t.Fatal(err)
+	}
+	expMS := ms
+
+	txn := makeTxn(*txn1, hlc.Timestamp{WallTime: 2})
+	if err := MVCCPutLock(ctx, engine, &ms, testKey1, txn.OrigTimestamp, nil); !testutils.IsError(
+		err, "locks can only be acquired within transactions",
+	) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := MVCCPutLock(ctx, engine, &ms, testKey1, txn.OrigTimestamp, txn); err != nil {
+		t.Fatal(err)
+	}
+	// Locking the key again at a later sequence leaves it locked.
+	txn.Sequence++
+	if err := MVCCPutLock(ctx, engine, &ms, testKey1, txn.OrigTimestamp, txn); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction reads the key's existing value.
+	value, _, err := MVCCGet(ctx, engine, testKey1, txn.OrigTimestamp, MVCCGetOptions{Txn: txn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || !bytes.Equal(value1.RawBytes, value.RawBytes) {
+		t.Fatalf("expected value %s, found %v", value1.RawBytes, value)
+	}
+
+	// Other transactions conflict with the lock.
+	txn2ts := makeTxn(*txn2, hlc.Timestamp{WallTime: 3})
+	err = MVCCPut(ctx, engine, &ms, testKey1, txn2ts.OrigTimestamp, value2, txn2ts)
+	if _, ok := err.(*roachpb.WriteIntentError); !ok {
+		t.Fatalf("expected WriteIntentError, found %v", err)
+	}
+
+	// Committing the transaction removes the lock without writing a new
+	// version of the key.
+	if err := MVCCResolveWriteIntent(ctx, engine, &ms, roachpb.Intent{
+		Span:   roachpb.Span{Key: testKey1},
+		Txn:    txn.TxnMeta,
+		Status: roachpb.COMMITTED,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	value, _, err = MVCCGet(ctx, engine, testKey1, hlc.Timestamp{WallTime: 4}, MVCCGetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value == nil || value.Timestamp != (hlc.Timestamp{WallTime: 1}) {
+		t.Fatalf("expected the value written at %s, found %v", hlc.Timestamp{WallTime: 1}, value)
+	}
+	assertEq(t, engine, "after commit", &ms, &expMS)
+}
+
 // TestMVCCResolveNewerIntent verifies that resolving a newer intent
 // than the committing transaction aborts the intent.
 func TestMVCCResolveNewerIntent(t *testing.T) {
@@ -16,7 +16,6 @@
 package storage
 
 import (
-	"container/list"
 	"context"
 	"sort"
 	"time"
@@ -26,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/txnwait"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -57,91 +57,10 @@ const (
 	intentResolverBatchSize = 100
 )
 
-type pusher struct {
-	txn      *roachpb.Transaction
-	waitCh   chan *enginepb.TxnMeta
-	detectCh chan struct{}
-}
-
-func newPusher(txn *roachpb.Transaction) *pusher {
-	p := &pusher{
-		txn:    txn,
-		waitCh: make(chan *enginepb.TxnMeta, 1),
-	}
-	if p.activeTxn() {
-		p.detectCh = make(chan struct{}, 1)
-		// Signal the channel in order to begin dependency cycle detection.
-		p.detectCh <- struct{}{}
-	}
-	return p
-}
-
-func (p *pusher) activeTxn() bool {
-	return p.txn != nil && p.txn.Key != nil
-}
-
-type contendedKey struct {
-	ll *list.List
-	// lastTxnMeta is the most recent txn to own the intent. Active
-	// transactions in the contention queue for this intent must push
-	// this txn record in order to detect dependency cycles.
-	lastTxnMeta *enginepb.TxnMeta
-}
-
-func newContendedKey() *contendedKey {
-	return &contendedKey{
-		ll: list.New(),
-	}
-}
-
-// setLastTxnMeta sets the most recent txn meta and sends to all
-// pushers w/ active txns still in the queue to push, to guarantee
-// detection of dependency cycles.
-func (ck *contendedKey) setLastTxnMeta(txnMeta *enginepb.TxnMeta) {
-	ck.lastTxnMeta = txnMeta
-	for e := ck.ll.Front(); e != nil; e = e.Next() {
-		p := e.Value.(*pusher)
-		if p.detectCh != nil {
-			select {
-			case p.detectCh <- struct{}{}:
-			default:
-			}
-		}
-	}
-}
-
-// contentionQueue handles contention on keys with conflicting intents
-// by forming queues of "pushers" which are requests that experienced
-// a WriteIntentError. There is a queue for each key with one or more
-// pushers. Queues are complicated by the difference between pushers
-// with writing transactions (i.e. they may have a transaction record
-// which can be pushed) and non-writing transactions (e.g.,
-// non-transactional requests, and read-only transactions).
-//
-// Queues are linked lists, with each element containing a transaction
-// (can be nil), and a wait channel. The wait channel is closed when
-// the request is dequeued and run to completion, whether to success
-// or failure. Pushers wait on the most recent pusher in the queue to
-// complete. However, pushers with an active transaction (i.e., txns
-// with a non-nil key) must send a PushTxn RPC. This is necessary in
-// order to properly detect dependency cycles.
-type contentionQueue struct {
-	// keys is a map from key to a linked list of pusher instances,
-	// ordered as a FIFO queue.
-	store *Store
-	mu    struct {
-		syncutil.Mutex
-		keys map[string]*contendedKey
-	}
-}
-
-func newContentionQueue(store *Store) *contentionQueue {
-	cq := &contentionQueue{
-		store: store,
-	}
-	cq.mu.keys = map[string]*contendedKey{}
-	return cq
-}
+// dependencyCyclePushDelay is the delay after which a transactional request
+// waiting behind other requests in a lock's wait-queue pushes the lock's
+// holder in order to detect dependency cycles.
+const dependencyCyclePushDelay = 100 * time.Millisecond
 
 func txnID(txn *roachpb.Transaction) string {
 	if txn == nil {
@@ -150,225 +69,142 @@ func txnID(txn *roachpb.Transaction) string {
 	return txn.ID.Short()
 }
 
-// add adds the intent specified in the supplied wiErr to the
-// contention queue. This may block the current goroutine if the
-// pusher has no transaction or the transaction is not yet writing
-// (i.e. read-only or hasn't successfully executed BeginTxn).
+// waitInLockQueue handles contention on a key with a conflicting intent by
+// queueing the request that experienced the supplied WriteIntentError in the
+// wait-queue of the intent's lock in the supplied lock table. If the request
+// is already queued on the same lock from a previous conflict, as specified by
+// the supplied guard, it retains its position in the queue. This blocks the
+// current goroutine until the request reaches the front of the queue.
+//
+// Requests in a wait-queue are complicated by the difference between requests
+// with writing transactions (i.e. they may have a transaction record which
+// can be pushed) and others (e.g., non-transactional requests and read-only
+// transactions). While waiting behind other requests, the former must
+// periodically push the lock's holder in order to properly detect dependency
+// cycles: the txnWaitQueue of the holder's transaction record is what detects
+// distributed deadlocks, and it only knows about the transactions that push.
 //
 // Note that the supplied wiErr write intent error must have only a
 // single intent (len(wiErr.Intents) == 1).
 //
-// Returns a cleanup function to be invoked by the caller after the
-// original request completes, a possibly updated WriteIntentError and
-// a bool indicating whether the intent resolver should regard the
-// original push / resolve as no longer applicable and skip those
-// steps to retry the original request that generated the
-// WriteIntentError. The cleanup function takes two arguments, a
-// newWIErr, non-nil in case the re-executed request experienced
-// another write intent error and could not complete; and
-// newIntentTxn, nil if the re-executed request left no intent, and
-// non-nil if it did. At most one of these two arguments should be
-// provided.
-func (cq *contentionQueue) add(
-	ctx context.Context, wiErr *roachpb.WriteIntentError, h roachpb.Header,
-) (
-	func(newWIErr *roachpb.WriteIntentError, newIntentTxn *enginepb.TxnMeta),
-	*roachpb.WriteIntentError,
-	bool,
-) {
+// Returns the guard of the request in the lock table, which the caller must
+// release once the request completes, a possibly updated WriteIntentError and
+// a bool indicating whether the intent resolver should regard the original
+// push / resolve as no longer applicable and skip those steps to retry the
+// original request that generated the WriteIntentError.
+func (ir *intentResolver) waitInLockQueue(
+	ctx context.Context,
+	lt *locktable.Table,
+	lg *locktable.Guard,
+	wiErr *roachpb.WriteIntentError,
+	h roachpb.Header,
+) (*locktable.Guard, *roachpb.WriteIntentError, bool) {
 	if len(wiErr.Intents) != 1 {
 		log.Fatalf(ctx, "write intent error must contain only a single intent: %s", wiErr)
 	}
 	intent := wiErr.Intents[0]
-	key := string(intent.Span.Key)
-	curPusher := newPusher(h.Txn)
-	log.VEventf(ctx, 3, "adding %s to contention queue on intent %s @%s", txnID(h.Txn), intent.Key, intent.Txn.ID.Short())
-
-	// Consider prior pushers in reverse arrival order to build queue
-	// by waiting on the most recent overlapping pusher.
-	var waitCh chan *enginepb.TxnMeta
-	var curElement *list.Element
-
-	cq.mu.Lock()
-	contended, ok := cq.mu.keys[key]
-	if !ok {
-		contended = newContendedKey()
-		contended.setLastTxnMeta(&intent.Txn)
-		cq.mu.keys[key] = contended
-	} else if contended.lastTxnMeta.ID != intent.Txn.ID {
-		contended.setLastTxnMeta(&intent.Txn)
-	}
-
-	// Get the prior pusher to wait on.
-	if e := contended.ll.Back(); e != nil {
-		p := e.Value.(*pusher)
-		waitCh = p.waitCh
-		log.VEventf(ctx, 3, "%s waiting on %s", txnID(curPusher.txn), txnID(p.txn))
-	}
-
-	// Append the current pusher to the queue.
-	curElement = contended.ll.PushBack(curPusher)
-	cq.mu.Unlock()
-
-	// Delay before pushing in order to detect dependency cycles.
-	const dependencyCyclePushDelay = 100 * time.Millisecond
-
-	// Wait on prior pusher, if applicable.
-	var done bool
-	if waitCh != nil {
-		var detectCh chan struct{}
-		var detectReady <-chan time.Time
-		// If the current pusher has an active txn, we need to push the
-		// transaction which owns the intent to detect dependency cycles.
-		// To avoid unnecessary push traffic, insert a delay by
-		// instantiating the detectReady, which sets detectCh when it
-		// fires. When detectCh receives, it sends a push to the most
-		// recent txn to own the intent.
-		if curPusher.detectCh != nil {
-			detectReady = time.After(dependencyCyclePushDelay)
+	if lg != nil && !lg.Key().Equal(intent.Key) {
+		// The request re-evaluated and hit an intent on a different key. Leave
+		// the old key's wait-queue without telling it anything about the new
+		// intent's transaction. This new intent could be from an earlier
+		// request in the batch than the one that previously hit the error, so
+		// we don't know anything about the state of the old intent. The next
+		// request in the old queue will discover it when it re-evaluates.
+		lg.Done(nil /* leftIntent */)
+		lg = nil
+	}
+	if lg == nil {
+		log.VEventf(ctx, 3, "adding %s to lock wait-queue on intent %s @%s",
+			txnID(h.Txn), intent.Key, intent.Txn.ID.Short())
+		var txn *enginepb.TxnMeta
+		if h.Txn != nil {
+			txn = &h.Txn.TxnMeta
 		}
-
-	Loop:
-		for {
-			select {
-			case txnMeta, ok := <-waitCh:
-				if !ok {
-					log.Fatalf(ctx, "the wait channel of a prior pusher was used twice (pusher=%s)", txnMeta)
-				}
-				// If the prior pusher wrote an intent, push it instead by
-				// creating a copy of the WriteIntentError with updated txn.
-				if txnMeta != nil {
-					log.VEventf(ctx, 3, "%s exiting contention queue to push %s", txnID(curPusher.txn), txnMeta.ID.Short())
-					wiErrCopy := *wiErr
-					wiErrCopy.Intents = []roachpb.Intent{
-						{
-							Span:   intent.Span,
-							Txn:    *txnMeta,
-							Status: roachpb.PENDING,
-						},
-					}
-					wiErr = &wiErrCopy
-				} else {
-					// No intent was left by the prior pusher; don't push, go
-					// immediately to retrying the conflicted request.
-					log.VEventf(ctx, 3, "%s exiting contention queue to proceed", txnID(curPusher.txn))
-					done = true
-				}
-				break Loop
-
-			case <-ctx.Done():
-				// The pusher's context is done. Return without pushing.
-				done = true
-				break Loop
-
-			case <-detectReady:
-				// When the detect timer fires, set detectCh and loop.
-				log.VEventf(ctx, 3, "%s cycle detection is ready", txnID(curPusher.txn))
-				detectCh = curPusher.detectCh
-
-			case <-detectCh:
-				cq.mu.Lock()
-				frontOfQueue := curElement == contended.ll.Front()
-				pusheeTxn := contended.lastTxnMeta
-				cq.mu.Unlock()
-				// If we're at the start of the queue loop and wait
-				// for the wait channel to signal.
-				if frontOfQueue {
-					log.VEventf(ctx, 3, "%s at front of queue; breaking from loop", txnID(curPusher.txn))
-					break Loop
-				}
-				pushReq := &roachpb.PushTxnRequest{
-					RequestHeader: roachpb.RequestHeader{
-						Key: pusheeTxn.Key,
+		lg = lt.Enqueue(intent.Key, intent.Txn, txn)
+	} else {
+		lg.Update(intent.Txn)
+	}
+
+	// If the request has an active txn, it needs to push the transaction
+	// which holds the lock to detect dependency cycles. To avoid unnecessary
+	// push traffic, insert a delay by instantiating detectReady. Once it
+	// fires, each new holder of the lock is pushed.
+	var detectReady <-chan time.Time
+	if h.Txn != nil && h.Txn.Key != nil {
+		detectReady = time.After(dependencyCyclePushDelay)
+	}
+	var detect bool
+	var lastPushed uuid.UUID
+	for {
+		holder, front := lg.State()
+		if front {
+			if holder == nil {
+				// The lock was freed; don't push, go immediately to retrying
+				// the conflicted request.
+				log.VEventf(ctx, 3, "%s at front of lock wait-queue; proceeding", txnID(h.Txn))
+				return lg, wiErr, true
+			}
+			// If the lock changed hands while we were waiting, push its new
+			// holder instead by creating a copy of the WriteIntentError with
+			// updated txn.
+			if holder.ID != intent.Txn.ID {
+				log.VEventf(ctx, 3, "%s at front of lock wait-queue; pushing %s",
+					txnID(h.Txn), holder.ID.Short())
+				wiErrCopy := *wiErr
+				wiErrCopy.Intents = []roachpb.Intent{
+					{
+						Span:   intent.Span,
+						Txn:    *holder,
+						Status: roachpb.PENDING,
 					},
-					PusherTxn: getPusherTxn(h),
-					PusheeTxn: *pusheeTxn,
-					PushTo:    h.Timestamp,
-					Now:       cq.store.Clock().Now(),
-					PushType:  roachpb.PUSH_ABORT,
-				}
-				b := &client.Batch{}
-				b.AddRawRequest(pushReq)
-				log.VEventf(ctx, 3, "%s pushing %s to detect dependency cycles", txnID(curPusher.txn), pusheeTxn.ID.Short())
-				if err := cq.store.db.Run(ctx, b); err != nil {
-					log.VErrEventf(ctx, 2, "while waiting in push contention queue to push %s: %s", pusheeTxn.ID.Short(), b.MustPErr())
-					done = true // done=true to avoid uselessly trying to push and resolve
-					break Loop
 				}
-				// Note that this pusher may have aborted the pushee, but it
-				// should still wait on the previous pusher's wait channel.
-				detectCh = nil
-				detectReady = time.After(dependencyCyclePushDelay)
+				wiErr = &wiErrCopy
 			}
+			return lg, wiErr, false
 		}
-	}
 
-	return func(newWIErr *roachpb.WriteIntentError, newIntentTxn *enginepb.TxnMeta) {
-		if newWIErr != nil && newIntentTxn != nil {
-			// The need for this implies that the function should be split
-			// into a more rigidly defined handle with multiple accessors.
-			// TODO(nvanbenschoten): clean this up and test better when we're
-			// not intending the change to be backported.
-			panic("newWIErr and newIntentTxn both provided")
-		}
-		if newWIErr == nil {
-			log.VEventf(ctx, 3, "%s finished, leaving intent? %t (owned by %s)", txnID(curPusher.txn), newIntentTxn != nil, newIntentTxn)
-		} else {
-			log.VEventf(ctx, 3, "%s encountered another write intent error %s", txnID(curPusher.txn), newWIErr)
-		}
-		cq.mu.Lock()
-		defer cq.mu.Unlock()
-
-		// Remove the current element from its list of pushers.
-		// If the current element isn't the front, it's being removed
-		// because its context was canceled. Swap the wait channel with
-		// the previous element.
-		if curElement != contended.ll.Front() {
-			prevPusher := curElement.Prev().Value.(*pusher)
-			if waitCh != nil && prevPusher.waitCh != waitCh {
-				log.Fatalf(ctx, "expected previous pusher's wait channel to be the one current pusher waited on")
+		if detect && holder != nil && holder.ID != lastPushed && holder.ID != h.Txn.ID {
+			pushReq := &roachpb.PushTxnRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key: holder.Key,
+				},
+				PusherTxn: getPusherTxn(h),
+				PusheeTxn: *holder,
+				PushTo:    h.Timestamp,
+				Now:       ir.store.Clock().Now(),
+				PushType:  roachpb.PUSH_ABORT,
+			}
+			b := &client.Batch{}
+			b.AddRawRequest(pushReq)
+			log.VEventf(ctx, 3, "%s pushing %s to detect dependency cycles",
+				txnID(h.Txn), holder.ID.Short())
+			if err := ir.store.db.Run(ctx, b); err != nil {
+				log.VErrEventf(ctx, 2, "while waiting in lock wait-queue to push %s: %s",
+					holder.ID.Short(), b.MustPErr())
+				return lg, wiErr, true // done=true to avoid uselessly trying to push and resolve
 			}
-			prevPusher.waitCh, curPusher.waitCh = curPusher.waitCh, prevPusher.waitCh
+			// Note that this request may have aborted the holder, but it
+			// should still wait for its turn in the queue.
+			lastPushed = holder.ID
+			detect = false
+			detectReady = time.After(dependencyCyclePushDelay)
+			continue
 		}
-		contended.ll.Remove(curElement)
 
-		if contended.ll.Len() == 0 {
-			// If the contendedKey's list is now empty, remove it. We don't need
-			// to send on or close our waitCh because no one is or ever will wait
-			// on it.
-			delete(cq.mu.keys, key)
-		} else {
-			// If the pusher re-executed its request and encountered another
-			// write intent error, check if it's for the same intent; if so,
-			// we can set the newIntentTxn to match the new intent. If not,
-			// make sure that we don't pollute the old contendedKey with any
-			// new information.
-			if newWIErr != nil {
-				sameKey := len(newWIErr.Intents) == 1 && newWIErr.Intents[0].Span.Equal(intent.Span)
-				if sameKey {
-					newIntentTxn = &newWIErr.Intents[0].Txn
-				} else {
-					// If the pusher re-executed and found a different intent, make
-					// sure that we don't tell the old contendedKey anything about
-					// the new intent's transaction. This new intent could be from
-					// an earlier request in the batch than the one that previously
-					// hit the error, so we don't know anything about the state of
-					// the old intent.
-					newIntentTxn = nil
-				}
-			}
-			if newIntentTxn != nil {
-				// Shallow copy the TxnMeta. After this request returns (i.e.
-				// now), we might mutate it (DistSender and such), but the
-				// receiver of the channel will read it.
-				newIntentTxnCopy := *newIntentTxn
-				newIntentTxn = &newIntentTxnCopy
-				contended.setLastTxnMeta(newIntentTxn)
-			}
-			curPusher.waitCh <- newIntentTxn
-			close(curPusher.waitCh)
+		select {
+		case <-lg.StateChanged():
+			// Loop and inspect the updated state.
+
+		case <-ctx.Done():
+			// The request's context is done. Return without pushing.
+			return lg, wiErr, true
+
+		case <-detectReady:
+			log.VEventf(ctx, 3, "%s cycle detection is ready", txnID(h.Txn))
+			detect = true
+			detectReady = nil
 		}
-	}, wiErr, done
+	}
 }
 
 // intentResolver manages the process of pushing transactions and
@@ -376,8 +212,7 @@ func (cq *contentionQueue) add(
 type intentResolver struct {
 	store *Store
 
-	sem         chan struct{}    // Semaphore to limit async goroutines.
-	contentionQ *contentionQueue // manages contention on individual keys
+	sem chan struct{} // Semaphore to limit async goroutines.
 
 	mu struct {
 		syncutil.Mutex
@@ -393,10 +228,9 @@ type intentResolver struct {
 
 func newIntentResolver(store *Store, taskLimit int) *intentResolver {
 	ir := &intentResolver{
-		store:       store,
-		sem:         make(chan struct{}, taskLimit),
-		contentionQ: newContentionQueue(store),
-		every:       log.Every(time.Minute),
+		store: store,
+		sem:   make(chan struct{}, taskLimit),
+		every: log.Every(time.Minute),
 	}
 	ir.mu.inFlightPushes = map[uuid.UUID]int{}
 	ir.mu.inFlightTxnCleanups = map[uuid.UUID]struct{}{}
@@ -405,22 +239,27 @@ func newIntentResolver(store *Store, taskLimit int) *intentResolver {
 
 // processWriteIntentError tries to push the conflicting
 // transaction(s) responsible for the given WriteIntentError, and to
-// resolve those intents if possible. Returns a cleanup function and
-// potentially a new error to be used in place of the original. The
-// cleanup function should be invoked by the caller after the request
-// which experienced the conflict has completed with a parameter
-// specifying a transaction in the event that the request left its own
-// intent.
+// resolve those intents if possible. A conflict on a single key is
+// first queued in the supplied lock table of the range, where lg is the
+// guard that the request holds from a previous conflict, if any. Returns
+// the guard that the request holds after processing the error and
+// potentially a new error to be used in place of the original. If the
+// returned guard is non-nil, the caller should release it through
+// Guard.Done after the request which experienced the conflict has
+// completed, specifying a transaction in the event that the request left
+// its own intent.
 func (ir *intentResolver) processWriteIntentError(
 	ctx context.Context,
+	lt *locktable.Table,
+	lg *locktable.Guard,
 	wiPErr *roachpb.Error,
 	args roachpb.Request,
 	h roachpb.Header,
 	pushType roachpb.PushTxnType,
-) (func(newWIErr *roachpb.WriteIntentError, newIntentTxn *enginepb.TxnMeta), *roachpb.Error) {
+) (*locktable.Guard, *roachpb.Error) {
 	wiErr, ok := wiPErr.GetDetail().(*roachpb.WriteIntentError)
 	if !ok {
-		return nil, roachpb.NewErrorf("not a WriteIntentError: %v", wiPErr)
+		return lg, roachpb.NewErrorf("not a WriteIntentError: %v", wiPErr)
 	}
 
 	if log.V(6) {
@@ -428,23 +267,28 @@ func (ir *intentResolver) processWriteIntentError(
 	}
 
 	// Possibly queue this processing if the write intent error is for a
-	// single intent affecting a unitary key.
-	var cleanup func(*roachpb.WriteIntentError, *enginepb.TxnMeta)
-	if len(wiErr.Intents) == 1 && len(wiErr.Intents[0].Span.EndKey) == 0 {
+	// single intent affecting a unitary key. Requests that don't block on
+	// conflicting intents never wait in the queue.
+	if h.WaitPolicy == roachpb.WaitPolicy_Block &&
+		len(wiErr.Intents) == 1 && len(wiErr.Intents[0].Span.EndKey) == 0 {
 		var done bool
 		// Note that the write intent error may be mutated here in the event
-		// that this pusher is queued to wait for a different transaction
+		// that this request is queued to wait for a different transaction
 		// instead.
-		if cleanup, wiErr, done = ir.contentionQ.add(ctx, wiErr, h); done {
-			return cleanup, nil
+		if lg, wiErr, done = ir.waitInLockQueue(ctx, lt, lg, wiErr, h); done {
+			return lg, nil
 		}
+	} else if lg != nil {
+		// The request no longer conflicts on the single key it was queued on.
+		lg.Done(nil /* leftIntent */)
+		lg = nil
 	}
 
 	resolveIntents, pErr := ir.maybePushIntents(
 		ctx, wiErr.Intents, h, pushType, false, /* skipIfInFlight */
 	)
 	if pErr != nil {
-		return cleanup, pErr
+		return lg, pErr
 	}
 
 	// We always poison due to limitations of the API: not poisoning equals
@@ -459,10 +303,10 @@ func (ir *intentResolver) processWriteIntentError(
 	// poison.
 	if err := ir.resolveIntents(ctx, resolveIntents,
 		ResolveOptions{Wait: false, Poison: true}); err != nil {
-		return cleanup, roachpb.NewError(err)
+		return lg, roachpb.NewError(err)
 	}
 
-	return cleanup, nil
+	return lg, nil
 }
 
 func getPusherTxn(h roachpb.Header) roachpb.Transaction {
@@ -613,18 +457,29 @@ func (ir *intentResolver) maybePushTransactions(
 			PushType: pushType,
 		})
 	}
-	b := &client.Batch{}
-	b.AddRawRequest(pushReqs...)
-	var pErr *roachpb.Error
-	if err := ir.store.db.Run(ctx, b); err != nil {
-		pErr = b.MustPErr()
+	var br *roachpb.BatchResponse
+	for {
+		b := &client.Batch{}
+		b.AddRawRequest(pushReqs...)
+		err := ir.store.db.Run(ctx, b)
+		if err == nil {
+			br = b.RawResponse()
+			break
+		}
+		// If one of the pushees is STAGING and the push would have succeeded
+		// had it been PENDING, determine its outcome using the transaction
+		// recovery procedure and push again. Pushes of a finalized pushee
+		// succeed trivially.
+		if icErr, ok := err.(*roachpb.IndeterminateCommitError); ok {
+			if _, err := ir.recoverTxn(ctx, &icErr.StagingTxn); err == nil {
+				continue
+			}
+		}
+		cleanupInFlightPushes()
+		return nil, b.MustPErr()
 	}
 	cleanupInFlightPushes()
-	if pErr != nil {
-		return nil, pErr
-	}
 
-	br := b.RawResponse()
 	pushedTxns := map[uuid.UUID]roachpb.Transaction{}
 	for _, resp := range br.Responses {
 		txn := resp.GetInner().(*roachpb.PushTxnResponse).PusheeTxn
@@ -637,6 +492,60 @@ func (ir *intentResolver) maybePushTransactions(
 	return pushedTxns, nil
 }
 
+// recoverTxn runs the transaction recovery procedure on a STAGING
+// transaction, whose coordinator is presumed to have failed, and returns the
+// updated transaction. The transaction is implicitly committed if all of its
+// in-flight writes succeeded at or below its staging timestamp. To decide
+// whether this is the case, each in-flight write is queried and, if missing,
+// prevented from ever succeeding at that timestamp. The transaction's record
+// is then moved to COMMITTED if all of the writes were found and to ABORTED
+// otherwise. If the transaction has been restaged in the meantime, the
+// returned transaction is left STAGING.
+func (ir *intentResolver) recoverTxn(
+	ctx context.Context, txn *roachpb.Transaction,
+) (roachpb.Transaction, error) {
+	log.VEventf(ctx, 2, "recovering STAGING txn %s", txn)
+	implicitlyCommitted := true
+	if len(txn.InFlightWrites) > 0 {
+		b := &client.Batch{}
+		for _, w := range txn.InFlightWrites {
+			meta := txn.TxnMeta
+			meta.Sequence = w.Sequence
+			b.AddRawRequest(&roachpb.QueryIntentRequest{
+				RequestHeader: roachpb.RequestHeader{Key: w.Key},
+				Txn:           meta,
+				IfMissing:     roachpb.QueryIntentRequest_PREVENT,
+			})
+		}
+		if err := ir.store.db.Run(ctx, b); err != nil {
+			return roachpb.Transaction{}, err
+		}
+		for _, resp := range b.RawResponse().Responses {
+			if !resp.GetInner().(*roachpb.QueryIntentResponse).FoundIntent {
+				implicitlyCommitted = false
+				break
+			}
+		}
+	}
+
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.PushTxnRequest{
+		RequestHeader: roachpb.RequestHeader{Key: txn.Key},
+		PusheeTxn:     txn.TxnMeta,
+		Now:           ir.store.Clock().Now(),
+		// The push type is ignored when recovering a STAGING pushee.
+		PushType:            roachpb.PUSH_ABORT,
+		RecoverStaging:      true,
+		ImplicitlyCommitted: implicitlyCommitted,
+	})
+	if err := ir.store.db.Run(ctx, b); err != nil {
+		return roachpb.Transaction{}, err
+	}
+	recoveredTxn := b.RawResponse().Responses[0].GetInner().(*roachpb.PushTxnResponse).PusheeTxn
+	log.VEventf(ctx, 2, "recovered STAGING txn %s as %s", txn.ID.Short(), recoveredTxn.Status)
+	return recoveredTxn, nil
+}
+
 // runAsyncTask semi-synchronously runs a generic task function. If
 // there is spare capacity in the limited async task semaphore, it's
 // run asynchronously; otherwise, it's run synchronously if
@@ -788,8 +697,9 @@ func (ir *intentResolver) lockInFlightTxnCleanup(
 // cleanupTxnIntentsOnGCAsync cleans up extant intents owned by a
 // single transaction, asynchronously (but returning an error if the
 // intentResolver's semaphore is maxed out). If the transaction is
-// PENDING, but expired, it is pushed first to abort it. This method
-// updates the metrics for intents resolved on GC on success.
+// PENDING, but expired, it is pushed first to abort it. If it is STAGING,
+// but expired, it is recovered first to finalize it. This method updates
+// the metrics for intents resolved on GC on success.
 func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 	ctx context.Context, txn *roachpb.Transaction, intents []roachpb.Intent, now hlc.Timestamp,
 ) error {
@@ -816,9 +726,10 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 
 			// If the transaction is still pending, but expired, push it
 			// before resolving the intents.
-			if txn.Status == roachpb.PENDING {
+			if !txn.Status.IsFinalized() {
 				if !txnwait.IsExpired(now, txn) {
-					log.VErrEventf(ctx, 3, "cannot push a PENDING transaction which is not expired: %s", txn)
+					log.VErrEventf(ctx, 3, "cannot push a %s transaction which is not expired: %s",
+						txn.Status, txn)
 					return
 				}
 				b := &client.Batch{}
@@ -833,11 +744,28 @@ func (ir *intentResolver) cleanupTxnIntentsOnGCAsync(
 				})
 				ir.store.metrics.GCPushTxn.Inc(1)
 				if err := ir.store.DB().Run(ctx, b); err != nil {
-					log.VErrEventf(ctx, 2, "failed to push PENDING, expired txn (%s): %s", txn, err)
-					return
+					icErr, ok := err.(*roachpb.IndeterminateCommitError)
+					if !ok {
+						log.VErrEventf(ctx, 2, "failed to push %s, expired txn (%s): %s", txn.Status, txn, err)
+						return
+					}
+					// The transaction is STAGING and its coordinator has failed to
+					// commit it explicitly. Determine its outcome.
+					recoveredTxn, err := ir.recoverTxn(ctx, &icErr.StagingTxn)
+					if err != nil {
+						log.VErrEventf(ctx, 2, "failed to recover STAGING, expired txn (%s): %s", txn, err)
+						return
+					}
+					if !recoveredTxn.Status.IsFinalized() {
+						log.VErrEventf(ctx, 2, "STAGING txn was restaged during recovery: %s", txn)
+						return
+					}
+					txn = &recoveredTxn
+				} else {
+					// Get the pushed txn.
+					txn = &b.RawResponse().Responses[0].GetInner().(*roachpb.PushTxnResponse).PusheeTxn
 				}
-				// Get the pushed txn and update the intents slice.
-				txn = &b.RawResponse().Responses[0].GetInner().(*roachpb.PushTxnResponse).PusheeTxn
+				// Update the intents slice.
 				for _, intent := range intents {
 					intent.Txn = txn.TxnMeta
 					intent.Status = txn.Status
@@ -24,6 +24,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -82,6 +84,18 @@ func beginTransaction(
 	return txn
 }
 
+// lockWaiters returns the transactions of the requests waiting in the lock
+// table on the given key, in queue order.
+func lockWaiters(lt *locktable.Table, key roachpb.Key) []*enginepb.TxnMeta {
+	var txns []*enginepb.TxnMeta
+	for _, w := range lt.Waiters() {
+		if w.Key.Equal(key) {
+			txns = append(txns, w.Waiter)
+		}
+	}
+	return txns
+}
+
 // TestContendedIntent verifies that multiple transactions, some actively
 // writing and others read-only, are queued if processing write intent
 // errors on a contended key. The test verifies the expected ordering in
@@ -119,6 +133,7 @@ func TestContendedIntent(t *testing.T) {
 	}
 
 	var wg sync.WaitGroup
+	var lt locktable.Table
 	ir := store.intentResolver
 	for _, tc := range testCases {
 		t.Run("", func(t *testing.T) {
@@ -126,29 +141,29 @@ func TestContendedIntent(t *testing.T) {
 			h := roachpb.Header{Txn: tc.pusher}
 			wg.Add(1)
 			go func() {
-				_, pErr := ir.processWriteIntentError(ctx, roachpb.NewError(wiErr), nil, h, roachpb.PUSH_ABORT)
+				lg, pErr := ir.processWriteIntentError(
+					ctx, &lt, nil /* lg */, roachpb.NewError(wiErr), nil, h, roachpb.PUSH_ABORT,
+				)
 				if pErr != nil && !testutils.IsPError(pErr, "context canceled") {
 					panic(pErr)
 				}
+				if lg != nil {
+					lg.Done(nil /* leftIntent */)
+				}
 				wg.Done()
 			}()
 			testutils.SucceedsSoon(t, func() error {
-				ir.contentionQ.mu.Lock()
-				defer ir.contentionQ.mu.Unlock()
-				contended, ok := ir.contentionQ.mu.keys[string(key)]
-				if !ok {
+				waiters := lockWaiters(&lt, key)
+				if len(waiters) == 0 {
 					return errors.Errorf("key not contended")
 				}
-				if lc, let := contended.ll.Len(), len(tc.expTxns); lc != let {
-					return errors.Errorf("expected len %d; got %d", let, lc)
+				if lw, let := len(waiters), len(tc.expTxns); lw != let {
+					return errors.Errorf("expected len %d; got %d", let, lw)
 				}
-				var idx int
-				for e := contended.ll.Front(); e != nil; e = e.Next() {
-					p := e.Value.(*pusher)
-					if p.txn != tc.expTxns[idx] {
-						return errors.Errorf("expected txn %s at index %d; got %s", tc.expTxns[idx], idx, p.txn)
+				for idx, w := range waiters {
+					if w.ID != tc.expTxns[idx].ID {
+						return errors.Errorf("expected txn %s at index %d; got %s", tc.expTxns[idx], idx, w)
 					}
-					idx++
 				}
 				return nil
 			})
@@ -277,7 +292,7 @@ func TestContendedIntentWithDependencyCycle(t *testing.T) {
 
 // TestContendedIntentChangesOnRetry verifies that a batch which observes a
 // WriteIntentError for one key and then a WriteIntentError for a different
-// key doesn't pollute the old key's contentionQueue state.
+// key doesn't pollute the old key's lock table state.
 //
 // This also serves as a regression test for #32582. In that issue, we
 // saw a transaction wait in the contentionQueue without pushing the
@@ -289,24 +304,24 @@ func TestContendedIntentWithDependencyCycle(t *testing.T) {
 // 1.  txn1 writes to keyA.
 // 2.  txn2 writes to keyB.
 // 3.  txn4 writes to keyC and keyB in the same batch. The batch initially
-//     fails with a WriteIntentError on keyA. It enters the contentionQueue
-//     and becomes the front of a contendedKey list.
-// 4.  txn5 writes to keyB. It enters the contentionQueue behind txn4.
+//     fails with a WriteIntentError on keyA. It enters the lock wait-queue
+//     and becomes the front of the wait-queue.
+// 4.  txn5 writes to keyB. It enters the lock wait-queue behind txn4.
 // 5.  txn3 writes to keyC.
 // 6.  txn2 is committed and the intent on keyB is resolved.
-// 7.  txn4 exits the contentionQueue and re-evaluates. This time, it hits
+// 7.  txn4 exits the lock wait-queue and re-evaluates. This time, it hits
 //     a WriteIntentError on the first request in its batch: keyC. HOWEVER,
-//     before it updates the contentionQueue, steps 8-10 occur.
-// 8.  txn3 writes to keyB. It never enters the contentionQueue. txn3 then
+//     before it updates the lock wait-queue, steps 8-10 occur.
+// 8.  txn3 writes to keyB. It never enters the lock wait-queue. txn3 then
 //     writes to keyA in a separate batch and gets stuck waiting on txn1.
 // 9.  txn2 writes to keyB. It observes txn3's intent and informs the
-//     contentionQueue of the new txn upon its entrance.
-// 10. txn5, the new front of the contendedKey list, pushes txn2 and gets
+//     lock table of the new holder upon its entrance.
+// 10. txn5, the new front of the wait-queue, pushes txn2 and gets
 //     stuck in the txnWaitQueue.
-// 11. txn4 finally updates the contentionQueue. A bug previously existed
-//     where it would set the contendedKey's lastTxnMeta to nil because it
-//     saw a WriteIntentError for a different key.
-// 12. txn1 notices the nil lastTxnMeta and does not push txn2. This prevents
+// 11. txn4 finally updates the lock table. A bug previously existed
+//     where it would clear the key's holder because it saw a
+//     WriteIntentError for a different key.
+// 12. txn1 notices the missing holder and does not push txn2. This prevents
 //     cycle detection from succeeding and we observe a deadlock.
 //
 func TestContendedIntentChangesOnRetry(t *testing.T) {
@@ -342,18 +357,16 @@ func TestContendedIntentChangesOnRetry(t *testing.T) {
 	txnCh5 := make(chan error, 1)
 
 	// waitForContended waits until the provided key has the specified
-	// number of pushers in the contentionQueue.
+	// number of waiters in the lock table of its range.
 	waitForContended := func(key roachpb.Key, count int) {
 		testutils.SucceedsSoon(t, func() error {
-			ir := store.intentResolver
-			ir.contentionQ.mu.Lock()
-			defer ir.contentionQ.mu.Unlock()
-			contended, ok := ir.contentionQ.mu.keys[string(key)]
-			if !ok {
+			repl := store.LookupReplica(roachpb.RKey(key))
+			waiters := lockWaiters(&repl.lockTable, key)
+			if len(waiters) == 0 {
 				return errors.Errorf("key not contended")
 			}
-			if lc := contended.ll.Len(); lc != count {
-				return errors.Errorf("expected len %d; got %d", count, lc)
+			if lw := len(waiters); lw != count {
+				return errors.Errorf("expected len %d; got %d", count, lw)
 			}
 			return nil
 		})
@@ -365,7 +378,7 @@ func TestContendedIntentChangesOnRetry(t *testing.T) {
 	// This txn will hit a WriteIntentError on its second request during the
 	// first time that it evaluates the batch and will hit a WriteIntentError
 	// on its first request during the second time that it evaluates. This
-	// second WriteIntentError must not be entangled with the contentionQueue
+	// second WriteIntentError must not be entangled with the lock wait-queue
 	// entry for the first key.
 	{
 		go func() {
@@ -392,13 +405,13 @@ func TestContendedIntentChangesOnRetry(t *testing.T) {
 		}()
 
 		waitForContended(keyB, 1)
-		t.Log("txn4 in contentionQueue")
+		t.Log("txn4 in lock wait-queue")
 	}
 
 	// Steps 4 and 10.
 	//
 	// Send txn5's put, followed by an end transaction. This request will
-	// wait at the head of the contention queue once txn2 is committed.
+	// wait at the head of the lock wait-queue once txn2 is committed.
 	{
 		go func() {
 			// Write keyB to create a cycle with txn3.
@@ -420,7 +433,7 @@ func TestContendedIntentChangesOnRetry(t *testing.T) {
 		}()
 
 		waitForContended(keyB, 2)
-		t.Log("txn5 in contentionQueue")
+		t.Log("txn5 in lock wait-queue")
 	}
 
 	// Step 5.
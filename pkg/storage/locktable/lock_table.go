@@ -0,0 +1,323 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+/*
+Package locktable provides a per-range table of the locks that requests have
+discovered on keys, along with FIFO wait-queues of the requests waiting for
+these locks to be released.
+
+Where latches (see package spanlatch) serialize the evaluation of overlapping
+requests and are only held for the duration of a request, locks are held by
+transactions for as long as they remain pending. In this package, a lock is the
+intent that a transaction left on a key and that another request conflicted
+with during evaluation.
+*/
+package locktable
+
+import (
+	"bytes"
+	"container/list"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// A Table is an in-memory table of the locks on the keys of a range that
+// requests are waiting on. Requests waiting on the same lock form a FIFO
+// wait-queue. Only the request at the front of the queue acts on the lock, by
+// pushing its holder and resolving it, while the requests behind it wait to be
+// signaled. This avoids a thundering herd of requests pushing the same
+// transaction and retrying at once when its lock is released, and it lets
+// requests acquire contended locks in the order in which they arrived.
+//
+// Requests enter a lock's wait-queue by invoking Table.Enqueue after hitting
+// a conflicting intent during evaluation. Enqueue returns a Guard, which is
+// scoped to the request's stay in the queue. Once the request completes, it
+// leaves the queue by invoking Guard.Done. If the request wrote an intent on
+// the key, its transaction becomes the lock's holder, which the next request
+// in the queue then pushes. Otherwise, the lock is considered free and the next
+// request proceeds directly. Locks are also freed when Table.Release is
+// informed that their intents were resolved.
+//
+// The table is not a source of truth for the locks on a range, which remain
+// the intents stored in the range's data. It only tracks the keys that
+// requests are waiting on and it can be cleared at any time, at which point
+// all waiters are released to re-evaluate their requests.
+//
+// Table is safe for concurrent use by multiple goroutines. Table's zero value
+// can be used directly.
+type Table struct {
+	mu struct {
+		syncutil.Mutex
+		locks map[string]*lockState
+	}
+}
+
+// lockState is the state of a lock in a Table.
+type lockState struct {
+	key roachpb.Key
+	// holder is the transaction that most recently held the lock, or nil if
+	// the lock is free.
+	holder *enginepb.TxnMeta
+	// queue is the FIFO wait-queue of *Guards waiting on the lock.
+	queue list.List
+}
+
+// Guard is a handle to a request's position in the wait-queue of a lock. It
+// is returned by Table.Enqueue and released by Guard.Done.
+type Guard struct {
+	t      *Table
+	key    roachpb.Key
+	txn    *enginepb.TxnMeta
+	start  time.Time
+	signal chan struct{}
+
+	// ls and elem are protected by t.mu. They are nil if the guard is not
+	// in a wait-queue, either because it is done or because its table was
+	// cleared.
+	ls   *lockState
+	elem *list.Element
+}
+
+// Enqueue adds a request to the back of the wait-queue of the lock on the
+// given key, which the request found held by the given transaction. txn is the
+// request's own transaction, or nil if the request is non-transactional.
+func (t *Table) Enqueue(key roachpb.Key, holder enginepb.TxnMeta, txn *enginepb.TxnMeta) *Guard {
+	g := &Guard{
+		t:      t,
+		key:    key,
+		start:  timeutil.Now(),
+		signal: make(chan struct{}, 1),
+	}
+	if txn != nil {
+		txnCopy := *txn
+		g.txn = &txnCopy
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enqueueLocked(g)
+	g.ls.setHolderLocked(&holder)
+	return g
+}
+
+func (t *Table) enqueueLocked(g *Guard) {
+	if t.mu.locks == nil {
+		t.mu.locks = make(map[string]*lockState)
+	}
+	ls, ok := t.mu.locks[string(g.key)]
+	if !ok {
+		ls = &lockState{key: g.key}
+		t.mu.locks[string(g.key)] = ls
+	}
+	g.ls = ls
+	g.elem = ls.queue.PushBack(g)
+}
+
+// Release informs the table that the intents of the given transaction in the
+// given span were resolved, freeing their locks. The waiters on the freed
+// locks are signaled.
+func (t *Table) Release(span roachpb.Span, txnID uuid.UUID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(span.EndKey) == 0 {
+		if ls, ok := t.mu.locks[string(span.Key)]; ok {
+			ls.releaseLocked(txnID)
+		}
+		return
+	}
+	for _, ls := range t.mu.locks {
+		if span.ContainsKey(ls.key) {
+			ls.releaseLocked(txnID)
+		}
+	}
+}
+
+// Clear removes all locks from the table and releases all of their waiters,
+// which proceed to re-evaluate their requests. This is used when the range's
+// lease changes hands or its bounds change, in which case the waiters must be
+// redirected.
+func (t *Table) Clear() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, ls := range t.mu.locks {
+		for e := ls.queue.Front(); e != nil; e = e.Next() {
+			g := e.Value.(*Guard)
+			g.ls, g.elem = nil, nil
+			g.notify()
+		}
+	}
+	t.mu.locks = nil
+}
+
+// WaitInfo describes a request waiting in the wait-queue of a lock.
+type WaitInfo struct {
+	// Key is the key of the lock.
+	Key roachpb.Key
+	// Holder is the transaction holding the lock, or nil if the lock is free.
+	Holder *enginepb.TxnMeta
+	// Waiter is the transaction of the waiting request, or nil if the request
+	// is non-transactional.
+	Waiter *enginepb.TxnMeta
+	// Position is the position of the request in the wait-queue. The request
+	// at the front of the queue is at position 0.
+	Position int
+	// WaitStart is the time at which the request entered the wait-queue.
+	WaitStart time.Time
+}
+
+// Waiters returns a snapshot of the requests waiting in the table, ordered by
+// key and by position in the key's wait-queue.
+func (t *Table) Waiters() []WaitInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var res []WaitInfo
+	for _, ls := range t.mu.locks {
+		var holder *enginepb.TxnMeta
+		if ls.holder != nil {
+			holderCopy := *ls.holder
+			holder = &holderCopy
+		}
+		var pos int
+		for e := ls.queue.Front(); e != nil; e = e.Next() {
+			g := e.Value.(*Guard)
+			res = append(res, WaitInfo{
+				Key:       ls.key,
+				Holder:    holder,
+				Waiter:    g.txn,
+				Position:  pos,
+				WaitStart: g.start,
+			})
+			pos++
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if c := bytes.Compare(res[i].Key, res[j].Key); c != 0 {
+			return c < 0
+		}
+		return res[i].Position < res[j].Position
+	})
+	return res
+}
+
+// setHolderLocked sets the holder of the lock, or frees the lock if holder is
+// nil. If the holder changed, all waiters are signaled so that they can react
+// to the new holder.
+func (ls *lockState) setHolderLocked(holder *enginepb.TxnMeta) {
+	changed := (ls.holder == nil) != (holder == nil) ||
+		(holder != nil && ls.holder.ID != holder.ID)
+	if holder == nil {
+		ls.holder = nil
+	} else {
+		// Copy the TxnMeta, as the caller may mutate it once we return.
+		holderCopy := *holder
+		ls.holder = &holderCopy
+	}
+	if changed {
+		for e := ls.queue.Front(); e != nil; e = e.Next() {
+			e.Value.(*Guard).notify()
+		}
+	}
+}
+
+// releaseLocked frees the lock if it is held by the given transaction.
+func (ls *lockState) releaseLocked(txnID uuid.UUID) {
+	if ls.holder != nil && ls.holder.ID == txnID {
+		ls.setHolderLocked(nil)
+	}
+}
+
+// Key returns the key of the lock that the guard is waiting on.
+func (g *Guard) Key() roachpb.Key {
+	return g.key
+}
+
+// StateChanged returns a channel that is signaled when the state returned by
+// State may have changed.
+func (g *Guard) StateChanged() <-chan struct{} {
+	return g.signal
+}
+
+// State returns the transaction holding the lock that the guard is waiting
+// on, or nil if the lock is free, and whether the guard is at the front of the
+// lock's wait-queue. A guard that was released by Table.Clear is reported to
+// be at the front of the wait-queue of a free lock, so that its request
+// proceeds to re-evaluate.
+func (g *Guard) State() (holder *enginepb.TxnMeta, front bool) {
+	g.t.mu.Lock()
+	defer g.t.mu.Unlock()
+	if g.ls == nil {
+		return nil, true
+	}
+	if g.ls.holder != nil {
+		holderCopy := *g.ls.holder
+		holder = &holderCopy
+	}
+	return holder, g.ls.queue.Front() == g.elem
+}
+
+// Update informs the table that the guard's request re-evaluated and found
+// the lock that it is waiting on held by the given transaction. The guard
+// retains its position in the lock's wait-queue, unless it was released by
+// Table.Clear, in which case it is added back to the back of the queue.
+func (g *Guard) Update(holder enginepb.TxnMeta) {
+	g.t.mu.Lock()
+	defer g.t.mu.Unlock()
+	if g.ls == nil {
+		g.t.enqueueLocked(g)
+	}
+	g.ls.setHolderLocked(&holder)
+}
+
+// Done removes the guard from its wait-queue once its request has completed.
+// leftIntent is the transaction of the intent that the request wrote on the
+// key, if any. If the guard was at the front of the queue and the request did
+// not leave an intent, the lock is considered free. In either case, the next
+// request in the queue is signaled.
+func (g *Guard) Done(leftIntent *enginepb.TxnMeta) {
+	g.t.mu.Lock()
+	defer g.t.mu.Unlock()
+	ls := g.ls
+	if ls == nil {
+		return
+	}
+	front := ls.queue.Front() == g.elem
+	ls.queue.Remove(g.elem)
+	g.ls, g.elem = nil, nil
+	if ls.queue.Len() == 0 {
+		// No one is waiting on the lock anymore, so stop tracking it.
+		delete(g.t.mu.locks, string(ls.key))
+		return
+	}
+	if front || leftIntent != nil {
+		ls.setHolderLocked(leftIntent)
+	}
+	if front {
+		ls.queue.Front().Value.(*Guard).notify()
+	}
+}
+
+// notify signals the guard without blocking. Signals are coalesced, as the
+// waiter only needs to know that it should inspect its state again.
+func (g *Guard) notify() {
+	select {
+	case g.signal <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package locktable
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTxn() *enginepb.TxnMeta {
+	return &enginepb.TxnMeta{ID: uuid.MakeV4()}
+}
+
+func requireState(t *testing.T, g *Guard, expHolder *enginepb.TxnMeta, expFront bool) {
+	t.Helper()
+	holder, front := g.State()
+	if expHolder == nil {
+		require.Nil(t, holder)
+	} else {
+		require.NotNil(t, holder)
+		require.Equal(t, expHolder.ID, holder.ID)
+	}
+	require.Equal(t, expFront, front)
+}
+
+func requireSignaled(t *testing.T, g *Guard) {
+	t.Helper()
+	select {
+	case <-g.StateChanged():
+	default:
+		t.Fatal("guard not signaled")
+	}
+}
+
+func requireNotSignaled(t *testing.T, g *Guard) {
+	t.Helper()
+	select {
+	case <-g.StateChanged():
+		t.Fatal("guard unexpectedly signaled")
+	default:
+	}
+}
+
+// drain clears any pending signal on the provided guards.
+func drain(gs ...*Guard) {
+	for _, g := range gs {
+		select {
+		case <-g.StateChanged():
+		default:
+		}
+	}
+}
+
+func TestTableFIFO(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var lt Table
+	key := roachpb.Key("a")
+	holder, txn1, txn2 := makeTxn(), makeTxn(), makeTxn()
+
+	g1 := lt.Enqueue(key, *holder, txn1)
+	g2 := lt.Enqueue(key, *holder, txn2)
+	g3 := lt.Enqueue(key, *holder, nil /* txn */)
+	requireState(t, g1, holder, true)
+	requireState(t, g2, holder, false)
+	requireState(t, g3, holder, false)
+	drain(g1, g2, g3)
+
+	// The front request leaves the queue after writing its own intent. Its
+	// transaction becomes the holder of the lock.
+	g1.Done(txn1)
+	requireSignaled(t, g2)
+	requireState(t, g2, txn1, true)
+	requireState(t, g3, txn1, false)
+	drain(g3)
+
+	// The lock is released once the intent is resolved.
+	lt.Release(roachpb.Span{Key: key}, txn1.ID)
+	requireSignaled(t, g2)
+	requireSignaled(t, g3)
+	requireState(t, g2, nil, true)
+
+	// The front request leaves the queue without writing an intent. The lock
+	// remains free.
+	g2.Done(nil)
+	requireSignaled(t, g3)
+	requireState(t, g3, nil, true)
+
+	g3.Done(nil)
+	require.Empty(t, lt.Waiters())
+	require.Empty(t, lt.mu.locks)
+}
+
+func TestTableDoneOutOfOrder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var lt Table
+	key := roachpb.Key("a")
+	holder, txn1, txn2 := makeTxn(), makeTxn(), makeTxn()
+
+	g1 := lt.Enqueue(key, *holder, txn1)
+	g2 := lt.Enqueue(key, *holder, txn2)
+	drain(g1, g2)
+
+	// A request that leaves the queue from behind the front, e.g. because
+	// its context was canceled, does not affect the lock or signal the front.
+	g2.Done(nil)
+	requireNotSignaled(t, g1)
+	requireState(t, g1, holder, true)
+	g1.Done(nil)
+	require.Empty(t, lt.mu.locks)
+}
+
+func TestTableUpdate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var lt Table
+	key := roachpb.Key("a")
+	holder1, holder2, txn1, txn2 := makeTxn(), makeTxn(), makeTxn(), makeTxn()
+
+	g1 := lt.Enqueue(key, *holder1, txn1)
+	g2 := lt.Enqueue(key, *holder1, txn2)
+	drain(g1, g2)
+
+	// Discovering the same holder again does not signal the waiters.
+	g1.Update(*holder1)
+	requireNotSignaled(t, g2)
+
+	// Discovering a new holder signals all waiters and the updating request
+	// retains its position in the queue.
+	lt.Release(roachpb.Span{Key: key}, holder1.ID)
+	drain(g1, g2)
+	g1.Update(*holder2)
+	requireSignaled(t, g2)
+	requireState(t, g1, holder2, true)
+	requireState(t, g2, holder2, false)
+
+	// Releasing a lock held by another transaction has no effect.
+	drain(g1, g2)
+	lt.Release(roachpb.Span{Key: key}, holder1.ID)
+	requireNotSignaled(t, g1)
+	requireState(t, g1, holder2, true)
+
+	// Ranged releases free all locks in the span.
+	lt.Release(roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("b")}, holder2.ID)
+	requireSignaled(t, g1)
+	requireState(t, g1, nil, true)
+}
+
+func TestTableClear(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var lt Table
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	holder, txn1, txn2 := makeTxn(), makeTxn(), makeTxn()
+
+	g1 := lt.Enqueue(keyA, *holder, txn1)
+	g2 := lt.Enqueue(keyA, *holder, txn2)
+	g3 := lt.Enqueue(keyB, *holder, nil /* txn */)
+	drain(g1, g2, g3)
+
+	// All waiters are released to proceed.
+	lt.Clear()
+	for _, g := range []*Guard{g1, g2, g3} {
+		requireSignaled(t, g)
+		requireState(t, g, nil, true)
+	}
+	require.Empty(t, lt.Waiters())
+
+	// A released request that discovers the lock again re-enters the queue.
+	g2.Update(*holder)
+	requireState(t, g2, holder, true)
+	g1.Done(nil)
+	g2.Done(nil)
+	g3.Done(nil)
+	require.Empty(t, lt.mu.locks)
+}
+
+func TestTableWaiters(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	var lt Table
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+	holderA, holderB, txn1, txn2 := makeTxn(), makeTxn(), makeTxn(), makeTxn()
+
+	gB := lt.Enqueue(keyB, *holderB, txn1)
+	gA1 := lt.Enqueue(keyA, *holderA, txn2)
+	gA2 := lt.Enqueue(keyA, *holderA, nil /* txn */)
+
+	waiters := lt.Waiters()
+	require.Len(t, waiters, 3)
+	for i, exp := range []struct {
+		key    roachpb.Key
+		holder *enginepb.TxnMeta
+		waiter *enginepb.TxnMeta
+		pos    int
+	}{
+		{keyA, holderA, txn2, 0},
+		{keyA, holderA, nil, 1},
+		{keyB, holderB, txn1, 0},
+	} {
+		w := waiters[i]
+		require.Equal(t, exp.key, w.Key)
+		require.Equal(t, exp.holder.ID, w.Holder.ID)
+		if exp.waiter == nil {
+			require.Nil(t, w.Waiter)
+		} else {
+			require.Equal(t, exp.waiter.ID, w.Waiter.ID)
+		}
+		require.Equal(t, exp.pos, w.Position)
+		require.False(t, w.WaitStart.IsZero())
+	}
+
+	gB.Done(nil)
+	gA1.Done(nil)
+	gA2.Done(nil)
+	require.Empty(t, lt.Waiters())
+}
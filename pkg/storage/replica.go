@@ -35,6 +35,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/rangefeed"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanlatch"
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
@@ -104,16 +105,6 @@ var MaxCommandSize = settings.RegisterValidatedByteSizeSetting(
 	},
 )
 
-// FollowerReadsEnabled controls whether replicas attempt to serve follower
-// reads. The closed timestamp machinery is unaffected by this, i.e. the same
-// information is collected and passed around, regardless of the value of this
-// setting.
-var FollowerReadsEnabled = settings.RegisterBoolSetting(
-	"kv.closed_timestamp.follower_reads_enabled",
-	"allow (all) replicas to serve consistent historical reads based on closed timestamp information",
-	false,
-)
-
 type proposalReevaluationReason int
 
 const (
@@ -225,6 +216,11 @@ type Replica struct {
 	// the rest (e.g. RangeDescriptor, transaction record, Lease, ...).
 	latchMgr spanlatch.Manager
 
+	// Tracks the intents that requests have conflicted with and queues these
+	// requests in FIFO order until the intents are resolved. Only populated
+	// on the leaseholder.
+	lockTable locktable.Table
+
 	mu struct {
 		// Protects all fields in the mu struct.
 		syncutil.RWMutex
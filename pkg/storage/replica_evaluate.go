@@ -251,8 +251,12 @@ func evaluateBatch(
 					writeTooOldErr = pErr
 					// For transactions, we want to swallow the write too old error
 					// and just move the transaction timestamp forward and set the
-					// WriteTooOld flag. See below for exceptions.
-					if ba.Txn != nil {
+					// WriteTooOld flag. See below for exceptions. READ_COMMITTED
+					// transactions are another exception: the conflict must be
+					// handled before the transaction advances its read timestamp,
+					// as the reads performed at earlier read timestamps are never
+					// refreshed.
+					if ba.Txn != nil && ba.Txn.Isolation == enginepb.SERIALIZABLE {
 						returnWriteTooOldErr = false
 					}
 				}
@@ -18,8 +18,10 @@ import (
 	"context"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/closedts/ctpb"
 	ctstorage "github.com/cockroachdb/cockroach/pkg/storage/closedts/storage"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
 
@@ -31,7 +33,7 @@ func (r *Replica) canServeFollowerRead(
 ) *roachpb.Error {
 	canServeFollowerRead := false
 	if lErr, ok := pErr.GetDetail().(*roachpb.NotLeaseHolderError); ok &&
-		FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) &&
+		closedts.FollowerReadsEnabled.Get(&r.store.cfg.Settings.SV) &&
 		lErr.LeaseHolder != nil && lErr.Lease.Type() == roachpb.LeaseEpoch {
 
 		r.mu.RLock()
@@ -70,3 +72,17 @@ func (r *Replica) canServeFollowerRead(
 	log.Event(ctx, "serving via follower read")
 	return nil
 }
+
+// maxClosed returns the maximum closed timestamp for this replica, i.e. the
+// timestamp below which it can serve follower reads given its lease applied
+// index. An empty timestamp is returned if no timestamp has been closed.
+func (r *Replica) maxClosed() hlc.Timestamp {
+	r.mu.RLock()
+	lai := r.mu.state.LeaseAppliedIndex
+	lease := *r.mu.state.Lease
+	r.mu.RUnlock()
+
+	return r.store.cfg.ClosedTimestamp.Provider.MaxClosed(
+		lease.Replica.NodeID, r.RangeID, ctpb.Epoch(lease.Epoch), ctpb.LAI(lai),
+	)
+}
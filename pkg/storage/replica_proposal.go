@@ -307,6 +307,8 @@ func (r *Replica) leasePostApply(ctx context.Context, newLease roachpb.Lease) {
 		// Also clear and disable the push transaction queue. Any waiters
 		// must be redirected to the new lease holder.
 		r.txnWaitQueue.Clear(true /* disable */)
+		// Likewise, release all requests waiting in the lock table.
+		r.lockTable.Clear()
 	}
 
 	// If we're the current raft leader, may want to transfer the leadership to
@@ -4791,6 +4791,121 @@ func TestPushTxnAlreadyCommittedOrAborted(t *testing.T) {
 	})
 }
 
+// TestParallelCommitRecovery verifies that an EndTransaction performing a
+// parallel commit stages its transaction record, that pushers which would win
+// against a STAGING pushee receive an IndeterminateCommitError, and that the
+// transaction recovery procedure finalizes the pushee according to whether
+// its in-flight writes are found.
+func TestParallelCommitRecovery(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testutils.RunTrueAndFalse(t, "implicit", func(t *testing.T, implicitlyCommitted bool) {
+		tc := testContext{}
+		stopper := stop.NewStopper()
+		defer stopper.Stop(context.TODO())
+		tc.Start(t, stopper)
+
+		keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+		pushee := newTransaction("pushee", keyA, 1, tc.Clock())
+		pusher := newTransaction("pusher", keyA, 1, tc.Clock())
+
+		// Write the pushee's first intent along with its transaction record.
+		_, btH := beginTxnArgs(keyA, pushee)
+		putA := putArgs(keyA, keyA)
+		assignSeqNumsForReqs(pushee, &putA)
+		if _, pErr := maybeWrapWithBeginTransaction(
+			context.Background(), tc.Sender(), btH, &putA,
+		); pErr != nil {
+			t.Fatal(pErr)
+		}
+		pushee.Writing = true
+
+		// Write the pushee's second intent only if it is to be found.
+		putB := putArgs(keyB, keyB)
+		assignSeqNumsForReqs(pushee, &putB)
+		if implicitlyCommitted {
+			if _, pErr := tc.SendWrappedWith(roachpb.Header{Txn: pushee}, &putB); pErr != nil {
+				t.Fatal(pErr)
+			}
+		}
+
+		// Stage the pushee's transaction record.
+		etArgs, h := endTxnArgs(pushee, true /* commit */)
+		etArgs.InFlightWrites = []roachpb.SequencedWrite{
+			{Key: keyA, Sequence: putA.Sequence},
+			{Key: keyB, Sequence: putB.Sequence},
+		}
+		assignSeqNumsForReqs(pushee, &etArgs)
+		resp, pErr := tc.SendWrappedWith(h, &etArgs)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		etReply := resp.(*roachpb.EndTransactionResponse)
+		if etReply.Txn.Status != roachpb.STAGING {
+			t.Fatalf("expected STAGING txn, found %s", etReply.Txn)
+		}
+		if etReply.StagingTimestamp != etReply.Txn.Timestamp {
+			t.Fatalf("expected staging timestamp %s, found %s",
+				etReply.Txn.Timestamp, etReply.StagingTimestamp)
+		}
+		stagingTxn := *etReply.Txn
+
+		// A forced push cannot determine the outcome of the pushee.
+		pushArgs := pushTxnArgs(pusher, pushee, roachpb.PUSH_ABORT)
+		pushArgs.Force = true
+		_, pErr = tc.SendWrapped(&pushArgs)
+		if _, ok := pErr.GetDetail().(*roachpb.IndeterminateCommitError); !ok {
+			t.Fatalf("expected IndeterminateCommitError, found %v", pErr)
+		}
+
+		// Query the in-flight writes, preventing any missing ones.
+		for _, w := range stagingTxn.InFlightWrites {
+			meta := stagingTxn.TxnMeta
+			meta.Sequence = w.Sequence
+			qiArgs := queryIntentArgs(w.Key, meta, roachpb.QueryIntentRequest_PREVENT)
+			resp, pErr := tc.SendWrapped(&qiArgs)
+			if pErr != nil {
+				t.Fatal(pErr)
+			}
+			found := resp.(*roachpb.QueryIntentResponse).FoundIntent
+			if expFound := implicitlyCommitted || w.Key.Equal(keyA); found != expFound {
+				t.Fatalf("expected found intent at %s to be %t", w.Key, expFound)
+			}
+		}
+
+		// Recover the pushee.
+		recoverArgs := pushTxnArgs(pusher, &stagingTxn, roachpb.PUSH_ABORT)
+		recoverArgs.RecoverStaging = true
+		recoverArgs.ImplicitlyCommitted = implicitlyCommitted
+		resp, pErr = tc.SendWrapped(&recoverArgs)
+		if pErr != nil {
+			t.Fatal(pErr)
+		}
+		expStatus := roachpb.ABORTED
+		if implicitlyCommitted {
+			expStatus = roachpb.COMMITTED
+		}
+		if status := resp.(*roachpb.PushTxnResponse).PusheeTxn.Status; status != expStatus {
+			t.Fatalf("expected recovered txn to be %s, found %s", expStatus, status)
+		}
+
+		// The missing write can no longer succeed at the staging timestamp.
+		if !implicitlyCommitted {
+			var ba roachpb.BatchRequest
+			ba.Header = roachpb.Header{Txn: pushee}
+			ba.Add(&putB)
+			br, pErr := tc.Sender().Send(context.Background(), ba)
+			if pErr != nil {
+				t.Fatal(pErr)
+			}
+			if !stagingTxn.Timestamp.Less(br.Txn.Timestamp) {
+				t.Fatalf("expected write to be pushed above %s, found %s",
+					stagingTxn.Timestamp, br.Txn.Timestamp)
+			}
+		}
+	})
+}
+
 // TestPushTxnUpgradeExistingTxn verifies that pushing a transaction record
 // with a new timestamp upgrades the pushee's timestamp if greater. In all
 // test cases, the priorities are set such that the push will succeed.
@@ -56,10 +56,31 @@ func (r *Replica) executeWriteBatch(
 			}
 			continue // retry
 		}
+		if pErr == nil {
+			r.releaseResolvedLocks(&ba)
+		}
 		return br, pErr
 	}
 }
 
+// releaseResolvedLocks informs the lock table of the intents of finalized
+// transactions that the batch resolved, so that the requests waiting on them
+// can proceed.
+func (r *Replica) releaseResolvedLocks(ba *roachpb.BatchRequest) {
+	for _, union := range ba.Requests {
+		switch t := union.GetInner().(type) {
+		case *roachpb.ResolveIntentRequest:
+			if t.Status.IsFinalized() {
+				r.lockTable.Release(t.Span(), t.IntentTxn.ID)
+			}
+		case *roachpb.ResolveIntentRangeRequest:
+			if t.Status.IsFinalized() {
+				r.lockTable.Release(t.Span(), t.IntentTxn.ID)
+			}
+		}
+	}
+}
+
 // tryExecuteWriteBatch is invoked by executeWriteBatch, which will
 // call this method until it returns a non-retryable result (i.e. no
 // proposalRetryReason is returned).
@@ -470,5 +491,10 @@ func isOnePhaseCommit(ba roachpb.BatchRequest, knobs *StoreTestingKnobs) bool {
 	if retry, _ := batcheval.IsEndTransactionTriggeringRetryError(ba.Txn, *etArg); retry {
 		return false
 	}
+	// A parallel commit has writes on other ranges, so its transaction
+	// record must pass through the STAGING status.
+	if etArg.IsParallelCommit() {
+		return false
+	}
 	return !knobs.DisableOptional1PC || etArg.Require1PC
 }
@@ -45,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/idalloc"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/storage/raftentry"
 	"github.com/cockroachdb/cockroach/pkg/storage/rditer"
 	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
@@ -2375,6 +2376,9 @@ func (s *Store) SplitRange(
 	// to ensure that no pre-split commands are inserted into the
 	// txnWaitQueue after we clear it.
 	leftRepl.txnWaitQueue.Clear(false /* disable */)
+	// Likewise, clear the LHS lock table so that requests waiting on keys
+	// that now belong to the RHS are redirected.
+	leftRepl.lockTable.Clear()
 
 	// The rangefeed processor will no longer be provided logical ops for
 	// its entire range, so it needs to be shut down and all registrations
@@ -2478,6 +2482,7 @@ func (s *Store) MergeRange(
 	// Clear the wait queue to redirect the queued transactions to the
 	// left-hand replica, if necessary.
 	rightRepl.txnWaitQueue.Clear(true /* disable */)
+	rightRepl.lockTable.Clear()
 
 	leftLease, _ := leftRepl.GetLease()
 	rightLease, _ := rightRepl.GetLease()
@@ -3041,16 +3046,19 @@ func (s *Store) Send(
 		log.Eventf(ctx, "executing %d requests", len(ba.Requests))
 	}
 
-	var cleanupAfterWriteIntentError func(newWIErr *roachpb.WriteIntentError, newIntentTxn *enginepb.TxnMeta)
+	// lockGuard is the request's position in the lock table of the range,
+	// if it is waiting on a conflicting intent.
+	var lockGuard *locktable.Guard
 	defer func() {
-		if cleanupAfterWriteIntentError != nil {
+		if lockGuard != nil {
 			// This request wrote an intent only if there was no error, the request
 			// is transactional, the transaction is still pending, and the request
-			// wasn't read-only.
-			if pErr == nil && ba.Txn != nil && br.Txn.Status == roachpb.PENDING && !ba.IsReadOnly() {
-				cleanupAfterWriteIntentError(nil, &br.Txn.TxnMeta)
+			// wasn't read-only. A STAGING transaction is still pending for
+			// this purpose.
+			if pErr == nil && ba.Txn != nil && !br.Txn.Status.IsFinalized() && !ba.IsReadOnly() {
+				lockGuard.Done(&br.Txn.TxnMeta)
 			} else {
-				cleanupAfterWriteIntentError(nil, nil)
+				lockGuard.Done(nil /* leftIntent */)
 			}
 		}
 	}()
@@ -3136,7 +3144,12 @@ func (s *Store) Send(
 			// this is the code path with the requesting client waiting.
 			if pErr.Index != nil {
 				var pushType roachpb.PushTxnType
-				if ba.IsWrite() {
+				if ba.WaitPolicy == roachpb.WaitPolicy_Error {
+					// The request must not wait for the conflicting transaction,
+					// so it only cleans up after the transaction if it was
+					// abandoned.
+					pushType = roachpb.PUSH_TOUCH
+				} else if ba.IsWrite() {
 					pushType = roachpb.PUSH_ABORT
 				} else {
 					pushType = roachpb.PUSH_TIMESTAMP
@@ -3162,14 +3175,21 @@ func (s *Store) Send(
 					clonedTxn := h.Txn.Clone()
 					h.Txn = &clonedTxn
 				}
-				// Handle the case where we get more than one write intent error;
-				// we need to cleanup the previous attempt to handle it to allow
-				// any other pusher queued up behind this RPC to proceed.
-				if cleanupAfterWriteIntentError != nil {
-					cleanupAfterWriteIntentError(t, nil)
-				}
-				if cleanupAfterWriteIntentError, pErr =
-					s.intentResolver.processWriteIntentError(ctx, pErr, args, h, pushType); pErr != nil {
+				// If we get more than one write intent error, the previous lock
+				// guard is handed back so that the request retains its position
+				// in the queue of the same key, or leaves it to allow any other
+				// request queued up behind this RPC to proceed.
+				wiPErr := pErr
+				if lockGuard, pErr = s.intentResolver.processWriteIntentError(
+					ctx, &repl.lockTable, lockGuard, pErr, args, h, pushType,
+				); pErr != nil {
+					// If the conflicting transaction could not be pushed without
+					// waiting, a request that doesn't wait returns the conflict
+					// to its client.
+					if _, ok := pErr.GetDetail().(*roachpb.TransactionPushError); ok &&
+						ba.WaitPolicy == roachpb.WaitPolicy_Error {
+						pErr = wiPErr
+					}
 					// Do not propagate ambiguous results; assume success and retry original op.
 					if _, ok := pErr.GetDetail().(*roachpb.AmbiguousResultError); !ok {
 						// Preserve the error index.
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/locktable"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -162,6 +163,37 @@ func (ls *Stores) GetReplicaForRangeID(rangeID roachpb.RangeID) (*Replica, error
 	return replica, nil
 }
 
+// MaxClosedTimestamp returns the maximum closed timestamp of the replica of
+// the given range held by one of the stores, i.e. the timestamp below which the
+// local node can serve reads on the range. False is returned if none of the
+// stores holds a replica of the range.
+func (ls *Stores) MaxClosedTimestamp(rangeID roachpb.RangeID) (hlc.Timestamp, bool) {
+	repl, err := ls.GetReplicaForRangeID(rangeID)
+	if err != nil {
+		return hlc.Timestamp{}, false
+	}
+	return repl.maxClosed(), true
+}
+
+// VisitLockWaits invokes the visitor with each of the requests waiting in the
+// lock tables of the replicas held by the stores.
+func (ls *Stores) VisitLockWaits(
+	visitor func(roachpb.StoreID, roachpb.RangeID, locktable.WaitInfo) error,
+) error {
+	return ls.VisitStores(func(s *Store) error {
+		var err error
+		newStoreReplicaVisitor(s).Visit(func(repl *Replica) bool {
+			for _, w := range repl.lockTable.Waiters() {
+				if err = visitor(s.StoreID(), repl.RangeID, w); err != nil {
+					return false
+				}
+			}
+			return true
+		})
+		return err
+	})
+}
+
 // Send implements the client.Sender interface. The store is looked up from the
 // store map using the ID specified in the request.
 func (ls *Stores) Send(
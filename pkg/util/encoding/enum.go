@@ -0,0 +1,104 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package encoding
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// The members of an ENUM type are stored as their physical representations,
+// which are short byte strings whose bytewise order is the order of the
+// members. Encoding them with EncodeBytesAscending or EncodeBytesDescending
+// therefore yields keys which sort in the order of the members.
+//
+// A physical representation is never empty and never ends with a zero byte.
+// This guarantees that there is always room for another representation
+// before it, so that members can be added at any position without
+// re-encoding the existing ones.
+
+// GenerateEnumPhysicalReps returns n physical representations for the members
+// of a new ENUM type, in increasing order. The representations are spread
+// evenly across the smallest number of bytes that can hold them all, so that
+// there is room to add members between them later.
+func GenerateEnumPhysicalReps(n int) [][]byte {
+	if n == 0 {
+		return nil
+	}
+	// Find the smallest number of bytes for which there are at least n + 1
+	// distinct values; the extra value keeps the representations away from
+	// the (all-zero) lower bound.
+	width := uint(1)
+	for width < 7 && uint64(n) >= uint64(1)<<(8*width) {
+		width++
+	}
+	space := uint64(1) << (8 * width)
+	step := space / uint64(n+1)
+
+	reps := make([][]byte, n)
+	for i := range reps {
+		v := uint64(i+1) * step
+		rep := make([]byte, width)
+		for j := int(width) - 1; j >= 0; j-- {
+			rep[j] = byte(v)
+			v >>= 8
+		}
+		reps[i] = bytes.TrimRight(rep, "\x00")
+	}
+	return reps
+}
+
+// EnumPhysicalRepBetween returns a physical representation that sorts
+// strictly after prev and strictly before next. A nil prev means there is no
+// lower bound, and a nil next means there is no upper bound. The result is
+// kept as short as possible.
+func EnumPhysicalRepBetween(prev, next []byte) ([]byte, error) {
+	if next != nil && bytes.Compare(prev, next) >= 0 {
+		return nil, errors.Errorf("enum representation %x does not sort before %x", prev, next)
+	}
+	if next != nil && len(next) == 0 {
+		return nil, errors.Errorf("invalid empty enum representation")
+	}
+
+	var res []byte
+	// prevTight (nextTight) is set while res is a prefix of prev (next), in
+	// which case the next byte of res is bounded by the next byte of prev
+	// (next).
+	prevTight, nextTight := true, next != nil
+	for i := 0; ; i++ {
+		lo, hi := 0, 256
+		if prevTight && i < len(prev) {
+			lo = int(prev[i])
+		} else {
+			prevTight = false
+		}
+		if nextTight {
+			if i >= len(next) {
+				return nil, errors.Errorf("enum representation %x does not sort before %x", prev, next)
+			}
+			hi = int(next[i])
+		}
+		if hi-lo > 1 {
+			// The midpoint is never zero, so the result never ends with a zero
+			// byte.
+			return append(res, byte((lo+hi)/2)), nil
+		}
+		res = append(res, byte(lo))
+		if lo != hi {
+			nextTight = false
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func checkEnumPhysicalReps(t *testing.T, reps [][]byte) {
+	t.Helper()
+	for i, rep := range reps {
+		if len(rep) == 0 || rep[len(rep)-1] == 0 {
+			t.Fatalf("invalid representation %x at position %d", rep, i)
+		}
+		if i > 0 && bytes.Compare(reps[i-1], rep) >= 0 {
+			t.Fatalf("representation %x at position %d does not sort after %x", rep, i, reps[i-1])
+		}
+		// The encoded keys must sort like the representations.
+		if i > 0 {
+			prevKey := EncodeBytesAscending(nil, reps[i-1])
+			key := EncodeBytesAscending(nil, rep)
+			if bytes.Compare(prevKey, key) >= 0 {
+				t.Fatalf("key of %x does not sort after key of %x", rep, reps[i-1])
+			}
+		}
+	}
+}
+
+func TestGenerateEnumPhysicalReps(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 100, 255, 256, 1000, 70000} {
+		reps := GenerateEnumPhysicalReps(n)
+		if len(reps) != n {
+			t.Fatalf("expected %d representations, got %d", n, len(reps))
+		}
+		checkEnumPhysicalReps(t, reps)
+	}
+
+	// Small enums use a single byte per member.
+	for _, rep := range GenerateEnumPhysicalReps(10) {
+		if len(rep) != 1 {
+			t.Fatalf("expected a single byte representation, got %x", rep)
+		}
+	}
+}
+
+func TestEnumPhysicalRepBetween(t *testing.T) {
+	testCases := []struct {
+		prev, next []byte
+		expected   []byte
+	}{
+		{nil, nil, []byte{128}},
+		{[]byte{128}, nil, []byte{192}},
+		{nil, []byte{128}, []byte{64}},
+		{[]byte{1}, []byte{3}, []byte{2}},
+		{[]byte{1}, []byte{2}, []byte{1, 128}},
+		{nil, []byte{1}, []byte{0, 128}},
+		{[]byte{255}, nil, []byte{255, 128}},
+		{[]byte{5}, []byte{5, 1}, []byte{5, 0, 128}},
+		{[]byte{5, 255}, []byte{6}, []byte{5, 255, 128}},
+	}
+	for _, tc := range testCases {
+		res, err := EnumPhysicalRepBetween(tc.prev, tc.next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(res, tc.expected) {
+			t.Errorf("between %x and %x: expected %x, got %x", tc.prev, tc.next, tc.expected, res)
+		}
+	}
+
+	for _, tc := range []struct{ prev, next []byte }{
+		{[]byte{2}, []byte{1}},
+		{[]byte{2}, []byte{2}},
+	} {
+		if _, err := EnumPhysicalRepBetween(tc.prev, tc.next); err == nil {
+			t.Errorf("expected error between %x and %x", tc.prev, tc.next)
+		}
+	}
+}
+
+func TestEnumPhysicalRepBetweenRandom(t *testing.T) {
+	rng, _ := randutil.NewPseudoRand()
+	reps := GenerateEnumPhysicalReps(3)
+	for i := 0; i < 1000; i++ {
+		// Insert a new member at a random position.
+		pos := rng.Intn(len(reps) + 1)
+		var prev, next []byte
+		if pos > 0 {
+			prev = reps[pos-1]
+		}
+		if pos < len(reps) {
+			next = reps[pos]
+		}
+		rep, err := EnumPhysicalRepBetween(prev, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reps = append(reps, nil)
+		copy(reps[pos+1:], reps[pos:])
+		reps[pos] = rep
+	}
+	checkEnumPhysicalReps(t, reps)
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package json
+
+import "github.com/cockroachdb/apd"
+
+const (
+	// offset64 is the initial hash value, and is taken from fnv.go
+	offset64 = 14695981039346656037
+
+	// prime64 is a large-ish prime number used in hashing and taken from fnv.go.
+	prime64 = 1099511628211
+)
+
+// Hash returns a 64-bit FNV-1a hash of the JSON document which is consistent
+// with Compare: JSON documents which compare as equal have the same hash, even
+// if their representations differ (for example, the numbers 1 and 1.0, or an
+// encoded and a decoded object).
+func Hash(j JSON) (uint64, error) {
+	h := jsonHasher(offset64)
+	if err := h.hashJSON(j); err != nil {
+		return 0, err
+	}
+	return uint64(h), nil
+}
+
+// jsonHasher accumulates a hash using the FNV-1a algorithm.
+type jsonHasher uint64
+
+func (h *jsonHasher) hashUint64(val uint64) {
+	*h ^= jsonHasher(val)
+	*h *= prime64
+}
+
+func (h *jsonHasher) hashString(val string) {
+	for i := 0; i < len(val); i++ {
+		*h ^= jsonHasher(val[i])
+		*h *= prime64
+	}
+	// Hash the length as well, so that the boundaries between the strings
+	// nested in a document are part of its hash.
+	h.hashUint64(uint64(len(val)))
+}
+
+func (h *jsonHasher) hashJSON(j JSON) error {
+	j, err := decodeIfNeeded(j)
+	if err != nil {
+		return err
+	}
+	h.hashUint64(uint64(j.Type()))
+	switch t := j.(type) {
+	case jsonString:
+		h.hashString(string(t))
+	case jsonNumber:
+		h.hashNumber(apd.Decimal(t))
+	case jsonArray:
+		h.hashUint64(uint64(len(t)))
+		for i := range t {
+			if err := h.hashJSON(t[i]); err != nil {
+				return err
+			}
+		}
+	case jsonObject:
+		// The pairs of an object are sorted by key, so equal objects hash their
+		// pairs in the same order.
+		h.hashUint64(uint64(len(t)))
+		for i := range t {
+			h.hashString(string(t[i].k))
+			if err := h.hashJSON(t[i].v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hashNumber hashes the reduced form of the decimal, in which trailing zeros
+// are removed from the coefficient, so that equal numbers hash the same.
+func (h *jsonHasher) hashNumber(d apd.Decimal) {
+	if d.Sign() == 0 {
+		// All the zeros (0, -0, 0.00, 0e10...) are equal.
+		return
+	}
+	var reduced apd.Decimal
+	reduced.Reduce(&d)
+	if reduced.Negative {
+		h.hashUint64(1)
+	}
+	h.hashUint64(uint64(reduced.Exponent))
+	for _, w := range reduced.Coeff.Bits() {
+		h.hashUint64(uint64(w))
+	}
+}
@@ -145,6 +145,84 @@ func TestJSONOrdering(t *testing.T) {
 	}
 }
 
+func TestJSONHash(t *testing.T) {
+	// Each group lists documents which compare as equal, and which must
+	// therefore have the same hash. The documents of different groups are
+	// expected to hash differently.
+	groups := [][]string{
+		{`null`},
+		{`true`},
+		{`false`},
+		{`0`, `-0`, `0.00`, `0e10`},
+		{`1`, `1.0`, `1e0`, `0.1e1`, `10e-1`},
+		{`-1`, `-1.00`},
+		{`100`, `1e2`, `100.0`},
+		{`1.5`, `15e-1`},
+		{`"1"`},
+		{`""`},
+		{`"ab"`},
+		{`[]`},
+		{`{}`},
+		{`[1, 2]`, `[1.0, 2e0]`},
+		{`[2, 1]`},
+		{`[["a"], "b"]`},
+		{`["a", ["b"]]`},
+		{`["ab"]`},
+		{`["a", "b"]`},
+		{`{"a": 1, "b": [1]}`, `{"b": [1.0], "a": 1}`},
+		{`{"a": 1}`},
+		{`{"b": 1}`},
+		{`{"a": "b"}`},
+	}
+	hashes := make(map[uint64]string)
+	for _, group := range groups {
+		groupHash, err := Hash(jsonTestShorthand(group[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, src := range group {
+			runDecodedAndEncoded(t, src, jsonTestShorthand(src), func(t *testing.T, j JSON) {
+				h, err := Hash(j)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if h != groupHash {
+					t.Fatalf("expected %s to have the same hash as %s", src, group[0])
+				}
+			})
+		}
+		if other, ok := hashes[groupHash]; ok {
+			t.Errorf("expected %s and %s to have different hashes", group[0], other)
+		}
+		hashes[groupHash] = group[0]
+	}
+}
+
+func TestJSONRandomHash(t *testing.T) {
+	rng := rand.New(rand.NewSource(timeutil.Now().Unix()))
+	for i := 0; i < 1000; i++ {
+		j, err := Random(20, rng)
+		if err != nil {
+			t.Fatal(err)
+		}
+		j2, err := ParseJSON(j.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		h, err := Hash(j)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, err := Hash(j2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != h2 {
+			t.Fatalf("%s and its round-trip %s have different hashes", j.String(), j2.String())
+		}
+	}
+}
+
 func TestJSONRoundTrip(t *testing.T) {
 	testCases := []string{
 		`1`,
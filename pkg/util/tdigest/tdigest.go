@@ -0,0 +1,237 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package tdigest implements the t-digest, a compact sketch of a
+// distribution of floating point values which supports approximate quantile
+// queries. Digests built over disjoint sets of values can be merged, which
+// makes them suitable for computing quantiles in a distributed fashion.
+//
+// This is a "merging" t-digest as described in Dunning and Ertl, "Computing
+// Extremely Accurate Quantiles Using t-Digests". Values are buffered and
+// periodically merged into a sorted list of centroids; the size of each
+// centroid is bounded by a function of its quantile, so centroids near the
+// tails of the distribution stay small and quantiles there are accurate.
+package tdigest
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/pkg/errors"
+)
+
+// DefaultCompression is the compression used by New. Higher values produce
+// more accurate quantiles at the expense of larger digests; the number of
+// centroids in a digest is roughly proportional to the compression.
+const DefaultCompression = 100
+
+// centroid summarizes a set of values by their mean and their count.
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// TDigest is a sketch of a distribution of values. The zero value is not
+// usable; use New or Decode to create a TDigest.
+type TDigest struct {
+	compression float64
+
+	// centroids is sorted by mean and has been compressed.
+	centroids []centroid
+	// unmerged contains centroids which have not yet been merged into
+	// centroids.
+	unmerged []centroid
+
+	count    float64
+	min, max float64
+}
+
+// New creates an empty TDigest with the default compression.
+func New() *TDigest {
+	return NewWithCompression(DefaultCompression)
+}
+
+// NewWithCompression creates an empty TDigest with the given compression.
+func NewWithCompression(compression float64) *TDigest {
+	return &TDigest{
+		compression: compression,
+		min:         math.Inf(+1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Count returns the number of values added to the digest.
+func (t *TDigest) Count() float64 {
+	return t.count
+}
+
+// Add adds a value to the digest.
+func (t *TDigest) Add(x float64) {
+	t.add(centroid{mean: x, weight: 1})
+}
+
+// Merge adds all the values summarized by another digest to this digest.
+func (t *TDigest) Merge(other *TDigest) {
+	for _, c := range other.centroids {
+		t.add(c)
+	}
+	for _, c := range other.unmerged {
+		t.add(c)
+	}
+	// The extremes of the other digest are not necessarily the means of its
+	// centroids.
+	t.min = math.Min(t.min, other.min)
+	t.max = math.Max(t.max, other.max)
+}
+
+func (t *TDigest) add(c centroid) {
+	t.unmerged = append(t.unmerged, c)
+	t.count += c.weight
+	t.min = math.Min(t.min, c.mean)
+	t.max = math.Max(t.max, c.mean)
+	// Bound the size of the buffer of unmerged values; the limit is arbitrary
+	// but a multiple of the compression keeps the amortized cost low.
+	if float64(len(t.unmerged)) > 4*t.compression {
+		t.compress()
+	}
+}
+
+// compress merges the unmerged centroids into the sorted centroid list. Two
+// adjacent centroids are combined if the result does not exceed the size bound
+// 4*n*q*(1-q)/compression, where n is the total count and q is the quantile of
+// the combined centroid.
+func (t *TDigest) compress() {
+	if len(t.unmerged) == 0 {
+		return
+	}
+	all := append(t.unmerged, t.centroids...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(t.centroids)+1)
+	cur := all[0]
+	var weightSoFar float64
+	for _, c := range all[1:] {
+		weight := cur.weight + c.weight
+		q := (weightSoFar + weight/2) / t.count
+		if weight <= 4*t.count*q*(1-q)/t.compression {
+			cur.mean += (c.mean - cur.mean) * c.weight / weight
+			cur.weight = weight
+			continue
+		}
+		merged = append(merged, cur)
+		weightSoFar += cur.weight
+		cur = c
+	}
+	t.centroids = append(merged, cur)
+	t.unmerged = t.unmerged[:0]
+}
+
+// Quantile returns the approximate value at quantile q of the values added to
+// the digest, where q is between 0 and 1. The result is interpolated linearly
+// between the centroids that surround q; when every centroid holds a single
+// value, this matches the exact continuous percentile of the values. Quantile
+// returns NaN if the digest is empty.
+func (t *TDigest) Quantile(q float64) float64 {
+	t.compress()
+	if len(t.centroids) == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+
+	// Each centroid is centered at the cumulative weight of the centroids that
+	// precede it plus half its own weight. The smallest and largest values sit
+	// at positions 0.5 and count-0.5 respectively.
+	pos := q*(t.count-1) + 0.5
+	first := t.centroids[0]
+	center := first.weight / 2
+	if pos < center {
+		return interpolate(t.min, first.mean, (pos-0.5)/(center-0.5))
+	}
+	for i := 1; i < len(t.centroids); i++ {
+		prev, c := t.centroids[i-1], t.centroids[i]
+		next := center + (prev.weight+c.weight)/2
+		if pos <= next {
+			return interpolate(prev.mean, c.mean, (pos-center)/(next-center))
+		}
+		center = next
+	}
+	last := t.centroids[len(t.centroids)-1]
+	if last.weight <= 1 {
+		return last.mean
+	}
+	return interpolate(last.mean, t.max, (pos-center)/(last.weight/2-0.5))
+}
+
+func interpolate(a, b, frac float64) float64 {
+	return a + (b-a)*frac
+}
+
+// Encode appends the serialized form of the digest to buf.
+func (t *TDigest) Encode(buf []byte) []byte {
+	t.compress()
+	buf = encoding.EncodeFloatAscending(buf, t.compression)
+	buf = encoding.EncodeFloatAscending(buf, t.min)
+	buf = encoding.EncodeFloatAscending(buf, t.max)
+	buf = encoding.EncodeUvarintAscending(buf, uint64(len(t.centroids)))
+	for _, c := range t.centroids {
+		buf = encoding.EncodeFloatAscending(buf, c.mean)
+		buf = encoding.EncodeFloatAscending(buf, c.weight)
+	}
+	return buf
+}
+
+// Decode deserializes a digest serialized with Encode.
+func Decode(buf []byte) (*TDigest, error) {
+	var compression, min, max float64
+	var n uint64
+	var err error
+	if buf, compression, err = encoding.DecodeFloatAscending(buf); err != nil {
+		return nil, err
+	}
+	if buf, min, err = encoding.DecodeFloatAscending(buf); err != nil {
+		return nil, err
+	}
+	if buf, max, err = encoding.DecodeFloatAscending(buf); err != nil {
+		return nil, err
+	}
+	if buf, n, err = encoding.DecodeUvarintAscending(buf); err != nil {
+		return nil, err
+	}
+	if compression <= 0 {
+		return nil, errors.Errorf("invalid t-digest compression %f", compression)
+	}
+	t := NewWithCompression(compression)
+	t.min, t.max = min, max
+	for i := uint64(0); i < n; i++ {
+		var c centroid
+		if buf, c.mean, err = encoding.DecodeFloatAscending(buf); err != nil {
+			return nil, err
+		}
+		if buf, c.weight, err = encoding.DecodeFloatAscending(buf); err != nil {
+			return nil, err
+		}
+		t.centroids = append(t.centroids, c)
+		t.count += c.weight
+	}
+	if len(buf) != 0 {
+		return nil, errors.Errorf("%d trailing bytes after t-digest", len(buf))
+	}
+	return t, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tdigest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// exactQuantile returns the continuous quantile q of the sorted values.
+func exactQuantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := math.Floor(pos)
+	hi := math.Ceil(pos)
+	return sorted[int(lo)] + (sorted[int(hi)]-sorted[int(lo)])*(pos-lo)
+}
+
+func TestQuantileExactForFewValues(t *testing.T) {
+	values := []float64{7, 1, 3, 10, 4}
+	d := New()
+	for _, v := range values {
+		d.Add(v)
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	for _, q := range []float64{0, 0.1, 0.25, 0.5, 0.6, 0.75, 0.99, 1} {
+		if res, exp := d.Quantile(q), exactQuantile(sorted, q); math.Abs(res-exp) > 1e-9 {
+			t.Errorf("quantile %f: expected %f, got %f", q, exp, res)
+		}
+	}
+}
+
+func TestQuantileEmpty(t *testing.T) {
+	if res := New().Quantile(0.5); !math.IsNaN(res) {
+		t.Errorf("expected NaN, got %f", res)
+	}
+}
+
+func TestMergeAndEncode(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	const numValues = 100000
+	const numDigests = 8
+
+	var values []float64
+	digests := make([]*TDigest, numDigests)
+	for i := range digests {
+		digests[i] = New()
+	}
+	for i := 0; i < numValues; i++ {
+		v := rng.NormFloat64()
+		values = append(values, v)
+		digests[rng.Intn(numDigests)].Add(v)
+	}
+	sort.Float64s(values)
+
+	// Round-trip each digest through its serialized form before merging, the
+	// way partial digests are shipped between nodes.
+	merged := New()
+	for _, d := range digests {
+		decoded, err := Decode(d.Encode(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged.Merge(decoded)
+	}
+	if merged.Count() != numValues {
+		t.Fatalf("expected count %d, got %f", numValues, merged.Count())
+	}
+
+	for _, q := range []float64{0, 0.001, 0.01, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999, 1} {
+		t.Run(fmt.Sprintf("q=%f", q), func(t *testing.T) {
+			res := merged.Quantile(q)
+			// Check the error in terms of the rank of the result rather than its
+			// value, which is how the accuracy of the t-digest is bounded.
+			rank := float64(sort.SearchFloat64s(values, res)) / numValues
+			if math.Abs(rank-q) > 0.01 {
+				t.Errorf("expected rank close to %f, got %f (value %f)", q, rank, res)
+			}
+		})
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	d := New()
+	d.Add(1)
+	d.Add(2)
+	buf := d.Encode(nil)
+	if _, err := Decode(buf[:len(buf)-1]); err == nil {
+		t.Error("expected error decoding truncated digest")
+	}
+	if _, err := Decode(append(buf, 0)); err == nil {
+		t.Error("expected error decoding digest with trailing bytes")
+	}
+}